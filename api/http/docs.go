@@ -15,45 +15,152 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
-            "post": {
-                "description": "Authenticates user and returns JWT token",
+        "/account": {
+            "delete": {
+                "description": "Deletes authenticated user and all their secrets after verifying the password",
                 "consumes": [
                     "application/json"
                 ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Delete account",
+                "parameters": [
+                    {
+                        "description": "Current password",
+                        "name": "deleteAccountRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.DeleteAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized or invalid password",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "organization must keep at least one owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "too many failed attempts",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "description": "Lists the newest audit events (secret access, logins, two-factor changes) of authenticated user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "audit"
                 ],
-                "summary": "Authenticate a user (login)",
+                "summary": "List audit events",
                 "parameters": [
                     {
-                        "description": "Login request payload",
-                        "name": "loginRequest",
+                        "type": "integer",
+                        "description": "Maximum number of events (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.AuditEventResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid limit",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/certificate": {
+            "put": {
+                "description": "Publishes the certificate other users wrap the keys of shared secrets for",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "Publish certificate",
+                "parameters": [
+                    {
+                        "description": "PEM certificate",
+                        "name": "certificateRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.LoginRequest"
+                            "$ref": "#/definitions/http.CertificateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "JWT token returned in Authorization header",
+                        "description": "ok",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "invalid request body",
+                        "description": "invalid request body or certificate",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "401": {
-                        "description": "invalid username or password",
+                        "description": "unauthorized",
                         "schema": {
                             "type": "string"
                         }
@@ -67,9 +174,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
+        "/login": {
             "post": {
-                "description": "Registers a user with username and password, returns JWT token",
+                "description": "Authenticates user and returns JWT token",
                 "consumes": [
                     "application/json"
                 ],
@@ -79,15 +186,15 @@ const docTemplate = `{
                 "tags": [
                     "auth"
                 ],
-                "summary": "Register a new user",
+                "summary": "Authenticate a user (login)",
                 "parameters": [
                     {
-                        "description": "Register request payload",
-                        "name": "registerRequest",
+                        "description": "Login request payload",
+                        "name": "loginRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.RegisterRequest"
+                            "$ref": "#/definitions/http.LoginRequest"
                         }
                     }
                 ],
@@ -104,8 +211,14 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "409": {
-                        "description": "user already exists",
+                    "401": {
+                        "description": "invalid username, password or one-time code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "too many failed attempts",
                         "schema": {
                             "type": "string"
                         }
@@ -119,35 +232,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/secrets": {
+        "/organizations": {
             "get": {
-                "description": "Returns all secrets belonging to the authenticated user.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists the organizations authenticated user belongs to with their role",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "secrets"
-                ],
-                "summary": "List all secrets for a user",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bearer token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    }
+                    "organizations"
                 ],
+                "summary": "List organizations",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/handlers.SecretResponse"
+                                "$ref": "#/definitions/http.MembershipResponse"
                             }
                         }
                     },
@@ -158,7 +259,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "failed to list secrets",
+                        "description": "internal server error",
                         "schema": {
                             "type": "string"
                         }
@@ -166,44 +267,34 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Adds a new secret for the authenticated user.",
+                "description": "Creates an organization owned by authenticated user",
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "secrets"
+                    "organizations"
                 ],
-                "summary": "Add a new secret",
+                "summary": "Create an organization",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Bearer token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "description": "Secret data to save",
-                        "name": "secret",
+                        "description": "Organization",
+                        "name": "organizationRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handlers.SecretSaveRequest"
+                            "$ref": "#/definitions/http.OrganizationRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "ok",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "400": {
-                        "description": "invalid request body",
+                        "description": "invalid request body or name",
                         "schema": {
                             "type": "string"
                         }
@@ -214,8 +305,14 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "409": {
+                        "description": "organization already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "failed to save secret",
+                        "description": "internal server error",
                         "schema": {
                             "type": "string"
                         }
@@ -223,53 +320,144 @@ const docTemplate = `{
                 }
             }
         },
-        "/secrets/{secret_type}/{secret_name}": {
-            "get": {
-                "description": "Retrieves a secret for the authenticated user by secret type and name.",
-                "consumes": [
-                    "application/json"
+        "/organizations/{organization}": {
+            "delete": {
+                "description": "Deletes an organization with all its vaults and secrets, requires the owner role",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Delete an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not an owner of the organization",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization}/members": {
+            "get": {
+                "description": "Lists the members of an organization authenticated user belongs to",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "secrets"
+                    "organizations"
                 ],
-                "summary": "Get a secret by type and name",
+                "summary": "List members",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Bearer token",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.MembershipResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not a member of the organization",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization}/members/{username}": {
+            "put": {
+                "description": "Adds a user to an organization or changes their role, requires the admin role; owner and admin roles are managed by owners",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Set a member",
+                "parameters": [
                     {
                         "type": "string",
-                        "example": "password",
-                        "description": "Secret type",
-                        "name": "secret_type",
+                        "description": "Organization name",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "example": "my-bank-password",
-                        "description": "Secret name",
-                        "name": "secret_name",
+                        "description": "Member username",
+                        "name": "username",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Role",
+                        "name": "memberRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.MemberRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "ok",
                         "schema": {
-                            "$ref": "#/definitions/handlers.SecretResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
-                        "description": "missing secret_type or secret_name URL parameter",
+                        "description": "invalid request body or role",
                         "schema": {
                             "type": "string"
                         }
@@ -280,96 +468,1524 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "403": {
+                        "description": "role does not allow this",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "organization must keep an owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "failed to get secret",
+                        "description": "internal server error",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "handlers.LoginRequest": {
-            "type": "object",
-            "properties": {
-                "password": {
-                    "description": "Password of the user\nexample: secret123",
-                    "type": "string"
-                },
-                "username": {
-                    "description": "Username of the user\nexample: johndoe",
-                    "type": "string"
-                }
-            }
+            },
+            "delete": {
+                "description": "Removes a user from an organization; members may always leave themselves",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Remove a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "role does not allow this",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "member not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "organization must keep an owner",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization}/vaults": {
+            "get": {
+                "description": "Lists the vaults of an organization authenticated user belongs to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List vaults",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.VaultResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not a member of the organization",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a vault in an organization, requires the admin role",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Create a vault",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Vault",
+                        "name": "vaultRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.VaultRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body or name",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "role does not allow this",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "vault already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization}/vaults/{vault}": {
+            "delete": {
+                "description": "Deletes a vault with all its secrets, requires the admin role",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Delete a vault",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Vault name",
+                        "name": "vault",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "role does not allow this",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "vault not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/otp/confirm": {
+            "post": {
+                "description": "Enables two-factor authentication for authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "One-time code",
+                        "name": "otpConfirmRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.OTPConfirmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body or one-time code",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "two-factor authentication not enrolled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "too many failed attempts",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/otp/enroll": {
+            "post": {
+                "description": "Generates a TOTP seed for authenticated user and returns otpauth URI and recovery codes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Enroll two-factor authentication",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.OTPEnrollResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "two-factor authentication already enabled",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/password": {
+            "post": {
+                "description": "Changes password of authenticated user, signs out all sessions and returns a new JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "changePasswordRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token returned in Authorization header",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized or invalid password",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "too many failed attempts",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Registers a user with username and password, returns JWT token.\nIn master-password mode the request carries the KDF parameters and the derived authentication key as password.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "Register request payload",
+                        "name": "registerRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "JWT token returned in Authorization header",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body or KDF parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "user already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "too many failed attempts",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets": {
+            "get": {
+                "description": "Lists all secrets for authenticated user, or all secrets of an organization vault",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "List all secrets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization vault as organization/vault",
+                        "name": "vault",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.SecretResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not a member of the organization",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "vault not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves a secret for authenticated user, or to an organization vault the user is at least a member of",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Save a secret",
+                "parameters": [
+                    {
+                        "description": "Secret save request payload",
+                        "name": "secret",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretSaveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not allowed to write to the vault",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "vault not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/batch": {
+            "post": {
+                "description": "Saves several secrets for authenticated user atomically, used for key rotation",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Save secrets in one transaction",
+                "parameters": [
+                    {
+                        "description": "Secrets to save",
+                        "name": "secrets",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.SecretSaveRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/secrets/{secret_type}/{secret_name}": {
+            "get": {
+                "description": "Retrieves a secret for authenticated user by secret_type and secret_name, or from an organization vault",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "secrets"
+                ],
+                "summary": "Get a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret type",
+                        "name": "secret_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "secret_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization vault as organization/vault",
+                        "name": "vault",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.SecretResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "missing parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "not a member of the organization",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "secret or vault not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/shared/{secret_owner}/{secret_type}/{secret_name}": {
+            "put": {
+                "description": "Replaces the content of a secret another user shares read-write with authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "Update a shared secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner of the secret",
+                        "name": "secret_owner",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret type",
+                        "name": "secret_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "secret_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New ciphertext",
+                        "name": "sharedSaveRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SharedSaveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "secret is shared read-only",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "share not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "secret has a new data key, the owner has to share it again",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/shares": {
+            "get": {
+                "description": "Lists secrets other users share with authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "List shared secrets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/http.SharedSecretResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Shares a secret of authenticated user read-only or read-write; sharing again replaces the key and permission",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "Share a secret",
+                "parameters": [
+                    {
+                        "description": "Share request payload",
+                        "name": "shareRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ShareRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body or permission",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "secret, user or certificate not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "key is not wrapped for the recipient's current certificate",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/shares/{secret_type}/{secret_name}/{recipient}": {
+            "delete": {
+                "description": "Revokes the access of a user to a secret of authenticated user",
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "Unshare a secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret type",
+                        "name": "secret_type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "secret_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User the secret is shared with",
+                        "name": "recipient",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "share not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/certificate": {
+            "get": {
+                "description": "Returns the certificate a user published for sharing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sharing"
+                ],
+                "summary": "Get certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/http.CertificateResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user or certificate not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}/kdf": {
+            "get": {
+                "description": "Returns the parameters the master password of a user is stretched with",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get KDF parameters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.KDFParams"
+                        }
+                    },
+                    "404": {
+                        "description": "master password is not set up for this user",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "http.AuditEventResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Action performed",
+                    "type": "string",
+                    "example": "secret.get"
+                },
+                "client_ip": {
+                    "description": "Client IP address",
+                    "type": "string",
+                    "example": "192.0.2.1"
+                },
+                "created_at": {
+                    "description": "Time of the event",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Event identifier",
+                    "type": "integer",
+                    "example": 42
+                },
+                "secret_key": {
+                    "description": "Accessed secret as type/name, empty for account events",
+                    "type": "string",
+                    "example": "user/mail"
+                },
+                "transport": {
+                    "description": "Transport the request came in on: http or grpc",
+                    "type": "string",
+                    "example": "http"
+                },
+                "username": {
+                    "description": "Account the event belongs to",
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "http.CertificateRequest": {
+            "type": "object",
+            "properties": {
+                "certificate": {
+                    "description": "PEM encoded certificate or public key",
+                    "type": "string",
+                    "example": "-----BEGIN CERTIFICATE-----..."
+                }
+            }
+        },
+        "http.CertificateResponse": {
+            "type": "object",
+            "properties": {
+                "certificate": {
+                    "description": "PEM encoded certificate or public key",
+                    "type": "string",
+                    "example": "-----BEGIN CERTIFICATE-----..."
+                }
+            }
+        },
+        "http.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "description": "New password of the user\nexample: n3w-Secret!",
+                    "type": "string",
+                    "example": "n3w-Secret!"
+                },
+                "old_password": {
+                    "description": "Current password of the user\nexample: secret123",
+                    "type": "string",
+                    "example": "secret123"
+                }
+            }
         },
-        "handlers.RegisterRequest": {
+        "http.DeleteAccountRequest": {
             "type": "object",
             "properties": {
                 "password": {
-                    "description": "Password for the new user\nexample: secret123",
+                    "description": "Current password of the user\nexample: secret123",
+                    "type": "string",
+                    "example": "secret123"
+                }
+            }
+        },
+        "http.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "otp_code": {
+                    "description": "One-time code, required when two-factor authentication is enabled\nexample: 123456",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "description": "Password of the user\nexample: secret123",
+                    "type": "string",
+                    "example": "secret123"
+                },
+                "username": {
+                    "description": "Username of the user\nexample: johndoe",
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "http.MemberRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "description": "Role of the member: owner, admin, member or read-only\nexample: member",
+                    "type": "string",
+                    "example": "member"
+                }
+            }
+        },
+        "http.MembershipResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Time the user joined the organization",
+                    "type": "string"
+                },
+                "organization": {
+                    "description": "Organization name",
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role of the member: owner, admin, member or read-only",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Time the role was last changed",
                     "type": "string"
                 },
                 "username": {
-                    "description": "Username for the new user\nexample: johndoe",
+                    "description": "Member username",
                     "type": "string"
                 }
             }
         },
-        "handlers.SecretResponse": {
+        "http.OTPConfirmRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "One-time code from the authenticator app\nexample: 123456",
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "http.OTPEnrollResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "description": "Single-use recovery codes",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "a1b2c-3d4e5"
+                    ]
+                },
+                "uri": {
+                    "description": "otpauth:// URI to add to an authenticator app",
+                    "type": "string",
+                    "example": "otpauth://totp/GophKeeper:johndoe?secret=JBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "http.OrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Organization name\nexample: acme",
+                    "type": "string",
+                    "example": "acme"
+                }
+            }
+        },
+        "http.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "kdf": {
+                    "description": "Parameters the master password is stretched with, set only in master-password mode",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.KDFParams"
+                        }
+                    ]
+                },
+                "password": {
+                    "description": "Password for the new user; in master-password mode the authentication key derived from it\nexample: secret123",
+                    "type": "string",
+                    "example": "secret123"
+                },
+                "username": {
+                    "description": "Username for the new user\nexample: johndoe",
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "http.SecretRecipient": {
+            "type": "object",
+            "properties": {
+                "aes_key_enc": {
+                    "description": "AES key wrapped with the recipient's public key",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "key_id": {
+                    "description": "Identifier of the recipient's public key\nexample: 9f86d081884c7d659a2feaa0c55ad015",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                }
+            }
+        },
+        "http.SecretResponse": {
             "type": "object",
             "properties": {
                 "aes_key_enc": {
-                    "description": "AESKeyEnc is the encrypted AES key, base64 encoded.",
+                    "description": "Encrypted AES key",
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
                 "ciphertext": {
-                    "description": "Ciphertext is the encrypted secret data, base64 encoded.",
+                    "description": "Ciphertext bytes",
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
+                "key_id": {
+                    "description": "Identifier of the public key the AES key is wrapped with",
+                    "type": "string"
+                },
+                "recipients": {
+                    "description": "AES key wrapped for every recipient when there is more than one",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SecretRecipient"
+                    }
+                },
                 "secret_name": {
-                    "description": "SecretName is the unique name of the secret.",
+                    "description": "Secret name",
+                    "type": "string"
+                },
+                "secret_type": {
+                    "description": "Secret type",
+                    "type": "string"
+                },
+                "vault": {
+                    "description": "Organization vault as organization/vault, empty for a personal secret",
+                    "type": "string"
+                }
+            }
+        },
+        "http.SecretSaveRequest": {
+            "type": "object",
+            "properties": {
+                "aes_key_enc": {
+                    "description": "Encrypted AES key",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "ciphertext": {
+                    "description": "Ciphertext bytes",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "created_at": {
+                    "description": "Time the secret was created, kept by batch saves of restored secrets; empty for now",
                     "type": "string"
                 },
+                "key_id": {
+                    "description": "Identifier of the public key the AES key is wrapped with\nexample: 9f86d081884c7d659a2feaa0c55ad015",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "recipients": {
+                    "description": "AES key wrapped for every recipient when there is more than one",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.SecretRecipient"
+                    }
+                },
+                "secret_name": {
+                    "description": "Secret name\nexample: mysecret",
+                    "type": "string",
+                    "example": "mysecret"
+                },
                 "secret_type": {
-                    "description": "SecretType represents the type/category of the secret.",
+                    "description": "Secret type\nexample: password",
+                    "type": "string",
+                    "example": "password"
+                },
+                "updated_at": {
+                    "description": "Time the secret was last updated, kept by batch saves of restored secrets; empty for now",
                     "type": "string"
+                },
+                "vault": {
+                    "description": "Organization vault as organization/vault, empty for a personal secret\nexample: acme/ops",
+                    "type": "string",
+                    "example": "acme/ops"
+                }
+            }
+        },
+        "http.ShareRequest": {
+            "type": "object",
+            "properties": {
+                "aes_key_enc": {
+                    "description": "AES key of the secret wrapped with the recipient's certificate",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "key_id": {
+                    "description": "Identifier of the recipient's certificate\nexample: 9f86d081884c7d659a2feaa0c55ad015",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "permission": {
+                    "description": "Permission of the recipient: ro or rw\nexample: ro",
+                    "type": "string",
+                    "example": "ro"
+                },
+                "recipient": {
+                    "description": "User the secret is shared with\nexample: janedoe",
+                    "type": "string",
+                    "example": "janedoe"
+                },
+                "secret_name": {
+                    "description": "Secret name\nexample: mysecret",
+                    "type": "string",
+                    "example": "mysecret"
+                },
+                "secret_type": {
+                    "description": "Secret type\nexample: password",
+                    "type": "string",
+                    "example": "password"
+                }
+            }
+        },
+        "http.SharedSaveRequest": {
+            "type": "object",
+            "properties": {
+                "ciphertext": {
+                    "description": "Ciphertext bytes encrypted with the existing data key of the secret",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "handlers.SecretSaveRequest": {
+        "http.SharedSecretResponse": {
             "type": "object",
             "properties": {
                 "aes_key_enc": {
-                    "description": "AESKeyEnc is the encrypted AES key, base64 encoded.\nexample: U29tZUVuY3J5cHRlZEtleQ==",
+                    "description": "AES key wrapped with the certificate of the authenticated user",
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
                 "ciphertext": {
-                    "description": "Ciphertext is the encrypted secret data, base64 encoded.\nexample: SGVsbG8sIHNlY3JldCE=",
+                    "description": "Ciphertext bytes",
                     "type": "array",
                     "items": {
                         "type": "integer"
                     }
                 },
+                "created_at": {
+                    "description": "Time the secret was created",
+                    "type": "string"
+                },
+                "key_id": {
+                    "description": "Identifier of the certificate the AES key is wrapped with",
+                    "type": "string"
+                },
+                "permission": {
+                    "description": "Permission of the authenticated user: ro or rw",
+                    "type": "string"
+                },
                 "secret_name": {
-                    "description": "SecretName is the unique name of the secret.\nexample: my-bank-password",
+                    "description": "Secret name",
+                    "type": "string"
+                },
+                "secret_owner": {
+                    "description": "Owner of the secret",
                     "type": "string"
                 },
                 "secret_type": {
-                    "description": "SecretType represents the type/category of the secret.\nexample: password",
+                    "description": "Secret type",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "Time the secret was last updated",
+                    "type": "string"
+                }
+            }
+        },
+        "http.VaultRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "Vault name\nexample: ops",
+                    "type": "string",
+                    "example": "ops"
+                }
+            }
+        },
+        "http.VaultResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "Time the vault was created",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Vault name",
+                    "type": "string"
+                },
+                "organization": {
+                    "description": "Organization name",
                     "type": "string"
                 }
             }
+        },
+        "models.KDFParams": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "description": "Algorithm is the key derivation function, always argon2id.",
+                    "type": "string",
+                    "example": "argon2id"
+                },
+                "memory": {
+                    "description": "Memory is the memory cost in KiB.",
+                    "type": "integer",
+                    "example": 65536
+                },
+                "salt": {
+                    "description": "Salt is random and unique per user.",
+                    "type": "string",
+                    "format": "byte",
+                    "example": "c2FsdHNhbHRzYWx0c2FsdA=="
+                },
+                "threads": {
+                    "description": "Threads is the degree of parallelism.",
+                    "type": "integer",
+                    "example": 4
+                },
+                "time": {
+                    "description": "Time is the number of passes over the memory.",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,595 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sbilibin2017/gophkeeper/internal/cli"
+	"github.com/sbilibin2017/gophkeeper/internal/client"
+	"github.com/sbilibin2017/gophkeeper/internal/scheme"
+)
+
+// programName is the name of the client binary in help and completion scripts.
+const programName = "gophkeeper"
+
+// newApp returns the client commands with their flags, help and completion.
+func newApp() *cli.App {
+	return cli.New(programName,
+		cli.WithFlags(flagDefs),
+		cli.WithCommands(commands()...),
+		cli.WithTopics(topics()...),
+	)
+}
+
+// noResult adapts a command variant without a result to schemeCommand.
+func noResult(fn func(context.Context) error) func(context.Context) (struct{}, error) {
+	return func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}
+}
+
+// printed returns the Run of a command printing the result of fn with print.
+func printed[T any](fn func(context.Context) (T, error), print func(T)) func(context.Context, []string) error {
+	return func(ctx context.Context, _ []string) error {
+		out, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		print(out)
+		return nil
+	}
+}
+
+// printLine and printText print the output of a command with and without a trailing newline.
+func printLine(out string) { fmt.Println(out) }
+func printText(out string) { fmt.Print(out) }
+
+// withPrefix prints the output of a command after prefix, like "Registered. Token:".
+func withPrefix(prefix string) func(string) {
+	return func(out string) { fmt.Println(prefix, out) }
+}
+
+// message prints a fixed message after a command without output.
+func message(msg string) func(struct{}) {
+	return func(struct{}) { fmt.Println(msg) }
+}
+
+// schemeCommand returns a function running the HTTP or the gRPC variant of a command
+// by the scheme of --server-url.
+func schemeCommand[T any](httpFn, grpcFn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		switch scheme.GetSchemeFromURL(serverURL) {
+		case scheme.HTTP, scheme.HTTPS:
+			return httpFn(ctx)
+		case scheme.GRPC:
+			return grpcFn(ctx)
+		}
+		var zero T
+		return zero, errors.New("unsupported scheme")
+	}
+}
+
+// shareCommand returns the variant of the sharing command by the scheme of --server-url.
+func shareCommand(command string) func(context.Context) (string, error) {
+	return schemeCommand(
+		func(ctx context.Context) (string, error) { return runShareHTTP(ctx, command) },
+		func(ctx context.Context) (string, error) { return runShareGRPC(ctx, command) },
+	)
+}
+
+// Flags shared by the commands encrypting secrets with --pubkey or the master password.
+var encryptFlags = []string{"pubkey", "recipient", "master-password", "server-url"}
+
+// Flags of the add commands, which encrypt a secret and, given the private key, keep the data key
+// of the stored secret they replace.
+var addFlags = append([]string{"privkey"}, encryptFlags...)
+
+// Flags shared by the commands decrypting secrets with --privkey or the master password.
+var decryptFlags = []string{"privkey", "strict", "master-password", "server-url"}
+
+// flags concatenates groups of flag names.
+func flags(groups ...[]string) []string {
+	var all []string
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+// commands returns the client commands in help order.
+func commands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:     client.CommandRegister,
+			Summary:  "Register a new user",
+			Flags:    []string{"username", "password", "master-password", "server-url"},
+			Required: []string{"username", "server-url"},
+			Description: "--password is required unless --master-password registers in master-password mode\n" +
+				"(see help master-password).",
+			Examples: []string{
+				"register --username alice --password secret123 --server-url http://localhost:8080",
+				`register --username alice --master-password "correct horse battery" --server-url http://localhost:8080`,
+			},
+			Run: printed(schemeCommand(runRegisterHTTP, runRegisterGRPC), withPrefix("Registered. Token:")),
+		},
+		{
+			Name:     client.CommandLogin,
+			Summary:  "Login and get authentication token",
+			Flags:    []string{"username", "password", "master-password", "otp-code", "server-url"},
+			Required: []string{"username", "server-url"},
+			Description: "--password is required unless --master-password is set. The one-time code or a recovery\n" +
+				"code is prompted for if two-factor authentication is enabled and --otp-code is not set.",
+			Examples: []string{"login --username alice --password secret123 --server-url http://localhost:8080"},
+			Run:      printed(schemeCommand(runLoginHTTP, runLoginGRPC), withPrefix("Logged in. Token:")),
+		},
+		{
+			Name:     client.CommandOTPEnroll,
+			Summary:  "Start two-factor authentication enrolment",
+			Flags:    []string{"token", "server-url"},
+			Required: []string{"token", "server-url"},
+			Examples: []string{"otp-enroll --token <token> --server-url http://localhost:8080"},
+			Run:      printed(schemeCommand(runOTPEnrollHTTP, runOTPEnrollGRPC), printLine),
+		},
+		{
+			Name:     client.CommandOTPConfirm,
+			Summary:  "Enable two-factor authentication with a one-time code",
+			Flags:    []string{"token", "otp-code", "server-url"},
+			Required: []string{"token", "otp-code", "server-url"},
+			Examples: []string{"otp-confirm --token <token> --otp-code 123456 --server-url http://localhost:8080"},
+			Run: printed(schemeCommand(noResult(runOTPConfirmHTTP), noResult(runOTPConfirmGRPC)),
+				message("Two-factor authentication enabled.")),
+		},
+		{
+			Name:     client.CommandChangePassword,
+			Summary:  "Change the account password and sign out all sessions",
+			Flags:    []string{"token", "password", "new-password", "master-password", "server-url"},
+			Required: []string{"token", "password", "new-password", "server-url"},
+			Description: "The master password of accounts in master-password mode cannot be changed: it derives\n" +
+				"the key every secret key is wrapped with, and the server cannot re-wrap them. To move to a new\n" +
+				"master password, export --store server --backup-password, delete-account, register with the\n" +
+				"new master password and restore-backup --store server --backup-password. Shares and\n" +
+				"organization memberships of the old account are not restored.",
+			Examples: []string{`change-password --token <token> --password secret123 --new-password "n3w-Secret!" --server-url http://localhost:8080`},
+			Run: func(ctx context.Context, args []string) error {
+				if masterPassword != "" {
+					return errors.New("the master password cannot be changed; move the secrets to a new account " +
+						"with export and restore-backup --backup-password (see help change-password)")
+				}
+				return printed(schemeCommand(runChangePasswordHTTP, runChangePasswordGRPC), withPrefix("Password changed. Token:"))(ctx, args)
+			},
+		},
+		{
+			Name:        client.CommandDeleteAccount,
+			Summary:     "Delete the account and all its secrets on the server",
+			Flags:       []string{"token", "password", "master-password", "server-url"},
+			Required:    []string{"token", "server-url"},
+			Description: "--password is required unless --master-password is set.",
+			Examples:    []string{"delete-account --token <token> --password secret123 --server-url http://localhost:8080"},
+			Run: printed(schemeCommand(noResult(runDeleteAccountHTTP), noResult(runDeleteAccountGRPC)),
+				message("Account deleted.")),
+		},
+		{
+			Name:     client.CommandAddBankcard,
+			Summary:  "Add a new bankcard secret",
+			Flags:    flags([]string{"token", "secret-name", "number", "owner", "exp", "cvv", "meta"}, addFlags),
+			Required: []string{"token", "secret-name", "number", "owner", "exp", "cvv"},
+			Examples: []string{`add-bankcard --token <token> --secret-name "MyCard" --number 1234567890123456 --owner "Alice" --exp "12/24" --cvv 123 --meta "personal" --pubkey "<public_key_pem>"`},
+			Run:      func(ctx context.Context, _ []string) error { return runAddSecretBankcard(ctx) },
+		},
+		{
+			Name:     client.CommandAddText,
+			Summary:  "Add a new text secret",
+			Flags:    flags([]string{"token", "secret-name", "data", "meta"}, addFlags),
+			Required: []string{"token", "secret-name", "data"},
+			Examples: []string{`add-text --token <token> --secret-name "Note" --data "My secret note" --meta "work" --pubkey "<public_key_pem>"`},
+			Run:      func(ctx context.Context, _ []string) error { return runAddSecretText(ctx) },
+		},
+		{
+			Name:        client.CommandAddBinary,
+			Summary:     "Add a new binary secret",
+			Flags:       flags([]string{"token", "secret-name", "data", "meta"}, addFlags),
+			Required:    []string{"token", "secret-name", "data"},
+			Description: "--data is base64 encoded.",
+			Examples:    []string{`add-binary --token <token> --secret-name "File" --data "<base64_data>" --meta "backup" --pubkey "<public_key_pem>"`},
+			Run:         func(ctx context.Context, _ []string) error { return runAddSecretBinary(ctx) },
+		},
+		{
+			Name:    client.CommandAddUser,
+			Summary: "Add a new user secret",
+			Flags: flags([]string{"token", "secret-name", "username", "password", "totp", "url", "meta",
+				"generate", "length", "classes", "exclude-ambiguous", "words", "separator"}, addFlags),
+			Required: []string{"token", "secret-name", "username"},
+			Description: "--password is required unless --generate is set; see help generate for the policy flags.\n" +
+				"Saving over a stored login keeps the time its password was set when the password is unchanged;\n" +
+				"that needs --privkey, --master-password or the agent to decrypt it, otherwise the time is now.",
+			Examples: []string{
+				`add-user --token <token> --secret-name "EmailAccount" --username "user@example.com" --password "passw0rd" --meta "personal" --pubkey "<public_key_pem>"`,
+				`add-user --token <token> --secret-name "EmailAccount" --username "user@example.com" --generate --length 24 --exclude-ambiguous --pubkey "<public_key_pem>"`,
+				`add-user --token <token> --secret-name "GitHub" --username "alice" --password "passw0rd" --totp "otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP" --pubkey "<public_key_pem>"`,
+				`add-user --token <token> --secret-name "GitHub" --username "alice" --password "<personal_access_token>" --url https://github.com --pubkey "<public_key_pem>"`,
+			},
+			Run: func(ctx context.Context, _ []string) error { return runAddSecretUser(ctx) },
+		},
+		{
+			Name:     client.CommandAddSSHKey,
+			Summary:  "Add a new SSH key secret",
+			Flags:    flags([]string{"token", "secret-name", "ssh-private-key", "ssh-public-key", "comment", "passphrase", "meta"}, addFlags),
+			Required: []string{"token", "secret-name", "ssh-private-key"},
+			Description: "--passphrase is required when the private key is encrypted and is stored with it, so\n" +
+				"ssh-agent can use the key without asking. The public key is derived from the private key;\n" +
+				"a given --ssh-public-key must match it and lends its comment when --comment is omitted.",
+			Examples: []string{
+				`add-sshkey --token <token> --secret-name "laptop" --ssh-private-key "$(cat ~/.ssh/id_ed25519)" --ssh-public-key "$(cat ~/.ssh/id_ed25519.pub)" --pubkey "<public_key_pem>"`,
+			},
+			Run: func(ctx context.Context, _ []string) error { return runAddSecretSSHKey(ctx) },
+		},
+		{
+			Name:    client.CommandGenerate,
+			Summary: "Generate a password or a passphrase",
+			Flags:   []string{"length", "classes", "exclude-ambiguous", "words", "separator"},
+			Description: "Every class of --classes appears at least once; all classes are used by default.\n" +
+				"Randomness comes from crypto/rand. Passphrase words are drawn from the embedded\n" +
+				"2048-word BIP-39 English wordlist, 11 bits each. The estimated entropy is printed\n" +
+				"after the password.",
+			Examples: []string{
+				"generate --length 32 --classes lower,upper,digits",
+				`generate --words 6 --separator " "`,
+			},
+			Run: func(context.Context, []string) error {
+				generated, report, err := runGenerate()
+				if err != nil {
+					return err
+				}
+				fmt.Println(generated)
+				fmt.Println(report)
+				return nil
+			},
+		},
+		{
+			Name:     client.CommandImport,
+			Summary:  "Import secrets from another password manager",
+			Flags:    flags([]string{"token", "file", "from", "dry-run"}, encryptFlags),
+			Required: []string{"token", "file", "from"},
+			Description: "--pubkey is required unless --dry-run is set. Formats: keepass-xml (KeePass 2.x XML),\n" +
+				"bitwarden-json (unencrypted Bitwarden JSON), 1password-csv (1Password CSV) or csv.\n\n" +
+				"Logins become user secrets, cards bankcard secrets, notes text secrets and KeePass\n" +
+				"attachments binary secrets. Entries are named by their folder and title. Entries whose\n" +
+				"type and name match a secret in the local store are skipped as duplicates. The generic\n" +
+				"csv format has a header row naming the columns type, name, username, password, url,\n" +
+				"notes, number, owner, exp, cvv and data (base64 for binary); a missing type means user.\n" +
+				"Imported secrets are saved to the local store; run sync to upload them.",
+			Examples: []string{
+				"import --token <token> --from bitwarden-json --file bitwarden_export.json --dry-run",
+				`import --token <token> --from keepass-xml --file vault.xml --pubkey "<public_key_pem>"`,
+			},
+			Run: printed(runImport, printText),
+		},
+		{
+			Name:     client.CommandExport,
+			Summary:  "Write an encrypted backup archive of your secrets",
+			Flags:    flags([]string{"token", "file", "store", "backup-password", "privkey", "strict"}, encryptFlags),
+			Required: []string{"token", "file"},
+			Description: "The archive file must not exist. --pubkey wraps the archive key without --backup-password;\n" +
+				"with it --privkey decrypts the secrets. --server-url is required with --store server.\n\n" +
+				"The archive has a versioned header, one record per secret and an HMAC-SHA256 over both.\n" +
+				"Without --backup-password the records stay encrypted to your keys and the MAC key is\n" +
+				"wrapped with your certificate. With it every secret is re-encrypted to a key derived\n" +
+				"from the password with Argon2id, so the archive restores without your keys.",
+			Examples: []string{
+				`export --token <token> --file vault.gkb --pubkey "<public_key_pem>"`,
+				`export --token <token> --file vault.gkb --store server --backup-password "long backup passphrase" --privkey "<private_key_pem>" --server-url http://localhost:8080`,
+			},
+			Run: printed(runExport, printText),
+		},
+		{
+			Name:     client.CommandRestoreBackup,
+			Summary:  "Verify a backup archive and restore its secrets",
+			Flags:    flags([]string{"token", "file", "store", "backup-password", "privkey", "strict"}, encryptFlags),
+			Required: []string{"token", "file"},
+			Description: "--privkey unwraps the archive key without --backup-password; with it --pubkey encrypts\n" +
+				"the restored secrets. --server-url is required with --store server.\n\n" +
+				"The MAC is checked before anything is saved. Existing secrets of the same type and name\n" +
+				"are overwritten. Restored secrets keep their creation and update times. An archive\n" +
+				"without a password can only be restored by its owner.",
+			Examples: []string{
+				`restore-backup --token <token> --file vault.gkb --privkey "<private_key_pem>"`,
+				`restore-backup --token <token> --file vault.gkb --store server --backup-password "long backup passphrase" --pubkey "<public_key_pem>" --server-url http://localhost:8080`,
+			},
+			Run: printed(runRestoreBackup, printText),
+		},
+		{
+			Name:     client.CommandTUI,
+			Summary:  "Browse, add and edit secrets in an interactive terminal UI",
+			Flags:    flags([]string{"token", "privkey", "strict"}, encryptFlags),
+			Required: []string{"token"},
+			Description: "--pubkey is required to add and edit secrets; --server-url enables the sync panel.\n\n" +
+				"Browses the secrets of the local store. Keys: arrows or j/k move, enter opens a secret,\n" +
+				"a adds, e edits, s syncs local changes to the server, q quits. Sensitive fields such as\n" +
+				"card numbers, CVVs, passwords and data are masked until r (ctrl-r in forms) reveals them.\n" +
+				"Forms check card numbers and CVVs like add-bankcard.",
+			Examples: []string{`tui --token <token> --privkey "<private_key_pem>" --pubkey "<public_key_pem>" --server-url http://localhost:8080`},
+			Run:      func(ctx context.Context, _ []string) error { return runTUI(ctx) },
+		},
+		{
+			Name:     client.CommandShell,
+			Summary:  "Run commands in a session that unlocks the keys once",
+			Flags:    flags([]string{"token", "privkey", "strict"}, encryptFlags, []string{"idle-timeout"}),
+			Required: []string{"token"},
+			Description: "--pubkey is required to add secrets; --server-url enables the sync command and is\n" +
+				"required with --master-password.\n\n" +
+				"Keeps client.db and the server connection open and the keys unlocked for the session.\n" +
+				"Commands: help, list, show, add-bankcard, add-text, add-binary, add-user, generate,\n" +
+				"sync, lock and exit. Arguments with spaces are quoted; Tab completes commands, secret\n" +
+				"types and names, arrows recall earlier lines. After the idle timeout the shell asks for\n" +
+				"the master password, or the path of the private key file, before it goes on.",
+			Examples: []string{`shell --token <token> --privkey "<private_key_pem>" --pubkey "<public_key_pem>" --idle-timeout 10m`},
+			Run:      func(ctx context.Context, _ []string) error { return runShell(ctx) },
+		},
+		{
+			Name:    client.CommandAgent,
+			Summary: "Keep the private key unlocked in a background agent",
+			Flags:   flags([]string{"token", "ttl", "agent-socket", "foreground", "privkey-file"}, decryptFlags),
+			Args:    "[start|status|stop]",
+			Description: "start, the default, reads the private key from --privkey-file, or with --token and\n" +
+				"--server-url asks for the master password without echoing it, so neither shows in the\n" +
+				"process list or the shell history. --privkey and --master-password are accepted too.\n\n" +
+				"The agent keeps the key in memory for --ttl and answers only processes of the same user\n" +
+				"on a Unix socket private to them. It prints the shell commands setting\n" +
+				"$GOPHKEEPER_AGENT_SOCK, so start it with eval \"$(gophkeeper agent ...)\". While the variable\n" +
+				"is set, list, get, health and breach-check decrypt through the agent when neither --privkey\n" +
+				"nor --master-password is given.",
+			Examples: []string{
+				`eval "$(gophkeeper agent --privkey-file private.pem --ttl 8h)"`,
+				`eval "$(gophkeeper agent --token <token> --server-url http://localhost:8080)"`,
+				`agent status`,
+				`agent stop`,
+			},
+			Run: runAgent,
+		},
+		{
+			Name:     client.CommandSSHAgent,
+			Summary:  "Serve the SSH keys of the vault to ssh over the SSH agent protocol",
+			Flags:    flags([]string{"token", "ssh-agent-socket"}, decryptFlags),
+			Required: []string{"token"},
+			Description: "The sshkey secrets of the local store are decrypted once and served in the foreground until\n" +
+				"Ctrl-C; keys cannot be added or removed through the agent. Every signature is confirmed\n" +
+				"first: by $SSH_ASKPASS when it is set, like keys added with ssh-add -c, and on the terminal\n" +
+				"otherwise. The secrets are decrypted through the agent of the agent command when neither\n" +
+				"--privkey nor --master-password is given. Point ssh at the agent by setting $SSH_AUTH_SOCK\n" +
+				"to the printed socket in another shell.",
+			Examples: []string{
+				`ssh-agent --token <token> --privkey "$(cat private.pem)"`,
+				`SSH_ASKPASS=ssh-askpass ssh-agent --token <token> --ssh-agent-socket ~/.ssh/gophkeeper.sock`,
+			},
+			Run: func(ctx context.Context, _ []string) error { return runSSHAgent(ctx) },
+		},
+		{
+			Name:     client.CommandList,
+			Summary:  "List all secrets (requires private key for decryption)",
+			Flags:    flags([]string{"token"}, decryptFlags),
+			Required: []string{"token", "server-url"},
+			Examples: []string{`list --token <token> --privkey "<private_key_pem>" --server-url http://localhost:8080`},
+			Run:      printed(schemeCommand(runSecretListHTTP, runSecretListGRPC), printLine),
+		},
+		{
+			Name:     client.CommandGet,
+			Summary:  "Print or copy one field of a secret",
+			Flags:    flags([]string{"token", "secret-type", "secret-name", "field", "copy", "clear-after"}, decryptFlags),
+			Required: []string{"token", "secret-type", "secret-name", "server-url"},
+			Description: "--field defaults to the number of a bankcard, the data of a text, the password of a\n" +
+				"user secret and the public key of an sshkey, with its comment as in authorized_keys; totp\n" +
+				"prints the current one-time code of a user secret added with --totp.\n\n" +
+				"With --copy the field goes to the clipboard rather than to the terminal scrollback, through\n" +
+				"wl-copy, xclip or xsel, or the OSC 52 escape sequence of the terminal without them. The\n" +
+				"command then waits --clear-after and clears the clipboard unless something else was copied\n" +
+				"meanwhile; Ctrl-C clears it at once.",
+			Examples: []string{
+				`get --token <token> --secret-type user --secret-name "EmailAccount" --copy --privkey "<private_key_pem>" --server-url http://localhost:8080`,
+				`get --token <token> --secret-type user --secret-name "EmailAccount" --field totp --copy --clear-after 20s --privkey "<private_key_pem>" --server-url http://localhost:8080`,
+			},
+			Run: runGet,
+		},
+		{
+			Name:     client.CommandRun,
+			Summary:  "Run a command with secrets in its environment, or render a template",
+			Flags:    flags([]string{"token", "env", "template", "output"}, decryptFlags),
+			Required: []string{"token"},
+			Args:     "[-- command [args...]]",
+			Description: "Secrets are referenced as type/name#field, like user/db#password, and read from the local\n" +
+				"store; without #field the field get prints by default is used. Each --env sets a variable of\n" +
+				"the command only, so the secrets never touch the disk or the shell. SIGTERM, SIGHUP and\n" +
+				"SIGUSR1/2 are passed on to the command; Ctrl-C and Ctrl-\\ reach it from the terminal\n" +
+				"once and leave run waiting for it. Its exit status becomes the status of run.\n\n" +
+				"--template renders a text/template file where {{ secret \"user/db\" \"password\" }} or\n" +
+				"{{ secret \"user/db#password\" }} is replaced with the field, to --output or standard\n" +
+				"output; with a command too, the file is rendered before the command starts.",
+			Examples: []string{
+				`run --token <token> --privkey "$(cat private.pem)" --env PGPASSWORD=user/db#password --env PGUSER=user/db#username -- psql -h db.internal`,
+				`run --token <token> --template config.yaml.tmpl --output config.yaml -- ./server --config config.yaml`,
+			},
+			Run: runRun,
+		},
+		{
+			Name:    client.CommandGitCredential,
+			Summary: "Answer git as its credential helper from the user secrets of the vault",
+			Flags:   flags([]string{"token"}, encryptFlags, []string{"privkey", "strict"}),
+			Args:    "<get|store|erase>",
+			Description: "Speaks the git credential helper protocol on standard input and output. get prints the\n" +
+				"login of the user secret whose --url has the protocol and host of the request and the\n" +
+				"longest path leading its path; store saves the login git used, updating the matching\n" +
+				"secret or adding one named like alice@github.com; erase deletes the matching secret when\n" +
+				"it holds the rejected password. Changes go to the local store only, run sync to push them.\n\n" +
+				"Installed on the PATH as git-credential-gophkeeper, the binary runs this command, so\n" +
+				"git config --global credential.helper gophkeeper sets it up. --token and --pubkey default\n" +
+				"to $GOPHKEEPER_TOKEN and $GOPHKEEPER_PUBKEY and $GOPHKEEPER_DB names client.db, since git\n" +
+				"runs helpers without flags in the repository. Without --privkey and --master-password the\n" +
+				"secrets are decrypted through the agent of the agent command.",
+			Examples: []string{
+				`git-credential --token <token> --privkey "$(cat private.pem)" get <<< $'protocol=https\nhost=github.com\n'`,
+			},
+			Run: runGitCredential,
+		},
+		{
+			Name:    client.CommandDockerCredential,
+			Summary: "Answer docker as its credential helper from the user secrets of the vault",
+			Flags:   flags([]string{"token"}, encryptFlags, []string{"privkey", "strict"}),
+			Args:    "<get|store|erase|list>",
+			Description: "Speaks the docker credential helper protocol on standard input and output: get reads a\n" +
+				"registry and prints its login as JSON, store reads a login as JSON, erase reads a registry\n" +
+				"and deletes its login, and list prints the logins by registry. Registries are matched\n" +
+				"against the --url of user secrets like in git-credential, bare hosts taken for https.\n" +
+				"Like docker, store keeps one login per registry: a login as another user replaces the\n" +
+				"login saved for the same registry. Changes go to the local store only, run sync to push them.\n\n" +
+				"Installed on the PATH as docker-credential-gophkeeper, the binary runs this command, with\n" +
+				"the defaults of git-credential, for \"credsStore\": \"gophkeeper\" in ~/.docker/config.json.",
+			Examples: []string{
+				`docker-credential --token <token> --privkey "$(cat private.pem)" get <<< https://index.docker.io/v1/`,
+			},
+			Run: runDockerCredential,
+		},
+		{
+			Name:     client.CommandHealth,
+			Summary:  "Report weak, reused and old passwords and expiring bank cards",
+			Flags:    flags([]string{"token", "format", "min-entropy", "max-age-days", "expiry-days"}, decryptFlags),
+			Required: []string{"token", "server-url"},
+			Description: "Secrets are decrypted locally. Passwords are also flagged as weak when they are short,\n" +
+				`common, contain the username, repeat a character or follow a sequence such as "abcd" or "qwer".` + "\n" +
+				"A password is old when it was last set more than --max-age-days ago; logins saved before\n" +
+				"password change times were recorded use the time the secret was last saved.",
+			Examples: []string{`health --token <token> --privkey "<private_key_pem>" --format json --server-url http://localhost:8080`},
+			Run:      printed(schemeCommand(runHealthHTTP, runHealthGRPC), printText),
+		},
+		{
+			Name:     client.CommandBreachCheck,
+			Summary:  "Check stored passwords against a local breach dataset",
+			Flags:    flags([]string{"token", "dataset", "format"}, decryptFlags),
+			Required: []string{"token", "dataset"},
+			Description: "--dataset is a Pwned Passwords dataset in the range format: a directory of\n" +
+				"00000.txt .. FFFFF.txt files of SUFFIX:COUNT lines, or one file of HASH:COUNT lines.\n\n" +
+				"Runs offline: the passwords of the local store are hashed with SHA-1 and looked up\n" +
+				"in the dataset, only the range files of their hash prefixes are read.",
+			Examples: []string{`breach-check --token <token> --privkey "<private_key_pem>" --dataset ./pwnedpasswords`},
+			Run:      printed(runBreachCheck, printText),
+		},
+		{
+			Name:     client.CommandSync,
+			Summary:  "Synchronize secrets between client and server (requires private key)",
+			Flags:    flags([]string{"token", "sync-mode", "privkey", "strict"}, encryptFlags),
+			Required: []string{"token", "sync-mode", "server-url"},
+			Examples: []string{`sync --token <token> --sync-mode client --privkey "<private_key_pem>" --server-url http://localhost:8080`},
+			Run:      printed(schemeCommand(noResult(runSyncHTTP), noResult(runSyncGRPC)), func(struct{}) {}),
+		},
+		{
+			Name:     client.CommandRotateKeys,
+			Summary:  "Re-encrypt all secrets with a new certificate",
+			Flags:    flags([]string{"token", "new-pubkey", "recipient"}, decryptFlags),
+			Required: []string{"token", "new-pubkey", "server-url"},
+			Examples: []string{
+				`rotate-keys --token <token> --privkey "<old_private_key_pem>" --new-pubkey "<new_certificate_pem>" --server-url http://localhost:8080`,
+				`rotate-keys --token <token> --privkey "<laptop_private_key_pem>" --new-pubkey "<laptop_certificate_pem>" --recipient "<phone_certificate_pem>" --server-url http://localhost:8080`,
+			},
+			Run: printed(schemeCommand(runRotateKeysHTTP, runRotateKeysGRPC), printLine),
+		},
+		{
+			Name:     client.CommandUpgrade,
+			Summary:  "Reseal secrets stored in an older ciphertext format",
+			Flags:    flags([]string{"token"}, decryptFlags),
+			Required: []string{"token", "server-url"},
+			Examples: []string{`upgrade-secrets --token <token> --privkey "<private_key_pem>" --server-url http://localhost:8080`},
+			Run:      printed(schemeCommand(runUpgradeHTTP, runUpgradeGRPC), printLine),
+		},
+		{
+			Name:     client.CommandAudit,
+			Summary:  "Show the audit log of secret access and account events",
+			Flags:    []string{"token", "limit", "server-url"},
+			Required: []string{"token", "server-url"},
+			Examples: []string{"audit --token <token> --limit 20 --server-url http://localhost:8080"},
+			Run:      printed(schemeCommand(runAuditHTTP, runAuditGRPC), printText),
+		},
+		{
+			Name:     client.CommandPublishCert,
+			Summary:  "Publish your certificate so other users can share secrets with you",
+			Flags:    []string{"token", "pubkey", "server-url"},
+			Required: []string{"token", "pubkey", "server-url"},
+			Examples: []string{`publish-cert --token <token> --pubkey "<certificate_pem>" --server-url http://localhost:8080`},
+			Run:      printed(shareCommand(client.CommandPublishCert), printLine),
+		},
+		{
+			Name:     client.CommandShare,
+			Summary:  "Share a secret with another user",
+			Flags:    flags([]string{"token", "secret-type", "secret-name", "share-with", "permission"}, decryptFlags),
+			Required: []string{"token", "secret-type", "secret-name", "share-with", "server-url"},
+			Description: "The secret is read from the local store and must already be synced to the server.\n" +
+				"The recipient must have published a certificate. Share the secret again after rotating\n" +
+				"keys, or after re-adding it without --privkey or --master-password, which editing needs\n" +
+				"to keep its data key: the recipient's key is bound to the old one.",
+			Examples: []string{`share --token <token> --secret-type text --secret-name "Note" --share-with bob --permission rw --privkey "<private_key_pem>" --server-url http://localhost:8080`},
+			Run:      printed(shareCommand(client.CommandShare), printLine),
+		},
+		{
+			Name:     client.CommandUnshare,
+			Summary:  "Revoke access of another user to a secret",
+			Flags:    []string{"token", "secret-type", "secret-name", "share-with", "server-url"},
+			Required: []string{"token", "secret-type", "secret-name", "share-with", "server-url"},
+			Examples: []string{`unshare --token <token> --secret-type text --secret-name "Note" --share-with bob --server-url http://localhost:8080`},
+			Run:      printed(shareCommand(client.CommandUnshare), printLine),
+		},
+		{
+			Name:     client.CommandListShared,
+			Summary:  "List secrets other users share with you",
+			Flags:    flags([]string{"token"}, decryptFlags),
+			Required: []string{"token", "server-url"},
+			Examples: []string{`list-shared --token <token> --privkey "<private_key_pem>" --server-url http://localhost:8080`},
+			Run:      printed(shareCommand(client.CommandListShared), printLine),
+		},
+		{
+			Name:    client.CommandUpdateShared,
+			Summary: "Replace the content of a secret shared with you read-write",
+			Flags: flags([]string{"token", "secret-owner", "secret-type", "secret-name",
+				"number", "owner", "exp", "cvv", "data", "username", "password", "url",
+				"ssh-private-key", "ssh-public-key", "comment", "passphrase", "meta"}, decryptFlags),
+			Required: []string{"token", "secret-owner", "secret-type", "secret-name", "server-url"},
+			Description: "The content flags are those of the matching add command: --number, --owner, --exp, --cvv\n" +
+				"(bankcard), --data (text, binary), --username, --password, --url (user), --ssh-private-key,\n" +
+				"--ssh-public-key, --comment, --passphrase (sshkey) and --meta.",
+			Examples: []string{`update-shared --token <token> --secret-owner alice --secret-type text --secret-name "Note" --data "Updated note" --privkey "<private_key_pem>" --server-url http://localhost:8080`},
+			Run:      printed(shareCommand(client.CommandUpdateShared), printLine),
+		},
+		{
+			Name:    client.CommandVersion,
+			Summary: "Show version information",
+			Run: func(context.Context, []string) error {
+				fmt.Printf("Version: %s\nBuild Date: %s\n", buildVersion, buildDate)
+				return nil
+			},
+		},
+	}
+}
+
+// topics returns the help topics that apply to many commands.
+func topics() []*cli.Topic {
+	return []*cli.Topic{
+		{
+			Name:    "master-password",
+			Summary: "Accounts without PEM files, keyed by a master password",
+			Text: "Accounts registered with --master-password need no PEM files. The client derives a vault key\n" +
+				"and an authentication key from the master password with Argon2id and a salt kept on the server;\n" +
+				"the server stores only the KDF parameters and a hash of the authentication key, and the vault key\n" +
+				"never leaves the client. Pass --master-password in place of --password, --pubkey and --privkey\n" +
+				"to login, delete-account and every secret command; --server-url is then always required.\n" +
+				"--recipient still adds certificates, and shared secrets are wrapped for certificates as usual.\n" +
+				"The master password cannot be changed.\n\n" +
+				"Examples:\n" +
+				`  gophkeeper add-text --token <token> --secret-name "Note" --data "Hello" --master-password "correct horse battery" --server-url http://localhost:8080` + "\n" +
+				`  gophkeeper list --token <token> --master-password "correct horse battery" --server-url http://localhost:8080`,
+		},
+		{
+			Name:    "keys",
+			Summary: "Supported certificate and private key formats",
+			Text: "Certificates may hold RSA, ECDSA P-256 or Ed25519 keys; raw X25519 keys are passed\n" +
+				`as "PUBLIC KEY" PEM. Private keys are accepted as PKCS#1, SEC 1 or PKCS#8 PEM.` + "\n" +
+				"Secrets encrypted to RSA and EC keys can be mixed with --recipient and shared across them.",
+		},
+	}
+}
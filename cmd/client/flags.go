@@ -0,0 +1,312 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/agent"
+	"github.com/sbilibin2017/gophkeeper/internal/cli"
+	"github.com/sbilibin2017/gophkeeper/internal/client"
+	"github.com/sbilibin2017/gophkeeper/internal/clipboard"
+	"github.com/sbilibin2017/gophkeeper/internal/generator"
+	"github.com/sbilibin2017/gophkeeper/internal/health"
+	"github.com/sbilibin2017/gophkeeper/internal/importer"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/shell"
+)
+
+var (
+	serverURL string
+	pubKey    string
+	privKey   string
+	privFile  string
+	newPubKey string
+	token     string
+
+	recipients stringsFlag
+
+	secretType string
+	secretName string
+
+	number string
+	owner  string
+	exp    string
+	cvv    string
+
+	data string
+
+	sshPrivateKey string
+	sshPublicKey  string
+	sshComment    string
+	passphrase    string
+
+	username       string
+	password       string
+	masterPassword string
+	strict         bool
+	otpCode        string
+	totp           string
+	siteURL        string
+
+	newPassword string
+
+	meta string
+
+	field      string
+	copyField  bool
+	clearAfter time.Duration
+
+	envVars      stringsFlag
+	templateFile string
+	outputFile   string
+
+	syncMode string
+
+	limit int
+
+	generate         bool
+	length           int
+	classes          string
+	excludeAmbiguous bool
+	words            int
+	separator        string
+
+	format     string
+	minEntropy float64
+	maxAgeDays int
+	expiryDays int
+
+	dataset string
+
+	importFile   string
+	importFormat string
+	dryRun       bool
+
+	backupPassword string
+	store          string
+
+	idleTimeout time.Duration
+
+	agentSocket string
+	agentTTL    time.Duration
+	foreground  bool
+
+	sshAgentSocket string
+
+	shareWith   string
+	permission  string
+	secretOwner string
+)
+
+// flagDefs defines every flag of the client once; commands list the flags they accept by name.
+var flagDefs = map[string]cli.FlagDef{
+	"server-url": func(fs *flag.FlagSet) {
+		fs.StringVar(&serverURL, "server-url", "", "Server URL")
+	},
+	"pubkey": func(fs *flag.FlagSet) {
+		fs.StringVar(&pubKey, "pubkey", "", "Public key certificate PEM for encryption")
+	},
+	"privkey": func(fs *flag.FlagSet) {
+		fs.StringVar(&privKey, "privkey", "", "Private key PEM for decryption")
+	},
+	"privkey-file": func(fs *flag.FlagSet) {
+		fs.StringVar(&privFile, "privkey-file", "", "File with the private key PEM for decryption")
+	},
+	"new-pubkey": func(fs *flag.FlagSet) {
+		fs.StringVar(&newPubKey, "new-pubkey", "", "New public key certificate for key rotation")
+	},
+	"token": func(fs *flag.FlagSet) {
+		fs.StringVar(&token, "token", "", "Authentication token")
+	},
+	"recipient": func(fs *flag.FlagSet) {
+		fs.Var(&recipients, "recipient", "Additional recipient `certificate` PEM, may be repeated")
+	},
+
+	"secret-type": func(fs *flag.FlagSet) {
+		fs.StringVar(&secretType, "secret-type", "", "Type of secret: bankcard, text, binary, user, sshkey")
+	},
+	"secret-name": func(fs *flag.FlagSet) {
+		fs.StringVar(&secretName, "secret-name", "", "Secret name")
+	},
+
+	"number": func(fs *flag.FlagSet) {
+		fs.StringVar(&number, "number", "", "Bankcard number")
+	},
+	"owner": func(fs *flag.FlagSet) {
+		fs.StringVar(&owner, "owner", "", "Bankcard owner")
+	},
+	"exp": func(fs *flag.FlagSet) {
+		fs.StringVar(&exp, "exp", "", "Bankcard expiry date")
+	},
+	"cvv": func(fs *flag.FlagSet) {
+		fs.StringVar(&cvv, "cvv", "", "Bankcard CVV")
+	},
+
+	"data": func(fs *flag.FlagSet) {
+		fs.StringVar(&data, "data", "", "Text data, or base64 data of binary secrets")
+	},
+
+	"ssh-private-key": func(fs *flag.FlagSet) {
+		fs.StringVar(&sshPrivateKey, "ssh-private-key", "", "SSH private key, PEM or OpenSSH encoded")
+	},
+	"ssh-public-key": func(fs *flag.FlagSet) {
+		fs.StringVar(&sshPublicKey, "ssh-public-key", "", "SSH public key in the authorized_keys format; derived from the private key when omitted")
+	},
+	"comment": func(fs *flag.FlagSet) {
+		fs.StringVar(&sshComment, "comment", "", "Comment of the SSH key, the comment of --ssh-public-key by default")
+	},
+	"passphrase": func(fs *flag.FlagSet) {
+		fs.StringVar(&passphrase, "passphrase", "", "Passphrase of an encrypted SSH private key")
+	},
+
+	"username": func(fs *flag.FlagSet) {
+		fs.StringVar(&username, "username", "", "Username")
+	},
+	"password": func(fs *flag.FlagSet) {
+		fs.StringVar(&password, "password", "", "Password")
+	},
+	"master-password": func(fs *flag.FlagSet) {
+		fs.StringVar(&masterPassword, "master-password", "", "Master password; derives the vault key in place of --pubkey/--privkey")
+	},
+	"strict": func(fs *flag.FlagSet) {
+		fs.BoolVar(&strict, "strict", false, "Refuse secrets in the legacy ciphertext format; use once upgrade-secrets has run")
+	},
+	"otp-code": func(fs *flag.FlagSet) {
+		fs.StringVar(&otpCode, "otp-code", "", "One-time code for two-factor authentication")
+	},
+	"totp": func(fs *flag.FlagSet) {
+		fs.StringVar(&totp, "totp", "", "TOTP seed of the account, in base32 or as an otpauth:// URI")
+	},
+	"url": func(fs *flag.FlagSet) {
+		fs.StringVar(&siteURL, "url", "", "URL of the site of the login, matched by the git and docker credential helpers")
+	},
+	"new-password": func(fs *flag.FlagSet) {
+		fs.StringVar(&newPassword, "new-password", "", "New password")
+	},
+
+	"meta": func(fs *flag.FlagSet) {
+		fs.StringVar(&meta, "meta", "", "Optional meta")
+	},
+
+	"field": func(fs *flag.FlagSet) {
+		fs.StringVar(&field, "field", "", "Field of the secret: number, owner, exp, cvv, data, username, password, totp, url, public-key, private-key, comment, passphrase or meta")
+	},
+	"copy": func(fs *flag.FlagSet) {
+		fs.BoolVar(&copyField, "copy", false, "Copy the field to the clipboard instead of printing it")
+	},
+	"clear-after": func(fs *flag.FlagSet) {
+		fs.DurationVar(&clearAfter, "clear-after", clipboard.DefaultClearAfter, "Time after which a copied field is cleared from the clipboard; 0 keeps it")
+	},
+
+	"env": func(fs *flag.FlagSet) {
+		fs.Var(&envVars, "env", "Environment variable `NAME=type/name#field` of the command, may be repeated")
+	},
+	"template": func(fs *flag.FlagSet) {
+		fs.StringVar(&templateFile, "template", "", "Template file to render the secret references of")
+	},
+	"output": func(fs *flag.FlagSet) {
+		fs.StringVar(&outputFile, "output", "", "File to write the rendered template to, readable only by you; standard output by default")
+	},
+
+	"sync-mode": func(fs *flag.FlagSet) {
+		fs.StringVar(&syncMode, "sync-mode", "", "Sync mode: server, client, or interactive")
+	},
+
+	"limit": func(fs *flag.FlagSet) {
+		fs.IntVar(&limit, "limit", 0, "Maximum number of audit events, newest first")
+	},
+
+	"generate": func(fs *flag.FlagSet) {
+		fs.BoolVar(&generate, "generate", false, "Generate the password of a user secret")
+	},
+	"length": func(fs *flag.FlagSet) {
+		fs.IntVar(&length, "length", generator.DefaultLength, "Generated password length")
+	},
+	"classes": func(fs *flag.FlagSet) {
+		fs.StringVar(&classes, "classes", "", "Comma-separated character classes of generated passwords: lower, upper, digits, symbols")
+	},
+	"exclude-ambiguous": func(fs *flag.FlagSet) {
+		fs.BoolVar(&excludeAmbiguous, "exclude-ambiguous", false, "Exclude look-alike characters from generated passwords")
+	},
+	"words": func(fs *flag.FlagSet) {
+		fs.IntVar(&words, "words", 0, "Generate a passphrase of this many words instead of a password")
+	},
+	"separator": func(fs *flag.FlagSet) {
+		fs.StringVar(&separator, "separator", generator.DefaultSeparator, "Separator of passphrase words")
+	},
+
+	"format": func(fs *flag.FlagSet) {
+		fs.StringVar(&format, "format", client.FormatText, "Report format: text or json")
+	},
+	"min-entropy": func(fs *flag.FlagSet) {
+		fs.Float64Var(&minEntropy, "min-entropy", health.DefaultMinEntropy, "Estimated entropy in bits below which a password is weak")
+	},
+	"max-age-days": func(fs *flag.FlagSet) {
+		fs.IntVar(&maxAgeDays, "max-age-days", int(health.DefaultMaxAge.Hours()/24), "Age in days after which a password is old")
+	},
+	"expiry-days": func(fs *flag.FlagSet) {
+		fs.IntVar(&expiryDays, "expiry-days", int(health.DefaultExpiryWindow.Hours()/24), "Days before expiry a bank card is reported")
+	},
+
+	"dataset": func(fs *flag.FlagSet) {
+		fs.StringVar(&dataset, "dataset", "", "Pwned Passwords range directory or file for breach checks")
+	},
+
+	"file": func(fs *flag.FlagSet) {
+		fs.StringVar(&importFile, "file", "", "Export file to import, or backup archive of export and restore-backup")
+	},
+	"from": func(fs *flag.FlagSet) {
+		fs.StringVar(&importFormat, "from", "", "Format of the export file: "+strings.Join(importer.Formats(), ", "))
+	},
+	"dry-run": func(fs *flag.FlagSet) {
+		fs.BoolVar(&dryRun, "dry-run", false, "Report what would be imported without saving anything")
+	},
+
+	"backup-password": func(fs *flag.FlagSet) {
+		fs.StringVar(&backupPassword, "backup-password", "", "Password re-encrypting a backup archive to a key derived from it")
+	},
+	"store": func(fs *flag.FlagSet) {
+		fs.StringVar(&store, "store", client.StoreLocal, "Store of export and restore-backup: local or server")
+	},
+
+	"idle-timeout": func(fs *flag.FlagSet) {
+		fs.DurationVar(&idleTimeout, "idle-timeout", shell.DefaultIdleTimeout, "Inactivity after which the shell drops its keys; 0 never locks")
+	},
+
+	"agent-socket": func(fs *flag.FlagSet) {
+		fs.StringVar(&agentSocket, "agent-socket", "", "Unix socket of the agent, $GOPHKEEPER_AGENT_SOCK or a per-user path by default")
+	},
+	"ttl": func(fs *flag.FlagSet) {
+		fs.DurationVar(&agentTTL, "ttl", agent.DefaultTTL, "Time the agent keeps the key; 0 keeps it until the agent stops")
+	},
+	"foreground": func(fs *flag.FlagSet) {
+		fs.BoolVar(&foreground, "foreground", false, "Run the agent in the foreground instead of in the background")
+	},
+
+	"ssh-agent-socket": func(fs *flag.FlagSet) {
+		fs.StringVar(&sshAgentSocket, "ssh-agent-socket", "", "Unix socket of the SSH agent, a per-user path by default")
+	},
+
+	"share-with": func(fs *flag.FlagSet) {
+		fs.StringVar(&shareWith, "share-with", "", "User to share a secret with")
+	},
+	"permission": func(fs *flag.FlagSet) {
+		fs.StringVar(&permission, "permission", models.SharePermissionRead, "Share permission: ro or rw")
+	},
+	"secret-owner": func(fs *flag.FlagSet) {
+		fs.StringVar(&secretOwner, "secret-owner", "", "Owner of a shared secret")
+	},
+}
+
+// stringsFlag collects the values of a flag that may be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
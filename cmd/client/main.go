@@ -1,34 +1,64 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
-	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"time"
 
-	"github.com/pressly/goose"
+	"github.com/pressly/goose/v3"
+	"github.com/sbilibin2017/gophkeeper/internal/agent"
+	"github.com/sbilibin2017/gophkeeper/internal/breach"
 	"github.com/sbilibin2017/gophkeeper/internal/client"
+	"github.com/sbilibin2017/gophkeeper/internal/clipboard"
+	"github.com/sbilibin2017/gophkeeper/internal/credhelper"
 	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
 	"github.com/sbilibin2017/gophkeeper/internal/db"
 	"github.com/sbilibin2017/gophkeeper/internal/facades"
+	"github.com/sbilibin2017/gophkeeper/internal/generator"
+	"github.com/sbilibin2017/gophkeeper/internal/health"
+	"github.com/sbilibin2017/gophkeeper/internal/importer"
+	"github.com/sbilibin2017/gophkeeper/internal/inject"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	"github.com/sbilibin2017/gophkeeper/internal/repositories"
 	"github.com/sbilibin2017/gophkeeper/internal/scheme"
+	"github.com/sbilibin2017/gophkeeper/internal/shell"
+	"github.com/sbilibin2017/gophkeeper/internal/sshagent"
+	"github.com/sbilibin2017/gophkeeper/internal/terminal"
 	"github.com/sbilibin2017/gophkeeper/internal/transport/grpc"
 	"github.com/sbilibin2017/gophkeeper/internal/transport/http"
+	"github.com/sbilibin2017/gophkeeper/internal/tui"
 	"github.com/sbilibin2017/gophkeeper/internal/validators"
 	_ "modernc.org/sqlite"
 )
 
 func main() {
-	flag.Parse()
-	err := run(context.Background(), os.Args)
-	if err != nil {
-		help := client.GetHelp()
-		log.Print(help)
+	args := os.Args[1:]
+	// Installed under the names git and docker look helpers up by, the binary is the helper.
+	switch strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") {
+	case "git-credential-" + programName:
+		args = append([]string{client.CommandGitCredential}, args...)
+	case "docker-credential-" + programName:
+		args = append([]string{client.CommandDockerCredential}, args...)
+	}
+
+	if err := newApp().Run(context.Background(), args); err != nil {
+		// run exits with the status of its command, docker-credential with the status docker expects.
+		var exitErr *inject.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		log.Fatal(err)
 	}
 }
@@ -38,157 +68,160 @@ var (
 	buildDate    = "N/A"
 )
 
-var (
-	serverURL string
-	pubKey    string
-	privKey   string
-	token     string
-
-	secretType string
-	secretName string
-
-	number string
-	owner  string
-	exp    string
-	cvv    string
-
-	data string
-
-	username string
-	password string
-
-	meta string
-
-	syncMode string
-)
-
 const (
 	apiVersion          = "/api/v1"
 	databaseDriver      = "sqlite"
-	databaseDSN         = "client.db"
 	pathToMigrationsDir = "migrations"
 )
 
-func init() {
-	flag.StringVar(&serverURL, "server-url", "", "Server URL")
-	flag.StringVar(&pubKey, "pubkey", "", "Public key")
-	flag.StringVar(&privKey, "privkey", "", "Private key")
-	flag.StringVar(&token, "token", "", "Authentication token")
-
-	flag.StringVar(&secretType, "secret-type", "", "Type of secret: bankcard, text, binary, user")
-	flag.StringVar(&secretName, "secret-name", "", "Secret name")
-
-	flag.StringVar(&number, "number", "", "Bankcard number")
-	flag.StringVar(&owner, "owner", "", "Bankcard owner")
-	flag.StringVar(&exp, "exp", "", "Bankcard expiry date")
-	flag.StringVar(&cvv, "cvv", "", "Bankcard CVV")
-
-	flag.StringVar(&data, "data", "", "Text data")
-
-	flag.StringVar(&username, "username", "", "Username")
-	flag.StringVar(&password, "password", "", "Password")
+// Environment read by the credential helpers, which git and docker run without flags.
+const (
+	envToken    = "GOPHKEEPER_TOKEN"
+	envPubKey   = "GOPHKEEPER_PUBKEY"
+	envDatabase = "GOPHKEEPER_DB"
+)
 
-	flag.StringVar(&meta, "meta", "", "Optional meta")
+// databaseDSN is the local store: client.db in the working directory, or the file $GOPHKEEPER_DB names.
+var databaseDSN = cmp.Or(os.Getenv(envDatabase), "client.db")
 
-	flag.StringVar(&syncMode, "sync-mode", "", "Sync mode")
+// encryptorOpts configures a cryptor to encrypt to the certificate pubKeyPEM
+// and to every certificate passed with --recipient.
+func encryptorOpts(pubKeyPEM string) []cryptor.Opt {
+	opts := []cryptor.Opt{cryptor.WithPublicKeyPEM([]byte(pubKeyPEM))}
+	for _, r := range recipients {
+		opts = append(opts, cryptor.WithRecipientPEM([]byte(r)))
+	}
+	return opts
 }
 
-// run executes the client command specified in args.
-// It supports commands: register, login, add secrets (bankcard, text, binary, user),
-// synchronize secrets with the server, show version info, and help.
-// Depending on the command and server URL scheme (HTTP(S)/gRPC), it creates
-// appropriate connections and clients, handling encryption and retries.
-func run(ctx context.Context, args []string) error {
-	command := client.GetCommand(args)
-
-	schm := scheme.GetSchemeFromURL(serverURL)
-
-	switch command {
-
-	case client.CommandRegister:
-		switch schm {
-		case scheme.HTTP, scheme.HTTPS:
-			tk, err := runRegisterHTTP(ctx)
-			if err != nil {
-				return err
-			}
-			fmt.Println("Registered. Token:", tk)
-
-		case scheme.GRPC:
-			tk, err := runRegisterGRPC(ctx)
-			if err != nil {
-				return err
-			}
-			fmt.Println("Registered. Token:", tk)
-
-		default:
-			return errors.New("unsupported scheme")
+// cryptorFromFlags builds a cryptor with opts, or, when --master-password is set, with the vault key
+// derived from it and the certificates passed with --recipient, in which case opts are ignored.
+// With --strict the cryptor refuses legacy ciphertexts.
+func cryptorFromFlags(ctx context.Context, opts ...cryptor.Opt) (*cryptor.Cryptor, error) {
+	if masterPassword == "" {
+		if strict {
+			opts = append(opts, cryptor.WithStrict())
 		}
+		return cryptor.New(opts...)
+	}
 
-	case client.CommandAddBankcard:
-		return runAddSecretBankcard(ctx)
-
-	case client.CommandAddText:
-		return runAddSecretText(ctx)
-
-	case client.CommandAddBinary:
-		return runAddSecretBinary(ctx)
-
-	case client.CommandAddUser:
-		return runAddSecretUser(ctx)
+	keys, err := masterKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return vaultCryptor(keys)
+}
 
-	case client.CommandList:
-		switch schm {
-		case scheme.HTTP, scheme.HTTPS:
-			list, err := runSecretListHTTP(ctx)
-			if err != nil {
-				return err
-			}
-			fmt.Println(list)
+// decryptorFromFlags returns the cryptor of --privkey or --master-password, or, without both,
+// the agent listening on $GOPHKEEPER_AGENT_SOCK.
+func decryptorFromFlags(ctx context.Context) (client.Decryptor, error) {
+	if privKey == "" && masterPassword == "" {
+		if path := os.Getenv(agent.EnvSocket); path != "" {
+			return agent.NewClient(path), nil
+		}
+	}
+	return cryptorFromFlags(ctx, cryptor.WithPrivateKeyPEM([]byte(privKey)))
+}
 
-		case scheme.GRPC:
-			list, err := runSecretListGRPC(ctx)
-			if err != nil {
-				return err
-			}
-			fmt.Println(list)
+// vaultCryptor returns a cryptor of the vault key of keys that also encrypts to the --recipient certificates.
+func vaultCryptor(keys *cryptor.MasterKeys) (*cryptor.Cryptor, error) {
+	masterOpts := []cryptor.Opt{cryptor.WithVaultKey(keys.VaultKey)}
+	for _, r := range recipients {
+		masterOpts = append(masterOpts, cryptor.WithRecipientPEM([]byte(r)))
+	}
+	if strict {
+		masterOpts = append(masterOpts, cryptor.WithStrict())
+	}
+	return cryptor.New(masterOpts...)
+}
 
-		default:
-			return errors.New("unsupported scheme")
+// masterKeys derives the master keys of the token owner from --master-password,
+// fetching the KDF parameters from the server.
+func masterKeys(ctx context.Context) (*cryptor.MasterKeys, error) {
+	switch scheme.GetSchemeFromURL(serverURL) {
+	case scheme.HTTP, scheme.HTTPS:
+		httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+			Count:   3,
+			Wait:    1 * time.Second,
+			MaxWait: 5 * time.Second,
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize HTTP client: %w", err)
 		}
+		return client.ClientMasterKeys(ctx, facades.NewAuthHTTPFacade(httpClient), token, masterPassword)
+
+	case scheme.GRPC:
+		grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+			Count:   3,
+			Wait:    1 * time.Second,
+			MaxWait: 5 * time.Second,
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize gRPC client: %w", err)
+		}
+		defer grpcConn.Close()
+		return client.ClientMasterKeys(ctx, facades.NewAuthGRPCFacade(grpcConn), token, masterPassword)
 
-	case client.CommandSync:
-		switch schm {
-		case scheme.HTTP, scheme.HTTPS:
-			return runSyncHTTP(ctx)
-
-		case scheme.GRPC:
-			return runSyncGRPC(ctx)
+	default:
+		return nil, errors.New("unsupported scheme")
+	}
+}
 
-		default:
-			return errors.New("unsupported scheme")
+// validateRegistration checks the username and the password, or the master password when it is set.
+func validateRegistration() error {
+	if err := validators.ValidateUsername(username); err != nil {
+		return fmt.Errorf("invalid username: %w", err)
+	}
+	if masterPassword != "" {
+		if err := validators.ValidatePassword(masterPassword); err != nil {
+			return fmt.Errorf("invalid master password: %w", err)
 		}
-
-	case client.CommandVersion:
-		fmt.Printf("Version: %s\nBuild Date: %s\n", buildVersion, buildDate)
 		return nil
+	}
+	if err := validators.ValidatePassword(password); err != nil {
+		return fmt.Errorf("invalid password: %w", err)
+	}
+	return nil
+}
 
-	case client.CommandHelp:
-		fmt.Println(client.GetHelp())
-		return nil
+// registerUser registers in master-password mode when --master-password is set.
+func registerUser(ctx context.Context, registerer client.Registerer) (string, error) {
+	if masterPassword != "" {
+		return client.ClientRegisterMaster(ctx, registerer, username, masterPassword)
+	}
+	return client.ClientRegister(ctx, registerer, username, password)
+}
 
-	default:
-		return errors.New("unknown command")
+// authFacade combines the server calls used to log in.
+type authFacade interface {
+	client.Loginer
+	client.KDFParamsGetter
+}
+
+// loginUser logs in in master-password mode when --master-password is set.
+func loginUser(ctx context.Context, facade authFacade) (string, error) {
+	if masterPassword != "" {
+		return client.ClientLoginMaster(ctx, facade, facade, username, masterPassword, otpCode, os.Stdin)
 	}
-	return nil
+	return client.ClientLogin(ctx, facade, username, password, otpCode, os.Stdin)
 }
 
-func runRegisterHTTP(ctx context.Context) (string, error) {
-	if err := validators.ValidateUsername(username); err != nil {
-		return "", fmt.Errorf("invalid username: %w", err)
+// accountPassword returns the password confirming account changes: --password,
+// or the authentication key derived from --master-password.
+func accountPassword(ctx context.Context, getter client.KDFParamsGetter) (string, error) {
+	if masterPassword == "" {
+		return password, nil
 	}
-	if err := validators.ValidatePassword(password); err != nil {
-		return "", fmt.Errorf("invalid password: %w", err)
+	keys, err := client.ClientMasterKeys(ctx, getter, token, masterPassword)
+	if err != nil {
+		return "", err
+	}
+	return keys.AuthKey, nil
+}
+
+func runRegisterHTTP(ctx context.Context) (string, error) {
+	if err := validateRegistration(); err != nil {
+		return "", err
 	}
 
 	dbConn, err := db.New(
@@ -221,7 +254,7 @@ func runRegisterHTTP(ctx context.Context) (string, error) {
 	}
 	authFacade := facades.NewAuthHTTPFacade(httpClient)
 
-	tk, err := client.ClientRegister(ctx, authFacade, username, password)
+	tk, err := registerUser(ctx, authFacade)
 	if err != nil {
 		return "", err
 	}
@@ -230,11 +263,8 @@ func runRegisterHTTP(ctx context.Context) (string, error) {
 }
 
 func runRegisterGRPC(ctx context.Context) (string, error) {
-	if err := validators.ValidateUsername(username); err != nil {
-		return "", fmt.Errorf("invalid username: %w", err)
-	}
-	if err := validators.ValidatePassword(password); err != nil {
-		return "", fmt.Errorf("invalid password: %w", err)
+	if err := validateRegistration(); err != nil {
+		return "", err
 	}
 
 	dbConn, err := db.New(
@@ -269,7 +299,7 @@ func runRegisterGRPC(ctx context.Context) (string, error) {
 
 	authFacade := facades.NewAuthGRPCFacade(grpcConn)
 
-	tk, err := client.ClientRegister(ctx, authFacade, username, password)
+	tk, err := registerUser(ctx, authFacade)
 	if err != nil {
 		return "", err
 	}
@@ -277,6 +307,174 @@ func runRegisterGRPC(ctx context.Context) (string, error) {
 	return tk, nil
 }
 
+func runLoginHTTP(ctx context.Context) (string, error) {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	authFacade := facades.NewAuthHTTPFacade(httpClient)
+
+	return loginUser(ctx, authFacade)
+}
+
+func runLoginGRPC(ctx context.Context) (string, error) {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	defer grpcConn.Close()
+
+	authFacade := facades.NewAuthGRPCFacade(grpcConn)
+
+	return loginUser(ctx, authFacade)
+}
+
+func runOTPEnrollHTTP(ctx context.Context) (string, error) {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	authFacade := facades.NewAuthHTTPFacade(httpClient)
+
+	return client.ClientEnrollOTP(ctx, authFacade, token)
+}
+
+func runOTPEnrollGRPC(ctx context.Context) (string, error) {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	defer grpcConn.Close()
+
+	authFacade := facades.NewAuthGRPCFacade(grpcConn)
+
+	return client.ClientEnrollOTP(ctx, authFacade, token)
+}
+
+func runOTPConfirmHTTP(ctx context.Context) error {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return err
+	}
+	authFacade := facades.NewAuthHTTPFacade(httpClient)
+
+	return client.ClientConfirmOTP(ctx, authFacade, token, otpCode)
+}
+
+func runOTPConfirmGRPC(ctx context.Context) error {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return err
+	}
+	defer grpcConn.Close()
+
+	authFacade := facades.NewAuthGRPCFacade(grpcConn)
+
+	return client.ClientConfirmOTP(ctx, authFacade, token, otpCode)
+}
+
+func runChangePasswordHTTP(ctx context.Context) (string, error) {
+	if err := validators.ValidatePassword(newPassword); err != nil {
+		return "", fmt.Errorf("invalid new password: %w", err)
+	}
+
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	authFacade := facades.NewAuthHTTPFacade(httpClient)
+
+	return client.ClientChangePassword(ctx, authFacade, token, password, newPassword, os.Stdin)
+}
+
+func runChangePasswordGRPC(ctx context.Context) (string, error) {
+	if err := validators.ValidatePassword(newPassword); err != nil {
+		return "", fmt.Errorf("invalid new password: %w", err)
+	}
+
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	defer grpcConn.Close()
+
+	authFacade := facades.NewAuthGRPCFacade(grpcConn)
+
+	return client.ClientChangePassword(ctx, authFacade, token, password, newPassword, os.Stdin)
+}
+
+func runDeleteAccountHTTP(ctx context.Context) error {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return err
+	}
+	authFacade := facades.NewAuthHTTPFacade(httpClient)
+
+	accountPass, err := accountPassword(ctx, authFacade)
+	if err != nil {
+		return err
+	}
+
+	return client.ClientDeleteAccount(ctx, authFacade, token, accountPass, os.Stdin)
+}
+
+func runDeleteAccountGRPC(ctx context.Context) error {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return err
+	}
+	defer grpcConn.Close()
+
+	authFacade := facades.NewAuthGRPCFacade(grpcConn)
+
+	accountPass, err := accountPassword(ctx, authFacade)
+	if err != nil {
+		return err
+	}
+
+	return client.ClientDeleteAccount(ctx, authFacade, token, accountPass, os.Stdin)
+}
+
 func runAddSecretBankcard(ctx context.Context) error {
 	if err := validators.ValidateLuhn(number); err != nil {
 		return fmt.Errorf("invalid card number: %w", err)
@@ -297,16 +495,16 @@ func runAddSecretBankcard(ctx context.Context) error {
 	}
 	defer dbConn.Close()
 
+	clientReader := repositories.NewSecretReadRepository(dbConn)
 	clientWriter := repositories.NewSecretWriteRepository(dbConn)
 
-	cryptorInst, err := cryptor.New(
-		cryptor.WithPublicKeyPEM([]byte(pubKey)),
-	)
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
 	if err != nil {
 		return fmt.Errorf("cryptor setup failed: %w", err)
 	}
+	encryptor := client.StoredKeyEncryptor(ctx, clientReader, cryptorInst, token)
 
-	return client.ClientAddBankcard(ctx, clientWriter, cryptorInst, token, secretName, number, owner, exp, cvv, meta)
+	return client.ClientAddBankcard(ctx, clientWriter, encryptor, token, secretName, number, owner, exp, cvv, meta)
 }
 
 func runAddSecretText(ctx context.Context) error {
@@ -322,16 +520,16 @@ func runAddSecretText(ctx context.Context) error {
 	}
 	defer dbConn.Close()
 
+	clientReader := repositories.NewSecretReadRepository(dbConn)
 	clientWriter := repositories.NewSecretWriteRepository(dbConn)
 
-	cryptorInst, err := cryptor.New(
-		cryptor.WithPublicKeyPEM([]byte(pubKey)),
-	)
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
 	if err != nil {
 		return fmt.Errorf("cryptor setup failed: %w", err)
 	}
+	encryptor := client.StoredKeyEncryptor(ctx, clientReader, cryptorInst, token)
 
-	return client.ClientAddText(ctx, clientWriter, cryptorInst, token, secretName, data, meta)
+	return client.ClientAddText(ctx, clientWriter, encryptor, token, secretName, data, meta)
 }
 
 func runAddSecretBinary(ctx context.Context) error {
@@ -347,21 +545,34 @@ func runAddSecretBinary(ctx context.Context) error {
 	}
 	defer dbConn.Close()
 
+	clientReader := repositories.NewSecretReadRepository(dbConn)
 	clientWriter := repositories.NewSecretWriteRepository(dbConn)
 
-	cryptorInst, err := cryptor.New(
-		cryptor.WithPublicKeyPEM([]byte(pubKey)),
-	)
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
 	if err != nil {
 		return fmt.Errorf("cryptor setup failed: %w", err)
 	}
+	encryptor := client.StoredKeyEncryptor(ctx, clientReader, cryptorInst, token)
 
 	encodedData := base64.StdEncoding.EncodeToString([]byte(data))
 
-	return client.ClientAddBinary(ctx, clientWriter, cryptorInst, token, secretName, encodedData, meta)
+	return client.ClientAddBinary(ctx, clientWriter, encryptor, token, secretName, encodedData, meta)
 }
 
 func runAddSecretUser(ctx context.Context) error {
+	if generate {
+		if password != "" {
+			return errors.New("--password and --generate are mutually exclusive")
+		}
+		generated, report, err := runGenerate()
+		if err != nil {
+			return err
+		}
+		password = generated
+		fmt.Println("Generated password:", generated)
+		fmt.Println(report)
+	}
+
 	dbConn, err := db.New(
 		databaseDriver,
 		databaseDSN,
@@ -374,16 +585,891 @@ func runAddSecretUser(ctx context.Context) error {
 	}
 	defer dbConn.Close()
 
+	clientReader := repositories.NewSecretReadRepository(dbConn)
 	clientWriter := repositories.NewSecretWriteRepository(dbConn)
 
-	cryptorInst, err := cryptor.New(
-		cryptor.WithPublicKeyPEM([]byte(pubKey)),
-	)
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
 	if err != nil {
 		return fmt.Errorf("cryptor setup failed: %w", err)
 	}
+	encryptor := client.StoredKeyEncryptor(ctx, clientReader, cryptorInst, token)
+
+	prev, err := storedLogin(ctx, clientReader)
+	if err != nil {
+		return err
+	}
 
-	return client.ClientAddUser(ctx, clientWriter, cryptorInst, token, secretName, username, password, meta)
+	return client.ClientAddUser(ctx, clientWriter, encryptor, token, secretName, username, password, totp, siteURL, meta, prev, time.Now())
+}
+
+// storedLogin returns the stored login --secret-name replaces, so that it keeps the time its
+// password was set when the password is unchanged. It returns nil when there is no key to
+// decrypt it with, --privkey, --master-password or the agent.
+func storedLogin(ctx context.Context, reader client.ServerGetter) (*models.UserPayload, error) {
+	if privKey == "" && masterPassword == "" && os.Getenv(agent.EnvSocket) == "" {
+		return nil, nil
+	}
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cryptor setup failed: %w", err)
+	}
+	return client.ClientGetUser(ctx, reader, decryptor, token, secretName)
+}
+
+func runAddSecretSSHKey(ctx context.Context) error {
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
+	clientWriter := repositories.NewSecretWriteRepository(dbConn)
+
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
+	if err != nil {
+		return fmt.Errorf("cryptor setup failed: %w", err)
+	}
+	encryptor := client.StoredKeyEncryptor(ctx, clientReader, cryptorInst, token)
+
+	return client.ClientAddSSHKey(ctx, clientWriter, encryptor, token, secretName, sshPrivateKey, sshPublicKey, sshComment, passphrase, meta)
+}
+
+// runImport parses the export file and imports its secrets into the local store.
+func runImport(ctx context.Context) (string, error) {
+	parser, err := importer.Get(importFormat)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(importFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to open export: %w", err)
+	}
+	defer f.Close()
+
+	items, err := parser.Parse(f)
+	if err != nil {
+		return "", err
+	}
+
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	if err := goose.SetDialect("sqlite"); err != nil {
+		return "", fmt.Errorf("failed to set goose dialect: %w", err)
+	}
+
+	if err := goose.Up(dbConn.DB, pathToMigrationsDir); err != nil {
+		return "", fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
+	clientWriter := repositories.NewSecretWriteRepository(dbConn)
+
+	var cryptorInst *cryptor.Cryptor
+	if !dryRun {
+		cryptorInst, err = cryptorFromFlags(ctx, encryptorOpts(pubKey)...)
+		if err != nil {
+			return "", fmt.Errorf("cryptor setup failed: %w", err)
+		}
+	}
+
+	return client.ClientImport(ctx, clientReader, clientWriter, cryptorInst, token, items, dryRun, time.Now())
+}
+
+// runTUI runs the terminal UI on the local store, syncing with the server when --server-url is set.
+func runTUI(ctx context.Context) error {
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	if err := goose.SetDialect("sqlite"); err != nil {
+		return fmt.Errorf("failed to set goose dialect: %w", err)
+	}
+
+	if err := goose.Up(dbConn.DB, pathToMigrationsDir); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
+	clientWriter := repositories.NewSecretWriteRepository(dbConn)
+
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
+	if err != nil {
+		return fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	opts := []tui.Opt{
+		tui.WithStore(clientReader, clientWriter),
+		tui.WithCryptor(client.StoredKeyEncryptor(ctx, clientReader, cryptorInst, token), cryptorInst),
+	}
+
+	switch scheme.GetSchemeFromURL(serverURL) {
+	case scheme.HTTP, scheme.HTTPS:
+		httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+			Count:   3,
+			Wait:    1 * time.Second,
+			MaxWait: 5 * time.Second,
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to initialize HTTP client: %w", err)
+		}
+		serverGetter := facades.NewSecretReaderHTTP(httpClient)
+		serverSaver := facades.NewSecretWriterHTTP(httpClient)
+		opts = append(opts, tui.WithSync(serverURL, func(ctx context.Context) error {
+			return client.ClientSyncClient(ctx, clientReader, serverGetter, serverSaver, token)
+		}))
+
+	case scheme.GRPC:
+		grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+			Count:   3,
+			Wait:    1 * time.Second,
+			MaxWait: 5 * time.Second,
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to initialize gRPC client: %w", err)
+		}
+		defer grpcConn.Close()
+		serverGetter := facades.NewSecretReaderGRPC(grpcConn)
+		serverSaver := facades.NewSecretWriterGRPC(grpcConn)
+		opts = append(opts, tui.WithSync(serverURL, func(ctx context.Context) error {
+			return client.ClientSyncClient(ctx, clientReader, serverGetter, serverSaver, token)
+		}))
+	}
+
+	return tui.Run(ctx, tui.New(token, opts...), os.Stdin, os.Stdout)
+}
+
+// runShell runs the interactive shell on the local store. The database and the connection to
+// --server-url stay open for the whole session, and the keys are unlocked once: from
+// --master-password, or from --pubkey/--privkey. After --idle-timeout without input the keys
+// are dropped and the shell asks for the master password or the private key file again.
+func runShell(ctx context.Context) error {
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	if err := goose.SetDialect("sqlite"); err != nil {
+		return fmt.Errorf("failed to set goose dialect: %w", err)
+	}
+
+	if err := goose.Up(dbConn.DB, pathToMigrationsDir); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
+	clientWriter := repositories.NewSecretWriteRepository(dbConn)
+
+	opts := []shell.Opt{
+		shell.WithStore(clientReader, clientWriter),
+		shell.WithIdleTimeout(idleTimeout),
+	}
+
+	var kdfGetter client.KDFParamsGetter
+	switch scheme.GetSchemeFromURL(serverURL) {
+	case scheme.HTTP, scheme.HTTPS:
+		httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+			Count:   3,
+			Wait:    1 * time.Second,
+			MaxWait: 5 * time.Second,
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to initialize HTTP client: %w", err)
+		}
+		kdfGetter = facades.NewAuthHTTPFacade(httpClient)
+		serverGetter := facades.NewSecretReaderHTTP(httpClient)
+		serverSaver := facades.NewSecretWriterHTTP(httpClient)
+		opts = append(opts, shell.WithSync(func(ctx context.Context) error {
+			return client.ClientSyncClient(ctx, clientReader, serverGetter, serverSaver, token)
+		}))
+
+	case scheme.GRPC:
+		grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+			Count:   3,
+			Wait:    1 * time.Second,
+			MaxWait: 5 * time.Second,
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to initialize gRPC client: %w", err)
+		}
+		defer grpcConn.Close()
+		kdfGetter = facades.NewAuthGRPCFacade(grpcConn)
+		serverGetter := facades.NewSecretReaderGRPC(grpcConn)
+		serverSaver := facades.NewSecretWriterGRPC(grpcConn)
+		opts = append(opts, shell.WithSync(func(ctx context.Context) error {
+			return client.ClientSyncClient(ctx, clientReader, serverGetter, serverSaver, token)
+		}))
+	}
+
+	var unlock shell.UnlockFunc
+	if masterPassword != "" {
+		if kdfGetter == nil {
+			return errors.New("--master-password needs --server-url to fetch the KDF parameters")
+		}
+		unlock = func(ctx context.Context, secret string) (shell.Keys, error) {
+			keys, err := client.ClientMasterKeys(ctx, kdfGetter, token, secret)
+			if err != nil {
+				return nil, err
+			}
+			return vaultCryptor(keys)
+		}
+		opts = append(opts, shell.WithUnlock("Master password: ", true, unlock))
+	} else {
+		unlock = func(_ context.Context, path string) (shell.Keys, error) {
+			privKeyPEM, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			return keyPairCryptor(privKeyPEM)
+		}
+		opts = append(opts, shell.WithUnlock("Private key file: ", false, unlock))
+	}
+
+	var keys shell.Keys
+	if masterPassword != "" {
+		keys, err = unlock(ctx, masterPassword)
+	} else {
+		keys, err = cryptor.New(keyOpts()...)
+	}
+	if err != nil {
+		return fmt.Errorf("cryptor setup failed: %w", err)
+	}
+	// The shell holds the only copy of the keys, so that locking drops them.
+	masterPassword, privKey = "", ""
+
+	return shell.New(token, append(opts, shell.WithKeys(keys))...).Run(ctx, os.Stdin, os.Stdout)
+}
+
+// Actions of the agent command.
+const (
+	agentStart  = "start"
+	agentStatus = "status"
+	agentStop   = "stop"
+)
+
+// runAgent starts the agent holding the key of --privkey or --master-password in the background
+// and prints the shell commands pointing later commands at it; status and stop ask the running
+// agent. With --foreground the command serves itself.
+func runAgent(ctx context.Context, args []string) error {
+	action := agentStart
+	if len(args) > 0 {
+		action = args[0]
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(args[1:], " "))
+	}
+
+	path := agentSocket
+	if path == "" {
+		path = os.Getenv(agent.EnvSocket)
+	}
+	if path == "" {
+		path = agent.DefaultSocketPath()
+	}
+
+	switch action {
+	case agentStatus:
+		status, err := agent.NewClient(path).Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Agent pid %d on %s\n", status.PID, path)
+		if status.KeyID != "" {
+			fmt.Println("Key:", status.KeyID)
+		}
+		if status.Expires.IsZero() {
+			fmt.Println("The key is kept until the agent stops")
+		} else {
+			fmt.Printf("The key expires in %s\n", time.Until(status.Expires).Round(time.Second))
+		}
+		return nil
+
+	case agentStop:
+		if err := agent.NewClient(path).Stop(ctx); err != nil {
+			return err
+		}
+		fmt.Println("Agent stopped")
+		return nil
+
+	case agentStart:
+	default:
+		return fmt.Errorf("unknown agent action %q, use start, status or stop", action)
+	}
+
+	keys, err := agentKeys(ctx)
+	if err != nil {
+		return err
+	}
+	decryptor, err := keys.Cryptor()
+	if err != nil {
+		return fmt.Errorf("cryptor setup failed: %w", err)
+	}
+	masterPassword, privKey, privFile = "", "", ""
+
+	if !foreground {
+		if _, err := agent.NewClient(path).Status(ctx); err == nil {
+			return fmt.Errorf("%w on %s", agent.ErrRunning, path)
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		exports, err := agent.Spawn(exe, []string{
+			client.CommandAgent, "--foreground", "--agent-socket", path, "--ttl", agentTTL.String(),
+		}, keys)
+		if err != nil {
+			return err
+		}
+		fmt.Println(exports)
+		return nil
+	}
+
+	l, err := agent.Listen(path)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// The first line tells Spawn that the agent is ready; eval-ing it sets up the shell.
+	fmt.Printf("%s=%s; export %s; echo Agent pid %d;\n", agent.EnvSocket, shellQuote(path), agent.EnvSocket, os.Getpid())
+	return agent.NewServer(decryptor, agent.WithTTL(agentTTL)).Serve(ctx, l)
+}
+
+// agentKeys returns the key of --privkey-file, --privkey or --master-password. Without them, an
+// agent in the foreground reads the key from standard input, where the background agent gets it
+// from Spawn, and otherwise the master password is asked for on the terminal.
+func agentKeys(ctx context.Context) (agent.Keys, error) {
+	switch {
+	case privFile != "":
+		pem, err := os.ReadFile(privFile)
+		if err != nil {
+			return agent.Keys{}, fmt.Errorf("failed to read the private key: %w", err)
+		}
+		return agent.Keys{PrivateKeyPEM: pem, Strict: strict}, nil
+	case privKey != "":
+		return agent.Keys{PrivateKeyPEM: []byte(privKey), Strict: strict}, nil
+	case foreground && masterPassword == "":
+		return agent.ReadKeys(os.Stdin)
+	case masterPassword == "" && token != "" && terminal.IsTerminal(int(os.Stdin.Fd())):
+		// Standard output is read by eval, the prompt goes to standard error.
+		password, err := terminal.ReadPassword(os.Stdin, os.Stderr, "Master password: ")
+		if err != nil {
+			return agent.Keys{}, err
+		}
+		masterPassword = password
+	}
+	if masterPassword == "" {
+		return agent.Keys{}, errors.New("--privkey-file, --privkey or --master-password is required")
+	}
+	keys, err := masterKeys(ctx)
+	if err != nil {
+		return agent.Keys{}, err
+	}
+	return agent.Keys{VaultKey: keys.VaultKey, Strict: strict}, nil
+}
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runRun renders --template and runs the command of args with the secrets of --env in its environment.
+func runRun(ctx context.Context, args []string) error {
+	if len(args) == 0 && templateFile == "" {
+		return errors.New("a command after -- or --template is required")
+	}
+	if len(args) == 0 && len(envVars) > 0 {
+		return errors.New("--env needs a command after --")
+	}
+
+	var env []string
+	err := withSecretResolver(ctx, func(resolver inject.Resolver) error {
+		if templateFile != "" {
+			if err := renderTemplate(ctx, resolver); err != nil {
+				return err
+			}
+		}
+		var err error
+		env, err = inject.Environ(ctx, resolver, envVars)
+		return err
+	})
+	if err != nil || len(args) == 0 {
+		return err
+	}
+
+	return inject.Run(inject.Command{
+		Name:   args[0],
+		Args:   args[1:],
+		Env:    env,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	})
+}
+
+// withSecretResolver calls fn with a resolver of the secret fields of the local store.
+func withSecretResolver(ctx context.Context, fn func(inject.Resolver) error) error {
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
+
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	return fn(inject.ResolverFunc(func(ctx context.Context, ref inject.Ref) (string, error) {
+		return client.ClientGetField(ctx, clientReader, decryptor, token, ref.Type, ref.Name, ref.Field, time.Now())
+	}))
+}
+
+// renderTemplate renders --template to --output, or to standard output without it.
+func renderTemplate(ctx context.Context, resolver inject.Resolver) error {
+	text, err := os.ReadFile(templateFile)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+	if outputFile == "" {
+		return inject.Render(ctx, resolver, filepath.Base(templateFile), string(text), os.Stdout)
+	}
+
+	// Rendered into memory first, so a failed reference leaves no partial file behind.
+	var out bytes.Buffer
+	if err := inject.Render(ctx, resolver, filepath.Base(templateFile), string(text), &out); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(outputFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+	// An existing file keeps its mode on open.
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to restrict output: %w", err)
+	}
+	if _, err := f.Write(out.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return f.Close()
+}
+
+// runGitCredential answers git as its credential helper.
+func runGitCredential(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: git-credential <get|store|erase>")
+	}
+	return withVaultLogins(ctx, func(store credhelper.Store) error {
+		return credhelper.Git(ctx, store, args[0], os.Stdin, os.Stdout)
+	})
+}
+
+// runDockerCredential answers docker as its credential helper. Docker reads the errors of its
+// helpers from standard output and only needs the exit status to be non-zero.
+func runDockerCredential(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: docker-credential <get|store|erase|list>")
+	}
+	err := withVaultLogins(ctx, func(store credhelper.Store) error {
+		return credhelper.Docker(ctx, store, args[0], os.Stdin, os.Stdout)
+	})
+	if err != nil {
+		fmt.Println(err)
+		return &inject.ExitError{Code: 1}
+	}
+	return nil
+}
+
+// withVaultLogins calls fn with the logins of the local store, taking --token and --pubkey from
+// $GOPHKEEPER_TOKEN and $GOPHKEEPER_PUBKEY when they are not given.
+func withVaultLogins(ctx context.Context, fn func(credhelper.Store) error) error {
+	token = cmp.Or(token, os.Getenv(envToken))
+	pubKey = cmp.Or(pubKey, os.Getenv(envPubKey))
+	if token == "" {
+		return fmt.Errorf("--token or $%s is required", envToken)
+	}
+
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	return fn(&vaultLogins{
+		reader: repositories.NewSecretReadRepository(dbConn),
+		writer: repositories.NewSecretWriteRepository(dbConn),
+		users:  make(map[string]models.UserPayload),
+	})
+}
+
+// vaultLogins is the credhelper.Store of the user secrets of the local store.
+type vaultLogins struct {
+	reader *repositories.SecretReadRepository
+	writer *repositories.SecretWriteRepository
+	// users holds the listed payloads, so that a saved login keeps its TOTP seed and meta.
+	users map[string]models.UserPayload
+}
+
+func (v *vaultLogins) List(ctx context.Context) ([]credhelper.Credential, error) {
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cryptor setup failed: %w", err)
+	}
+	secrets, err := client.ClientGetSecrets(ctx, v.reader, decryptor, token)
+	if err != nil {
+		return nil, err
+	}
+	var creds []credhelper.Credential
+	for _, secret := range secrets {
+		payload, ok := secret.Payload.(models.UserPayload)
+		if !ok {
+			continue
+		}
+		v.users[secret.SecretName] = payload
+		creds = append(creds, credhelper.Credential{
+			Name:     secret.SecretName,
+			URL:      payload.URL,
+			Username: payload.Username,
+			Password: payload.Password,
+		})
+	}
+	return creds, nil
+}
+
+func (v *vaultLogins) Save(ctx context.Context, c credhelper.Credential) error {
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
+	if err != nil {
+		return fmt.Errorf("cryptor setup failed: %w", err)
+	}
+	encryptor := client.StoredKeyEncryptor(ctx, v.reader, cryptorInst, token)
+	var stored *models.UserPayload
+	prev, ok := v.users[c.Name]
+	if ok {
+		stored = &prev
+	}
+	var prevMeta string
+	if prev.Meta != nil {
+		prevMeta = *prev.Meta
+	}
+	return client.ClientAddUser(ctx, v.writer, encryptor, token, c.Name, c.Username, c.Password, prev.TOTP, c.URL, prevMeta, stored, time.Now())
+}
+
+func (v *vaultLogins) Delete(ctx context.Context, name string) error {
+	return client.ClientDeleteSecret(ctx, v.writer, token, models.SecretTypeUser, name)
+}
+
+// runSSHAgent serves the sshkey secrets of the local store over the SSH agent protocol until interrupted.
+func runSSHAgent(ctx context.Context) error {
+	confirm, err := sshConfirmer()
+	if err != nil {
+		return err
+	}
+
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return fmt.Errorf("cryptor setup failed: %w", err)
+	}
+	secrets, err := client.ClientGetSecrets(ctx, repositories.NewSecretReadRepository(dbConn), decryptor, token)
+	if err != nil {
+		return err
+	}
+	var keys []*sshagent.Key
+	for _, secret := range secrets {
+		payload, ok := secret.Payload.(models.SSHKeyPayload)
+		if !ok {
+			continue
+		}
+		key, err := sshagent.ParseKey(secret.SecretName, payload)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return errors.New("no sshkey secrets to serve")
+	}
+
+	path := sshAgentSocket
+	if path == "" {
+		path = sshagent.DefaultSocketPath()
+	}
+	l, err := agent.Listen(path)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("%s=%s; export %s;\n", sshagent.EnvSocket, shellQuote(path), sshagent.EnvSocket)
+	fmt.Fprintf(os.Stderr, "Serving %d SSH keys, Ctrl-C stops the agent\n", len(keys))
+	return sshagent.NewKeyring(keys, confirm).Serve(ctx, l)
+}
+
+// sshConfirmer returns the confirmer of SSH key use: $SSH_ASKPASS when it is set, the terminal otherwise.
+func sshConfirmer() (sshagent.Confirmer, error) {
+	if program := os.Getenv("SSH_ASKPASS"); program != "" {
+		return sshagent.NewAskpass(program), nil
+	}
+	if !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.New("confirming the use of SSH keys needs a terminal or $SSH_ASKPASS")
+	}
+	return sshagent.NewTerminal(os.Stdin, os.Stderr), nil
+}
+
+// keyPairCryptor returns a cryptor of --pubkey and privKeyPEM. The private key must belong
+// to the certificate, as a mismatch would only be noticed when decrypting.
+func keyPairCryptor(privKeyPEM []byte) (*cryptor.Cryptor, error) {
+	if pubKey == "" {
+		return cryptor.New(append(keyOpts(), cryptor.WithPrivateKeyPEM(privKeyPEM))...)
+	}
+
+	priv, err := cryptor.New(cryptor.WithPrivateKeyPEM(privKeyPEM))
+	if err != nil {
+		return nil, err
+	}
+	c, err := cryptor.New(append(keyOpts(), cryptor.WithPrivateKeyPEM(privKeyPEM))...)
+	if err != nil {
+		return nil, err
+	}
+	privID, err := priv.KeyID()
+	if err != nil {
+		return nil, err
+	}
+	pubID, err := c.KeyID()
+	if err != nil {
+		return nil, err
+	}
+	if privID != pubID {
+		return nil, errors.New("the private key does not belong to --pubkey")
+	}
+	return c, nil
+}
+
+// runExport writes the secrets of the store chosen with --store to the archive --file.
+func runExport(ctx context.Context) (string, error) {
+	if importFile == "" {
+		return "", errors.New("--file is required")
+	}
+
+	lister, _, closeStore, err := backupStore(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer closeStore()
+
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	var params *models.KDFParams
+	if backupPassword != "" {
+		params, err = cryptor.NewKDFParams()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	f, err := os.OpenFile(importFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+
+	n, err := client.ClientExport(ctx, lister, cryptorInst, cryptorInst, token, backupPassword, params, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(importFile)
+		return "", err
+	}
+	return fmt.Sprintf("Exported %d secrets to %s.\n", n, importFile), nil
+}
+
+// runRestoreBackup verifies the archive --file and restores it into the store chosen with --store.
+func runRestoreBackup(ctx context.Context) (string, error) {
+	if importFile == "" {
+		return "", errors.New("--file is required")
+	}
+
+	f, err := os.Open(importFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	_, saver, closeStore, err := backupStore(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer closeStore()
+
+	cryptorInst, err := cryptorFromFlags(ctx, keyOpts()...)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	return client.ClientRestoreBackup(ctx, f, saver, cryptorInst, cryptorInst, token, backupPassword)
+}
+
+// keyOpts configures a cryptor with whichever of --pubkey and --privkey are given, --recipient and --strict,
+// for commands that need only one of the keys depending on their other flags.
+func keyOpts() []cryptor.Opt {
+	var opts []cryptor.Opt
+	if pubKey != "" {
+		opts = encryptorOpts(pubKey)
+	}
+	if privKey != "" {
+		opts = append(opts, cryptor.WithPrivateKeyPEM([]byte(privKey)))
+	}
+	if strict {
+		opts = append(opts, cryptor.WithStrict())
+	}
+	return opts
+}
+
+// backupStore opens the store chosen with --store: the local client.db or the server at --server-url.
+// The returned function closes it.
+func backupStore(ctx context.Context) (client.ServerLister, client.SecretBatchSaver, func() error, error) {
+	switch store {
+	case client.StoreLocal:
+		dbConn, err := db.New(
+			databaseDriver,
+			databaseDSN,
+			db.WithMaxOpenConns(1),
+			db.WithMaxIdleConns(1),
+			db.WithConnMaxLifetime(30*time.Minute),
+		)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to connect to DB: %w", err)
+		}
+
+		if err := goose.SetDialect("sqlite"); err != nil {
+			dbConn.Close()
+			return nil, nil, nil, fmt.Errorf("failed to set goose dialect: %w", err)
+		}
+
+		if err := goose.Up(dbConn.DB, pathToMigrationsDir); err != nil {
+			dbConn.Close()
+			return nil, nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+
+		return repositories.NewSecretReadRepository(dbConn), repositories.NewSecretWriteRepository(dbConn), dbConn.Close, nil
+
+	case client.StoreServer:
+		switch scheme.GetSchemeFromURL(serverURL) {
+		case scheme.HTTP, scheme.HTTPS:
+			httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+				Count:   3,
+				Wait:    1 * time.Second,
+				MaxWait: 5 * time.Second,
+			}))
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to initialize HTTP client: %w", err)
+			}
+			return facades.NewSecretReaderHTTP(httpClient), facades.NewSecretWriterHTTP(httpClient), func() error { return nil }, nil
+
+		case scheme.GRPC:
+			grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+				Count:   3,
+				Wait:    1 * time.Second,
+				MaxWait: 5 * time.Second,
+			}))
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to initialize gRPC client: %w", err)
+			}
+			return facades.NewSecretReaderGRPC(grpcConn), facades.NewSecretWriterGRPC(grpcConn), grpcConn.Close, nil
+
+		default:
+			return nil, nil, nil, errors.New("unsupported scheme")
+		}
+
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown store: %s", store)
+	}
+}
+
+// runGenerate generates a password or a passphrase from the generator flags
+// and returns it with a line describing its estimated entropy.
+func runGenerate() (string, string, error) {
+	var opts []generator.Opt
+	if words > 0 {
+		opts = append(opts, generator.WithWords(words), generator.WithSeparator(separator))
+	} else {
+		opts = append(opts, generator.WithLength(length))
+		if classes != "" {
+			opts = append(opts, generator.WithClasses(strings.Split(classes, ",")...))
+		}
+		if excludeAmbiguous {
+			opts = append(opts, generator.WithExcludeAmbiguous())
+		}
+	}
+
+	g, err := generator.New(opts...)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid generator policy: %w", err)
+	}
+
+	return client.ClientGeneratePassword(g)
 }
 
 func runSecretListHTTP(ctx context.Context) (string, error) {
@@ -398,14 +1484,12 @@ func runSecretListHTTP(ctx context.Context) (string, error) {
 
 	secretReader := facades.NewSecretReaderHTTP(httpClient)
 
-	cryptorInst, err := cryptor.New(
-		cryptor.WithPrivateKeyPEM([]byte(privKey)),
-	)
+	decryptor, err := decryptorFromFlags(ctx)
 	if err != nil {
 		return "", fmt.Errorf("cryptor setup failed: %w", err)
 	}
 
-	secretsStr, err := client.ClientListSecrets(ctx, secretReader, cryptorInst, token)
+	secretsStr, err := client.ClientListSecrets(ctx, secretReader, decryptor, token)
 	if err != nil {
 		return "", fmt.Errorf("failed to list secrets: %w", err)
 	}
@@ -426,19 +1510,182 @@ func runSecretListGRPC(ctx context.Context) (string, error) {
 
 	secretReader := facades.NewSecretReaderGRPC(grpcConn)
 
-	cryptorInst, err := cryptor.New(
-		cryptor.WithPrivateKeyPEM([]byte(privKey)),
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	secretsStr, err := client.ClientListSecrets(ctx, secretReader, decryptor, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	return secretsStr, nil
+}
+
+func runGetHTTP(ctx context.Context) (string, error) {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize HTTP client: %w", err)
+	}
+
+	secretReader := facades.NewSecretReaderHTTP(httpClient)
+
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	return client.ClientGetField(ctx, secretReader, decryptor, token, secretType, secretName, field, time.Now())
+}
+
+func runGetGRPC(ctx context.Context) (string, error) {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize gRPC client: %w", err)
+	}
+	defer grpcConn.Close()
+
+	secretReader := facades.NewSecretReaderGRPC(grpcConn)
+
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	return client.ClientGetField(ctx, secretReader, decryptor, token, secretType, secretName, field, time.Now())
+}
+
+// runGet prints the field get selected, or with --copy places it on the clipboard and waits
+// --clear-after to clear it. An interrupt clears the clipboard at once.
+func runGet(ctx context.Context, _ []string) error {
+	value, err := schemeCommand(runGetHTTP, runGetGRPC)(ctx)
+	if err != nil {
+		return err
+	}
+	if !copyField {
+		fmt.Println(value)
+		return nil
+	}
+
+	// OSC 52 goes to stderr, which stays on the terminal when stdout is redirected.
+	var tty io.Writer
+	if terminal.IsTerminal(int(os.Stderr.Fd())) {
+		tty = os.Stderr
+	}
+	backend, err := clipboard.Detect(tty)
+	if err != nil {
+		return err
+	}
+
+	name := field
+	if name == "" {
+		name = client.DefaultField(secretType)
+	}
+	if clearAfter <= 0 {
+		if err := clipboard.Copy(ctx, backend, value, 0); err != nil {
+			return err
+		}
+		fmt.Printf("Copied the %s of %s/%s to the clipboard.\n", name, secretType, secretName)
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Printf("Copied the %s of %s/%s to the clipboard, clearing it in %s. Press Ctrl-C to clear it now.\n",
+		name, secretType, secretName, clearAfter)
+	return clipboard.Copy(ctx, backend, value, clearAfter)
+}
+
+func runHealthHTTP(ctx context.Context) (string, error) {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize HTTP client: %w", err)
+	}
+
+	secretReader := facades.NewSecretReaderHTTP(httpClient)
+
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	return client.ClientHealthReport(ctx, secretReader, decryptor, newHealthChecker(), token, format)
+}
+
+func runHealthGRPC(ctx context.Context) (string, error) {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize gRPC client: %w", err)
+	}
+	defer grpcConn.Close()
+
+	secretReader := facades.NewSecretReaderGRPC(grpcConn)
+
+	decryptor, err := decryptorFromFlags(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	return client.ClientHealthReport(ctx, secretReader, decryptor, newHealthChecker(), token, format)
+}
+
+// newHealthChecker builds a health checker with the thresholds of the health flags.
+func newHealthChecker() *health.Checker {
+	return health.New(
+		health.WithMinEntropy(minEntropy),
+		health.WithMaxAge(time.Duration(maxAgeDays)*24*time.Hour),
+		health.WithExpiryWindow(time.Duration(expiryDays)*24*time.Hour),
+	)
+}
+
+// runBreachCheck checks the passwords of the local store against the breach dataset.
+// It needs no server unless the vault key is derived from a master password.
+func runBreachCheck(ctx context.Context) (string, error) {
+	if dataset == "" {
+		return "", errors.New("--dataset is required")
+	}
+	breachDataset, err := breach.Open(dataset)
+	if err != nil {
+		return "", err
+	}
+
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
 	)
 	if err != nil {
-		return "", fmt.Errorf("cryptor setup failed: %w", err)
+		return "", fmt.Errorf("failed to connect to DB: %w", err)
 	}
+	defer dbConn.Close()
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
 
-	secretsStr, err := client.ClientListSecrets(ctx, secretReader, cryptorInst, token)
+	decryptor, err := decryptorFromFlags(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to list secrets: %w", err)
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
 	}
 
-	return secretsStr, nil
+	return client.ClientBreachCheck(ctx, clientReader, decryptor, breachDataset, token, format)
 }
 
 func runSyncHTTP(ctx context.Context) error {
@@ -454,7 +1701,7 @@ func runSyncHTTP(ctx context.Context) error {
 
 	clientReader := repositories.NewSecretReadRepository(dbConn)
 
-	cryptorInst, err := cryptor.New(
+	cryptorInst, err := cryptorFromFlags(ctx,
 		cryptor.WithPublicKeyPEM([]byte(pubKey)),
 		cryptor.WithPrivateKeyPEM([]byte(privKey)),
 	)
@@ -508,7 +1755,7 @@ func runSyncGRPC(ctx context.Context) error {
 
 	clientReader := repositories.NewSecretReadRepository(dbConn)
 
-	cryptorInst, err := cryptor.New(
+	cryptorInst, err := cryptorFromFlags(ctx,
 		cryptor.WithPublicKeyPEM([]byte(pubKey)),
 		cryptor.WithPrivateKeyPEM([]byte(privKey)),
 	)
@@ -549,3 +1796,366 @@ func runSyncGRPC(ctx context.Context) error {
 
 	return nil
 }
+
+func runRotateKeysHTTP(ctx context.Context) (string, error) {
+	oldCryptor, newCryptor, newKeyIDs, err := newRotationCryptors(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+
+	serverLister := facades.NewSecretReaderHTTP(httpClient)
+	serverSaver := facades.NewSecretWriterHTTP(httpClient)
+
+	serverCount, err := client.ClientRotateKeys(ctx, serverLister, serverSaver, oldCryptor, newCryptor, token, newKeyIDs)
+	if err != nil {
+		return "", fmt.Errorf("server vault rotation failed: %w", err)
+	}
+
+	localCount, err := runRotateKeysLocal(ctx, oldCryptor, newCryptor, newKeyIDs)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Rotated %d secrets on the server and %d in the local store. Key IDs: %s", serverCount, localCount, strings.Join(newKeyIDs, ", ")), nil
+}
+
+func runRotateKeysGRPC(ctx context.Context) (string, error) {
+	oldCryptor, newCryptor, newKeyIDs, err := newRotationCryptors(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	defer grpcConn.Close()
+
+	serverLister := facades.NewSecretReaderGRPC(grpcConn)
+	serverSaver := facades.NewSecretWriterGRPC(grpcConn)
+
+	serverCount, err := client.ClientRotateKeys(ctx, serverLister, serverSaver, oldCryptor, newCryptor, token, newKeyIDs)
+	if err != nil {
+		return "", fmt.Errorf("server vault rotation failed: %w", err)
+	}
+
+	localCount, err := runRotateKeysLocal(ctx, oldCryptor, newCryptor, newKeyIDs)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Rotated %d secrets on the server and %d in the local store. Key IDs: %s", serverCount, localCount, strings.Join(newKeyIDs, ", ")), nil
+}
+
+// newRotationCryptors builds the decryptor for the current key and the encryptor for the new certificate.
+func newRotationCryptors(ctx context.Context) (*cryptor.Cryptor, *cryptor.Cryptor, []string, error) {
+	oldCryptor, err := cryptorFromFlags(ctx,
+		cryptor.WithPrivateKeyPEM([]byte(privKey)),
+	)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("current key setup failed: %w", err)
+	}
+
+	newCryptor, err := cryptor.New(encryptorOpts(newPubKey)...)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("new certificate setup failed: %w", err)
+	}
+
+	newKeyIDs, err := newCryptor.KeyIDs()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return oldCryptor, newCryptor, newKeyIDs, nil
+}
+
+// runRotateKeysLocal re-encrypts the secrets kept in the local store.
+func runRotateKeysLocal(ctx context.Context, oldCryptor, newCryptor *cryptor.Cryptor, newKeyIDs []string) (int, error) {
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	if err := goose.SetDialect("sqlite"); err != nil {
+		return 0, fmt.Errorf("failed to set goose dialect: %w", err)
+	}
+
+	if err := goose.Up(dbConn.DB, pathToMigrationsDir); err != nil {
+		return 0, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
+	clientWriter := repositories.NewSecretWriteRepository(dbConn)
+
+	n, err := client.ClientRotateKeys(ctx, clientReader, clientWriter, oldCryptor, newCryptor, token, newKeyIDs)
+	if err != nil {
+		return 0, fmt.Errorf("local store rotation failed: %w", err)
+	}
+	return n, nil
+}
+
+func runUpgradeHTTP(ctx context.Context) (string, error) {
+	cryptorInst, err := cryptorFromFlags(ctx,
+		cryptor.WithPrivateKeyPEM([]byte(privKey)),
+	)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+
+	serverLister := facades.NewSecretReaderHTTP(httpClient)
+	serverSaver := facades.NewSecretWriterHTTP(httpClient)
+
+	serverCount, err := client.ClientUpgradeSecrets(ctx, serverLister, serverSaver, cryptorInst, token)
+	if err != nil {
+		return "", fmt.Errorf("server upgrade failed: %w", err)
+	}
+
+	localCount, err := runUpgradeLocal(ctx, cryptorInst)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Upgraded %d secrets on the server and %d in the local store.", serverCount, localCount), nil
+}
+
+func runUpgradeGRPC(ctx context.Context) (string, error) {
+	cryptorInst, err := cryptorFromFlags(ctx,
+		cryptor.WithPrivateKeyPEM([]byte(privKey)),
+	)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", err
+	}
+	defer grpcConn.Close()
+
+	serverLister := facades.NewSecretReaderGRPC(grpcConn)
+	serverSaver := facades.NewSecretWriterGRPC(grpcConn)
+
+	serverCount, err := client.ClientUpgradeSecrets(ctx, serverLister, serverSaver, cryptorInst, token)
+	if err != nil {
+		return "", fmt.Errorf("server upgrade failed: %w", err)
+	}
+
+	localCount, err := runUpgradeLocal(ctx, cryptorInst)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Upgraded %d secrets on the server and %d in the local store.", serverCount, localCount), nil
+}
+
+// runUpgradeLocal reseals the secrets kept in the local store in the current ciphertext format.
+func runUpgradeLocal(ctx context.Context, cryptorInst *cryptor.Cryptor) (int, error) {
+	dbConn, err := db.New(
+		databaseDriver,
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	if err := goose.SetDialect("sqlite"); err != nil {
+		return 0, fmt.Errorf("failed to set goose dialect: %w", err)
+	}
+
+	if err := goose.Up(dbConn.DB, pathToMigrationsDir); err != nil {
+		return 0, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	clientReader := repositories.NewSecretReadRepository(dbConn)
+	clientWriter := repositories.NewSecretWriteRepository(dbConn)
+
+	n, err := client.ClientUpgradeSecrets(ctx, clientReader, clientWriter, cryptorInst, token)
+	if err != nil {
+		return 0, fmt.Errorf("local store upgrade failed: %w", err)
+	}
+	return n, nil
+}
+
+func runAuditHTTP(ctx context.Context) (string, error) {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize HTTP client: %w", err)
+	}
+
+	auditFacade := facades.NewAuditHTTPFacade(httpClient)
+
+	return client.ClientListAudit(ctx, auditFacade, token, limit)
+}
+
+func runAuditGRPC(ctx context.Context) (string, error) {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize gRPC client: %w", err)
+	}
+	defer grpcConn.Close()
+
+	auditFacade := facades.NewAuditGRPCFacade(grpcConn)
+
+	return client.ClientListAudit(ctx, auditFacade, token, limit)
+}
+
+// shareFacade combines the server calls used by the sharing commands.
+type shareFacade interface {
+	client.CertificatePublisher
+	client.CertificateGetter
+	client.SecretSharer
+	client.SharedLister
+	client.SharedSaver
+}
+
+func runShareHTTP(ctx context.Context, command string) (string, error) {
+	httpClient, err := http.New(serverURL+apiVersion, http.WithRetryPolicy(http.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize HTTP client: %w", err)
+	}
+
+	return runShare(ctx, command, facades.NewShareHTTPFacade(httpClient), facades.NewSecretReaderHTTP(httpClient))
+}
+
+func runShareGRPC(ctx context.Context, command string) (string, error) {
+	grpcConn, err := grpc.New(serverURL+apiVersion, grpc.WithRetryPolicy(grpc.RetryPolicy{
+		Count:   3,
+		Wait:    1 * time.Second,
+		MaxWait: 5 * time.Second,
+	}))
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize gRPC client: %w", err)
+	}
+	defer grpcConn.Close()
+
+	return runShare(ctx, command, facades.NewShareGRPCFacade(grpcConn), facades.NewSecretReaderGRPC(grpcConn))
+}
+
+// runShare executes one of the sharing commands against the server behind facade.
+func runShare(ctx context.Context, command string, facade shareFacade, secretReader client.ServerGetter) (string, error) {
+	switch command {
+	case client.CommandPublishCert:
+		if err := client.ClientPublishCertificate(ctx, facade, token, pubKey); err != nil {
+			return "", err
+		}
+		return "Certificate published.", nil
+
+	case client.CommandUnshare:
+		if err := client.ClientUnshareSecret(ctx, facade, token, secretType, secretName, shareWith); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Secret %s/%s is no longer shared with %s.", secretType, secretName, shareWith), nil
+	}
+
+	cryptorInst, err := cryptorFromFlags(ctx,
+		cryptor.WithPrivateKeyPEM([]byte(privKey)),
+	)
+	if err != nil {
+		return "", fmt.Errorf("cryptor setup failed: %w", err)
+	}
+
+	switch command {
+	case client.CommandShare:
+		keyID, err := client.ClientShareSecret(ctx, secretReader, facade, facade, cryptorInst, token, secretType, secretName, shareWith, permission)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Secret %s/%s shared with %s (%s). Key ID: %s", secretType, secretName, shareWith, permission, keyID), nil
+
+	case client.CommandListShared:
+		return client.ClientListShared(ctx, facade, cryptorInst, token)
+
+	default:
+		plaintext, err := sharedPayload()
+		if err != nil {
+			return "", err
+		}
+		if err := client.ClientSaveShared(ctx, facade, facade, cryptorInst, cryptorInst, token, secretOwner, secretType, secretName, plaintext, time.Now()); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Shared secret %s:%s/%s updated.", secretOwner, secretType, secretName), nil
+	}
+}
+
+// sharedPayload builds the plaintext of a shared secret of --secret-type
+// from the same flags the add commands use.
+func sharedPayload() ([]byte, error) {
+	var metaPtr *string
+	if meta != "" {
+		metaPtr = &meta
+	}
+
+	switch secretType {
+	case models.SecretTypeBankCard:
+		if err := validators.ValidateLuhn(number); err != nil {
+			return nil, fmt.Errorf("invalid card number: %w", err)
+		}
+		if err := validators.ValidateCVV(cvv); err != nil {
+			return nil, fmt.Errorf("invalid CVV: %w", err)
+		}
+		return json.Marshal(models.BankcardPayload{Number: number, Owner: owner, Exp: exp, CVV: cvv, Meta: metaPtr})
+	case models.SecretTypeText:
+		return json.Marshal(models.TextPayload{Data: data, Meta: metaPtr})
+	case models.SecretTypeBinary:
+		return json.Marshal(models.BinaryPayload{Data: []byte(data), Meta: metaPtr})
+	case models.SecretTypeUser:
+		return json.Marshal(models.UserPayload{Username: username, Password: password, URL: siteURL, Meta: metaPtr})
+	case models.SecretTypeSSHKey:
+		payload, err := client.NewSSHKeyPayload(sshPrivateKey, sshPublicKey, sshComment, passphrase, meta)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(payload)
+	default:
+		return nil, fmt.Errorf("unknown secret type: %s", secretType)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/db"
+	"github.com/sbilibin2017/gophkeeper/internal/snapshot"
+)
+
+// Subcommands of the server binary. Without one the server is started.
+const (
+	commandBackup  = "backup"
+	commandRestore = "restore"
+)
+
+// runBackup takes a snapshot of the database at --database-dsn to --output, or into --dir.
+// With --interval it keeps taking snapshots into --dir until interrupted, keeping the --keep newest.
+func runBackup(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet(commandBackup, flag.ContinueOnError)
+	output := fs.String("output", "", "Snapshot file to write; must not exist")
+	dir := fs.String("dir", "backups", "Directory of timestamped snapshots, used when --output is not set")
+	interval := fs.Duration("interval", 0, "Take a snapshot into --dir every interval until interrupted")
+	keep := fs.Int("keep", 0, "Number of newest snapshots to keep in --dir (0 keeps all)")
+	verify := fs.Bool("verify", true, "Verify every snapshot after writing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *output != "" && *interval > 0 {
+		return errors.New("--interval writes into --dir and cannot be combined with --output")
+	}
+
+	dbConn, err := db.New(
+		"sqlite",
+		databaseDSN,
+		db.WithMaxOpenConns(1),
+		db.WithMaxIdleConns(1),
+		db.WithConnMaxLifetime(30*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to DB: %w", err)
+	}
+	defer dbConn.Close()
+
+	takeSnapshot := func() error {
+		path := *output
+		if path != "" {
+			err = snapshot.Take(ctx, dbConn, path)
+		} else {
+			path, err = snapshot.TakeInDir(ctx, dbConn, *dir, time.Now())
+		}
+		if err != nil {
+			return err
+		}
+
+		if *verify {
+			info, err := snapshot.Verify(ctx, path, pathToMigrationsDir)
+			if err != nil {
+				return fmt.Errorf("snapshot %s failed verification: %w", path, err)
+			}
+			log.Printf("Snapshot %s written and verified (%d bytes, migration version %d)\n", path, info.Size, info.Version)
+		} else {
+			log.Printf("Snapshot %s written\n", path)
+		}
+
+		if *output == "" {
+			removed, err := snapshot.Prune(*dir, *keep)
+			for _, p := range removed {
+				log.Printf("Snapshot %s removed\n", p)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := takeSnapshot(); err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGQUIT, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Shutdown signal received for scheduled backups")
+			return nil
+		case <-ticker.C:
+			// A failed snapshot is retried on the next tick rather than stopping the schedule.
+			if err := takeSnapshot(); err != nil {
+				log.Printf("Scheduled snapshot failed: %v\n", err)
+			}
+		}
+	}
+}
+
+// runRestore verifies the snapshot --input and replaces the database file of --database-dsn with it.
+// The server must be stopped first.
+func runRestore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet(commandRestore, flag.ContinueOnError)
+	input := fs.String("input", "", "Snapshot file to restore (required)")
+	force := fs.Bool("force", false, "Replace an existing database")
+	verifyOnly := fs.Bool("verify-only", false, "Only verify the snapshot, restore nothing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return errors.New("--input is required")
+	}
+	dbPath, err := snapshot.PathFromDSN(databaseDSN)
+	if err != nil {
+		return err
+	}
+
+	info, err := snapshot.Verify(ctx, *input, pathToMigrationsDir)
+	if err != nil {
+		return fmt.Errorf("snapshot %s failed verification: %w", *input, err)
+	}
+	log.Printf("Snapshot %s verified (%d bytes, migration version %d, newest %d)\n", info.Path, info.Size, info.Version, info.Latest)
+	if *verifyOnly {
+		return nil
+	}
+
+	if _, err := os.Stat(dbPath); err == nil && !*force {
+		return fmt.Errorf("database %s exists, pass --force to replace it", dbPath)
+	}
+	if err := snapshot.Restore(ctx, *input, dbPath); err != nil {
+		return err
+	}
+	log.Printf("Database %s restored from %s\n", dbPath, *input)
+	return nil
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/hkdf"
+	"crypto/sha256"
 	"flag"
 	"fmt"
 	"log"
@@ -19,6 +21,8 @@ import (
 	grpcHandlers "github.com/sbilibin2017/gophkeeper/internal/handlers/grpc"
 	httpHandlers "github.com/sbilibin2017/gophkeeper/internal/handlers/http"
 	"github.com/sbilibin2017/gophkeeper/internal/jwt"
+	"github.com/sbilibin2017/gophkeeper/internal/otp"
+	"github.com/sbilibin2017/gophkeeper/internal/ratelimit"
 	"github.com/sbilibin2017/gophkeeper/internal/repositories"
 	"github.com/sbilibin2017/gophkeeper/internal/scheme"
 	"github.com/sbilibin2017/gophkeeper/internal/services"
@@ -33,7 +37,18 @@ func main() {
 
 	ctx := context.Background()
 
-	if err := run(ctx); err != nil {
+	var err error
+	switch flag.Arg(0) {
+	case commandBackup:
+		err = runBackup(ctx, flag.Args()[1:])
+	case commandRestore:
+		err = runRestore(ctx, flag.Args()[1:])
+	case "":
+		err = run(ctx)
+	default:
+		err = fmt.Errorf("unknown command: %s", flag.Arg(0))
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -48,6 +63,13 @@ var (
 	databaseDSN  string
 	jwtSecretKey string
 	jwtExp       time.Duration
+	otpSecretKey string
+	kdfDecoyKey  string
+
+	loginMaxAttempts int
+	loginIPFactor    int
+	loginLockout     time.Duration
+	loginMaxLockout  time.Duration
 )
 
 func init() {
@@ -55,6 +77,12 @@ func init() {
 	flag.StringVar(&databaseDSN, "database-dsn", "server.db", "Database DSN (Data Source Name)")
 	flag.StringVar(&jwtSecretKey, "jwt-secret-key", "secret", "JWT secret key")
 	flag.DurationVar(&jwtExp, "jwt-exp", 9999, "JWT expiration duration (e.g. 24h, 30m)")
+	flag.StringVar(&otpSecretKey, "otp-secret-key", "secret", "Key used to encrypt two-factor authentication seeds at rest")
+	flag.StringVar(&kdfDecoyKey, "kdf-decoy-key", "", "Key deriving the KDF parameters answered for unknown users (derived from --jwt-secret-key when empty)")
+	flag.IntVar(&loginMaxAttempts, "login-max-attempts", 5, "Failed login/register attempts per username before lockout (0 disables rate limiting)")
+	flag.IntVar(&loginIPFactor, "login-ip-factor", 4, "How many times more failed attempts a single client IP may make than a username")
+	flag.DurationVar(&loginLockout, "login-lockout", time.Minute, "First lockout duration, doubled on every repeated lockout")
+	flag.DurationVar(&loginMaxLockout, "login-max-lockout", time.Hour, "Maximum lockout duration")
 }
 
 func printBuildInfo() {
@@ -64,8 +92,25 @@ func printBuildInfo() {
 const (
 	apiVersion          = "/api/v1"
 	pathToMigrationsDir = "migrations"
+	otpIssuer           = "GophKeeper"
+
+	// kdfDecoyInfo binds the KDF decoy key derived from the JWT secret to this purpose.
+	kdfDecoyInfo = "gophkeeper kdf decoy key v1"
 )
 
+// decoyKey returns --kdf-decoy-key, or a key derived from --jwt-secret-key when it is empty,
+// so that the decoy KDF parameters never use the token signing key itself.
+func decoyKey() ([]byte, error) {
+	if kdfDecoyKey != "" {
+		return []byte(kdfDecoyKey), nil
+	}
+	key, err := hkdf.Key(sha256.New, []byte(jwtSecretKey), nil, kdfDecoyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the KDF decoy key: %w", err)
+	}
+	return key, nil
+}
+
 func run(ctx context.Context) error {
 	schm := scheme.GetSchemeFromURL(serverURL)
 
@@ -79,11 +124,22 @@ func run(ctx context.Context) error {
 		addr = serverURL
 	}
 
+	decoy, err := decoyKey()
+	if err != nil {
+		return err
+	}
+
+	limitOpts := []ratelimit.Opt{
+		ratelimit.WithMaxFailures(loginMaxAttempts),
+		ratelimit.WithIPFactor(loginIPFactor),
+		ratelimit.WithLockout(loginLockout, loginMaxLockout),
+	}
+
 	switch schm {
 	case scheme.HTTP, scheme.HTTPS:
-		return runServerHTTP(ctx, addr, databaseDSN, jwtSecretKey, jwtExp, apiVersion, pathToMigrationsDir)
+		return runServerHTTP(ctx, addr, databaseDSN, jwtSecretKey, jwtExp, otpSecretKey, decoy, limitOpts, apiVersion, pathToMigrationsDir)
 	case scheme.GRPC:
-		return runServerGRPC(ctx, addr, databaseDSN, jwtSecretKey, jwtExp, apiVersion, pathToMigrationsDir)
+		return runServerGRPC(ctx, addr, databaseDSN, jwtSecretKey, jwtExp, otpSecretKey, decoy, limitOpts, apiVersion, pathToMigrationsDir)
 	default:
 		return fmt.Errorf("unsupported scheme: %s", schm)
 	}
@@ -96,6 +152,9 @@ func runServerHTTP(
 	databaseDSN string,
 	jwtSecretKey string,
 	jwtExp time.Duration,
+	otpSecretKey string,
+	kdfDecoy []byte,
+	limitOpts []ratelimit.Opt,
 	apiVersion string,
 	pathToMigrationsDir string,
 ) error {
@@ -125,29 +184,95 @@ func runServerHTTP(
 	userReadRepo := repositories.NewUserReadRepository(dbConn)
 	secretWriter := repositories.NewSecretWriteRepository(dbConn)
 	secretReader := repositories.NewSecretReadRepository(dbConn)
-
-	authService := services.NewAuthService(userReadRepo, userWriteRepo)
-	secretWriteService := services.NewSecretWriteService(secretWriter)
-	secretReadService := services.NewSecretReadService(secretReader)
+	lockoutWriter := repositories.NewLockoutEventWriteRepository(dbConn)
+	auditWriter := repositories.NewAuditEventWriteRepository(dbConn)
+	auditReader := repositories.NewAuditEventReadRepository(dbConn)
+	shareWriter := repositories.NewShareWriteRepository(dbConn)
+	shareReader := repositories.NewShareReadRepository(dbConn)
+	orgWriter := repositories.NewOrganizationWriteRepository(dbConn)
+	orgReader := repositories.NewOrganizationReadRepository(dbConn)
+	vaultSecretWriter := repositories.NewVaultSecretWriteRepository(dbConn)
+	vaultSecretReader := repositories.NewVaultSecretReadRepository(dbConn)
+
+	auditService := services.NewAuditService(auditWriter, auditReader)
+	authService := services.NewAuthService(
+		userReadRepo,
+		userWriteRepo,
+		services.WithOTP(userWriteRepo, otp.NewSealer(otpSecretKey), otpIssuer),
+		services.WithAccounts(userWriteRepo),
+		services.WithOrganizations(orgReader),
+		services.WithCertificates(userWriteRepo),
+		services.WithKDFDecoy(kdfDecoy),
+		services.WithAuthAuditor(auditService),
+	)
+	organizationService := services.NewOrganizationService(
+		orgWriter, orgReader, userReadRepo,
+		services.WithOrganizationAuditor(auditService),
+	)
+	secretWriteService := services.NewSecretWriteService(
+		secretWriter,
+		services.WithSecretWriteAuditor(auditService),
+		services.WithSecretWriteVaults(organizationService, vaultSecretWriter),
+	)
+	secretReadService := services.NewSecretReadService(
+		secretReader,
+		services.WithSecretReadAuditor(auditService),
+		services.WithSecretReadVaults(organizationService, vaultSecretReader),
+	)
+	shareService := services.NewShareService(
+		shareWriter, shareReader, secretReader, secretWriter, userReadRepo,
+		services.WithShareAuditor(auditService),
+	)
 
 	jwtManager := jwt.New(
 		jwt.WithSecret(jwtSecretKey),
 		jwt.WithLifetime(jwtExp),
+		jwt.WithRevocationChecker(authService),
 	)
 
+	loginLimiter := ratelimit.New(append(limitOpts, ratelimit.WithRecorder(lockoutWriter))...)
+
 	// Setup router and middleware
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(httpHandlers.NewRequestMetaMiddleware())
 
 	// Register routes
-	r.Post(apiVersion+"/register", httpHandlers.NewRegisterHandler(authService, jwtManager))
-	r.Post(apiVersion+"/login", httpHandlers.NewLoginHandler(authService, jwtManager))
+	rateLimited := r.With(httpHandlers.NewRateLimitMiddleware(loginLimiter))
+	rateLimited.Post(apiVersion+"/register", httpHandlers.NewRegisterHandler(authService, jwtManager))
+	rateLimited.Post(apiVersion+"/login", httpHandlers.NewLoginHandler(authService, jwtManager))
+	r.Get(apiVersion+"/users/{username}/kdf", httpHandlers.NewKDFParamsHandler(authService))
+	r.Post(apiVersion+"/otp/enroll", httpHandlers.NewOTPEnrollHandler(authService, jwtManager))
+	tokenLimited := r.With(httpHandlers.NewTokenRateLimitMiddleware(loginLimiter, jwtManager))
+	tokenLimited.Post(apiVersion+"/otp/confirm", httpHandlers.NewOTPConfirmHandler(authService, jwtManager))
+	tokenLimited.Post(apiVersion+"/password", httpHandlers.NewChangePasswordHandler(authService, jwtManager, jwtManager))
+	tokenLimited.Delete(apiVersion+"/account", httpHandlers.NewDeleteAccountHandler(authService, jwtManager))
 
 	r.Post(apiVersion+"/secrets", httpHandlers.NewSecretAddHandler(secretWriteService, jwtManager))
+	r.Post(apiVersion+"/secrets/batch", httpHandlers.NewSecretBatchSaveHandler(secretWriteService, jwtManager))
 	r.Get(apiVersion+"/secrets/{secret_type}/{secret_name}", httpHandlers.NewSecretGetHandler(secretReadService, jwtManager))
 	r.Get(apiVersion+"/secrets", httpHandlers.NewSecretListHandler(secretReadService, jwtManager))
 
+	r.Get(apiVersion+"/audit", httpHandlers.NewAuditListHandler(auditService, jwtManager))
+
+	r.Put(apiVersion+"/certificate", httpHandlers.NewCertificatePublishHandler(authService, jwtManager))
+	r.Get(apiVersion+"/users/{username}/certificate", httpHandlers.NewCertificateGetHandler(authService, jwtManager))
+	r.Post(apiVersion+"/shares", httpHandlers.NewShareHandler(shareService, jwtManager))
+	r.Get(apiVersion+"/shares", httpHandlers.NewSharedListHandler(shareService, jwtManager))
+	r.Delete(apiVersion+"/shares/{secret_type}/{secret_name}/{recipient}", httpHandlers.NewUnshareHandler(shareService, jwtManager))
+	r.Put(apiVersion+"/shared/{secret_owner}/{secret_type}/{secret_name}", httpHandlers.NewSharedSaveHandler(shareService, jwtManager))
+
+	r.Post(apiVersion+"/organizations", httpHandlers.NewOrganizationCreateHandler(organizationService, jwtManager))
+	r.Get(apiVersion+"/organizations", httpHandlers.NewOrganizationListHandler(organizationService, jwtManager))
+	r.Delete(apiVersion+"/organizations/{organization}", httpHandlers.NewOrganizationDeleteHandler(organizationService, jwtManager))
+	r.Get(apiVersion+"/organizations/{organization}/members", httpHandlers.NewMemberListHandler(organizationService, jwtManager))
+	r.Put(apiVersion+"/organizations/{organization}/members/{username}", httpHandlers.NewMemberSetHandler(organizationService, jwtManager))
+	r.Delete(apiVersion+"/organizations/{organization}/members/{username}", httpHandlers.NewMemberRemoveHandler(organizationService, jwtManager))
+	r.Get(apiVersion+"/organizations/{organization}/vaults", httpHandlers.NewVaultListHandler(organizationService, jwtManager))
+	r.Post(apiVersion+"/organizations/{organization}/vaults", httpHandlers.NewVaultCreateHandler(organizationService, jwtManager))
+	r.Delete(apiVersion+"/organizations/{organization}/vaults/{vault}", httpHandlers.NewVaultDeleteHandler(organizationService, jwtManager))
+
 	srv := &http.Server{
 		Addr:    serverAddr,
 		Handler: r,
@@ -188,6 +313,9 @@ func runServerGRPC(
 	databaseDSN string,
 	jwtSecretKey string,
 	jwtExp time.Duration,
+	otpSecretKey string,
+	kdfDecoy []byte,
+	limitOpts []ratelimit.Opt,
 	apiVersion string,
 	pathToMigrationsDir string,
 ) error {
@@ -217,19 +345,76 @@ func runServerGRPC(
 	userReadRepo := repositories.NewUserReadRepository(dbConn)
 	secretWriter := repositories.NewSecretWriteRepository(dbConn)
 	secretReader := repositories.NewSecretReadRepository(dbConn)
-
-	authService := services.NewAuthService(userReadRepo, userWriteRepo)
-	secretWriteService := services.NewSecretWriteService(secretWriter)
-	secretReadService := services.NewSecretReadService(secretReader)
+	lockoutWriter := repositories.NewLockoutEventWriteRepository(dbConn)
+	auditWriter := repositories.NewAuditEventWriteRepository(dbConn)
+	auditReader := repositories.NewAuditEventReadRepository(dbConn)
+	shareWriter := repositories.NewShareWriteRepository(dbConn)
+	shareReader := repositories.NewShareReadRepository(dbConn)
+	orgWriter := repositories.NewOrganizationWriteRepository(dbConn)
+	orgReader := repositories.NewOrganizationReadRepository(dbConn)
+	vaultSecretWriter := repositories.NewVaultSecretWriteRepository(dbConn)
+	vaultSecretReader := repositories.NewVaultSecretReadRepository(dbConn)
+
+	auditService := services.NewAuditService(auditWriter, auditReader)
+	authService := services.NewAuthService(
+		userReadRepo,
+		userWriteRepo,
+		services.WithOTP(userWriteRepo, otp.NewSealer(otpSecretKey), otpIssuer),
+		services.WithAccounts(userWriteRepo),
+		services.WithOrganizations(orgReader),
+		services.WithCertificates(userWriteRepo),
+		services.WithKDFDecoy(kdfDecoy),
+		services.WithAuthAuditor(auditService),
+	)
+	organizationService := services.NewOrganizationService(
+		orgWriter, orgReader, userReadRepo,
+		services.WithOrganizationAuditor(auditService),
+	)
+	secretWriteService := services.NewSecretWriteService(
+		secretWriter,
+		services.WithSecretWriteAuditor(auditService),
+		services.WithSecretWriteVaults(organizationService, vaultSecretWriter),
+	)
+	secretReadService := services.NewSecretReadService(
+		secretReader,
+		services.WithSecretReadAuditor(auditService),
+		services.WithSecretReadVaults(organizationService, vaultSecretReader),
+	)
+	shareService := services.NewShareService(
+		shareWriter, shareReader, secretReader, secretWriter, userReadRepo,
+		services.WithShareAuditor(auditService),
+	)
 
 	jwtManager := jwt.New(
 		jwt.WithSecret(jwtSecretKey),
 		jwt.WithLifetime(jwtExp),
+		jwt.WithRevocationChecker(authService),
 	)
 
-	grpcServer := grpc.NewServer()
+	loginLimiter := ratelimit.New(append(limitOpts, ratelimit.WithRecorder(lockoutWriter))...)
+
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			grpcHandlers.NewRequestMetaInterceptor(),
+			grpcHandlers.NewRateLimitInterceptor(
+				loginLimiter,
+				pb.AuthService_Register_FullMethodName,
+				pb.AuthService_Login_FullMethodName,
+			),
+			grpcHandlers.NewTokenRateLimitInterceptor(
+				loginLimiter,
+				jwtManager,
+				pb.AuthService_ConfirmOTP_FullMethodName,
+				pb.AuthService_ChangePassword_FullMethodName,
+				pb.AuthService_DeleteAccount_FullMethodName,
+			),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcHandlers.NewRequestMetaStreamInterceptor(),
+		),
+	)
 
-	authServer := grpcHandlers.NewAuthServer(authService, jwtManager)
+	authServer := grpcHandlers.NewAuthServer(authService, jwtManager, jwtManager)
 	pb.RegisterAuthServiceServer(grpcServer, authServer)
 
 	secretWriteServer := grpcHandlers.NewSecretWriteServer(secretWriteService, jwtManager)
@@ -238,6 +423,15 @@ func runServerGRPC(
 	secretReadServer := grpcHandlers.NewSecretReadServer(secretReadService, jwtManager)
 	pb.RegisterSecretReadServiceServer(grpcServer, secretReadServer)
 
+	auditServer := grpcHandlers.NewAuditServer(auditService, jwtManager)
+	pb.RegisterAuditServiceServer(grpcServer, auditServer)
+
+	shareServer := grpcHandlers.NewShareServer(shareService, authService, jwtManager)
+	pb.RegisterShareServiceServer(grpcServer, shareServer)
+
+	organizationServer := grpcHandlers.NewOrganizationServer(organizationService, jwtManager)
+	pb.RegisterOrganizationServiceServer(grpcServer, organizationServer)
+
 	lis, err := net.Listen("tcp", serverAddr+apiVersion)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
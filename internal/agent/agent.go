@@ -0,0 +1,365 @@
+// Package agent keeps an unlocked key in a background process and decrypts secrets for the
+// client commands of the same user, like ssh-agent does for SSH keys. Commands reach it through
+// a Unix socket, so the key is given once rather than on every command line.
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/client"
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+const (
+	// EnvSocket is the environment variable holding the socket of the running agent.
+	EnvSocket = "GOPHKEEPER_AGENT_SOCK"
+	// DefaultTTL is how long the agent keeps the key by default.
+	DefaultTTL = time.Hour
+	// ioTimeout bounds each request, so a stuck peer cannot hold a connection open.
+	ioTimeout = 10 * time.Second
+)
+
+var (
+	// ErrExpired is returned once the TTL of the key has passed.
+	ErrExpired = errors.New("agent key expired")
+	// ErrPeerRejected is returned to connections of another user.
+	ErrPeerRejected = errors.New("agent connection from another user rejected")
+	// ErrUnsupported is returned on platforms without peer credentials of Unix sockets.
+	ErrUnsupported = errors.New("agent is not supported on this platform")
+	// ErrRunning is returned by Listen when an agent already serves the socket.
+	ErrRunning = errors.New("an agent is already running")
+)
+
+// Operations of the agent protocol.
+const (
+	opDecrypt = "decrypt"
+	opStatus  = "status"
+	opStop    = "stop"
+)
+
+// request is one line a client sends to the agent.
+type request struct {
+	Op      string                  `json:"op"`
+	Secret  *models.SecretEncrypted `json:"secret,omitempty"`
+	Binding models.SecretBinding    `json:"binding"`
+}
+
+// response is the line the agent answers a request with.
+type response struct {
+	Plaintext []byte  `json:"plaintext,omitempty"`
+	Status    *Status `json:"status,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Status describes a running agent.
+type Status struct {
+	PID   int    `json:"pid"`
+	KeyID string `json:"key_id,omitempty"`
+	// Expires is when the key is dropped, zero when it is kept until the agent stops.
+	Expires time.Time `json:"expires"`
+}
+
+// Keys is the key material handed to an agent started in the background:
+// a private key PEM or the vault key of a master password.
+type Keys struct {
+	PrivateKeyPEM []byte           `json:"private_key_pem,omitempty"`
+	VaultKey      cryptor.VaultKey `json:"vault_key,omitempty"`
+	// Strict makes the agent refuse legacy ciphertexts, see cryptor.WithStrict.
+	Strict bool `json:"strict,omitempty"`
+}
+
+// Cryptor returns the cryptor decrypting with k.
+func (k Keys) Cryptor() (*cryptor.Cryptor, error) {
+	var opts []cryptor.Opt
+	if k.Strict {
+		opts = append(opts, cryptor.WithStrict())
+	}
+	switch {
+	case len(k.PrivateKeyPEM) > 0:
+		return cryptor.New(append(opts, cryptor.WithPrivateKeyPEM(k.PrivateKeyPEM))...)
+	case len(k.VaultKey) > 0:
+		return cryptor.New(append(opts, cryptor.WithVaultKey(k.VaultKey))...)
+	}
+	return nil, errors.New("no agent key given")
+}
+
+// keyIdentifier is implemented by decryptors that can name their key, like *cryptor.Cryptor.
+type keyIdentifier interface {
+	KeyID() (string, error)
+}
+
+// wiper is implemented by decryptors that can overwrite their key in memory, like *cryptor.Cryptor.
+type wiper interface {
+	Wipe()
+}
+
+// Server decrypts secrets for connections of its own user until its key expires.
+type Server struct {
+	mu        sync.Mutex
+	decryptor client.Decryptor
+	ttl       time.Duration
+	uid       int
+	expires   time.Time
+	stop      context.CancelFunc
+}
+
+// Opt configures a Server.
+type Opt func(*Server)
+
+// WithTTL sets how long the key is kept; zero keeps it until the agent stops. DefaultTTL by default.
+func WithTTL(ttl time.Duration) Opt {
+	return func(s *Server) {
+		s.ttl = ttl
+	}
+}
+
+// WithUID sets the user whose connections are served, the user running the agent by default.
+func WithUID(uid int) Opt {
+	return func(s *Server) {
+		s.uid = uid
+	}
+}
+
+// NewServer returns an agent decrypting with decryptor.
+func NewServer(decryptor client.Decryptor, opts ...Opt) *Server {
+	s := &Server{decryptor: decryptor, ttl: DefaultTTL, uid: os.Getuid()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Serve answers connections on l until ctx is done, a client stops the agent or the TTL passes.
+// The key is dropped and l closed when it returns; a decryptor that can wipe its key has it
+// overwritten once the last request is answered.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	if w, ok := s.decryptor.(wiper); ok {
+		defer w.Wipe()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if s.ttl > 0 {
+		s.expires = time.Now().Add(s.ttl)
+		var cancelTTL context.CancelFunc
+		ctx, cancelTTL = context.WithDeadline(ctx, s.expires)
+		defer cancelTTL()
+	}
+	s.mu.Lock()
+	s.stop = cancel
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		s.decryptor = nil
+		s.mu.Unlock()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			s.handle(conn)
+		}()
+	}
+}
+
+// handle answers the requests of one connection after checking that it comes from the agent user.
+func (s *Server) handle(conn net.Conn) {
+	enc := json.NewEncoder(conn)
+	uid, err := PeerUID(conn)
+	if err != nil || uid != s.uid {
+		_ = enc.Encode(response{Error: ErrPeerRejected.Error()})
+		return
+	}
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	for {
+		_ = conn.SetDeadline(time.Now().Add(ioTimeout))
+		if !scanner.Scan() {
+			return
+		}
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = enc.Encode(response{Error: "invalid request: " + err.Error()})
+			return
+		}
+		if err := enc.Encode(s.answer(req)); err != nil {
+			return
+		}
+	}
+}
+
+// answer runs one request.
+func (s *Server) answer(req request) response {
+	s.mu.Lock()
+	decryptor := s.decryptor
+	s.mu.Unlock()
+
+	switch req.Op {
+	case opStatus:
+		status := &Status{PID: os.Getpid(), Expires: s.expires}
+		if ider, ok := decryptor.(keyIdentifier); ok {
+			status.KeyID, _ = ider.KeyID()
+		}
+		return response{Status: status}
+	case opStop:
+		s.stop()
+		return response{}
+	case opDecrypt:
+		if decryptor == nil {
+			return response{Error: ErrExpired.Error()}
+		}
+		if req.Secret == nil {
+			return response{Error: "no secret to decrypt"}
+		}
+		plaintext, err := decryptor.Decrypt(req.Secret, req.Binding)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{Plaintext: plaintext}
+	}
+	return response{Error: fmt.Sprintf("unknown operation %q", req.Op)}
+}
+
+// DefaultSocketPath returns the socket of the agent of the current user: in $XDG_RUNTIME_DIR
+// when it is set, in a per-user directory of the temporary directory otherwise.
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "gophkeeper", "agent.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gophkeeper-%d", os.Getuid()), "agent.sock")
+}
+
+// Listen listens on the Unix socket path, readable only by the current user. Its directory is
+// created private to the user when missing, and must be owned by the user. A socket left by an agent that is gone is replaced;
+// ErrRunning is returned when an agent still answers on it.
+func Listen(path string) (net.Listener, error) {
+	if !peerCredentials {
+		return nil, ErrUnsupported
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create the agent directory: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("agent directory %s is accessible by other users", dir)
+	}
+	owner, err := fileOwner(info)
+	if err != nil {
+		return nil, err
+	}
+	if owner != os.Getuid() {
+		return nil, fmt.Errorf("agent directory %s is owned by another user", dir)
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w on %s", ErrRunning, path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove the stale agent socket: %w", err)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to restrict the agent socket: %w", err)
+	}
+	return l, nil
+}
+
+// Client talks to the agent listening on a socket. It implements client.Decryptor.
+type Client struct {
+	path string
+}
+
+// NewClient returns a client of the agent listening on path.
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// Decrypt asks the agent to decrypt secret.
+func (c *Client) Decrypt(secret *models.SecretEncrypted, binding models.SecretBinding) ([]byte, error) {
+	resp, err := c.call(context.Background(), request{Op: opDecrypt, Secret: secret, Binding: binding})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Plaintext, nil
+}
+
+// Status returns the status of the agent.
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	resp, err := c.call(ctx, request{Op: opStatus})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status == nil {
+		return nil, errors.New("agent returned no status")
+	}
+	return resp.Status, nil
+}
+
+// Stop makes the agent drop its key and exit.
+func (c *Client) Stop(ctx context.Context) error {
+	_, err := c.call(ctx, request{Op: opStop})
+	return err
+}
+
+// call sends one request on a new connection and reads its response.
+func (c *Client) call(ctx context.Context, req request) (*response, error) {
+	dialer := net.Dialer{Timeout: ioTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the agent: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send to the agent: %w", err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read the agent response: %w", err)
+	}
+	if resp.Error != "" {
+		for _, known := range []error{ErrExpired, ErrPeerRejected} {
+			if resp.Error == known.Error() {
+				return nil, known
+			}
+		}
+		return nil, fmt.Errorf("agent: %s", resp.Error)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDecryptor "decrypts" by reversing the ciphertext and fails for secrets called "broken".
+type fakeDecryptor struct{}
+
+func (fakeDecryptor) Decrypt(secret *models.SecretEncrypted, binding models.SecretBinding) ([]byte, error) {
+	if binding.Name == "broken" {
+		return nil, errors.New("authentication failed")
+	}
+	out := make([]byte, len(secret.Ciphertext))
+	for i, b := range secret.Ciphertext {
+		out[len(out)-1-i] = b
+	}
+	return out, nil
+}
+
+func (fakeDecryptor) KeyID() (string, error) {
+	return "key-1", nil
+}
+
+// startAgent serves s on a socket in a temporary directory and returns the socket and
+// a channel receiving the result of Serve.
+func startAgent(t *testing.T, s *Server) (string, <-chan error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "agent", "agent.sock")
+	l, err := Listen(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	finished := make(chan struct{})
+	go func() {
+		done <- s.Serve(ctx, l)
+		close(finished)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-finished
+	})
+	return path, done
+}
+
+func TestAgent_Decrypt(t *testing.T) {
+	path, _ := startAgent(t, NewServer(fakeDecryptor{}))
+	c := NewClient(path)
+
+	plaintext, err := c.Decrypt(&models.SecretEncrypted{Ciphertext: []byte("terces")}, models.SecretBinding{Owner: "alice", Type: "text", Name: "note"})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	_, err = c.Decrypt(&models.SecretEncrypted{Ciphertext: []byte("x")}, models.SecretBinding{Name: "broken"})
+	assert.EqualError(t, err, "agent: authentication failed")
+
+	status, err := c.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), status.PID)
+	assert.Equal(t, "key-1", status.KeyID)
+	assert.WithinDuration(t, time.Now().Add(DefaultTTL), status.Expires, time.Minute)
+}
+
+func TestAgent_Socket(t *testing.T) {
+	path, _ := startAgent(t, NewServer(fakeDecryptor{}))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	info, err = os.Stat(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	_, err = Listen(path)
+	assert.ErrorIs(t, err, ErrRunning)
+}
+
+func TestListen_StaleSocket(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "agent")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	path := filepath.Join(dir, "agent.sock")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	l, err := Listen(path)
+	require.NoError(t, err)
+	l.Close()
+}
+
+func TestListen_SharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o755))
+
+	_, err := Listen(filepath.Join(dir, "agent.sock"))
+	assert.ErrorContains(t, err, "accessible by other users")
+}
+
+func TestListen_ForeignDirectory(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the owner of a directory needs root")
+	}
+	dir := filepath.Join(t.TempDir(), "agent")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	require.NoError(t, os.Chown(dir, 65534, 65534))
+
+	_, err := Listen(filepath.Join(dir, "agent.sock"))
+	assert.ErrorContains(t, err, "owned by another user")
+}
+
+func TestAgent_TTL(t *testing.T) {
+	path, done := startAgent(t, NewServer(fakeDecryptor{}, WithTTL(50*time.Millisecond)))
+	c := NewClient(path)
+
+	_, err := c.Decrypt(&models.SecretEncrypted{Ciphertext: []byte("a")}, models.SecretBinding{})
+	require.NoError(t, err)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not stop after its TTL")
+	}
+	_, err = c.Decrypt(&models.SecretEncrypted{Ciphertext: []byte("a")}, models.SecretBinding{})
+	assert.Error(t, err)
+}
+
+func TestAgent_TTLWipesKey(t *testing.T) {
+	key := cryptor.VaultKey(bytes.Repeat([]byte{7}, 32))
+	c, err := cryptor.New(cryptor.WithVaultKey(key))
+	require.NoError(t, err)
+	_, done := startAgent(t, NewServer(c, WithTTL(50*time.Millisecond)))
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not stop after its TTL")
+	}
+	assert.Equal(t, make([]byte, 32), []byte(key))
+	assert.Nil(t, c.PrivateKey)
+}
+
+func TestAgent_Expired(t *testing.T) {
+	s := NewServer(fakeDecryptor{})
+	s.decryptor = nil
+
+	resp := s.answer(request{Op: opDecrypt, Secret: &models.SecretEncrypted{}})
+	assert.Equal(t, ErrExpired.Error(), resp.Error)
+}
+
+func TestAgent_Stop(t *testing.T) {
+	path, done := startAgent(t, NewServer(fakeDecryptor{}, WithTTL(0)))
+	c := NewClient(path)
+
+	status, err := c.Status(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.Expires.IsZero())
+
+	require.NoError(t, c.Stop(context.Background()))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("agent did not stop")
+	}
+}
+
+func TestAgent_PeerRejected(t *testing.T) {
+	path, _ := startAgent(t, NewServer(fakeDecryptor{}, WithUID(os.Getuid()+1)))
+
+	_, err := NewClient(path).Decrypt(&models.SecretEncrypted{Ciphertext: []byte("a")}, models.SecretBinding{})
+	assert.ErrorIs(t, err, ErrPeerRejected)
+}
+
+func TestKeys(t *testing.T) {
+	key := cryptor.VaultKey(make([]byte, 32))
+	keys, err := ReadKeys(strings.NewReader(`{"vault_key":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}`))
+	require.NoError(t, err)
+	assert.Equal(t, key, keys.VaultKey)
+
+	c, err := keys.Cryptor()
+	require.NoError(t, err)
+	assert.NotNil(t, c)
+
+	keys, err = ReadKeys(strings.NewReader(`{"vault_key":"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=","strict":true}`))
+	require.NoError(t, err)
+	c, err = keys.Cryptor()
+	require.NoError(t, err)
+	_, err = c.Decrypt(&models.SecretEncrypted{Ciphertext: []byte("legacy nonce and ciphertext")}, models.SecretBinding{})
+	assert.ErrorIs(t, err, cryptor.ErrLegacyCiphertext)
+
+	_, err = Keys{}.Cryptor()
+	assert.Error(t, err)
+}
+
+func TestSpawn(t *testing.T) {
+	line, err := Spawn("sh", []string{"-c", `read -r keys; echo "got $keys"`}, Keys{VaultKey: []byte{1, 2}})
+	require.NoError(t, err)
+	assert.Equal(t, `got {"vault_key":"AQI="}`, line)
+
+	_, err = Spawn("sh", []string{"-c", "echo 'no key' >&2; exit 1"}, Keys{})
+	assert.EqualError(t, err, "agent failed to start: no key")
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package agent
+
+import "os"
+
+// fileOwner fails on platforms without Unix file owners.
+func fileOwner(os.FileInfo) (int, error) {
+	return 0, ErrUnsupported
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package agent
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// fileOwner returns the user owning the file described by info.
+func fileOwner(info os.FileInfo) (int, error) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, errors.New("file owner unknown")
+	}
+	return int(st.Uid), nil
+}
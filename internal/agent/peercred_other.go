@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || freebsd)
+
+package agent
+
+import "net"
+
+// peerCredentials reports whether PeerUID works on this platform.
+const peerCredentials = false
+
+// PeerUID fails on platforms without peer credentials, rejecting every connection.
+func PeerUID(net.Conn) (int, error) {
+	return 0, ErrUnsupported
+}
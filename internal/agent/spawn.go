@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// spawnTimeout bounds how long Spawn waits for the agent to get ready.
+const spawnTimeout = 30 * time.Second
+
+// ReadKeys reads the keys Spawn hands to the agent.
+func ReadKeys(r io.Reader) (Keys, error) {
+	var keys Keys
+	if err := json.NewDecoder(r).Decode(&keys); err != nil {
+		return Keys{}, fmt.Errorf("failed to read the agent keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Spawn starts the agent in the background, in its own session, by running name with args:
+// a command reading keys with ReadKeys from its standard input, serving in the foreground and
+// printing a line once it listens. Spawn waits for that line and returns it. The keys are passed
+// through a pipe, so they never show in the process list or the environment.
+func Spawn(name string, args []string, keys Keys) (string, error) {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return "", err
+	}
+
+	// The agent outlives this process, so its errors go to a file rather than a pipe
+	// nobody reads once it is ready.
+	errFile, err := os.CreateTemp("", "gophkeeper-agent-*.log")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(errFile.Name())
+	defer errFile.Close()
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(string(payload))
+	cmd.Stderr = errFile
+	cmd.SysProcAttr = detached()
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", fmt.Errorf("failed to start the agent: %w", err)
+	}
+
+	ready := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stdout).ReadString('\n')
+		ready <- line
+	}()
+
+	select {
+	case line := <-ready:
+		if line != "" {
+			return strings.TrimRight(line, "\n"), nil
+		}
+		err := cmd.Wait()
+		msg, _ := os.ReadFile(errFile.Name())
+		if s := strings.TrimSpace(string(msg)); s != "" {
+			return "", fmt.Errorf("agent failed to start: %s", s)
+		}
+		return "", fmt.Errorf("agent failed to start: %w", err)
+	case <-time.After(spawnTimeout):
+		_ = cmd.Process.Kill()
+		return "", fmt.Errorf("agent did not get ready in %s", spawnTimeout)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package agent
+
+import "syscall"
+
+// detached returns nil on platforms without sessions.
+func detached() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,249 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// Magic opens every archive.
+const Magic = "GKBACKUP"
+
+// Version is the format version written by Write.
+const Version uint8 = 1
+
+// Limits guarding Read against corrupt or hostile archives.
+const (
+	maxHeaderSize = 64 * 1024
+	maxRecordSize = 64 * 1024 * 1024
+	maxRecords    = 1 << 20
+)
+
+// keySize is the size of the random key the MAC of an archive is computed with.
+const keySize = 32
+
+// keyBindingType is the secret type the archive key is bound to when it is wrapped.
+const keyBindingType = "archive"
+
+var (
+	// ErrInvalidArchive is returned for data that is not a well-formed archive.
+	ErrInvalidArchive = errors.New("invalid archive")
+	// ErrUnsupportedVersion is returned for an archive written by a newer format version.
+	ErrUnsupportedVersion = errors.New("unsupported archive version")
+	// ErrMACMismatch is returned when the integrity MAC of an archive does not verify.
+	ErrMACMismatch = errors.New("archive integrity check failed")
+)
+
+// Encryptor wraps the archive key.
+type Encryptor interface {
+	Encrypt(plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error)
+}
+
+// Decryptor unwraps the archive key.
+type Decryptor interface {
+	Decrypt(secret *models.SecretEncrypted, binding models.SecretBinding) ([]byte, error)
+}
+
+// Header describes an archive. It is written in the clear but covered by the MAC.
+type Header struct {
+	// Owner is the username whose secrets the archive holds; record ciphertexts are bound to it.
+	Owner string `json:"owner"`
+	// CreatedAt is when the archive was written.
+	CreatedAt time.Time `json:"created_at"`
+	// KDF holds the Argon2id parameters of a password-protected archive, whose records
+	// and key are encrypted to the vault key derived from the password. It is nil when
+	// the records are kept as encrypted to the owner's keys.
+	KDF *models.KDFParams `json:"kdf,omitempty"`
+	// Key is the archive key wrapped like a secret; the MAC is computed with it.
+	Key *models.SecretEncrypted `json:"key"`
+}
+
+// Binding returns the binding the archive key is wrapped with.
+func (h *Header) Binding() models.SecretBinding {
+	return models.SecretBinding{
+		Owner: h.Owner,
+		Type:  keyBindingType,
+		Name:  h.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// Archive is a portable backup of a vault: a versioned header, one record per secret
+// holding its models.Secret fields, and an HMAC-SHA256 over both.
+//
+// The binary layout is
+//
+//	"GKBACKUP" | version (1 byte) | header length (4 bytes) | header JSON |
+//	record count (4 bytes) | { record length (4 bytes) | record JSON }... | MAC (32 bytes)
+//
+// with big-endian integers. The MAC covers every byte before it.
+type Archive struct {
+	Header
+	Secrets []*models.Secret
+
+	version uint8
+	signed  []byte
+	mac     []byte
+}
+
+// Write encrypts a fresh archive key with encryptor, stores it in the header of a and
+// writes a to w with a MAC computed with the key. CreatedAt is set when it is zero.
+func Write(w io.Writer, a *Archive, encryptor Encryptor) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now().UTC()
+	}
+
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("archive key gen failed: %w", err)
+	}
+	defer clear(key)
+
+	wrapped, err := encryptor.Encrypt(key, a.Binding())
+	if err != nil {
+		return fmt.Errorf("failed to wrap archive key: %w", err)
+	}
+	a.Key = wrapped
+
+	var buf bytes.Buffer
+	buf.WriteString(Magic)
+	buf.WriteByte(Version)
+
+	header, err := json.Marshal(a.Header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive header: %w", err)
+	}
+	writeFrame(&buf, header)
+
+	binary.Write(&buf, binary.BigEndian, uint32(len(a.Secrets)))
+	for _, secret := range a.Secrets {
+		record, err := json.Marshal(secret)
+		if err != nil {
+			return fmt.Errorf("failed to marshal secret %s: %w", secret.SecretName, err)
+		}
+		writeFrame(&buf, record)
+	}
+
+	buf.Write(computeMAC(key, buf.Bytes()))
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	a.version = Version
+	return nil
+}
+
+// Read parses the archive in r. The archive is not yet trusted: call Verify
+// before using its secrets.
+func Read(r io.Reader) (*Archive, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if len(data) < len(Magic)+1+sha256.Size || string(data[:len(Magic)]) != Magic {
+		return nil, ErrInvalidArchive
+	}
+
+	a := &Archive{
+		version: data[len(Magic)],
+		signed:  data[:len(data)-sha256.Size],
+		mac:     data[len(data)-sha256.Size:],
+	}
+	if a.version != Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, a.version)
+	}
+
+	body := bytes.NewReader(a.signed[len(Magic)+1:])
+
+	header, err := readFrame(body, maxHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(header, &a.Header); err != nil {
+		return nil, fmt.Errorf("%w: bad header: %v", ErrInvalidArchive, err)
+	}
+	if a.Key == nil {
+		return nil, fmt.Errorf("%w: missing archive key", ErrInvalidArchive)
+	}
+
+	var count uint32
+	if err := binary.Read(body, binary.BigEndian, &count); err != nil {
+		return nil, fmt.Errorf("%w: truncated record count", ErrInvalidArchive)
+	}
+	if count > maxRecords {
+		return nil, fmt.Errorf("%w: too many records", ErrInvalidArchive)
+	}
+
+	a.Secrets = make([]*models.Secret, 0, count)
+	for i := uint32(0); i < count; i++ {
+		record, err := readFrame(body, maxRecordSize)
+		if err != nil {
+			return nil, err
+		}
+		var secret models.Secret
+		if err := json.Unmarshal(record, &secret); err != nil {
+			return nil, fmt.Errorf("%w: bad record %d: %v", ErrInvalidArchive, i, err)
+		}
+		a.Secrets = append(a.Secrets, &secret)
+	}
+	if body.Len() != 0 {
+		return nil, fmt.Errorf("%w: trailing data", ErrInvalidArchive)
+	}
+
+	return a, nil
+}
+
+// Verify unwraps the archive key with decryptor and checks the MAC of an archive returned by Read.
+func (a *Archive) Verify(decryptor Decryptor) error {
+	if a.signed == nil {
+		return fmt.Errorf("%w: archive was not read", ErrInvalidArchive)
+	}
+
+	key, err := decryptor.Decrypt(a.Key, a.Binding())
+	if err != nil {
+		return fmt.Errorf("failed to unwrap archive key: %w", err)
+	}
+	defer clear(key)
+
+	if !hmac.Equal(computeMAC(key, a.signed), a.mac) {
+		return ErrMACMismatch
+	}
+	return nil
+}
+
+// computeMAC returns the HMAC-SHA256 of data under key.
+func computeMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// writeFrame writes data prefixed with its length.
+func writeFrame(buf *bytes.Buffer, data []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+// readFrame reads data prefixed with its length, refusing frames over limit bytes.
+func readFrame(r *bytes.Reader, limit int) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, fmt.Errorf("%w: truncated frame", ErrInvalidArchive)
+	}
+	if int64(size) > int64(limit) || int64(size) > int64(r.Len()) {
+		return nil, fmt.Errorf("%w: frame of %d bytes", ErrInvalidArchive, size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("%w: truncated frame", ErrInvalidArchive)
+	}
+	return data, nil
+}
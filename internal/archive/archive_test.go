@@ -0,0 +1,85 @@
+package archive
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCryptor(t *testing.T) *cryptor.Cryptor {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	c, err := cryptor.New(cryptor.WithVaultKey(key))
+	require.NoError(t, err)
+	return c
+}
+
+func writeTestArchive(t *testing.T, c *cryptor.Cryptor) []byte {
+	t.Helper()
+	a := &Archive{
+		Header: Header{Owner: "alice", CreatedAt: time.Date(2025, time.August, 10, 12, 0, 0, 0, time.UTC)},
+		Secrets: []*models.Secret{
+			{SecretName: "github", SecretType: models.SecretTypeUser, Ciphertext: []byte("ct1"), AESKeyEnc: []byte("k1"), KeyID: "id"},
+			{SecretName: "note", SecretType: models.SecretTypeText, Ciphertext: []byte("ct2"), AESKeyEnc: []byte("k2"), KeyID: "id"},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, a, c))
+	require.NotNil(t, a.Key)
+	return buf.Bytes()
+}
+
+func TestWriteRead(t *testing.T) {
+	c := testCryptor(t)
+	data := writeTestArchive(t, c)
+	assert.Equal(t, Magic, string(data[:len(Magic)]))
+	assert.Equal(t, Version, data[len(Magic)])
+
+	a, err := Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.NoError(t, a.Verify(c))
+
+	assert.Equal(t, "alice", a.Owner)
+	assert.Nil(t, a.KDF)
+	require.Len(t, a.Secrets, 2)
+	assert.Equal(t, "github", a.Secrets[0].SecretName)
+	assert.Equal(t, []byte("ct2"), a.Secrets[1].Ciphertext)
+}
+
+func TestVerify_Tampered(t *testing.T) {
+	c := testCryptor(t)
+	data := writeTestArchive(t, c)
+
+	tampered := bytes.Replace(data, []byte(`"github"`), []byte(`"gitlab"`), 1)
+	require.NotEqual(t, data, tampered)
+	a, err := Read(bytes.NewReader(tampered))
+	require.NoError(t, err)
+	assert.ErrorIs(t, a.Verify(c), ErrMACMismatch)
+
+	a, err = Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Error(t, a.Verify(testCryptor(t)), "wrong key")
+
+	_, err = Read(bytes.NewReader(data[:len(data)-40]))
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+}
+
+func TestRead_Invalid(t *testing.T) {
+	_, err := Read(bytes.NewReader([]byte("not an archive at all, not even close to one")))
+	assert.ErrorIs(t, err, ErrInvalidArchive)
+
+	data := writeTestArchive(t, testCryptor(t))
+	data[len(Magic)] = Version + 1
+	_, err = Read(bytes.NewReader(data))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	assert.Error(t, (&Archive{}).Verify(testCryptor(t)))
+}
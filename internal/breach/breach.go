@@ -0,0 +1,119 @@
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PrefixLen is the number of hex characters of a SHA-1 hash a range file is named after.
+const PrefixLen = 5
+
+// ErrInvalidLine is returned for a dataset line that is not HASH:COUNT.
+var ErrInvalidLine = errors.New("invalid dataset line")
+
+// Dataset is a local copy of the Pwned Passwords dataset in the range format of the
+// k-anonymity API: either a directory of files named after a five-character hash prefix
+// (00000.txt .. FFFFF.txt) holding SUFFIX:COUNT lines, or a single file holding the
+// range files concatenated as full HASH:COUNT lines. Passwords never leave the client.
+type Dataset struct {
+	path string
+	dir  bool
+}
+
+// Open opens the dataset at path, a range directory or a single file.
+func Open(path string) (*Dataset, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach dataset: %w", err)
+	}
+	return &Dataset{path: path, dir: info.IsDir()}, nil
+}
+
+// Hash returns the upper-case hex SHA-1 hash of password, as used by the dataset.
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Lookup returns how many times each of hashes appears in the dataset.
+// Hashes that do not appear, or appear only as padding with a zero count, are absent from the result.
+func (d *Dataset) Lookup(hashes []string) (map[string]int, error) {
+	wanted := make(map[string]bool, len(hashes))
+	for _, h := range hashes {
+		wanted[strings.ToUpper(h)] = true
+	}
+
+	if !d.dir {
+		return d.scan(d.path, "", wanted)
+	}
+
+	prefixes := make(map[string]bool)
+	for h := range wanted {
+		if len(h) == sha1.Size*2 {
+			prefixes[h[:PrefixLen]] = true
+		}
+	}
+
+	counts := make(map[string]int)
+	for prefix := range prefixes {
+		path := filepath.Join(d.path, prefix+".txt")
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		found, err := d.scan(path, prefix, wanted)
+		if err != nil {
+			return nil, err
+		}
+		for h, n := range found {
+			counts[h] = n
+		}
+	}
+	return counts, nil
+}
+
+// scan reads the HASH:COUNT lines of path, prepending prefix to every hash,
+// and returns the counts of the wanted hashes.
+func (d *Dataset) scan(path, prefix string, wanted map[string]bool) (map[string]int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breach dataset: %w", err)
+	}
+	defer f.Close()
+
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		hash, count, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w %d of %s", ErrInvalidLine, line, path)
+		}
+		hash = prefix + strings.ToUpper(hash)
+		if !wanted[hash] {
+			continue
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, fmt.Errorf("%w %d of %s", ErrInvalidLine, line, path)
+		}
+		if n > 0 {
+			counts[hash] = n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breach dataset: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,64 @@
+package breach
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8.
+const passwordHash = "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func TestHash(t *testing.T) {
+	assert.Equal(t, passwordHash, Hash("password"))
+}
+
+func TestLookup_RangeDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(
+		"003D68EB55068C33ACE09247EE4C639306B:3\r\n"+
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD8:10434004\r\n"+
+			"1E4C9B93F3F0682250B6CF8331B7EE68FD9:0\r\n",
+	), 0o600))
+
+	d, err := Open(dir)
+	require.NoError(t, err)
+
+	counts, err := d.Lookup([]string{
+		passwordHash,
+		Hash("correct horse battery staple"),
+		"5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD9", // padding entry
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{passwordHash: 10434004}, counts)
+}
+
+func TestLookup_SingleFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1.txt")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"000000005AD76BD555C1D6D771DE417A4B87E4B4:10\n"+
+			"5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:10434004\n",
+	), 0o600))
+
+	d, err := Open(path)
+	require.NoError(t, err)
+
+	counts, err := d.Lookup([]string{passwordHash, Hash("hunter2-but-longer")})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{passwordHash: 10434004}, counts)
+}
+
+func TestLookup_Errors(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "broken.txt")
+	require.NoError(t, os.WriteFile(path, []byte(passwordHash+"\n"), 0o600))
+	d, err := Open(path)
+	require.NoError(t, err)
+	_, err = d.Lookup([]string{passwordHash})
+	assert.ErrorIs(t, err, ErrInvalidLine)
+}
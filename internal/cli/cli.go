@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Errors of parsing a command line.
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrMissingFlag    = errors.New("missing required flag")
+	ErrUnexpectedArgs = errors.New("unexpected arguments")
+)
+
+// FlagDef defines one flag on a flag set, binding it to its variable.
+type FlagDef func(fs *flag.FlagSet)
+
+// Command is a subcommand with its own flags.
+type Command struct {
+	Name    string
+	Summary string
+	// Flags lists the names of the flags the command accepts, defined with WithFlags.
+	Flags []string
+	// Required lists the flags that must be set.
+	Required []string
+	// Args describes the positional arguments in the usage line; without it none are accepted.
+	Args string
+	// Description follows the flags in the help of the command.
+	Description string
+	Examples    []string
+	Run         func(ctx context.Context, args []string) error
+}
+
+// Topic is a help page that is not a command, shown by help <topic>.
+type Topic struct {
+	Name    string
+	Summary string
+	Text    string
+}
+
+// App dispatches a command line to its command.
+type App struct {
+	name     string
+	commands []*Command
+	topics   []*Topic
+	flags    map[string]FlagDef
+	out      io.Writer
+}
+
+// Opt configures an App.
+type Opt func(*App)
+
+// WithCommands adds commands, listed in help in the order they are added.
+func WithCommands(commands ...*Command) Opt {
+	return func(a *App) {
+		a.commands = append(a.commands, commands...)
+	}
+}
+
+// WithTopics adds help topics.
+func WithTopics(topics ...*Topic) Opt {
+	return func(a *App) {
+		a.topics = append(a.topics, topics...)
+	}
+}
+
+// WithFlags sets the definitions of the flags commands refer to by name.
+func WithFlags(flags map[string]FlagDef) Opt {
+	return func(a *App) {
+		a.flags = flags
+	}
+}
+
+// WithOutput sets where help and completion scripts are written, os.Stdout by default.
+func WithOutput(w io.Writer) Opt {
+	return func(a *App) {
+		a.out = w
+	}
+}
+
+// New returns the app of the program called name.
+func New(name string, opts ...Opt) *App {
+	a := &App{name: name, out: os.Stdout}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Built-in commands.
+const (
+	commandHelp       = "help"
+	commandCompletion = "completion"
+)
+
+// Run runs the command named in args, the arguments without the program name. Flags may come
+// before and after the command; arguments after -- are passed to the command as they are.
+// Defining all flags first also sets every flag variable to its default.
+func (a *App) Run(ctx context.Context, args []string) error {
+	all, err := a.flagSet("", a.allFlags())
+	if err != nil {
+		return err
+	}
+
+	i := commandIndex(all, args)
+	if i < 0 {
+		if slices.ContainsFunc(args, isHelpFlag) || len(args) == 0 {
+			fmt.Fprint(a.out, a.Help())
+			return nil
+		}
+		return fmt.Errorf("%w, run '%s help' for the list of commands", ErrUnknownCommand, a.name)
+	}
+	name := args[i]
+	rest := append(slices.Clone(args[:i]), args[i+1:]...)
+
+	switch name {
+	case commandHelp:
+		return a.runHelp(rest)
+	case commandCompletion:
+		if len(rest) != 1 {
+			return fmt.Errorf("usage: %s completion bash|zsh|fish", a.name)
+		}
+		script, err := a.Completion(rest[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(a.out, script)
+		return nil
+	}
+
+	cmd := a.command(name)
+	if cmd == nil {
+		return fmt.Errorf("%w %q, run '%s help' for the list of commands", ErrUnknownCommand, name, a.name)
+	}
+
+	fs, err := a.flagSet(cmd.Name, cmd.Flags)
+	if err != nil {
+		return err
+	}
+	positional, err := parseInterspersed(fs, rest)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprint(a.out, a.commandHelp(cmd, fs))
+		return nil
+	}
+	if err == nil {
+		err = checkArgs(cmd, fs, positional)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w\nRun '%s help %s' for usage.", cmd.Name, err, a.name, cmd.Name)
+	}
+	return cmd.Run(ctx, positional)
+}
+
+// runHelp prints the help of the app, a command or a topic.
+func (a *App) runHelp(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(a.out, a.Help())
+		return nil
+	}
+	help, err := a.CommandHelp(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(a.out, help)
+	return nil
+}
+
+// command returns the command called name, or nil.
+func (a *App) command(name string) *Command {
+	for _, cmd := range a.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// allFlags returns the names of all defined flags, sorted.
+func (a *App) allFlags() []string {
+	names := make([]string, 0, len(a.flags))
+	for name := range a.flags {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// flagSet returns a flag set of the named flags that reports errors instead of printing them.
+func (a *App) flagSet(name string, flags []string) (*flag.FlagSet, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	for _, f := range flags {
+		def, ok := a.flags[f]
+		if !ok {
+			return nil, fmt.Errorf("command %s refers to undefined flag --%s", name, f)
+		}
+		def(fs)
+	}
+	return fs, nil
+}
+
+// commandIndex returns the index of the first argument that is neither a flag nor the value
+// of one, or -1. The value of a flag is told apart by whether the flag takes one.
+func commandIndex(all *flag.FlagSet, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return -1
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i
+		}
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		if f := all.Lookup(name); f != nil && !isBoolFlag(f) {
+			i++
+		}
+	}
+	return -1
+}
+
+// parseInterspersed parses flags mixed with positional arguments, which it returns.
+// Everything after -- is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// checkArgs checks that the required flags are set and that positional arguments are accepted.
+func checkArgs(cmd *Command, fs *flag.FlagSet, positional []string) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var missing []string
+	for _, name := range cmd.Required {
+		if !set[name] {
+			missing = append(missing, "--"+name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingFlag, strings.Join(missing, ", "))
+	}
+	if cmd.Args == "" && len(positional) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnexpectedArgs, strings.Join(positional, " "))
+	}
+	return nil
+}
+
+// isBoolFlag reports whether f takes no value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testApp returns an app with an add and a run command, recording the arguments of the last run.
+func testApp(out *bytes.Buffer) (*App, *[]string, *string, *bool) {
+	var (
+		name    string
+		verbose bool
+		args    []string
+	)
+	flags := map[string]FlagDef{
+		"name":    func(fs *flag.FlagSet) { fs.StringVar(&name, "name", "", "Secret `name`") },
+		"verbose": func(fs *flag.FlagSet) { fs.BoolVar(&verbose, "verbose", false, "Print more") },
+		"format":  func(fs *flag.FlagSet) { fs.String("format", "text", "Output format") },
+	}
+	record := func(_ context.Context, a []string) error {
+		args = a
+		return nil
+	}
+	app := New("keeper",
+		WithFlags(flags),
+		WithOutput(out),
+		WithCommands(
+			&Command{
+				Name:        "add",
+				Summary:     "Add a secret",
+				Flags:       []string{"name", "verbose", "format"},
+				Required:    []string{"name"},
+				Description: "Secrets are saved locally.",
+				Examples:    []string{"add --name note"},
+				Run:         record,
+			},
+			&Command{Name: "run", Summary: "Run a command", Flags: []string{"verbose"}, Args: "[-- command]", Run: record},
+		),
+		WithTopics(&Topic{Name: "keys", Summary: "Key formats", Text: "PEM only."}),
+	)
+	return app, &args, &name, &verbose
+}
+
+func TestApp_Run(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	app, args, name, verbose := testApp(&out)
+
+	// Flags may come before and after the command.
+	require.NoError(t, app.Run(ctx, []string{"--verbose", "add", "--name", "note"}))
+	assert.Equal(t, "note", *name)
+	assert.True(t, *verbose)
+
+	require.NoError(t, app.Run(ctx, []string{"--name", "add", "add"}), "a flag value is not taken for the command")
+	assert.Equal(t, "add", *name)
+	assert.False(t, *verbose, "flags are reset to their defaults on every run")
+
+	require.NoError(t, app.Run(ctx, []string{"run", "--verbose", "--", "ls", "-la", "--verbose"}))
+	assert.Equal(t, []string{"ls", "-la", "--verbose"}, *args)
+	require.NoError(t, app.Run(ctx, []string{"run", "echo", "--verbose", "hi"}))
+	assert.Equal(t, []string{"echo", "hi"}, *args)
+	assert.True(t, *verbose)
+
+	err := app.Run(ctx, []string{"add", "--verbose"})
+	assert.ErrorIs(t, err, ErrMissingFlag)
+	assert.Contains(t, err.Error(), "--name")
+	assert.Contains(t, err.Error(), "Run 'keeper help add' for usage.")
+
+	assert.ErrorIs(t, app.Run(ctx, []string{"add", "--name", "n", "extra"}), ErrUnexpectedArgs)
+	assert.ErrorIs(t, app.Run(ctx, []string{"delete"}), ErrUnknownCommand)
+	assert.ErrorIs(t, app.Run(ctx, []string{"--verbose"}), ErrUnknownCommand)
+	assert.Error(t, app.Run(ctx, []string{"add", "--unknown"}))
+}
+
+func TestApp_Help(t *testing.T) {
+	ctx := context.Background()
+	var out bytes.Buffer
+	app, _, _, _ := testApp(&out)
+
+	require.NoError(t, app.Run(ctx, nil))
+	help := out.String()
+	assert.Contains(t, help, "Usage:\n  keeper <command> [flags]")
+	assert.Contains(t, help, "  add         Add a secret\n")
+	assert.Contains(t, help, "  completion  Print a completion script")
+	assert.Contains(t, help, "  keys        Key formats\n")
+
+	out.Reset()
+	require.NoError(t, app.Run(ctx, []string{"add", "--help"}))
+	assert.Equal(t, `Usage:
+  keeper add [flags]
+
+Add a secret
+
+Flags:
+  --name name      Secret name (required)
+  --verbose        Print more
+  --format string  Output format (default "text")
+
+  Secrets are saved locally.
+
+Examples:
+  keeper add --name note
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, app.Run(ctx, []string{"help", "run"}))
+	assert.True(t, strings.HasPrefix(out.String(), "Usage:\n  keeper run [flags] [-- command]\n"))
+
+	out.Reset()
+	require.NoError(t, app.Run(ctx, []string{"help", "keys"}))
+	assert.Equal(t, "PEM only.\n", out.String())
+
+	_, err := app.CommandHelp("delete")
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+}
+
+func TestApp_Completion(t *testing.T) {
+	var out bytes.Buffer
+	app, _, _, _ := testApp(&out)
+
+	bash, err := app.Completion(ShellBash)
+	require.NoError(t, err)
+	assert.Contains(t, bash, `local commands="add run completion help"`)
+	assert.Contains(t, bash, `add) opts="--name --verbose --format" ;;`)
+	assert.Contains(t, bash, "complete -F _keeper keeper\n")
+
+	zsh, err := app.Completion(ShellZsh)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(zsh, "#compdef keeper\n"))
+	assert.Contains(t, zsh, `add) opts=('--name:Secret name' '--verbose:Print more' '--format:Output format') ;;`)
+
+	fish, err := app.Completion(ShellFish)
+	require.NoError(t, err)
+	assert.Contains(t, fish, "complete -c keeper -n __fish_use_subcommand -a add -d 'Add a secret'\n")
+	assert.Contains(t, fish, "complete -c keeper -n '__fish_seen_subcommand_from add' -l name -r -F -d 'Secret name'\n")
+	assert.Contains(t, fish, "complete -c keeper -n '__fish_seen_subcommand_from add' -l verbose -d 'Print more'\n")
+
+	_, err = app.Completion("tcsh")
+	assert.Error(t, err)
+
+	require.NoError(t, app.Run(context.Background(), []string{"completion", "bash"}))
+	assert.Equal(t, bash, out.String())
+}
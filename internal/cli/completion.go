@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Shells completion scripts are generated for.
+const (
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+)
+
+// completionCommand is a command as completion scripts see it.
+type completionCommand struct {
+	name    string
+	summary string
+	flags   []*flag.Flag
+}
+
+// completionCommands returns the commands, the built-in ones included, with their flags.
+func (a *App) completionCommands() ([]completionCommand, error) {
+	var cmds []completionCommand
+	for _, cmd := range a.commands {
+		fs, err := a.flagSet(cmd.Name, cmd.Flags)
+		if err != nil {
+			return nil, err
+		}
+		c := completionCommand{name: cmd.Name, summary: cmd.Summary}
+		for _, name := range cmd.Flags {
+			c.flags = append(c.flags, fs.Lookup(name))
+		}
+		cmds = append(cmds, c)
+	}
+	return append(cmds,
+		completionCommand{name: commandCompletion, summary: "Print a completion script"},
+		completionCommand{name: commandHelp, summary: "Show the flags of a command"},
+	), nil
+}
+
+// Completion returns the completion script of the commands and their flags for shell:
+// ShellBash, ShellZsh or ShellFish.
+func (a *App) Completion(shell string) (string, error) {
+	cmds, err := a.completionCommands()
+	if err != nil {
+		return "", err
+	}
+	switch shell {
+	case ShellBash:
+		return a.bashCompletion(cmds), nil
+	case ShellZsh:
+		return a.zshCompletion(cmds), nil
+	case ShellFish:
+		return a.fishCompletion(cmds), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q, use bash, zsh or fish", shell)
+}
+
+// funcName returns the name of the completion function of the app.
+func (a *App) funcName() string {
+	return "_" + strings.NewReplacer("-", "_", ".", "_").Replace(a.name)
+}
+
+func (a *App) bashCompletion(cmds []completionCommand) string {
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c.name)
+	}
+
+	var b strings.Builder
+	fn := a.funcName()
+	fmt.Fprintf(&b, "# bash completion for %s, load with: source <(%s completion bash)\n", a.name, a.name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("  local cur=\"${COMP_WORDS[COMP_CWORD]}\" cmd=\"\" opts=\"\" i\n")
+	fmt.Fprintf(&b, "  local commands=\"%s\"\n", strings.Join(names, " "))
+	b.WriteString("  for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("    if [[ \" $commands \" == *\" ${COMP_WORDS[i]} \"* ]]; then\n")
+	b.WriteString("      cmd=\"${COMP_WORDS[i]}\"\n      break\n    fi\n  done\n")
+	b.WriteString("  if [[ -z \"$cmd\" && \"$cur\" != -* ]]; then\n")
+	b.WriteString("    COMPREPLY=($(compgen -W \"$commands\" -- \"$cur\"))\n    return\n  fi\n")
+	b.WriteString("  case \"$cmd\" in\n")
+	for _, c := range cmds {
+		var flags []string
+		for _, f := range c.flags {
+			flags = append(flags, "--"+f.Name)
+		}
+		switch c.name {
+		case commandHelp:
+			flags = names
+		case commandCompletion:
+			flags = []string{ShellBash, ShellZsh, ShellFish}
+		}
+		fmt.Fprintf(&b, "    %s) opts=\"%s\" ;;\n", c.name, strings.Join(flags, " "))
+	}
+	b.WriteString("  esac\n")
+	b.WriteString("  if [[ \"$cur\" == -* || \"$cmd\" == help || \"$cmd\" == completion ]]; then\n")
+	b.WriteString("    COMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))\n")
+	b.WriteString("  else\n    COMPREPLY=($(compgen -f -- \"$cur\"))\n  fi\n")
+	fmt.Fprintf(&b, "}\ncomplete -F %s %s\n", fn, a.name)
+	return b.String()
+}
+
+func (a *App) zshCompletion(cmds []completionCommand) string {
+	// zshQuote escapes s for a single-quoted zsh word. Descriptions follow the first colon
+	// of a name:description word, so their own colons need no escaping.
+	zshQuote := func(s string) string {
+		return strings.ReplaceAll(s, "'", `'\''`)
+	}
+
+	var b strings.Builder
+	fn := a.funcName()
+	fmt.Fprintf(&b, "#compdef %s\n# zsh completion for %s, load with: source <(%s completion zsh)\n", a.name, a.name, a.name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("  local -a commands opts\n  local cmd word\n  commands=(\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "    '%s:%s'\n", c.name, zshQuote(c.summary))
+	}
+	b.WriteString("  )\n")
+	b.WriteString("  for word in ${words[2,CURRENT-1]}; do\n")
+	b.WriteString("    if [[ -n ${(M)commands:#${word}:*} ]]; then\n")
+	b.WriteString("      cmd=$word\n      break\n    fi\n  done\n")
+	b.WriteString("  if [[ -z $cmd && $PREFIX != -* ]]; then\n")
+	b.WriteString("    _describe 'command' commands\n    return\n  fi\n")
+	b.WriteString("  case $cmd in\n")
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "    %s) opts=(", c.name)
+		switch c.name {
+		case commandHelp:
+			b.WriteString("${commands[@]}")
+		case commandCompletion:
+			b.WriteString("'bash:Bash' 'zsh:Zsh' 'fish:Fish'")
+		default:
+			for i, f := range c.flags {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				_, usage := flag.UnquoteUsage(f)
+				fmt.Fprintf(&b, "'--%s:%s'", f.Name, zshQuote(usage))
+			}
+		}
+		b.WriteString(") ;;\n")
+	}
+	b.WriteString("  esac\n")
+	b.WriteString("  if [[ $PREFIX == -* || $cmd == help || $cmd == completion ]]; then\n")
+	b.WriteString("    _describe 'option' opts\n  else\n    _files\n  fi\n")
+	fmt.Fprintf(&b, "}\ncompdef %s %s\n", fn, a.name)
+	return b.String()
+}
+
+func (a *App) fishCompletion(cmds []completionCommand) string {
+	// fishQuote quotes s as a single-quoted fish string.
+	fishQuote := func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+	}
+
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c.name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s, load with: %s completion fish | source\n", a.name, a.name)
+	fmt.Fprintf(&b, "complete -c %s -f\n", a.name)
+	for _, c := range cmds {
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %s -d %s\n", a.name, c.name, fishQuote(c.summary))
+	}
+	for _, c := range cmds {
+		cond := fishQuote("__fish_seen_subcommand_from " + c.name)
+		switch c.name {
+		case commandHelp:
+			fmt.Fprintf(&b, "complete -c %s -n %s -a %s\n", a.name, cond, fishQuote(strings.Join(names, " ")))
+		case commandCompletion:
+			fmt.Fprintf(&b, "complete -c %s -n %s -a 'bash zsh fish'\n", a.name, cond)
+		}
+		for _, f := range c.flags {
+			_, usage := flag.UnquoteUsage(f)
+			argument := " -r -F"
+			if isBoolFlag(f) {
+				argument = ""
+			}
+			fmt.Fprintf(&b, "complete -c %s -n %s -l %s%s -d %s\n", a.name, cond, f.Name, argument, fishQuote(usage))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Help returns the usage of the app: its commands and help topics.
+func (a *App) Help() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage:\n  %s <command> [flags]\n\nCommands:\n", a.name)
+
+	width := len(commandCompletion)
+	for _, cmd := range a.commands {
+		width = max(width, len(cmd.Name))
+	}
+	for _, cmd := range a.commands {
+		fmt.Fprintf(&b, "  %-*s  %s\n", width, cmd.Name, cmd.Summary)
+	}
+	fmt.Fprintf(&b, "  %-*s  %s\n", width, commandCompletion, "Print a completion script for bash, zsh or fish")
+	fmt.Fprintf(&b, "  %-*s  %s\n", width, commandHelp, "Show the flags of a command or a help topic")
+
+	if len(a.topics) > 0 {
+		b.WriteString("\nHelp topics:\n")
+		for _, t := range a.topics {
+			fmt.Fprintf(&b, "  %-*s  %s\n", width, t.Name, t.Summary)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nRun '%s help <command>' for the flags of a command. Flags may come before or after it.\n", a.name)
+	return b.String()
+}
+
+// CommandHelp returns the help of the command or the help topic called name.
+func (a *App) CommandHelp(name string) (string, error) {
+	for _, t := range a.topics {
+		if t.Name == name {
+			return t.Text + "\n", nil
+		}
+	}
+
+	switch name {
+	case commandHelp:
+		return fmt.Sprintf("Usage:\n  %s help [command|topic]\n", a.name), nil
+	case commandCompletion:
+		return fmt.Sprintf("Usage:\n  %s completion bash|zsh|fish\n\n"+
+			"Prints a completion script of the commands and their flags. Load it with\n"+
+			"  source <(%s completion bash)\n", a.name, a.name), nil
+	}
+
+	cmd := a.command(name)
+	if cmd == nil {
+		return "", fmt.Errorf("%w %q, run '%s help' for the list of commands", ErrUnknownCommand, name, a.name)
+	}
+	fs, err := a.flagSet(cmd.Name, cmd.Flags)
+	if err != nil {
+		return "", err
+	}
+	return a.commandHelp(cmd, fs), nil
+}
+
+// commandHelp renders the help of cmd with its flag set fs.
+func (a *App) commandHelp(cmd *Command, fs *flag.FlagSet) string {
+	var b strings.Builder
+	usage := fmt.Sprintf("%s %s", a.name, cmd.Name)
+	if len(cmd.Flags) > 0 {
+		usage += " [flags]"
+	}
+	if cmd.Args != "" {
+		usage += " " + cmd.Args
+	}
+	fmt.Fprintf(&b, "Usage:\n  %s\n\n%s\n", usage, cmd.Summary)
+
+	if len(cmd.Flags) > 0 {
+		b.WriteString("\nFlags:\n")
+		b.WriteString(flagUsages(cmd, fs))
+	}
+	if cmd.Description != "" {
+		b.WriteString("\n" + indent(cmd.Description) + "\n")
+	}
+	if len(cmd.Examples) > 0 {
+		b.WriteString("\nExamples:\n")
+		for _, e := range cmd.Examples {
+			fmt.Fprintf(&b, "  %s %s\n", a.name, e)
+		}
+	}
+	return b.String()
+}
+
+// flagUsages lists the flags of cmd in the order the command lists them.
+func flagUsages(cmd *Command, fs *flag.FlagSet) string {
+	required := make(map[string]bool)
+	for _, name := range cmd.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(cmd.Flags))
+	width := 0
+	for _, name := range cmd.Flags {
+		f := fs.Lookup(name)
+		kind, _ := flag.UnquoteUsage(f)
+		if kind != "" {
+			name += " " + kind
+		}
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+
+	var b strings.Builder
+	for i, name := range cmd.Flags {
+		f := fs.Lookup(name)
+		kind, usage := flag.UnquoteUsage(f)
+		switch {
+		case required[name]:
+			usage += " (required)"
+		case !isZeroDefault(f) && kind == "string":
+			usage += fmt.Sprintf(" (default %q)", f.DefValue)
+		case !isZeroDefault(f):
+			usage += fmt.Sprintf(" (default %s)", f.DefValue)
+		}
+		fmt.Fprintf(&b, "  --%-*s  %s\n", width, names[i], usage)
+	}
+	return b.String()
+}
+
+// isZeroDefault reports whether the default of f is the zero value of its type.
+func isZeroDefault(f *flag.Flag) bool {
+	switch f.DefValue {
+	case "", "0", "0s", "false", "[]":
+		return true
+	}
+	return false
+}
+
+// indent indents every non-empty line of s by two spaces.
+func indent(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
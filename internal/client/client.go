@@ -2,36 +2,78 @@ package client
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"golang.org/x/crypto/ssh"
+
+	"github.com/sbilibin2017/gophkeeper/internal/archive"
+	"github.com/sbilibin2017/gophkeeper/internal/breach"
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/generator"
+	"github.com/sbilibin2017/gophkeeper/internal/health"
+	"github.com/sbilibin2017/gophkeeper/internal/importer"
+	"github.com/sbilibin2017/gophkeeper/internal/jwt"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/otp"
 )
 
 // Registerer defines the interface for registering a new user.
 type Registerer interface {
-	Register(ctx context.Context, username string, password string) (*string, error)
+	Register(ctx context.Context, username string, password string, kdfParams *models.KDFParams) (*string, error)
+}
+
+// KDFParamsGetter defines the interface for fetching the master-password KDF parameters of a user.
+type KDFParamsGetter interface {
+	GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error)
 }
 
 // Loginer defines the interface for logging in a user.
 type Loginer interface {
-	Login(ctx context.Context, username string, password string) (*string, error)
+	Login(ctx context.Context, username string, password string, otpCode string) (*string, error)
+}
+
+// OTPEnroller defines the interface for starting two-factor authentication enrolment.
+type OTPEnroller interface {
+	EnrollOTP(ctx context.Context, token string) (*models.OTPEnrollment, error)
+}
+
+// OTPConfirmer defines the interface for confirming two-factor authentication enrolment.
+type OTPConfirmer interface {
+	ConfirmOTP(ctx context.Context, token string, code string) error
+}
+
+// PasswordChanger defines the interface for changing the account password.
+type PasswordChanger interface {
+	ChangePassword(ctx context.Context, token string, oldPassword string, newPassword string) (*string, error)
+}
+
+// AccountDeleter defines the interface for deleting the account.
+type AccountDeleter interface {
+	DeleteAccount(ctx context.Context, token string, password string) error
 }
 
+// ErrAborted is returned when the user declines a confirmation prompt.
+var ErrAborted = errors.New("aborted")
+
 // Encryptor defines the interface for encrypting plaintext data.
 type Encryptor interface {
-	Encrypt(plaintext []byte) (*models.SecretEncrypted, error)
+	Encrypt(plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error)
 }
 
 // Decryptor defines the interface for decrypting SecretEncrypted secrets.
 type Decryptor interface {
-	Decrypt(secret *models.SecretEncrypted) ([]byte, error)
+	Decrypt(secret *models.SecretEncrypted, binding models.SecretBinding) ([]byte, error)
 }
 
 // ClientSaver defines the interface for saving secrets from the client.
@@ -43,9 +85,16 @@ type ClientSaver interface {
 		secretType string,
 		ciphertext []byte,
 		aesKeyEnc []byte,
+		keyID string,
+		recipients models.Recipients,
 	) error
 }
 
+// ClientDeleter defines the interface for deleting secrets from the client.
+type ClientDeleter interface {
+	Delete(ctx context.Context, secretOwner string, secretType string, secretName string) error
+}
+
 // ClientLister defines the interface for listing secrets from the client.
 type ClientLister interface {
 	List(ctx context.Context, secretOwner string) ([]*models.Secret, error)
@@ -75,9 +124,16 @@ type ServerSaver interface {
 		secretType string,
 		ciphertext []byte,
 		aesKeyEnc []byte,
+		keyID string,
+		recipients models.Recipients,
 	) error
 }
 
+// SecretBatchSaver defines the interface for saving several secrets atomically.
+type SecretBatchSaver interface {
+	SaveBatch(ctx context.Context, secretOwner string, secrets []*models.Secret) error
+}
+
 // ClientResolver defines the interface for client-side synchronization of secrets.
 type ClientResolver interface {
 	Resolve(ctx context.Context, secretOwner string) error
@@ -88,6 +144,106 @@ type InteractiveResolver interface {
 	Resolve(ctx context.Context, secretOwner string, reader io.Reader) error
 }
 
+// AuditLister defines the interface for reading the audit log from the server.
+type AuditLister interface {
+	List(ctx context.Context, token string, limit int) ([]*models.AuditEvent, error)
+}
+
+// CertificatePublisher defines the interface for publishing the certificate of a user.
+type CertificatePublisher interface {
+	PublishCertificate(ctx context.Context, token string, certificate string) error
+}
+
+// CertificateGetter defines the interface for reading the certificate another user published.
+type CertificateGetter interface {
+	GetCertificate(ctx context.Context, token string, username string) (string, error)
+}
+
+// SecretSharer defines the interface for sharing secrets with other users.
+type SecretSharer interface {
+	Share(
+		ctx context.Context,
+		token string,
+		secretType string,
+		secretName string,
+		recipient string,
+		aesKeyEnc []byte,
+		keyID string,
+		permission string,
+	) error
+	Unshare(ctx context.Context, token string, secretType string, secretName string, recipient string) error
+}
+
+// SharedLister defines the interface for listing secrets other users share.
+type SharedLister interface {
+	ListSharedWithMe(ctx context.Context, token string) ([]*models.SharedSecret, error)
+}
+
+// SharedSaver defines the interface for updating a secret shared read-write.
+type SharedSaver interface {
+	SaveShared(
+		ctx context.Context,
+		token string,
+		secretOwner string,
+		secretType string,
+		secretName string,
+		ciphertext []byte,
+	) error
+}
+
+// KeyWrapper defines the interface for wrapping the data key of a secret for a certificate.
+type KeyWrapper interface {
+	WrapFor(enc *models.SecretEncrypted, certPEM []byte) (*models.Recipient, error)
+}
+
+// Resealer defines the interface for encrypting new content with the data key of a secret.
+type Resealer interface {
+	Reseal(enc *models.SecretEncrypted, binding models.SecretBinding, plaintext []byte) ([]byte, error)
+}
+
+// Upgrader defines the interface for resealing a ciphertext in the current envelope format.
+type Upgrader interface {
+	Upgrade(enc *models.SecretEncrypted, binding models.SecretBinding) (bool, error)
+}
+
+// Reencryptor defines the interface for encrypting a secret over its stored version,
+// reusing the data key of prev when the keys it is wrapped for are unchanged.
+type Reencryptor interface {
+	Reencrypt(prev *models.SecretEncrypted, plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error)
+}
+
+// storedKeyEncryptor is the Encryptor returned by StoredKeyEncryptor.
+type storedKeyEncryptor struct {
+	ctx         context.Context
+	getter      ServerGetter
+	reencryptor Reencryptor
+	token       string
+}
+
+// StoredKeyEncryptor returns an Encryptor that encrypts the secrets of the token owner already
+// in getter over their stored version. Editing a secret then keeps its data key, and so the keys
+// the owner wrapped for the recipients of its shares, which the server would otherwise mark stale.
+func StoredKeyEncryptor(ctx context.Context, getter ServerGetter, reencryptor Reencryptor, token string) Encryptor {
+	return &storedKeyEncryptor{ctx: ctx, getter: getter, reencryptor: reencryptor, token: token}
+}
+
+func (e *storedKeyEncryptor) Encrypt(plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error) {
+	var prev *models.SecretEncrypted
+	secret, err := e.getter.Get(e.ctx, e.token, binding.Type, binding.Name)
+	switch {
+	case err == nil:
+		prev = &models.SecretEncrypted{
+			Ciphertext: secret.Ciphertext,
+			AESKeyEnc:  secret.AESKeyEnc,
+			KeyID:      secret.KeyID,
+			Recipients: secret.Recipients,
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	return e.reencryptor.Reencrypt(prev, plaintext, binding)
+}
+
 // ClientRegister registers a new user with a username and password.
 // It returns an authentication token on success.
 func ClientRegister(
@@ -96,7 +252,36 @@ func ClientRegister(
 	username string,
 	password string,
 ) (string, error) {
-	tokenPtr, err := registerer.Register(ctx, username, password)
+	tokenPtr, err := registerer.Register(ctx, username, password, nil)
+	if err != nil {
+		return "", err
+	}
+	if tokenPtr == nil {
+		return "", errors.New("registration returned nil token")
+	}
+	return *tokenPtr, nil
+}
+
+// ClientRegisterMaster registers a new user in master-password mode.
+// Fresh KDF parameters are generated and sent to the server together with the authentication key
+// derived from masterPassword; neither the master password nor the vault key leaves the client.
+// It returns an authentication token on success.
+func ClientRegisterMaster(
+	ctx context.Context,
+	registerer Registerer,
+	username string,
+	masterPassword string,
+) (string, error) {
+	params, err := cryptor.NewKDFParams()
+	if err != nil {
+		return "", err
+	}
+	keys, err := cryptor.DeriveMasterKeys(masterPassword, *params)
+	if err != nil {
+		return "", err
+	}
+
+	tokenPtr, err := registerer.Register(ctx, username, keys.AuthKey, params)
 	if err != nil {
 		return "", err
 	}
@@ -106,15 +291,70 @@ func ClientRegister(
 	return *tokenPtr, nil
 }
 
-// ClientLogin logs in an existing user with username and password.
+// ClientLoginMaster logs in a user registered in master-password mode. The KDF parameters
+// are fetched from the server and the authentication key derived from masterPassword
+// is used as the password, see ClientLogin.
+func ClientLoginMaster(
+	ctx context.Context,
+	loginer Loginer,
+	getter KDFParamsGetter,
+	username string,
+	masterPassword string,
+	otpCode string,
+	reader io.Reader,
+) (string, error) {
+	keys, err := deriveMasterKeys(ctx, getter, username, masterPassword)
+	if err != nil {
+		return "", err
+	}
+	return ClientLogin(ctx, loginer, username, keys.AuthKey, otpCode, reader)
+}
+
+// ClientMasterKeys derives the master keys of the token owner from masterPassword:
+// the vault key that encrypts the secrets and the authentication key that replaces the password.
+func ClientMasterKeys(
+	ctx context.Context,
+	getter KDFParamsGetter,
+	token string,
+	masterPassword string,
+) (*cryptor.MasterKeys, error) {
+	username, err := jwt.Username(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+	return deriveMasterKeys(ctx, getter, username, masterPassword)
+}
+
+// deriveMasterKeys fetches the KDF parameters of username and derives the master keys from masterPassword.
+func deriveMasterKeys(ctx context.Context, getter KDFParamsGetter, username, masterPassword string) (*cryptor.MasterKeys, error) {
+	params, err := getter.GetKDFParams(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get KDF parameters: %w", err)
+	}
+	return cryptor.DeriveMasterKeys(masterPassword, *params)
+}
+
+// ClientLogin logs in an existing user with username, password and optional one-time code.
+// If the server asks for a one-time code that was not given, the code is read from reader
+// after a prompt and the login is retried once.
 // It returns an authentication token on success.
 func ClientLogin(
 	ctx context.Context,
 	loginer Loginer,
 	username string,
 	password string,
+	otpCode string,
+	reader io.Reader,
 ) (string, error) {
-	tokenPtr, err := loginer.Login(ctx, username, password)
+	tokenPtr, err := loginer.Login(ctx, username, password, otpCode)
+	if errors.Is(err, models.ErrOTPRequired) && otpCode == "" && reader != nil {
+		fmt.Print("Enter one-time code: ")
+		scanner := bufio.NewScanner(reader)
+		if !scanner.Scan() {
+			return "", models.ErrOTPRequired
+		}
+		tokenPtr, err = loginer.Login(ctx, username, password, strings.TrimSpace(scanner.Text()))
+	}
 	if err != nil {
 		return "", err
 	}
@@ -124,6 +364,99 @@ func ClientLogin(
 	return *tokenPtr, nil
 }
 
+// ClientEnrollOTP starts two-factor authentication enrolment for the token owner.
+// It returns a text with the otpauth URI to add to an authenticator app and the recovery codes.
+func ClientEnrollOTP(
+	ctx context.Context,
+	enroller OTPEnroller,
+	token string,
+) (string, error) {
+	enrollment, err := enroller.EnrollOTP(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if enrollment == nil {
+		return "", errors.New("otp enrollment returned nil result")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Add this URI to your authenticator app:\n")
+	sb.WriteString(enrollment.URI)
+	sb.WriteString("\n\nRecovery codes (each can be used once instead of a one-time code):\n")
+	for _, code := range enrollment.RecoveryCodes {
+		sb.WriteString(code)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nRun otp-confirm with a current code to enable two-factor authentication.")
+	return sb.String(), nil
+}
+
+// ClientConfirmOTP enables two-factor authentication with a code from the authenticator app.
+func ClientConfirmOTP(
+	ctx context.Context,
+	confirmer OTPConfirmer,
+	token string,
+	code string,
+) error {
+	if code == "" {
+		return errors.New("one-time code is empty")
+	}
+	return confirmer.ConfirmOTP(ctx, token, code)
+}
+
+// ClientChangePassword changes the account password after the user confirms on reader
+// that all existing sessions will be signed out.
+// It returns the new authentication token on success.
+func ClientChangePassword(
+	ctx context.Context,
+	changer PasswordChanger,
+	token string,
+	oldPassword string,
+	newPassword string,
+	reader io.Reader,
+) (string, error) {
+	fmt.Print("All existing sessions will be signed out. Continue? [y/N]: ")
+	answer := readLine(reader)
+	if answer != "y" && answer != "yes" {
+		return "", ErrAborted
+	}
+
+	tokenPtr, err := changer.ChangePassword(ctx, token, oldPassword, newPassword)
+	if err != nil {
+		return "", err
+	}
+	if tokenPtr == nil {
+		return "", errors.New("change password returned nil token")
+	}
+	return *tokenPtr, nil
+}
+
+// ClientDeleteAccount deletes the account and all its secrets on the server
+// after the user types "delete" on reader.
+func ClientDeleteAccount(
+	ctx context.Context,
+	deleter AccountDeleter,
+	token string,
+	password string,
+	reader io.Reader,
+) error {
+	fmt.Print("The account and all its secrets will be deleted permanently. Type \"delete\" to confirm: ")
+	if readLine(reader) != "delete" {
+		return ErrAborted
+	}
+
+	return deleter.DeleteAccount(ctx, token, password)
+}
+
+// readLine reads one trimmed, lower-cased line from reader. It returns an empty string on EOF.
+func readLine(reader io.Reader) string {
+	scanner := bufio.NewScanner(reader)
+	if !scanner.Scan() {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(scanner.Text()))
+}
+
 // ClientAddBankcard encrypts and saves a bankcard secret.
 func ClientAddBankcard(
 	ctx context.Context,
@@ -155,7 +488,12 @@ func ClientAddBankcard(
 		return fmt.Errorf("failed to marshal bankcard payload: %w", err)
 	}
 
-	SecretEncrypted, err := encryptor.Encrypt(plaintext)
+	binding, err := tokenBinding(token, models.SecretTypeBankCard, secretName)
+	if err != nil {
+		return err
+	}
+
+	SecretEncrypted, err := encryptor.Encrypt(plaintext, binding)
 	if err != nil {
 		return fmt.Errorf("encryption failed: %w", err)
 	}
@@ -167,6 +505,8 @@ func ClientAddBankcard(
 		models.SecretTypeBankCard,
 		SecretEncrypted.Ciphertext,
 		SecretEncrypted.AESKeyEnc,
+		SecretEncrypted.KeyID,
+		SecretEncrypted.Recipients,
 	)
 }
 
@@ -195,7 +535,12 @@ func ClientAddText(
 		return fmt.Errorf("failed to marshal text payload: %w", err)
 	}
 
-	SecretEncrypted, err := encryptor.Encrypt(plaintext)
+	binding, err := tokenBinding(token, models.SecretTypeText, secretName)
+	if err != nil {
+		return err
+	}
+
+	SecretEncrypted, err := encryptor.Encrypt(plaintext, binding)
 	if err != nil {
 		return fmt.Errorf("encryption failed: %w", err)
 	}
@@ -207,6 +552,8 @@ func ClientAddText(
 		models.SecretTypeText,
 		SecretEncrypted.Ciphertext,
 		SecretEncrypted.AESKeyEnc,
+		SecretEncrypted.KeyID,
+		SecretEncrypted.Recipients,
 	)
 }
 
@@ -241,7 +588,12 @@ func ClientAddBinary(
 		return fmt.Errorf("failed to marshal binary payload: %w", err)
 	}
 
-	SecretEncrypted, err := encryptor.Encrypt(plaintext)
+	binding, err := tokenBinding(token, models.SecretTypeBinary, secretName)
+	if err != nil {
+		return err
+	}
+
+	SecretEncrypted, err := encryptor.Encrypt(plaintext, binding)
 	if err != nil {
 		return fmt.Errorf("encryption failed: %w", err)
 	}
@@ -253,10 +605,125 @@ func ClientAddBinary(
 		models.SecretTypeBinary,
 		SecretEncrypted.Ciphertext,
 		SecretEncrypted.AESKeyEnc,
+		SecretEncrypted.KeyID,
+		SecretEncrypted.Recipients,
+	)
+}
+
+// NewSSHKeyPayload parses an SSH private key, decrypting it with passphrase when it is encrypted,
+// and returns the payload of an SSH key secret. The public key is derived from the private key
+// when empty and must match it otherwise; its comment is used when comment is empty.
+func NewSSHKeyPayload(privateKey, publicKey, comment, passphrase, meta string) (models.SSHKeyPayload, error) {
+	var metaPtr *string
+	if meta != "" {
+		metaPtr = &meta
+	}
+
+	var (
+		signer ssh.Signer
+		err    error
+	)
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(privateKey))
+	}
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return models.SSHKeyPayload{}, errors.New("the private key is encrypted and no passphrase was given")
+	}
+	if err != nil {
+		return models.SSHKeyPayload{}, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	if publicKey != "" {
+		pub, pubComment, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+		if err != nil {
+			return models.SSHKeyPayload{}, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		if !bytes.Equal(pub.Marshal(), signer.PublicKey().Marshal()) {
+			return models.SSHKeyPayload{}, errors.New("the public key does not match the private key")
+		}
+		if comment == "" {
+			comment = pubComment
+		}
+	}
+
+	return models.SSHKeyPayload{
+		PublicKey:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))),
+		PrivateKey: privateKey,
+		Comment:    comment,
+		Passphrase: passphrase,
+		Meta:       metaPtr,
+	}, nil
+}
+
+// ClientAddSSHKey encrypts and saves an SSH key secret; see NewSSHKeyPayload for the arguments.
+func ClientAddSSHKey(
+	ctx context.Context,
+	clientSaver ClientSaver,
+	encryptor Encryptor,
+	token string,
+	secretName string,
+	privateKey string,
+	publicKey string,
+	comment string,
+	passphrase string,
+	meta string,
+) error {
+	payload, err := NewSSHKeyPayload(privateKey, publicKey, comment, passphrase, meta)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sshkey payload: %w", err)
+	}
+
+	binding, err := tokenBinding(token, models.SecretTypeSSHKey, secretName)
+	if err != nil {
+		return err
+	}
+
+	SecretEncrypted, err := encryptor.Encrypt(plaintext, binding)
+	if err != nil {
+		return fmt.Errorf("encryption failed: %w", err)
+	}
+
+	return clientSaver.Save(
+		ctx,
+		token,
+		secretName,
+		models.SecretTypeSSHKey,
+		SecretEncrypted.Ciphertext,
+		SecretEncrypted.AESKeyEnc,
+		SecretEncrypted.KeyID,
+		SecretEncrypted.Recipients,
 	)
 }
 
-// ClientAddUser encrypts and saves a user credential secret.
+// PasswordGenerator defines the interface for generating passwords of a known entropy.
+type PasswordGenerator interface {
+	Generate() (string, error)
+	Entropy() float64
+}
+
+// ClientGeneratePassword generates a password with g and returns it
+// together with a line describing its estimated entropy.
+func ClientGeneratePassword(g PasswordGenerator) (string, string, error) {
+	password, err := g.Generate()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate password: %w", err)
+	}
+	bits := g.Entropy()
+	return password, fmt.Sprintf("Entropy: %.1f bits (%s)", bits, generator.Strength(bits)), nil
+}
+
+// ClientAddUser encrypts and saves a user credential secret. totp is the optional seed of
+// the account's one-time codes, in base32 or as an otpauth:// URI, and url the optional site
+// of the login. prev is the stored login the secret replaces, nil for a new one: its password
+// change time is kept when password is unchanged, otherwise now is recorded.
 func ClientAddUser(
 	ctx context.Context,
 	clientSaver ClientSaver,
@@ -265,17 +732,32 @@ func ClientAddUser(
 	secretName string,
 	username string,
 	password string,
+	totp string,
+	url string,
 	meta string,
+	prev *models.UserPayload,
+	now time.Time,
 ) error {
+	if totp != "" {
+		seed, err := otp.ParseSecret(totp)
+		if err != nil {
+			return err
+		}
+		totp = otp.EncodeSecret(seed)
+	}
+
 	var metaPtr *string
 	if meta != "" {
 		metaPtr = &meta
 	}
 
 	payload := models.UserPayload{
-		Username: username,
-		Password: password,
-		Meta:     metaPtr,
+		Username:          username,
+		Password:          password,
+		TOTP:              totp,
+		URL:               url,
+		Meta:              metaPtr,
+		PasswordChangedAt: passwordChangedAt(prev, password, now),
 	}
 
 	plaintext, err := json.Marshal(payload)
@@ -283,7 +765,12 @@ func ClientAddUser(
 		return fmt.Errorf("failed to marshal user payload: %w", err)
 	}
 
-	SecretEncrypted, err := encryptor.Encrypt(plaintext)
+	binding, err := tokenBinding(token, models.SecretTypeUser, secretName)
+	if err != nil {
+		return err
+	}
+
+	SecretEncrypted, err := encryptor.Encrypt(plaintext, binding)
 	if err != nil {
 		return fmt.Errorf("encryption failed: %w", err)
 	}
@@ -295,75 +782,736 @@ func ClientAddUser(
 		models.SecretTypeUser,
 		SecretEncrypted.Ciphertext,
 		SecretEncrypted.AESKeyEnc,
+		SecretEncrypted.KeyID,
+		SecretEncrypted.Recipients,
 	)
 }
 
-// ClientListSecrets fetches, decrypts, and returns secrets associated with the given token.
-func ClientListSecrets(
+// passwordChangedAt returns the time the password of a login was last set: the one of prev
+// when password is the same as its, now otherwise.
+func passwordChangedAt(prev *models.UserPayload, password string, now time.Time) *time.Time {
+	if prev != nil && prev.Password == password {
+		return prev.PasswordChangedAt
+	}
+	now = now.UTC()
+	return &now
+}
+
+// ClientGetUser fetches and decrypts the user secret secretName of the token owner.
+// It returns nil without an error when there is no such secret.
+func ClientGetUser(
 	ctx context.Context,
-	secretReader ServerLister,
+	secretGetter ServerGetter,
 	decryptor Decryptor,
 	token string,
-) (string, error) {
-	secrets, err := secretReader.List(ctx, token)
+	secretName string,
+) (*models.UserPayload, error) {
+	binding, err := tokenBinding(token, models.SecretTypeUser, secretName)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	var builder strings.Builder
-
-	for _, secret := range secrets {
-		decrypted, err := decryptor.Decrypt(&models.SecretEncrypted{
-			Ciphertext: secret.Ciphertext,
-			AESKeyEnc:  secret.AESKeyEnc,
-		})
-		if err != nil {
-			return "", fmt.Errorf("failed to decrypt secret %s: %w", secret.SecretName, err)
-		}
-
-		switch secret.SecretType {
-		case models.SecretTypeBankCard:
-			var bankcard models.BankcardPayload
-			if err := json.Unmarshal(decrypted, &bankcard); err != nil {
-				return "", fmt.Errorf("failed to unmarshal bankcard: %w", err)
-			}
-			out, _ := json.MarshalIndent(bankcard, "", "  ")
-			builder.Write(out)
-
-		case models.SecretTypeText:
-			var text models.TextPayload
-			if err := json.Unmarshal(decrypted, &text); err != nil {
-				return "", fmt.Errorf("failed to unmarshal text: %w", err)
-			}
-			out, _ := json.MarshalIndent(text, "", "  ")
-			builder.Write(out)
-
-		case models.SecretTypeBinary:
-			var binary models.BinaryPayload
-			if err := json.Unmarshal(decrypted, &binary); err != nil {
-				return "", fmt.Errorf("failed to unmarshal binary: %w", err)
-			}
-			out, _ := json.MarshalIndent(binary, "", "  ")
-			builder.Write(out)
-
-		case models.SecretTypeUser:
-			var user models.UserPayload
-			if err := json.Unmarshal(decrypted, &user); err != nil {
-				return "", fmt.Errorf("failed to unmarshal user: %w", err)
-			}
-			out, _ := json.MarshalIndent(user, "", "  ")
-			builder.Write(out)
+	secret, err := secretGetter.Get(ctx, token, models.SecretTypeUser, secretName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
 
-		default:
-			builder.WriteString(fmt.Sprintf("Unknown secret type: %s\n", secret.SecretType))
-		}
-		builder.WriteString("\n\n")
+	plaintext, err := decryptor.Decrypt(&models.SecretEncrypted{
+		Ciphertext: secret.Ciphertext,
+		AESKeyEnc:  secret.AESKeyEnc,
+		KeyID:      secret.KeyID,
+		Recipients: secret.Recipients,
+	}, binding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret %s: %w", secretName, err)
 	}
 
-	return builder.String(), nil
+	var payload models.UserPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	return &payload, nil
 }
 
-// Constants as you defined
+// ClientDeleteSecret deletes a secret of the token owner from the local store.
+func ClientDeleteSecret(
+	ctx context.Context,
+	clientDeleter ClientDeleter,
+	token string,
+	secretType string,
+	secretName string,
+) error {
+	err := clientDeleter.Delete(ctx, token, secretType, secretName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("secret %s/%s not found", secretType, secretName)
+	}
+	return err
+}
+
+// ClientImport encrypts items parsed from an export and saves them to the local store in one batch.
+// Items whose type and name match a stored secret or an earlier item are skipped as duplicates.
+// With dryRun nothing is encrypted or saved, and the report lists the items that would be imported.
+// Imported logins without a password change time get now.
+func ClientImport(
+	ctx context.Context,
+	lister ClientLister,
+	saver SecretBatchSaver,
+	encryptor Encryptor,
+	token string,
+	items []importer.Item,
+	dryRun bool,
+	now time.Time,
+) (string, error) {
+	stored, err := lister.List(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("failed to list secrets: %w", err)
+	}
+	seen := make(map[string]bool, len(stored)+len(items))
+	for _, secret := range stored {
+		seen[secret.SecretType+"/"+secret.SecretName] = true
+	}
+
+	var (
+		imported   []string
+		duplicates []string
+		secrets    []*models.Secret
+		perType    = make(map[string]int)
+	)
+	for _, item := range items {
+		if seen[item.Key()] {
+			duplicates = append(duplicates, item.Key())
+			continue
+		}
+		seen[item.Key()] = true
+
+		payload := item.Payload
+		if user, ok := payload.(models.UserPayload); ok && user.PasswordChangedAt == nil {
+			changed := now.UTC()
+			user.PasswordChangedAt = &changed
+			payload = user
+		}
+		plaintext, err := json.Marshal(payload)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal %s: %w", item.Key(), err)
+		}
+		imported = append(imported, item.Key())
+		perType[item.Type]++
+		if dryRun {
+			continue
+		}
+
+		binding, err := tokenBinding(token, item.Type, item.Name)
+		if err != nil {
+			return "", err
+		}
+		enc, err := encryptor.Encrypt(plaintext, binding)
+		if err != nil {
+			return "", fmt.Errorf("encryption of %s failed: %w", item.Key(), err)
+		}
+		secrets = append(secrets, &models.Secret{
+			SecretName: item.Name,
+			SecretType: item.Type,
+			Ciphertext: enc.Ciphertext,
+			AESKeyEnc:  enc.AESKeyEnc,
+			KeyID:      enc.KeyID,
+			Recipients: enc.Recipients,
+		})
+	}
+
+	if len(secrets) > 0 {
+		if err := saver.SaveBatch(ctx, token, secrets); err != nil {
+			return "", fmt.Errorf("failed to save imported secrets: %w", err)
+		}
+	}
+
+	var builder strings.Builder
+	verb := "Imported"
+	if dryRun {
+		verb = "Dry run: would import"
+	}
+	fmt.Fprintf(&builder, "%s %d secrets", verb, len(imported))
+	var counts []string
+	for _, t := range []string{models.SecretTypeUser, models.SecretTypeBankCard, models.SecretTypeText, models.SecretTypeBinary, models.SecretTypeSSHKey} {
+		if perType[t] > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", perType[t], t))
+		}
+	}
+	if len(counts) > 0 {
+		fmt.Fprintf(&builder, " (%s)", strings.Join(counts, ", "))
+	}
+	builder.WriteString(".\n")
+	if dryRun {
+		for _, key := range imported {
+			fmt.Fprintf(&builder, "  %s\n", key)
+		}
+	}
+	if len(duplicates) > 0 {
+		fmt.Fprintf(&builder, "Skipped %d duplicates:\n", len(duplicates))
+		for _, key := range duplicates {
+			fmt.Fprintf(&builder, "  %s\n", key)
+		}
+	}
+	return builder.String(), nil
+}
+
+// Stores export reads from and restore-backup writes to.
+const (
+	StoreLocal  = "local"
+	StoreServer = "server"
+)
+
+// ErrBackupPasswordRequired is returned when restoring a password-protected archive without its password.
+var ErrBackupPasswordRequired = errors.New("archive is password-protected, its password is required")
+
+// ClientExport writes the secrets of the token owner to w as an archive and returns how many were written.
+// Without a password the records are copied as stored, still encrypted to the owner's keys, and the
+// archive key is wrapped with encryptor. With a password every secret is decrypted with decryptor and
+// re-encrypted to the vault key derived from the password with params, so the archive can be restored
+// without the original keys and into another account.
+func ClientExport(
+	ctx context.Context,
+	secretReader ServerLister,
+	encryptor Encryptor,
+	decryptor Decryptor,
+	token string,
+	password string,
+	params *models.KDFParams,
+	w io.Writer,
+) (int, error) {
+	owner, err := jwt.Username(token)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	a := &archive.Archive{Header: archive.Header{Owner: owner}}
+
+	if password == "" {
+		secrets, err := secretReader.List(ctx, token)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list secrets: %w", err)
+		}
+		// The owner of local secrets is the token they were saved with.
+		for _, secret := range secrets {
+			record := *secret
+			record.SecretOwner = ""
+			a.Secrets = append(a.Secrets, &record)
+		}
+		if err := archive.Write(w, a, encryptor); err != nil {
+			return 0, err
+		}
+		return len(a.Secrets), nil
+	}
+
+	if params == nil {
+		return 0, errors.New("KDF parameters are required for a password-protected archive")
+	}
+	passwordCryptor, err := backupCryptor(password, *params)
+	if err != nil {
+		return 0, err
+	}
+	a.KDF = params
+
+	decrypted, err := decryptSecrets(ctx, secretReader, decryptor, token)
+	if err != nil {
+		return 0, err
+	}
+	for _, secret := range decrypted {
+		enc, err := passwordCryptor.Encrypt(secret.plaintext, models.SecretBinding{
+			Owner: owner,
+			Type:  secret.SecretType,
+			Name:  secret.SecretName,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("encryption of %s/%s failed: %w", secret.SecretType, secret.SecretName, err)
+		}
+		a.Secrets = append(a.Secrets, &models.Secret{
+			SecretName: secret.SecretName,
+			SecretType: secret.SecretType,
+			Ciphertext: enc.Ciphertext,
+			AESKeyEnc:  enc.AESKeyEnc,
+			KeyID:      enc.KeyID,
+			Vault:      secret.Vault,
+			CreatedAt:  secret.CreatedAt,
+			UpdatedAt:  secret.UpdatedAt,
+		})
+	}
+
+	if err := archive.Write(w, a, passwordCryptor); err != nil {
+		return 0, err
+	}
+	return len(a.Secrets), nil
+}
+
+// ClientRestoreBackup verifies the archive in r and saves its secrets for the token owner with saver.
+// Records of an archive without a password are saved as they are, which requires the archive to belong
+// to the token owner; decryptor unwraps the archive key. Records of a password-protected archive are
+// decrypted with the vault key derived from password and re-encrypted with encryptor.
+// Nothing is saved unless the whole archive verifies.
+func ClientRestoreBackup(
+	ctx context.Context,
+	r io.Reader,
+	saver SecretBatchSaver,
+	encryptor Encryptor,
+	decryptor Decryptor,
+	token string,
+	password string,
+) (string, error) {
+	owner, err := jwt.Username(token)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+
+	a, err := archive.Read(r)
+	if err != nil {
+		return "", err
+	}
+
+	secrets := a.Secrets
+	if a.KDF == nil {
+		if a.Owner != owner {
+			return "", fmt.Errorf("archive belongs to %s; export it with a password to restore it into another account", a.Owner)
+		}
+		if err := a.Verify(decryptor); err != nil {
+			return "", err
+		}
+	} else {
+		if password == "" {
+			return "", ErrBackupPasswordRequired
+		}
+		passwordCryptor, err := backupCryptor(password, *a.KDF)
+		if err != nil {
+			return "", err
+		}
+		if err := a.Verify(passwordCryptor); err != nil {
+			return "", err
+		}
+
+		secrets = make([]*models.Secret, 0, len(a.Secrets))
+		for _, secret := range a.Secrets {
+			plaintext, err := passwordCryptor.Decrypt(&models.SecretEncrypted{
+				Ciphertext: secret.Ciphertext,
+				AESKeyEnc:  secret.AESKeyEnc,
+				KeyID:      secret.KeyID,
+			}, models.SecretBinding{Owner: a.Owner, Type: secret.SecretType, Name: secret.SecretName})
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt %s/%s: %w", secret.SecretType, secret.SecretName, err)
+			}
+			enc, err := encryptor.Encrypt(plaintext, models.SecretBinding{
+				Owner: owner,
+				Type:  secret.SecretType,
+				Name:  secret.SecretName,
+			})
+			if err != nil {
+				return "", fmt.Errorf("encryption of %s/%s failed: %w", secret.SecretType, secret.SecretName, err)
+			}
+			secrets = append(secrets, &models.Secret{
+				SecretName: secret.SecretName,
+				SecretType: secret.SecretType,
+				Ciphertext: enc.Ciphertext,
+				AESKeyEnc:  enc.AESKeyEnc,
+				KeyID:      enc.KeyID,
+				Recipients: enc.Recipients,
+				Vault:      secret.Vault,
+				CreatedAt:  secret.CreatedAt,
+				UpdatedAt:  secret.UpdatedAt,
+			})
+		}
+	}
+
+	if len(secrets) > 0 {
+		if err := saver.SaveBatch(ctx, token, secrets); err != nil {
+			return "", fmt.Errorf("failed to save restored secrets: %w", err)
+		}
+	}
+
+	return fmt.Sprintf("Restored %d secrets from the backup of %s taken %s.\n",
+		len(secrets), a.Owner, a.CreatedAt.UTC().Format(time.RFC3339)), nil
+}
+
+// backupCryptor returns a cryptor for the vault key derived from the password of an archive.
+func backupCryptor(password string, params models.KDFParams) (*cryptor.Cryptor, error) {
+	keys, err := cryptor.DeriveMasterKeys(password, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive archive key: %w", err)
+	}
+	return cryptor.New(cryptor.WithVaultKey(keys.VaultKey))
+}
+
+// ClientListSecrets fetches, decrypts, and returns secrets associated with the given token.
+func ClientListSecrets(
+	ctx context.Context,
+	secretReader ServerLister,
+	decryptor Decryptor,
+	token string,
+) (string, error) {
+	secrets, err := decryptSecrets(ctx, secretReader, decryptor, token)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	for _, secret := range secrets {
+		if err := writePayload(&builder, secret.SecretType, secret.plaintext); err != nil {
+			return "", err
+		}
+		builder.WriteString("\n\n")
+	}
+
+	return builder.String(), nil
+}
+
+// DecryptedSecret is a secret with its payload decrypted into the models payload of its type:
+// models.BankcardPayload, models.TextPayload, models.BinaryPayload, models.UserPayload or models.SSHKeyPayload.
+type DecryptedSecret struct {
+	*models.Secret
+	Payload any
+}
+
+// ClientGetSecrets fetches and decrypts the secrets of the token owner, sorted by type and name.
+// Secrets of an unknown type are skipped.
+func ClientGetSecrets(
+	ctx context.Context,
+	secretReader ServerLister,
+	decryptor Decryptor,
+	token string,
+) ([]DecryptedSecret, error) {
+	secrets, err := decryptSecrets(ctx, secretReader, decryptor, token)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]DecryptedSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		payload, err := decodePayload(secret.SecretType, secret.plaintext)
+		if errors.Is(err, errUnknownSecretType) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, DecryptedSecret{Secret: secret.Secret, Payload: payload})
+	}
+
+	slices.SortFunc(result, func(a, b DecryptedSecret) int {
+		if c := strings.Compare(a.SecretType, b.SecretType); c != 0 {
+			return c
+		}
+		return strings.Compare(a.SecretName, b.SecretName)
+	})
+	return result, nil
+}
+
+// Fields of a secret ClientGetField returns.
+const (
+	FieldNumber     = "number"
+	FieldOwner      = "owner"
+	FieldExp        = "exp"
+	FieldCVV        = "cvv"
+	FieldData       = "data"
+	FieldUsername   = "username"
+	FieldPassword   = "password"
+	FieldTOTP       = "totp"
+	FieldURL        = "url"
+	FieldPublicKey  = "public-key"
+	FieldPrivateKey = "private-key"
+	FieldComment    = "comment"
+	FieldPassphrase = "passphrase"
+	FieldMeta       = "meta"
+)
+
+// DefaultField returns the field ClientGetField returns for secrets of secretType when none is given:
+// the number of a bank card, the data of a text, the password of a user secret and the public key
+// of an SSH key.
+func DefaultField(secretType string) string {
+	switch secretType {
+	case models.SecretTypeSSHKey:
+		return FieldPublicKey
+	case models.SecretTypeBankCard:
+		return FieldNumber
+	case models.SecretTypeText:
+		return FieldData
+	case models.SecretTypeUser:
+		return FieldPassword
+	}
+	return ""
+}
+
+// ClientGetField fetches and decrypts one secret and returns one of its fields, the DefaultField
+// of its type when field is empty. FieldTOTP returns the one-time code of a user secret at now
+// rather than its seed.
+func ClientGetField(
+	ctx context.Context,
+	secretGetter ServerGetter,
+	decryptor Decryptor,
+	token string,
+	secretType string,
+	secretName string,
+	field string,
+	now time.Time,
+) (string, error) {
+	binding, err := tokenBinding(token, secretType, secretName)
+	if err != nil {
+		return "", err
+	}
+	if field == "" {
+		field = DefaultField(secretType)
+	}
+
+	secret, err := secretGetter.Get(ctx, token, secretType, secretName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret: %w", err)
+	}
+
+	plaintext, err := decryptor.Decrypt(&models.SecretEncrypted{
+		Ciphertext: secret.Ciphertext,
+		AESKeyEnc:  secret.AESKeyEnc,
+		KeyID:      secret.KeyID,
+		Recipients: secret.Recipients,
+	}, binding)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: %w", secretName, err)
+	}
+
+	payload, err := decodePayload(secretType, plaintext)
+	if err != nil {
+		return "", err
+	}
+
+	var fields map[string]string
+	var meta *string
+	switch p := payload.(type) {
+	case models.BankcardPayload:
+		fields = map[string]string{FieldNumber: p.Number, FieldOwner: p.Owner, FieldExp: p.Exp, FieldCVV: p.CVV}
+		meta = p.Meta
+	case models.TextPayload:
+		fields = map[string]string{FieldData: p.Data}
+		meta = p.Meta
+	case models.UserPayload:
+		fields = map[string]string{FieldUsername: p.Username, FieldPassword: p.Password, FieldURL: p.URL}
+		meta = p.Meta
+		if field == FieldTOTP {
+			if p.TOTP == "" {
+				return "", fmt.Errorf("secret %s has no TOTP seed", secretName)
+			}
+			seed, err := otp.ParseSecret(p.TOTP)
+			if err != nil {
+				return "", err
+			}
+			return otp.Code(seed, now), nil
+		}
+	case models.SSHKeyPayload:
+		// The public key is returned in the authorized_keys format, with its comment.
+		publicKey := p.PublicKey
+		if p.Comment != "" {
+			publicKey += " " + p.Comment
+		}
+		fields = map[string]string{FieldPublicKey: publicKey, FieldPrivateKey: p.PrivateKey, FieldComment: p.Comment, FieldPassphrase: p.Passphrase}
+		meta = p.Meta
+	default:
+		return "", fmt.Errorf("%s secrets have no fields to get", secretType)
+	}
+	fields[FieldMeta] = ""
+	if meta != nil {
+		fields[FieldMeta] = *meta
+	}
+
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("%s secrets have no field %q", secretType, field)
+	}
+	return value, nil
+}
+
+// Report formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ClientHealthReport decrypts the secrets of the token owner and reports weak, reused and old
+// passwords and expiring bank cards, as a table or, with FormatJSON, as JSON.
+func ClientHealthReport(
+	ctx context.Context,
+	secretReader ServerLister,
+	decryptor Decryptor,
+	checker *health.Checker,
+	token string,
+	format string,
+) (string, error) {
+	if format != FormatText && format != FormatJSON {
+		return "", fmt.Errorf("unknown report format: %s", format)
+	}
+
+	secrets, err := decryptSecrets(ctx, secretReader, decryptor, token)
+	if err != nil {
+		return "", err
+	}
+
+	var entries []health.Entry
+	for _, secret := range secrets {
+		entry := health.Entry{Type: secret.SecretType, Name: secret.SecretName, UpdatedAt: secret.UpdatedAt}
+		switch secret.SecretType {
+		case models.SecretTypeUser:
+			entry.User = &models.UserPayload{}
+			if err := json.Unmarshal(secret.plaintext, entry.User); err != nil {
+				return "", fmt.Errorf("failed to unmarshal user %s: %w", secret.SecretName, err)
+			}
+		case models.SecretTypeBankCard:
+			entry.Bankcard = &models.BankcardPayload{}
+			if err := json.Unmarshal(secret.plaintext, entry.Bankcard); err != nil {
+				return "", fmt.Errorf("failed to unmarshal bankcard %s: %w", secret.SecretName, err)
+			}
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	report := checker.Check(entries)
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	}
+	return report.Text()
+}
+
+// BreachLookup defines the interface for looking up password hashes in a breach dataset.
+type BreachLookup interface {
+	Lookup(hashes []string) (map[string]int, error)
+}
+
+// Compromised describes a user secret whose password appears in a breach dataset.
+type Compromised struct {
+	SecretName string `json:"secret_name"`
+	Username   string `json:"username"`
+	Breaches   int    `json:"breaches"` // Breaches is the number of times the password appears in the dataset.
+}
+
+// ClientBreachCheck decrypts the user secrets of the token owner and looks their password hashes up
+// in dataset. It reports the compromised secrets as a table or, with FormatJSON, as JSON.
+func ClientBreachCheck(
+	ctx context.Context,
+	secretReader ServerLister,
+	decryptor Decryptor,
+	dataset BreachLookup,
+	token string,
+	format string,
+) (string, error) {
+	if format != FormatText && format != FormatJSON {
+		return "", fmt.Errorf("unknown report format: %s", format)
+	}
+
+	secrets, err := decryptSecrets(ctx, secretReader, decryptor, token)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		users  []*models.Secret
+		hashes []string
+		names  []string
+	)
+	for _, secret := range secrets {
+		if secret.SecretType != models.SecretTypeUser {
+			continue
+		}
+		var user models.UserPayload
+		if err := json.Unmarshal(secret.plaintext, &user); err != nil {
+			return "", fmt.Errorf("failed to unmarshal user %s: %w", secret.SecretName, err)
+		}
+		if user.Password == "" {
+			continue
+		}
+		users = append(users, secret.Secret)
+		hashes = append(hashes, breach.Hash(user.Password))
+		names = append(names, user.Username)
+	}
+
+	counts, err := dataset.Lookup(hashes)
+	if err != nil {
+		return "", err
+	}
+
+	compromised := []Compromised{}
+	for i, secret := range users {
+		if n := counts[hashes[i]]; n > 0 {
+			compromised = append(compromised, Compromised{SecretName: secret.SecretName, Username: names[i], Breaches: n})
+		}
+	}
+
+	if format == FormatJSON {
+		out, err := json.MarshalIndent(compromised, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out), nil
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Checked %d passwords, %d compromised.\n", len(users), len(compromised))
+	if len(compromised) == 0 {
+		return builder.String(), nil
+	}
+
+	builder.WriteString("\n")
+	tw := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SECRET\tUSERNAME\tBREACHES")
+	for _, c := range compromised {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", c.SecretName, c.Username, c.Breaches)
+	}
+	if err := tw.Flush(); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// decryptedSecret is a secret together with its decrypted payload.
+type decryptedSecret struct {
+	*models.Secret
+	plaintext []byte
+}
+
+// decryptSecrets fetches the secrets of the token owner and decrypts them.
+func decryptSecrets(
+	ctx context.Context,
+	secretReader ServerLister,
+	decryptor Decryptor,
+	token string,
+) ([]decryptedSecret, error) {
+	owner, err := jwt.Username(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	secrets, err := secretReader.List(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted := make([]decryptedSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		plaintext, err := decryptor.Decrypt(&models.SecretEncrypted{
+			Ciphertext: secret.Ciphertext,
+			AESKeyEnc:  secret.AESKeyEnc,
+			KeyID:      secret.KeyID,
+			Recipients: secret.Recipients,
+		}, models.SecretBinding{Owner: owner, Type: secret.SecretType, Name: secret.SecretName})
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret %s: %w", secret.SecretName, err)
+		}
+		decrypted = append(decrypted, decryptedSecret{Secret: secret, plaintext: plaintext})
+	}
+	return decrypted, nil
+}
+
+// Constants as you defined
 const (
 	ResolveStrategyServer      = "server"
 	ResolveStrategyClient      = "client"
@@ -397,6 +1545,8 @@ func ClientSyncClient(
 				clientSecret.SecretType,
 				clientSecret.Ciphertext,
 				clientSecret.AESKeyEnc,
+				clientSecret.KeyID,
+				clientSecret.Recipients,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to save secret to server: %w", err)
@@ -439,6 +1589,8 @@ func ClientSyncInteractive(
 				clientSecret.SecretType,
 				clientSecret.Ciphertext,
 				clientSecret.AESKeyEnc,
+				clientSecret.KeyID,
+				clientSecret.Recipients,
 			)
 			if err != nil {
 				return fmt.Errorf("failed to save client secret: %w", err)
@@ -447,10 +1599,17 @@ func ClientSyncInteractive(
 		}
 
 		if !clientSecret.UpdatedAt.Before(serverSecret.UpdatedAt) {
+			binding, err := tokenBinding(secretOwner, clientSecret.SecretType, clientSecret.SecretName)
+			if err != nil {
+				return err
+			}
+
 			clientPlain, err := d.Decrypt(&models.SecretEncrypted{
 				Ciphertext: clientSecret.Ciphertext,
 				AESKeyEnc:  clientSecret.AESKeyEnc,
-			})
+				KeyID:      clientSecret.KeyID,
+				Recipients: clientSecret.Recipients,
+			}, binding)
 			if err != nil {
 				continue
 			}
@@ -458,7 +1617,9 @@ func ClientSyncInteractive(
 			serverPlain, err := d.Decrypt(&models.SecretEncrypted{
 				Ciphertext: serverSecret.Ciphertext,
 				AESKeyEnc:  serverSecret.AESKeyEnc,
-			})
+				KeyID:      serverSecret.KeyID,
+				Recipients: serverSecret.Recipients,
+			}, binding)
 			if err != nil {
 				continue
 			}
@@ -512,6 +1673,8 @@ func ClientSyncInteractive(
 					clientSecret.SecretType,
 					clientSecret.Ciphertext,
 					clientSecret.AESKeyEnc,
+					clientSecret.KeyID,
+					clientSecret.Recipients,
 				)
 				if err != nil {
 					return fmt.Errorf("failed to save client version: %w", err)
@@ -523,3 +1686,425 @@ func ClientSyncInteractive(
 
 	return nil
 }
+
+// ClientRotateKeys re-encrypts every secret of the vault listed by lister with encryptor
+// and stores the result through saver in a single batch, so the vault is never left half rotated.
+//
+// Secrets already encrypted to exactly the keys in newKeyIDs are skipped, which makes an
+// interrupted rotation safe to repeat and lets vaults holding secrets of both keys be finished later.
+// Nothing is saved if any secret fails to decrypt.
+// It returns the number of rotated secrets.
+func ClientRotateKeys(
+	ctx context.Context,
+	lister ServerLister,
+	saver SecretBatchSaver,
+	decryptor Decryptor,
+	encryptor Encryptor,
+	secretOwner string,
+	newKeyIDs []string,
+) (int, error) {
+	owner, err := jwt.Username(secretOwner)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	secrets, err := lister.List(ctx, secretOwner)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	var rotated []*models.Secret
+	for _, secret := range secrets {
+		if slices.Equal(secretKeyIDs(secret), newKeyIDs) {
+			continue
+		}
+
+		binding := models.SecretBinding{Owner: owner, Type: secret.SecretType, Name: secret.SecretName}
+		plaintext, err := decryptor.Decrypt(&models.SecretEncrypted{
+			Ciphertext: secret.Ciphertext,
+			AESKeyEnc:  secret.AESKeyEnc,
+			KeyID:      secret.KeyID,
+			Recipients: secret.Recipients,
+		}, binding)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decrypt secret %s/%s: %w", secret.SecretType, secret.SecretName, err)
+		}
+
+		enc, err := encryptor.Encrypt(plaintext, binding)
+		clear(plaintext)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encrypt secret %s/%s: %w", secret.SecretType, secret.SecretName, err)
+		}
+
+		rotated = append(rotated, &models.Secret{
+			SecretName:  secret.SecretName,
+			SecretType:  secret.SecretType,
+			SecretOwner: secret.SecretOwner,
+			Ciphertext:  enc.Ciphertext,
+			AESKeyEnc:   enc.AESKeyEnc,
+			KeyID:       enc.KeyID,
+			Recipients:  enc.Recipients,
+		})
+	}
+
+	if len(rotated) == 0 {
+		return 0, nil
+	}
+
+	if err := saver.SaveBatch(ctx, secretOwner, rotated); err != nil {
+		return 0, fmt.Errorf("failed to save rotated secrets: %w", err)
+	}
+
+	return len(rotated), nil
+}
+
+// ClientUpgradeSecrets reseals every secret listed by lister that still uses an older
+// ciphertext format in the current envelope and stores the result through saver in a single batch.
+//
+// The data keys are kept, so wrapped keys and shares stay valid. Secrets already in the
+// current format are skipped, which makes the upgrade safe to repeat. Nothing is saved
+// if any secret fails to decrypt. It returns the number of upgraded secrets.
+func ClientUpgradeSecrets(
+	ctx context.Context,
+	lister ServerLister,
+	saver SecretBatchSaver,
+	upgrader Upgrader,
+	secretOwner string,
+) (int, error) {
+	owner, err := jwt.Username(secretOwner)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read token: %w", err)
+	}
+
+	secrets, err := lister.List(ctx, secretOwner)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list secrets: %w", err)
+	}
+
+	var upgraded []*models.Secret
+	for _, secret := range secrets {
+		enc := &models.SecretEncrypted{
+			Ciphertext: secret.Ciphertext,
+			AESKeyEnc:  secret.AESKeyEnc,
+			KeyID:      secret.KeyID,
+			Recipients: secret.Recipients,
+		}
+		ok, err := upgrader.Upgrade(enc, models.SecretBinding{Owner: owner, Type: secret.SecretType, Name: secret.SecretName})
+		if err != nil {
+			return 0, fmt.Errorf("failed to upgrade secret %s/%s: %w", secret.SecretType, secret.SecretName, err)
+		}
+		if !ok {
+			continue
+		}
+
+		upgraded = append(upgraded, &models.Secret{
+			SecretName:  secret.SecretName,
+			SecretType:  secret.SecretType,
+			SecretOwner: secret.SecretOwner,
+			Ciphertext:  enc.Ciphertext,
+			AESKeyEnc:   secret.AESKeyEnc,
+			KeyID:       secret.KeyID,
+			Recipients:  secret.Recipients,
+		})
+	}
+
+	if len(upgraded) == 0 {
+		return 0, nil
+	}
+
+	if err := saver.SaveBatch(ctx, secretOwner, upgraded); err != nil {
+		return 0, fmt.Errorf("failed to save upgraded secrets: %w", err)
+	}
+
+	return len(upgraded), nil
+}
+
+// ClientListAudit fetches the newest audit events of the token owner
+// and formats them as a table, newest first.
+func ClientListAudit(
+	ctx context.Context,
+	lister AuditLister,
+	token string,
+	limit int,
+) (string, error) {
+	events, err := lister.List(ctx, token, limit)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	tw := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tACTION\tSECRET\tTRANSPORT\tCLIENT IP")
+	for _, ev := range events {
+		secret := ev.SecretKey
+		if secret == "" {
+			secret = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			ev.CreatedAt.Local().Format(time.RFC3339),
+			ev.Action,
+			secret,
+			ev.Transport,
+			ev.ClientIP,
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return "", err
+	}
+
+	return builder.String(), nil
+}
+
+// ClientPublishCertificate publishes the certificate other users wrap shared keys for.
+func ClientPublishCertificate(
+	ctx context.Context,
+	publisher CertificatePublisher,
+	token string,
+	certificate string,
+) error {
+	if strings.TrimSpace(certificate) == "" {
+		return errors.New("certificate is required")
+	}
+	return publisher.PublishCertificate(ctx, token, certificate)
+}
+
+// ClientShareSecret shares a secret with recipient.
+// The data key of the server's copy of the secret, which the share gets joined with,
+// is unwrapped locally and wrapped for the certificate recipient published, so the
+// server never sees it. It returns the identifier of the recipient's certificate.
+func ClientShareSecret(
+	ctx context.Context,
+	getter ServerGetter,
+	certs CertificateGetter,
+	sharer SecretSharer,
+	wrapper KeyWrapper,
+	token string,
+	secretType string,
+	secretName string,
+	recipient string,
+	permission string,
+) (string, error) {
+	if permission != models.SharePermissionRead && permission != models.SharePermissionReadWrite {
+		return "", fmt.Errorf("invalid permission %q, expected %s or %s", permission, models.SharePermissionRead, models.SharePermissionReadWrite)
+	}
+
+	secret, err := getter.Get(ctx, token, secretType, secretName)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s/%s: %w", secretType, secretName, err)
+	}
+
+	cert, err := certs.GetCertificate(ctx, token, recipient)
+	if err != nil {
+		return "", fmt.Errorf("failed to get certificate of %s: %w", recipient, err)
+	}
+
+	wrapped, err := wrapper.WrapFor(&models.SecretEncrypted{
+		Ciphertext: secret.Ciphertext,
+		AESKeyEnc:  secret.AESKeyEnc,
+		KeyID:      secret.KeyID,
+		Recipients: secret.Recipients,
+	}, []byte(cert))
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap key for %s: %w", recipient, err)
+	}
+
+	if err := sharer.Share(ctx, token, secretType, secretName, recipient, wrapped.AESKeyEnc, wrapped.KeyID, permission); err != nil {
+		return "", err
+	}
+
+	return wrapped.KeyID, nil
+}
+
+// ClientUnshareSecret revokes the access of recipient to a secret.
+func ClientUnshareSecret(
+	ctx context.Context,
+	sharer SecretSharer,
+	token string,
+	secretType string,
+	secretName string,
+	recipient string,
+) error {
+	return sharer.Unshare(ctx, token, secretType, secretName, recipient)
+}
+
+// ClientListShared fetches and decrypts the secrets other users share with the token owner.
+func ClientListShared(
+	ctx context.Context,
+	lister SharedLister,
+	decryptor Decryptor,
+	token string,
+) (string, error) {
+	secrets, err := lister.ListSharedWithMe(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+
+	for _, secret := range secrets {
+		decrypted, err := decryptor.Decrypt(&models.SecretEncrypted{
+			Ciphertext: secret.Ciphertext,
+			AESKeyEnc:  secret.AESKeyEnc,
+			KeyID:      secret.KeyID,
+		}, models.SecretBinding{Owner: secret.SecretOwner, Type: secret.SecretType, Name: secret.SecretName})
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt secret %s:%s/%s: %w", secret.SecretOwner, secret.SecretType, secret.SecretName, err)
+		}
+
+		fmt.Fprintf(&builder, "%s:%s/%s (%s)\n", secret.SecretOwner, secret.SecretType, secret.SecretName, secret.Permission)
+		if err := writePayload(&builder, secret.SecretType, decrypted); err != nil {
+			return "", err
+		}
+		builder.WriteString("\n\n")
+	}
+
+	return builder.String(), nil
+}
+
+// ClientSaveShared replaces the content of a secret of secretOwner shared read-write
+// with the token owner. plaintext is encrypted with the existing data key of the secret,
+// so the owner and the other recipients can still decrypt it. A user secret keeps its
+// password change time unless its password changes, which records now.
+func ClientSaveShared(
+	ctx context.Context,
+	lister SharedLister,
+	saver SharedSaver,
+	resealer Resealer,
+	decryptor Decryptor,
+	token string,
+	secretOwner string,
+	secretType string,
+	secretName string,
+	plaintext []byte,
+	now time.Time,
+) error {
+	secrets, err := lister.ListSharedWithMe(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	idx := slices.IndexFunc(secrets, func(s *models.SharedSecret) bool {
+		return s.SecretOwner == secretOwner && s.SecretType == secretType && s.SecretName == secretName
+	})
+	if idx < 0 {
+		return fmt.Errorf("secret %s:%s/%s is not shared with you", secretOwner, secretType, secretName)
+	}
+	secret := secrets[idx]
+	if secret.Permission != models.SharePermissionReadWrite {
+		return fmt.Errorf("secret %s:%s/%s is shared read-only", secretOwner, secretType, secretName)
+	}
+
+	enc := &models.SecretEncrypted{
+		Ciphertext: secret.Ciphertext,
+		AESKeyEnc:  secret.AESKeyEnc,
+		KeyID:      secret.KeyID,
+	}
+	binding := models.SecretBinding{Owner: secretOwner, Type: secretType, Name: secretName}
+
+	if secretType == models.SecretTypeUser {
+		stored, err := decryptor.Decrypt(enc, binding)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt secret %s:%s/%s: %w", secretOwner, secretType, secretName, err)
+		}
+		var prev, payload models.UserPayload
+		if err := json.Unmarshal(stored, &prev); err != nil {
+			return fmt.Errorf("failed to unmarshal user: %w", err)
+		}
+		if err := json.Unmarshal(plaintext, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal user: %w", err)
+		}
+		payload.PasswordChangedAt = passwordChangedAt(&prev, payload.Password, now)
+		if plaintext, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to marshal user payload: %w", err)
+		}
+	}
+
+	ciphertext, err := resealer.Reseal(enc, binding, plaintext)
+	if err != nil {
+		return fmt.Errorf("encryption failed: %w", err)
+	}
+
+	return saver.SaveShared(ctx, token, secretOwner, secretType, secretName, ciphertext)
+}
+
+// writePayload pretty-prints a decrypted payload of secretType to b.
+func writePayload(b *strings.Builder, secretType string, decrypted []byte) error {
+	payload, err := decodePayload(secretType, decrypted)
+	if errors.Is(err, errUnknownSecretType) {
+		b.WriteString(fmt.Sprintf("Unknown secret type: %s\n", secretType))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(payload, "", "  ")
+	b.Write(out)
+	return nil
+}
+
+// errUnknownSecretType is returned by decodePayload for a secret type it has no payload for.
+var errUnknownSecretType = errors.New("unknown secret type")
+
+// decodePayload unmarshals a decrypted payload into the models payload of secretType.
+func decodePayload(secretType string, decrypted []byte) (any, error) {
+	switch secretType {
+	case models.SecretTypeBankCard:
+		var bankcard models.BankcardPayload
+		if err := json.Unmarshal(decrypted, &bankcard); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bankcard: %w", err)
+		}
+		return bankcard, nil
+
+	case models.SecretTypeText:
+		var text models.TextPayload
+		if err := json.Unmarshal(decrypted, &text); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal text: %w", err)
+		}
+		return text, nil
+
+	case models.SecretTypeBinary:
+		var binary models.BinaryPayload
+		if err := json.Unmarshal(decrypted, &binary); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal binary: %w", err)
+		}
+		return binary, nil
+
+	case models.SecretTypeUser:
+		var user models.UserPayload
+		if err := json.Unmarshal(decrypted, &user); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+		}
+		return user, nil
+
+	case models.SecretTypeSSHKey:
+		var key models.SSHKeyPayload
+		if err := json.Unmarshal(decrypted, &key); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal sshkey: %w", err)
+		}
+		return key, nil
+
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownSecretType, secretType)
+	}
+}
+
+// tokenBinding returns the binding of a secret of the user token was issued to.
+func tokenBinding(token, secretType, secretName string) (models.SecretBinding, error) {
+	owner, err := jwt.Username(token)
+	if err != nil {
+		return models.SecretBinding{}, fmt.Errorf("failed to read token: %w", err)
+	}
+	return models.SecretBinding{Owner: owner, Type: secretType, Name: secretName}, nil
+}
+
+// secretKeyIDs returns the identifiers of the keys a secret is encrypted to.
+func secretKeyIDs(secret *models.Secret) []string {
+	if len(secret.Recipients) == 0 {
+		return []string{secret.KeyID}
+	}
+	ids := make([]string, 0, len(secret.Recipients))
+	for _, r := range secret.Recipients {
+		ids = append(ids, r.KeyID)
+	}
+	return ids
+}
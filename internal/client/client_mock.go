@@ -37,18 +37,56 @@ func (m *MockRegisterer) EXPECT() *MockRegistererMockRecorder {
 }
 
 // Register mocks base method.
-func (m *MockRegisterer) Register(ctx context.Context, username, password string) (*string, error) {
+func (m *MockRegisterer) Register(ctx context.Context, username, password string, kdfParams *models.KDFParams) (*string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", ctx, username, password)
+	ret := m.ctrl.Call(m, "Register", ctx, username, password, kdfParams)
 	ret0, _ := ret[0].(*string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Register indicates an expected call of Register.
-func (mr *MockRegistererMockRecorder) Register(ctx, username, password interface{}) *gomock.Call {
+func (mr *MockRegistererMockRecorder) Register(ctx, username, password, kdfParams interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockRegisterer)(nil).Register), ctx, username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockRegisterer)(nil).Register), ctx, username, password, kdfParams)
+}
+
+// MockKDFParamsGetter is a mock of KDFParamsGetter interface.
+type MockKDFParamsGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockKDFParamsGetterMockRecorder
+}
+
+// MockKDFParamsGetterMockRecorder is the mock recorder for MockKDFParamsGetter.
+type MockKDFParamsGetterMockRecorder struct {
+	mock *MockKDFParamsGetter
+}
+
+// NewMockKDFParamsGetter creates a new mock instance.
+func NewMockKDFParamsGetter(ctrl *gomock.Controller) *MockKDFParamsGetter {
+	mock := &MockKDFParamsGetter{ctrl: ctrl}
+	mock.recorder = &MockKDFParamsGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKDFParamsGetter) EXPECT() *MockKDFParamsGetterMockRecorder {
+	return m.recorder
+}
+
+// GetKDFParams mocks base method.
+func (m *MockKDFParamsGetter) GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKDFParams", ctx, username)
+	ret0, _ := ret[0].(*models.KDFParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKDFParams indicates an expected call of GetKDFParams.
+func (mr *MockKDFParamsGetterMockRecorder) GetKDFParams(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKDFParams", reflect.TypeOf((*MockKDFParamsGetter)(nil).GetKDFParams), ctx, username)
 }
 
 // MockLoginer is a mock of Loginer interface.
@@ -75,18 +113,168 @@ func (m *MockLoginer) EXPECT() *MockLoginerMockRecorder {
 }
 
 // Login mocks base method.
-func (m *MockLoginer) Login(ctx context.Context, username, password string) (*string, error) {
+func (m *MockLoginer) Login(ctx context.Context, username, password, otpCode string) (*string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, username, password)
+	ret := m.ctrl.Call(m, "Login", ctx, username, password, otpCode)
 	ret0, _ := ret[0].(*string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockLoginerMockRecorder) Login(ctx, username, password interface{}) *gomock.Call {
+func (mr *MockLoginerMockRecorder) Login(ctx, username, password, otpCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockLoginer)(nil).Login), ctx, username, password, otpCode)
+}
+
+// MockOTPEnroller is a mock of OTPEnroller interface.
+type MockOTPEnroller struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPEnrollerMockRecorder
+}
+
+// MockOTPEnrollerMockRecorder is the mock recorder for MockOTPEnroller.
+type MockOTPEnrollerMockRecorder struct {
+	mock *MockOTPEnroller
+}
+
+// NewMockOTPEnroller creates a new mock instance.
+func NewMockOTPEnroller(ctrl *gomock.Controller) *MockOTPEnroller {
+	mock := &MockOTPEnroller{ctrl: ctrl}
+	mock.recorder = &MockOTPEnrollerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPEnroller) EXPECT() *MockOTPEnrollerMockRecorder {
+	return m.recorder
+}
+
+// EnrollOTP mocks base method.
+func (m *MockOTPEnroller) EnrollOTP(ctx context.Context, token string) (*models.OTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollOTP", ctx, token)
+	ret0, _ := ret[0].(*models.OTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollOTP indicates an expected call of EnrollOTP.
+func (mr *MockOTPEnrollerMockRecorder) EnrollOTP(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollOTP", reflect.TypeOf((*MockOTPEnroller)(nil).EnrollOTP), ctx, token)
+}
+
+// MockOTPConfirmer is a mock of OTPConfirmer interface.
+type MockOTPConfirmer struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPConfirmerMockRecorder
+}
+
+// MockOTPConfirmerMockRecorder is the mock recorder for MockOTPConfirmer.
+type MockOTPConfirmerMockRecorder struct {
+	mock *MockOTPConfirmer
+}
+
+// NewMockOTPConfirmer creates a new mock instance.
+func NewMockOTPConfirmer(ctrl *gomock.Controller) *MockOTPConfirmer {
+	mock := &MockOTPConfirmer{ctrl: ctrl}
+	mock.recorder = &MockOTPConfirmerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPConfirmer) EXPECT() *MockOTPConfirmerMockRecorder {
+	return m.recorder
+}
+
+// ConfirmOTP mocks base method.
+func (m *MockOTPConfirmer) ConfirmOTP(ctx context.Context, token, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmOTP", ctx, token, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmOTP indicates an expected call of ConfirmOTP.
+func (mr *MockOTPConfirmerMockRecorder) ConfirmOTP(ctx, token, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmOTP", reflect.TypeOf((*MockOTPConfirmer)(nil).ConfirmOTP), ctx, token, code)
+}
+
+// MockPasswordChanger is a mock of PasswordChanger interface.
+type MockPasswordChanger struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordChangerMockRecorder
+}
+
+// MockPasswordChangerMockRecorder is the mock recorder for MockPasswordChanger.
+type MockPasswordChangerMockRecorder struct {
+	mock *MockPasswordChanger
+}
+
+// NewMockPasswordChanger creates a new mock instance.
+func NewMockPasswordChanger(ctrl *gomock.Controller) *MockPasswordChanger {
+	mock := &MockPasswordChanger{ctrl: ctrl}
+	mock.recorder = &MockPasswordChangerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordChanger) EXPECT() *MockPasswordChangerMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockPasswordChanger) ChangePassword(ctx context.Context, token, oldPassword, newPassword string) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, token, oldPassword, newPassword)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockPasswordChangerMockRecorder) ChangePassword(ctx, token, oldPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockPasswordChanger)(nil).ChangePassword), ctx, token, oldPassword, newPassword)
+}
+
+// MockAccountDeleter is a mock of AccountDeleter interface.
+type MockAccountDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountDeleterMockRecorder
+}
+
+// MockAccountDeleterMockRecorder is the mock recorder for MockAccountDeleter.
+type MockAccountDeleterMockRecorder struct {
+	mock *MockAccountDeleter
+}
+
+// NewMockAccountDeleter creates a new mock instance.
+func NewMockAccountDeleter(ctrl *gomock.Controller) *MockAccountDeleter {
+	mock := &MockAccountDeleter{ctrl: ctrl}
+	mock.recorder = &MockAccountDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountDeleter) EXPECT() *MockAccountDeleterMockRecorder {
+	return m.recorder
+}
+
+// DeleteAccount mocks base method.
+func (m *MockAccountDeleter) DeleteAccount(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockAccountDeleterMockRecorder) DeleteAccount(ctx, token, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockLoginer)(nil).Login), ctx, username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockAccountDeleter)(nil).DeleteAccount), ctx, token, password)
 }
 
 // MockEncryptor is a mock of Encryptor interface.
@@ -113,18 +301,18 @@ func (m *MockEncryptor) EXPECT() *MockEncryptorMockRecorder {
 }
 
 // Encrypt mocks base method.
-func (m *MockEncryptor) Encrypt(plaintext []byte) (*models.SecretEncrypted, error) {
+func (m *MockEncryptor) Encrypt(plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Encrypt", plaintext)
+	ret := m.ctrl.Call(m, "Encrypt", plaintext, binding)
 	ret0, _ := ret[0].(*models.SecretEncrypted)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Encrypt indicates an expected call of Encrypt.
-func (mr *MockEncryptorMockRecorder) Encrypt(plaintext interface{}) *gomock.Call {
+func (mr *MockEncryptorMockRecorder) Encrypt(plaintext, binding interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encrypt", reflect.TypeOf((*MockEncryptor)(nil).Encrypt), plaintext)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Encrypt", reflect.TypeOf((*MockEncryptor)(nil).Encrypt), plaintext, binding)
 }
 
 // MockDecryptor is a mock of Decryptor interface.
@@ -151,18 +339,18 @@ func (m *MockDecryptor) EXPECT() *MockDecryptorMockRecorder {
 }
 
 // Decrypt mocks base method.
-func (m *MockDecryptor) Decrypt(secret *models.SecretEncrypted) ([]byte, error) {
+func (m *MockDecryptor) Decrypt(secret *models.SecretEncrypted, binding models.SecretBinding) ([]byte, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Decrypt", secret)
+	ret := m.ctrl.Call(m, "Decrypt", secret, binding)
 	ret0, _ := ret[0].([]byte)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Decrypt indicates an expected call of Decrypt.
-func (mr *MockDecryptorMockRecorder) Decrypt(secret interface{}) *gomock.Call {
+func (mr *MockDecryptorMockRecorder) Decrypt(secret, binding interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decrypt", reflect.TypeOf((*MockDecryptor)(nil).Decrypt), secret)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Decrypt", reflect.TypeOf((*MockDecryptor)(nil).Decrypt), secret, binding)
 }
 
 // MockClientSaver is a mock of ClientSaver interface.
@@ -189,17 +377,54 @@ func (m *MockClientSaver) EXPECT() *MockClientSaverMockRecorder {
 }
 
 // Save mocks base method.
-func (m *MockClientSaver) Save(ctx context.Context, secretOwner, secretName, secretType string, ciphertext, aesKeyEnc []byte) error {
+func (m *MockClientSaver) Save(ctx context.Context, secretOwner, secretName, secretType string, ciphertext, aesKeyEnc []byte, keyID string, recipients models.Recipients) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc)
+	ret := m.ctrl.Call(m, "Save", ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockClientSaverMockRecorder) Save(ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc interface{}) *gomock.Call {
+func (mr *MockClientSaverMockRecorder) Save(ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockClientSaver)(nil).Save), ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockClientSaver)(nil).Save), ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
+}
+
+// MockClientDeleter is a mock of ClientDeleter interface.
+type MockClientDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientDeleterMockRecorder
+}
+
+// MockClientDeleterMockRecorder is the mock recorder for MockClientDeleter.
+type MockClientDeleterMockRecorder struct {
+	mock *MockClientDeleter
+}
+
+// NewMockClientDeleter creates a new mock instance.
+func NewMockClientDeleter(ctrl *gomock.Controller) *MockClientDeleter {
+	mock := &MockClientDeleter{ctrl: ctrl}
+	mock.recorder = &MockClientDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientDeleter) EXPECT() *MockClientDeleterMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockClientDeleter) Delete(ctx context.Context, secretOwner, secretType, secretName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, secretOwner, secretType, secretName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockClientDeleterMockRecorder) Delete(ctx, secretOwner, secretType, secretName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClientDeleter)(nil).Delete), ctx, secretOwner, secretType, secretName)
 }
 
 // MockClientLister is a mock of ClientLister interface.
@@ -340,17 +565,54 @@ func (m *MockServerSaver) EXPECT() *MockServerSaverMockRecorder {
 }
 
 // Save mocks base method.
-func (m *MockServerSaver) Save(ctx context.Context, secretOwner, secretName, secretType string, ciphertext, aesKeyEnc []byte) error {
+func (m *MockServerSaver) Save(ctx context.Context, secretOwner, secretName, secretType string, ciphertext, aesKeyEnc []byte, keyID string, recipients models.Recipients) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc)
+	ret := m.ctrl.Call(m, "Save", ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockServerSaverMockRecorder) Save(ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc interface{}) *gomock.Call {
+func (mr *MockServerSaverMockRecorder) Save(ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockServerSaver)(nil).Save), ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
+}
+
+// MockSecretBatchSaver is a mock of SecretBatchSaver interface.
+type MockSecretBatchSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretBatchSaverMockRecorder
+}
+
+// MockSecretBatchSaverMockRecorder is the mock recorder for MockSecretBatchSaver.
+type MockSecretBatchSaverMockRecorder struct {
+	mock *MockSecretBatchSaver
+}
+
+// NewMockSecretBatchSaver creates a new mock instance.
+func NewMockSecretBatchSaver(ctrl *gomock.Controller) *MockSecretBatchSaver {
+	mock := &MockSecretBatchSaver{ctrl: ctrl}
+	mock.recorder = &MockSecretBatchSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretBatchSaver) EXPECT() *MockSecretBatchSaverMockRecorder {
+	return m.recorder
+}
+
+// SaveBatch mocks base method.
+func (m *MockSecretBatchSaver) SaveBatch(ctx context.Context, secretOwner string, secrets []*models.Secret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, secretOwner, secrets)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockSecretBatchSaverMockRecorder) SaveBatch(ctx, secretOwner, secrets interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockServerSaver)(nil).Save), ctx, secretOwner, secretName, secretType, ciphertext, aesKeyEnc)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockSecretBatchSaver)(nil).SaveBatch), ctx, secretOwner, secrets)
 }
 
 // MockClientResolver is a mock of ClientResolver interface.
@@ -426,3 +688,484 @@ func (mr *MockInteractiveResolverMockRecorder) Resolve(ctx, secretOwner, reader
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resolve", reflect.TypeOf((*MockInteractiveResolver)(nil).Resolve), ctx, secretOwner, reader)
 }
+
+// MockAuditLister is a mock of AuditLister interface.
+type MockAuditLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditListerMockRecorder
+}
+
+// MockAuditListerMockRecorder is the mock recorder for MockAuditLister.
+type MockAuditListerMockRecorder struct {
+	mock *MockAuditLister
+}
+
+// NewMockAuditLister creates a new mock instance.
+func NewMockAuditLister(ctrl *gomock.Controller) *MockAuditLister {
+	mock := &MockAuditLister{ctrl: ctrl}
+	mock.recorder = &MockAuditListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLister) EXPECT() *MockAuditListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAuditLister) List(ctx context.Context, token string, limit int) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, token, limit)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditListerMockRecorder) List(ctx, token, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditLister)(nil).List), ctx, token, limit)
+}
+
+// MockCertificatePublisher is a mock of CertificatePublisher interface.
+type MockCertificatePublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificatePublisherMockRecorder
+}
+
+// MockCertificatePublisherMockRecorder is the mock recorder for MockCertificatePublisher.
+type MockCertificatePublisherMockRecorder struct {
+	mock *MockCertificatePublisher
+}
+
+// NewMockCertificatePublisher creates a new mock instance.
+func NewMockCertificatePublisher(ctrl *gomock.Controller) *MockCertificatePublisher {
+	mock := &MockCertificatePublisher{ctrl: ctrl}
+	mock.recorder = &MockCertificatePublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificatePublisher) EXPECT() *MockCertificatePublisherMockRecorder {
+	return m.recorder
+}
+
+// PublishCertificate mocks base method.
+func (m *MockCertificatePublisher) PublishCertificate(ctx context.Context, token, certificate string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishCertificate", ctx, token, certificate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishCertificate indicates an expected call of PublishCertificate.
+func (mr *MockCertificatePublisherMockRecorder) PublishCertificate(ctx, token, certificate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCertificate", reflect.TypeOf((*MockCertificatePublisher)(nil).PublishCertificate), ctx, token, certificate)
+}
+
+// MockCertificateGetter is a mock of CertificateGetter interface.
+type MockCertificateGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificateGetterMockRecorder
+}
+
+// MockCertificateGetterMockRecorder is the mock recorder for MockCertificateGetter.
+type MockCertificateGetterMockRecorder struct {
+	mock *MockCertificateGetter
+}
+
+// NewMockCertificateGetter creates a new mock instance.
+func NewMockCertificateGetter(ctrl *gomock.Controller) *MockCertificateGetter {
+	mock := &MockCertificateGetter{ctrl: ctrl}
+	mock.recorder = &MockCertificateGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificateGetter) EXPECT() *MockCertificateGetterMockRecorder {
+	return m.recorder
+}
+
+// GetCertificate mocks base method.
+func (m *MockCertificateGetter) GetCertificate(ctx context.Context, token, username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCertificate", ctx, token, username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCertificate indicates an expected call of GetCertificate.
+func (mr *MockCertificateGetterMockRecorder) GetCertificate(ctx, token, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCertificate", reflect.TypeOf((*MockCertificateGetter)(nil).GetCertificate), ctx, token, username)
+}
+
+// MockSecretSharer is a mock of SecretSharer interface.
+type MockSecretSharer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretSharerMockRecorder
+}
+
+// MockSecretSharerMockRecorder is the mock recorder for MockSecretSharer.
+type MockSecretSharerMockRecorder struct {
+	mock *MockSecretSharer
+}
+
+// NewMockSecretSharer creates a new mock instance.
+func NewMockSecretSharer(ctrl *gomock.Controller) *MockSecretSharer {
+	mock := &MockSecretSharer{ctrl: ctrl}
+	mock.recorder = &MockSecretSharerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretSharer) EXPECT() *MockSecretSharerMockRecorder {
+	return m.recorder
+}
+
+// Share mocks base method.
+func (m *MockSecretSharer) Share(ctx context.Context, token, secretType, secretName, recipient string, aesKeyEnc []byte, keyID, permission string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", ctx, token, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockSecretSharerMockRecorder) Share(ctx, token, secretType, secretName, recipient, aesKeyEnc, keyID, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockSecretSharer)(nil).Share), ctx, token, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+}
+
+// Unshare mocks base method.
+func (m *MockSecretSharer) Unshare(ctx context.Context, token, secretType, secretName, recipient string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unshare", ctx, token, secretType, secretName, recipient)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unshare indicates an expected call of Unshare.
+func (mr *MockSecretSharerMockRecorder) Unshare(ctx, token, secretType, secretName, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unshare", reflect.TypeOf((*MockSecretSharer)(nil).Unshare), ctx, token, secretType, secretName, recipient)
+}
+
+// MockSharedLister is a mock of SharedLister interface.
+type MockSharedLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharedListerMockRecorder
+}
+
+// MockSharedListerMockRecorder is the mock recorder for MockSharedLister.
+type MockSharedListerMockRecorder struct {
+	mock *MockSharedLister
+}
+
+// NewMockSharedLister creates a new mock instance.
+func NewMockSharedLister(ctrl *gomock.Controller) *MockSharedLister {
+	mock := &MockSharedLister{ctrl: ctrl}
+	mock.recorder = &MockSharedListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSharedLister) EXPECT() *MockSharedListerMockRecorder {
+	return m.recorder
+}
+
+// ListSharedWithMe mocks base method.
+func (m *MockSharedLister) ListSharedWithMe(ctx context.Context, token string) ([]*models.SharedSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSharedWithMe", ctx, token)
+	ret0, _ := ret[0].([]*models.SharedSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSharedWithMe indicates an expected call of ListSharedWithMe.
+func (mr *MockSharedListerMockRecorder) ListSharedWithMe(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSharedWithMe", reflect.TypeOf((*MockSharedLister)(nil).ListSharedWithMe), ctx, token)
+}
+
+// MockSharedSaver is a mock of SharedSaver interface.
+type MockSharedSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharedSaverMockRecorder
+}
+
+// MockSharedSaverMockRecorder is the mock recorder for MockSharedSaver.
+type MockSharedSaverMockRecorder struct {
+	mock *MockSharedSaver
+}
+
+// NewMockSharedSaver creates a new mock instance.
+func NewMockSharedSaver(ctrl *gomock.Controller) *MockSharedSaver {
+	mock := &MockSharedSaver{ctrl: ctrl}
+	mock.recorder = &MockSharedSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSharedSaver) EXPECT() *MockSharedSaverMockRecorder {
+	return m.recorder
+}
+
+// SaveShared mocks base method.
+func (m *MockSharedSaver) SaveShared(ctx context.Context, token, secretOwner, secretType, secretName string, ciphertext []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveShared", ctx, token, secretOwner, secretType, secretName, ciphertext)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveShared indicates an expected call of SaveShared.
+func (mr *MockSharedSaverMockRecorder) SaveShared(ctx, token, secretOwner, secretType, secretName, ciphertext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveShared", reflect.TypeOf((*MockSharedSaver)(nil).SaveShared), ctx, token, secretOwner, secretType, secretName, ciphertext)
+}
+
+// MockKeyWrapper is a mock of KeyWrapper interface.
+type MockKeyWrapper struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyWrapperMockRecorder
+}
+
+// MockKeyWrapperMockRecorder is the mock recorder for MockKeyWrapper.
+type MockKeyWrapperMockRecorder struct {
+	mock *MockKeyWrapper
+}
+
+// NewMockKeyWrapper creates a new mock instance.
+func NewMockKeyWrapper(ctrl *gomock.Controller) *MockKeyWrapper {
+	mock := &MockKeyWrapper{ctrl: ctrl}
+	mock.recorder = &MockKeyWrapperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyWrapper) EXPECT() *MockKeyWrapperMockRecorder {
+	return m.recorder
+}
+
+// WrapFor mocks base method.
+func (m *MockKeyWrapper) WrapFor(enc *models.SecretEncrypted, certPEM []byte) (*models.Recipient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WrapFor", enc, certPEM)
+	ret0, _ := ret[0].(*models.Recipient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WrapFor indicates an expected call of WrapFor.
+func (mr *MockKeyWrapperMockRecorder) WrapFor(enc, certPEM interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WrapFor", reflect.TypeOf((*MockKeyWrapper)(nil).WrapFor), enc, certPEM)
+}
+
+// MockResealer is a mock of Resealer interface.
+type MockResealer struct {
+	ctrl     *gomock.Controller
+	recorder *MockResealerMockRecorder
+}
+
+// MockResealerMockRecorder is the mock recorder for MockResealer.
+type MockResealerMockRecorder struct {
+	mock *MockResealer
+}
+
+// NewMockResealer creates a new mock instance.
+func NewMockResealer(ctrl *gomock.Controller) *MockResealer {
+	mock := &MockResealer{ctrl: ctrl}
+	mock.recorder = &MockResealerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockResealer) EXPECT() *MockResealerMockRecorder {
+	return m.recorder
+}
+
+// Reseal mocks base method.
+func (m *MockResealer) Reseal(enc *models.SecretEncrypted, binding models.SecretBinding, plaintext []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reseal", enc, binding, plaintext)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reseal indicates an expected call of Reseal.
+func (mr *MockResealerMockRecorder) Reseal(enc, binding, plaintext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reseal", reflect.TypeOf((*MockResealer)(nil).Reseal), enc, binding, plaintext)
+}
+
+// MockUpgrader is a mock of Upgrader interface.
+type MockUpgrader struct {
+	ctrl     *gomock.Controller
+	recorder *MockUpgraderMockRecorder
+}
+
+// MockUpgraderMockRecorder is the mock recorder for MockUpgrader.
+type MockUpgraderMockRecorder struct {
+	mock *MockUpgrader
+}
+
+// NewMockUpgrader creates a new mock instance.
+func NewMockUpgrader(ctrl *gomock.Controller) *MockUpgrader {
+	mock := &MockUpgrader{ctrl: ctrl}
+	mock.recorder = &MockUpgraderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUpgrader) EXPECT() *MockUpgraderMockRecorder {
+	return m.recorder
+}
+
+// Upgrade mocks base method.
+func (m *MockUpgrader) Upgrade(enc *models.SecretEncrypted, binding models.SecretBinding) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Upgrade", enc, binding)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upgrade indicates an expected call of Upgrade.
+func (mr *MockUpgraderMockRecorder) Upgrade(enc, binding interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upgrade", reflect.TypeOf((*MockUpgrader)(nil).Upgrade), enc, binding)
+}
+
+// MockReencryptor is a mock of Reencryptor interface.
+type MockReencryptor struct {
+	ctrl     *gomock.Controller
+	recorder *MockReencryptorMockRecorder
+}
+
+// MockReencryptorMockRecorder is the mock recorder for MockReencryptor.
+type MockReencryptorMockRecorder struct {
+	mock *MockReencryptor
+}
+
+// NewMockReencryptor creates a new mock instance.
+func NewMockReencryptor(ctrl *gomock.Controller) *MockReencryptor {
+	mock := &MockReencryptor{ctrl: ctrl}
+	mock.recorder = &MockReencryptorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReencryptor) EXPECT() *MockReencryptorMockRecorder {
+	return m.recorder
+}
+
+// Reencrypt mocks base method.
+func (m *MockReencryptor) Reencrypt(prev *models.SecretEncrypted, plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reencrypt", prev, plaintext, binding)
+	ret0, _ := ret[0].(*models.SecretEncrypted)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reencrypt indicates an expected call of Reencrypt.
+func (mr *MockReencryptorMockRecorder) Reencrypt(prev, plaintext, binding interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reencrypt", reflect.TypeOf((*MockReencryptor)(nil).Reencrypt), prev, plaintext, binding)
+}
+
+// MockPasswordGenerator is a mock of PasswordGenerator interface.
+type MockPasswordGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordGeneratorMockRecorder
+}
+
+// MockPasswordGeneratorMockRecorder is the mock recorder for MockPasswordGenerator.
+type MockPasswordGeneratorMockRecorder struct {
+	mock *MockPasswordGenerator
+}
+
+// NewMockPasswordGenerator creates a new mock instance.
+func NewMockPasswordGenerator(ctrl *gomock.Controller) *MockPasswordGenerator {
+	mock := &MockPasswordGenerator{ctrl: ctrl}
+	mock.recorder = &MockPasswordGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordGenerator) EXPECT() *MockPasswordGeneratorMockRecorder {
+	return m.recorder
+}
+
+// Entropy mocks base method.
+func (m *MockPasswordGenerator) Entropy() float64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Entropy")
+	ret0, _ := ret[0].(float64)
+	return ret0
+}
+
+// Entropy indicates an expected call of Entropy.
+func (mr *MockPasswordGeneratorMockRecorder) Entropy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Entropy", reflect.TypeOf((*MockPasswordGenerator)(nil).Entropy))
+}
+
+// Generate mocks base method.
+func (m *MockPasswordGenerator) Generate() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Generate indicates an expected call of Generate.
+func (mr *MockPasswordGeneratorMockRecorder) Generate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockPasswordGenerator)(nil).Generate))
+}
+
+// MockBreachLookup is a mock of BreachLookup interface.
+type MockBreachLookup struct {
+	ctrl     *gomock.Controller
+	recorder *MockBreachLookupMockRecorder
+}
+
+// MockBreachLookupMockRecorder is the mock recorder for MockBreachLookup.
+type MockBreachLookupMockRecorder struct {
+	mock *MockBreachLookup
+}
+
+// NewMockBreachLookup creates a new mock instance.
+func NewMockBreachLookup(ctrl *gomock.Controller) *MockBreachLookup {
+	mock := &MockBreachLookup{ctrl: ctrl}
+	mock.recorder = &MockBreachLookupMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBreachLookup) EXPECT() *MockBreachLookupMockRecorder {
+	return m.recorder
+}
+
+// Lookup mocks base method.
+func (m *MockBreachLookup) Lookup(hashes []string) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lookup", hashes)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lookup indicates an expected call of Lookup.
+func (mr *MockBreachLookupMockRecorder) Lookup(hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lookup", reflect.TypeOf((*MockBreachLookup)(nil).Lookup), hashes)
+}
@@ -1,23 +1,41 @@
 package client
 
 const (
-	CommandRegister    = "register"
-	CommandLogin       = "login"
-	CommandAddBankcard = "add-bankcard"
-	CommandAddText     = "add-text"
-	CommandAddBinary   = "add-binary"
-	CommandAddUser     = "add-user"
-	CommandList        = "list"
-	CommandSync        = "sync"
-	CommandVersion     = "version"
-	CommandHelp        = "help"
+	CommandRegister         = "register"
+	CommandLogin            = "login"
+	CommandOTPEnroll        = "otp-enroll"
+	CommandOTPConfirm       = "otp-confirm"
+	CommandChangePassword   = "change-password"
+	CommandDeleteAccount    = "delete-account"
+	CommandAddBankcard      = "add-bankcard"
+	CommandAddText          = "add-text"
+	CommandAddBinary        = "add-binary"
+	CommandAddUser          = "add-user"
+	CommandAddSSHKey        = "add-sshkey"
+	CommandGenerate         = "generate"
+	CommandImport           = "import"
+	CommandExport           = "export"
+	CommandRestoreBackup    = "restore-backup"
+	CommandTUI              = "tui"
+	CommandShell            = "shell"
+	CommandAgent            = "agent"
+	CommandSSHAgent         = "ssh-agent"
+	CommandList             = "list"
+	CommandGet              = "get"
+	CommandRun              = "run"
+	CommandGitCredential    = "git-credential"
+	CommandDockerCredential = "docker-credential"
+	CommandHealth           = "health"
+	CommandBreachCheck      = "breach-check"
+	CommandSync             = "sync"
+	CommandRotateKeys       = "rotate-keys"
+	CommandUpgrade          = "upgrade-secrets"
+	CommandAudit            = "audit"
+	CommandPublishCert      = "publish-cert"
+	CommandShare            = "share"
+	CommandUnshare          = "unshare"
+	CommandListShared       = "list-shared"
+	CommandUpdateShared     = "update-shared"
+	CommandVersion          = "version"
+	CommandHelp             = "help"
 )
-
-// GetCommand extracts the command from args.
-// If no command provided, returns empty string.
-func GetCommand(args []string) string {
-	if len(args) > 1 {
-		return args[1]
-	}
-	return ""
-}
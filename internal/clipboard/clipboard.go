@@ -0,0 +1,185 @@
+// Package clipboard copies text to the system clipboard and clears it again after a timeout.
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultClearAfter is how long copied text stays on the clipboard by default.
+const DefaultClearAfter = 45 * time.Second
+
+var (
+	// ErrNoBackend is returned by Detect when neither a clipboard tool nor a terminal is available.
+	ErrNoBackend = errors.New("no clipboard available: install wl-clipboard, xclip or xsel, or run in a terminal supporting OSC 52")
+	// ErrUnreadable is returned by backends that can write the clipboard but not read it.
+	ErrUnreadable = errors.New("clipboard cannot be read")
+)
+
+// Backend writes and reads the system clipboard.
+type Backend interface {
+	Write(ctx context.Context, text string) error
+	Read(ctx context.Context) (string, error)
+}
+
+// Command is a backend running external clipboard tools.
+type Command struct {
+	copyCmd  []string
+	pasteCmd []string
+}
+
+// WlClipboard returns the backend of wl-copy and wl-paste, for Wayland sessions.
+func WlClipboard() *Command {
+	return &Command{
+		copyCmd:  []string{"wl-copy"},
+		pasteCmd: []string{"wl-paste", "--no-newline"},
+	}
+}
+
+// Xclip returns the backend of xclip, for X11 sessions.
+func Xclip() *Command {
+	return &Command{
+		copyCmd:  []string{"xclip", "-selection", "clipboard"},
+		pasteCmd: []string{"xclip", "-selection", "clipboard", "-o"},
+	}
+}
+
+// Xsel returns the backend of xsel, for X11 sessions.
+func Xsel() *Command {
+	return &Command{
+		copyCmd:  []string{"xsel", "--clipboard", "--input"},
+		pasteCmd: []string{"xsel", "--clipboard", "--output"},
+	}
+}
+
+// Name returns the name of the tool that copies text.
+func (c *Command) Name() string {
+	return c.copyCmd[0]
+}
+
+// Write passes text to the copy tool on its standard input, so it never shows in the process list.
+func (c *Command) Write(ctx context.Context, text string) error {
+	return c.run(ctx, c.copyCmd, strings.NewReader(text), nil)
+}
+
+// Read returns the output of the paste tool.
+func (c *Command) Read(ctx context.Context) (string, error) {
+	var stdout bytes.Buffer
+	if err := c.run(ctx, c.pasteCmd, nil, &stdout); err != nil {
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// toolWaitDelay is how long run waits for the standard error of a tool once it has exited.
+// xclip and wl-copy fork a child that serves the clipboard and inherits the pipe, so
+// waiting for the pipe to close would last until something else is copied.
+const toolWaitDelay = 200 * time.Millisecond
+
+func (c *Command) run(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = toolWaitDelay
+	err := cmd.Run()
+	if errors.Is(err, exec.ErrWaitDelay) {
+		// The tool succeeded and left a child holding its standard error.
+		return nil
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w: %s", args[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
+
+// OSC52 is a backend asking the terminal to set the clipboard with the OSC 52 escape sequence.
+// It also works over SSH, but the clipboard cannot be read back.
+type OSC52 struct {
+	w io.Writer
+}
+
+// NewOSC52 returns the OSC 52 backend writing to the terminal w.
+func NewOSC52(w io.Writer) *OSC52 {
+	return &OSC52{w: w}
+}
+
+// Write sends text to the terminal; empty text clears the clipboard.
+func (o *OSC52) Write(_ context.Context, text string) error {
+	_, err := fmt.Fprintf(o.w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+// Read returns ErrUnreadable: terminals do not answer clipboard queries reliably.
+func (o *OSC52) Read(context.Context) (string, error) {
+	return "", ErrUnreadable
+}
+
+// Detect returns the clipboard tool of the session: wl-copy on Wayland, xclip or xsel on X11.
+// Without one it falls back to OSC 52 on tty, when tty is not nil.
+func Detect(tty io.Writer) (Backend, error) {
+	var candidates []*Command
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		candidates = append(candidates, WlClipboard())
+	}
+	if os.Getenv("DISPLAY") != "" {
+		candidates = append(candidates, Xclip(), Xsel())
+	}
+	for _, c := range candidates {
+		if _, err := exec.LookPath(c.copyCmd[0]); err == nil {
+			if _, err := exec.LookPath(c.pasteCmd[0]); err == nil {
+				return c, nil
+			}
+		}
+	}
+	if tty != nil {
+		return NewOSC52(tty), nil
+	}
+	return nil, ErrNoBackend
+}
+
+// Copy writes text to the clipboard of b. With a positive clearAfter it then waits that long,
+// or until ctx is done, and clears the clipboard unless its content has changed meanwhile.
+// A clipboard that cannot be read is cleared regardless.
+func Copy(ctx context.Context, b Backend, text string, clearAfter time.Duration) error {
+	if err := b.Write(ctx, text); err != nil {
+		return fmt.Errorf("failed to copy to the clipboard: %w", err)
+	}
+	if clearAfter <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(clearAfter)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	// Clear even when ctx is cancelled, an interrupt is the user asking to clear now.
+	ctx = context.WithoutCancel(ctx)
+	current, err := b.Read(ctx)
+	switch {
+	case errors.Is(err, ErrUnreadable):
+	case err != nil:
+		return fmt.Errorf("failed to read the clipboard: %w", err)
+	case current != text:
+		return nil
+	}
+	if err := b.Write(ctx, ""); err != nil {
+		return fmt.Errorf("failed to clear the clipboard: %w", err)
+	}
+	return nil
+}
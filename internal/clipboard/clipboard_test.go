@@ -0,0 +1,218 @@
+package clipboard
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend is an in-memory clipboard recording every write.
+type fakeBackend struct {
+	mu      sync.Mutex
+	content string
+	writes  []string
+	// onWrite runs after each write, to change the clipboard behind Copy's back.
+	onWrite func(f *fakeBackend)
+}
+
+func (f *fakeBackend) Write(_ context.Context, text string) error {
+	f.mu.Lock()
+	f.content = text
+	f.writes = append(f.writes, text)
+	onWrite := f.onWrite
+	f.mu.Unlock()
+	if onWrite != nil {
+		onWrite(f)
+	}
+	return nil
+}
+
+func (f *fakeBackend) Read(context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.content, nil
+}
+
+func (f *fakeBackend) set(text string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.content = text
+}
+
+func TestCopy_ClearsAfterTimeout(t *testing.T) {
+	b := &fakeBackend{}
+	start := time.Now()
+	require.NoError(t, Copy(context.Background(), b, "s3cret!", 20*time.Millisecond))
+
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, []string{"s3cret!", ""}, b.writes)
+	assert.Empty(t, b.content)
+}
+
+func TestCopy_KeepsChangedContent(t *testing.T) {
+	b := &fakeBackend{}
+	b.onWrite = func(f *fakeBackend) {
+		f.onWrite = nil
+		f.set("copied by the user")
+	}
+	require.NoError(t, Copy(context.Background(), b, "s3cret!", 10*time.Millisecond))
+
+	assert.Equal(t, []string{"s3cret!"}, b.writes)
+	assert.Equal(t, "copied by the user", b.content)
+}
+
+func TestCopy_NoClear(t *testing.T) {
+	b := &fakeBackend{}
+	require.NoError(t, Copy(context.Background(), b, "s3cret!", 0))
+
+	assert.Equal(t, []string{"s3cret!"}, b.writes)
+	assert.Equal(t, "s3cret!", b.content)
+}
+
+func TestCopy_ClearsOnCancel(t *testing.T) {
+	b := &fakeBackend{}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.onWrite = func(f *fakeBackend) {
+		f.onWrite = nil
+		cancel()
+	}
+	start := time.Now()
+	require.NoError(t, Copy(ctx, b, "s3cret!", time.Hour))
+
+	assert.Less(t, time.Since(start), time.Minute)
+	assert.Equal(t, []string{"s3cret!", ""}, b.writes)
+}
+
+func TestOSC52(t *testing.T) {
+	var out bytes.Buffer
+	b := NewOSC52(&out)
+	require.NoError(t, Copy(context.Background(), b, "s3cret!", time.Millisecond))
+
+	assert.Equal(t, "\x1b]52;c;czNjcmV0IQ==\a\x1b]52;c;\a", out.String())
+	_, err := b.Read(context.Background())
+	assert.ErrorIs(t, err, ErrUnreadable)
+}
+
+// fakeTools installs xclip and xsel scripts keeping the clipboard in a file and returns the file.
+func fakeTools(t *testing.T) string {
+	cat, err := exec.LookPath("cat")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	store := filepath.Join(dir, "clipboard")
+	script := "#!/bin/sh\n" +
+		"for arg; do\n" +
+		"  case $arg in -o|--output) " + cat + " '" + store + "' 2>/dev/null; exit 0 ;; esac\n" +
+		"done\n" +
+		cat + " > '" + store + "'\n"
+	for _, name := range []string{"xclip", "xsel"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755))
+	}
+	t.Setenv("PATH", dir)
+	return store
+}
+
+func TestDetect(t *testing.T) {
+	store := fakeTools(t)
+
+	t.Run("x11", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "")
+		t.Setenv("DISPLAY", ":0")
+
+		b, err := Detect(nil)
+		require.NoError(t, err)
+		require.IsType(t, &Command{}, b)
+		assert.Equal(t, "xclip", b.(*Command).Name())
+
+		ctx := context.Background()
+		require.NoError(t, b.Write(ctx, "s3cret!"))
+		stored, err := os.ReadFile(store)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret!", string(stored))
+
+		text, err := b.Read(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret!", text)
+	})
+
+	t.Run("wayland without wl-clipboard", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+		t.Setenv("DISPLAY", ":0")
+
+		b, err := Detect(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "xclip", b.(*Command).Name())
+	})
+
+	t.Run("terminal", func(t *testing.T) {
+		t.Setenv("WAYLAND_DISPLAY", "")
+		t.Setenv("DISPLAY", "")
+
+		b, err := Detect(&bytes.Buffer{})
+		require.NoError(t, err)
+		assert.IsType(t, &OSC52{}, b)
+
+		_, err = Detect(nil)
+		assert.ErrorIs(t, err, ErrNoBackend)
+	})
+}
+
+func TestCommand_Error(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "xclip"), []byte("#!/bin/sh\necho 'Error: Can'\\''t open display' >&2\nexit 1\n"), 0o755))
+	t.Setenv("PATH", dir)
+
+	err := Xclip().Write(context.Background(), "s3cret!")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "xclip: exit status 1: Error: Can't open display")
+}
+
+func TestCommand_ForkingTool(t *testing.T) {
+	cat, err := exec.LookPath("cat")
+	require.NoError(t, err)
+	sleep, err := exec.LookPath("sleep")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	store := filepath.Join(dir, "clipboard")
+	pidFile := filepath.Join(dir, "child.pid")
+	// Like xclip, the tool leaves a child serving the clipboard that keeps stdout and stderr open.
+	script := "#!/bin/sh\n" +
+		cat + " > '" + store + "'\n" +
+		sleep + " 30 &\n" +
+		"echo $! > '" + pidFile + "'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0o755))
+	t.Setenv("PATH", dir)
+	t.Cleanup(func() {
+		data, err := os.ReadFile(pidFile)
+		if err != nil {
+			return
+		}
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			if p, err := os.FindProcess(pid); err == nil {
+				p.Kill()
+			}
+		}
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- Xclip().Write(context.Background(), "s3cret!") }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Write waited for the child of the copy tool")
+	}
+
+	stored, err := os.ReadFile(store)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret!", string(stored))
+}
@@ -0,0 +1,152 @@
+// Package credhelper keeps the logins of git and docker in the vault. It speaks the credential
+// helper protocols of both over standard input and output and stores the logins as user secrets
+// with a URL.
+package credhelper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Credential is the login of a site.
+type Credential struct {
+	Name     string // Name is the name of the user secret holding the login.
+	URL      string
+	Username string
+	Password string
+}
+
+// Store keeps the logins of the vault.
+type Store interface {
+	// List returns every login, those without a URL too.
+	List(ctx context.Context) ([]Credential, error)
+	// Save stores c under c.Name, replacing the login of that name.
+	Save(ctx context.Context, c Credential) error
+	// Delete removes the login called name.
+	Delete(ctx context.Context, name string) error
+}
+
+// parseURL parses rawURL, taking bare hosts like registry.example.com:5000 for https.
+func parseURL(rawURL string) (*url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("URL %s has no host", rawURL)
+	}
+	return u, nil
+}
+
+// pathSegments returns the segments of the path of u.
+func pathSegments(u *url.URL) []string {
+	p := strings.Trim(u.Path, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// find returns the login of u among creds, nil when there is none. A login matches when its URL
+// has the scheme and host of u and its path is empty or a leading part of the path of u, and,
+// when username is given, when it is the login of username. The login with the longest path wins.
+func find(creds []Credential, u *url.URL, username string) *Credential {
+	segments := pathSegments(u)
+	var (
+		best      *Credential
+		bestDepth = -1
+	)
+	for i, c := range creds {
+		if c.URL == "" || (username != "" && c.Username != username) {
+			continue
+		}
+		cu, err := parseURL(c.URL)
+		if err != nil || !strings.EqualFold(cu.Scheme, u.Scheme) || !strings.EqualFold(cu.Host, u.Host) {
+			continue
+		}
+		prefix := pathSegments(cu)
+		if len(prefix) > len(segments) || !slices.Equal(prefix, segments[:len(prefix)]) {
+			continue
+		}
+		if len(prefix) > bestDepth {
+			best, bestDepth = &creds[i], len(prefix)
+		}
+	}
+	return best
+}
+
+// lookup lists the logins of store and returns the login of rawURL; see find.
+func lookup(ctx context.Context, store Store, rawURL, username string) ([]Credential, *url.URL, *Credential, error) {
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	creds, err := store.List(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return creds, u, find(creds, u, username), nil
+}
+
+// save stores the login of username on rawURL. The matching login of username is updated unless
+// it already holds password; without one, a login is added under a new name.
+func save(ctx context.Context, store Store, rawURL, username, password string) error {
+	return put(ctx, store, rawURL, username, password, func(creds []Credential, u *url.URL) *Credential {
+		return find(creds, u, username)
+	})
+}
+
+// put stores the login of username on rawURL in the login match picks among the logins of store,
+// unless it already holds username and password; without one, a login is added under a new name.
+// An empty username keeps the username of the picked login.
+func put(
+	ctx context.Context,
+	store Store,
+	rawURL, username, password string,
+	match func(creds []Credential, u *url.URL) *Credential,
+) error {
+	if strings.ContainsAny(rawURL+username+password, "\n\x00") {
+		return fmt.Errorf("the login of %s contains a newline or NUL", rawURL)
+	}
+	u, err := parseURL(rawURL)
+	if err != nil {
+		return err
+	}
+	creds, err := store.List(ctx)
+	if err != nil {
+		return err
+	}
+	if c := match(creds, u); c != nil {
+		if (username == "" || c.Username == username) && c.Password == password {
+			return nil
+		}
+		if username != "" {
+			c.Username = username
+		}
+		c.Password = password
+		return store.Save(ctx, *c)
+	}
+	return store.Save(ctx, Credential{Name: newName(creds, u, username), URL: rawURL, Username: username, Password: password})
+}
+
+// newName returns a name like alice@github.com for a login of username on u that no login in creds has.
+func newName(creds []Credential, u *url.URL, username string) string {
+	base := strings.TrimSuffix(u.Host+u.Path, "/")
+	if username != "" {
+		base = username + "@" + base
+	}
+	taken := func(name string) bool {
+		return slices.ContainsFunc(creds, func(c Credential) bool { return c.Name == name })
+	}
+	name := base
+	for i := 2; taken(name); i++ {
+		name = fmt.Sprintf("%s (%d)", base, i)
+	}
+	return name
+}
@@ -0,0 +1,98 @@
+package credhelper
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps logins in memory, in the order they were saved.
+type fakeStore struct {
+	creds []Credential
+}
+
+func (f *fakeStore) List(context.Context) ([]Credential, error) {
+	return slices.Clone(f.creds), nil
+}
+
+func (f *fakeStore) Save(_ context.Context, c Credential) error {
+	for i := range f.creds {
+		if f.creds[i].Name == c.Name {
+			f.creds[i] = c
+			return nil
+		}
+	}
+	f.creds = append(f.creds, c)
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, name string) error {
+	for i := range f.creds {
+		if f.creds[i].Name == name {
+			f.creds = slices.Delete(f.creds, i, i+1)
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func TestFind(t *testing.T) {
+	creds := []Credential{
+		{Name: "note", Username: "x"},
+		{Name: "github", URL: "https://github.com", Username: "alice"},
+		{Name: "work", URL: "https://github.com/acme", Username: "alice-work"},
+		{Name: "bob", URL: "https://GitHub.com/", Username: "bob"},
+		{Name: "registry", URL: "registry.example.com:5000", Username: "ci"},
+	}
+	tests := []struct {
+		url      string
+		username string
+		want     string
+	}{
+		{url: "https://github.com", want: "github"},
+		{url: "https://github.com/alice/repo.git", want: "github"},
+		{url: "https://github.com/acme/app.git", want: "work"},
+		{url: "https://github.com/acmecorp/app.git", want: "github"},
+		{url: "https://github.com/acme/app.git", username: "bob", want: "bob"},
+		{url: "https://github.com", username: "carol"},
+		{url: "http://github.com"},
+		{url: "https://gitlab.com"},
+		{url: "registry.example.com:5000", want: "registry"},
+		{url: "https://registry.example.com:5000/v2/", want: "registry"},
+		{url: "registry.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url+"/"+tt.username, func(t *testing.T) {
+			u, err := parseURL(tt.url)
+			require.NoError(t, err)
+			got := find(creds, u, tt.username)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, tt.want, got.Name)
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{creds: []Credential{{Name: "alice@github.com", Username: "someone"}}}
+
+	require.NoError(t, save(ctx, store, "https://github.com", "alice", "pw1"))
+	require.NoError(t, save(ctx, store, "https://github.com/acme/app", "alice", "pw2"))
+	require.NoError(t, save(ctx, store, "https://github.com/", "bob", "pw3"))
+	assert.Equal(t, []Credential{
+		{Name: "alice@github.com", Username: "someone"},
+		{Name: "alice@github.com (2)", URL: "https://github.com", Username: "alice", Password: "pw2"},
+		{Name: "bob@github.com", URL: "https://github.com/", Username: "bob", Password: "pw3"},
+	}, store.creds)
+
+	assert.Error(t, save(ctx, store, "https://github.com", "alice", "pw\nusername=mallory"))
+	assert.Error(t, save(ctx, store, "https://", "alice", "pw"))
+}
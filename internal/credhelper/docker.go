@@ -0,0 +1,128 @@
+package credhelper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Actions of the docker credential helper protocol.
+const (
+	DockerGet   = "get"
+	DockerStore = "store"
+	DockerErase = "erase"
+	DockerList  = "list"
+)
+
+// ErrCredentialsNotFound is the error docker expects from a helper without a login for a registry.
+var ErrCredentialsNotFound = errors.New("credentials not found in native keychain")
+
+// dockerCredential is the JSON form of a login docker exchanges with its helpers.
+type dockerCredential struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// readServerURL reads the registry docker names on in.
+func readServerURL(in io.Reader) (string, error) {
+	b, err := io.ReadAll(in)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the server URL: %w", err)
+	}
+	serverURL := strings.TrimSpace(string(b))
+	if serverURL == "" {
+		return "", errors.New("no server URL given")
+	}
+	return serverURL, nil
+}
+
+// Docker runs an action of the docker credential helper protocol. get and erase read a registry
+// from in, store reads a JSON login; get writes the login as JSON and list the usernames of all
+// logins by URL to out. ErrCredentialsNotFound is returned by get and erase without a login for
+// the registry. Docker reads the errors of its helpers from their standard output.
+func Docker(ctx context.Context, store Store, action string, in io.Reader, out io.Writer) error {
+	switch action {
+	case DockerGet:
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		_, _, c, err := lookup(ctx, store, serverURL, "")
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return ErrCredentialsNotFound
+		}
+		return json.NewEncoder(out).Encode(dockerCredential{ServerURL: serverURL, Username: c.Username, Secret: c.Password})
+
+	case DockerStore:
+		var c dockerCredential
+		if err := json.NewDecoder(in).Decode(&c); err != nil {
+			return fmt.Errorf("invalid credential: %w", err)
+		}
+		if c.ServerURL == "" {
+			return errors.New("no server URL given")
+		}
+		return saveRegistry(ctx, store, c.ServerURL, c.Username, c.Secret)
+
+	case DockerErase:
+		serverURL, err := readServerURL(in)
+		if err != nil {
+			return err
+		}
+		_, _, c, err := lookup(ctx, store, serverURL, "")
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return ErrCredentialsNotFound
+		}
+		return store.Delete(ctx, c.Name)
+
+	case DockerList:
+		creds, err := store.List(ctx)
+		if err != nil {
+			return err
+		}
+		list := make(map[string]string)
+		for _, c := range creds {
+			if c.URL != "" {
+				list[c.URL] = c.Username
+			}
+		}
+		return json.NewEncoder(out).Encode(list)
+	}
+	return fmt.Errorf("unknown docker credential action %q", action)
+}
+
+// saveRegistry stores the login of username on the registry rawURL. Docker keeps one login per
+// registry, so the login saved for the same URL is replaced whatever its username; otherwise
+// get could keep returning the login of the previous user.
+func saveRegistry(ctx context.Context, store Store, rawURL, username, password string) error {
+	return put(ctx, store, rawURL, username, password, findRegistry)
+}
+
+// findRegistry returns the first login in creds whose URL has the scheme, host and path of u,
+// nil when there is none.
+func findRegistry(creds []Credential, u *url.URL) *Credential {
+	for i, c := range creds {
+		if c.URL == "" {
+			continue
+		}
+		cu, err := parseURL(c.URL)
+		if err != nil || !strings.EqualFold(cu.Scheme, u.Scheme) || !strings.EqualFold(cu.Host, u.Host) {
+			continue
+		}
+		if slices.Equal(pathSegments(cu), pathSegments(u)) {
+			return &creds[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package credhelper
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// docker runs action like docker does, with in on standard input.
+func docker(t *testing.T, store Store, action, in string) (string, error) {
+	t.Helper()
+	var out strings.Builder
+	err := Docker(context.Background(), store, action, strings.NewReader(in), &out)
+	return out.String(), err
+}
+
+func TestDocker(t *testing.T) {
+	store := &fakeStore{}
+
+	_, err := docker(t, store, DockerGet, "https://index.docker.io/v1/\n")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+	assert.EqualError(t, err, "credentials not found in native keychain")
+
+	_, err = docker(t, store, DockerStore, `{"ServerURL":"https://index.docker.io/v1/","Username":"alice","Secret":"s3cret"}`)
+	require.NoError(t, err)
+	_, err = docker(t, store, DockerStore, `{"ServerURL":"registry.example.com:5000","Username":"ci","Secret":"token"}`)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@index.docker.io/v1", store.creds[0].Name)
+
+	out, err := docker(t, store, DockerGet, "https://index.docker.io/v1/")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ServerURL":"https://index.docker.io/v1/","Username":"alice","Secret":"s3cret"}`, out)
+
+	out, err = docker(t, store, DockerGet, "registry.example.com:5000")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ServerURL":"registry.example.com:5000","Username":"ci","Secret":"token"}`, out)
+
+	out, err = docker(t, store, DockerList, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"https://index.docker.io/v1/":"alice","registry.example.com:5000":"ci"}`, out)
+
+	_, err = docker(t, store, DockerErase, "registry.example.com:5000")
+	require.NoError(t, err)
+	_, err = docker(t, store, DockerErase, "registry.example.com:5000")
+	assert.ErrorIs(t, err, ErrCredentialsNotFound)
+	assert.Len(t, store.creds, 1)
+
+	_, err = docker(t, store, DockerGet, "")
+	assert.EqualError(t, err, "no server URL given")
+	_, err = docker(t, store, DockerStore, `{"Username":"alice"}`)
+	assert.EqualError(t, err, "no server URL given")
+	_, err = docker(t, store, "version", "")
+	assert.EqualError(t, err, `unknown docker credential action "version"`)
+}
+
+func TestDocker_StoreReplacesRegistryLogin(t *testing.T) {
+	store := &fakeStore{}
+
+	_, err := docker(t, store, DockerStore, `{"ServerURL":"registry.example.com:5000","Username":"alice","Secret":"s3cret"}`)
+	require.NoError(t, err)
+	_, err = docker(t, store, DockerStore, `{"ServerURL":"registry.example.com:5000/team","Username":"ci","Secret":"token"}`)
+	require.NoError(t, err)
+	// docker login as another user on the same registry replaces alice's login.
+	_, err = docker(t, store, DockerStore, `{"ServerURL":"https://registry.example.com:5000/","Username":"bob","Secret":"hunter2"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Credential{
+		{Name: "alice@registry.example.com:5000", URL: "registry.example.com:5000", Username: "bob", Password: "hunter2"},
+		{Name: "ci@registry.example.com:5000/team", URL: "registry.example.com:5000/team", Username: "ci", Password: "token"},
+	}, store.creds)
+
+	out, err := docker(t, store, DockerGet, "registry.example.com:5000")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ServerURL":"registry.example.com:5000","Username":"bob","Secret":"hunter2"}`, out)
+
+	out, err = docker(t, store, DockerList, "")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"registry.example.com:5000":"bob","registry.example.com:5000/team":"ci"}`, out)
+}
@@ -0,0 +1,102 @@
+package credhelper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Actions of the git credential helper protocol.
+const (
+	GitGet   = "get"
+	GitStore = "store"
+	GitErase = "erase"
+)
+
+// readGitRequest reads the key=value lines git describes a credential with, up to a blank line
+// or the end of in.
+func readGitRequest(in io.Reader) (map[string]string, error) {
+	attrs := make(map[string]string)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid credential line %q", line)
+		}
+		attrs[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the credential: %w", err)
+	}
+	return attrs, nil
+}
+
+// gitURL returns the URL of a credential: its url attribute, or its protocol, host and path.
+func gitURL(attrs map[string]string) (string, error) {
+	if u := attrs["url"]; u != "" {
+		return u, nil
+	}
+	if attrs["protocol"] == "" || attrs["host"] == "" {
+		return "", errors.New("the credential has no protocol and host")
+	}
+	u := attrs["protocol"] + "://" + attrs["host"]
+	if p := attrs["path"]; p != "" {
+		u += "/" + p
+	}
+	return u, nil
+}
+
+// Git runs an action of the git credential helper protocol on the credential read from in. get
+// writes the username and password of the matching login to out, nothing when there is none, so
+// that git asks the user. store saves the login git used; erase deletes the matching login when it
+// holds the rejected password. Other actions are ignored, as the protocol asks of helpers.
+func Git(ctx context.Context, store Store, action string, in io.Reader, out io.Writer) error {
+	if action != GitGet && action != GitStore && action != GitErase {
+		return nil
+	}
+	attrs, err := readGitRequest(in)
+	if err != nil {
+		return err
+	}
+	rawURL, err := gitURL(attrs)
+	if err != nil {
+		return err
+	}
+	username, password := attrs["username"], attrs["password"]
+
+	switch action {
+	case GitGet:
+		_, _, c, err := lookup(ctx, store, rawURL, username)
+		if err != nil || c == nil {
+			return err
+		}
+		if strings.ContainsAny(c.Username+c.Password, "\n\x00") {
+			return fmt.Errorf("the login %s contains a newline or NUL", c.Name)
+		}
+		_, err = fmt.Fprintf(out, "username=%s\npassword=%s\n", c.Username, c.Password)
+		return err
+
+	case GitStore:
+		if username == "" || password == "" {
+			return nil
+		}
+		return save(ctx, store, rawURL, username, password)
+
+	default:
+		_, _, c, err := lookup(ctx, store, rawURL, username)
+		if err != nil || c == nil {
+			return err
+		}
+		if password != "" && c.Password != password {
+			return nil
+		}
+		return store.Delete(ctx, c.Name)
+	}
+}
@@ -0,0 +1,60 @@
+package credhelper
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// git runs action like git does, with the credential description in.
+func git(t *testing.T, store Store, action, in string) (string, error) {
+	t.Helper()
+	var out strings.Builder
+	err := Git(context.Background(), store, action, strings.NewReader(in), &out)
+	return out.String(), err
+}
+
+func TestGit(t *testing.T) {
+	store := &fakeStore{}
+
+	out, err := git(t, store, GitGet, "protocol=https\nhost=github.com\n\n")
+	require.NoError(t, err)
+	assert.Empty(t, out, "without a login git asks the user")
+
+	_, err = git(t, store, GitStore, "protocol=https\nhost=github.com\nusername=alice\npassword=s3cret\ncapability[]=authtype\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, []Credential{{Name: "alice@github.com", URL: "https://github.com", Username: "alice", Password: "s3cret"}}, store.creds)
+
+	out, err = git(t, store, GitGet, "protocol=https\nhost=github.com\npath=acme/app.git\n")
+	require.NoError(t, err)
+	assert.Equal(t, "username=alice\npassword=s3cret\n", out)
+
+	out, err = git(t, store, GitGet, "url=https://github.com\nusername=bob\n\n")
+	require.NoError(t, err)
+	assert.Empty(t, out)
+
+	// Storing the login get returned changes nothing.
+	_, err = git(t, store, GitStore, "protocol=https\nhost=github.com\nusername=alice\npassword=s3cret\n")
+	require.NoError(t, err)
+	assert.Len(t, store.creds, 1)
+
+	// A rejected password that is no longer stored keeps the login.
+	_, err = git(t, store, GitErase, "protocol=https\nhost=github.com\nusername=alice\npassword=old\n")
+	require.NoError(t, err)
+	assert.Len(t, store.creds, 1)
+
+	_, err = git(t, store, GitErase, "protocol=https\nhost=github.com\nusername=alice\npassword=s3cret\n")
+	require.NoError(t, err)
+	assert.Empty(t, store.creds)
+
+	_, err = git(t, store, "capability", "")
+	assert.NoError(t, err, "unknown actions are ignored")
+
+	_, err = git(t, store, GitGet, "host=github.com\n")
+	assert.EqualError(t, err, "the credential has no protocol and host")
+	_, err = git(t, store, GitGet, "protocol https\n")
+	assert.EqualError(t, err, `invalid credential line "protocol https"`)
+}
@@ -1,6 +1,11 @@
 package cryptor
 
 import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -15,6 +20,9 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// testBinding is the secret the ciphertexts in these tests belong to.
+var testBinding = models.SecretBinding{Owner: "alice", Type: models.SecretTypeText, Name: "wifi"}
+
 func generateRSAKeys(t *testing.T) (*rsa.PrivateKey, *rsa.PublicKey) {
 	priv, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
@@ -67,13 +75,13 @@ func TestEncryptDecrypt_Success(t *testing.T) {
 
 	plaintext := []byte("super secret data")
 
-	enc, err := c.Encrypt(plaintext)
+	enc, err := c.Encrypt(plaintext, testBinding)
 	require.NoError(t, err)
 	require.NotNil(t, enc)
 	assert.NotEmpty(t, enc.Ciphertext)
 	assert.NotEmpty(t, enc.AESKeyEnc)
 
-	dec, err := c.Decrypt(enc)
+	dec, err := c.Decrypt(enc, testBinding)
 	require.NoError(t, err)
 	assert.Equal(t, plaintext, dec)
 }
@@ -81,7 +89,7 @@ func TestEncryptDecrypt_Success(t *testing.T) {
 func TestEncrypt_NoPublicKey(t *testing.T) {
 	c := &Cryptor{}
 
-	_, err := c.Encrypt([]byte("data"))
+	_, err := c.Encrypt([]byte("data"), testBinding)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "public key is nil")
 }
@@ -89,7 +97,7 @@ func TestEncrypt_NoPublicKey(t *testing.T) {
 func TestDecrypt_NoPrivateKey(t *testing.T) {
 	c := &Cryptor{}
 
-	_, err := c.Decrypt(&models.SecretEncrypted{}) // fixed type name
+	_, err := c.Decrypt(&models.SecretEncrypted{}, testBinding) // fixed type name
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "private key is nil")
 }
@@ -106,13 +114,13 @@ func TestDecrypt_InvalidAESKey(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	enc, err := c.Encrypt([]byte("data"))
+	enc, err := c.Encrypt([]byte("data"), testBinding)
 	require.NoError(t, err)
 
 	// Corrupt the AES key
 	enc.AESKeyEnc[0] ^= 0xFF
 
-	_, err = c.Decrypt(enc)
+	_, err = c.Decrypt(enc, testBinding)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "RSA decryption failed")
 }
@@ -130,17 +138,153 @@ func TestDecrypt_CorruptCiphertext(t *testing.T) {
 	require.NoError(t, err)
 
 	plaintext := []byte("hello world")
-	enc, err := c.Encrypt(plaintext)
+	enc, err := c.Encrypt(plaintext, testBinding)
 	require.NoError(t, err)
 
 	// Corrupt the ciphertext
-	enc.Ciphertext[5] ^= 0xFF
+	enc.Ciphertext[len(enc.Ciphertext)-1] ^= 0xFF
 
-	_, err = c.Decrypt(enc)
+	_, err = c.Decrypt(enc, testBinding)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "AES decryption failed")
 }
 
+func TestEncrypt_Envelope(t *testing.T) {
+	priv, _ := generateRSAKeys(t)
+	c, err := New(
+		WithPrivateKeyPEM(encodePrivateKeyPEM(priv)),
+		WithPublicKeyPEM(generateSelfSignedCertPEM(t, priv)),
+	)
+	require.NoError(t, err)
+
+	enc, err := c.Encrypt([]byte("hunter2"), testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, CiphertextV1, Version(enc.Ciphertext))
+
+	header, _, err := splitHeader(enc.Ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, AlgAES256GCM, header[len(ciphertextMagic)+1])
+	assert.Equal(t, enc.KeyID, string(header[len(ciphertextMagic)+3:]))
+
+	// A ciphertext moved to another secret does not decrypt.
+	for _, other := range []models.SecretBinding{
+		{Owner: "bob", Type: testBinding.Type, Name: testBinding.Name},
+		{Owner: testBinding.Owner, Type: models.SecretTypeUser, Name: testBinding.Name},
+		{Owner: testBinding.Owner, Type: testBinding.Type, Name: "vpn"},
+		{Owner: "alicetext", Type: "", Name: testBinding.Name},
+	} {
+		_, err = c.Decrypt(enc, other)
+		assert.Error(t, err, "%+v", other)
+	}
+
+	// The header is authenticated.
+	tampered := *enc
+	tampered.Ciphertext = bytes.Clone(enc.Ciphertext)
+	tampered.Ciphertext[len(ciphertextMagic)+3] ^= 0x01
+	_, err = c.Decrypt(&tampered, testBinding)
+	assert.Error(t, err)
+
+	tampered.Ciphertext = bytes.Clone(enc.Ciphertext)
+	tampered.Ciphertext[len(ciphertextMagic)] = 0x7f
+	_, err = c.Decrypt(&tampered, testBinding)
+	assert.ErrorContains(t, err, "unsupported ciphertext version")
+}
+
+func TestDecrypt_LegacyCiphertext(t *testing.T) {
+	priv, _ := generateRSAKeys(t)
+	c, err := New(
+		WithPrivateKeyPEM(encodePrivateKeyPEM(priv)),
+		WithPublicKeyPEM(generateSelfSignedCertPEM(t, priv)),
+	)
+	require.NoError(t, err)
+
+	enc, err := c.Encrypt([]byte("old secret"), testBinding)
+	require.NoError(t, err)
+	aesKey, err := c.dataKey(enc)
+	require.NoError(t, err)
+
+	// Secrets stored before the envelope hold a bare nonce and ciphertext.
+	enc.Ciphertext, err = seal(aesKey, []byte("old secret"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, CiphertextLegacy, Version(enc.Ciphertext))
+
+	dec, err := c.Decrypt(enc, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old secret"), dec)
+
+	upgraded, err := c.Upgrade(enc, testBinding)
+	require.NoError(t, err)
+	assert.True(t, upgraded)
+	assert.Equal(t, CiphertextV1, Version(enc.Ciphertext))
+
+	dec, err = c.Decrypt(enc, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old secret"), dec)
+
+	_, err = c.Decrypt(enc, models.SecretBinding{Owner: "bob", Type: testBinding.Type, Name: testBinding.Name})
+	assert.Error(t, err)
+
+	upgraded, err = c.Upgrade(enc, testBinding)
+	require.NoError(t, err)
+	assert.False(t, upgraded)
+}
+
+func TestDecrypt_Strict(t *testing.T) {
+	priv, _ := generateRSAKeys(t)
+	c, err := New(
+		WithPrivateKeyPEM(encodePrivateKeyPEM(priv)),
+		WithPublicKeyPEM(generateSelfSignedCertPEM(t, priv)),
+		WithStrict(),
+	)
+	require.NoError(t, err)
+
+	enc, err := c.Encrypt([]byte("old secret"), testBinding)
+	require.NoError(t, err)
+	aesKey, err := c.dataKey(enc)
+	require.NoError(t, err)
+
+	enc.Ciphertext, err = seal(aesKey, []byte("old secret"), nil)
+	require.NoError(t, err)
+
+	_, err = c.Decrypt(enc, testBinding)
+	assert.ErrorIs(t, err, ErrLegacyCiphertext)
+
+	// Upgrading still reads the legacy ciphertext.
+	upgraded, err := c.Upgrade(enc, testBinding)
+	require.NoError(t, err)
+	assert.True(t, upgraded)
+
+	dec, err := c.Decrypt(enc, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old secret"), dec)
+}
+
+func TestDecrypt_NoLegacyFallback(t *testing.T) {
+	priv, _ := generateRSAKeys(t)
+	c, err := New(
+		WithPrivateKeyPEM(encodePrivateKeyPEM(priv)),
+		WithPublicKeyPEM(generateSelfSignedCertPEM(t, priv)),
+	)
+	require.NoError(t, err)
+
+	enc, err := c.Encrypt([]byte("secret"), testBinding)
+	require.NoError(t, err)
+	aesKey, err := c.dataKey(enc)
+	require.NoError(t, err)
+
+	// An unbound ciphertext whose nonce forms a valid envelope header is not
+	// accepted as legacy, or it could stand in for any secret with the same data key.
+	aead, err := newGCM(aesKey)
+	require.NoError(t, err)
+	nonce := append([]byte(ciphertextMagic), CiphertextV1, AlgAES256GCM, 0)
+	nonce = append(nonce, make([]byte, aead.NonceSize()-len(nonce))...)
+	enc.Ciphertext = aead.Seal(bytes.Clone(nonce), nonce, []byte("forged"), nil)
+	assert.Equal(t, CiphertextV1, Version(enc.Ciphertext))
+
+	_, err = c.Decrypt(enc, testBinding)
+	assert.Error(t, err)
+}
+
 func TestWithPrivateKeyPEM(t *testing.T) {
 	priv, err := rsa.GenerateKey(rand.Reader, 2048)
 	require.NoError(t, err)
@@ -218,3 +362,378 @@ func TestWithPrivateKeyPEM(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyID(t *testing.T) {
+	priv, _ := generateRSAKeys(t)
+	other, _ := generateRSAKeys(t)
+
+	certPEM := generateSelfSignedCertPEM(t, priv)
+
+	encryptor, err := New(WithPublicKeyPEM(certPEM))
+	require.NoError(t, err)
+	decryptor, err := New(WithPrivateKeyPEM(encodePrivateKeyPEM(priv)))
+	require.NoError(t, err)
+	otherDecryptor, err := New(WithPrivateKeyPEM(encodePrivateKeyPEM(other)))
+	require.NoError(t, err)
+
+	pubID, err := encryptor.KeyID()
+	require.NoError(t, err)
+	privID, err := decryptor.KeyID()
+	require.NoError(t, err)
+	otherID, err := otherDecryptor.KeyID()
+	require.NoError(t, err)
+
+	assert.Equal(t, pubID, privID)
+	assert.NotEqual(t, pubID, otherID)
+	assert.Len(t, pubID, 64)
+
+	_, err = (&Cryptor{}).KeyID()
+	assert.Error(t, err)
+
+	enc, err := encryptor.Encrypt([]byte("data"), testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, pubID, enc.KeyID)
+
+	dec, err := decryptor.Decrypt(enc, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), dec)
+
+	_, err = otherDecryptor.Decrypt(enc, testBinding)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	// Secrets stored before key identifiers were recorded are still decrypted.
+	enc.KeyID = ""
+	dec, err = decryptor.Decrypt(enc, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), dec)
+}
+
+func TestEncryptDecrypt_MultipleRecipients(t *testing.T) {
+	laptop, _ := generateRSAKeys(t)
+	phone, _ := generateRSAKeys(t)
+	other, _ := generateRSAKeys(t)
+
+	laptopCert := generateSelfSignedCertPEM(t, laptop)
+	phoneCert := generateSelfSignedCertPEM(t, phone)
+
+	encryptor, err := New(
+		WithPublicKeyPEM(laptopCert),
+		WithRecipientPEM(phoneCert),
+		WithRecipientPEM(laptopCert),
+	)
+	require.NoError(t, err)
+
+	ids, err := encryptor.KeyIDs()
+	require.NoError(t, err)
+	require.Len(t, ids, 2, "repeated recipients are wrapped for once")
+
+	enc, err := encryptor.Encrypt([]byte("data"), testBinding)
+	require.NoError(t, err)
+	require.Len(t, enc.Recipients, 2)
+	assert.Equal(t, ids[0], enc.KeyID)
+	assert.Equal(t, enc.Recipients[0].AESKeyEnc, enc.AESKeyEnc)
+	assert.Equal(t, ids[1], enc.Recipients[1].KeyID)
+
+	for _, priv := range []*rsa.PrivateKey{laptop, phone} {
+		decryptor, err := New(WithPrivateKeyPEM(encodePrivateKeyPEM(priv)))
+		require.NoError(t, err)
+
+		dec, err := decryptor.Decrypt(enc, testBinding)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), dec)
+	}
+
+	otherDecryptor, err := New(WithPrivateKeyPEM(encodePrivateKeyPEM(other)))
+	require.NoError(t, err)
+	_, err = otherDecryptor.Decrypt(enc, testBinding)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	// A single recipient keeps the original format.
+	single, err := New(WithPublicKeyPEM(laptopCert))
+	require.NoError(t, err)
+	enc, err = single.Encrypt([]byte("data"), testBinding)
+	require.NoError(t, err)
+	assert.Nil(t, enc.Recipients)
+
+	_, err = New(WithRecipientPEM([]byte("not a pem")))
+	assert.Error(t, err)
+}
+
+func TestWrapForAndReseal(t *testing.T) {
+	alice, _ := generateRSAKeys(t)
+	bob, _ := generateRSAKeys(t)
+
+	aliceCert := generateSelfSignedCertPEM(t, alice)
+	bobCert := generateSelfSignedCertPEM(t, bob)
+
+	aliceCryptor, err := New(WithPublicKeyPEM(aliceCert), WithPrivateKeyPEM(encodePrivateKeyPEM(alice)))
+	require.NoError(t, err)
+	bobCryptor, err := New(WithPrivateKeyPEM(encodePrivateKeyPEM(bob)))
+	require.NoError(t, err)
+
+	enc, err := aliceCryptor.Encrypt([]byte("wifi password"), testBinding)
+	require.NoError(t, err)
+
+	recipient, err := aliceCryptor.WrapFor(enc, bobCert)
+	require.NoError(t, err)
+
+	bobID, err := CertificateKeyID(bobCert)
+	require.NoError(t, err)
+	assert.Equal(t, bobID, recipient.KeyID)
+
+	shared := &models.SecretEncrypted{Ciphertext: enc.Ciphertext, AESKeyEnc: recipient.AESKeyEnc, KeyID: recipient.KeyID}
+	dec, err := bobCryptor.Decrypt(shared, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("wifi password"), dec)
+
+	// Bob changes the content, Alice still reads it with her own wrapped key.
+	ciphertext, err := bobCryptor.Reseal(shared, testBinding, []byte("new wifi password"))
+	require.NoError(t, err)
+
+	enc.Ciphertext = ciphertext
+	dec, err = aliceCryptor.Decrypt(enc, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new wifi password"), dec)
+
+	_, err = bobCryptor.WrapFor(enc, aliceCert)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	_, err = aliceCryptor.WrapFor(enc, []byte("not a pem"))
+	assert.Error(t, err)
+
+	_, err = CertificateKeyID([]byte("not a pem"))
+	assert.Error(t, err)
+}
+
+func TestWipe(t *testing.T) {
+	priv, _ := generateRSAKeys(t)
+	c, err := New(WithPrivateKeyPEM(encodePrivateKeyPEM(priv)))
+	require.NoError(t, err)
+	k := c.PrivateKey.(*rsa.PrivateKey)
+
+	c.Wipe()
+	assert.Nil(t, c.PrivateKey)
+	for _, n := range append([]*big.Int{k.D}, k.Primes...) {
+		for _, w := range n.Bits() {
+			assert.Zero(t, w)
+		}
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	alice, _ := generateRSAKeys(t)
+	bob, _ := generateRSAKeys(t)
+	aliceCert := generateSelfSignedCertPEM(t, alice)
+	bobCert := generateSelfSignedCertPEM(t, bob)
+
+	aliceCryptor, err := New(WithPublicKeyPEM(aliceCert), WithPrivateKeyPEM(encodePrivateKeyPEM(alice)))
+	require.NoError(t, err)
+
+	enc, err := aliceCryptor.Reencrypt(nil, []byte("v1"), testBinding)
+	require.NoError(t, err)
+
+	// Editing keeps the wrapped data key.
+	edited, err := aliceCryptor.Reencrypt(enc, []byte("v2"), testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, enc.AESKeyEnc, edited.AESKeyEnc)
+	assert.Equal(t, enc.KeyID, edited.KeyID)
+	dec, err := aliceCryptor.Decrypt(edited, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("v2"), dec)
+
+	// Without the private key the data key cannot be reused.
+	encryptOnly, err := New(WithPublicKeyPEM(aliceCert))
+	require.NoError(t, err)
+	fresh, err := encryptOnly.Reencrypt(enc, []byte("v3"), testBinding)
+	require.NoError(t, err)
+	assert.NotEqual(t, enc.AESKeyEnc, fresh.AESKeyEnc)
+
+	// A new recipient needs a new data key.
+	withBob, err := New(WithPublicKeyPEM(aliceCert), WithPrivateKeyPEM(encodePrivateKeyPEM(alice)), WithRecipientPEM(bobCert))
+	require.NoError(t, err)
+	fresh, err = withBob.Reencrypt(enc, []byte("v3"), testBinding)
+	require.NoError(t, err)
+	assert.Len(t, fresh.Recipients, 2)
+	assert.NotEqual(t, enc.AESKeyEnc, fresh.AESKeyEnc)
+}
+
+// ecKeyPair holds the PEM public part, a certificate or a public key, and the PEM private key of an EC key pair.
+type ecKeyPair struct {
+	name    string
+	pubPEM  []byte
+	privPEM []byte
+	alg     byte
+}
+
+func generateECKeyPairs(t *testing.T) []ecKeyPair {
+	selfSigned := func(pub, priv any) []byte {
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "Test Cert"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	pkcs8 := func(priv any) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(priv)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(p256)
+	require.NoError(t, err)
+
+	x25519, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	x25519Pub, err := x509.MarshalPKIXPublicKey(x25519.PublicKey())
+	require.NoError(t, err)
+
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return []ecKeyPair{
+		{
+			name:    "ECDSA P-256 certificate, SEC 1 key",
+			pubPEM:  selfSigned(&p256.PublicKey, p256),
+			privPEM: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}),
+			alg:     AlgP256HKDF,
+		},
+		{
+			name:    "ECDSA P-256 certificate, PKCS#8 key",
+			pubPEM:  selfSigned(&p256.PublicKey, p256),
+			privPEM: pkcs8(p256),
+			alg:     AlgP256HKDF,
+		},
+		{
+			name:    "raw X25519 keys",
+			pubPEM:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: x25519Pub}),
+			privPEM: pkcs8(x25519),
+			alg:     AlgX25519HKDF,
+		},
+		{
+			name:    "Ed25519 certificate",
+			pubPEM:  selfSigned(edPub, edPriv),
+			privPEM: pkcs8(edPriv),
+			alg:     AlgX25519HKDF,
+		},
+	}
+}
+
+func TestEncryptDecrypt_ECKeys(t *testing.T) {
+	for _, kp := range generateECKeyPairs(t) {
+		t.Run(kp.name, func(t *testing.T) {
+			encryptor, err := New(WithPublicKeyPEM(kp.pubPEM))
+			require.NoError(t, err)
+			decryptor, err := New(WithPrivateKeyPEM(kp.privPEM))
+			require.NoError(t, err)
+
+			pubID, err := encryptor.KeyID()
+			require.NoError(t, err)
+			privID, err := decryptor.KeyID()
+			require.NoError(t, err)
+			assert.Equal(t, pubID, privID)
+
+			certID, err := CertificateKeyID(kp.pubPEM)
+			require.NoError(t, err)
+			assert.Equal(t, pubID, certID)
+
+			enc, err := encryptor.Encrypt([]byte("data"), testBinding)
+			require.NoError(t, err)
+			assert.Equal(t, pubID, enc.KeyID)
+
+			alg, ok := envelopeAlg(enc.AESKeyEnc)
+			require.True(t, ok)
+			assert.Equal(t, kp.alg, alg)
+
+			dec, err := decryptor.Decrypt(enc, testBinding)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("data"), dec)
+
+			enc.AESKeyEnc[len(enc.AESKeyEnc)-1] ^= 0xFF
+			_, err = decryptor.Decrypt(enc, testBinding)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncryptDecrypt_MixedRecipients(t *testing.T) {
+	rsaPriv, _ := generateRSAKeys(t)
+	rsaCert := generateSelfSignedCertPEM(t, rsaPriv)
+	ec := generateECKeyPairs(t)[2]
+
+	encryptor, err := New(WithPublicKeyPEM(rsaCert), WithRecipientPEM(ec.pubPEM))
+	require.NoError(t, err)
+
+	enc, err := encryptor.Encrypt([]byte("data"), testBinding)
+	require.NoError(t, err)
+	require.Len(t, enc.Recipients, 2)
+
+	// RSA keys keep the original format, EC keys are wrapped in an envelope.
+	_, ok := envelopeAlg(enc.Recipients[0].AESKeyEnc)
+	assert.False(t, ok)
+	assert.Len(t, enc.Recipients[0].AESKeyEnc, rsaPriv.Size())
+	alg, ok := envelopeAlg(enc.Recipients[1].AESKeyEnc)
+	assert.True(t, ok)
+	assert.Equal(t, AlgX25519HKDF, alg)
+
+	for _, privPEM := range [][]byte{encodePrivateKeyPEM(rsaPriv), ec.privPEM} {
+		decryptor, err := New(WithPrivateKeyPEM(privPEM))
+		require.NoError(t, err)
+
+		dec, err := decryptor.Decrypt(enc, testBinding)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), dec)
+	}
+
+	// A key wrapped with another algorithm is reported as a key mismatch.
+	ecDecryptor, err := New(WithPrivateKeyPEM(ec.privPEM))
+	require.NoError(t, err)
+	_, err = ecDecryptor.Decrypt(&models.SecretEncrypted{Ciphertext: enc.Ciphertext, AESKeyEnc: enc.Recipients[0].AESKeyEnc}, testBinding)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	rsaDecryptor, err := New(WithPrivateKeyPEM(encodePrivateKeyPEM(rsaPriv)))
+	require.NoError(t, err)
+	_, err = rsaDecryptor.Decrypt(&models.SecretEncrypted{Ciphertext: enc.Ciphertext, AESKeyEnc: enc.Recipients[1].AESKeyEnc}, testBinding)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	// Sharing works across algorithms.
+	recipient, err := rsaDecryptor.WrapFor(enc, generateECKeyPairs(t)[0].pubPEM)
+	require.NoError(t, err)
+	alg, ok = envelopeAlg(recipient.AESKeyEnc)
+	assert.True(t, ok)
+	assert.Equal(t, AlgP256HKDF, alg)
+}
+
+func TestEd25519ToX25519(t *testing.T) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pub, err := x25519FromEd25519Public(edPub)
+	require.NoError(t, err)
+	priv, err := x25519FromEd25519Private(edPriv)
+	require.NoError(t, err)
+
+	assert.Equal(t, pub.Bytes(), priv.PublicKey().Bytes())
+}
+
+func TestUnsupportedKeys(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&p384.PublicKey)
+	require.NoError(t, err)
+
+	_, err = New(WithPublicKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported ECDSA curve")
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(p384)
+	require.NoError(t, err)
+	_, err = New(WithPrivateKeyPEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported ECDSA curve")
+}
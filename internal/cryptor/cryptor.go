@@ -1,21 +1,96 @@
 package cryptor
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hkdf"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"math/big"
+	"slices"
 
 	"github.com/sbilibin2017/gophkeeper/internal/models"
 )
 
+// ErrKeyMismatch is returned by Decrypt when a secret is wrapped with a key
+// other than the configured private key.
+var ErrKeyMismatch = errors.New("secret is encrypted with another key")
+
+// ErrLegacyCiphertext is returned by a strict Cryptor for ciphertexts stored before the envelope.
+var ErrLegacyCiphertext = errors.New("secret is stored in the legacy ciphertext format, run upgrade-secrets")
+
+// Algorithm identifiers recorded in the envelope of a wrapped data key.
+//
+// A data key wrapped with RSA-OAEP is stored as is, the way every key was stored
+// before other algorithms were supported. Keys wrapped with ECDH are stored as an
+// envelope: the "GKW" magic, the algorithm identifier, the ephemeral public key,
+// and the data key sealed with AES-GCM under a key derived with HKDF-SHA256.
+// Keys wrapped with a vault key are stored as the magic, the algorithm identifier
+// and the data key sealed with AES-GCM under the vault key.
+const (
+	AlgRSAOAEP    byte = 0x01 // RSA-OAEP with SHA-256
+	AlgX25519HKDF byte = 0x02 // X25519 ECDH, HKDF-SHA256, AES-256-GCM
+	AlgP256HKDF   byte = 0x03 // P-256 ECDH, HKDF-SHA256, AES-256-GCM
+	AlgVaultKey   byte = 0x04 // AES-256-GCM under a key derived from a master password
+)
+
+// envelopeMagic prefixes every data key wrapped in an envelope.
+const envelopeMagic = "GKW"
+
+// hkdfInfo binds keys derived for wrapping to this purpose.
+const hkdfInfo = "gophkeeper data key wrap v1"
+
+// Ciphertext envelope.
+//
+// Secrets are sealed in a versioned envelope: the "GKC" magic, the format version,
+// the content algorithm, the length-prefixed identifier of the key the data key is
+// wrapped for, the nonce and the AES-GCM ciphertext. The header together with the owner,
+// type and name of the secret is authenticated as additional data, so a ciphertext
+// moved to another secret fails to decrypt. Ciphertexts stored before the envelope,
+// a bare nonce and ciphertext without additional data, are still decrypted unless the
+// Cryptor is strict.
+const (
+	CiphertextLegacy byte = 0x00 // nonce || ciphertext, no additional data
+	CiphertextV1     byte = 0x01 // envelope bound to owner, type and name
+
+	AlgAES256GCM byte = 0x01 // AES-256-GCM with a random 96-bit nonce
+)
+
+// CiphertextVersion is the envelope version Encrypt and Reseal produce.
+const CiphertextVersion = CiphertextV1
+
+// ciphertextMagic prefixes every ciphertext sealed in an envelope.
+const ciphertextMagic = "GKC"
+
+// Cryptor encrypts secrets to public keys and decrypts them with a private key.
+//
+// Public keys are *rsa.PublicKey or *ecdh.PublicKey on X25519 or P-256,
+// the private key is *rsa.PrivateKey or *ecdh.PrivateKey. ECDSA P-256 and
+// Ed25519 keys are converted to their ECDH counterparts when parsed.
+// A VaultKey derived from a master password is both the public and the private key.
 type Cryptor struct {
-	PublicKey  *rsa.PublicKey
-	PrivateKey *rsa.PrivateKey
+	PublicKey  crypto.PublicKey
+	PrivateKey crypto.PrivateKey
+	// Recipients are further public keys the data key is wrapped for,
+	// so that each of them can decrypt with its own private key.
+	Recipients []crypto.PublicKey
+
+	// strict rejects legacy ciphertexts, which are not bound to their secret.
+	strict bool
 }
 
 // Opt defines a functional option for configuring a Cryptor.
@@ -32,27 +107,94 @@ func New(opts ...Opt) (*Cryptor, error) {
 	return c, nil
 }
 
-// WithPublicKeyPEM sets the public key from PEM-encoded certificate bytes.
+// WithStrict makes Decrypt reject ciphertexts stored before the envelope with ErrLegacyCiphertext,
+// so a server cannot swap in an unbound ciphertext once every secret has been upgraded.
+func WithStrict() Opt {
+	return func(c *Cryptor) error {
+		c.strict = true
+		return nil
+	}
+}
+
+// WithPublicKeyPEM sets the public key from a PEM-encoded certificate
+// or, for raw X25519 keys that have no certificate, a PEM-encoded public key.
 func WithPublicKeyPEM(pemBytes []byte) Opt {
 	return func(c *Cryptor) error {
-		block, _ := pem.Decode(pemBytes)
-		if block == nil {
-			return fmt.Errorf("invalid public key PEM block")
-		}
-		cert, err := x509.ParseCertificate(block.Bytes)
+		pub, err := parseCertificatePEM(pemBytes)
 		if err != nil {
-			return fmt.Errorf("parse certificate failed: %w", err)
-		}
-		pub, ok := cert.PublicKey.(*rsa.PublicKey)
-		if !ok {
-			return fmt.Errorf("certificate does not contain RSA public key")
+			return err
 		}
 		c.PublicKey = pub
 		return nil
 	}
 }
 
-// WithPrivateKeyPEM sets the private key from PEM-encoded key bytes (PKCS#1 or PKCS#8).
+// WithRecipientPEM adds a recipient from a PEM-encoded certificate or public key.
+// Secrets are encrypted to the public key and to every recipient.
+func WithRecipientPEM(pemBytes []byte) Opt {
+	return func(c *Cryptor) error {
+		pub, err := parseCertificatePEM(pemBytes)
+		if err != nil {
+			return err
+		}
+		c.Recipients = append(c.Recipients, pub)
+		return nil
+	}
+}
+
+// CertificateKeyID returns the identifier of the public key of a PEM-encoded certificate or public key.
+func CertificateKeyID(certPEM []byte) (string, error) {
+	pub, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return "", err
+	}
+	return KeyID(pub)
+}
+
+// parseCertificatePEM returns the public key of a PEM-encoded certificate or public key.
+func parseCertificatePEM(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("invalid public key PEM block")
+	}
+	if block.Type == "PUBLIC KEY" {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key failed: %w", err)
+		}
+		return normalizePublicKey(pub)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate failed: %w", err)
+	}
+	return normalizePublicKey(cert.PublicKey)
+}
+
+// normalizePublicKey returns the RSA or ECDH key a data key can be wrapped for.
+func normalizePublicKey(pub any) (crypto.PublicKey, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return k, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s, expected P-256", k.Curve.Params().Name)
+		}
+		return k.ECDH()
+	case ed25519.PublicKey:
+		return x25519FromEd25519Public(k)
+	case *ecdh.PublicKey:
+		if _, err := ecdhAlg(k.Curve()); err != nil {
+			return nil, err
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// WithPrivateKeyPEM sets the private key from PEM-encoded key bytes:
+// PKCS#1 RSA, SEC 1 EC or PKCS#8 RSA, ECDSA P-256, Ed25519 and X25519 keys.
 func WithPrivateKeyPEM(pemBytes []byte) Opt {
 	return func(c *Cryptor) error {
 		block, _ := pem.Decode(pemBytes)
@@ -64,89 +206,687 @@ func WithPrivateKeyPEM(pemBytes []byte) Opt {
 			c.PrivateKey = priv
 			return nil
 		}
+		if ecPriv, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+			key, err := normalizePrivateKey(ecPriv)
+			if err != nil {
+				return err
+			}
+			c.PrivateKey = key
+			return nil
+		}
 		key, err2 := x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err2 != nil {
 			return fmt.Errorf("parse private key failed: %v, %v", err, err2)
 		}
-		rsaPriv, ok := key.(*rsa.PrivateKey)
-		if !ok {
-			return fmt.Errorf("not an RSA private key")
+		normalized, err := normalizePrivateKey(key)
+		if err != nil {
+			return err
 		}
-		c.PrivateKey = rsaPriv
+		c.PrivateKey = normalized
 		return nil
 	}
 }
 
-// Encrypt performs hybrid encryption using the RSA public key.
-func (c *Cryptor) Encrypt(plaintext []byte) (*models.SecretEncrypted, error) {
-	if c.PublicKey == nil {
+// normalizePrivateKey returns the RSA or ECDH key a data key can be unwrapped with.
+func normalizePrivateKey(priv any) (crypto.PrivateKey, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("unsupported ECDSA curve %s, expected P-256", k.Curve.Params().Name)
+		}
+		return k.ECDH()
+	case ed25519.PrivateKey:
+		return x25519FromEd25519Private(k)
+	case *ecdh.PrivateKey:
+		if _, err := ecdhAlg(k.Curve()); err != nil {
+			return nil, err
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+// publicKey returns the public key of an RSA or ECDH private key.
+func publicKey(priv crypto.PrivateKey) (crypto.PublicKey, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey, nil
+	case *ecdh.PrivateKey:
+		return k.PublicKey(), nil
+	case VaultKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+// KeyID returns the identifier of a public key: the hex-encoded SHA-256
+// of its DER SubjectPublicKeyInfo. It is the same whether computed from a certificate
+// or from the matching private key. Vault keys have no public part and are identified
+// by a hash of the key instead.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	if k, ok := pub.(VaultKey); ok {
+		return k.id(), nil
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("marshal public key failed: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// KeyID returns the identifier of the configured key pair,
+// preferring the public key when both are set.
+func (c *Cryptor) KeyID() (string, error) {
+	switch {
+	case c.PublicKey != nil:
+		return KeyID(c.PublicKey)
+	case c.PrivateKey != nil:
+		pub, err := publicKey(c.PrivateKey)
+		if err != nil {
+			return "", err
+		}
+		return KeyID(pub)
+	default:
+		return "", fmt.Errorf("no key configured")
+	}
+}
+
+// KeyIDs returns the identifiers of all keys secrets are encrypted to:
+// the public key first, then the recipients, without duplicates.
+func (c *Cryptor) KeyIDs() ([]string, error) {
+	keys, err := c.encryptionKeys()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k.id)
+	}
+	return ids, nil
+}
+
+// encryptionKey is a public key together with its identifier.
+type encryptionKey struct {
+	id  string
+	pub crypto.PublicKey
+}
+
+// encryptionKeys returns the public key and the recipients, skipping repeated keys.
+func (c *Cryptor) encryptionKeys() ([]encryptionKey, error) {
+	pubs := c.Recipients
+	if c.PublicKey != nil {
+		pubs = append([]crypto.PublicKey{c.PublicKey}, pubs...)
+	}
+	if len(pubs) == 0 {
 		return nil, fmt.Errorf("public key is nil")
 	}
+
+	keys := make([]encryptionKey, 0, len(pubs))
+	seen := make(map[string]bool, len(pubs))
+	for _, pub := range pubs {
+		id, err := KeyID(pub)
+		if err != nil {
+			return nil, err
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		keys = append(keys, encryptionKey{id: id, pub: pub})
+	}
+	return keys, nil
+}
+
+// Wipe overwrites the private key in memory and drops it, as far as its type allows: a vault
+// key is zeroed, and so are the numbers of an RSA key. X25519 and P-256 keys are only dropped,
+// crypto/ecdh keeps their bytes out of reach.
+func (c *Cryptor) Wipe() {
+	switch k := c.PrivateKey.(type) {
+	case VaultKey:
+		clear(k)
+		c.PublicKey = nil
+	case *rsa.PrivateKey:
+		for _, n := range append([]*big.Int{k.D, k.Precomputed.Dp, k.Precomputed.Dq, k.Precomputed.Qinv}, k.Primes...) {
+			if n != nil {
+				clear(n.Bits())
+			}
+		}
+	}
+	c.PrivateKey = nil
+}
+
+// Encrypt performs hybrid encryption using the public key.
+//
+// The data key is wrapped for the public key and every recipient. The first wrapped key
+// is also stored in AESKeyEnc and KeyID, so a secret with a single recipient keeps the
+// original format and older clients holding the first key can still read it.
+// The ciphertext is sealed in an envelope bound to the secret identified by binding.
+func (c *Cryptor) Encrypt(plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error) {
+	keys, err := c.encryptionKeys()
+	if err != nil {
+		return nil, err
+	}
 	aesKey := make([]byte, 32)
 	if _, err := rand.Read(aesKey); err != nil {
 		return nil, fmt.Errorf("AES key gen failed: %w", err)
 	}
 
-	block, err := aes.NewCipher(aesKey)
+	ciphertext, err := sealSecret(aesKey, keys[0].id, binding, plaintext)
 	if err != nil {
-		return nil, fmt.Errorf("AES cipher failed: %w", err)
-	}
-	aead, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("GCM failed: %w", err)
+		return nil, err
 	}
 
-	nonce := make([]byte, aead.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, fmt.Errorf("nonce gen failed: %w", err)
+	recipients := make(models.Recipients, 0, len(keys))
+	for _, k := range keys {
+		encKey, err := wrapKey(k.pub, aesKey)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, models.Recipient{KeyID: k.id, AESKeyEnc: encKey})
 	}
 
-	ciphertext := aead.Seal(nonce, nonce, plaintext, nil)
+	enc := &models.SecretEncrypted{
+		Ciphertext: ciphertext,
+		AESKeyEnc:  recipients[0].AESKeyEnc,
+		KeyID:      recipients[0].KeyID,
+	}
+	if len(recipients) > 1 {
+		enc.Recipients = recipients
+	}
+	return enc, nil
+}
 
-	label := []byte("AESKey")
-	encKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, c.PublicKey, aesKey, label)
+// Reencrypt encrypts plaintext replacing the stored secret prev. When prev is wrapped for
+// exactly the keys Encrypt would wrap for and its data key can be unwrapped, the data key is
+// reused and the wrapped keys of prev are returned unchanged, so that keys the owner wrapped
+// for the recipients of a share stay valid. Otherwise, and when prev is nil, it is Encrypt.
+func (c *Cryptor) Reencrypt(prev *models.SecretEncrypted, plaintext []byte, binding models.SecretBinding) (*models.SecretEncrypted, error) {
+	if prev == nil || prev.KeyID == "" {
+		return c.Encrypt(plaintext, binding)
+	}
+	keys, err := c.encryptionKeys()
 	if err != nil {
-		return nil, fmt.Errorf("RSA encryption failed: %w", err)
+		return nil, err
+	}
+	wrapped := []string{prev.KeyID}
+	if len(prev.Recipients) > 0 {
+		wrapped = wrapped[:0]
+		for _, r := range prev.Recipients {
+			wrapped = append(wrapped, r.KeyID)
+		}
+	}
+	if !slices.EqualFunc(keys, wrapped, func(k encryptionKey, id string) bool { return k.id == id }) {
+		return c.Encrypt(plaintext, binding)
 	}
+	aesKey, err := c.dataKey(prev)
+	if err != nil {
+		return c.Encrypt(plaintext, binding)
+	}
+	defer clear(aesKey)
 
+	ciphertext, err := sealSecret(aesKey, prev.KeyID, binding, plaintext)
+	if err != nil {
+		return nil, err
+	}
 	return &models.SecretEncrypted{
 		Ciphertext: ciphertext,
-		AESKeyEnc:  encKey,
+		AESKeyEnc:  prev.AESKeyEnc,
+		KeyID:      prev.KeyID,
+		Recipients: prev.Recipients,
 	}, nil
 }
 
-// Decrypt performs hybrid decryption using the RSA private key.
-func (c *Cryptor) Decrypt(enc *models.SecretEncrypted) ([]byte, error) {
+// Decrypt performs hybrid decryption using the private key.
+//
+// The data key is unwrapped with the algorithm recorded in its envelope. For secrets with several recipients the data key wrapped for the private key is picked.
+// Secrets not encrypted to the private key fail with ErrKeyMismatch;
+// secrets without an identifier, stored before identifiers were recorded, are tried as is.
+// Ciphertexts sealed in an envelope must have been encrypted for binding, a strict
+// Cryptor accepts no others.
+func (c *Cryptor) Decrypt(enc *models.SecretEncrypted, binding models.SecretBinding) ([]byte, error) {
+	if c.strict && Version(enc.Ciphertext) == CiphertextLegacy {
+		return nil, ErrLegacyCiphertext
+	}
+	aesKey, err := c.dataKey(enc)
+	if err != nil {
+		return nil, err
+	}
+	return openSecret(aesKey, enc.Ciphertext, binding)
+}
+
+// WrapFor unwraps the data key of enc with the private key and wraps it
+// for the public key of a PEM-encoded certificate, so that its owner can decrypt enc.
+func (c *Cryptor) WrapFor(enc *models.SecretEncrypted, certPEM []byte) (*models.Recipient, error) {
+	pub, err := parseCertificatePEM(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := KeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	aesKey, err := c.dataKey(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	encKey, err := wrapKey(pub, aesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Recipient{KeyID: keyID, AESKeyEnc: encKey}, nil
+}
+
+// Reseal encrypts plaintext with the data key of enc and returns the new ciphertext,
+// sealed in an envelope bound to binding.
+// Every key wrapped for enc stays valid, which lets the recipient of a shared secret
+// change its content without access to the other recipients' certificates.
+func (c *Cryptor) Reseal(enc *models.SecretEncrypted, binding models.SecretBinding, plaintext []byte) ([]byte, error) {
+	aesKey, err := c.dataKey(enc)
+	if err != nil {
+		return nil, err
+	}
+	return sealSecret(aesKey, enc.KeyID, binding, plaintext)
+}
+
+// Upgrade reseals the ciphertext of enc in the current envelope with the same data key,
+// so every wrapped key stays valid. It reports false and leaves enc untouched when the
+// ciphertext already uses the current envelope.
+func (c *Cryptor) Upgrade(enc *models.SecretEncrypted, binding models.SecretBinding) (bool, error) {
+	if Version(enc.Ciphertext) == CiphertextVersion {
+		return false, nil
+	}
+	aesKey, err := c.dataKey(enc)
+	if err != nil {
+		return false, err
+	}
+	plaintext, err := openSecret(aesKey, enc.Ciphertext, binding)
+	if err != nil {
+		return false, err
+	}
+	defer clear(plaintext)
+
+	ciphertext, err := sealSecret(aesKey, enc.KeyID, binding, plaintext)
+	if err != nil {
+		return false, err
+	}
+	enc.Ciphertext = ciphertext
+	return true, nil
+}
+
+// Version returns the envelope version of ciphertext, CiphertextLegacy for ciphertexts
+// stored before the envelope.
+func Version(ciphertext []byte) byte {
+	if len(ciphertext) <= len(ciphertextMagic) || !bytes.HasPrefix(ciphertext, []byte(ciphertextMagic)) {
+		return CiphertextLegacy
+	}
+	return ciphertext[len(ciphertextMagic)]
+}
+
+// dataKey unwraps the data key of enc with the private key.
+func (c *Cryptor) dataKey(enc *models.SecretEncrypted) ([]byte, error) {
 	if c.PrivateKey == nil {
 		return nil, fmt.Errorf("private key is nil")
 	}
 
-	label := []byte("AESKey")
-	aesKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, c.PrivateKey, enc.AESKeyEnc, label)
+	encKey, err := c.wrappedKey(enc)
 	if err != nil {
-		return nil, fmt.Errorf("RSA decryption failed: %w", err)
+		return nil, err
 	}
+	return unwrapKey(c.PrivateKey, encKey)
+}
 
-	block, err := aes.NewCipher(aesKey)
+// wrapKey wraps aesKey for pub: with RSA-OAEP for RSA keys,
+// in an ECDH envelope for X25519 and P-256 keys.
+func wrapKey(pub crypto.PublicKey, aesKey []byte) ([]byte, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		encKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, k, aesKey, []byte("AESKey"))
+		if err != nil {
+			return nil, fmt.Errorf("RSA encryption failed: %w", err)
+		}
+		return encKey, nil
+	case *ecdh.PublicKey:
+		alg, err := ecdhAlg(k.Curve())
+		if err != nil {
+			return nil, err
+		}
+		ephemeral, err := k.Curve().GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("ephemeral key gen failed: %w", err)
+		}
+		shared, err := ephemeral.ECDH(k)
+		if err != nil {
+			return nil, fmt.Errorf("ECDH failed: %w", err)
+		}
+		kek, err := deriveKEK(shared, ephemeral.PublicKey().Bytes(), k.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		sealed, err := seal(kek, aesKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		out := append([]byte(envelopeMagic), alg)
+		out = append(out, ephemeral.PublicKey().Bytes()...)
+		return append(out, sealed...), nil
+	case VaultKey:
+		sealed, err := seal(k, aesKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(envelopeMagic+string(AlgVaultKey)), sealed...), nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// unwrapKey unwraps a data key wrapped for the public key of priv.
+func unwrapKey(priv crypto.PrivateKey, encKey []byte) ([]byte, error) {
+	alg, isEnvelope := envelopeAlg(encKey)
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		if isEnvelope && len(encKey) != k.Size() {
+			return nil, fmt.Errorf("%w: data key is wrapped with %s, not RSA", ErrKeyMismatch, algName(alg))
+		}
+		aesKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, k, encKey, []byte("AESKey"))
+		if err != nil {
+			return nil, fmt.Errorf("RSA decryption failed: %w", err)
+		}
+		return aesKey, nil
+	case *ecdh.PrivateKey:
+		want, err := ecdhAlg(k.Curve())
+		if err != nil {
+			return nil, err
+		}
+		if !isEnvelope {
+			return nil, fmt.Errorf("%w: data key is wrapped with %s, not %s", ErrKeyMismatch, algName(AlgRSAOAEP), algName(want))
+		}
+		if alg != want {
+			return nil, fmt.Errorf("%w: data key is wrapped with %s, not %s", ErrKeyMismatch, algName(alg), algName(want))
+		}
+		body := encKey[len(envelopeMagic)+1:]
+		pubLen := len(k.PublicKey().Bytes())
+		if len(body) < pubLen {
+			return nil, fmt.Errorf("wrapped key too short")
+		}
+		ephemeral, err := k.Curve().NewPublicKey(body[:pubLen])
+		if err != nil {
+			return nil, fmt.Errorf("invalid ephemeral key: %w", err)
+		}
+		shared, err := k.ECDH(ephemeral)
+		if err != nil {
+			return nil, fmt.Errorf("ECDH failed: %w", err)
+		}
+		kek, err := deriveKEK(shared, ephemeral.Bytes(), k.PublicKey().Bytes())
+		if err != nil {
+			return nil, err
+		}
+		aesKey, err := open(kek, body[pubLen:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s decryption failed: %w", algName(alg), err)
+		}
+		return aesKey, nil
+	case VaultKey:
+		if !isEnvelope {
+			return nil, fmt.Errorf("%w: data key is wrapped with %s, not %s", ErrKeyMismatch, algName(AlgRSAOAEP), algName(AlgVaultKey))
+		}
+		if alg != AlgVaultKey {
+			return nil, fmt.Errorf("%w: data key is wrapped with %s, not %s", ErrKeyMismatch, algName(alg), algName(AlgVaultKey))
+		}
+		aesKey, err := open(k, encKey[len(envelopeMagic)+1:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s decryption failed: %w", algName(alg), err)
+		}
+		return aesKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+// deriveKEK derives the key wrapping a data key from the shared secret of an ECDH exchange.
+// The ephemeral and the recipient's public keys are mixed into the salt.
+func deriveKEK(shared, ephemeral, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	kek, err := hkdf.Key(sha256.New, shared, salt, hkdfInfo, 32)
 	if err != nil {
-		return nil, fmt.Errorf("AES cipher failed: %w", err)
+		return nil, fmt.Errorf("HKDF failed: %w", err)
 	}
-	aead, err := cipher.NewGCM(block)
+	return kek, nil
+}
+
+// ecdhAlg returns the algorithm identifier of wrapping keys for curve.
+func ecdhAlg(curve ecdh.Curve) (byte, error) {
+	switch curve {
+	case ecdh.X25519():
+		return AlgX25519HKDF, nil
+	case ecdh.P256():
+		return AlgP256HKDF, nil
+	default:
+		return 0, fmt.Errorf("unsupported ECDH curve %s", curve)
+	}
+}
+
+// envelopeAlg returns the algorithm recorded in the envelope of encKey,
+// false when encKey is not an envelope.
+func envelopeAlg(encKey []byte) (byte, bool) {
+	if len(encKey) <= len(envelopeMagic) || !bytes.HasPrefix(encKey, []byte(envelopeMagic)) {
+		return 0, false
+	}
+	alg := encKey[len(envelopeMagic)]
+	return alg, alg == AlgX25519HKDF || alg == AlgP256HKDF || alg == AlgVaultKey
+}
+
+// algName returns a human-readable name of a wrapping algorithm.
+func algName(alg byte) string {
+	switch alg {
+	case AlgRSAOAEP:
+		return "RSA-OAEP"
+	case AlgX25519HKDF:
+		return "X25519"
+	case AlgP256HKDF:
+		return "P-256"
+	case AlgVaultKey:
+		return "vault key"
+	default:
+		return fmt.Sprintf("unknown algorithm %#x", alg)
+	}
+}
+
+// sealSecret seals plaintext under aesKey in the current envelope.
+// keyID identifies the key the data key is wrapped for and is recorded in the header.
+func sealSecret(aesKey []byte, keyID string, binding models.SecretBinding, plaintext []byte) ([]byte, error) {
+	if len(keyID) > 255 {
+		return nil, fmt.Errorf("key ID too long")
+	}
+	header := append([]byte(ciphertextMagic), CiphertextV1, AlgAES256GCM, byte(len(keyID)))
+	header = append(header, keyID...)
+
+	sealed, err := seal(aesKey, plaintext, additionalData(header, binding))
 	if err != nil {
-		return nil, fmt.Errorf("GCM failed: %w", err)
+		return nil, err
+	}
+	return append(header, sealed...), nil
+}
+
+// openSecret decrypts a ciphertext produced by sealSecret for binding,
+// or a legacy nonce-prefixed ciphertext without additional data.
+//
+// A legacy ciphertext starts with a random nonce, which may look like the magic of an
+// envelope; it is only tried as legacy when the rest of the header does not parse.
+// Once the header parses, a ciphertext that fails to open is rejected, so an envelope
+// cannot be downgraded to an unbound ciphertext.
+func openSecret(aesKey, ciphertext []byte, binding models.SecretBinding) ([]byte, error) {
+	switch Version(ciphertext) {
+	case CiphertextLegacy:
+		return open(aesKey, ciphertext, nil)
+	case CiphertextV1:
+		header, sealed, err := splitHeader(ciphertext)
+		if err != nil {
+			if legacy, legacyErr := open(aesKey, ciphertext, nil); legacyErr == nil {
+				return legacy, nil
+			}
+			return nil, err
+		}
+		return open(aesKey, sealed, additionalData(header, binding))
+	default:
+		return nil, fmt.Errorf("unsupported ciphertext version %#x", Version(ciphertext))
+	}
+}
+
+// splitHeader splits a version 1 envelope into its header and the sealed content.
+func splitHeader(ciphertext []byte) (header, sealed []byte, err error) {
+	fixed := len(ciphertextMagic) + 3
+	if len(ciphertext) < fixed {
+		return nil, nil, fmt.Errorf("ciphertext too short")
+	}
+	if alg := ciphertext[len(ciphertextMagic)+1]; alg != AlgAES256GCM {
+		return nil, nil, fmt.Errorf("unsupported content algorithm %#x", alg)
+	}
+	end := fixed + int(ciphertext[fixed-1])
+	if len(ciphertext) < end {
+		return nil, nil, fmt.Errorf("ciphertext too short")
+	}
+	return ciphertext[:end], ciphertext[end:], nil
+}
+
+// additionalData returns the AES-GCM additional data of an envelope:
+// the header followed by the length-prefixed owner, type and name of the secret.
+func additionalData(header []byte, binding models.SecretBinding) []byte {
+	aad := append([]byte{}, header...)
+	for _, field := range []string{binding.Owner, binding.Type, binding.Name} {
+		aad = binary.AppendUvarint(aad, uint64(len(field)))
+		aad = append(aad, field...)
+	}
+	return aad
+}
+
+// seal encrypts plaintext with AES-GCM under aesKey, prefixing the random nonce.
+func seal(aesKey, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newGCM(aesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce gen failed: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open decrypts a nonce-prefixed AES-GCM ciphertext produced by seal.
+func open(aesKey, sealed, aad []byte) ([]byte, error) {
+	aead, err := newGCM(aesKey)
+	if err != nil {
+		return nil, err
 	}
 
 	nonceSize := aead.NonceSize()
-	if len(enc.Ciphertext) < nonceSize {
+	if len(sealed) < nonceSize {
 		return nil, fmt.Errorf("ciphertext too short")
 	}
-	nonce := enc.Ciphertext[:nonceSize]
-	ciphertext := enc.Ciphertext[nonceSize:]
+	nonce := sealed[:nonceSize]
+	ciphertext := sealed[nonceSize:]
 
-	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad)
 	if err != nil {
 		return nil, fmt.Errorf("AES decryption failed: %w", err)
 	}
 
 	return plaintext, nil
 }
+
+// newGCM returns an AES-GCM cipher for aesKey.
+func newGCM(aesKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return nil, fmt.Errorf("AES cipher failed: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("GCM failed: %w", err)
+	}
+	return aead, nil
+}
+
+// wrappedKey returns the data key of enc wrapped for the configured private key.
+func (c *Cryptor) wrappedKey(enc *models.SecretEncrypted) ([]byte, error) {
+	if len(enc.Recipients) == 0 && enc.KeyID == "" {
+		return enc.AESKeyEnc, nil
+	}
+
+	pub, err := publicKey(c.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := KeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(enc.Recipients) == 0 {
+		if keyID != enc.KeyID {
+			return nil, fmt.Errorf("%w: %s", ErrKeyMismatch, enc.KeyID)
+		}
+		return enc.AESKeyEnc, nil
+	}
+
+	for _, r := range enc.Recipients {
+		if r.KeyID == keyID {
+			return r.AESKeyEnc, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: none of %d recipients matches", ErrKeyMismatch, len(enc.Recipients))
+}
+
+// x25519FromEd25519Public converts an Ed25519 public key to the X25519 public key
+// of the same key pair with the birational map u = (1 + y) / (1 - y) of RFC 7748.
+func x25519FromEd25519Public(pub ed25519.PublicKey) (*ecdh.PublicKey, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key size %d", len(pub))
+	}
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+	le := bytes.Clone(pub)
+	le[31] &= 0x7f // drop the sign of x
+	y := new(big.Int).SetBytes(reversed(le))
+	if y.Cmp(p) >= 0 {
+		return nil, fmt.Errorf("invalid Ed25519 public key")
+	}
+
+	one := big.NewInt(1)
+	num := new(big.Int).Add(one, y)
+	den := new(big.Int).Mod(new(big.Int).Sub(one, y), p)
+	if den.Sign() == 0 {
+		return nil, fmt.Errorf("invalid Ed25519 public key")
+	}
+	u := num.Mul(num, den.ModInverse(den, p))
+	u.Mod(u, p)
+
+	return ecdh.X25519().NewPublicKey(reversed(u.FillBytes(make([]byte, 32))))
+}
+
+// x25519FromEd25519Private converts an Ed25519 private key to the X25519 private key
+// of the same key pair: the first half of the SHA-512 of the seed, clamped by X25519.
+func x25519FromEd25519Private(priv ed25519.PrivateKey) (*ecdh.PrivateKey, error) {
+	h := sha512.Sum512(priv.Seed())
+	return ecdh.X25519().NewPrivateKey(h[:32])
+}
+
+// reversed returns b in reverse order, converting between little- and big-endian.
+func reversed(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i, v := range b {
+		out[len(b)-1-i] = v
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package cryptor
+
+import (
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// ErrInvalidKDFParams is returned for KDF parameters outside the accepted bounds.
+var ErrInvalidKDFParams = errors.New("invalid KDF parameters")
+
+// Default Argon2id parameters of new master passwords, the first recommended option of RFC 9106
+// scaled down to 64 MiB.
+const (
+	DefaultKDFTime    uint32 = 3
+	DefaultKDFMemory  uint32 = 64 * 1024
+	DefaultKDFThreads uint8  = 4
+)
+
+// Bounds of accepted KDF parameters. The lower bounds keep guessing expensive,
+// the upper bounds stop a server from making clients exhaust their memory.
+const (
+	kdfSaltSize   = 16
+	kdfMinMemory  = 19 * 1024
+	kdfMaxMemory  = 2 * 1024 * 1024
+	kdfMaxTime    = 16
+	kdfMaxThreads = 64
+)
+
+// Info strings separating the keys derived from the master key.
+const (
+	vaultKeyInfo   = "gophkeeper vault key v1"
+	authKeyInfo    = "gophkeeper auth key v1"
+	vaultKeyIDInfo = "gophkeeper vault key id v1"
+)
+
+// VaultKey is a 256-bit key derived from a master password.
+// Data keys are wrapped with AES-GCM under it, it never leaves the client.
+type VaultKey []byte
+
+// id returns the identifier of the vault key, derived so that it reveals nothing about the key.
+func (k VaultKey) id() string {
+	id, _ := hkdf.Key(sha256.New, k, nil, vaultKeyIDInfo, 32)
+	return hex.EncodeToString(id)
+}
+
+// WithVaultKey makes the Cryptor encrypt to and decrypt with a vault key.
+// Recipient certificates can still be added with WithRecipientPEM.
+func WithVaultKey(key VaultKey) Opt {
+	return func(c *Cryptor) error {
+		if len(key) != 32 {
+			return fmt.Errorf("vault key must be 32 bytes, got %d", len(key))
+		}
+		c.PublicKey = key
+		c.PrivateKey = key
+		return nil
+	}
+}
+
+// MasterKeys are the keys derived from a master password.
+type MasterKeys struct {
+	// VaultKey wraps the data keys of secrets.
+	VaultKey VaultKey
+	// AuthKey is sent to the server in place of the password. The server stores only
+	// its hash, which verifies logins but cannot be used to derive the vault key.
+	AuthKey string
+}
+
+// NewKDFParams returns Argon2id parameters with the default costs and a random salt.
+func NewKDFParams() (*models.KDFParams, error) {
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("salt gen failed: %w", err)
+	}
+	return &models.KDFParams{
+		Algorithm: models.KDFAlgorithmArgon2id,
+		Salt:      salt,
+		Time:      DefaultKDFTime,
+		Memory:    DefaultKDFMemory,
+		Threads:   DefaultKDFThreads,
+	}, nil
+}
+
+// ValidateKDFParams checks that params name Argon2id with costs within the accepted bounds.
+func ValidateKDFParams(params models.KDFParams) error {
+	switch {
+	case params.Algorithm != models.KDFAlgorithmArgon2id:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidKDFParams, params.Algorithm)
+	case len(params.Salt) < kdfSaltSize:
+		return fmt.Errorf("%w: salt must be at least %d bytes", ErrInvalidKDFParams, kdfSaltSize)
+	case params.Time < 1 || params.Time > kdfMaxTime:
+		return fmt.Errorf("%w: time must be between 1 and %d", ErrInvalidKDFParams, kdfMaxTime)
+	case params.Memory < kdfMinMemory || params.Memory > kdfMaxMemory:
+		return fmt.Errorf("%w: memory must be between %d and %d KiB", ErrInvalidKDFParams, kdfMinMemory, kdfMaxMemory)
+	case params.Threads < 1 || params.Threads > kdfMaxThreads:
+		return fmt.Errorf("%w: threads must be between 1 and %d", ErrInvalidKDFParams, kdfMaxThreads)
+	}
+	return nil
+}
+
+// DeriveMasterKeys stretches password with Argon2id and derives the vault key
+// and the authentication key from the result with HKDF-SHA256.
+func DeriveMasterKeys(password string, params models.KDFParams) (*MasterKeys, error) {
+	if password == "" {
+		return nil, errors.New("master password is empty")
+	}
+	if err := ValidateKDFParams(params); err != nil {
+		return nil, err
+	}
+
+	master := argon2.IDKey([]byte(password), params.Salt, params.Time, params.Memory, params.Threads, 32)
+	defer clear(master)
+
+	vaultKey, err := hkdf.Key(sha256.New, master, nil, vaultKeyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("HKDF failed: %w", err)
+	}
+	authKey, err := hkdf.Key(sha256.New, master, nil, authKeyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("HKDF failed: %w", err)
+	}
+
+	return &MasterKeys{VaultKey: vaultKey, AuthKey: hex.EncodeToString(authKey)}, nil
+}
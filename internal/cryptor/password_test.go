@@ -0,0 +1,120 @@
+package cryptor
+
+import (
+	"testing"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKDFParams are the cheapest accepted parameters, to keep the tests fast.
+func testKDFParams(t *testing.T) models.KDFParams {
+	t.Helper()
+	params, err := NewKDFParams()
+	require.NoError(t, err)
+	params.Time = 1
+	params.Memory = kdfMinMemory
+	params.Threads = 1
+	return *params
+}
+
+func TestDeriveMasterKeys(t *testing.T) {
+	params := testKDFParams(t)
+
+	keys, err := DeriveMasterKeys("correct horse", params)
+	require.NoError(t, err)
+	require.Len(t, keys.VaultKey, 32)
+	require.Len(t, keys.AuthKey, 64)
+
+	again, err := DeriveMasterKeys("correct horse", params)
+	require.NoError(t, err)
+	assert.Equal(t, keys, again)
+
+	other, err := DeriveMasterKeys("battery staple", params)
+	require.NoError(t, err)
+	assert.NotEqual(t, keys.VaultKey, other.VaultKey)
+
+	params2 := testKDFParams(t)
+	salted, err := DeriveMasterKeys("correct horse", params2)
+	require.NoError(t, err)
+	assert.NotEqual(t, keys.VaultKey, salted.VaultKey)
+
+	_, err = DeriveMasterKeys("", params)
+	assert.Error(t, err)
+}
+
+func TestValidateKDFParams(t *testing.T) {
+	valid := testKDFParams(t)
+	require.NoError(t, ValidateKDFParams(valid))
+
+	defaults, err := NewKDFParams()
+	require.NoError(t, err)
+	require.NoError(t, ValidateKDFParams(*defaults))
+
+	for name, mutate := range map[string]func(*models.KDFParams){
+		"algorithm":    func(p *models.KDFParams) { p.Algorithm = "scrypt" },
+		"short salt":   func(p *models.KDFParams) { p.Salt = p.Salt[:8] },
+		"no time":      func(p *models.KDFParams) { p.Time = 0 },
+		"low memory":   func(p *models.KDFParams) { p.Memory = 1024 },
+		"huge memory":  func(p *models.KDFParams) { p.Memory = 64 * 1024 * 1024 },
+		"no threads":   func(p *models.KDFParams) { p.Threads = 0 },
+		"many threads": func(p *models.KDFParams) { p.Threads = 255 },
+	} {
+		p := valid
+		p.Salt = append([]byte{}, valid.Salt...)
+		mutate(&p)
+		assert.ErrorIs(t, ValidateKDFParams(p), ErrInvalidKDFParams, name)
+	}
+}
+
+func TestEncryptDecrypt_VaultKey(t *testing.T) {
+	keys, err := DeriveMasterKeys("correct horse", testKDFParams(t))
+	require.NoError(t, err)
+
+	c, err := New(WithVaultKey(keys.VaultKey))
+	require.NoError(t, err)
+
+	enc, err := c.Encrypt([]byte("hunter2"), testBinding)
+	require.NoError(t, err)
+
+	id, err := c.KeyID()
+	require.NoError(t, err)
+	assert.Equal(t, id, enc.KeyID)
+
+	dec, err := c.Decrypt(enc, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hunter2"), dec)
+
+	other, err := DeriveMasterKeys("wrong password", testKDFParams(t))
+	require.NoError(t, err)
+	wrong, err := New(WithVaultKey(other.VaultKey))
+	require.NoError(t, err)
+	_, err = wrong.Decrypt(enc, testBinding)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	// A secret encrypted to an RSA key is not readable with a vault key and vice versa.
+	priv, _ := generateRSAKeys(t)
+	rsaCryptor, err := New(
+		WithPrivateKeyPEM(encodePrivateKeyPEM(priv)),
+		WithPublicKeyPEM(generateSelfSignedCertPEM(t, priv)),
+	)
+	require.NoError(t, err)
+	_, err = rsaCryptor.Decrypt(&models.SecretEncrypted{Ciphertext: enc.Ciphertext, AESKeyEnc: enc.AESKeyEnc}, testBinding)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	rsaEnc, err := rsaCryptor.Encrypt([]byte("data"), testBinding)
+	require.NoError(t, err)
+	_, err = c.Decrypt(&models.SecretEncrypted{Ciphertext: rsaEnc.Ciphertext, AESKeyEnc: rsaEnc.AESKeyEnc}, testBinding)
+	assert.ErrorIs(t, err, ErrKeyMismatch)
+
+	// Secrets of a master-password vault can be shared with certificate holders.
+	shared, err := c.WrapFor(enc, generateSelfSignedCertPEM(t, priv))
+	require.NoError(t, err)
+	dec, err = rsaCryptor.Decrypt(&models.SecretEncrypted{Ciphertext: enc.Ciphertext, AESKeyEnc: shared.AESKeyEnc, KeyID: shared.KeyID}, testBinding)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hunter2"), dec)
+
+	_, err = New(WithVaultKey([]byte("short")))
+	assert.Error(t, err)
+}
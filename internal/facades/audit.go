@@ -0,0 +1,84 @@
+package facades
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+)
+
+// AuditHTTPFacade reads the audit log over HTTP.
+type AuditHTTPFacade struct {
+	client *resty.Client
+}
+
+// NewAuditHTTPFacade creates a new AuditHTTPFacade with the given Resty client.
+func NewAuditHTTPFacade(client *resty.Client) *AuditHTTPFacade {
+	return &AuditHTTPFacade{client: client}
+}
+
+// List returns at most limit newest audit events of the token owner.
+// A zero limit lets the server pick its default.
+func (a *AuditHTTPFacade) List(ctx context.Context, token string, limit int) ([]*models.AuditEvent, error) {
+	var events []*models.AuditEvent
+
+	req := a.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetResult(&events)
+	if limit > 0 {
+		req.SetQueryParam("limit", strconv.Itoa(limit))
+	}
+
+	resp, err := req.Get("/audit")
+	if err != nil {
+		return nil, fmt.Errorf("audit request failed: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("audit request returned error: %s", resp.Status())
+	}
+
+	return events, nil
+}
+
+// AuditGRPCFacade reads the audit log over gRPC.
+type AuditGRPCFacade struct {
+	client pb.AuditServiceClient
+}
+
+// NewAuditGRPCFacade creates a new AuditGRPCFacade with the given gRPC client connection.
+func NewAuditGRPCFacade(conn *grpc.ClientConn) *AuditGRPCFacade {
+	return &AuditGRPCFacade{client: pb.NewAuditServiceClient(conn)}
+}
+
+// List returns at most limit newest audit events of the token owner.
+// A zero limit lets the server pick its default.
+func (a *AuditGRPCFacade) List(ctx context.Context, token string, limit int) ([]*models.AuditEvent, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	resp, err := a.client.List(ctx, &pb.AuditListRequest{Limit: int32(limit)})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.AuditEvent, 0, len(resp.GetEvents()))
+	for _, ev := range resp.GetEvents() {
+		events = append(events, &models.AuditEvent{
+			ID:        ev.GetId(),
+			Username:  ev.GetUsername(),
+			Action:    ev.GetAction(),
+			SecretKey: ev.GetSecretKey(),
+			Transport: ev.GetTransport(),
+			ClientIP:  ev.GetClientIp(),
+			CreatedAt: ev.GetCreatedAt().AsTime(),
+		})
+	}
+
+	return events, nil
+}
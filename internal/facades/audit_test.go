@@ -0,0 +1,95 @@
+package facades
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+)
+
+func TestAuditHTTPFacade_List(t *testing.T) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/audit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":1,"username":"alice","action":"secret.get","secret_key":"user/mail","transport":"http","client_ip":"192.0.2.1","created_at":"2025-08-01T12:00:00Z"}]`))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	facade := NewAuditHTTPFacade(newRestyClientWithBaseURL(server.URL))
+
+	events, err := facade.List(context.Background(), "token", 5)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "secret.get", events[0].Action)
+	assert.Equal(t, "user/mail", events[0].SecretKey)
+	assert.Equal(t, time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC), events[0].CreatedAt)
+
+	_, err = facade.List(context.Background(), "bad", 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "audit request returned error")
+}
+
+type mockAuditServiceServer struct {
+	pb.UnimplementedAuditServiceServer
+}
+
+func (m *mockAuditServiceServer) List(ctx context.Context, req *pb.AuditListRequest) (*pb.AuditListResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if got := md.Get("authorization"); len(got) == 0 || got[0] != "Bearer token" {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return &pb.AuditListResponse{Events: []*pb.AuditEvent{{
+		Id:        int64(req.Limit),
+		Username:  "alice",
+		Action:    "auth.login",
+		Transport: "grpc",
+		ClientIp:  "192.0.2.1",
+		CreatedAt: timestamppb.New(time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)),
+	}}}, nil
+}
+
+func TestAuditGRPCFacade_List(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterAuditServiceServer(grpcServer, &mockAuditServiceServer{})
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	facade := NewAuditGRPCFacade(conn)
+
+	events, err := facade.List(context.Background(), "token", 3)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, int64(3), events[0].ID)
+	assert.Equal(t, "auth.login", events[0].Action)
+	assert.Equal(t, "192.0.2.1", events[0].ClientIP)
+
+	_, err = facade.List(context.Background(), "bad", 3)
+	require.Error(t, err)
+}
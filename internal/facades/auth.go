@@ -3,12 +3,22 @@ package facades
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// otpRequiredMessage is the server message signalling that login needs a one-time code.
+const otpRequiredMessage = "one-time code required"
+
 // AuthHTTPFacade provides HTTP-based authentication methods.
 type AuthHTTPFacade struct {
 	client *resty.Client
@@ -21,17 +31,21 @@ func NewAuthHTTPFacade(client *resty.Client) *AuthHTTPFacade {
 
 // Register sends a registration request over HTTP with username and password,
 // and returns an authentication token or an error.
+// kdfParams is set only when registering in master-password mode.
 func (a *AuthHTTPFacade) Register(
 	ctx context.Context,
 	username string,
 	password string,
+	kdfParams *models.KDFParams,
 ) (*string, error) {
 	req := struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
+		Username string            `json:"username"`
+		Password string            `json:"password"`
+		KDF      *models.KDFParams `json:"kdf,omitempty"`
 	}{
 		Username: username,
 		Password: password,
+		KDF:      kdfParams,
 	}
 
 	resp, err := a.client.R().
@@ -60,19 +74,41 @@ func (a *AuthHTTPFacade) Register(
 	return &token, nil
 }
 
-// Login sends a login request over HTTP with username and password,
+// GetKDFParams fetches the master-password KDF parameters of username over HTTP.
+func (a *AuthHTTPFacade) GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error) {
+	var params models.KDFParams
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetPathParam("username", username).
+		SetResult(&params).
+		Get("/users/{username}/kdf")
+	if err != nil {
+		return nil, fmt.Errorf("get KDF parameters request failed: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("get KDF parameters request returned error: %s", resp.Status())
+	}
+	return &params, nil
+}
+
+// Login sends a login request over HTTP with username, password and optional one-time code,
 // and returns an authentication token or an error.
+// models.ErrOTPRequired is returned when the account needs a one-time code that was not supplied.
 func (a *AuthHTTPFacade) Login(
 	ctx context.Context,
 	username string,
 	password string,
+	otpCode string,
 ) (*string, error) {
 	req := struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
+		OTPCode  string `json:"otp_code,omitempty"`
 	}{
 		Username: username,
 		Password: password,
+		OTPCode:  otpCode,
 	}
 
 	resp, err := a.client.R().
@@ -82,6 +118,10 @@ func (a *AuthHTTPFacade) Login(
 	if err != nil {
 		return nil, fmt.Errorf("login request failed: %w", err)
 	}
+	if resp.StatusCode() == http.StatusUnauthorized &&
+		strings.TrimSpace(resp.String()) == otpRequiredMessage {
+		return nil, models.ErrOTPRequired
+	}
 	if resp.IsError() {
 		return nil, fmt.Errorf("login request returned error: %s", resp.Status())
 	}
@@ -100,6 +140,110 @@ func (a *AuthHTTPFacade) Login(
 	return &token, nil
 }
 
+// EnrollOTP starts two-factor authentication enrolment over HTTP
+// and returns the otpauth URI and recovery codes.
+func (a *AuthHTTPFacade) EnrollOTP(ctx context.Context, token string) (*models.OTPEnrollment, error) {
+	var enrollment models.OTPEnrollment
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetResult(&enrollment).
+		Post("/otp/enroll")
+	if err != nil {
+		return nil, fmt.Errorf("otp enroll request failed: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("otp enroll request returned error: %s", resp.Status())
+	}
+
+	return &enrollment, nil
+}
+
+// ConfirmOTP enables two-factor authentication over HTTP with a code from the authenticator app.
+func (a *AuthHTTPFacade) ConfirmOTP(ctx context.Context, token string, code string) error {
+	req := struct {
+		Code string `json:"code"`
+	}{
+		Code: code,
+	}
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetBody(req).
+		Post("/otp/confirm")
+	if err != nil {
+		return fmt.Errorf("otp confirm request failed: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("otp confirm request returned error: %s", resp.Status())
+	}
+
+	return nil
+}
+
+// ChangePassword changes the password over HTTP and returns the new authentication token.
+// The token passed in is revoked by the server.
+func (a *AuthHTTPFacade) ChangePassword(
+	ctx context.Context,
+	token string,
+	oldPassword string,
+	newPassword string,
+) (*string, error) {
+	req := struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetBody(req).
+		Post("/password")
+	if err != nil {
+		return nil, fmt.Errorf("change password request failed: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("change password request returned error: %s", resp.Status())
+	}
+
+	const bearerPrefix = "Bearer "
+	authHeader := resp.Header().Get("Authorization")
+	if !strings.HasPrefix(authHeader, bearerPrefix) || len(authHeader) == len(bearerPrefix) {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	newToken := authHeader[len(bearerPrefix):]
+	return &newToken, nil
+}
+
+// DeleteAccount deletes the account and all its secrets over HTTP.
+func (a *AuthHTTPFacade) DeleteAccount(ctx context.Context, token string, password string) error {
+	req := struct {
+		Password string `json:"password"`
+	}{
+		Password: password,
+	}
+
+	resp, err := a.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetBody(req).
+		Delete("/account")
+	if err != nil {
+		return fmt.Errorf("delete account request failed: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("delete account request returned error: %s", resp.Status())
+	}
+
+	return nil
+}
+
 // AuthGRPCFacade provides gRPC-based authentication methods.
 type AuthGRPCFacade struct {
 	client pb.AuthServiceClient
@@ -114,34 +258,124 @@ func NewAuthGRPCFacade(conn *grpc.ClientConn) *AuthGRPCFacade {
 
 // Register sends a registration request over gRPC with username and password,
 // and returns an authentication token or an error.
+// kdfParams is set only when registering in master-password mode.
 func (a *AuthGRPCFacade) Register(
 	ctx context.Context,
 	username string,
 	password string,
+	kdfParams *models.KDFParams,
 ) (*string, error) {
-	resp, err := a.client.Register(ctx, &pb.AuthRequest{
+	req := &pb.AuthRequest{
 		Username: username,
 		Password: password,
-	})
+	}
+	if kdfParams != nil {
+		req.Kdf = &pb.KDFParams{
+			Algorithm: kdfParams.Algorithm,
+			Salt:      kdfParams.Salt,
+			Time:      kdfParams.Time,
+			Memory:    kdfParams.Memory,
+			Threads:   uint32(kdfParams.Threads),
+		}
+	}
+
+	resp, err := a.client.Register(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	return &resp.Token, nil
 }
 
-// Login sends a login request over gRPC with username and password,
+// GetKDFParams fetches the master-password KDF parameters of username over gRPC.
+func (a *AuthGRPCFacade) GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error) {
+	resp, err := a.client.GetKDFParams(ctx, &pb.KDFParamsRequest{Username: username})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetThreads() > 255 {
+		return nil, fmt.Errorf("invalid KDF threads %d", resp.GetThreads())
+	}
+	return &models.KDFParams{
+		Algorithm: resp.GetAlgorithm(),
+		Salt:      resp.GetSalt(),
+		Time:      resp.GetTime(),
+		Memory:    resp.GetMemory(),
+		Threads:   uint8(resp.GetThreads()),
+	}, nil
+}
+
+// Login sends a login request over gRPC with username, password and optional one-time code,
 // and returns an authentication token or an error.
+// models.ErrOTPRequired is returned when the account needs a one-time code that was not supplied.
 func (a *AuthGRPCFacade) Login(
 	ctx context.Context,
 	username string,
 	password string,
+	otpCode string,
 ) (*string, error) {
 	resp, err := a.client.Login(ctx, &pb.AuthRequest{
 		Username: username,
 		Password: password,
+		OtpCode:  otpCode,
+	})
+	if err != nil {
+		if st, ok := status.FromError(err); ok &&
+			st.Code() == codes.Unauthenticated && st.Message() == otpRequiredMessage {
+			return nil, models.ErrOTPRequired
+		}
+		return nil, err
+	}
+	return &resp.Token, nil
+}
+
+// EnrollOTP starts two-factor authentication enrolment over gRPC
+// and returns the otpauth URI and recovery codes.
+func (a *AuthGRPCFacade) EnrollOTP(ctx context.Context, token string) (*models.OTPEnrollment, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	resp, err := a.client.EnrollOTP(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OTPEnrollment{
+		URI:           resp.GetUri(),
+		RecoveryCodes: resp.GetRecoveryCodes(),
+	}, nil
+}
+
+// ConfirmOTP enables two-factor authentication over gRPC with a code from the authenticator app.
+func (a *AuthGRPCFacade) ConfirmOTP(ctx context.Context, token string, code string) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	_, err := a.client.ConfirmOTP(ctx, &pb.OTPConfirmRequest{Code: code})
+	return err
+}
+
+// ChangePassword changes the password over gRPC and returns the new authentication token.
+// The token passed in is revoked by the server.
+func (a *AuthGRPCFacade) ChangePassword(
+	ctx context.Context,
+	token string,
+	oldPassword string,
+	newPassword string,
+) (*string, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	resp, err := a.client.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
 	})
 	if err != nil {
 		return nil, err
 	}
 	return &resp.Token, nil
 }
+
+// DeleteAccount deletes the account and all its secrets over gRPC.
+func (a *AuthGRPCFacade) DeleteAccount(ctx context.Context, token string, password string) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+	_, err := a.client.DeleteAccount(ctx, &pb.DeleteAccountRequest{Password: password})
+	return err
+}
@@ -12,7 +12,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
 )
 
@@ -52,13 +57,13 @@ func TestAuthHTTPFacade_RegisterAndLogin(t *testing.T) {
 	ctx := context.Background()
 
 	// Test Register
-	registerResp, err := client.Register(ctx, "user1", "pass")
+	registerResp, err := client.Register(ctx, "user1", "pass", nil)
 	require.NoError(t, err)
 	require.NotNil(t, registerResp)
 	assert.Equal(t, "register-token-for-user1", *registerResp)
 
 	// Test Login
-	loginResp, err := client.Login(ctx, "user1", "pass")
+	loginResp, err := client.Login(ctx, "user1", "pass", "")
 	require.NoError(t, err)
 	require.NotNil(t, loginResp)
 	assert.Equal(t, "login-token-for-user1", *loginResp)
@@ -82,10 +87,53 @@ func (m *mockAuthServiceServer) Register(ctx context.Context, req *pb.AuthReques
 	return &pb.AuthResponse{Token: "register-token-for-" + req.Username}, nil
 }
 
+func (m *mockAuthServiceServer) GetKDFParams(ctx context.Context, req *pb.KDFParamsRequest) (*pb.KDFParams, error) {
+	if req.Username != "user1" {
+		return nil, status.Error(codes.NotFound, "master password is not set up for this user")
+	}
+	return &pb.KDFParams{Algorithm: "argon2id", Salt: []byte("0123456789abcdef"), Time: 3, Memory: 65536, Threads: 4}, nil
+}
+
 func (m *mockAuthServiceServer) Login(ctx context.Context, req *pb.AuthRequest) (*pb.AuthResponse, error) {
+	if req.Username == "otp-user" && req.OtpCode == "" {
+		return nil, status.Error(codes.Unauthenticated, "one-time code required")
+	}
 	return &pb.AuthResponse{Token: "login-token-for-" + req.Username}, nil
 }
 
+func (m *mockAuthServiceServer) EnrollOTP(ctx context.Context, _ *emptypb.Empty) (*pb.OTPEnrollResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if got := md.Get("authorization"); len(got) == 0 || got[0] != "Bearer token" {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return &pb.OTPEnrollResponse{Uri: "otpauth://totp/test", RecoveryCodes: []string{"aaaaa-bbbbb"}}, nil
+}
+
+func (m *mockAuthServiceServer) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.AuthResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if got := md.Get("authorization"); len(got) == 0 || got[0] != "Bearer token" {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	if req.OldPassword != "old" {
+		return nil, status.Error(codes.Unauthenticated, "invalid password")
+	}
+	return &pb.AuthResponse{Token: "new-token"}, nil
+}
+
+func (m *mockAuthServiceServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*emptypb.Empty, error) {
+	if req.Password != "pass" {
+		return nil, status.Error(codes.Unauthenticated, "invalid password")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *mockAuthServiceServer) ConfirmOTP(ctx context.Context, req *pb.OTPConfirmRequest) (*emptypb.Empty, error) {
+	if req.Code != "123456" {
+		return nil, status.Error(codes.InvalidArgument, "invalid one-time code")
+	}
+	return &emptypb.Empty{}, nil
+}
+
 func TestAuthGRPCFacade_RegisterAndLogin(t *testing.T) {
 	lis, err := net.Listen("tcp", "127.0.0.1:0") // choose random available port
 	require.NoError(t, err)
@@ -104,18 +152,82 @@ func TestAuthGRPCFacade_RegisterAndLogin(t *testing.T) {
 	ctx := context.Background()
 
 	// Test Register
-	registerResp, err := client.Register(ctx, "user1", "pass")
+	registerResp, err := client.Register(ctx, "user1", "pass", nil)
 	require.NoError(t, err)
 	require.NotNil(t, registerResp)
 	assert.Equal(t, "register-token-for-user1", *registerResp)
 
 	// Test Login
-	loginResp, err := client.Login(ctx, "user1", "pass")
+	loginResp, err := client.Login(ctx, "user1", "pass", "")
 	require.NoError(t, err)
 	require.NotNil(t, loginResp)
 	assert.Equal(t, "login-token-for-user1", *loginResp)
 }
 
+func TestAuthHTTPFacade_KDFParams(t *testing.T) {
+	want := &models.KDFParams{Algorithm: "argon2id", Salt: []byte("0123456789abcdef"), Time: 3, Memory: 65536, Threads: 4}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			KDF *models.KDFParams `json:"kdf"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, want, req.KDF)
+		w.Header().Set("Authorization", "Bearer token")
+	})
+	handler.HandleFunc("/users/user1/kdf", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(want))
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	facade := NewAuthHTTPFacade(newRestyClientWithBaseURL(server.URL))
+	ctx := context.Background()
+
+	_, err := facade.Register(ctx, "user1", "auth-key", want)
+	require.NoError(t, err)
+
+	got, err := facade.GetKDFParams(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	_, err = facade.GetKDFParams(ctx, "user2")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "get KDF parameters request returned error")
+}
+
+func TestAuthGRPCFacade_KDFParams(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterAuthServiceServer(grpcServer, &mockAuthServiceServer{})
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	facade := NewAuthGRPCFacade(conn)
+	ctx := context.Background()
+
+	got, err := facade.GetKDFParams(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, &models.KDFParams{Algorithm: "argon2id", Salt: []byte("0123456789abcdef"), Time: 3, Memory: 65536, Threads: 4}, got)
+
+	_, err = facade.Register(ctx, "user1", "auth-key", got)
+	require.NoError(t, err)
+
+	_, err = facade.GetKDFParams(ctx, "user2")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func TestAuthHTTPFacade_ErrorCases(t *testing.T) {
 	// HTTP 500 error simulation
 	handler := http.NewServeMux()
@@ -133,22 +245,185 @@ func TestAuthHTTPFacade_ErrorCases(t *testing.T) {
 	ctx := context.Background()
 
 	// Register HTTP error
-	_, err := client.Register(ctx, "user1", "pass")
+	_, err := client.Register(ctx, "user1", "pass", nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "register request returned error")
 
 	// Login HTTP error
-	_, err = client.Login(ctx, "user1", "pass")
+	_, err = client.Login(ctx, "user1", "pass", "")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "login request returned error")
 
 	// Network error (invalid URL)
 	badClient := NewAuthHTTPFacade(newRestyClientWithBaseURL("http://invalid.localhost"))
-	_, err = badClient.Register(ctx, "user1", "pass")
+	_, err = badClient.Register(ctx, "user1", "pass", nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "register request failed")
 
-	_, err = badClient.Login(ctx, "user1", "pass")
+	_, err = badClient.Login(ctx, "user1", "pass", "")
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "login request failed")
 }
+
+func TestAuthHTTPFacade_OTP(t *testing.T) {
+	handler := http.NewServeMux()
+
+	handler.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Username string `json:"username"`
+			OTPCode  string `json:"otp_code"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if req.OTPCode == "" {
+			http.Error(w, "one-time code required", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Authorization", "Bearer login-token-for-"+req.Username)
+	})
+
+	handler.HandleFunc("/otp/enroll", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"uri":"otpauth://totp/test","recovery_codes":["aaaaa-bbbbb"]}`))
+	})
+
+	handler.HandleFunc("/otp/confirm", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Code string `json:"code"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Code != "123456" {
+			http.Error(w, "invalid one-time code", http.StatusBadRequest)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	facade := NewAuthHTTPFacade(newRestyClientWithBaseURL(server.URL))
+	ctx := context.Background()
+
+	_, err := facade.Login(ctx, "user1", "pass", "")
+	require.ErrorIs(t, err, models.ErrOTPRequired)
+
+	tk, err := facade.Login(ctx, "user1", "pass", "123456")
+	require.NoError(t, err)
+	assert.Equal(t, "login-token-for-user1", *tk)
+
+	enrollment, err := facade.EnrollOTP(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/test", enrollment.URI)
+	assert.Equal(t, []string{"aaaaa-bbbbb"}, enrollment.RecoveryCodes)
+
+	require.NoError(t, facade.ConfirmOTP(ctx, "token", "123456"))
+	require.Error(t, facade.ConfirmOTP(ctx, "token", "000000"))
+}
+
+func TestAuthGRPCFacade_OTP(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterAuthServiceServer(grpcServer, &mockAuthServiceServer{})
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	facade := NewAuthGRPCFacade(conn)
+	ctx := context.Background()
+
+	_, err = facade.Login(ctx, "otp-user", "pass", "")
+	require.ErrorIs(t, err, models.ErrOTPRequired)
+
+	tk, err := facade.Login(ctx, "otp-user", "pass", "123456")
+	require.NoError(t, err)
+	assert.Equal(t, "login-token-for-otp-user", *tk)
+
+	enrollment, err := facade.EnrollOTP(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth://totp/test", enrollment.URI)
+	assert.Equal(t, []string{"aaaaa-bbbbb"}, enrollment.RecoveryCodes)
+
+	require.NoError(t, facade.ConfirmOTP(ctx, "token", "123456"))
+	require.Error(t, facade.ConfirmOTP(ctx, "token", "000000"))
+}
+
+func TestAuthHTTPFacade_Account(t *testing.T) {
+	handler := http.NewServeMux()
+
+	handler.HandleFunc("/password", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var req struct {
+			OldPassword string `json:"old_password"`
+			NewPassword string `json:"new_password"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.OldPassword != "old" {
+			http.Error(w, "invalid password", http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "new", req.NewPassword)
+		w.Header().Set("Authorization", "Bearer new-token")
+	})
+
+	handler.HandleFunc("/account", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		var req struct {
+			Password string `json:"password"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if req.Password != "pass" {
+			http.Error(w, "invalid password", http.StatusUnauthorized)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	facade := NewAuthHTTPFacade(newRestyClientWithBaseURL(server.URL))
+	ctx := context.Background()
+
+	tk, err := facade.ChangePassword(ctx, "token", "old", "new")
+	require.NoError(t, err)
+	assert.Equal(t, "new-token", *tk)
+
+	_, err = facade.ChangePassword(ctx, "token", "wrong", "new")
+	require.Error(t, err)
+
+	require.NoError(t, facade.DeleteAccount(ctx, "token", "pass"))
+	require.Error(t, facade.DeleteAccount(ctx, "token", "wrong"))
+}
+
+func TestAuthGRPCFacade_Account(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterAuthServiceServer(grpcServer, &mockAuthServiceServer{})
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	facade := NewAuthGRPCFacade(conn)
+	ctx := context.Background()
+
+	tk, err := facade.ChangePassword(ctx, "token", "old", "new")
+	require.NoError(t, err)
+	assert.Equal(t, "new-token", *tk)
+
+	_, err = facade.ChangePassword(ctx, "token", "wrong", "new")
+	require.Error(t, err)
+
+	require.NoError(t, facade.DeleteAccount(ctx, "token", "pass"))
+	require.Error(t, facade.DeleteAccount(ctx, "token", "wrong"))
+}
@@ -8,7 +8,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
-	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/sbilibin2017/gophkeeper/internal/models"
 	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
@@ -34,6 +34,8 @@ func (w *SecretWriterHTTP) Save(
 	secretType string,
 	ciphertext []byte,
 	aesKeyEnc []byte,
+	keyID string,
+	recipients models.Recipients,
 ) error {
 	secret := &models.Secret{
 		SecretOwner: secretOwner,
@@ -41,6 +43,8 @@ func (w *SecretWriterHTTP) Save(
 		SecretType:  secretType,
 		Ciphertext:  ciphertext,
 		AESKeyEnc:   aesKeyEnc,
+		KeyID:       keyID,
+		Recipients:  recipients,
 	}
 
 	resp, err := w.client.R().
@@ -57,6 +61,26 @@ func (w *SecretWriterHTTP) Save(
 	return nil
 }
 
+// SaveBatch sends several secrets to be stored atomically via HTTP.
+func (w *SecretWriterHTTP) SaveBatch(
+	ctx context.Context,
+	secretOwner string,
+	secrets []*models.Secret,
+) error {
+	resp, err := w.client.R().
+		SetContext(ctx).
+		SetAuthToken(secretOwner).
+		SetBody(secrets).
+		Post("/secrets/batch")
+	if err != nil {
+		return fmt.Errorf("http save batch request failed: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("http error status %d, body: %s", resp.StatusCode(), resp.String())
+	}
+	return nil
+}
+
 type SecretReaderHTTP struct {
 	client *resty.Client
 }
@@ -131,6 +155,8 @@ func (w *SecretWriterGRPC) Save(
 	secretType string,
 	ciphertext []byte,
 	aesKeyEnc []byte,
+	keyID string,
+	recipients models.Recipients,
 ) error {
 	// Inject secretOwner as metadata in the outgoing context
 	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("token", secretOwner))
@@ -140,6 +166,8 @@ func (w *SecretWriterGRPC) Save(
 		SecretType: secretType,
 		Ciphertext: ciphertext,
 		AesKeyEnc:  aesKeyEnc,
+		KeyId:      keyID,
+		Recipients: recipientsToPB(recipients),
 	}
 
 	_, err := w.client.Save(ctx, req)
@@ -149,6 +177,41 @@ func (w *SecretWriterGRPC) Save(
 	return nil
 }
 
+// SaveBatch sends several secrets to be stored atomically via gRPC.
+func (w *SecretWriterGRPC) SaveBatch(
+	ctx context.Context,
+	secretOwner string,
+	secrets []*models.Secret,
+) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+secretOwner)
+
+	req := &pb.SecretBatchSaveRequest{
+		Secrets: make([]*pb.SecretSaveRequest, 0, len(secrets)),
+	}
+	for _, s := range secrets {
+		r := &pb.SecretSaveRequest{
+			SecretName: s.SecretName,
+			SecretType: s.SecretType,
+			Ciphertext: s.Ciphertext,
+			AesKeyEnc:  s.AESKeyEnc,
+			KeyId:      s.KeyID,
+			Recipients: recipientsToPB(s.Recipients),
+		}
+		if !s.CreatedAt.IsZero() {
+			r.CreatedAt = timestamppb.New(s.CreatedAt)
+		}
+		if !s.UpdatedAt.IsZero() {
+			r.UpdatedAt = timestamppb.New(s.UpdatedAt)
+		}
+		req.Secrets = append(req.Secrets, r)
+	}
+
+	if _, err := w.client.SaveBatch(ctx, req); err != nil {
+		return fmt.Errorf("gRPC save batch failed: %w", err)
+	}
+	return nil
+}
+
 type SecretReaderGRPC struct {
 	client pb.SecretReadServiceClient
 }
@@ -184,6 +247,8 @@ func (r *SecretReaderGRPC) Get(
 		SecretType:  resp.SecretType,
 		Ciphertext:  resp.Ciphertext,
 		AESKeyEnc:   resp.AesKeyEnc,
+		KeyID:       resp.KeyId,
+		Recipients:  recipientsFromPB(resp.Recipients),
 		CreatedAt:   resp.CreatedAt.AsTime(),
 		UpdatedAt:   resp.UpdatedAt.AsTime(),
 	}, nil
@@ -196,7 +261,7 @@ func (r *SecretReaderGRPC) List(
 ) ([]*models.Secret, error) {
 	ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("token", secretOwner))
 
-	stream, err := r.client.List(ctx, &emptypb.Empty{})
+	stream, err := r.client.List(ctx, &pb.SecretListRequest{})
 	if err != nil {
 		return nil, fmt.Errorf("gRPC List stream start failed: %w", err)
 	}
@@ -217,6 +282,8 @@ func (r *SecretReaderGRPC) List(
 			SecretType:  resp.SecretType,
 			Ciphertext:  resp.Ciphertext,
 			AESKeyEnc:   resp.AesKeyEnc,
+			KeyID:       resp.KeyId,
+			Recipients:  recipientsFromPB(resp.Recipients),
 			CreatedAt:   resp.CreatedAt.AsTime(),
 			UpdatedAt:   resp.UpdatedAt.AsTime(),
 		})
@@ -224,3 +291,27 @@ func (r *SecretReaderGRPC) List(
 
 	return secrets, nil
 }
+
+// recipientsToPB converts model recipients to the protobuf representation.
+func recipientsToPB(in models.Recipients) []*pb.Recipient {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]*pb.Recipient, 0, len(in))
+	for _, r := range in {
+		out = append(out, &pb.Recipient{KeyId: r.KeyID, AesKeyEnc: r.AESKeyEnc})
+	}
+	return out
+}
+
+// recipientsFromPB converts protobuf recipients to the model representation.
+func recipientsFromPB(in []*pb.Recipient) models.Recipients {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(models.Recipients, 0, len(in))
+	for _, r := range in {
+		out = append(out, models.Recipient{KeyID: r.GetKeyId(), AESKeyEnc: r.GetAesKeyEnc()})
+	}
+	return out
+}
@@ -38,6 +38,8 @@ func TestSecretWriterHTTP_Save(t *testing.T) {
 
 		assert.NotEmpty(t, secret.SecretName)
 		assert.NotEmpty(t, secret.SecretType)
+		assert.Equal(t, "key-1", secret.KeyID)
+		assert.Len(t, secret.Recipients, 2)
 		w.WriteHeader(http.StatusOK)
 	})
 
@@ -53,10 +55,40 @@ func TestSecretWriterHTTP_Save(t *testing.T) {
 		"type1",
 		[]byte("ciphertext"),
 		[]byte("key"),
+		"key-1",
+		models.Recipients{
+			{KeyID: "key-1", AESKeyEnc: []byte("key")},
+			{KeyID: "key-2", AESKeyEnc: []byte("key-2")},
+		},
 	)
 	assert.NoError(t, err)
 }
 
+func TestSecretWriterHTTP_SaveBatch(t *testing.T) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/secrets/batch", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var secrets []models.Secret
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&secrets))
+		require.Len(t, secrets, 2)
+		assert.Equal(t, "key-2", secrets[0].KeyID)
+		assert.Equal(t, "name2", secrets[1].SecretName)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := NewSecretWriterHTTP(resty.New().SetBaseURL(server.URL))
+
+	err := client.SaveBatch(context.Background(), "token", []*models.Secret{
+		{SecretName: "name1", SecretType: "text", Ciphertext: []byte("c1"), AESKeyEnc: []byte("k1"), KeyID: "key-2"},
+		{SecretName: "name2", SecretType: "text", Ciphertext: []byte("c2"), AESKeyEnc: []byte("k2"), KeyID: "key-2"},
+	})
+	assert.NoError(t, err)
+}
+
 func TestSecretReaderHTTP_Get(t *testing.T) {
 	handler := http.NewServeMux()
 	handler.HandleFunc("/get/type1/name1", func(w http.ResponseWriter, r *http.Request) {
@@ -144,12 +176,23 @@ func (s *testSecretService) Save(ctx context.Context, req *pb.SecretSaveRequest)
 		SecretOwner: "test-owner",
 		Ciphertext:  req.Ciphertext,
 		AesKeyEnc:   req.AesKeyEnc,
+		KeyId:       req.KeyId,
+		Recipients:  req.Recipients,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 	return &emptypb.Empty{}, nil
 }
 
+func (s *testSecretService) SaveBatch(ctx context.Context, req *pb.SecretBatchSaveRequest) (*emptypb.Empty, error) {
+	for _, r := range req.Secrets {
+		if _, err := s.Save(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
+
 func (s *testSecretService) Get(ctx context.Context, req *pb.SecretGetRequest) (*pb.Secret, error) {
 	key := req.SecretType + "/" + req.SecretName
 	secret, ok := s.store[key]
@@ -159,7 +202,7 @@ func (s *testSecretService) Get(ctx context.Context, req *pb.SecretGetRequest) (
 	return secret, nil
 }
 
-func (s *testSecretService) List(_ *emptypb.Empty, stream pb.SecretReadService_ListServer) error {
+func (s *testSecretService) List(_ *pb.SecretListRequest, stream pb.SecretReadService_ListServer) error {
 	for _, secret := range s.store {
 		if err := stream.Send(secret); err != nil {
 			return err
@@ -203,6 +246,11 @@ func TestSecretWriterGRPC_Save_and_SecretReaderGRPC_Get_List(t *testing.T) {
 		SecretType: "type1",
 		Ciphertext: []byte("ciphertext"),
 		AESKeyEnc:  []byte("aeskey"),
+		KeyID:      "key-1",
+		Recipients: models.Recipients{
+			{KeyID: "key-1", AESKeyEnc: []byte("aeskey")},
+			{KeyID: "key-2", AESKeyEnc: []byte("aeskey-2")},
+		},
 	}
 
 	// Save the secret
@@ -213,6 +261,8 @@ func TestSecretWriterGRPC_Save_and_SecretReaderGRPC_Get_List(t *testing.T) {
 		secret.SecretType,
 		secret.Ciphertext,
 		secret.AESKeyEnc,
+		secret.KeyID,
+		secret.Recipients,
 	)
 	require.NoError(t, err)
 
@@ -224,6 +274,8 @@ func TestSecretWriterGRPC_Save_and_SecretReaderGRPC_Get_List(t *testing.T) {
 	assert.Equal(t, secret.SecretType, got.SecretType)
 	assert.Equal(t, secret.Ciphertext, got.Ciphertext)
 	assert.Equal(t, secret.AESKeyEnc, got.AESKeyEnc)
+	assert.Equal(t, secret.KeyID, got.KeyID)
+	assert.Equal(t, secret.Recipients, got.Recipients)
 
 	// List secrets
 	secrets, err := reader.List(context.Background(), "test-owner")
@@ -232,3 +284,28 @@ func TestSecretWriterGRPC_Save_and_SecretReaderGRPC_Get_List(t *testing.T) {
 	assert.Equal(t, secret.SecretName, secrets[0].SecretName)
 	assert.Equal(t, secret.SecretType, secrets[0].SecretType)
 }
+
+func TestSecretWriterGRPC_SaveBatch(t *testing.T) {
+	addr, stop := startTestGRPCServer(t)
+	defer stop()
+
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	writer := NewSecretWriterGRPC(conn)
+	reader := NewSecretReaderGRPC(conn)
+
+	err = writer.SaveBatch(context.Background(), "test-owner", []*models.Secret{
+		{SecretName: "name1", SecretType: "text", Ciphertext: []byte("c1"), AESKeyEnc: []byte("k1"), KeyID: "key-2"},
+		{SecretName: "name2", SecretType: "text", Ciphertext: []byte("c2"), AESKeyEnc: []byte("k2"), KeyID: "key-2"},
+	})
+	require.NoError(t, err)
+
+	secrets, err := reader.List(context.Background(), "test-owner")
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	for _, s := range secrets {
+		assert.Equal(t, "key-2", s.KeyID)
+	}
+}
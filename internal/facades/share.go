@@ -0,0 +1,252 @@
+package facades
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-resty/resty/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+)
+
+// ShareHTTPFacade publishes certificates and shares secrets over HTTP.
+type ShareHTTPFacade struct {
+	client *resty.Client
+}
+
+// NewShareHTTPFacade creates a new ShareHTTPFacade with the given Resty client.
+func NewShareHTTPFacade(client *resty.Client) *ShareHTTPFacade {
+	return &ShareHTTPFacade{client: client}
+}
+
+// PublishCertificate publishes the PEM certificate of the token owner.
+func (s *ShareHTTPFacade) PublishCertificate(ctx context.Context, token, certificate string) error {
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetBody(map[string]string{"certificate": certificate}).
+		Put("/certificate")
+	if err != nil {
+		return fmt.Errorf("publish certificate request failed: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("publish certificate request returned error: %s", resp.Status())
+	}
+	return nil
+}
+
+// GetCertificate returns the PEM certificate published by username.
+func (s *ShareHTTPFacade) GetCertificate(ctx context.Context, token, username string) (string, error) {
+	var result struct {
+		Certificate string `json:"certificate"`
+	}
+
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetPathParam("username", username).
+		SetResult(&result).
+		Get("/users/{username}/certificate")
+	if err != nil {
+		return "", fmt.Errorf("get certificate request failed: %w", err)
+	}
+	if resp.IsError() {
+		return "", fmt.Errorf("get certificate request returned error: %s", resp.Status())
+	}
+	return result.Certificate, nil
+}
+
+// Share shares a secret of the token owner with recipient.
+func (s *ShareHTTPFacade) Share(
+	ctx context.Context,
+	token, secretType, secretName, recipient string,
+	aesKeyEnc []byte,
+	keyID, permission string,
+) error {
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetBody(map[string]any{
+			"secret_type": secretType,
+			"secret_name": secretName,
+			"recipient":   recipient,
+			"aes_key_enc": aesKeyEnc,
+			"key_id":      keyID,
+			"permission":  permission,
+		}).
+		Post("/shares")
+	if err != nil {
+		return fmt.Errorf("share request failed: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("share request returned error: %s", resp.Status())
+	}
+	return nil
+}
+
+// Unshare revokes the access of recipient to a secret of the token owner.
+func (s *ShareHTTPFacade) Unshare(ctx context.Context, token, secretType, secretName, recipient string) error {
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetPathParams(map[string]string{
+			"secret_type": secretType,
+			"secret_name": secretName,
+			"recipient":   recipient,
+		}).
+		Delete("/shares/{secret_type}/{secret_name}/{recipient}")
+	if err != nil {
+		return fmt.Errorf("unshare request failed: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("unshare request returned error: %s", resp.Status())
+	}
+	return nil
+}
+
+// ListSharedWithMe returns the secrets other users share with the token owner.
+func (s *ShareHTTPFacade) ListSharedWithMe(ctx context.Context, token string) ([]*models.SharedSecret, error) {
+	var secrets []*models.SharedSecret
+
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetResult(&secrets).
+		Get("/shares")
+	if err != nil {
+		return nil, fmt.Errorf("list shared request failed: %w", err)
+	}
+	if resp.IsError() {
+		return nil, fmt.Errorf("list shared request returned error: %s", resp.Status())
+	}
+	return secrets, nil
+}
+
+// SaveShared replaces the content of a secret of owner shared read-write with the token owner.
+func (s *ShareHTTPFacade) SaveShared(
+	ctx context.Context,
+	token, owner, secretType, secretName string,
+	ciphertext []byte,
+) error {
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetPathParams(map[string]string{
+			"secret_owner": owner,
+			"secret_type":  secretType,
+			"secret_name":  secretName,
+		}).
+		SetBody(map[string][]byte{"ciphertext": ciphertext}).
+		Put("/shared/{secret_owner}/{secret_type}/{secret_name}")
+	if err != nil {
+		return fmt.Errorf("save shared request failed: %w", err)
+	}
+	if resp.IsError() {
+		return fmt.Errorf("save shared request returned error: %s", resp.Status())
+	}
+	return nil
+}
+
+// ShareGRPCFacade publishes certificates and shares secrets over gRPC.
+type ShareGRPCFacade struct {
+	client pb.ShareServiceClient
+}
+
+// NewShareGRPCFacade creates a new ShareGRPCFacade with the given gRPC client connection.
+func NewShareGRPCFacade(conn *grpc.ClientConn) *ShareGRPCFacade {
+	return &ShareGRPCFacade{client: pb.NewShareServiceClient(conn)}
+}
+
+// PublishCertificate publishes the PEM certificate of the token owner.
+func (s *ShareGRPCFacade) PublishCertificate(ctx context.Context, token, certificate string) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	_, err := s.client.PublishCertificate(ctx, &pb.PublishCertificateRequest{Certificate: certificate})
+	return err
+}
+
+// GetCertificate returns the PEM certificate published by username.
+func (s *ShareGRPCFacade) GetCertificate(ctx context.Context, token, username string) (string, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	resp, err := s.client.GetCertificate(ctx, &pb.GetCertificateRequest{Username: username})
+	if err != nil {
+		return "", err
+	}
+	return resp.GetCertificate(), nil
+}
+
+// Share shares a secret of the token owner with recipient.
+func (s *ShareGRPCFacade) Share(
+	ctx context.Context,
+	token, secretType, secretName, recipient string,
+	aesKeyEnc []byte,
+	keyID, permission string,
+) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	_, err := s.client.Share(ctx, &pb.ShareRequest{
+		SecretType: secretType,
+		SecretName: secretName,
+		Recipient:  recipient,
+		AesKeyEnc:  aesKeyEnc,
+		KeyId:      keyID,
+		Permission: permission,
+	})
+	return err
+}
+
+// Unshare revokes the access of recipient to a secret of the token owner.
+func (s *ShareGRPCFacade) Unshare(ctx context.Context, token, secretType, secretName, recipient string) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	_, err := s.client.Unshare(ctx, &pb.UnshareRequest{
+		SecretType: secretType,
+		SecretName: secretName,
+		Recipient:  recipient,
+	})
+	return err
+}
+
+// ListSharedWithMe returns the secrets other users share with the token owner.
+func (s *ShareGRPCFacade) ListSharedWithMe(ctx context.Context, token string) ([]*models.SharedSecret, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	resp, err := s.client.ListSharedWithMe(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*models.SharedSecret, 0, len(resp.GetSecrets()))
+	for _, secret := range resp.GetSecrets() {
+		secrets = append(secrets, &models.SharedSecret{
+			Secret: models.Secret{
+				SecretName:  secret.GetSecretName(),
+				SecretType:  secret.GetSecretType(),
+				SecretOwner: secret.GetSecretOwner(),
+				Ciphertext:  secret.GetCiphertext(),
+				AESKeyEnc:   secret.GetAesKeyEnc(),
+				KeyID:       secret.GetKeyId(),
+				CreatedAt:   secret.GetCreatedAt().AsTime(),
+				UpdatedAt:   secret.GetUpdatedAt().AsTime(),
+			},
+			Permission: secret.GetPermission(),
+		})
+	}
+	return secrets, nil
+}
+
+// SaveShared replaces the content of a secret of owner shared read-write with the token owner.
+func (s *ShareGRPCFacade) SaveShared(
+	ctx context.Context,
+	token, owner, secretType, secretName string,
+	ciphertext []byte,
+) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	_, err := s.client.SaveShared(ctx, &pb.SaveSharedRequest{
+		SecretOwner: owner,
+		SecretType:  secretType,
+		SecretName:  secretName,
+		Ciphertext:  ciphertext,
+	})
+	return err
+}
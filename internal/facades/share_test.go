@@ -0,0 +1,220 @@
+package facades
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+)
+
+func TestShareHTTPFacade(t *testing.T) {
+	handler := http.NewServeMux()
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	handler.HandleFunc("PUT /certificate", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "pem", body["certificate"])
+	})
+	handler.HandleFunc("GET /users/{username}/certificate", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"certificate":"` + r.PathValue("username") + `-pem"}`))
+	})
+	handler.HandleFunc("POST /shares", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		var body struct {
+			SecretType string `json:"secret_type"`
+			Recipient  string `json:"recipient"`
+			AESKeyEnc  []byte `json:"aes_key_enc"`
+			Permission string `json:"permission"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "text", body.SecretType)
+		assert.Equal(t, "bob", body.Recipient)
+		assert.Equal(t, []byte("wrapped"), body.AESKeyEnc)
+		assert.Equal(t, "rw", body.Permission)
+	})
+	handler.HandleFunc("DELETE /shares/{secret_type}/{secret_name}/{recipient}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if r.PathValue("recipient") != "bob" {
+			http.Error(w, "share not found", http.StatusNotFound)
+		}
+	})
+	handler.HandleFunc("GET /shares", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"secret_name":"wifi","secret_type":"text","secret_owner":"alice","ciphertext":"Y3Q=","aes_key_enc":"d3JhcHBlZA==","key_id":"bob-id","permission":"ro","created_at":"2025-08-01T12:00:00Z","updated_at":"2025-08-01T12:00:00Z"}]`))
+	})
+	handler.HandleFunc("PUT /shared/{secret_owner}/{secret_type}/{secret_name}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		assert.Equal(t, "alice", r.PathValue("secret_owner"))
+		var body map[string][]byte
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []byte("ct"), body["ciphertext"])
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	facade := NewShareHTTPFacade(newRestyClientWithBaseURL(server.URL))
+	ctx := context.Background()
+
+	require.NoError(t, facade.PublishCertificate(ctx, "token", "pem"))
+
+	cert, err := facade.GetCertificate(ctx, "token", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "bob-pem", cert)
+
+	require.NoError(t, facade.Share(ctx, "token", "text", "wifi", "bob", []byte("wrapped"), "bob-id", "rw"))
+	require.NoError(t, facade.Unshare(ctx, "token", "text", "wifi", "bob"))
+
+	err = facade.Unshare(ctx, "token", "text", "wifi", "carol")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unshare request returned error")
+
+	secrets, err := facade.ListSharedWithMe(ctx, "token")
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "alice", secrets[0].SecretOwner)
+	assert.Equal(t, "ro", secrets[0].Permission)
+	assert.Equal(t, []byte("wrapped"), secrets[0].AESKeyEnc)
+
+	require.NoError(t, facade.SaveShared(ctx, "token", "alice", "text", "wifi", []byte("ct")))
+
+	err = facade.PublishCertificate(ctx, "bad", "pem")
+	require.Error(t, err)
+}
+
+type mockShareServiceServer struct {
+	pb.UnimplementedShareServiceServer
+}
+
+func (m *mockShareServiceServer) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if got := md.Get("authorization"); len(got) == 0 || got[0] != "Bearer token" {
+		return status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return nil
+}
+
+func (m *mockShareServiceServer) PublishCertificate(ctx context.Context, req *pb.PublishCertificateRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, m.authorize(ctx)
+}
+
+func (m *mockShareServiceServer) GetCertificate(ctx context.Context, req *pb.GetCertificateRequest) (*pb.CertificateResponse, error) {
+	if err := m.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return &pb.CertificateResponse{Certificate: req.GetUsername() + "-pem"}, nil
+}
+
+func (m *mockShareServiceServer) Share(ctx context.Context, req *pb.ShareRequest) (*emptypb.Empty, error) {
+	if err := m.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if req.GetPermission() != "ro" && req.GetPermission() != "rw" {
+		return nil, status.Error(codes.InvalidArgument, "invalid share permission")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (m *mockShareServiceServer) Unshare(ctx context.Context, req *pb.UnshareRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, m.authorize(ctx)
+}
+
+func (m *mockShareServiceServer) ListSharedWithMe(ctx context.Context, _ *emptypb.Empty) (*pb.SharedSecretListResponse, error) {
+	if err := m.authorize(ctx); err != nil {
+		return nil, err
+	}
+	ts := timestamppb.New(time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC))
+	return &pb.SharedSecretListResponse{Secrets: []*pb.SharedSecret{{
+		SecretName:  "wifi",
+		SecretType:  "text",
+		SecretOwner: "alice",
+		Ciphertext:  []byte("ct"),
+		AesKeyEnc:   []byte("wrapped"),
+		KeyId:       "bob-id",
+		Permission:  "rw",
+		CreatedAt:   ts,
+		UpdatedAt:   ts,
+	}}}, nil
+}
+
+func (m *mockShareServiceServer) SaveShared(ctx context.Context, req *pb.SaveSharedRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, m.authorize(ctx)
+}
+
+func TestShareGRPCFacade(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	pb.RegisterShareServiceServer(grpcServer, &mockShareServiceServer{})
+
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	facade := NewShareGRPCFacade(conn)
+	ctx := context.Background()
+
+	require.NoError(t, facade.PublishCertificate(ctx, "token", "pem"))
+
+	cert, err := facade.GetCertificate(ctx, "token", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, "bob-pem", cert)
+
+	require.NoError(t, facade.Share(ctx, "token", "text", "wifi", "bob", []byte("wrapped"), "bob-id", "ro"))
+	err = facade.Share(ctx, "token", "text", "wifi", "bob", []byte("wrapped"), "bob-id", "admin")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	require.NoError(t, facade.Unshare(ctx, "token", "text", "wifi", "bob"))
+
+	secrets, err := facade.ListSharedWithMe(ctx, "token")
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "alice", secrets[0].SecretOwner)
+	assert.Equal(t, "rw", secrets[0].Permission)
+	assert.Equal(t, time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC), secrets[0].UpdatedAt)
+
+	require.NoError(t, facade.SaveShared(ctx, "token", "alice", "text", "wifi", []byte("ct")))
+
+	_, err = facade.ListSharedWithMe(ctx, "bad")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
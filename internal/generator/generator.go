@@ -0,0 +1,274 @@
+package generator
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// Character classes a password can be drawn from.
+const (
+	ClassLower   = "lower"
+	ClassUpper   = "upper"
+	ClassDigits  = "digits"
+	ClassSymbols = "symbols"
+)
+
+// Ambiguous holds the characters that are easily confused with one another when read or typed.
+const Ambiguous = "0Oo1lI|`'\""
+
+// Defaults and bounds of the generation policy.
+const (
+	DefaultLength    = 20
+	DefaultWords     = 6
+	DefaultSeparator = "-"
+
+	MaxLength = 256
+	MaxWords  = 64
+)
+
+var charsets = map[string]string{
+	ClassLower:   "abcdefghijklmnopqrstuvwxyz",
+	ClassUpper:   "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	ClassDigits:  "0123456789",
+	ClassSymbols: "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~",
+}
+
+// wordlistData is the BIP-39 English wordlist: 2048 short words, unique in their first four letters.
+//
+//go:embed wordlist.txt
+var wordlistData string
+
+// Wordlist holds the words passphrases are drawn from.
+var Wordlist = strings.Fields(wordlistData)
+
+// Generator produces random passwords and passphrases from crypto/rand according to a policy.
+type Generator struct {
+	length           int
+	classes          []string
+	excludeAmbiguous bool
+	words            int
+	separator        string
+}
+
+// Opt defines a functional option for Generator configuration.
+type Opt func(*Generator)
+
+// WithLength sets the password length in characters.
+func WithLength(length int) Opt {
+	return func(g *Generator) {
+		g.length = length
+	}
+}
+
+// WithClasses sets the character classes of a password. Every class appears at least once.
+func WithClasses(classes ...string) Opt {
+	return func(g *Generator) {
+		g.classes = classes
+	}
+}
+
+// WithExcludeAmbiguous removes the Ambiguous characters from every class.
+func WithExcludeAmbiguous() Opt {
+	return func(g *Generator) {
+		g.excludeAmbiguous = true
+	}
+}
+
+// WithWords switches to diceware-style passphrases of the given number of words.
+func WithWords(words int) Opt {
+	return func(g *Generator) {
+		g.words = words
+	}
+}
+
+// WithSeparator sets the string joining the words of a passphrase.
+func WithSeparator(separator string) Opt {
+	return func(g *Generator) {
+		g.separator = separator
+	}
+}
+
+// New constructs a Generator with given options and checks the resulting policy.
+// Without options it generates passwords of DefaultLength characters from all classes.
+func New(opts ...Opt) (*Generator, error) {
+	g := &Generator{
+		length:    DefaultLength,
+		classes:   []string{ClassLower, ClassUpper, ClassDigits, ClassSymbols},
+		separator: DefaultSeparator,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if g.words != 0 {
+		if g.words < 1 || g.words > MaxWords {
+			return nil, fmt.Errorf("number of words must be between 1 and %d", MaxWords)
+		}
+		return g, nil
+	}
+
+	if len(g.classes) == 0 {
+		return nil, errors.New("at least one character class is required")
+	}
+	seen := make(map[string]bool, len(g.classes))
+	for _, class := range g.classes {
+		if _, ok := charsets[class]; !ok {
+			return nil, fmt.Errorf("unknown character class %q", class)
+		}
+		if seen[class] {
+			return nil, fmt.Errorf("duplicate character class %q", class)
+		}
+		seen[class] = true
+	}
+	if g.length < len(g.classes) || g.length > MaxLength {
+		return nil, fmt.Errorf("length must be between %d and %d", len(g.classes), MaxLength)
+	}
+	return g, nil
+}
+
+// Generate returns a new password, or a passphrase if WithWords was given.
+func (g *Generator) Generate() (string, error) {
+	if g.words > 0 {
+		return g.passphrase()
+	}
+	return g.password()
+}
+
+// password draws every character uniformly from the union of the classes and retries
+// until each class is present, so that all passwords allowed by the policy are equally likely.
+func (g *Generator) password() (string, error) {
+	sets := g.sets()
+	alphabet := []rune(strings.Join(sets, ""))
+
+	for {
+		password := make([]rune, g.length)
+		for i := range password {
+			n, err := randInt(len(alphabet))
+			if err != nil {
+				return "", err
+			}
+			password[i] = alphabet[n]
+		}
+
+		if containsAll(string(password), sets) {
+			return string(password), nil
+		}
+	}
+}
+
+// passphrase joins words drawn uniformly from Wordlist.
+func (g *Generator) passphrase() (string, error) {
+	words := make([]string, g.words)
+	for i := range words {
+		n, err := randInt(len(Wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[i] = Wordlist[n]
+	}
+	return strings.Join(words, g.separator), nil
+}
+
+// Entropy returns the entropy in bits of the outputs of Generate, that is log2 of the number
+// of equally likely outputs. For passwords the count excludes those missing a class.
+func (g *Generator) Entropy() float64 {
+	if g.words > 0 {
+		return float64(g.words) * math.Log2(float64(len(Wordlist)))
+	}
+
+	sets := g.sets()
+	total := 0
+	for _, set := range sets {
+		total += len(set)
+	}
+
+	// Inclusion-exclusion over the subsets of classes left out of a password.
+	count := new(big.Int)
+	length := big.NewInt(int64(g.length))
+	for mask := 0; mask < 1<<len(sets); mask++ {
+		size, excluded := total, 0
+		for i, set := range sets {
+			if mask&(1<<i) != 0 {
+				size -= len(set)
+				excluded++
+			}
+		}
+		term := new(big.Int).Exp(big.NewInt(int64(size)), length, nil)
+		if excluded%2 == 1 {
+			count.Sub(count, term)
+		} else {
+			count.Add(count, term)
+		}
+	}
+
+	return log2(count)
+}
+
+// sets returns the characters of each class, without the ambiguous ones if requested.
+func (g *Generator) sets() []string {
+	sets := make([]string, len(g.classes))
+	for i, class := range g.classes {
+		set := charsets[class]
+		if g.excludeAmbiguous {
+			set = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(Ambiguous, r) {
+					return -1
+				}
+				return r
+			}, set)
+		}
+		sets[i] = set
+	}
+	return sets
+}
+
+// Strength describes an entropy in bits in words.
+func Strength(bits float64) string {
+	switch {
+	case bits < 28:
+		return "very weak"
+	case bits < 36:
+		return "weak"
+	case bits < 60:
+		return "reasonable"
+	case bits < 128:
+		return "strong"
+	default:
+		return "very strong"
+	}
+}
+
+// containsAll reports whether s contains a character of every set.
+func containsAll(s string, sets []string) bool {
+	for _, set := range sets {
+		if !strings.ContainsAny(s, set) {
+			return false
+		}
+	}
+	return true
+}
+
+// randInt returns a uniform random integer in [0, n) from crypto/rand.
+func randInt(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("random number gen failed: %w", err)
+	}
+	return int(v.Int64()), nil
+}
+
+// log2 returns the base-2 logarithm of a positive x.
+func log2(x *big.Int) float64 {
+	if x.Sign() <= 0 {
+		return 0
+	}
+	mant := new(big.Float)
+	exp := new(big.Float).SetInt(x).MantExp(mant)
+	m, _ := mant.Float64()
+	return float64(exp) + math.Log2(m)
+}
@@ -0,0 +1,107 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordlist(t *testing.T) {
+	require.Len(t, Wordlist, 2048)
+
+	seen := make(map[string]bool, len(Wordlist))
+	for _, w := range Wordlist {
+		assert.False(t, seen[w], "duplicate word %q", w)
+		seen[w] = true
+	}
+}
+
+func TestGenerate_Password(t *testing.T) {
+	g, err := New()
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		password, err := g.Generate()
+		require.NoError(t, err)
+		assert.Len(t, password, DefaultLength)
+		for _, class := range []string{ClassLower, ClassUpper, ClassDigits, ClassSymbols} {
+			assert.True(t, strings.ContainsAny(password, charsets[class]), "password %q lacks %s", password, class)
+		}
+		seen[password] = true
+	}
+	assert.Len(t, seen, 50)
+}
+
+func TestGenerate_Policy(t *testing.T) {
+	g, err := New(WithLength(4), WithClasses(ClassDigits))
+	require.NoError(t, err)
+	pin, err := g.Generate()
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9]{4}$`, pin)
+	assert.InDelta(t, 4*math.Log2(10), g.Entropy(), 1e-9)
+
+	g, err = New(WithLength(64), WithClasses(ClassLower, ClassUpper, ClassDigits, ClassSymbols), WithExcludeAmbiguous())
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		password, err := g.Generate()
+		require.NoError(t, err)
+		assert.False(t, strings.ContainsAny(password, Ambiguous), "password %q has ambiguous characters", password)
+	}
+
+	for name, opts := range map[string][]Opt{
+		"no classes":      {WithClasses()},
+		"unknown class":   {WithClasses("emoji")},
+		"duplicate class": {WithClasses(ClassLower, ClassLower)},
+		"too short":       {WithLength(3)},
+		"too long":        {WithLength(MaxLength + 1)},
+		"too many words":  {WithWords(MaxWords + 1)},
+		"negative words":  {WithWords(-1)},
+	} {
+		_, err := New(opts...)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestGenerate_Passphrase(t *testing.T) {
+	g, err := New(WithWords(5), WithSeparator(" "))
+	require.NoError(t, err)
+
+	passphrase, err := g.Generate()
+	require.NoError(t, err)
+
+	words := strings.Split(passphrase, " ")
+	require.Len(t, words, 5)
+	for _, w := range words {
+		assert.Contains(t, Wordlist, w)
+	}
+	assert.InDelta(t, 55, g.Entropy(), 1e-9)
+}
+
+func TestEntropy(t *testing.T) {
+	// Two-character passwords over lower and digits that contain both: 2*26*10 of 36^2.
+	g, err := New(WithLength(2), WithClasses(ClassLower, ClassDigits))
+	require.NoError(t, err)
+	assert.InDelta(t, math.Log2(520), g.Entropy(), 1e-9)
+
+	// Requiring every class costs little at the default length.
+	g, err = New()
+	require.NoError(t, err)
+	assert.InDelta(t, DefaultLength*math.Log2(94), g.Entropy(), 0.5)
+	assert.Less(t, g.Entropy(), DefaultLength*math.Log2(94))
+
+	g, err = New(WithExcludeAmbiguous())
+	require.NoError(t, err)
+	assert.InDelta(t, DefaultLength*math.Log2(float64(94-len(Ambiguous))), g.Entropy(), 0.5)
+}
+
+func TestStrength(t *testing.T) {
+	assert.Equal(t, "very weak", Strength(13.3))
+	assert.Equal(t, "weak", Strength(30))
+	assert.Equal(t, "reasonable", Strength(55))
+	assert.Equal(t, "strong", Strength(66))
+	assert.Equal(t, "very strong", Strength(131))
+}
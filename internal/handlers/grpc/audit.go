@@ -0,0 +1,61 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AuditLister defines the interface for reading the audit log of a user.
+type AuditLister interface {
+	// List returns the newest audit events of a user.
+	List(ctx context.Context, username string, limit int) ([]*models.AuditEvent, error)
+}
+
+// AuditServer implements the AuditService gRPC interface.
+type AuditServer struct {
+	pb.UnimplementedAuditServiceServer
+
+	lister AuditLister
+	parser JWTParser
+}
+
+// NewAuditServer creates a new AuditServer instance.
+func NewAuditServer(lister AuditLister, parser JWTParser) *AuditServer {
+	return &AuditServer{
+		lister: lister,
+		parser: parser,
+	}
+}
+
+// List returns the newest audit events of the authenticated user.
+func (s *AuditServer) List(ctx context.Context, req *pb.AuditListRequest) (*pb.AuditListResponse, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	events, err := s.lister.List(ctx, username, int(req.GetLimit()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	resp := &pb.AuditListResponse{Events: make([]*pb.AuditEvent, 0, len(events))}
+	for _, ev := range events {
+		resp.Events = append(resp.Events, &pb.AuditEvent{
+			Id:        ev.ID,
+			Username:  ev.Username,
+			Action:    ev.Action,
+			SecretKey: ev.SecretKey,
+			Transport: ev.Transport,
+			ClientIp:  ev.ClientIP,
+			CreatedAt: timestamppb.New(ev.CreatedAt),
+		})
+	}
+
+	return resp, nil
+}
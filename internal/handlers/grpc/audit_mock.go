@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/handlers/grpc/audit.go
+
+// Package grpc is a generated GoMock package.
+package grpc
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockAuditLister is a mock of AuditLister interface.
+type MockAuditLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditListerMockRecorder
+}
+
+// MockAuditListerMockRecorder is the mock recorder for MockAuditLister.
+type MockAuditListerMockRecorder struct {
+	mock *MockAuditLister
+}
+
+// NewMockAuditLister creates a new mock instance.
+func NewMockAuditLister(ctrl *gomock.Controller) *MockAuditLister {
+	mock := &MockAuditLister{ctrl: ctrl}
+	mock.recorder = &MockAuditListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLister) EXPECT() *MockAuditListerMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAuditLister) List(ctx context.Context, username string, limit int) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, username, limit)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditListerMockRecorder) List(ctx, username, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditLister)(nil).List), ctx, username, limit)
+}
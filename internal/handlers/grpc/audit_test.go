@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuditServer_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLister := NewMockAuditLister(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewAuditServer(mockLister, mockParser)
+
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		mockSetup   func()
+		wantErrCode codes.Code
+	}{
+		{
+			name: "success",
+			ctx:  contextWithAuthToken("validtoken"),
+			mockSetup: func() {
+				mockParser.EXPECT().Parse("validtoken").Return("alice", nil)
+				mockLister.EXPECT().List(gomock.Any(), "alice", 10).Return([]*models.AuditEvent{
+					{
+						ID:        7,
+						Username:  "alice",
+						Action:    models.AuditActionSecretGet,
+						SecretKey: "user/mail",
+						Transport: "grpc",
+						ClientIP:  "192.0.2.1",
+						CreatedAt: now,
+					},
+				}, nil)
+			},
+			wantErrCode: codes.OK,
+		},
+		{
+			name:        "missing token",
+			ctx:         context.Background(),
+			mockSetup:   func() {},
+			wantErrCode: codes.Unauthenticated,
+		},
+		{
+			name: "lister error",
+			ctx:  contextWithAuthToken("validtoken"),
+			mockSetup: func() {
+				mockParser.EXPECT().Parse("validtoken").Return("alice", nil)
+				mockLister.EXPECT().List(gomock.Any(), "alice", 10).Return(nil, errors.New("db error"))
+			},
+			wantErrCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			resp, err := srv.List(tt.ctx, &pb.AuditListRequest{Limit: 10})
+			if tt.wantErrCode != codes.OK {
+				assert.Equal(t, tt.wantErrCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, resp.Events, 1)
+			ev := resp.Events[0]
+			assert.Equal(t, int64(7), ev.Id)
+			assert.Equal(t, models.AuditActionSecretGet, ev.Action)
+			assert.Equal(t, "user/mail", ev.SecretKey)
+			assert.Equal(t, "192.0.2.1", ev.ClientIp)
+			assert.True(t, now.Equal(ev.CreatedAt.AsTime()))
+		})
+	}
+}
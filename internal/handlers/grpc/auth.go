@@ -2,17 +2,28 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"strings"
 
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	"github.com/sbilibin2017/gophkeeper/internal/services"
 	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // Registerer defines interface for user registration.
 type AuthService interface {
-	Register(ctx context.Context, username, password string) error
+	Register(ctx context.Context, username, password string, kdfParams *models.KDFParams) error
+	GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error)
 	Authenticate(ctx context.Context, username, password string) error
+	VerifyOTP(ctx context.Context, username, code string) error
+	EnrollOTP(ctx context.Context, username string) (*models.OTPEnrollment, error)
+	ConfirmOTP(ctx context.Context, username, code string) error
+	ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error
+	DeleteAccount(ctx context.Context, username, password string) error
 }
 
 // JWTGenerator generates JWT tokens for users.
@@ -26,24 +37,44 @@ type AuthServer struct {
 
 	svc          AuthService
 	jwtGenerator JWTGenerator
+	parser       JWTParser
 }
 
 // NewAuthServer creates a new AuthServer instance with the provided interfaces.
 func NewAuthServer(
 	svc AuthService,
 	jwtGen JWTGenerator,
+	parser JWTParser,
 ) *AuthServer {
 	return &AuthServer{
 		svc:          svc,
 		jwtGenerator: jwtGen,
+		parser:       parser,
 	}
 }
 
 // Register implements user registration via gRPC.
+// A request carrying KDF parameters registers the user in master-password mode.
 func (s *AuthServer) Register(ctx context.Context, req *pb.AuthRequest) (*pb.AuthResponse, error) {
-	err := s.svc.Register(ctx, req.GetUsername(), req.GetPassword())
+	var kdfParams *models.KDFParams
+	if kdf := req.GetKdf(); kdf != nil {
+		if kdf.GetThreads() > 255 {
+			return nil, status.Error(codes.InvalidArgument, services.ErrInvalidKDFParams.Error())
+		}
+		kdfParams = &models.KDFParams{
+			Algorithm: kdf.GetAlgorithm(),
+			Salt:      kdf.GetSalt(),
+			Time:      kdf.GetTime(),
+			Memory:    kdf.GetMemory(),
+			Threads:   uint8(kdf.GetThreads()),
+		}
+	}
+
+	err := s.svc.Register(ctx, req.GetUsername(), req.GetPassword(), kdfParams)
 	if err != nil {
 		switch {
+		case errors.Is(err, services.ErrInvalidKDFParams):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
 		case err == services.ErrUserAlreadyExists:
 			return nil, status.Error(codes.AlreadyExists, err.Error())
 		default:
@@ -60,6 +91,10 @@ func (s *AuthServer) Register(ctx context.Context, req *pb.AuthRequest) (*pb.Aut
 }
 
 // Login implements user authentication via gRPC.
+//
+// When the account has two-factor authentication enabled the request must also
+// carry a one-time code, otherwise Unauthenticated with ErrOTPRequired is returned
+// and the client is expected to repeat the call with the code.
 func (s *AuthServer) Login(ctx context.Context, req *pb.AuthRequest) (*pb.AuthResponse, error) {
 	err := s.svc.Authenticate(ctx, req.GetUsername(), req.GetPassword())
 	if err != nil {
@@ -71,6 +106,20 @@ func (s *AuthServer) Login(ctx context.Context, req *pb.AuthRequest) (*pb.AuthRe
 		}
 	}
 
+	err = s.svc.VerifyOTP(ctx, req.GetUsername(), req.GetOtpCode())
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrOTPRequired):
+			// The password was right; the client asks for the code and logs in again.
+			markNeutral(ctx)
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, services.ErrInvalidOTP):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
 	token, err := s.jwtGenerator.Generate(req.GetUsername())
 	if err != nil {
 		return nil, status.Error(codes.Internal, "failed to generate token")
@@ -78,3 +127,152 @@ func (s *AuthServer) Login(ctx context.Context, req *pb.AuthRequest) (*pb.AuthRe
 
 	return &pb.AuthResponse{Token: token}, nil
 }
+
+// GetKDFParams returns the master-password KDF parameters of a user.
+// It needs no token, clients call it before deriving the key they log in with.
+func (s *AuthServer) GetKDFParams(ctx context.Context, req *pb.KDFParamsRequest) (*pb.KDFParams, error) {
+	params, err := s.svc.GetKDFParams(ctx, req.GetUsername())
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoKDFParams):
+			return nil, status.Error(codes.NotFound, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &pb.KDFParams{
+		Algorithm: params.Algorithm,
+		Salt:      params.Salt,
+		Time:      params.Time,
+		Memory:    params.Memory,
+		Threads:   uint32(params.Threads),
+	}, nil
+}
+
+// EnrollOTP starts two-factor authentication enrolment for the authenticated user.
+func (s *AuthServer) EnrollOTP(ctx context.Context, _ *emptypb.Empty) (*pb.OTPEnrollResponse, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	enrollment, err := s.svc.EnrollOTP(ctx, username)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOTPAlreadyEnabled):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, services.ErrOTPNotConfigured):
+			return nil, status.Error(codes.Unimplemented, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &pb.OTPEnrollResponse{
+		Uri:           enrollment.URI,
+		RecoveryCodes: enrollment.RecoveryCodes,
+	}, nil
+}
+
+// ConfirmOTP enables two-factor authentication for the authenticated user.
+func (s *AuthServer) ConfirmOTP(ctx context.Context, req *pb.OTPConfirmRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.svc.ConfirmOTP(ctx, username, req.GetCode()); err != nil {
+		if errors.Is(err, services.ErrInvalidOTP) {
+			markFailed(ctx)
+		} else {
+			markNeutral(ctx)
+		}
+		switch {
+		case errors.Is(err, services.ErrInvalidOTP):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, services.ErrOTPNotEnrolled), errors.Is(err, services.ErrOTPAlreadyEnabled):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, services.ErrOTPNotConfigured):
+			return nil, status.Error(codes.Unimplemented, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ChangePassword changes the password of the authenticated user and returns a new token.
+// Tokens issued before the change, including the one used for this call, stop being accepted.
+func (s *AuthServer) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.AuthResponse, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.svc.ChangePassword(ctx, username, req.GetOldPassword(), req.GetNewPassword()); err != nil {
+		if !errors.Is(err, services.ErrInvalidPassword) {
+			markNeutral(ctx)
+		}
+		return nil, accountError(err)
+	}
+
+	token, err := s.jwtGenerator.Generate(username)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate token")
+	}
+
+	return &pb.AuthResponse{Token: token}, nil
+}
+
+// DeleteAccount deletes the authenticated user together with all their secrets.
+func (s *AuthServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.svc.DeleteAccount(ctx, username, req.GetPassword()); err != nil {
+		if !errors.Is(err, services.ErrInvalidPassword) {
+			markNeutral(ctx)
+		}
+		return nil, accountError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// accountError maps errors of password changes and account deletion to gRPC statuses.
+func accountError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidPassword), errors.Is(err, services.ErrInvalidData):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, services.ErrLastOwner):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrAccountNotConfigured):
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+
+// usernameFromContext extracts the bearer token from gRPC metadata and returns the username it was issued to.
+func usernameFromContext(ctx context.Context, parser JWTParser) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", errors.New("missing metadata in context")
+	}
+
+	authHeaders := md.Get("authorization")
+	if len(authHeaders) == 0 {
+		return "", errors.New("missing authorization token")
+	}
+
+	authHeader := authHeaders[0]
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return "", errors.New("invalid authorization token format")
+	}
+
+	return parser.Parse(strings.TrimPrefix(authHeader, "Bearer "))
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
 )
 
 // MockAuthService is a mock of AuthService interface.
@@ -48,18 +49,104 @@ func (mr *MockAuthServiceMockRecorder) Authenticate(ctx, username, password inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthService)(nil).Authenticate), ctx, username, password)
 }
 
+// ChangePassword mocks base method.
+func (m *MockAuthService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, username, oldPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockAuthServiceMockRecorder) ChangePassword(ctx, username, oldPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockAuthService)(nil).ChangePassword), ctx, username, oldPassword, newPassword)
+}
+
+// ConfirmOTP mocks base method.
+func (m *MockAuthService) ConfirmOTP(ctx context.Context, username, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmOTP", ctx, username, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmOTP indicates an expected call of ConfirmOTP.
+func (mr *MockAuthServiceMockRecorder) ConfirmOTP(ctx, username, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmOTP", reflect.TypeOf((*MockAuthService)(nil).ConfirmOTP), ctx, username, code)
+}
+
+// DeleteAccount mocks base method.
+func (m *MockAuthService) DeleteAccount(ctx context.Context, username, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, username, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockAuthServiceMockRecorder) DeleteAccount(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockAuthService)(nil).DeleteAccount), ctx, username, password)
+}
+
+// EnrollOTP mocks base method.
+func (m *MockAuthService) EnrollOTP(ctx context.Context, username string) (*models.OTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollOTP", ctx, username)
+	ret0, _ := ret[0].(*models.OTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollOTP indicates an expected call of EnrollOTP.
+func (mr *MockAuthServiceMockRecorder) EnrollOTP(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollOTP", reflect.TypeOf((*MockAuthService)(nil).EnrollOTP), ctx, username)
+}
+
+// GetKDFParams mocks base method.
+func (m *MockAuthService) GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKDFParams", ctx, username)
+	ret0, _ := ret[0].(*models.KDFParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKDFParams indicates an expected call of GetKDFParams.
+func (mr *MockAuthServiceMockRecorder) GetKDFParams(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKDFParams", reflect.TypeOf((*MockAuthService)(nil).GetKDFParams), ctx, username)
+}
+
 // Register mocks base method.
-func (m *MockAuthService) Register(ctx context.Context, username, password string) error {
+func (m *MockAuthService) Register(ctx context.Context, username, password string, kdfParams *models.KDFParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", ctx, username, password)
+	ret := m.ctrl.Call(m, "Register", ctx, username, password, kdfParams)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Register indicates an expected call of Register.
-func (mr *MockAuthServiceMockRecorder) Register(ctx, username, password interface{}) *gomock.Call {
+func (mr *MockAuthServiceMockRecorder) Register(ctx, username, password, kdfParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthService)(nil).Register), ctx, username, password, kdfParams)
+}
+
+// VerifyOTP mocks base method.
+func (m *MockAuthService) VerifyOTP(ctx context.Context, username, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyOTP", ctx, username, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyOTP indicates an expected call of VerifyOTP.
+func (mr *MockAuthServiceMockRecorder) VerifyOTP(ctx, username, code interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuthService)(nil).Register), ctx, username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyOTP", reflect.TypeOf((*MockAuthService)(nil).VerifyOTP), ctx, username, code)
 }
 
 // MockJWTGenerator is a mock of JWTGenerator interface.
@@ -6,11 +6,14 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	"github.com/sbilibin2017/gophkeeper/internal/services"
 	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func TestAuthServer_Register(t *testing.T) {
@@ -20,7 +23,7 @@ func TestAuthServer_Register(t *testing.T) {
 	mockAuthService := NewMockAuthService(ctrl)
 	mockJWTGen := NewMockJWTGenerator(ctrl)
 
-	srv := NewAuthServer(mockAuthService, mockJWTGen)
+	srv := NewAuthServer(mockAuthService, mockJWTGen, nil)
 
 	tests := []struct {
 		name        string
@@ -69,7 +72,7 @@ func TestAuthServer_Register(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockAuthService.EXPECT().
-				Register(gomock.Any(), tt.username, tt.password).
+				Register(gomock.Any(), tt.username, tt.password, nil).
 				Return(tt.registerErr).
 				Times(1)
 
@@ -96,6 +99,52 @@ func TestAuthServer_Register(t *testing.T) {
 	}
 }
 
+func TestAuthServer_MasterPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := NewMockAuthService(ctrl)
+	mockJWTGen := NewMockJWTGenerator(ctrl)
+	srv := NewAuthServer(mockAuthService, mockJWTGen, nil)
+
+	ctx := context.Background()
+	params := &models.KDFParams{Algorithm: models.KDFAlgorithmArgon2id, Salt: []byte("0123456789abcdef"), Time: 3, Memory: 65536, Threads: 4}
+	pbParams := &pb.KDFParams{Algorithm: params.Algorithm, Salt: params.Salt, Time: params.Time, Memory: params.Memory, Threads: uint32(params.Threads)}
+
+	t.Run("register", func(t *testing.T) {
+		mockAuthService.EXPECT().Register(ctx, "alice", "auth-key", params).Return(nil)
+		mockJWTGen.EXPECT().Generate("alice").Return("token", nil)
+
+		resp, err := srv.Register(ctx, &pb.AuthRequest{Username: "alice", Password: "auth-key", Kdf: pbParams})
+		require.NoError(t, err)
+		assert.Equal(t, "token", resp.GetToken())
+	})
+
+	t.Run("register with invalid parameters", func(t *testing.T) {
+		mockAuthService.EXPECT().Register(ctx, "alice", "auth-key", params).Return(services.ErrInvalidKDFParams)
+
+		_, err := srv.Register(ctx, &pb.AuthRequest{Username: "alice", Password: "auth-key", Kdf: pbParams})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = srv.Register(ctx, &pb.AuthRequest{Username: "alice", Password: "auth-key", Kdf: &pb.KDFParams{Threads: 1000}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("get parameters", func(t *testing.T) {
+		mockAuthService.EXPECT().GetKDFParams(ctx, "alice").Return(params, nil)
+		mockAuthService.EXPECT().GetKDFParams(ctx, "bob").Return(nil, services.ErrNoKDFParams)
+
+		resp, err := srv.GetKDFParams(ctx, &pb.KDFParamsRequest{Username: "alice"})
+		require.NoError(t, err)
+		assert.Equal(t, pbParams.GetSalt(), resp.GetSalt())
+		assert.Equal(t, pbParams.GetMemory(), resp.GetMemory())
+		assert.Equal(t, pbParams.GetThreads(), resp.GetThreads())
+
+		_, err = srv.GetKDFParams(ctx, &pb.KDFParamsRequest{Username: "bob"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
 func TestAuthServer_Login(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -103,13 +152,15 @@ func TestAuthServer_Login(t *testing.T) {
 	mockAuthService := NewMockAuthService(ctrl)
 	mockJWTGen := NewMockJWTGenerator(ctrl)
 
-	srv := NewAuthServer(mockAuthService, mockJWTGen)
+	srv := NewAuthServer(mockAuthService, mockJWTGen, nil)
 
 	tests := []struct {
 		name        string
 		username    string
 		password    string
+		otpCode     string
 		authErr     error
+		otpErr      error
 		jwtToken    string
 		jwtGenErr   error
 		wantErrCode codes.Code
@@ -147,6 +198,30 @@ func TestAuthServer_Login(t *testing.T) {
 			jwtGenErr:   errors.New("jwt error"),
 			wantErrCode: codes.Internal,
 		},
+		{
+			name:        "one-time code required",
+			username:    "user1",
+			password:    "pass1",
+			otpErr:      models.ErrOTPRequired,
+			wantErrCode: codes.Unauthenticated,
+		},
+		{
+			name:        "invalid one-time code",
+			username:    "user1",
+			password:    "pass1",
+			otpCode:     "000000",
+			otpErr:      services.ErrInvalidOTP,
+			wantErrCode: codes.Unauthenticated,
+		},
+		{
+			name:        "successful login with one-time code",
+			username:    "user1",
+			password:    "pass1",
+			otpCode:     "123456",
+			jwtToken:    "token123",
+			wantErrCode: codes.OK,
+			wantToken:   "token123",
+		},
 	}
 
 	for _, tt := range tests {
@@ -157,13 +232,20 @@ func TestAuthServer_Login(t *testing.T) {
 				Times(1)
 
 			if tt.authErr == nil {
+				mockAuthService.EXPECT().
+					VerifyOTP(gomock.Any(), tt.username, tt.otpCode).
+					Return(tt.otpErr).
+					Times(1)
+			}
+
+			if tt.authErr == nil && tt.otpErr == nil {
 				mockJWTGen.EXPECT().
 					Generate(tt.username).
 					Return(tt.jwtToken, tt.jwtGenErr).
 					Times(1)
 			}
 
-			req := &pb.AuthRequest{Username: tt.username, Password: tt.password}
+			req := &pb.AuthRequest{Username: tt.username, Password: tt.password, OtpCode: tt.otpCode}
 			resp, err := srv.Login(context.Background(), req)
 
 			if tt.wantErrCode == codes.OK {
@@ -178,3 +260,195 @@ func TestAuthServer_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthServer_EnrollOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := NewMockAuthService(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+
+	srv := NewAuthServer(mockAuthService, nil, mockParser)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		mockSetup   func()
+		wantErrCode codes.Code
+	}{
+		{
+			name: "success",
+			ctx:  contextWithAuthToken("validtoken"),
+			mockSetup: func() {
+				mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+				mockAuthService.EXPECT().EnrollOTP(gomock.Any(), "user1").Return(&models.OTPEnrollment{
+					URI:           "otpauth://totp/GophKeeper:user1?secret=ABC",
+					RecoveryCodes: []string{"aaaaa-bbbbb"},
+				}, nil)
+			},
+			wantErrCode: codes.OK,
+		},
+		{
+			name:        "missing token",
+			ctx:         context.Background(),
+			mockSetup:   func() {},
+			wantErrCode: codes.Unauthenticated,
+		},
+		{
+			name: "already enabled",
+			ctx:  contextWithAuthToken("validtoken"),
+			mockSetup: func() {
+				mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+				mockAuthService.EXPECT().EnrollOTP(gomock.Any(), "user1").Return(nil, services.ErrOTPAlreadyEnabled)
+			},
+			wantErrCode: codes.FailedPrecondition,
+		},
+		{
+			name: "internal error",
+			ctx:  contextWithAuthToken("validtoken"),
+			mockSetup: func() {
+				mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+				mockAuthService.EXPECT().EnrollOTP(gomock.Any(), "user1").Return(nil, errors.New("db error"))
+			},
+			wantErrCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			resp, err := srv.EnrollOTP(tt.ctx, &emptypb.Empty{})
+			if tt.wantErrCode == codes.OK {
+				assert.NoError(t, err)
+				assert.Equal(t, "otpauth://totp/GophKeeper:user1?secret=ABC", resp.GetUri())
+				assert.Equal(t, []string{"aaaaa-bbbbb"}, resp.GetRecoveryCodes())
+			} else {
+				st, ok := status.FromError(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantErrCode, st.Code())
+			}
+		})
+	}
+}
+
+func TestAuthServer_ConfirmOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := NewMockAuthService(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+
+	srv := NewAuthServer(mockAuthService, nil, mockParser)
+
+	tests := []struct {
+		name        string
+		confirmErr  error
+		wantErrCode codes.Code
+	}{
+		{name: "success", wantErrCode: codes.OK},
+		{name: "invalid code", confirmErr: services.ErrInvalidOTP, wantErrCode: codes.InvalidArgument},
+		{name: "not enrolled", confirmErr: services.ErrOTPNotEnrolled, wantErrCode: codes.FailedPrecondition},
+		{name: "internal error", confirmErr: errors.New("db error"), wantErrCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+			mockAuthService.EXPECT().ConfirmOTP(gomock.Any(), "user1", "123456").Return(tt.confirmErr)
+
+			_, err := srv.ConfirmOTP(contextWithAuthToken("validtoken"), &pb.OTPConfirmRequest{Code: "123456"})
+			if tt.wantErrCode == codes.OK {
+				assert.NoError(t, err)
+			} else {
+				st, ok := status.FromError(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantErrCode, st.Code())
+			}
+		})
+	}
+}
+
+func TestAuthServer_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := NewMockAuthService(ctrl)
+	mockJWTGen := NewMockJWTGenerator(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+
+	srv := NewAuthServer(mockAuthService, mockJWTGen, mockParser)
+
+	tests := []struct {
+		name        string
+		changeErr   error
+		wantErrCode codes.Code
+	}{
+		{name: "success", wantErrCode: codes.OK},
+		{name: "wrong password", changeErr: services.ErrInvalidPassword, wantErrCode: codes.Unauthenticated},
+		{name: "not configured", changeErr: services.ErrAccountNotConfigured, wantErrCode: codes.Unimplemented},
+		{name: "internal error", changeErr: errors.New("db error"), wantErrCode: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+			mockAuthService.EXPECT().ChangePassword(gomock.Any(), "user1", "old", "new").Return(tt.changeErr)
+			if tt.changeErr == nil {
+				mockJWTGen.EXPECT().Generate("user1").Return("newtoken", nil)
+			}
+
+			resp, err := srv.ChangePassword(
+				contextWithAuthToken("validtoken"),
+				&pb.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			)
+			if tt.wantErrCode == codes.OK {
+				require.NoError(t, err)
+				assert.Equal(t, "newtoken", resp.GetToken())
+			} else {
+				st, ok := status.FromError(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantErrCode, st.Code())
+			}
+		})
+	}
+}
+
+func TestAuthServer_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthService := NewMockAuthService(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+
+	srv := NewAuthServer(mockAuthService, nil, mockParser)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		deleteErr   error
+		wantErrCode codes.Code
+	}{
+		{name: "success", ctx: contextWithAuthToken("validtoken"), wantErrCode: codes.OK},
+		{name: "wrong password", ctx: contextWithAuthToken("validtoken"), deleteErr: services.ErrInvalidPassword, wantErrCode: codes.Unauthenticated},
+		{name: "missing token", ctx: context.Background(), wantErrCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.ctx != context.Background() {
+				mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+				mockAuthService.EXPECT().DeleteAccount(gomock.Any(), "user1", "pass").Return(tt.deleteErr)
+			}
+
+			_, err := srv.DeleteAccount(tt.ctx, &pb.DeleteAccountRequest{Password: "pass"})
+			if tt.wantErrCode == codes.OK {
+				assert.NoError(t, err)
+			} else {
+				st, ok := status.FromError(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantErrCode, st.Code())
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// OrganizationManager defines the interface for managing organizations, their members and vaults.
+type OrganizationManager interface {
+	CreateOrganization(ctx context.Context, username, name string) error
+	DeleteOrganization(ctx context.Context, username, name string) error
+	ListOrganizations(ctx context.Context, username string) ([]*models.Membership, error)
+	SetMember(ctx context.Context, username, organization, member, role string) error
+	RemoveMember(ctx context.Context, username, organization, member string) error
+	ListMembers(ctx context.Context, username, organization string) ([]*models.Membership, error)
+	CreateVault(ctx context.Context, username, organization, name string) error
+	DeleteVault(ctx context.Context, username, organization, name string) error
+	ListVaults(ctx context.Context, username, organization string) ([]*models.Vault, error)
+}
+
+// OrganizationServer implements the OrganizationService gRPC interface.
+type OrganizationServer struct {
+	pb.UnimplementedOrganizationServiceServer
+
+	orgs   OrganizationManager
+	parser JWTParser
+}
+
+// NewOrganizationServer creates a new OrganizationServer instance.
+func NewOrganizationServer(orgs OrganizationManager, parser JWTParser) *OrganizationServer {
+	return &OrganizationServer{
+		orgs:   orgs,
+		parser: parser,
+	}
+}
+
+// CreateOrganization creates an organization owned by the authenticated user.
+func (s *OrganizationServer) CreateOrganization(ctx context.Context, req *pb.OrganizationRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.orgs.CreateOrganization(ctx, username, req.GetOrganization()); err != nil {
+		return nil, organizationError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteOrganization deletes an organization owned by the authenticated user.
+func (s *OrganizationServer) DeleteOrganization(ctx context.Context, req *pb.OrganizationRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.orgs.DeleteOrganization(ctx, username, req.GetOrganization()); err != nil {
+		return nil, organizationError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListOrganizations returns the memberships of the authenticated user.
+func (s *OrganizationServer) ListOrganizations(ctx context.Context, _ *emptypb.Empty) (*pb.MembershipListResponse, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	memberships, err := s.orgs.ListOrganizations(ctx, username)
+	if err != nil {
+		return nil, organizationError(err)
+	}
+
+	return membershipsToPB(memberships), nil
+}
+
+// SetMember adds a member to an organization or changes their role.
+func (s *OrganizationServer) SetMember(ctx context.Context, req *pb.SetMemberRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.orgs.SetMember(ctx, username, req.GetOrganization(), req.GetUsername(), req.GetRole()); err != nil {
+		return nil, organizationError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// RemoveMember removes a member from an organization.
+func (s *OrganizationServer) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.orgs.RemoveMember(ctx, username, req.GetOrganization(), req.GetUsername()); err != nil {
+		return nil, organizationError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListMembers returns the members of an organization the authenticated user belongs to.
+func (s *OrganizationServer) ListMembers(ctx context.Context, req *pb.OrganizationRequest) (*pb.MembershipListResponse, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	memberships, err := s.orgs.ListMembers(ctx, username, req.GetOrganization())
+	if err != nil {
+		return nil, organizationError(err)
+	}
+
+	return membershipsToPB(memberships), nil
+}
+
+// CreateVault creates a vault in an organization.
+func (s *OrganizationServer) CreateVault(ctx context.Context, req *pb.VaultRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.orgs.CreateVault(ctx, username, req.GetOrganization(), req.GetVault()); err != nil {
+		return nil, organizationError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// DeleteVault deletes a vault of an organization with all its secrets.
+func (s *OrganizationServer) DeleteVault(ctx context.Context, req *pb.VaultRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.orgs.DeleteVault(ctx, username, req.GetOrganization(), req.GetVault()); err != nil {
+		return nil, organizationError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListVaults returns the vaults of an organization the authenticated user belongs to.
+func (s *OrganizationServer) ListVaults(ctx context.Context, req *pb.OrganizationRequest) (*pb.VaultListResponse, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	vaults, err := s.orgs.ListVaults(ctx, username, req.GetOrganization())
+	if err != nil {
+		return nil, organizationError(err)
+	}
+
+	resp := &pb.VaultListResponse{Vaults: make([]*pb.Vault, 0, len(vaults))}
+	for _, v := range vaults {
+		resp.Vaults = append(resp.Vaults, &pb.Vault{
+			Organization: v.Organization,
+			Name:         v.Name,
+			CreatedAt:    timestamppb.New(v.CreatedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+// membershipsToPB converts memberships to the protobuf representation.
+func membershipsToPB(in []*models.Membership) *pb.MembershipListResponse {
+	resp := &pb.MembershipListResponse{Memberships: make([]*pb.Membership, 0, len(in))}
+	for _, m := range in {
+		resp.Memberships = append(resp.Memberships, &pb.Membership{
+			Organization: m.Organization,
+			Username:     m.Username,
+			Role:         m.Role,
+			CreatedAt:    timestamppb.New(m.CreatedAt),
+			UpdatedAt:    timestamppb.New(m.UpdatedAt),
+		})
+	}
+	return resp
+}
+
+// organizationError maps errors of organization management to gRPC statuses.
+func organizationError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidName),
+		errors.Is(err, services.ErrInvalidRole),
+		errors.Is(err, services.ErrInvalidVault):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrOrganizationExists),
+		errors.Is(err, services.ErrVaultExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, services.ErrNotMember),
+		errors.Is(err, services.ErrInsufficientRole):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrVaultNotFound),
+		errors.Is(err, services.ErrUserNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrLastOwner):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
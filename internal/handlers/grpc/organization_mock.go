@@ -0,0 +1,165 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/handlers/grpc/organization.go
+
+// Package grpc is a generated GoMock package.
+package grpc
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockOrganizationManager is a mock of OrganizationManager interface.
+type MockOrganizationManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationManagerMockRecorder
+}
+
+// MockOrganizationManagerMockRecorder is the mock recorder for MockOrganizationManager.
+type MockOrganizationManagerMockRecorder struct {
+	mock *MockOrganizationManager
+}
+
+// NewMockOrganizationManager creates a new mock instance.
+func NewMockOrganizationManager(ctrl *gomock.Controller) *MockOrganizationManager {
+	mock := &MockOrganizationManager{ctrl: ctrl}
+	mock.recorder = &MockOrganizationManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationManager) EXPECT() *MockOrganizationManagerMockRecorder {
+	return m.recorder
+}
+
+// CreateOrganization mocks base method.
+func (m *MockOrganizationManager) CreateOrganization(ctx context.Context, username, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, username, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockOrganizationManagerMockRecorder) CreateOrganization(ctx, username, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockOrganizationManager)(nil).CreateOrganization), ctx, username, name)
+}
+
+// CreateVault mocks base method.
+func (m *MockOrganizationManager) CreateVault(ctx context.Context, username, organization, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVault", ctx, username, organization, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVault indicates an expected call of CreateVault.
+func (mr *MockOrganizationManagerMockRecorder) CreateVault(ctx, username, organization, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVault", reflect.TypeOf((*MockOrganizationManager)(nil).CreateVault), ctx, username, organization, name)
+}
+
+// DeleteOrganization mocks base method.
+func (m *MockOrganizationManager) DeleteOrganization(ctx context.Context, username, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganization", ctx, username, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganization indicates an expected call of DeleteOrganization.
+func (mr *MockOrganizationManagerMockRecorder) DeleteOrganization(ctx, username, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockOrganizationManager)(nil).DeleteOrganization), ctx, username, name)
+}
+
+// DeleteVault mocks base method.
+func (m *MockOrganizationManager) DeleteVault(ctx context.Context, username, organization, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVault", ctx, username, organization, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVault indicates an expected call of DeleteVault.
+func (mr *MockOrganizationManagerMockRecorder) DeleteVault(ctx, username, organization, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockOrganizationManager)(nil).DeleteVault), ctx, username, organization, name)
+}
+
+// ListMembers mocks base method.
+func (m *MockOrganizationManager) ListMembers(ctx context.Context, username, organization string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, username, organization)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockOrganizationManagerMockRecorder) ListMembers(ctx, username, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockOrganizationManager)(nil).ListMembers), ctx, username, organization)
+}
+
+// ListOrganizations mocks base method.
+func (m *MockOrganizationManager) ListOrganizations(ctx context.Context, username string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizations", ctx, username)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizations indicates an expected call of ListOrganizations.
+func (mr *MockOrganizationManagerMockRecorder) ListOrganizations(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizations", reflect.TypeOf((*MockOrganizationManager)(nil).ListOrganizations), ctx, username)
+}
+
+// ListVaults mocks base method.
+func (m *MockOrganizationManager) ListVaults(ctx context.Context, username, organization string) ([]*models.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaults", ctx, username, organization)
+	ret0, _ := ret[0].([]*models.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaults indicates an expected call of ListVaults.
+func (mr *MockOrganizationManagerMockRecorder) ListVaults(ctx, username, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaults", reflect.TypeOf((*MockOrganizationManager)(nil).ListVaults), ctx, username, organization)
+}
+
+// RemoveMember mocks base method.
+func (m *MockOrganizationManager) RemoveMember(ctx context.Context, username, organization, member string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, username, organization, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockOrganizationManagerMockRecorder) RemoveMember(ctx, username, organization, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockOrganizationManager)(nil).RemoveMember), ctx, username, organization, member)
+}
+
+// SetMember mocks base method.
+func (m *MockOrganizationManager) SetMember(ctx context.Context, username, organization, member, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, username, organization, member, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockOrganizationManagerMockRecorder) SetMember(ctx, username, organization, member, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockOrganizationManager)(nil).SetMember), ctx, username, organization, member, role)
+}
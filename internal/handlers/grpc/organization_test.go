@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestOrganizationServer_Organizations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrgs := NewMockOrganizationManager(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewOrganizationServer(mockOrgs, mockParser)
+
+	ctx := contextWithAuthToken("token")
+	now := time.Now()
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().CreateOrganization(gomock.Any(), "alice", "acme").Return(nil)
+	_, err := srv.CreateOrganization(ctx, &pb.OrganizationRequest{Organization: "acme"})
+	require.NoError(t, err)
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().CreateOrganization(gomock.Any(), "alice", "acme").Return(services.ErrOrganizationExists)
+	_, err = srv.CreateOrganization(ctx, &pb.OrganizationRequest{Organization: "acme"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().ListOrganizations(gomock.Any(), "alice").Return([]*models.Membership{
+		{Organization: "acme", Username: "alice", Role: models.RoleOwner, CreatedAt: now, UpdatedAt: now},
+	}, nil)
+	resp, err := srv.ListOrganizations(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetMemberships(), 1)
+	assert.Equal(t, models.RoleOwner, resp.GetMemberships()[0].GetRole())
+
+	mockParser.EXPECT().Parse("token").Return("bob", nil)
+	mockOrgs.EXPECT().DeleteOrganization(gomock.Any(), "bob", "acme").Return(services.ErrInsufficientRole)
+	_, err = srv.DeleteOrganization(ctx, &pb.OrganizationRequest{Organization: "acme"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = srv.CreateOrganization(context.Background(), &pb.OrganizationRequest{Organization: "acme"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestOrganizationServer_Members(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrgs := NewMockOrganizationManager(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewOrganizationServer(mockOrgs, mockParser)
+
+	ctx := contextWithAuthToken("token")
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().SetMember(gomock.Any(), "alice", "acme", "bob", models.RoleAdmin).Return(nil)
+	_, err := srv.SetMember(ctx, &pb.SetMemberRequest{Organization: "acme", Username: "bob", Role: models.RoleAdmin})
+	require.NoError(t, err)
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().SetMember(gomock.Any(), "alice", "acme", "bob", "root").Return(services.ErrInvalidRole)
+	_, err = srv.SetMember(ctx, &pb.SetMemberRequest{Organization: "acme", Username: "bob", Role: "root"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().RemoveMember(gomock.Any(), "alice", "acme", "alice").Return(services.ErrLastOwner)
+	_, err = srv.RemoveMember(ctx, &pb.RemoveMemberRequest{Organization: "acme", Username: "alice"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().ListMembers(gomock.Any(), "alice", "acme").Return([]*models.Membership{
+		{Organization: "acme", Username: "alice", Role: models.RoleOwner},
+		{Organization: "acme", Username: "bob", Role: models.RoleAdmin},
+	}, nil)
+	resp, err := srv.ListMembers(ctx, &pb.OrganizationRequest{Organization: "acme"})
+	require.NoError(t, err)
+	assert.Len(t, resp.GetMemberships(), 2)
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().ListMembers(gomock.Any(), "alice", "acme").Return(nil, errors.New("db down"))
+	_, err = srv.ListMembers(ctx, &pb.OrganizationRequest{Organization: "acme"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestOrganizationServer_Vaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrgs := NewMockOrganizationManager(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewOrganizationServer(mockOrgs, mockParser)
+
+	ctx := contextWithAuthToken("token")
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().CreateVault(gomock.Any(), "alice", "acme", "ops").Return(nil)
+	_, err := srv.CreateVault(ctx, &pb.VaultRequest{Organization: "acme", Vault: "ops"})
+	require.NoError(t, err)
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().DeleteVault(gomock.Any(), "alice", "acme", "gone").Return(services.ErrVaultNotFound)
+	_, err = srv.DeleteVault(ctx, &pb.VaultRequest{Organization: "acme", Vault: "gone"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockOrgs.EXPECT().ListVaults(gomock.Any(), "alice", "acme").Return([]*models.Vault{
+		{Organization: "acme", Name: "ops"},
+	}, nil)
+	resp, err := srv.ListVaults(ctx, &pb.OrganizationRequest{Organization: "acme"})
+	require.NoError(t, err)
+	require.Len(t, resp.GetVaults(), 1)
+	assert.Equal(t, "ops", resp.GetVaults()[0].GetName())
+}
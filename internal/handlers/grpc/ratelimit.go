@@ -0,0 +1,153 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// LoginLimiter tracks failed authentication attempts and locks out abusive clients.
+type LoginLimiter interface {
+	// Check reports whether the attempt may proceed and, if not, how long to wait.
+	Check(ip, username string) (time.Duration, bool)
+	// Fail registers a failed attempt.
+	Fail(ctx context.Context, ip, username string)
+	// Succeed registers a successful attempt.
+	Succeed(ip, username string)
+}
+
+// attemptKey is the context key of the outcome a handler sets with markNeutral or markFailed.
+type attemptKey struct{}
+
+// attempt is the outcome of a call as reported by its handler, overriding the one of its result.
+type attempt int
+
+const (
+	attemptByResult attempt = iota
+	attemptNeutral
+	attemptFailed
+)
+
+// markNeutral tells the rate limit interceptor that the call neither failed nor succeeded,
+// like the first step of a login that asks for the one-time code.
+func markNeutral(ctx context.Context) {
+	setAttempt(ctx, attemptNeutral)
+}
+
+// markFailed tells the rate limit interceptor that the call is a failed attempt whatever its result,
+// like a wrong one-time code, which is an invalid argument rather than an authentication failure.
+func markFailed(ctx context.Context) {
+	setAttempt(ctx, attemptFailed)
+}
+
+func setAttempt(ctx context.Context, outcome attempt) {
+	if a, ok := ctx.Value(attemptKey{}).(*attempt); ok {
+		*a = outcome
+	}
+}
+
+// NewRateLimitInterceptor returns a unary interceptor that protects the given methods from brute force.
+//
+// Calls from a locked client IP or for a locked username fail with ResourceExhausted.
+// Unauthenticated and AlreadyExists results count as failed attempts unless the handler marked
+// them neutral, successful calls reset the username state. Other methods pass through untouched.
+func NewRateLimitInterceptor(limiter LoginLimiter, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		guarded[m] = struct{}{}
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if _, ok := guarded[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+
+		var username string
+		if r, ok := req.(interface{ GetUsername() string }); ok {
+			username = r.GetUsername()
+		}
+		return guard(ctx, limiter, req, handler, username)
+	}
+}
+
+// NewTokenRateLimitInterceptor returns a unary interceptor that protects the given methods
+// re-checking the password of an authenticated user, like password changes, from brute force
+// with a stolen token.
+//
+// The username is taken from the bearer token. Calls without a valid token are passed on
+// untouched, the handler rejects them without counting a failed attempt. Otherwise calls are
+// limited like those of NewRateLimitInterceptor, with handlers marking every failure but a wrong
+// password or one-time code neutral.
+func NewTokenRateLimitInterceptor(limiter LoginLimiter, parser JWTParser, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]struct{}, len(methods))
+	for _, m := range methods {
+		guarded[m] = struct{}{}
+	}
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if _, ok := guarded[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+
+		username, err := usernameFromContext(ctx, parser)
+		if err != nil {
+			return handler(ctx, req)
+		}
+		return guard(ctx, limiter, req, handler, username)
+	}
+}
+
+// guard calls handler unless the peer IP or username is locked, and registers the outcome.
+func guard(ctx context.Context, limiter LoginLimiter, req interface{}, handler grpc.UnaryHandler, username string) (interface{}, error) {
+	ip := peerIP(ctx)
+
+	if wait, ok := limiter.Check(ip, username); !ok {
+		return nil, status.Error(
+			codes.ResourceExhausted,
+			fmt.Sprintf("too many failed attempts, try again in %ds", int(math.Ceil(wait.Seconds()))),
+		)
+	}
+
+	outcome := new(attempt)
+	resp, err := handler(context.WithValue(ctx, attemptKey{}, outcome), req)
+
+	switch code := status.Code(err); {
+	case *outcome == attemptNeutral:
+	case *outcome == attemptFailed, code == codes.Unauthenticated, code == codes.AlreadyExists:
+		limiter.Fail(ctx, ip, username)
+	case code == codes.OK:
+		limiter.Succeed(ip, username)
+	}
+
+	return resp, err
+}
+
+// peerIP returns the host part of the calling peer address.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,75 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/handlers/grpc/ratelimit.go
+
+// Package grpc is a generated GoMock package.
+package grpc
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLoginLimiter is a mock of LoginLimiter interface.
+type MockLoginLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginLimiterMockRecorder
+}
+
+// MockLoginLimiterMockRecorder is the mock recorder for MockLoginLimiter.
+type MockLoginLimiterMockRecorder struct {
+	mock *MockLoginLimiter
+}
+
+// NewMockLoginLimiter creates a new mock instance.
+func NewMockLoginLimiter(ctrl *gomock.Controller) *MockLoginLimiter {
+	mock := &MockLoginLimiter{ctrl: ctrl}
+	mock.recorder = &MockLoginLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginLimiter) EXPECT() *MockLoginLimiterMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockLoginLimiter) Check(ip, username string) (time.Duration, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ip, username)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockLoginLimiterMockRecorder) Check(ip, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockLoginLimiter)(nil).Check), ip, username)
+}
+
+// Fail mocks base method.
+func (m *MockLoginLimiter) Fail(ctx context.Context, ip, username string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Fail", ctx, ip, username)
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockLoginLimiterMockRecorder) Fail(ctx, ip, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockLoginLimiter)(nil).Fail), ctx, ip, username)
+}
+
+// Succeed mocks base method.
+func (m *MockLoginLimiter) Succeed(ip, username string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Succeed", ip, username)
+}
+
+// Succeed indicates an expected call of Succeed.
+func (mr *MockLoginLimiterMockRecorder) Succeed(ip, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Succeed", reflect.TypeOf((*MockLoginLimiter)(nil).Succeed), ip, username)
+}
@@ -0,0 +1,225 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitInterceptor(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234},
+	})
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Login_FullMethodName}
+	req := &pb.AuthRequest{Username: "alice", Password: "pass"}
+
+	tests := []struct {
+		name        string
+		info        *grpc.UnaryServerInfo
+		handlerErr  error
+		neutral     bool
+		failed      bool
+		wantErrCode codes.Code
+		mockSetup   func(l *MockLoginLimiter)
+	}{
+		{
+			name:        "success resets username",
+			info:        info,
+			wantErrCode: codes.OK,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Succeed("192.0.2.1", "alice")
+			},
+		},
+		{
+			name:        "unauthenticated counts as failure",
+			info:        info,
+			handlerErr:  status.Error(codes.Unauthenticated, "invalid username or password"),
+			wantErrCode: codes.Unauthenticated,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Fail(gomock.Any(), "192.0.2.1", "alice")
+			},
+		},
+		{
+			name:        "one-time code request is neutral",
+			info:        info,
+			handlerErr:  status.Error(codes.Unauthenticated, "one-time code required"),
+			neutral:     true,
+			wantErrCode: codes.Unauthenticated,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+			},
+		},
+		{
+			name:        "wrong one-time code counts as failure",
+			info:        info,
+			handlerErr:  status.Error(codes.InvalidArgument, "invalid one-time code"),
+			failed:      true,
+			wantErrCode: codes.InvalidArgument,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Fail(gomock.Any(), "192.0.2.1", "alice")
+			},
+		},
+		{
+			name:        "internal error is neutral",
+			info:        info,
+			handlerErr:  status.Error(codes.Internal, "internal server error"),
+			wantErrCode: codes.Internal,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+			},
+		},
+		{
+			name:        "locked out",
+			info:        info,
+			wantErrCode: codes.ResourceExhausted,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Minute, false)
+			},
+		},
+		{
+			name:        "unguarded method passes through",
+			info:        &grpc.UnaryServerInfo{FullMethod: pb.SecretReadService_List_FullMethodName},
+			wantErrCode: codes.OK,
+			mockSetup:   func(l *MockLoginLimiter) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limiter := NewMockLoginLimiter(ctrl)
+			tt.mockSetup(limiter)
+
+			interceptor := NewRateLimitInterceptor(
+				limiter,
+				pb.AuthService_Login_FullMethodName,
+				pb.AuthService_Register_FullMethodName,
+			)
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				if tt.neutral {
+					markNeutral(ctx)
+				}
+				if tt.failed {
+					markFailed(ctx)
+				}
+				if tt.handlerErr != nil {
+					return nil, tt.handlerErr
+				}
+				return &pb.AuthResponse{Token: "token"}, nil
+			}
+
+			_, err := interceptor(ctx, req, tt.info, handler)
+			assert.Equal(t, tt.wantErrCode, status.Code(err))
+			assert.Equal(t, tt.wantErrCode != codes.ResourceExhausted, called)
+		})
+	}
+}
+
+func TestTokenRateLimitInterceptor(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234},
+	})
+	withToken := func(token string) context.Context {
+		return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_ChangePassword_FullMethodName}
+	req := &pb.ChangePasswordRequest{OldPassword: "wrong", NewPassword: "new"}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		handlerErr  error
+		wantErrCode codes.Code
+		mockSetup   func(l *MockLoginLimiter, p *MockJWTParser)
+	}{
+		{
+			name:        "wrong password counts as failure of the token user",
+			ctx:         withToken("token"),
+			handlerErr:  status.Error(codes.Unauthenticated, "invalid password"),
+			wantErrCode: codes.Unauthenticated,
+			mockSetup: func(l *MockLoginLimiter, p *MockJWTParser) {
+				p.EXPECT().Parse("token").Return("alice", nil)
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Fail(gomock.Any(), "192.0.2.1", "alice")
+			},
+		},
+		{
+			name:        "locked out",
+			ctx:         withToken("token"),
+			wantErrCode: codes.ResourceExhausted,
+			mockSetup: func(l *MockLoginLimiter, p *MockJWTParser) {
+				p.EXPECT().Parse("token").Return("alice", nil)
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Minute, false)
+			},
+		},
+		{
+			name:        "invalid token is not counted",
+			ctx:         withToken("expired"),
+			handlerErr:  status.Error(codes.Unauthenticated, "token expired"),
+			wantErrCode: codes.Unauthenticated,
+			mockSetup: func(l *MockLoginLimiter, p *MockJWTParser) {
+				p.EXPECT().Parse("expired").Return("", errors.New("token expired"))
+			},
+		},
+		{
+			name:        "missing token is not counted",
+			ctx:         ctx,
+			handlerErr:  status.Error(codes.Unauthenticated, "missing metadata in context"),
+			wantErrCode: codes.Unauthenticated,
+			mockSetup:   func(l *MockLoginLimiter, p *MockJWTParser) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limiter := NewMockLoginLimiter(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(limiter, parser)
+
+			interceptor := NewTokenRateLimitInterceptor(
+				limiter,
+				parser,
+				pb.AuthService_ChangePassword_FullMethodName,
+				pb.AuthService_DeleteAccount_FullMethodName,
+			)
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.handlerErr
+			}
+
+			_, err := interceptor(tt.ctx, req, info, handler)
+			assert.Equal(t, tt.wantErrCode, status.Code(err))
+		})
+	}
+}
+
+func TestPeerIP(t *testing.T) {
+	assert.Equal(t, "", peerIP(context.Background()))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 443},
+	})
+	assert.Equal(t, "2001:db8::1", peerIP(ctx))
+}
@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/sbilibin2017/gophkeeper/internal/requestmeta"
+	"google.golang.org/grpc"
+)
+
+// NewRequestMetaInterceptor returns a unary interceptor that stores the transport
+// and the peer IP in the request context for auditing.
+func NewRequestMetaInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(withRequestMeta(ctx), req)
+	}
+}
+
+// NewRequestMetaStreamInterceptor is the streaming counterpart of NewRequestMetaInterceptor.
+func NewRequestMetaStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &metaServerStream{ServerStream: ss, ctx: withRequestMeta(ss.Context())})
+	}
+}
+
+// metaServerStream overrides the context of a server stream.
+type metaServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *metaServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func withRequestMeta(ctx context.Context) context.Context {
+	ctx = requestmeta.WithTransport(ctx, requestmeta.TransportGRPC)
+	return requestmeta.WithClientIP(ctx, peerIP(ctx))
+}
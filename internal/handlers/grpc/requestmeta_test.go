@@ -0,0 +1,47 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/sbilibin2017/gophkeeper/internal/requestmeta"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+func TestRequestMetaInterceptor(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234},
+	})
+
+	_, err := NewRequestMetaInterceptor()(ctx, nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.Equal(t, requestmeta.TransportGRPC, requestmeta.Transport(ctx))
+			assert.Equal(t, "192.0.2.1", requestmeta.ClientIP(ctx))
+			return nil, nil
+		})
+	assert.NoError(t, err)
+}
+
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context { return s.ctx }
+
+func TestRequestMetaStreamInterceptor(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 1234},
+	})
+
+	err := NewRequestMetaStreamInterceptor()(nil, &fakeServerStream{ctx: ctx}, &grpc.StreamServerInfo{},
+		func(srv interface{}, ss grpc.ServerStream) error {
+			assert.Equal(t, requestmeta.TransportGRPC, requestmeta.Transport(ss.Context()))
+			assert.Equal(t, "192.0.2.1", requestmeta.ClientIP(ss.Context()))
+			return nil
+		})
+	assert.NoError(t, err)
+}
@@ -4,42 +4,58 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
 	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
 
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // SecretWriter defines the interface for writing secrets to storage.
 type SecretWriter interface {
-	// Save stores a secret for a given user.
+	// Save stores a secret for a given user, in an organization vault when vault is set.
 	Save(
 		ctx context.Context,
 		username string,
+		vault string,
 		secretName string,
 		secretType string,
 		ciphertext []byte,
 		aesKeyEnc []byte,
+		keyID string,
+		recipients models.Recipients,
+	) error
+
+	// SaveBatch stores several secrets of a given user atomically.
+	SaveBatch(
+		ctx context.Context,
+		username string,
+		secrets []*models.Secret,
 	) error
 }
 
 // SecretReader defines the interface for reading secrets from storage.
 type SecretReader interface {
-	// Get retrieves a secret by type and name for a given user.
+	// Get retrieves a secret by type and name for a given user, from an organization vault when vault is set.
 	Get(
 		ctx context.Context,
 		username string,
+		vault string,
 		secretType string,
 		secretName string,
 	) (*models.Secret, error)
 
-	// List returns all secrets for a given user.
+	// List returns all secrets for a given user, or of an organization vault when vault is set.
 	List(
 		ctx context.Context,
 		username string,
+		vault string,
 	) ([]*models.Secret, error)
 }
 
@@ -96,8 +112,39 @@ func (s *SecretWriteServer) Save(ctx context.Context, req *pb.SecretSaveRequest)
 		return nil, err
 	}
 
-	if err := s.writer.Save(ctx, username, req.GetSecretName(), req.GetSecretType(), req.GetCiphertext(), req.GetAesKeyEnc()); err != nil {
-		return nil, err
+	if err := s.writer.Save(ctx, username, req.GetVault(), req.GetSecretName(), req.GetSecretType(), req.GetCiphertext(), req.GetAesKeyEnc(), req.GetKeyId(), recipientsFromPB(req.GetRecipients())); err != nil {
+		return nil, vaultError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// SaveBatch handles saving several secrets atomically via gRPC.
+//
+// Either all secrets of the request are stored for the authenticated user or none,
+// which lets clients re-encrypt a whole vault without leaving it half rotated.
+func (s *SecretWriteServer) SaveBatch(ctx context.Context, req *pb.SecretBatchSaveRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	secrets := make([]*models.Secret, 0, len(req.GetSecrets()))
+	for _, r := range req.GetSecrets() {
+		secrets = append(secrets, &models.Secret{
+			SecretName: r.GetSecretName(),
+			SecretType: r.GetSecretType(),
+			Ciphertext: r.GetCiphertext(),
+			AESKeyEnc:  r.GetAesKeyEnc(),
+			KeyID:      r.GetKeyId(),
+			Recipients: recipientsFromPB(r.GetRecipients()),
+			CreatedAt:  timeFromPB(r.GetCreatedAt()),
+			UpdatedAt:  timeFromPB(r.GetUpdatedAt()),
+		})
+	}
+
+	if err := s.writer.SaveBatch(ctx, username, secrets); err != nil {
+		return nil, status.Error(codes.Internal, "failed to save secrets")
 	}
 
 	return &emptypb.Empty{}, nil
@@ -150,9 +197,9 @@ func (s *SecretReadServer) Get(ctx context.Context, req *pb.SecretGetRequest) (*
 		return nil, err
 	}
 
-	secret, err := s.reader.Get(ctx, username, req.GetSecretType(), req.GetSecretName())
+	secret, err := s.reader.Get(ctx, username, req.GetVault(), req.GetSecretType(), req.GetSecretName())
 	if err != nil {
-		return nil, err
+		return nil, vaultError(err)
 	}
 
 	return &pb.Secret{
@@ -161,6 +208,9 @@ func (s *SecretReadServer) Get(ctx context.Context, req *pb.SecretGetRequest) (*
 		SecretOwner: secret.SecretOwner,
 		Ciphertext:  secret.Ciphertext,
 		AesKeyEnc:   secret.AESKeyEnc,
+		KeyId:       secret.KeyID,
+		Recipients:  recipientsToPB(secret.Recipients),
+		Vault:       secret.Vault,
 		CreatedAt:   timestamppb.New(secret.CreatedAt),
 		UpdatedAt:   timestamppb.New(secret.UpdatedAt),
 	}, nil
@@ -170,8 +220,8 @@ func (s *SecretReadServer) Get(ctx context.Context, req *pb.SecretGetRequest) (*
 //
 // It extracts and validates the JWT token from gRPC metadata,
 // extracts the username from the token,
-// then streams all secrets associated with the user.
-func (s *SecretReadServer) List(empty *emptypb.Empty, stream pb.SecretReadService_ListServer) error {
+// then streams all secrets associated with the user or the requested vault.
+func (s *SecretReadServer) List(req *pb.SecretListRequest, stream pb.SecretReadService_ListServer) error {
 	ctx := stream.Context()
 
 	md, ok := metadata.FromIncomingContext(ctx)
@@ -196,9 +246,9 @@ func (s *SecretReadServer) List(empty *emptypb.Empty, stream pb.SecretReadServic
 		return err
 	}
 
-	secrets, err := s.reader.List(ctx, username)
+	secrets, err := s.reader.List(ctx, username, req.GetVault())
 	if err != nil {
-		return err
+		return vaultError(err)
 	}
 
 	for _, secret := range secrets {
@@ -208,6 +258,9 @@ func (s *SecretReadServer) List(empty *emptypb.Empty, stream pb.SecretReadServic
 			SecretOwner: secret.SecretOwner,
 			Ciphertext:  secret.Ciphertext,
 			AesKeyEnc:   secret.AESKeyEnc,
+			KeyId:       secret.KeyID,
+			Recipients:  recipientsToPB(secret.Recipients),
+			Vault:       secret.Vault,
 			CreatedAt:   timestamppb.New(secret.CreatedAt),
 			UpdatedAt:   timestamppb.New(secret.UpdatedAt),
 		}); err != nil {
@@ -217,3 +270,52 @@ func (s *SecretReadServer) List(empty *emptypb.Empty, stream pb.SecretReadServic
 
 	return nil
 }
+
+// vaultError maps errors of organization vault access to gRPC statuses,
+// any other error is returned unchanged.
+func vaultError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidVault):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrNotMember), errors.Is(err, services.ErrInsufficientRole):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrVaultNotFound), errors.Is(err, services.ErrSecretNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrVaultsNotConfigured):
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return err
+	}
+}
+
+// recipientsFromPB converts protobuf recipients to the model representation.
+func recipientsFromPB(in []*pb.Recipient) models.Recipients {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(models.Recipients, 0, len(in))
+	for _, r := range in {
+		out = append(out, models.Recipient{KeyID: r.GetKeyId(), AESKeyEnc: r.GetAesKeyEnc()})
+	}
+	return out
+}
+
+// timeFromPB converts a protobuf timestamp to a time, the zero time when it is unset.
+func timeFromPB(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// recipientsToPB converts model recipients to the protobuf representation.
+func recipientsToPB(in models.Recipients) []*pb.Recipient {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]*pb.Recipient, 0, len(in))
+	for _, r := range in {
+		out = append(out, &pb.Recipient{KeyId: r.KeyID, AesKeyEnc: r.AESKeyEnc})
+	}
+	return out
+}
@@ -36,17 +36,31 @@ func (m *MockSecretWriter) EXPECT() *MockSecretWriterMockRecorder {
 }
 
 // Save mocks base method.
-func (m *MockSecretWriter) Save(ctx context.Context, username, secretName, secretType string, ciphertext, aesKeyEnc []byte) error {
+func (m *MockSecretWriter) Save(ctx context.Context, username, vault, secretName, secretType string, ciphertext, aesKeyEnc []byte, keyID string, recipients models.Recipients) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, username, secretName, secretType, ciphertext, aesKeyEnc)
+	ret := m.ctrl.Call(m, "Save", ctx, username, vault, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockSecretWriterMockRecorder) Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc interface{}) *gomock.Call {
+func (mr *MockSecretWriterMockRecorder) Save(ctx, username, vault, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSecretWriter)(nil).Save), ctx, username, secretName, secretType, ciphertext, aesKeyEnc)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSecretWriter)(nil).Save), ctx, username, vault, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
+}
+
+// SaveBatch mocks base method.
+func (m *MockSecretWriter) SaveBatch(ctx context.Context, username string, secrets []*models.Secret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, username, secrets)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockSecretWriterMockRecorder) SaveBatch(ctx, username, secrets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockSecretWriter)(nil).SaveBatch), ctx, username, secrets)
 }
 
 // MockSecretReader is a mock of SecretReader interface.
@@ -73,33 +87,33 @@ func (m *MockSecretReader) EXPECT() *MockSecretReaderMockRecorder {
 }
 
 // Get mocks base method.
-func (m *MockSecretReader) Get(ctx context.Context, username, secretType, secretName string) (*models.Secret, error) {
+func (m *MockSecretReader) Get(ctx context.Context, username, vault, secretType, secretName string) (*models.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, username, secretType, secretName)
+	ret := m.ctrl.Call(m, "Get", ctx, username, vault, secretType, secretName)
 	ret0, _ := ret[0].(*models.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockSecretReaderMockRecorder) Get(ctx, username, secretType, secretName interface{}) *gomock.Call {
+func (mr *MockSecretReaderMockRecorder) Get(ctx, username, vault, secretType, secretName interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSecretReader)(nil).Get), ctx, username, secretType, secretName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSecretReader)(nil).Get), ctx, username, vault, secretType, secretName)
 }
 
 // List mocks base method.
-func (m *MockSecretReader) List(ctx context.Context, username string) ([]*models.Secret, error) {
+func (m *MockSecretReader) List(ctx context.Context, username, vault string) ([]*models.Secret, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, username)
+	ret := m.ctrl.Call(m, "List", ctx, username, vault)
 	ret0, _ := ret[0].([]*models.Secret)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockSecretReaderMockRecorder) List(ctx, username interface{}) *gomock.Call {
+func (mr *MockSecretReaderMockRecorder) List(ctx, username, vault interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSecretReader)(nil).List), ctx, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSecretReader)(nil).List), ctx, username, vault)
 }
 
 // MockJWTParser is a mock of JWTParser interface.
@@ -8,9 +8,13 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
 	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -34,6 +38,15 @@ func TestSecretWriteServer_Save(t *testing.T) {
 		SecretType: "type1",
 		Ciphertext: []byte("ciphertext"),
 		AesKeyEnc:  []byte("aeskey"),
+		KeyId:      "key-1",
+		Recipients: []*pb.Recipient{
+			{KeyId: "key-1", AesKeyEnc: []byte("aeskey")},
+			{KeyId: "key-2", AesKeyEnc: []byte("aeskey-2")},
+		},
+	}
+	recipients := models.Recipients{
+		{KeyID: "key-1", AESKeyEnc: []byte("aeskey")},
+		{KeyID: "key-2", AESKeyEnc: []byte("aeskey-2")},
 	}
 
 	tests := []struct {
@@ -51,7 +64,7 @@ func TestSecretWriteServer_Save(t *testing.T) {
 			wantErr: false,
 			mockSetup: func() {
 				mockParser.EXPECT().Parse("validtoken").Return("user1", nil).Times(1)
-				mockWriter.EXPECT().Save(gomock.Any(), "user1", req.SecretName, req.SecretType, req.Ciphertext, req.AesKeyEnc).Return(nil).Times(1)
+				mockWriter.EXPECT().Save(gomock.Any(), "user1", "", req.SecretName, req.SecretType, req.Ciphertext, req.AesKeyEnc, req.KeyId, recipients).Return(nil).Times(1)
 			},
 		},
 		{
@@ -96,7 +109,7 @@ func TestSecretWriteServer_Save(t *testing.T) {
 			errContains: "save error",
 			mockSetup: func() {
 				mockParser.EXPECT().Parse("validtoken").Return("user1", nil).Times(1)
-				mockWriter.EXPECT().Save(gomock.Any(), "user1", req.SecretName, req.SecretType, req.Ciphertext, req.AesKeyEnc).Return(errors.New("save error")).Times(1)
+				mockWriter.EXPECT().Save(gomock.Any(), "user1", "", req.SecretName, req.SecretType, req.Ciphertext, req.AesKeyEnc, req.KeyId, recipients).Return(errors.New("save error")).Times(1)
 			},
 		},
 	}
@@ -118,6 +131,67 @@ func TestSecretWriteServer_Save(t *testing.T) {
 	}
 }
 
+func TestSecretWriteServer_SaveBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockSecretWriter(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewSecretWriteServer(mockWriter, mockParser)
+
+	req := &pb.SecretBatchSaveRequest{
+		Secrets: []*pb.SecretSaveRequest{
+			{SecretName: "s1", SecretType: "text", Ciphertext: []byte("c1"), AesKeyEnc: []byte("k1"), KeyId: "key-2"},
+			{SecretName: "s2", SecretType: "user", Ciphertext: []byte("c2"), AesKeyEnc: []byte("k2"), KeyId: "key-2"},
+		},
+	}
+	want := []*models.Secret{
+		{SecretName: "s1", SecretType: "text", Ciphertext: []byte("c1"), AESKeyEnc: []byte("k1"), KeyID: "key-2"},
+		{SecretName: "s2", SecretType: "user", Ciphertext: []byte("c2"), AESKeyEnc: []byte("k2"), KeyID: "key-2"},
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		setup       func()
+		wantErrCode codes.Code
+	}{
+		{
+			name: "success",
+			ctx:  contextWithAuthToken("validtoken"),
+			setup: func() {
+				mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+				mockWriter.EXPECT().SaveBatch(gomock.Any(), "user1", want).Return(nil)
+			},
+			wantErrCode: codes.OK,
+		},
+		{
+			name:        "missing token",
+			ctx:         context.Background(),
+			setup:       func() {},
+			wantErrCode: codes.Unauthenticated,
+		},
+		{
+			name: "save fails",
+			ctx:  contextWithAuthToken("validtoken"),
+			setup: func() {
+				mockParser.EXPECT().Parse("validtoken").Return("user1", nil)
+				mockWriter.EXPECT().SaveBatch(gomock.Any(), "user1", want).Return(errors.New("tx error"))
+			},
+			wantErrCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setup()
+
+			_, err := srv.SaveBatch(tt.ctx, req)
+			assert.Equal(t, tt.wantErrCode, status.Code(err))
+		})
+	}
+}
+
 func TestSecretReadServer_Get(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -145,12 +219,13 @@ func TestSecretReadServer_Get(t *testing.T) {
 			wantErr: false,
 			mockSetup: func() {
 				mockParser.EXPECT().Parse("validtoken").Return("user1", nil).Times(1)
-				mockReader.EXPECT().Get(gomock.Any(), "user1", "type1", "secret1").Return(&models.Secret{
+				mockReader.EXPECT().Get(gomock.Any(), "user1", "", "type1", "secret1").Return(&models.Secret{
 					SecretName:  "secret1",
 					SecretType:  "type1",
 					SecretOwner: "user1",
 					Ciphertext:  []byte("ciphertext"),
 					AESKeyEnc:   []byte("aeskey"),
+					Recipients:  models.Recipients{{KeyID: "key-1", AESKeyEnc: []byte("aeskey")}},
 					CreatedAt:   now,
 					UpdatedAt:   now,
 				}, nil).Times(1)
@@ -198,7 +273,7 @@ func TestSecretReadServer_Get(t *testing.T) {
 			errContains: "not found",
 			mockSetup: func() {
 				mockParser.EXPECT().Parse("validtoken").Return("user1", nil).Times(1)
-				mockReader.EXPECT().Get(gomock.Any(), "user1", "type1", "secret1").Return(nil, errors.New("not found")).Times(1)
+				mockReader.EXPECT().Get(gomock.Any(), "user1", "", "type1", "secret1").Return(nil, errors.New("not found")).Times(1)
 			},
 		},
 	}
@@ -217,6 +292,8 @@ func TestSecretReadServer_Get(t *testing.T) {
 				assert.Equal(t, tt.req.GetSecretName(), secret.SecretName)
 				assert.Equal(t, tt.req.GetSecretType(), secret.SecretType)
 				assert.Equal(t, "user1", secret.SecretOwner)
+				require.Len(t, secret.Recipients, 1)
+				assert.Equal(t, "key-1", secret.Recipients[0].KeyId)
 				// Check timestamps correctly converted
 				assert.True(t, secret.CreatedAt.AsTime().Equal(now))
 				assert.True(t, secret.UpdatedAt.AsTime().Equal(now))
@@ -296,7 +373,7 @@ func TestSecretReadServer_List(t *testing.T) {
 			wantSent: 2,
 			mockSetup: func(stream *mockSecretReadService_ListServer) {
 				mockParser.EXPECT().Parse("validtoken").Return("user1", nil).Times(1)
-				mockReader.EXPECT().List(gomock.Any(), "user1").Return([]*models.Secret{
+				mockReader.EXPECT().List(gomock.Any(), "user1", "").Return([]*models.Secret{
 					{
 						SecretName:  "secret1",
 						SecretType:  "type1",
@@ -365,7 +442,7 @@ func TestSecretReadServer_List(t *testing.T) {
 			errContains: "list error",
 			mockSetup: func(stream *mockSecretReadService_ListServer) {
 				mockParser.EXPECT().Parse("validtoken").Return("user1", nil).Times(1)
-				mockReader.EXPECT().List(gomock.Any(), "user1").Return(nil, errors.New("list error")).Times(1)
+				mockReader.EXPECT().List(gomock.Any(), "user1", "").Return(nil, errors.New("list error")).Times(1)
 			},
 		},
 		{
@@ -378,7 +455,7 @@ func TestSecretReadServer_List(t *testing.T) {
 			errContains: "send error",
 			mockSetup: func(stream *mockSecretReadService_ListServer) {
 				mockParser.EXPECT().Parse("validtoken").Return("user1", nil).Times(1)
-				mockReader.EXPECT().List(gomock.Any(), "user1").Return([]*models.Secret{
+				mockReader.EXPECT().List(gomock.Any(), "user1", "").Return([]*models.Secret{
 					{
 						SecretName:  "secret1",
 						SecretType:  "type1",
@@ -396,7 +473,7 @@ func TestSecretReadServer_List(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mockSetup(tt.stream)
-			err := srv.List(&emptypb.Empty{}, tt.stream)
+			err := srv.List(&pb.SecretListRequest{}, tt.stream)
 			if tt.wantErr {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errContains)
@@ -415,3 +492,36 @@ func TestSecretReadServer_List(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretReadServer_VaultErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReader := NewMockSecretReader(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewSecretReadServer(mockReader, mockParser)
+
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{services.ErrInvalidVault, codes.InvalidArgument},
+		{services.ErrNotMember, codes.PermissionDenied},
+		{services.ErrVaultNotFound, codes.NotFound},
+		{services.ErrVaultsNotConfigured, codes.Unimplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			mockParser.EXPECT().Parse("token").Return("user1", nil)
+			mockReader.EXPECT().Get(gomock.Any(), "user1", "acme/ops", "type1", "secret1").Return(nil, tt.err)
+
+			_, err := srv.Get(contextWithAuthToken("token"), &pb.SecretGetRequest{
+				SecretType: "type1",
+				SecretName: "secret1",
+				Vault:      "acme/ops",
+			})
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+}
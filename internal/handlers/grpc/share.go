@@ -0,0 +1,181 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ShareManager defines the interface for sharing secrets between users.
+type ShareManager interface {
+	Share(
+		ctx context.Context,
+		owner, secretType, secretName, recipient string,
+		aesKeyEnc []byte,
+		keyID, permission string,
+	) error
+	Unshare(ctx context.Context, owner, secretType, secretName, recipient string) error
+	ListSharedWithMe(ctx context.Context, username string) ([]*models.SharedSecret, error)
+	SaveShared(ctx context.Context, username, owner, secretType, secretName string, ciphertext []byte) error
+}
+
+// CertificateManager defines the interface for publishing and reading user certificates.
+type CertificateManager interface {
+	PublishCertificate(ctx context.Context, username, certificate string) error
+	GetCertificate(ctx context.Context, username string) (string, error)
+}
+
+// ShareServer implements the ShareService gRPC interface.
+type ShareServer struct {
+	pb.UnimplementedShareServiceServer
+
+	shares ShareManager
+	certs  CertificateManager
+	parser JWTParser
+}
+
+// NewShareServer creates a new ShareServer instance.
+func NewShareServer(shares ShareManager, certs CertificateManager, parser JWTParser) *ShareServer {
+	return &ShareServer{
+		shares: shares,
+		certs:  certs,
+		parser: parser,
+	}
+}
+
+// PublishCertificate stores the certificate of the authenticated user.
+func (s *ShareServer) PublishCertificate(ctx context.Context, req *pb.PublishCertificateRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.certs.PublishCertificate(ctx, username, req.GetCertificate()); err != nil {
+		return nil, shareError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// GetCertificate returns the published certificate of a user.
+func (s *ShareServer) GetCertificate(ctx context.Context, req *pb.GetCertificateRequest) (*pb.CertificateResponse, error) {
+	if _, err := usernameFromContext(ctx, s.parser); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	cert, err := s.certs.GetCertificate(ctx, req.GetUsername())
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return &pb.CertificateResponse{Certificate: cert}, nil
+}
+
+// Share shares a secret of the authenticated user with another user.
+func (s *ShareServer) Share(ctx context.Context, req *pb.ShareRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	err = s.shares.Share(ctx, username,
+		req.GetSecretType(), req.GetSecretName(), req.GetRecipient(),
+		req.GetAesKeyEnc(), req.GetKeyId(), req.GetPermission(),
+	)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Unshare revokes the access of a recipient to a secret of the authenticated user.
+func (s *ShareServer) Unshare(ctx context.Context, req *pb.UnshareRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if err := s.shares.Unshare(ctx, username, req.GetSecretType(), req.GetSecretName(), req.GetRecipient()); err != nil {
+		return nil, shareError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListSharedWithMe returns the secrets other users share with the authenticated user.
+func (s *ShareServer) ListSharedWithMe(ctx context.Context, _ *emptypb.Empty) (*pb.SharedSecretListResponse, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	secrets, err := s.shares.ListSharedWithMe(ctx, username)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	resp := &pb.SharedSecretListResponse{Secrets: make([]*pb.SharedSecret, 0, len(secrets))}
+	for _, secret := range secrets {
+		resp.Secrets = append(resp.Secrets, &pb.SharedSecret{
+			SecretName:  secret.SecretName,
+			SecretType:  secret.SecretType,
+			SecretOwner: secret.SecretOwner,
+			Ciphertext:  secret.Ciphertext,
+			AesKeyEnc:   secret.AESKeyEnc,
+			KeyId:       secret.KeyID,
+			Permission:  secret.Permission,
+			CreatedAt:   timestamppb.New(secret.CreatedAt),
+			UpdatedAt:   timestamppb.New(secret.UpdatedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+// SaveShared replaces the content of a secret shared read-write with the authenticated user.
+func (s *ShareServer) SaveShared(ctx context.Context, req *pb.SaveSharedRequest) (*emptypb.Empty, error) {
+	username, err := usernameFromContext(ctx, s.parser)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	err = s.shares.SaveShared(ctx, username,
+		req.GetSecretOwner(), req.GetSecretType(), req.GetSecretName(), req.GetCiphertext(),
+	)
+	if err != nil {
+		return nil, shareError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// shareError maps errors of sharing and certificate publishing to gRPC statuses.
+func shareError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidPermission),
+		errors.Is(err, services.ErrShareWithSelf),
+		errors.Is(err, services.ErrInvalidCertificate):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrSecretNotFound),
+		errors.Is(err, services.ErrShareNotFound),
+		errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrNoCertificate):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrRecipientKeyOutdated), errors.Is(err, services.ErrShareStale):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, services.ErrShareReadOnly):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, services.ErrCertNotConfigured):
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,145 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/handlers/grpc/share.go
+
+// Package grpc is a generated GoMock package.
+package grpc
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockShareManager is a mock of ShareManager interface.
+type MockShareManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareManagerMockRecorder
+}
+
+// MockShareManagerMockRecorder is the mock recorder for MockShareManager.
+type MockShareManagerMockRecorder struct {
+	mock *MockShareManager
+}
+
+// NewMockShareManager creates a new mock instance.
+func NewMockShareManager(ctrl *gomock.Controller) *MockShareManager {
+	mock := &MockShareManager{ctrl: ctrl}
+	mock.recorder = &MockShareManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareManager) EXPECT() *MockShareManagerMockRecorder {
+	return m.recorder
+}
+
+// ListSharedWithMe mocks base method.
+func (m *MockShareManager) ListSharedWithMe(ctx context.Context, username string) ([]*models.SharedSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSharedWithMe", ctx, username)
+	ret0, _ := ret[0].([]*models.SharedSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSharedWithMe indicates an expected call of ListSharedWithMe.
+func (mr *MockShareManagerMockRecorder) ListSharedWithMe(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSharedWithMe", reflect.TypeOf((*MockShareManager)(nil).ListSharedWithMe), ctx, username)
+}
+
+// SaveShared mocks base method.
+func (m *MockShareManager) SaveShared(ctx context.Context, username, owner, secretType, secretName string, ciphertext []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveShared", ctx, username, owner, secretType, secretName, ciphertext)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveShared indicates an expected call of SaveShared.
+func (mr *MockShareManagerMockRecorder) SaveShared(ctx, username, owner, secretType, secretName, ciphertext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveShared", reflect.TypeOf((*MockShareManager)(nil).SaveShared), ctx, username, owner, secretType, secretName, ciphertext)
+}
+
+// Share mocks base method.
+func (m *MockShareManager) Share(ctx context.Context, owner, secretType, secretName, recipient string, aesKeyEnc []byte, keyID, permission string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", ctx, owner, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockShareManagerMockRecorder) Share(ctx, owner, secretType, secretName, recipient, aesKeyEnc, keyID, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockShareManager)(nil).Share), ctx, owner, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+}
+
+// Unshare mocks base method.
+func (m *MockShareManager) Unshare(ctx context.Context, owner, secretType, secretName, recipient string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unshare", ctx, owner, secretType, secretName, recipient)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unshare indicates an expected call of Unshare.
+func (mr *MockShareManagerMockRecorder) Unshare(ctx, owner, secretType, secretName, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unshare", reflect.TypeOf((*MockShareManager)(nil).Unshare), ctx, owner, secretType, secretName, recipient)
+}
+
+// MockCertificateManager is a mock of CertificateManager interface.
+type MockCertificateManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificateManagerMockRecorder
+}
+
+// MockCertificateManagerMockRecorder is the mock recorder for MockCertificateManager.
+type MockCertificateManagerMockRecorder struct {
+	mock *MockCertificateManager
+}
+
+// NewMockCertificateManager creates a new mock instance.
+func NewMockCertificateManager(ctrl *gomock.Controller) *MockCertificateManager {
+	mock := &MockCertificateManager{ctrl: ctrl}
+	mock.recorder = &MockCertificateManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificateManager) EXPECT() *MockCertificateManagerMockRecorder {
+	return m.recorder
+}
+
+// GetCertificate mocks base method.
+func (m *MockCertificateManager) GetCertificate(ctx context.Context, username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCertificate", ctx, username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCertificate indicates an expected call of GetCertificate.
+func (mr *MockCertificateManagerMockRecorder) GetCertificate(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCertificate", reflect.TypeOf((*MockCertificateManager)(nil).GetCertificate), ctx, username)
+}
+
+// PublishCertificate mocks base method.
+func (m *MockCertificateManager) PublishCertificate(ctx context.Context, username, certificate string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishCertificate", ctx, username, certificate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishCertificate indicates an expected call of PublishCertificate.
+func (mr *MockCertificateManagerMockRecorder) PublishCertificate(ctx, username, certificate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCertificate", reflect.TypeOf((*MockCertificateManager)(nil).PublishCertificate), ctx, username, certificate)
+}
@@ -0,0 +1,151 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+	pb "github.com/sbilibin2017/gophkeeper/pkg/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestShareServer_Certificates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCerts := NewMockCertificateManager(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewShareServer(nil, mockCerts, mockParser)
+
+	ctx := contextWithAuthToken("token")
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockCerts.EXPECT().PublishCertificate(gomock.Any(), "alice", "pem").Return(nil)
+	_, err := srv.PublishCertificate(ctx, &pb.PublishCertificateRequest{Certificate: "pem"})
+	require.NoError(t, err)
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockCerts.EXPECT().PublishCertificate(gomock.Any(), "alice", "garbage").Return(services.ErrInvalidCertificate)
+	_, err = srv.PublishCertificate(ctx, &pb.PublishCertificateRequest{Certificate: "garbage"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockCerts.EXPECT().GetCertificate(gomock.Any(), "bob").Return("bob-pem", nil)
+	resp, err := srv.GetCertificate(ctx, &pb.GetCertificateRequest{Username: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "bob-pem", resp.GetCertificate())
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockCerts.EXPECT().GetCertificate(gomock.Any(), "carol").Return("", services.ErrNoCertificate)
+	_, err = srv.GetCertificate(ctx, &pb.GetCertificateRequest{Username: "carol"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = srv.GetCertificate(context.Background(), &pb.GetCertificateRequest{Username: "bob"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestShareServer_ShareUnshare(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShares := NewMockShareManager(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewShareServer(mockShares, nil, mockParser)
+
+	ctx := contextWithAuthToken("token")
+	req := &pb.ShareRequest{
+		SecretType: "text",
+		SecretName: "wifi",
+		Recipient:  "bob",
+		AesKeyEnc:  []byte("wrapped"),
+		KeyId:      "bob-id",
+		Permission: models.SharePermissionRead,
+	}
+
+	tests := []struct {
+		name     string
+		shareErr error
+		wantCode codes.Code
+	}{
+		{"success", nil, codes.OK},
+		{"invalid permission", services.ErrInvalidPermission, codes.InvalidArgument},
+		{"secret not found", services.ErrSecretNotFound, codes.NotFound},
+		{"outdated certificate", services.ErrRecipientKeyOutdated, codes.FailedPrecondition},
+		{"internal", errors.New("db error"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockParser.EXPECT().Parse("token").Return("alice", nil)
+			mockShares.EXPECT().
+				Share(gomock.Any(), "alice", "text", "wifi", "bob", []byte("wrapped"), "bob-id", models.SharePermissionRead).
+				Return(tt.shareErr)
+
+			_, err := srv.Share(ctx, req)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+
+	mockParser.EXPECT().Parse("token").Return("alice", nil)
+	mockShares.EXPECT().Unshare(gomock.Any(), "alice", "text", "wifi", "bob").Return(services.ErrShareNotFound)
+	_, err := srv.Unshare(ctx, &pb.UnshareRequest{SecretType: "text", SecretName: "wifi", Recipient: "bob"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	mockParser.EXPECT().Parse("bad").Return("", errors.New("invalid token"))
+	_, err = srv.Unshare(contextWithAuthToken("bad"), &pb.UnshareRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestShareServer_ListAndSaveShared(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockShares := NewMockShareManager(ctrl)
+	mockParser := NewMockJWTParser(ctrl)
+	srv := NewShareServer(mockShares, nil, mockParser)
+
+	ctx := contextWithAuthToken("token")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	mockParser.EXPECT().Parse("token").Return("bob", nil)
+	mockShares.EXPECT().ListSharedWithMe(gomock.Any(), "bob").Return([]*models.SharedSecret{{
+		Secret: models.Secret{
+			SecretName:  "wifi",
+			SecretType:  "text",
+			SecretOwner: "alice",
+			Ciphertext:  []byte("ct"),
+			AESKeyEnc:   []byte("wrapped"),
+			KeyID:       "bob-id",
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		Permission: models.SharePermissionReadWrite,
+	}}, nil)
+
+	resp, err := srv.ListSharedWithMe(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetSecrets(), 1)
+	got := resp.GetSecrets()[0]
+	assert.Equal(t, "alice", got.GetSecretOwner())
+	assert.Equal(t, []byte("wrapped"), got.GetAesKeyEnc())
+	assert.Equal(t, models.SharePermissionReadWrite, got.GetPermission())
+	assert.True(t, got.GetUpdatedAt().AsTime().Equal(now))
+
+	mockParser.EXPECT().Parse("token").Return("bob", nil)
+	mockShares.EXPECT().SaveShared(gomock.Any(), "bob", "alice", "text", "wifi", []byte("ct2")).Return(nil)
+	_, err = srv.SaveShared(ctx, &pb.SaveSharedRequest{SecretOwner: "alice", SecretType: "text", SecretName: "wifi", Ciphertext: []byte("ct2")})
+	require.NoError(t, err)
+
+	mockParser.EXPECT().Parse("token").Return("bob", nil)
+	mockShares.EXPECT().SaveShared(gomock.Any(), "bob", "alice", "text", "wifi", []byte("ct2")).Return(services.ErrShareReadOnly)
+	_, err = srv.SaveShared(ctx, &pb.SaveSharedRequest{SecretOwner: "alice", SecretType: "text", SecretName: "wifi", Ciphertext: []byte("ct2")})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// AuditLister defines the interface for reading the audit log of a user.
+type AuditLister interface {
+	// List returns the newest audit events of a user.
+	List(ctx context.Context, username string, limit int) ([]*models.AuditEvent, error)
+}
+
+// AuditEventResponse represents an audit log entry returned in responses.
+// swagger:model AuditEventResponse
+type AuditEventResponse struct {
+	// Event identifier
+	ID int64 `json:"id" example:"42"`
+	// Account the event belongs to
+	Username string `json:"username" example:"johndoe"`
+	// Action performed
+	Action string `json:"action" example:"secret.get"`
+	// Accessed secret as type/name, empty for account events
+	SecretKey string `json:"secret_key" example:"user/mail"`
+	// Transport the request came in on: http or grpc
+	Transport string `json:"transport" example:"http"`
+	// Client IP address
+	ClientIP string `json:"client_ip" example:"192.0.2.1"`
+	// Time of the event
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewAuditListHandler returns an HTTP handler that lists the newest audit events of a user.
+//
+// @Summary List audit events
+// @Description Lists the newest audit events (secret access, logins, two-factor changes) of authenticated user
+// @Tags audit
+// @Produce json
+// @Param limit query int false "Maximum number of events (default 100, max 1000)"
+// @Success 200 {array} AuditEventResponse
+// @Failure 400 {string} string "invalid limit"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Router /audit [get]
+func NewAuditListHandler(lister AuditLister, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var limit int
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit < 0 {
+				http.Error(w, "invalid limit", http.StatusBadRequest)
+				return
+			}
+		}
+
+		events, err := lister.List(r.Context(), username, limit)
+		if err != nil {
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := make([]AuditEventResponse, 0, len(events))
+		for _, ev := range events {
+			resp = append(resp, AuditEventResponse{
+				ID:        ev.ID,
+				Username:  ev.Username,
+				Action:    ev.Action,
+				SecretKey: ev.SecretKey,
+				Transport: ev.Transport,
+				ClientIP:  ev.ClientIP,
+				CreatedAt: ev.CreatedAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
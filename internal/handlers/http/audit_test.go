@@ -0,0 +1,107 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditListHandler(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		url            string
+		authHeader     string
+		expectedStatus int
+		mockSetup      func(lister *MockAuditLister, parser *MockJWTParser)
+	}{
+		{
+			name:           "success with limit",
+			url:            "/audit?limit=5",
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(lister *MockAuditLister, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				lister.EXPECT().List(gomock.Any(), "alice", 5).Return([]*models.AuditEvent{
+					{ID: 1, Username: "alice", Action: models.AuditActionSecretGet, SecretKey: "user/mail", Transport: "http", ClientIP: "192.0.2.1", CreatedAt: now},
+				}, nil)
+			},
+		},
+		{
+			name:           "default limit",
+			url:            "/audit",
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(lister *MockAuditLister, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				lister.EXPECT().List(gomock.Any(), "alice", 0).Return([]*models.AuditEvent{
+					{ID: 1, Username: "alice", Action: models.AuditActionSecretGet, SecretKey: "user/mail", Transport: "http", ClientIP: "192.0.2.1", CreatedAt: now},
+				}, nil)
+			},
+		},
+		{
+			name:           "invalid limit",
+			url:            "/audit?limit=abc",
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(lister *MockAuditLister, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			},
+		},
+		{
+			name:           "unauthorized",
+			url:            "/audit",
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup:      func(lister *MockAuditLister, parser *MockJWTParser) {},
+		},
+		{
+			name:           "lister error",
+			url:            "/audit",
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusInternalServerError,
+			mockSetup: func(lister *MockAuditLister, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				lister.EXPECT().List(gomock.Any(), "alice", 0).Return(nil, errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			lister := NewMockAuditLister(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(lister, parser)
+
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rec := httptest.NewRecorder()
+
+			NewAuditListHandler(lister, parser).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp []AuditEventResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				require.Len(t, resp, 1)
+				assert.Equal(t, models.AuditActionSecretGet, resp[0].Action)
+				assert.Equal(t, "user/mail", resp[0].SecretKey)
+				assert.Equal(t, "192.0.2.1", resp[0].ClientIP)
+				assert.True(t, now.Equal(resp[0].CreatedAt))
+			}
+		})
+	}
+}
@@ -5,20 +5,55 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	"github.com/sbilibin2017/gophkeeper/internal/services"
 )
 
 // Registerer defines interface for user registration.
 type Registerer interface {
-	// Register registers a new user, returns error if any.
-	Register(ctx context.Context, username, password string) error
+	// Register registers a new user, in master-password mode when kdfParams is set, returns error if any.
+	Register(ctx context.Context, username, password string, kdfParams *models.KDFParams) error
+}
+
+// KDFParamsGetter defines interface for reading the master-password KDF parameters of a user.
+type KDFParamsGetter interface {
+	// GetKDFParams returns the parameters the master password of a user is stretched with.
+	GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error)
 }
 
 // Authenticator defines interface for user authentication (login).
 type Authenticator interface {
 	// Authenticate authenticates a user, returns error if any.
 	Authenticate(ctx context.Context, username, password string) error
+	// VerifyOTP checks the second factor of a user, returns error if any.
+	VerifyOTP(ctx context.Context, username, code string) error
+}
+
+// OTPEnroller defines interface for starting two-factor authentication enrolment.
+type OTPEnroller interface {
+	// EnrollOTP generates a new TOTP seed for the user and returns its provisioning data.
+	EnrollOTP(ctx context.Context, username string) (*models.OTPEnrollment, error)
+}
+
+// OTPConfirmer defines interface for finishing two-factor authentication enrolment.
+type OTPConfirmer interface {
+	// ConfirmOTP enables two-factor authentication once a valid code is supplied.
+	ConfirmOTP(ctx context.Context, username, code string) error
+}
+
+// PasswordChanger defines interface for changing the password of a user.
+type PasswordChanger interface {
+	// ChangePassword verifies the current password, stores the new one and revokes issued tokens.
+	ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error
+}
+
+// AccountDeleter defines interface for deleting a user account.
+type AccountDeleter interface {
+	// DeleteAccount verifies the password and removes the user with all their secrets.
+	DeleteAccount(ctx context.Context, username, password string) error
 }
 
 // JWTGenerator generates JWT tokens for users.
@@ -32,9 +67,11 @@ type RegisterRequest struct {
 	// Username for the new user
 	// example: johndoe
 	Username string `json:"username" example:"johndoe"`
-	// Password for the new user
+	// Password for the new user; in master-password mode the authentication key derived from it
 	// example: secret123
 	Password string `json:"password" example:"secret123"`
+	// Parameters the master password is stretched with, set only in master-password mode
+	KDF *models.KDFParams `json:"kdf,omitempty"`
 }
 
 // LoginRequest represents the expected request body for user login.
@@ -46,6 +83,45 @@ type LoginRequest struct {
 	// Password of the user
 	// example: secret123
 	Password string `json:"password" example:"secret123"`
+	// One-time code, required when two-factor authentication is enabled
+	// example: 123456
+	OTPCode string `json:"otp_code,omitempty" example:"123456"`
+}
+
+// OTPEnrollResponse represents the response body of two-factor authentication enrolment.
+// swagger:model OTPEnrollResponse
+type OTPEnrollResponse struct {
+	// otpauth:// URI to add to an authenticator app
+	URI string `json:"uri" example:"otpauth://totp/GophKeeper:johndoe?secret=JBSWY3DPEHPK3PXP"`
+	// Single-use recovery codes
+	RecoveryCodes []string `json:"recovery_codes" example:"a1b2c-3d4e5"`
+}
+
+// OTPConfirmRequest represents the expected request body for confirming two-factor authentication.
+// swagger:model OTPConfirmRequest
+type OTPConfirmRequest struct {
+	// One-time code from the authenticator app
+	// example: 123456
+	Code string `json:"code" example:"123456"`
+}
+
+// ChangePasswordRequest represents the expected request body for changing the password.
+// swagger:model ChangePasswordRequest
+type ChangePasswordRequest struct {
+	// Current password of the user
+	// example: secret123
+	OldPassword string `json:"old_password" example:"secret123"`
+	// New password of the user
+	// example: n3w-Secret!
+	NewPassword string `json:"new_password" example:"n3w-Secret!"`
+}
+
+// DeleteAccountRequest represents the expected request body for deleting the account.
+// swagger:model DeleteAccountRequest
+type DeleteAccountRequest struct {
+	// Current password of the user
+	// example: secret123
+	Password string `json:"password" example:"secret123"`
 }
 
 // NewRegisterHandler returns an HTTP handler for registering a new user.
@@ -53,14 +129,16 @@ type LoginRequest struct {
 // creates a user, generates a JWT token, and returns it in Authorization header.
 //
 // @Summary Register a new user
-// @Description Registers a user with username and password, returns JWT token
+// @Description Registers a user with username and password, returns JWT token.
+// @Description In master-password mode the request carries the KDF parameters and the derived authentication key as password.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param registerRequest body RegisterRequest true "Register request payload"
 // @Success 200 {string} string "JWT token returned in Authorization header"
-// @Failure 400 {string} string "invalid request body"
+// @Failure 400 {string} string "invalid request body or KDF parameters"
 // @Failure 409 {string} string "user already exists"
+// @Failure 429 {string} string "too many failed attempts"
 // @Failure 500 {string} string "internal server error"
 // @Router /register [post]
 func NewRegisterHandler(auth Registerer, jwtGen JWTGenerator) http.HandlerFunc {
@@ -72,9 +150,11 @@ func NewRegisterHandler(auth Registerer, jwtGen JWTGenerator) http.HandlerFunc {
 		}
 
 		// Register user
-		err := auth.Register(r.Context(), req.Username, req.Password)
+		err := auth.Register(r.Context(), req.Username, req.Password, req.KDF)
 		if err != nil {
 			switch {
+			case errors.Is(err, services.ErrInvalidKDFParams):
+				http.Error(w, err.Error(), http.StatusBadRequest)
 			case errors.Is(err, services.ErrUserAlreadyExists):
 				http.Error(w, err.Error(), http.StatusConflict)
 			default:
@@ -98,6 +178,8 @@ func NewRegisterHandler(auth Registerer, jwtGen JWTGenerator) http.HandlerFunc {
 // NewLoginHandler returns an HTTP handler for authenticating a user.
 // It accepts JSON body with username and password,
 // authenticates the user, generates a JWT token, and returns it in Authorization header.
+// If the user has two-factor authentication enabled the body must also carry otp_code,
+// otherwise 401 with "one-time code required" is returned.
 //
 // @Summary Authenticate a user (login)
 // @Description Authenticates user and returns JWT token
@@ -107,7 +189,8 @@ func NewRegisterHandler(auth Registerer, jwtGen JWTGenerator) http.HandlerFunc {
 // @Param loginRequest body LoginRequest true "Login request payload"
 // @Success 200 {string} string "JWT token returned in Authorization header"
 // @Failure 400 {string} string "invalid request body"
-// @Failure 401 {string} string "invalid username or password"
+// @Failure 401 {string} string "invalid username, password or one-time code"
+// @Failure 429 {string} string "too many failed attempts"
 // @Failure 500 {string} string "internal server error"
 // @Router /login [post]
 func NewLoginHandler(auth Authenticator, jwtGen JWTGenerator) http.HandlerFunc {
@@ -130,6 +213,22 @@ func NewLoginHandler(auth Authenticator, jwtGen JWTGenerator) http.HandlerFunc {
 			return
 		}
 
+		// Check second factor
+		err = auth.VerifyOTP(r.Context(), req.Username, req.OTPCode)
+		if err != nil {
+			switch {
+			case errors.Is(err, models.ErrOTPRequired):
+				// The password was right; the client asks for the code and logs in again.
+				markNeutral(r)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			case errors.Is(err, services.ErrInvalidOTP):
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+			default:
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
 		// Generate JWT token after successful authentication
 		token, err := jwtGen.Generate(req.Username)
 		if err != nil {
@@ -141,3 +240,247 @@ func NewLoginHandler(auth Authenticator, jwtGen JWTGenerator) http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+// NewKDFParamsHandler returns an HTTP handler that returns the master-password KDF parameters of a user.
+// It needs no token, clients fetch the parameters to derive the key they log in with.
+//
+// @Summary Get KDF parameters
+// @Description Returns the parameters the master password of a user is stretched with
+// @Tags auth
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} models.KDFParams
+// @Failure 404 {string} string "master password is not set up for this user"
+// @Failure 500 {string} string "internal server error"
+// @Router /users/{username}/kdf [get]
+func NewKDFParamsHandler(getter KDFParamsGetter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params, err := getter.GetKDFParams(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrNoKDFParams):
+				http.Error(w, err.Error(), http.StatusNotFound)
+			default:
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(params); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// NewOTPEnrollHandler returns an HTTP handler that starts two-factor authentication enrolment.
+// The response contains an otpauth:// URI for authenticator apps and single-use recovery codes.
+//
+// @Summary Enroll two-factor authentication
+// @Description Generates a TOTP seed for authenticated user and returns otpauth URI and recovery codes
+// @Tags auth
+// @Produce json
+// @Success 200 {object} OTPEnrollResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 409 {string} string "two-factor authentication already enabled"
+// @Failure 500 {string} string "internal server error"
+// @Router /otp/enroll [post]
+func NewOTPEnrollHandler(enroller OTPEnroller, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		enrollment, err := enroller.EnrollOTP(r.Context(), username)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrOTPAlreadyEnabled):
+				http.Error(w, err.Error(), http.StatusConflict)
+			case errors.Is(err, services.ErrOTPNotConfigured):
+				http.Error(w, err.Error(), http.StatusNotImplemented)
+			default:
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		resp := OTPEnrollResponse{
+			URI:           enrollment.URI,
+			RecoveryCodes: enrollment.RecoveryCodes,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// NewOTPConfirmHandler returns an HTTP handler that enables two-factor authentication
+// after the user proves possession of the seed with a valid one-time code.
+//
+// @Summary Confirm two-factor authentication
+// @Description Enables two-factor authentication for authenticated user
+// @Tags auth
+// @Accept json
+// @Param otpConfirmRequest body OTPConfirmRequest true "One-time code"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body or one-time code"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 409 {string} string "two-factor authentication not enrolled"
+// @Failure 429 {string} string "too many failed attempts"
+// @Failure 500 {string} string "internal server error"
+// @Router /otp/confirm [post]
+func NewOTPConfirmHandler(confirmer OTPConfirmer, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req OTPConfirmRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		err = confirmer.ConfirmOTP(r.Context(), username, req.Code)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidOTP) {
+				markFailed(r)
+			} else {
+				markNeutral(r)
+			}
+			switch {
+			case errors.Is(err, services.ErrInvalidOTP):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, services.ErrOTPNotEnrolled), errors.Is(err, services.ErrOTPAlreadyEnabled):
+				http.Error(w, err.Error(), http.StatusConflict)
+			case errors.Is(err, services.ErrOTPNotConfigured):
+				http.Error(w, err.Error(), http.StatusNotImplemented)
+			default:
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewChangePasswordHandler returns an HTTP handler that changes the password of the authenticated user.
+// Tokens issued before the change stop being accepted, a new token is returned in Authorization header.
+//
+// @Summary Change password
+// @Description Changes password of authenticated user, signs out all sessions and returns a new JWT token
+// @Tags auth
+// @Accept json
+// @Param changePasswordRequest body ChangePasswordRequest true "Current and new password"
+// @Success 200 {string} string "JWT token returned in Authorization header"
+// @Failure 400 {string} string "invalid request body"
+// @Failure 401 {string} string "unauthorized or invalid password"
+// @Failure 429 {string} string "too many failed attempts"
+// @Failure 500 {string} string "internal server error"
+// @Router /password [post]
+func NewChangePasswordHandler(changer PasswordChanger, jwtGen JWTGenerator, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req ChangePasswordRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := changer.ChangePassword(r.Context(), username, req.OldPassword, req.NewPassword); err != nil {
+			if !errors.Is(err, services.ErrInvalidPassword) {
+				markNeutral(r)
+			}
+			writeAccountError(w, err)
+			return
+		}
+
+		token, err := jwtGen.Generate(username)
+		if err != nil {
+			http.Error(w, "failed to generate token", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Authorization", "Bearer "+token)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewDeleteAccountHandler returns an HTTP handler that deletes the authenticated user
+// together with all their secrets.
+//
+// @Summary Delete account
+// @Description Deletes authenticated user and all their secrets after verifying the password
+// @Tags auth
+// @Accept json
+// @Param deleteAccountRequest body DeleteAccountRequest true "Current password"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body"
+// @Failure 401 {string} string "unauthorized or invalid password"
+// @Failure 409 {string} string "organization must keep at least one owner"
+// @Failure 429 {string} string "too many failed attempts"
+// @Failure 500 {string} string "internal server error"
+// @Router /account [delete]
+func NewDeleteAccountHandler(deleter AccountDeleter, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req DeleteAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := deleter.DeleteAccount(r.Context(), username, req.Password); err != nil {
+			if !errors.Is(err, services.ErrInvalidPassword) {
+				markNeutral(r)
+			}
+			writeAccountError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// writeAccountError maps errors of password changes and account deletion to HTTP statuses.
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPassword), errors.Is(err, services.ErrInvalidData):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrLastOwner):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrAccountNotConfigured):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+// usernameFromRequest extracts the bearer token from the Authorization header
+// and returns the username it was issued to.
+func usernameFromRequest(r *http.Request, parser JWTParser) (string, error) {
+	parts := strings.Fields(r.Header.Get("Authorization"))
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return "", ErrUnauthorized
+	}
+	return parser.Parse(parts[1])
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
 )
 
 // MockRegisterer is a mock of Registerer interface.
@@ -35,17 +36,55 @@ func (m *MockRegisterer) EXPECT() *MockRegistererMockRecorder {
 }
 
 // Register mocks base method.
-func (m *MockRegisterer) Register(ctx context.Context, username, password string) error {
+func (m *MockRegisterer) Register(ctx context.Context, username, password string, kdfParams *models.KDFParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Register", ctx, username, password)
+	ret := m.ctrl.Call(m, "Register", ctx, username, password, kdfParams)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Register indicates an expected call of Register.
-func (mr *MockRegistererMockRecorder) Register(ctx, username, password interface{}) *gomock.Call {
+func (mr *MockRegistererMockRecorder) Register(ctx, username, password, kdfParams interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockRegisterer)(nil).Register), ctx, username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockRegisterer)(nil).Register), ctx, username, password, kdfParams)
+}
+
+// MockKDFParamsGetter is a mock of KDFParamsGetter interface.
+type MockKDFParamsGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockKDFParamsGetterMockRecorder
+}
+
+// MockKDFParamsGetterMockRecorder is the mock recorder for MockKDFParamsGetter.
+type MockKDFParamsGetterMockRecorder struct {
+	mock *MockKDFParamsGetter
+}
+
+// NewMockKDFParamsGetter creates a new mock instance.
+func NewMockKDFParamsGetter(ctrl *gomock.Controller) *MockKDFParamsGetter {
+	mock := &MockKDFParamsGetter{ctrl: ctrl}
+	mock.recorder = &MockKDFParamsGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKDFParamsGetter) EXPECT() *MockKDFParamsGetterMockRecorder {
+	return m.recorder
+}
+
+// GetKDFParams mocks base method.
+func (m *MockKDFParamsGetter) GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKDFParams", ctx, username)
+	ret0, _ := ret[0].(*models.KDFParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKDFParams indicates an expected call of GetKDFParams.
+func (mr *MockKDFParamsGetterMockRecorder) GetKDFParams(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKDFParams", reflect.TypeOf((*MockKDFParamsGetter)(nil).GetKDFParams), ctx, username)
 }
 
 // MockAuthenticator is a mock of Authenticator interface.
@@ -85,6 +124,169 @@ func (mr *MockAuthenticatorMockRecorder) Authenticate(ctx, username, password in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAuthenticator)(nil).Authenticate), ctx, username, password)
 }
 
+// VerifyOTP mocks base method.
+func (m *MockAuthenticator) VerifyOTP(ctx context.Context, username, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyOTP", ctx, username, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyOTP indicates an expected call of VerifyOTP.
+func (mr *MockAuthenticatorMockRecorder) VerifyOTP(ctx, username, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyOTP", reflect.TypeOf((*MockAuthenticator)(nil).VerifyOTP), ctx, username, code)
+}
+
+// MockOTPEnroller is a mock of OTPEnroller interface.
+type MockOTPEnroller struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPEnrollerMockRecorder
+}
+
+// MockOTPEnrollerMockRecorder is the mock recorder for MockOTPEnroller.
+type MockOTPEnrollerMockRecorder struct {
+	mock *MockOTPEnroller
+}
+
+// NewMockOTPEnroller creates a new mock instance.
+func NewMockOTPEnroller(ctrl *gomock.Controller) *MockOTPEnroller {
+	mock := &MockOTPEnroller{ctrl: ctrl}
+	mock.recorder = &MockOTPEnrollerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPEnroller) EXPECT() *MockOTPEnrollerMockRecorder {
+	return m.recorder
+}
+
+// EnrollOTP mocks base method.
+func (m *MockOTPEnroller) EnrollOTP(ctx context.Context, username string) (*models.OTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollOTP", ctx, username)
+	ret0, _ := ret[0].(*models.OTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollOTP indicates an expected call of EnrollOTP.
+func (mr *MockOTPEnrollerMockRecorder) EnrollOTP(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollOTP", reflect.TypeOf((*MockOTPEnroller)(nil).EnrollOTP), ctx, username)
+}
+
+// MockOTPConfirmer is a mock of OTPConfirmer interface.
+type MockOTPConfirmer struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPConfirmerMockRecorder
+}
+
+// MockOTPConfirmerMockRecorder is the mock recorder for MockOTPConfirmer.
+type MockOTPConfirmerMockRecorder struct {
+	mock *MockOTPConfirmer
+}
+
+// NewMockOTPConfirmer creates a new mock instance.
+func NewMockOTPConfirmer(ctrl *gomock.Controller) *MockOTPConfirmer {
+	mock := &MockOTPConfirmer{ctrl: ctrl}
+	mock.recorder = &MockOTPConfirmerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPConfirmer) EXPECT() *MockOTPConfirmerMockRecorder {
+	return m.recorder
+}
+
+// ConfirmOTP mocks base method.
+func (m *MockOTPConfirmer) ConfirmOTP(ctx context.Context, username, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmOTP", ctx, username, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmOTP indicates an expected call of ConfirmOTP.
+func (mr *MockOTPConfirmerMockRecorder) ConfirmOTP(ctx, username, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmOTP", reflect.TypeOf((*MockOTPConfirmer)(nil).ConfirmOTP), ctx, username, code)
+}
+
+// MockPasswordChanger is a mock of PasswordChanger interface.
+type MockPasswordChanger struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordChangerMockRecorder
+}
+
+// MockPasswordChangerMockRecorder is the mock recorder for MockPasswordChanger.
+type MockPasswordChangerMockRecorder struct {
+	mock *MockPasswordChanger
+}
+
+// NewMockPasswordChanger creates a new mock instance.
+func NewMockPasswordChanger(ctrl *gomock.Controller) *MockPasswordChanger {
+	mock := &MockPasswordChanger{ctrl: ctrl}
+	mock.recorder = &MockPasswordChangerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordChanger) EXPECT() *MockPasswordChangerMockRecorder {
+	return m.recorder
+}
+
+// ChangePassword mocks base method.
+func (m *MockPasswordChanger) ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, username, oldPassword, newPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockPasswordChangerMockRecorder) ChangePassword(ctx, username, oldPassword, newPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockPasswordChanger)(nil).ChangePassword), ctx, username, oldPassword, newPassword)
+}
+
+// MockAccountDeleter is a mock of AccountDeleter interface.
+type MockAccountDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountDeleterMockRecorder
+}
+
+// MockAccountDeleterMockRecorder is the mock recorder for MockAccountDeleter.
+type MockAccountDeleterMockRecorder struct {
+	mock *MockAccountDeleter
+}
+
+// NewMockAccountDeleter creates a new mock instance.
+func NewMockAccountDeleter(ctrl *gomock.Controller) *MockAccountDeleter {
+	mock := &MockAccountDeleter{ctrl: ctrl}
+	mock.recorder = &MockAccountDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountDeleter) EXPECT() *MockAccountDeleterMockRecorder {
+	return m.recorder
+}
+
+// DeleteAccount mocks base method.
+func (m *MockAccountDeleter) DeleteAccount(ctx context.Context, username, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, username, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount.
+func (mr *MockAccountDeleterMockRecorder) DeleteAccount(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockAccountDeleter)(nil).DeleteAccount), ctx, username, password)
+}
+
 // MockJWTGenerator is a mock of JWTGenerator interface.
 type MockJWTGenerator struct {
 	ctrl     *gomock.Controller
@@ -9,10 +9,15 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	"github.com/sbilibin2017/gophkeeper/internal/services"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// testKDFParams are master-password parameters sent by clients in master-password mode.
+var testKDFParams = &models.KDFParams{Algorithm: models.KDFAlgorithmArgon2id, Salt: []byte("0123456789abcdef"), Time: 3, Memory: 65536, Threads: 4}
+
 func TestRegisterHandler(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -32,7 +37,7 @@ func TestRegisterHandler(t *testing.T) {
 				mockJWTGen := NewMockJWTGenerator(ctrl)
 
 				mockRegisterer.EXPECT().
-					Register(gomock.Any(), "alice", "pass123").
+					Register(gomock.Any(), "alice", "pass123", nil).
 					Return(nil).
 					Times(1)
 
@@ -44,6 +49,40 @@ func TestRegisterHandler(t *testing.T) {
 				return mockRegisterer, mockJWTGen
 			},
 		},
+		{
+			name:               "master-password mode",
+			requestBody:        RegisterRequest{Username: "erin", Password: "auth-key", KDF: testKDFParams},
+			expectedStatus:     http.StatusOK,
+			expectedAuthHeader: "Bearer sometoken",
+			mockSetup: func(ctrl *gomock.Controller) (Registerer, JWTGenerator) {
+				mockRegisterer := NewMockRegisterer(ctrl)
+				mockJWTGen := NewMockJWTGenerator(ctrl)
+
+				mockRegisterer.EXPECT().
+					Register(gomock.Any(), "erin", "auth-key", testKDFParams).
+					Return(nil)
+				mockJWTGen.EXPECT().
+					Generate("erin").
+					Return("sometoken", nil)
+
+				return mockRegisterer, mockJWTGen
+			},
+		},
+		{
+			name:           "invalid KDF parameters",
+			requestBody:    RegisterRequest{Username: "erin", Password: "auth-key", KDF: testKDFParams},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   services.ErrInvalidKDFParams.Error() + "\n",
+			mockSetup: func(ctrl *gomock.Controller) (Registerer, JWTGenerator) {
+				mockRegisterer := NewMockRegisterer(ctrl)
+
+				mockRegisterer.EXPECT().
+					Register(gomock.Any(), "erin", "auth-key", testKDFParams).
+					Return(services.ErrInvalidKDFParams)
+
+				return mockRegisterer, NewMockJWTGenerator(ctrl)
+			},
+		},
 		{
 			name:           "invalid json",
 			requestBody:    "invalid-json",
@@ -64,7 +103,7 @@ func TestRegisterHandler(t *testing.T) {
 				mockJWTGen := NewMockJWTGenerator(ctrl)
 
 				mockRegisterer.EXPECT().
-					Register(gomock.Any(), "bob", "pass123").
+					Register(gomock.Any(), "bob", "pass123", nil).
 					Return(services.ErrUserAlreadyExists).
 					Times(1)
 
@@ -82,7 +121,7 @@ func TestRegisterHandler(t *testing.T) {
 				mockJWTGen := NewMockJWTGenerator(ctrl)
 
 				mockRegisterer.EXPECT().
-					Register(gomock.Any(), "charlie", "pass123").
+					Register(gomock.Any(), "charlie", "pass123", nil).
 					Return(errors.New("db error")).
 					Times(1)
 
@@ -99,7 +138,7 @@ func TestRegisterHandler(t *testing.T) {
 				mockJWTGen := NewMockJWTGenerator(ctrl)
 
 				mockRegisterer.EXPECT().
-					Register(gomock.Any(), "dave", "pass123").
+					Register(gomock.Any(), "dave", "pass123", nil).
 					Return(nil).
 					Times(1)
 
@@ -145,6 +184,34 @@ func TestRegisterHandler(t *testing.T) {
 	}
 }
 
+func TestKDFParamsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	getter := NewMockKDFParamsGetter(ctrl)
+	handler := NewKDFParamsHandler(getter)
+
+	getter.EXPECT().GetKDFParams(gomock.Any(), "alice").Return(testKDFParams, nil)
+	getter.EXPECT().GetKDFParams(gomock.Any(), "bob").Return(nil, services.ErrNoKDFParams)
+	getter.EXPECT().GetKDFParams(gomock.Any(), "carol").Return(nil, errors.New("db error"))
+
+	req := withURLParams(httptest.NewRequest(http.MethodGet, "/users/alice/kdf", nil), map[string]string{"username": "alice"})
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var params models.KDFParams
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&params))
+	assert.Equal(t, *testKDFParams, params)
+
+	for username, status := range map[string]int{"bob": http.StatusNotFound, "carol": http.StatusInternalServerError} {
+		req := withURLParams(httptest.NewRequest(http.MethodGet, "/users/"+username+"/kdf", nil), map[string]string{"username": username})
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, status, rr.Code, username)
+	}
+}
+
 func TestLoginHandler(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -168,6 +235,11 @@ func TestLoginHandler(t *testing.T) {
 					Return(nil).
 					Times(1)
 
+				mockAuthenticator.EXPECT().
+					VerifyOTP(gomock.Any(), "alice", "").
+					Return(nil).
+					Times(1)
+
 				mockJWTGen.EXPECT().
 					Generate("alice").
 					Return("sometoken", nil).
@@ -233,6 +305,11 @@ func TestLoginHandler(t *testing.T) {
 					Return(nil).
 					Times(1)
 
+				mockAuthenticator.EXPECT().
+					VerifyOTP(gomock.Any(), "dave", "").
+					Return(nil).
+					Times(1)
+
 				mockJWTGen.EXPECT().
 					Generate("dave").
 					Return("", errors.New("jwt error")).
@@ -241,6 +318,77 @@ func TestLoginHandler(t *testing.T) {
 				return mockAuthenticator, mockJWTGen
 			},
 		},
+		{
+			name:           "one-time code required",
+			requestBody:    LoginRequest{Username: "erin", Password: "pass123"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   models.ErrOTPRequired.Error() + "\n",
+			mockSetup: func(ctrl *gomock.Controller) (Authenticator, JWTGenerator) {
+				mockAuthenticator := NewMockAuthenticator(ctrl)
+				mockJWTGen := NewMockJWTGenerator(ctrl)
+
+				mockAuthenticator.EXPECT().
+					Authenticate(gomock.Any(), "erin", "pass123").
+					Return(nil).
+					Times(1)
+
+				mockAuthenticator.EXPECT().
+					VerifyOTP(gomock.Any(), "erin", "").
+					Return(models.ErrOTPRequired).
+					Times(1)
+
+				return mockAuthenticator, mockJWTGen
+			},
+		},
+		{
+			name:               "success with one-time code",
+			requestBody:        LoginRequest{Username: "frank", Password: "pass123", OTPCode: "123456"},
+			expectedStatus:     http.StatusOK,
+			expectedAuthHeader: "Bearer othertoken",
+			mockSetup: func(ctrl *gomock.Controller) (Authenticator, JWTGenerator) {
+				mockAuthenticator := NewMockAuthenticator(ctrl)
+				mockJWTGen := NewMockJWTGenerator(ctrl)
+
+				mockAuthenticator.EXPECT().
+					Authenticate(gomock.Any(), "frank", "pass123").
+					Return(nil).
+					Times(1)
+
+				mockAuthenticator.EXPECT().
+					VerifyOTP(gomock.Any(), "frank", "123456").
+					Return(nil).
+					Times(1)
+
+				mockJWTGen.EXPECT().
+					Generate("frank").
+					Return("othertoken", nil).
+					Times(1)
+
+				return mockAuthenticator, mockJWTGen
+			},
+		},
+		{
+			name:           "invalid one-time code",
+			requestBody:    LoginRequest{Username: "grace", Password: "pass123", OTPCode: "000000"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   services.ErrInvalidOTP.Error() + "\n",
+			mockSetup: func(ctrl *gomock.Controller) (Authenticator, JWTGenerator) {
+				mockAuthenticator := NewMockAuthenticator(ctrl)
+				mockJWTGen := NewMockJWTGenerator(ctrl)
+
+				mockAuthenticator.EXPECT().
+					Authenticate(gomock.Any(), "grace", "pass123").
+					Return(nil).
+					Times(1)
+
+				mockAuthenticator.EXPECT().
+					VerifyOTP(gomock.Any(), "grace", "000000").
+					Return(services.ErrInvalidOTP).
+					Times(1)
+
+				return mockAuthenticator, mockJWTGen
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -274,3 +422,321 @@ func TestLoginHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestOTPEnrollHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectedStatus int
+		mockSetup      func(enroller *MockOTPEnroller, parser *MockJWTParser)
+	}{
+		{
+			name:           "success",
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(enroller *MockOTPEnroller, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				enroller.EXPECT().EnrollOTP(gomock.Any(), "alice").Return(&models.OTPEnrollment{
+					URI:           "otpauth://totp/GophKeeper:alice?secret=ABC",
+					RecoveryCodes: []string{"aaaaa-bbbbb"},
+				}, nil)
+			},
+		},
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup:      func(enroller *MockOTPEnroller, parser *MockJWTParser) {},
+		},
+		{
+			name:           "already enabled",
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusConflict,
+			mockSetup: func(enroller *MockOTPEnroller, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				enroller.EXPECT().EnrollOTP(gomock.Any(), "alice").Return(nil, services.ErrOTPAlreadyEnabled)
+			},
+		},
+		{
+			name:           "internal error",
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusInternalServerError,
+			mockSetup: func(enroller *MockOTPEnroller, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				enroller.EXPECT().EnrollOTP(gomock.Any(), "alice").Return(nil, errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			enroller := NewMockOTPEnroller(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(enroller, parser)
+
+			req := httptest.NewRequest(http.MethodPost, "/otp/enroll", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rec := httptest.NewRecorder()
+
+			NewOTPEnrollHandler(enroller, parser).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var resp OTPEnrollResponse
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, "otpauth://totp/GophKeeper:alice?secret=ABC", resp.URI)
+				assert.Equal(t, []string{"aaaaa-bbbbb"}, resp.RecoveryCodes)
+			}
+		})
+	}
+}
+
+func TestOTPConfirmHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+		mockSetup      func(confirmer *MockOTPConfirmer, parser *MockJWTParser)
+	}{
+		{
+			name:           "success",
+			requestBody:    OTPConfirmRequest{Code: "123456"},
+			expectedStatus: http.StatusOK,
+			mockSetup: func(confirmer *MockOTPConfirmer, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				confirmer.EXPECT().ConfirmOTP(gomock.Any(), "alice", "123456").Return(nil)
+			},
+		},
+		{
+			name:           "invalid json",
+			requestBody:    "invalid-json",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(confirmer *MockOTPConfirmer, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			},
+		},
+		{
+			name:           "invalid code",
+			requestBody:    OTPConfirmRequest{Code: "000000"},
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(confirmer *MockOTPConfirmer, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				confirmer.EXPECT().ConfirmOTP(gomock.Any(), "alice", "000000").Return(services.ErrInvalidOTP)
+			},
+		},
+		{
+			name:           "not enrolled",
+			requestBody:    OTPConfirmRequest{Code: "123456"},
+			expectedStatus: http.StatusConflict,
+			mockSetup: func(confirmer *MockOTPConfirmer, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				confirmer.EXPECT().ConfirmOTP(gomock.Any(), "alice", "123456").Return(services.ErrOTPNotEnrolled)
+			},
+		},
+		{
+			name:           "unauthorized",
+			requestBody:    OTPConfirmRequest{Code: "123456"},
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(confirmer *MockOTPConfirmer, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("", errors.New("bad token"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			confirmer := NewMockOTPConfirmer(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(confirmer, parser)
+
+			var bodyBytes []byte
+			switch v := tt.requestBody.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/otp/confirm", bytes.NewReader(bodyBytes))
+			req.Header.Set("Authorization", "Bearer validtoken")
+			rec := httptest.NewRecorder()
+
+			NewOTPConfirmHandler(confirmer, parser).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+		expectedToken  string
+		mockSetup      func(changer *MockPasswordChanger, jwtGen *MockJWTGenerator, parser *MockJWTParser)
+	}{
+		{
+			name:           "success",
+			requestBody:    ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			expectedStatus: http.StatusOK,
+			expectedToken:  "Bearer newtoken",
+			mockSetup: func(changer *MockPasswordChanger, jwtGen *MockJWTGenerator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				changer.EXPECT().ChangePassword(gomock.Any(), "alice", "old", "new").Return(nil)
+				jwtGen.EXPECT().Generate("alice").Return("newtoken", nil)
+			},
+		},
+		{
+			name:           "invalid json",
+			requestBody:    "invalid-json",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(changer *MockPasswordChanger, jwtGen *MockJWTGenerator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			},
+		},
+		{
+			name:           "wrong old password",
+			requestBody:    ChangePasswordRequest{OldPassword: "wrong", NewPassword: "new"},
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(changer *MockPasswordChanger, jwtGen *MockJWTGenerator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				changer.EXPECT().ChangePassword(gomock.Any(), "alice", "wrong", "new").Return(services.ErrInvalidPassword)
+			},
+		},
+		{
+			name:           "internal error",
+			requestBody:    ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			expectedStatus: http.StatusInternalServerError,
+			mockSetup: func(changer *MockPasswordChanger, jwtGen *MockJWTGenerator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				changer.EXPECT().ChangePassword(gomock.Any(), "alice", "old", "new").Return(errors.New("db error"))
+			},
+		},
+		{
+			name:           "unauthorized",
+			requestBody:    ChangePasswordRequest{OldPassword: "old", NewPassword: "new"},
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(changer *MockPasswordChanger, jwtGen *MockJWTGenerator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("", errors.New("token has been revoked"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			changer := NewMockPasswordChanger(ctrl)
+			jwtGen := NewMockJWTGenerator(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(changer, jwtGen, parser)
+
+			var bodyBytes []byte
+			switch v := tt.requestBody.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/password", bytes.NewReader(bodyBytes))
+			req.Header.Set("Authorization", "Bearer validtoken")
+			rec := httptest.NewRecorder()
+
+			NewChangePasswordHandler(changer, jwtGen, parser).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectedToken, rec.Header().Get("Authorization"))
+		})
+	}
+}
+
+func TestDeleteAccountHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+		mockSetup      func(deleter *MockAccountDeleter, parser *MockJWTParser)
+	}{
+		{
+			name:           "success",
+			requestBody:    DeleteAccountRequest{Password: "pass"},
+			expectedStatus: http.StatusOK,
+			mockSetup: func(deleter *MockAccountDeleter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				deleter.EXPECT().DeleteAccount(gomock.Any(), "alice", "pass").Return(nil)
+			},
+		},
+		{
+			name:           "wrong password",
+			requestBody:    DeleteAccountRequest{Password: "wrong"},
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(deleter *MockAccountDeleter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				deleter.EXPECT().DeleteAccount(gomock.Any(), "alice", "wrong").Return(services.ErrInvalidPassword)
+			},
+		},
+		{
+			name:           "sole organization owner",
+			requestBody:    DeleteAccountRequest{Password: "pass"},
+			expectedStatus: http.StatusConflict,
+			mockSetup: func(deleter *MockAccountDeleter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				deleter.EXPECT().DeleteAccount(gomock.Any(), "alice", "pass").Return(services.ErrLastOwner)
+			},
+		},
+		{
+			name:           "not configured",
+			requestBody:    DeleteAccountRequest{Password: "pass"},
+			expectedStatus: http.StatusNotImplemented,
+			mockSetup: func(deleter *MockAccountDeleter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				deleter.EXPECT().DeleteAccount(gomock.Any(), "alice", "pass").Return(services.ErrAccountNotConfigured)
+			},
+		},
+		{
+			name:           "invalid json",
+			requestBody:    "invalid-json",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(deleter *MockAccountDeleter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			deleter := NewMockAccountDeleter(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(deleter, parser)
+
+			var bodyBytes []byte
+			switch v := tt.requestBody.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			req := httptest.NewRequest(http.MethodDelete, "/account", bytes.NewReader(bodyBytes))
+			req.Header.Set("Authorization", "Bearer validtoken")
+			rec := httptest.NewRecorder()
+
+			NewDeleteAccountHandler(deleter, parser).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
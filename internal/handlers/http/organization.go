@@ -0,0 +1,469 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+)
+
+// OrganizationCreator defines interface for creating organizations.
+type OrganizationCreator interface {
+	// CreateOrganization creates an organization owned by username.
+	CreateOrganization(ctx context.Context, username, name string) error
+}
+
+// OrganizationDeleter defines interface for deleting organizations.
+type OrganizationDeleter interface {
+	// DeleteOrganization deletes an organization with all its vaults and secrets.
+	DeleteOrganization(ctx context.Context, username, name string) error
+}
+
+// OrganizationLister defines interface for listing the organizations of a user.
+type OrganizationLister interface {
+	// ListOrganizations returns the memberships of username.
+	ListOrganizations(ctx context.Context, username string) ([]*models.Membership, error)
+}
+
+// MemberSetter defines interface for adding members and changing their roles.
+type MemberSetter interface {
+	// SetMember adds member to an organization or changes their role.
+	SetMember(ctx context.Context, username, organization, member, role string) error
+}
+
+// MemberRemover defines interface for removing members from organizations.
+type MemberRemover interface {
+	// RemoveMember removes member from an organization.
+	RemoveMember(ctx context.Context, username, organization, member string) error
+}
+
+// MemberLister defines interface for listing the members of an organization.
+type MemberLister interface {
+	// ListMembers returns the members of an organization username belongs to.
+	ListMembers(ctx context.Context, username, organization string) ([]*models.Membership, error)
+}
+
+// VaultCreator defines interface for creating vaults.
+type VaultCreator interface {
+	// CreateVault creates a vault in an organization.
+	CreateVault(ctx context.Context, username, organization, name string) error
+}
+
+// VaultDeleter defines interface for deleting vaults.
+type VaultDeleter interface {
+	// DeleteVault deletes a vault with all its secrets.
+	DeleteVault(ctx context.Context, username, organization, name string) error
+}
+
+// VaultLister defines interface for listing the vaults of an organization.
+type VaultLister interface {
+	// ListVaults returns the vaults of an organization username belongs to.
+	ListVaults(ctx context.Context, username, organization string) ([]*models.Vault, error)
+}
+
+// OrganizationRequest represents the expected request body for creating an organization.
+// swagger:model OrganizationRequest
+type OrganizationRequest struct {
+	// Organization name
+	// example: acme
+	Name string `json:"name" example:"acme"`
+}
+
+// MembershipResponse represents the role of a user in an organization.
+// swagger:model MembershipResponse
+type MembershipResponse struct {
+	// Organization name
+	Organization string `json:"organization"`
+	// Member username
+	Username string `json:"username"`
+	// Role of the member: owner, admin, member or read-only
+	Role string `json:"role"`
+	// Time the user joined the organization
+	CreatedAt time.Time `json:"created_at"`
+	// Time the role was last changed
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MemberRequest represents the expected request body for setting the role of a member.
+// swagger:model MemberRequest
+type MemberRequest struct {
+	// Role of the member: owner, admin, member or read-only
+	// example: member
+	Role string `json:"role" example:"member"`
+}
+
+// VaultRequest represents the expected request body for creating a vault.
+// swagger:model VaultRequest
+type VaultRequest struct {
+	// Vault name
+	// example: ops
+	Name string `json:"name" example:"ops"`
+}
+
+// VaultResponse represents a vault of an organization.
+// swagger:model VaultResponse
+type VaultResponse struct {
+	// Organization name
+	Organization string `json:"organization"`
+	// Vault name
+	Name string `json:"name"`
+	// Time the vault was created
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewOrganizationCreateHandler returns an HTTP handler that creates an organization.
+//
+// @Summary Create an organization
+// @Description Creates an organization owned by authenticated user
+// @Tags organizations
+// @Accept json
+// @Param organizationRequest body OrganizationRequest true "Organization"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body or name"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 409 {string} string "organization already exists"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations [post]
+func NewOrganizationCreateHandler(creator OrganizationCreator, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req OrganizationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := creator.CreateOrganization(r.Context(), username, req.Name); err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewOrganizationDeleteHandler returns an HTTP handler that deletes an organization.
+//
+// @Summary Delete an organization
+// @Description Deletes an organization with all its vaults and secrets, requires the owner role
+// @Tags organizations
+// @Param organization path string true "Organization name"
+// @Success 200 {string} string "ok"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not an owner of the organization"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations/{organization} [delete]
+func NewOrganizationDeleteHandler(deleter OrganizationDeleter, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		if err := deleter.DeleteOrganization(r.Context(), username, chi.URLParam(r, "organization")); err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewOrganizationListHandler returns an HTTP handler that lists the organizations of a user.
+//
+// @Summary List organizations
+// @Description Lists the organizations authenticated user belongs to with their role
+// @Tags organizations
+// @Produce json
+// @Success 200 {array} MembershipResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations [get]
+func NewOrganizationListHandler(lister OrganizationLister, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		memberships, err := lister.ListOrganizations(r.Context(), username)
+		if err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		writeMemberships(w, memberships)
+	}
+}
+
+// NewMemberSetHandler returns an HTTP handler that adds a member or changes their role.
+//
+// @Summary Set a member
+// @Description Adds a user to an organization or changes their role, requires the admin role; owner and admin roles are managed by owners
+// @Tags organizations
+// @Accept json
+// @Param organization path string true "Organization name"
+// @Param username path string true "Member username"
+// @Param memberRequest body MemberRequest true "Role"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body or role"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "role does not allow this"
+// @Failure 404 {string} string "user not found"
+// @Failure 409 {string} string "organization must keep an owner"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations/{organization}/members/{username} [put]
+func NewMemberSetHandler(setter MemberSetter, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req MemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		err = setter.SetMember(r.Context(), username,
+			chi.URLParam(r, "organization"), chi.URLParam(r, "username"), req.Role,
+		)
+		if err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewMemberRemoveHandler returns an HTTP handler that removes a member from an organization.
+//
+// @Summary Remove a member
+// @Description Removes a user from an organization; members may always leave themselves
+// @Tags organizations
+// @Param organization path string true "Organization name"
+// @Param username path string true "Member username"
+// @Success 200 {string} string "ok"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "role does not allow this"
+// @Failure 404 {string} string "member not found"
+// @Failure 409 {string} string "organization must keep an owner"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations/{organization}/members/{username} [delete]
+func NewMemberRemoveHandler(remover MemberRemover, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		err = remover.RemoveMember(r.Context(), username, chi.URLParam(r, "organization"), chi.URLParam(r, "username"))
+		if err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewMemberListHandler returns an HTTP handler that lists the members of an organization.
+//
+// @Summary List members
+// @Description Lists the members of an organization authenticated user belongs to
+// @Tags organizations
+// @Produce json
+// @Param organization path string true "Organization name"
+// @Success 200 {array} MembershipResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not a member of the organization"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations/{organization}/members [get]
+func NewMemberListHandler(lister MemberLister, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		memberships, err := lister.ListMembers(r.Context(), username, chi.URLParam(r, "organization"))
+		if err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		writeMemberships(w, memberships)
+	}
+}
+
+// NewVaultCreateHandler returns an HTTP handler that creates a vault.
+//
+// @Summary Create a vault
+// @Description Creates a vault in an organization, requires the admin role
+// @Tags organizations
+// @Accept json
+// @Param organization path string true "Organization name"
+// @Param vaultRequest body VaultRequest true "Vault"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body or name"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "role does not allow this"
+// @Failure 409 {string} string "vault already exists"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations/{organization}/vaults [post]
+func NewVaultCreateHandler(creator VaultCreator, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req VaultRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := creator.CreateVault(r.Context(), username, chi.URLParam(r, "organization"), req.Name); err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewVaultDeleteHandler returns an HTTP handler that deletes a vault.
+//
+// @Summary Delete a vault
+// @Description Deletes a vault with all its secrets, requires the admin role
+// @Tags organizations
+// @Param organization path string true "Organization name"
+// @Param vault path string true "Vault name"
+// @Success 200 {string} string "ok"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "role does not allow this"
+// @Failure 404 {string} string "vault not found"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations/{organization}/vaults/{vault} [delete]
+func NewVaultDeleteHandler(deleter VaultDeleter, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		err = deleter.DeleteVault(r.Context(), username, chi.URLParam(r, "organization"), chi.URLParam(r, "vault"))
+		if err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewVaultListHandler returns an HTTP handler that lists the vaults of an organization.
+//
+// @Summary List vaults
+// @Description Lists the vaults of an organization authenticated user belongs to
+// @Tags organizations
+// @Produce json
+// @Param organization path string true "Organization name"
+// @Success 200 {array} VaultResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not a member of the organization"
+// @Failure 500 {string} string "internal server error"
+// @Router /organizations/{organization}/vaults [get]
+func NewVaultListHandler(lister VaultLister, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		vaults, err := lister.ListVaults(r.Context(), username, chi.URLParam(r, "organization"))
+		if err != nil {
+			writeOrganizationError(w, err)
+			return
+		}
+
+		resp := make([]VaultResponse, 0, len(vaults))
+		for _, v := range vaults {
+			resp = append(resp, VaultResponse{
+				Organization: v.Organization,
+				Name:         v.Name,
+				CreatedAt:    v.CreatedAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// writeMemberships writes memberships as a JSON response.
+func writeMemberships(w http.ResponseWriter, memberships []*models.Membership) {
+	resp := make([]MembershipResponse, 0, len(memberships))
+	for _, m := range memberships {
+		resp = append(resp, MembershipResponse{
+			Organization: m.Organization,
+			Username:     m.Username,
+			Role:         m.Role,
+			CreatedAt:    m.CreatedAt,
+			UpdatedAt:    m.UpdatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeOrganizationError maps errors of organization management to HTTP statuses.
+func writeOrganizationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidName),
+		errors.Is(err, services.ErrInvalidRole),
+		errors.Is(err, services.ErrInvalidVault):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrNotMember),
+		errors.Is(err, services.ErrInsufficientRole):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrMemberNotFound),
+		errors.Is(err, services.ErrVaultNotFound),
+		errors.Is(err, services.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrOrganizationExists),
+		errors.Is(err, services.ErrVaultExists),
+		errors.Is(err, services.ErrLastOwner):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
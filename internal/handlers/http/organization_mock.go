@@ -0,0 +1,349 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/handlers/http/organization.go
+
+// Package http is a generated GoMock package.
+package http
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockOrganizationCreator is a mock of OrganizationCreator interface.
+type MockOrganizationCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationCreatorMockRecorder
+}
+
+// MockOrganizationCreatorMockRecorder is the mock recorder for MockOrganizationCreator.
+type MockOrganizationCreatorMockRecorder struct {
+	mock *MockOrganizationCreator
+}
+
+// NewMockOrganizationCreator creates a new mock instance.
+func NewMockOrganizationCreator(ctrl *gomock.Controller) *MockOrganizationCreator {
+	mock := &MockOrganizationCreator{ctrl: ctrl}
+	mock.recorder = &MockOrganizationCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationCreator) EXPECT() *MockOrganizationCreatorMockRecorder {
+	return m.recorder
+}
+
+// CreateOrganization mocks base method.
+func (m *MockOrganizationCreator) CreateOrganization(ctx context.Context, username, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", ctx, username, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockOrganizationCreatorMockRecorder) CreateOrganization(ctx, username, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockOrganizationCreator)(nil).CreateOrganization), ctx, username, name)
+}
+
+// MockOrganizationDeleter is a mock of OrganizationDeleter interface.
+type MockOrganizationDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationDeleterMockRecorder
+}
+
+// MockOrganizationDeleterMockRecorder is the mock recorder for MockOrganizationDeleter.
+type MockOrganizationDeleterMockRecorder struct {
+	mock *MockOrganizationDeleter
+}
+
+// NewMockOrganizationDeleter creates a new mock instance.
+func NewMockOrganizationDeleter(ctrl *gomock.Controller) *MockOrganizationDeleter {
+	mock := &MockOrganizationDeleter{ctrl: ctrl}
+	mock.recorder = &MockOrganizationDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationDeleter) EXPECT() *MockOrganizationDeleterMockRecorder {
+	return m.recorder
+}
+
+// DeleteOrganization mocks base method.
+func (m *MockOrganizationDeleter) DeleteOrganization(ctx context.Context, username, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganization", ctx, username, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganization indicates an expected call of DeleteOrganization.
+func (mr *MockOrganizationDeleterMockRecorder) DeleteOrganization(ctx, username, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockOrganizationDeleter)(nil).DeleteOrganization), ctx, username, name)
+}
+
+// MockOrganizationLister is a mock of OrganizationLister interface.
+type MockOrganizationLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationListerMockRecorder
+}
+
+// MockOrganizationListerMockRecorder is the mock recorder for MockOrganizationLister.
+type MockOrganizationListerMockRecorder struct {
+	mock *MockOrganizationLister
+}
+
+// NewMockOrganizationLister creates a new mock instance.
+func NewMockOrganizationLister(ctrl *gomock.Controller) *MockOrganizationLister {
+	mock := &MockOrganizationLister{ctrl: ctrl}
+	mock.recorder = &MockOrganizationListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationLister) EXPECT() *MockOrganizationListerMockRecorder {
+	return m.recorder
+}
+
+// ListOrganizations mocks base method.
+func (m *MockOrganizationLister) ListOrganizations(ctx context.Context, username string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizations", ctx, username)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizations indicates an expected call of ListOrganizations.
+func (mr *MockOrganizationListerMockRecorder) ListOrganizations(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizations", reflect.TypeOf((*MockOrganizationLister)(nil).ListOrganizations), ctx, username)
+}
+
+// MockMemberSetter is a mock of MemberSetter interface.
+type MockMemberSetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMemberSetterMockRecorder
+}
+
+// MockMemberSetterMockRecorder is the mock recorder for MockMemberSetter.
+type MockMemberSetterMockRecorder struct {
+	mock *MockMemberSetter
+}
+
+// NewMockMemberSetter creates a new mock instance.
+func NewMockMemberSetter(ctrl *gomock.Controller) *MockMemberSetter {
+	mock := &MockMemberSetter{ctrl: ctrl}
+	mock.recorder = &MockMemberSetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMemberSetter) EXPECT() *MockMemberSetterMockRecorder {
+	return m.recorder
+}
+
+// SetMember mocks base method.
+func (m *MockMemberSetter) SetMember(ctx context.Context, username, organization, member, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, username, organization, member, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockMemberSetterMockRecorder) SetMember(ctx, username, organization, member, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockMemberSetter)(nil).SetMember), ctx, username, organization, member, role)
+}
+
+// MockMemberRemover is a mock of MemberRemover interface.
+type MockMemberRemover struct {
+	ctrl     *gomock.Controller
+	recorder *MockMemberRemoverMockRecorder
+}
+
+// MockMemberRemoverMockRecorder is the mock recorder for MockMemberRemover.
+type MockMemberRemoverMockRecorder struct {
+	mock *MockMemberRemover
+}
+
+// NewMockMemberRemover creates a new mock instance.
+func NewMockMemberRemover(ctrl *gomock.Controller) *MockMemberRemover {
+	mock := &MockMemberRemover{ctrl: ctrl}
+	mock.recorder = &MockMemberRemoverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMemberRemover) EXPECT() *MockMemberRemoverMockRecorder {
+	return m.recorder
+}
+
+// RemoveMember mocks base method.
+func (m *MockMemberRemover) RemoveMember(ctx context.Context, username, organization, member string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", ctx, username, organization, member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockMemberRemoverMockRecorder) RemoveMember(ctx, username, organization, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockMemberRemover)(nil).RemoveMember), ctx, username, organization, member)
+}
+
+// MockMemberLister is a mock of MemberLister interface.
+type MockMemberLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMemberListerMockRecorder
+}
+
+// MockMemberListerMockRecorder is the mock recorder for MockMemberLister.
+type MockMemberListerMockRecorder struct {
+	mock *MockMemberLister
+}
+
+// NewMockMemberLister creates a new mock instance.
+func NewMockMemberLister(ctrl *gomock.Controller) *MockMemberLister {
+	mock := &MockMemberLister{ctrl: ctrl}
+	mock.recorder = &MockMemberListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMemberLister) EXPECT() *MockMemberListerMockRecorder {
+	return m.recorder
+}
+
+// ListMembers mocks base method.
+func (m *MockMemberLister) ListMembers(ctx context.Context, username, organization string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, username, organization)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockMemberListerMockRecorder) ListMembers(ctx, username, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockMemberLister)(nil).ListMembers), ctx, username, organization)
+}
+
+// MockVaultCreator is a mock of VaultCreator interface.
+type MockVaultCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultCreatorMockRecorder
+}
+
+// MockVaultCreatorMockRecorder is the mock recorder for MockVaultCreator.
+type MockVaultCreatorMockRecorder struct {
+	mock *MockVaultCreator
+}
+
+// NewMockVaultCreator creates a new mock instance.
+func NewMockVaultCreator(ctrl *gomock.Controller) *MockVaultCreator {
+	mock := &MockVaultCreator{ctrl: ctrl}
+	mock.recorder = &MockVaultCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultCreator) EXPECT() *MockVaultCreatorMockRecorder {
+	return m.recorder
+}
+
+// CreateVault mocks base method.
+func (m *MockVaultCreator) CreateVault(ctx context.Context, username, organization, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVault", ctx, username, organization, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVault indicates an expected call of CreateVault.
+func (mr *MockVaultCreatorMockRecorder) CreateVault(ctx, username, organization, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVault", reflect.TypeOf((*MockVaultCreator)(nil).CreateVault), ctx, username, organization, name)
+}
+
+// MockVaultDeleter is a mock of VaultDeleter interface.
+type MockVaultDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultDeleterMockRecorder
+}
+
+// MockVaultDeleterMockRecorder is the mock recorder for MockVaultDeleter.
+type MockVaultDeleterMockRecorder struct {
+	mock *MockVaultDeleter
+}
+
+// NewMockVaultDeleter creates a new mock instance.
+func NewMockVaultDeleter(ctrl *gomock.Controller) *MockVaultDeleter {
+	mock := &MockVaultDeleter{ctrl: ctrl}
+	mock.recorder = &MockVaultDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultDeleter) EXPECT() *MockVaultDeleterMockRecorder {
+	return m.recorder
+}
+
+// DeleteVault mocks base method.
+func (m *MockVaultDeleter) DeleteVault(ctx context.Context, username, organization, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVault", ctx, username, organization, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVault indicates an expected call of DeleteVault.
+func (mr *MockVaultDeleterMockRecorder) DeleteVault(ctx, username, organization, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockVaultDeleter)(nil).DeleteVault), ctx, username, organization, name)
+}
+
+// MockVaultLister is a mock of VaultLister interface.
+type MockVaultLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultListerMockRecorder
+}
+
+// MockVaultListerMockRecorder is the mock recorder for MockVaultLister.
+type MockVaultListerMockRecorder struct {
+	mock *MockVaultLister
+}
+
+// NewMockVaultLister creates a new mock instance.
+func NewMockVaultLister(ctrl *gomock.Controller) *MockVaultLister {
+	mock := &MockVaultLister{ctrl: ctrl}
+	mock.recorder = &MockVaultListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultLister) EXPECT() *MockVaultListerMockRecorder {
+	return m.recorder
+}
+
+// ListVaults mocks base method.
+func (m *MockVaultLister) ListVaults(ctx context.Context, username, organization string) ([]*models.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaults", ctx, username, organization)
+	ret0, _ := ret[0].([]*models.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaults indicates an expected call of ListVaults.
+func (mr *MockVaultListerMockRecorder) ListVaults(ctx, username, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaults", reflect.TypeOf((*MockVaultLister)(nil).ListVaults), ctx, username, organization)
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationCreateHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		authHeader     string
+		expectedStatus int
+		mockSetup      func(creator *MockOrganizationCreator, parser *MockJWTParser)
+	}{
+		{
+			name:           "success",
+			body:           `{"name":"acme"}`,
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(creator *MockOrganizationCreator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				creator.EXPECT().CreateOrganization(gomock.Any(), "alice", "acme").Return(nil)
+			},
+		},
+		{
+			name:           "already exists",
+			body:           `{"name":"acme"}`,
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusConflict,
+			mockSetup: func(creator *MockOrganizationCreator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				creator.EXPECT().CreateOrganization(gomock.Any(), "alice", "acme").Return(services.ErrOrganizationExists)
+			},
+		},
+		{
+			name:           "invalid body",
+			body:           `{`,
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(creator *MockOrganizationCreator, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			},
+		},
+		{
+			name:           "unauthorized",
+			body:           `{"name":"acme"}`,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup:      func(creator *MockOrganizationCreator, parser *MockJWTParser) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			creator := NewMockOrganizationCreator(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(creator, parser)
+
+			req := httptest.NewRequest(http.MethodPost, "/organizations", strings.NewReader(tt.body))
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rr := httptest.NewRecorder()
+
+			NewOrganizationCreateHandler(creator, parser).ServeHTTP(rr, req)
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestOrganizationDeleteAndListHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parser := NewMockJWTParser(ctrl)
+	deleter := NewMockOrganizationDeleter(ctrl)
+	lister := NewMockOrganizationLister(ctrl)
+
+	parser.EXPECT().Parse("validtoken").Return("bob", nil)
+	deleter.EXPECT().DeleteOrganization(gomock.Any(), "bob", "acme").Return(services.ErrInsufficientRole)
+	req := httptest.NewRequest(http.MethodDelete, "/organizations/acme", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr := httptest.NewRecorder()
+	NewOrganizationDeleteHandler(deleter, parser).ServeHTTP(rr, withURLParams(req, map[string]string{"organization": "acme"}))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	parser.EXPECT().Parse("validtoken").Return("bob", nil)
+	lister.EXPECT().ListOrganizations(gomock.Any(), "bob").Return([]*models.Membership{
+		{Organization: "acme", Username: "bob", Role: models.RoleAdmin},
+	}, nil)
+	req = httptest.NewRequest(http.MethodGet, "/organizations", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr = httptest.NewRecorder()
+	NewOrganizationListHandler(lister, parser).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp []MembershipResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp, 1)
+	assert.Equal(t, "acme", resp[0].Organization)
+	assert.Equal(t, models.RoleAdmin, resp[0].Role)
+}
+
+func TestMemberHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parser := NewMockJWTParser(ctrl)
+	setter := NewMockMemberSetter(ctrl)
+	remover := NewMockMemberRemover(ctrl)
+	lister := NewMockMemberLister(ctrl)
+	params := map[string]string{"organization": "acme", "username": "carol"}
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil)
+	setter.EXPECT().SetMember(gomock.Any(), "alice", "acme", "carol", models.RoleReadOnly).Return(nil)
+	req := httptest.NewRequest(http.MethodPut, "/organizations/acme/members/carol", strings.NewReader(`{"role":"read-only"}`))
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr := httptest.NewRecorder()
+	NewMemberSetHandler(setter, parser).ServeHTTP(rr, withURLParams(req, params))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil)
+	setter.EXPECT().SetMember(gomock.Any(), "alice", "acme", "carol", "root").Return(services.ErrInvalidRole)
+	req = httptest.NewRequest(http.MethodPut, "/organizations/acme/members/carol", strings.NewReader(`{"role":"root"}`))
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr = httptest.NewRecorder()
+	NewMemberSetHandler(setter, parser).ServeHTTP(rr, withURLParams(req, params))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil)
+	remover.EXPECT().RemoveMember(gomock.Any(), "alice", "acme", "carol").Return(services.ErrMemberNotFound)
+	req = httptest.NewRequest(http.MethodDelete, "/organizations/acme/members/carol", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr = httptest.NewRecorder()
+	NewMemberRemoveHandler(remover, parser).ServeHTTP(rr, withURLParams(req, params))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	parser.EXPECT().Parse("validtoken").Return("eve", nil)
+	lister.EXPECT().ListMembers(gomock.Any(), "eve", "acme").Return(nil, services.ErrNotMember)
+	req = httptest.NewRequest(http.MethodGet, "/organizations/acme/members", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr = httptest.NewRecorder()
+	NewMemberListHandler(lister, parser).ServeHTTP(rr, withURLParams(req, params))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestVaultHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parser := NewMockJWTParser(ctrl)
+	creator := NewMockVaultCreator(ctrl)
+	deleter := NewMockVaultDeleter(ctrl)
+	lister := NewMockVaultLister(ctrl)
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil)
+	creator.EXPECT().CreateVault(gomock.Any(), "alice", "acme", "ops").Return(services.ErrVaultExists)
+	req := httptest.NewRequest(http.MethodPost, "/organizations/acme/vaults", strings.NewReader(`{"name":"ops"}`))
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr := httptest.NewRecorder()
+	NewVaultCreateHandler(creator, parser).ServeHTTP(rr, withURLParams(req, map[string]string{"organization": "acme"}))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil)
+	deleter.EXPECT().DeleteVault(gomock.Any(), "alice", "acme", "ops").Return(nil)
+	req = httptest.NewRequest(http.MethodDelete, "/organizations/acme/vaults/ops", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr = httptest.NewRecorder()
+	NewVaultDeleteHandler(deleter, parser).ServeHTTP(rr, withURLParams(req, map[string]string{"organization": "acme", "vault": "ops"}))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil)
+	lister.EXPECT().ListVaults(gomock.Any(), "alice", "acme").Return([]*models.Vault{{Organization: "acme", Name: "ops"}}, nil)
+	req = httptest.NewRequest(http.MethodGet, "/organizations/acme/vaults", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr = httptest.NewRecorder()
+	NewVaultListHandler(lister, parser).ServeHTTP(rr, withURLParams(req, map[string]string{"organization": "acme"}))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var resp []VaultResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp, 1)
+	assert.Equal(t, "ops", resp[0].Name)
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"time"
+)
+
+// LoginLimiter tracks failed authentication attempts and locks out abusive clients.
+type LoginLimiter interface {
+	// Check reports whether the attempt may proceed and, if not, how long to wait.
+	Check(ip, username string) (time.Duration, bool)
+	// Fail registers a failed attempt.
+	Fail(ctx context.Context, ip, username string)
+	// Succeed registers a successful attempt.
+	Succeed(ip, username string)
+}
+
+// maxAuthBodySize bounds the bodies of rate limited routes, which are read before authentication.
+const maxAuthBodySize = 16 << 10
+
+// attemptKey is the context key of the outcome a handler sets with markNeutral or markFailed.
+type attemptKey struct{}
+
+// attempt is the outcome of a request as reported by its handler, overriding the one of its result.
+type attempt int
+
+const (
+	attemptByResult attempt = iota
+	attemptNeutral
+	attemptFailed
+)
+
+// markNeutral tells the rate limit middleware that the request neither failed nor succeeded,
+// like the first step of a login that asks for the one-time code.
+func markNeutral(r *http.Request) {
+	setAttempt(r.Context(), attemptNeutral)
+}
+
+// markFailed tells the rate limit middleware that the request is a failed attempt whatever its result,
+// like a wrong one-time code, which is an invalid argument rather than an authentication failure.
+func markFailed(r *http.Request) {
+	setAttempt(r.Context(), attemptFailed)
+}
+
+func setAttempt(ctx context.Context, outcome attempt) {
+	if a, ok := ctx.Value(attemptKey{}).(*attempt); ok {
+		*a = outcome
+	}
+}
+
+// NewRateLimitMiddleware returns middleware that protects authentication routes from brute force.
+//
+// The username is taken from the JSON request body, which may hold up to 16 KiB. Requests from a
+// locked client IP or for a locked username are rejected with 429 and a Retry-After header.
+// Responses with 401 or 409 count as failed attempts unless the handler marked them neutral,
+// successful responses reset the username state.
+func NewRateLimitMiddleware(limiter LoginLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAuthBodySize))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var req struct {
+				Username string `json:"username"`
+			}
+			_ = json.Unmarshal(body, &req)
+
+			guard(limiter, next, w, r, req.Username)
+		})
+	}
+}
+
+// NewTokenRateLimitMiddleware returns middleware that protects routes re-checking the password of
+// an authenticated user, like password changes, from brute force with a stolen token.
+//
+// The username is taken from the bearer token. Requests without a valid token are passed on
+// untouched, the handler rejects them without counting a failed attempt. Otherwise requests are
+// limited like those of NewRateLimitMiddleware, with handlers marking every failure but a wrong
+// password or one-time code neutral.
+func NewTokenRateLimitMiddleware(limiter LoginLimiter, parser JWTParser) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, err := usernameFromRequest(r, parser)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			guard(limiter, next, w, r, username)
+		})
+	}
+}
+
+// guard serves r with next unless the client IP or username is locked, and registers the outcome.
+func guard(limiter LoginLimiter, next http.Handler, w http.ResponseWriter, r *http.Request, username string) {
+	ip := clientIP(r)
+
+	if wait, ok := limiter.Check(ip, username); !ok {
+		w.Header().Set("Retry-After", fmt.Sprint(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, "too many failed attempts, try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	outcome := new(attempt)
+	r = r.WithContext(context.WithValue(r.Context(), attemptKey{}, outcome))
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(sw, r)
+
+	switch {
+	case *outcome == attemptNeutral:
+	case *outcome == attemptFailed, sw.status == http.StatusUnauthorized, sw.status == http.StatusConflict:
+		limiter.Fail(r.Context(), ip, username)
+	case sw.status < http.StatusMultipleChoices:
+		limiter.Succeed(ip, username)
+	}
+}
+
+// statusWriter remembers the status code written by the wrapped handler.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// clientIP returns the host part of the request remote address.
+// When the server runs behind a proxy, chi middleware.RealIP rewrites RemoteAddr beforehand.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
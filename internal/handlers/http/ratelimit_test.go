@@ -0,0 +1,242 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		handlerStatus  int
+		neutral        bool
+		failed         bool
+		expectedStatus int
+		expectedRetry  string
+		mockSetup      func(l *MockLoginLimiter)
+	}{
+		{
+			name:           "success resets username",
+			body:           `{"username":"alice","password":"pass"}`,
+			handlerStatus:  http.StatusOK,
+			expectedStatus: http.StatusOK,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Succeed("192.0.2.1", "alice")
+			},
+		},
+		{
+			name:           "unauthorized counts as failure",
+			body:           `{"username":"alice","password":"wrong"}`,
+			handlerStatus:  http.StatusUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Fail(gomock.Any(), "192.0.2.1", "alice")
+			},
+		},
+		{
+			name:           "conflict counts as failure",
+			body:           `{"username":"alice","password":"pass"}`,
+			handlerStatus:  http.StatusConflict,
+			expectedStatus: http.StatusConflict,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Fail(gomock.Any(), "192.0.2.1", "alice")
+			},
+		},
+		{
+			name:           "one-time code request is neutral",
+			body:           `{"username":"alice","password":"pass"}`,
+			handlerStatus:  http.StatusUnauthorized,
+			neutral:        true,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+			},
+		},
+		{
+			name:           "wrong one-time code counts as failure",
+			body:           `{"username":"alice","password":"pass"}`,
+			handlerStatus:  http.StatusBadRequest,
+			failed:         true,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Fail(gomock.Any(), "192.0.2.1", "alice")
+			},
+		},
+		{
+			name:           "server error is neutral",
+			body:           `{"username":"alice","password":"pass"}`,
+			handlerStatus:  http.StatusInternalServerError,
+			expectedStatus: http.StatusInternalServerError,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+			},
+		},
+		{
+			name:           "locked out",
+			body:           `{"username":"alice","password":"pass"}`,
+			expectedStatus: http.StatusTooManyRequests,
+			expectedRetry:  "91",
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "alice").Return(90*time.Second+time.Millisecond, false)
+			},
+		},
+		{
+			name:           "invalid json still limited by ip",
+			body:           `invalid-json`,
+			handlerStatus:  http.StatusBadRequest,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(l *MockLoginLimiter) {
+				l.EXPECT().Check("192.0.2.1", "").Return(time.Duration(0), true)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limiter := NewMockLoginLimiter(ctrl)
+			tt.mockSetup(limiter)
+
+			var gotBody string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				buf := new(bytes.Buffer)
+				_, _ = buf.ReadFrom(r.Body)
+				gotBody = buf.String()
+				if tt.neutral {
+					markNeutral(r)
+				}
+				if tt.failed {
+					markFailed(r)
+				}
+				w.WriteHeader(tt.handlerStatus)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader([]byte(tt.body)))
+			req.RemoteAddr = "192.0.2.1:1234"
+			rec := httptest.NewRecorder()
+
+			NewRateLimitMiddleware(limiter)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedRetry != "" {
+				assert.Equal(t, tt.expectedRetry, rec.Header().Get("Retry-After"))
+			} else {
+				assert.Equal(t, tt.body, gotBody)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddleware_BodyTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("handler called")
+	})
+	body := `{"username":"` + strings.Repeat("a", maxAuthBodySize) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	NewRateLimitMiddleware(NewMockLoginLimiter(ctrl))(next).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestTokenRateLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		auth           string
+		handlerStatus  int
+		expectedStatus int
+		mockSetup      func(l *MockLoginLimiter, p *MockJWTParser)
+	}{
+		{
+			name:           "wrong password counts as failure of the token user",
+			auth:           "Bearer token",
+			handlerStatus:  http.StatusUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(l *MockLoginLimiter, p *MockJWTParser) {
+				p.EXPECT().Parse("token").Return("alice", nil)
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Fail(gomock.Any(), "192.0.2.1", "alice")
+			},
+		},
+		{
+			name:           "success resets username",
+			auth:           "Bearer token",
+			handlerStatus:  http.StatusOK,
+			expectedStatus: http.StatusOK,
+			mockSetup: func(l *MockLoginLimiter, p *MockJWTParser) {
+				p.EXPECT().Parse("token").Return("alice", nil)
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Duration(0), true)
+				l.EXPECT().Succeed("192.0.2.1", "alice")
+			},
+		},
+		{
+			name:           "locked out",
+			auth:           "Bearer token",
+			expectedStatus: http.StatusTooManyRequests,
+			mockSetup: func(l *MockLoginLimiter, p *MockJWTParser) {
+				p.EXPECT().Parse("token").Return("alice", nil)
+				l.EXPECT().Check("192.0.2.1", "alice").Return(time.Minute, false)
+			},
+		},
+		{
+			name:           "invalid token is not counted",
+			auth:           "Bearer expired",
+			handlerStatus:  http.StatusUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(l *MockLoginLimiter, p *MockJWTParser) {
+				p.EXPECT().Parse("expired").Return("", errors.New("token expired"))
+			},
+		},
+		{
+			name:           "missing token is not counted",
+			handlerStatus:  http.StatusUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup:      func(l *MockLoginLimiter, p *MockJWTParser) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			limiter := NewMockLoginLimiter(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(limiter, parser)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.handlerStatus)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/password", strings.NewReader(`{"old_password":"x"}`))
+			req.RemoteAddr = "192.0.2.1:1234"
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+
+			NewTokenRateLimitMiddleware(limiter, parser)(next).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
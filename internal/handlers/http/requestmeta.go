@@ -0,0 +1,19 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/sbilibin2017/gophkeeper/internal/requestmeta"
+)
+
+// NewRequestMetaMiddleware returns middleware that stores the transport and the client IP
+// in the request context for auditing.
+func NewRequestMetaMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := requestmeta.WithTransport(r.Context(), requestmeta.TransportHTTP)
+			ctx = requestmeta.WithClientIP(ctx, clientIP(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sbilibin2017/gophkeeper/internal/requestmeta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMetaMiddleware(t *testing.T) {
+	var transport, ip string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport = requestmeta.Transport(r.Context())
+		ip = requestmeta.ClientIP(r.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/secrets", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	NewRequestMetaMiddleware()(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, requestmeta.TransportHTTP, transport)
+	assert.Equal(t, "192.0.2.1", ip)
+}
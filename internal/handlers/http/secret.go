@@ -6,20 +6,23 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
 )
 
 // SecretWriter defines interface to save secrets.
 type SecretWriter interface {
-	Save(ctx context.Context, username, secretName, secretType string, ciphertext, aesKeyEnc []byte) error
+	Save(ctx context.Context, username, vault, secretName, secretType string, ciphertext, aesKeyEnc []byte, keyID string, recipients models.Recipients) error
+	SaveBatch(ctx context.Context, username string, secrets []*models.Secret) error
 }
 
 // SecretReader defines interface to read secrets.
 type SecretReader interface {
-	Get(ctx context.Context, username, secretType, secretName string) (*models.Secret, error)
-	List(ctx context.Context, username string) ([]*models.Secret, error)
+	Get(ctx context.Context, username, vault, secretType, secretName string) (*models.Secret, error)
+	List(ctx context.Context, username, vault string) ([]*models.Secret, error)
 }
 
 // JWTParser parses JWT token and returns username or error.
@@ -42,6 +45,28 @@ type SecretSaveRequest struct {
 	Ciphertext []byte `json:"ciphertext"`
 	// Encrypted AES key
 	AESKeyEnc []byte `json:"aes_key_enc"`
+	// Identifier of the public key the AES key is wrapped with
+	// example: 9f86d081884c7d659a2feaa0c55ad015
+	KeyID string `json:"key_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	// AES key wrapped for every recipient when there is more than one
+	Recipients []SecretRecipient `json:"recipients,omitempty"`
+	// Organization vault as organization/vault, empty for a personal secret
+	// example: acme/ops
+	Vault string `json:"vault,omitempty" example:"acme/ops"`
+	// Time the secret was created, kept by batch saves of restored secrets; empty for now
+	CreatedAt time.Time `json:"created_at"`
+	// Time the secret was last updated, kept by batch saves of restored secrets; empty for now
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SecretRecipient holds the AES key wrapped with one recipient's public key.
+// swagger:model SecretRecipient
+type SecretRecipient struct {
+	// Identifier of the recipient's public key
+	// example: 9f86d081884c7d659a2feaa0c55ad015
+	KeyID string `json:"key_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	// AES key wrapped with the recipient's public key
+	AESKeyEnc []byte `json:"aes_key_enc"`
 }
 
 // SecretResponse represents secret data returned in responses.
@@ -55,12 +80,18 @@ type SecretResponse struct {
 	Ciphertext []byte `json:"ciphertext"`
 	// Encrypted AES key
 	AESKeyEnc []byte `json:"aes_key_enc"`
+	// Identifier of the public key the AES key is wrapped with
+	KeyID string `json:"key_id"`
+	// AES key wrapped for every recipient when there is more than one
+	Recipients []SecretRecipient `json:"recipients"`
+	// Organization vault as organization/vault, empty for a personal secret
+	Vault string `json:"vault,omitempty"`
 }
 
 // NewSecretAddHandler returns an HTTP handler that saves a secret.
 //
 // @Summary Save a secret
-// @Description Saves a secret for authenticated user
+// @Description Saves a secret for authenticated user, or to an organization vault the user is at least a member of
 // @Tags secrets
 // @Accept json
 // @Produce json
@@ -68,6 +99,8 @@ type SecretResponse struct {
 // @Success 200 {string} string "ok"
 // @Failure 400 {string} string "invalid request body"
 // @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not allowed to write to the vault"
+// @Failure 404 {string} string "vault not found"
 // @Failure 500 {string} string "internal server error"
 // @Router /secrets [post]
 func NewSecretAddHandler(writer SecretWriter, parser JWTParser) http.HandlerFunc {
@@ -98,8 +131,58 @@ func NewSecretAddHandler(writer SecretWriter, parser JWTParser) http.HandlerFunc
 			return
 		}
 
-		if err := writer.Save(ctx, username, req.SecretName, req.SecretType, req.Ciphertext, req.AESKeyEnc); err != nil {
-			http.Error(w, "failed to save secret", http.StatusInternalServerError)
+		if err := writer.Save(ctx, username, req.Vault, req.SecretName, req.SecretType, req.Ciphertext, req.AESKeyEnc, req.KeyID, toRecipients(req.Recipients)); err != nil {
+			writeSecretError(w, err, "failed to save secret")
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewSecretBatchSaveHandler returns an HTTP handler that saves several secrets atomically.
+// Either all secrets of the request are stored or none.
+//
+// @Summary Save secrets in one transaction
+// @Description Saves several secrets for authenticated user atomically, used for key rotation
+// @Tags secrets
+// @Accept json
+// @Param secrets body []SecretSaveRequest true "Secrets to save"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Router /secrets/batch [post]
+func NewSecretBatchSaveHandler(writer SecretWriter, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req []SecretSaveRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		secrets := make([]*models.Secret, 0, len(req))
+		for _, s := range req {
+			secrets = append(secrets, &models.Secret{
+				SecretName: s.SecretName,
+				SecretType: s.SecretType,
+				Ciphertext: s.Ciphertext,
+				AESKeyEnc:  s.AESKeyEnc,
+				KeyID:      s.KeyID,
+				Recipients: toRecipients(s.Recipients),
+				CreatedAt:  s.CreatedAt,
+				UpdatedAt:  s.UpdatedAt,
+			})
+		}
+
+		if err := writer.SaveBatch(r.Context(), username, secrets); err != nil {
+			http.Error(w, "failed to save secrets", http.StatusInternalServerError)
 			return
 		}
 
@@ -110,15 +193,18 @@ func NewSecretAddHandler(writer SecretWriter, parser JWTParser) http.HandlerFunc
 // NewSecretGetHandler returns an HTTP handler that retrieves a secret by type and name.
 //
 // @Summary Get a secret
-// @Description Retrieves a secret for authenticated user by secret_type and secret_name
+// @Description Retrieves a secret for authenticated user by secret_type and secret_name, or from an organization vault
 // @Tags secrets
 // @Accept json
 // @Produce json
 // @Param secret_type path string true "Secret type"
 // @Param secret_name path string true "Secret name"
+// @Param vault query string false "Organization vault as organization/vault"
 // @Success 200 {object} SecretResponse
 // @Failure 400 {string} string "missing parameters"
 // @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not a member of the organization"
+// @Failure 404 {string} string "secret or vault not found"
 // @Failure 500 {string} string "internal server error"
 // @Router /secrets/{secret_type}/{secret_name} [get]
 func NewSecretGetHandler(reader SecretReader, parser JWTParser) http.HandlerFunc {
@@ -150,9 +236,9 @@ func NewSecretGetHandler(reader SecretReader, parser JWTParser) http.HandlerFunc
 			return
 		}
 
-		secret, err := reader.Get(ctx, username, secretType, secretName)
+		secret, err := reader.Get(ctx, username, r.URL.Query().Get("vault"), secretType, secretName)
 		if err != nil {
-			http.Error(w, "failed to get secret", http.StatusInternalServerError)
+			writeSecretError(w, err, "failed to get secret")
 			return
 		}
 
@@ -167,12 +253,15 @@ func NewSecretGetHandler(reader SecretReader, parser JWTParser) http.HandlerFunc
 // NewSecretListHandler returns an HTTP handler that lists all secrets for a user.
 //
 // @Summary List all secrets
-// @Description Lists all secrets for authenticated user
+// @Description Lists all secrets for authenticated user, or all secrets of an organization vault
 // @Tags secrets
 // @Accept json
 // @Produce json
+// @Param vault query string false "Organization vault as organization/vault"
 // @Success 200 {array} SecretResponse
 // @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "not a member of the organization"
+// @Failure 404 {string} string "vault not found"
 // @Failure 500 {string} string "internal server error"
 // @Router /secrets [get]
 func NewSecretListHandler(reader SecretReader, parser JWTParser) http.HandlerFunc {
@@ -197,9 +286,9 @@ func NewSecretListHandler(reader SecretReader, parser JWTParser) http.HandlerFun
 			return
 		}
 
-		secrets, err := reader.List(ctx, username)
+		secrets, err := reader.List(ctx, username, r.URL.Query().Get("vault"))
 		if err != nil {
-			http.Error(w, "failed to list secrets", http.StatusInternalServerError)
+			writeSecretError(w, err, "failed to list secrets")
 			return
 		}
 
@@ -210,3 +299,32 @@ func NewSecretListHandler(reader SecretReader, parser JWTParser) http.HandlerFun
 		}
 	}
 }
+
+// toRecipients converts request recipients to the model representation.
+func toRecipients(in []SecretRecipient) models.Recipients {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(models.Recipients, 0, len(in))
+	for _, r := range in {
+		out = append(out, models.Recipient{KeyID: r.KeyID, AESKeyEnc: r.AESKeyEnc})
+	}
+	return out
+}
+
+// writeSecretError maps errors of organization vault access to HTTP statuses,
+// any other error is reported as an internal error with msg.
+func writeSecretError(w http.ResponseWriter, err error, msg string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidVault):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrNotMember), errors.Is(err, services.ErrInsufficientRole):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrVaultNotFound), errors.Is(err, services.ErrSecretNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrVaultsNotConfigured):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		http.Error(w, msg, http.StatusInternalServerError)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,6 +33,11 @@ func TestNewSecretAddHandler(t *testing.T) {
 				SecretType: "password",
 				Ciphertext: []byte("encrypted"),
 				AESKeyEnc:  []byte("keyenc"),
+				KeyID:      "key-1",
+				Recipients: []SecretRecipient{
+					{KeyID: "key-1", AESKeyEnc: []byte("keyenc")},
+					{KeyID: "key-2", AESKeyEnc: []byte("keyenc-2")},
+				},
 			},
 			expectedStatus: http.StatusOK,
 			mockSetup: func(ctrl *gomock.Controller) (SecretWriter, JWTParser) {
@@ -40,7 +46,10 @@ func TestNewSecretAddHandler(t *testing.T) {
 
 				mockParser.EXPECT().Parse("validtoken").Return("alice", nil).Times(1)
 				mockWriter.EXPECT().
-					Save(gomock.Any(), "alice", "mysecret", "password", []byte("encrypted"), []byte("keyenc")).
+					Save(gomock.Any(), "alice", "", "mysecret", "password", []byte("encrypted"), []byte("keyenc"), "key-1", models.Recipients{
+						{KeyID: "key-1", AESKeyEnc: []byte("keyenc")},
+						{KeyID: "key-2", AESKeyEnc: []byte("keyenc-2")},
+					}).
 					Return(nil).
 					Times(1)
 
@@ -113,13 +122,36 @@ func TestNewSecretAddHandler(t *testing.T) {
 
 				mockParser.EXPECT().Parse("token123").Return("bob", nil).Times(1)
 				mockWriter.EXPECT().
-					Save(gomock.Any(), "bob", "sn", "st", []byte("ct"), []byte("ak")).
+					Save(gomock.Any(), "bob", "", "sn", "st", []byte("ct"), []byte("ak"), "", nil).
 					Return(errors.New("db failure")).
 					Times(1)
 
 				return mockWriter, mockParser
 			},
 		},
+		{
+			name:       "read-only vault member",
+			authHeader: "Bearer token123",
+			requestBody: SecretSaveRequest{
+				SecretName: "sn",
+				SecretType: "st",
+				Vault:      "acme/ops",
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   services.ErrInsufficientRole.Error() + "\n",
+			mockSetup: func(ctrl *gomock.Controller) (SecretWriter, JWTParser) {
+				mockWriter := NewMockSecretWriter(ctrl)
+				mockParser := NewMockJWTParser(ctrl)
+
+				mockParser.EXPECT().Parse("token123").Return("bob", nil).Times(1)
+				mockWriter.EXPECT().
+					Save(gomock.Any(), "bob", "acme/ops", "sn", "st", nil, nil, "", nil).
+					Return(services.ErrInsufficientRole).
+					Times(1)
+
+				return mockWriter, mockParser
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +193,86 @@ func TestNewSecretAddHandler(t *testing.T) {
 	}
 }
 
+func TestNewSecretBatchSaveHandler(t *testing.T) {
+	want := []*models.Secret{
+		{SecretName: "s1", SecretType: "text", Ciphertext: []byte("c1"), AESKeyEnc: []byte("k1"), KeyID: "key-2"},
+		{SecretName: "s2", SecretType: "user", Ciphertext: []byte("c2"), AESKeyEnc: []byte("k2"), KeyID: "key-2"},
+	}
+	body := []SecretSaveRequest{
+		{SecretName: "s1", SecretType: "text", Ciphertext: []byte("c1"), AESKeyEnc: []byte("k1"), KeyID: "key-2"},
+		{SecretName: "s2", SecretType: "user", Ciphertext: []byte("c2"), AESKeyEnc: []byte("k2"), KeyID: "key-2"},
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		expectedStatus int
+		mockSetup      func(writer *MockSecretWriter, parser *MockJWTParser)
+	}{
+		{
+			name:           "success",
+			requestBody:    body,
+			expectedStatus: http.StatusOK,
+			mockSetup: func(writer *MockSecretWriter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				writer.EXPECT().SaveBatch(gomock.Any(), "alice", want).Return(nil)
+			},
+		},
+		{
+			name:           "invalid json",
+			requestBody:    "invalid-json",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(writer *MockSecretWriter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			},
+		},
+		{
+			name:           "save fails",
+			requestBody:    body,
+			expectedStatus: http.StatusInternalServerError,
+			mockSetup: func(writer *MockSecretWriter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				writer.EXPECT().SaveBatch(gomock.Any(), "alice", want).Return(errors.New("tx error"))
+			},
+		},
+		{
+			name:           "unauthorized",
+			requestBody:    body,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup: func(writer *MockSecretWriter, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("", errors.New("bad token"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			writer := NewMockSecretWriter(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(writer, parser)
+
+			var bodyBytes []byte
+			switch v := tt.requestBody.(type) {
+			case string:
+				bodyBytes = []byte(v)
+			default:
+				bodyBytes, _ = json.Marshal(v)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/secrets/batch", bytes.NewReader(bodyBytes))
+			req.Header.Set("Authorization", "Bearer validtoken")
+			rec := httptest.NewRecorder()
+
+			NewSecretBatchSaveHandler(writer, parser).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
 func TestNewSecretGetHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -183,7 +295,7 @@ func TestNewSecretGetHandler(t *testing.T) {
 
 				mockParser.EXPECT().Parse("validtoken").Return("alice", nil).Times(1)
 				mockReader.EXPECT().
-					Get(gomock.Any(), "alice", "password", "mysecret").
+					Get(gomock.Any(), "alice", "", "password", "mysecret").
 					Return(&models.Secret{
 						SecretName: "mysecret",
 						SecretType: "password",
@@ -256,7 +368,7 @@ func TestNewSecretGetHandler(t *testing.T) {
 
 				mockParser.EXPECT().Parse("token123").Return("bob", nil).Times(1)
 				mockReader.EXPECT().
-					Get(gomock.Any(), "bob", "st", "sn").
+					Get(gomock.Any(), "bob", "", "st", "sn").
 					Return(nil, errors.New("db failure")).
 					Times(1)
 
@@ -324,7 +436,7 @@ func TestNewSecretListHandler(t *testing.T) {
 
 				mockParser.EXPECT().Parse("validtoken").Return("alice", nil).Times(1)
 				mockReader.EXPECT().
-					List(gomock.Any(), "alice").
+					List(gomock.Any(), "alice", "").
 					Return([]*models.Secret{
 						{
 							SecretName: "s1",
@@ -384,7 +496,7 @@ func TestNewSecretListHandler(t *testing.T) {
 
 				mockParser.EXPECT().Parse("token123").Return("bob", nil).Times(1)
 				mockReader.EXPECT().
-					List(gomock.Any(), "bob").
+					List(gomock.Any(), "bob", "").
 					Return(nil, errors.New("db failure")).
 					Times(1)
 
@@ -0,0 +1,377 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+)
+
+// CertificatePublisher defines interface for publishing the certificate of a user.
+type CertificatePublisher interface {
+	// PublishCertificate stores the PEM certificate other users wrap shared keys for.
+	PublishCertificate(ctx context.Context, username, certificate string) error
+}
+
+// CertificateGetter defines interface for reading the published certificate of a user.
+type CertificateGetter interface {
+	// GetCertificate returns the PEM certificate published by a user.
+	GetCertificate(ctx context.Context, username string) (string, error)
+}
+
+// SecretSharer defines interface for sharing a secret with another user.
+type SecretSharer interface {
+	// Share grants recipient access to a secret of owner.
+	Share(
+		ctx context.Context,
+		owner, secretType, secretName, recipient string,
+		aesKeyEnc []byte,
+		keyID, permission string,
+	) error
+}
+
+// SecretUnsharer defines interface for revoking access to a shared secret.
+type SecretUnsharer interface {
+	// Unshare revokes the access of recipient to a secret of owner.
+	Unshare(ctx context.Context, owner, secretType, secretName, recipient string) error
+}
+
+// SharedLister defines interface for listing secrets shared with a user.
+type SharedLister interface {
+	// ListSharedWithMe returns all secrets other users share with username.
+	ListSharedWithMe(ctx context.Context, username string) ([]*models.SharedSecret, error)
+}
+
+// SharedSaver defines interface for updating a secret shared read-write.
+type SharedSaver interface {
+	// SaveShared replaces the content of a secret of owner shared with username.
+	SaveShared(ctx context.Context, username, owner, secretType, secretName string, ciphertext []byte) error
+}
+
+// CertificateRequest represents the expected request body for publishing a certificate.
+// swagger:model CertificateRequest
+type CertificateRequest struct {
+	// PEM encoded certificate or public key
+	Certificate string `json:"certificate" example:"-----BEGIN CERTIFICATE-----..."`
+}
+
+// CertificateResponse represents the published certificate of a user.
+// swagger:model CertificateResponse
+type CertificateResponse struct {
+	// PEM encoded certificate or public key
+	Certificate string `json:"certificate" example:"-----BEGIN CERTIFICATE-----..."`
+}
+
+// ShareRequest represents the expected request body for sharing a secret.
+// swagger:model ShareRequest
+type ShareRequest struct {
+	// Secret type
+	// example: password
+	SecretType string `json:"secret_type" example:"password"`
+	// Secret name
+	// example: mysecret
+	SecretName string `json:"secret_name" example:"mysecret"`
+	// User the secret is shared with
+	// example: janedoe
+	Recipient string `json:"recipient" example:"janedoe"`
+	// AES key of the secret wrapped with the recipient's certificate
+	AESKeyEnc []byte `json:"aes_key_enc"`
+	// Identifier of the recipient's certificate
+	// example: 9f86d081884c7d659a2feaa0c55ad015
+	KeyID string `json:"key_id" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	// Permission of the recipient: ro or rw
+	// example: ro
+	Permission string `json:"permission" example:"ro"`
+}
+
+// SharedSecretResponse represents a secret shared with the authenticated user.
+// swagger:model SharedSecretResponse
+type SharedSecretResponse struct {
+	// Secret name
+	SecretName string `json:"secret_name"`
+	// Secret type
+	SecretType string `json:"secret_type"`
+	// Owner of the secret
+	SecretOwner string `json:"secret_owner"`
+	// Ciphertext bytes
+	Ciphertext []byte `json:"ciphertext"`
+	// AES key wrapped with the certificate of the authenticated user
+	AESKeyEnc []byte `json:"aes_key_enc"`
+	// Identifier of the certificate the AES key is wrapped with
+	KeyID string `json:"key_id"`
+	// Permission of the authenticated user: ro or rw
+	Permission string `json:"permission"`
+	// Time the secret was created
+	CreatedAt time.Time `json:"created_at"`
+	// Time the secret was last updated
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SharedSaveRequest represents the expected request body for updating a shared secret.
+// swagger:model SharedSaveRequest
+type SharedSaveRequest struct {
+	// Ciphertext bytes encrypted with the existing data key of the secret
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// NewCertificatePublishHandler returns an HTTP handler that publishes the certificate of a user.
+//
+// @Summary Publish certificate
+// @Description Publishes the certificate other users wrap the keys of shared secrets for
+// @Tags sharing
+// @Accept json
+// @Param certificateRequest body CertificateRequest true "PEM certificate"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body or certificate"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Router /certificate [put]
+func NewCertificatePublishHandler(publisher CertificatePublisher, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req CertificateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := publisher.PublishCertificate(r.Context(), username, req.Certificate); err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewCertificateGetHandler returns an HTTP handler that returns the published certificate of a user.
+//
+// @Summary Get certificate
+// @Description Returns the certificate a user published for sharing
+// @Tags sharing
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} CertificateResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "user or certificate not found"
+// @Failure 500 {string} string "internal server error"
+// @Router /users/{username}/certificate [get]
+func NewCertificateGetHandler(getter CertificateGetter, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, err := usernameFromRequest(r, parser); err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		cert, err := getter.GetCertificate(r.Context(), chi.URLParam(r, "username"))
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(CertificateResponse{Certificate: cert}); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// NewShareHandler returns an HTTP handler that shares a secret with another user.
+//
+// @Summary Share a secret
+// @Description Shares a secret of authenticated user read-only or read-write; sharing again replaces the key and permission
+// @Tags sharing
+// @Accept json
+// @Param shareRequest body ShareRequest true "Share request payload"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body or permission"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "secret, user or certificate not found"
+// @Failure 409 {string} string "key is not wrapped for the recipient's current certificate"
+// @Failure 500 {string} string "internal server error"
+// @Router /shares [post]
+func NewShareHandler(sharer SecretSharer, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req ShareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		err = sharer.Share(r.Context(), username,
+			req.SecretType, req.SecretName, req.Recipient,
+			req.AESKeyEnc, req.KeyID, req.Permission,
+		)
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewUnshareHandler returns an HTTP handler that revokes access to a shared secret.
+//
+// @Summary Unshare a secret
+// @Description Revokes the access of a user to a secret of authenticated user
+// @Tags sharing
+// @Param secret_type path string true "Secret type"
+// @Param secret_name path string true "Secret name"
+// @Param recipient path string true "User the secret is shared with"
+// @Success 200 {string} string "ok"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 404 {string} string "share not found"
+// @Failure 500 {string} string "internal server error"
+// @Router /shares/{secret_type}/{secret_name}/{recipient} [delete]
+func NewUnshareHandler(unsharer SecretUnsharer, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		err = unsharer.Unshare(r.Context(), username,
+			chi.URLParam(r, "secret_type"), chi.URLParam(r, "secret_name"), chi.URLParam(r, "recipient"),
+		)
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// NewSharedListHandler returns an HTTP handler that lists secrets shared with a user.
+//
+// @Summary List shared secrets
+// @Description Lists secrets other users share with authenticated user
+// @Tags sharing
+// @Produce json
+// @Success 200 {array} SharedSecretResponse
+// @Failure 401 {string} string "unauthorized"
+// @Failure 500 {string} string "internal server error"
+// @Router /shares [get]
+func NewSharedListHandler(lister SharedLister, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		secrets, err := lister.ListSharedWithMe(r.Context(), username)
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		resp := make([]SharedSecretResponse, 0, len(secrets))
+		for _, secret := range secrets {
+			resp = append(resp, SharedSecretResponse{
+				SecretName:  secret.SecretName,
+				SecretType:  secret.SecretType,
+				SecretOwner: secret.SecretOwner,
+				Ciphertext:  secret.Ciphertext,
+				AESKeyEnc:   secret.AESKeyEnc,
+				KeyID:       secret.KeyID,
+				Permission:  secret.Permission,
+				CreatedAt:   secret.CreatedAt,
+				UpdatedAt:   secret.UpdatedAt,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// NewSharedSaveHandler returns an HTTP handler that updates a secret shared read-write.
+//
+// @Summary Update a shared secret
+// @Description Replaces the content of a secret another user shares read-write with authenticated user
+// @Tags sharing
+// @Accept json
+// @Param secret_owner path string true "Owner of the secret"
+// @Param secret_type path string true "Secret type"
+// @Param secret_name path string true "Secret name"
+// @Param sharedSaveRequest body SharedSaveRequest true "New ciphertext"
+// @Success 200 {string} string "ok"
+// @Failure 400 {string} string "invalid request body"
+// @Failure 401 {string} string "unauthorized"
+// @Failure 403 {string} string "secret is shared read-only"
+// @Failure 404 {string} string "share not found"
+// @Failure 409 {string} string "secret has a new data key, the owner has to share it again"
+// @Failure 500 {string} string "internal server error"
+// @Router /shared/{secret_owner}/{secret_type}/{secret_name} [put]
+func NewSharedSaveHandler(saver SharedSaver, parser JWTParser) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		username, err := usernameFromRequest(r, parser)
+		if err != nil {
+			http.Error(w, ErrUnauthorized.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req SharedSaveRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		err = saver.SaveShared(r.Context(), username,
+			chi.URLParam(r, "secret_owner"), chi.URLParam(r, "secret_type"), chi.URLParam(r, "secret_name"),
+			req.Ciphertext,
+		)
+		if err != nil {
+			writeShareError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// writeShareError maps errors of sharing and certificate publishing to HTTP statuses.
+func writeShareError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPermission),
+		errors.Is(err, services.ErrShareWithSelf),
+		errors.Is(err, services.ErrInvalidCertificate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrSecretNotFound),
+		errors.Is(err, services.ErrShareNotFound),
+		errors.Is(err, services.ErrUserNotFound),
+		errors.Is(err, services.ErrNoCertificate):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrRecipientKeyOutdated), errors.Is(err, services.ErrShareStale):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrShareReadOnly):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, services.ErrCertNotConfigured):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,237 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/handlers/http/share.go
+
+// Package http is a generated GoMock package.
+package http
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockCertificatePublisher is a mock of CertificatePublisher interface.
+type MockCertificatePublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificatePublisherMockRecorder
+}
+
+// MockCertificatePublisherMockRecorder is the mock recorder for MockCertificatePublisher.
+type MockCertificatePublisherMockRecorder struct {
+	mock *MockCertificatePublisher
+}
+
+// NewMockCertificatePublisher creates a new mock instance.
+func NewMockCertificatePublisher(ctrl *gomock.Controller) *MockCertificatePublisher {
+	mock := &MockCertificatePublisher{ctrl: ctrl}
+	mock.recorder = &MockCertificatePublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificatePublisher) EXPECT() *MockCertificatePublisherMockRecorder {
+	return m.recorder
+}
+
+// PublishCertificate mocks base method.
+func (m *MockCertificatePublisher) PublishCertificate(ctx context.Context, username, certificate string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishCertificate", ctx, username, certificate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishCertificate indicates an expected call of PublishCertificate.
+func (mr *MockCertificatePublisherMockRecorder) PublishCertificate(ctx, username, certificate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishCertificate", reflect.TypeOf((*MockCertificatePublisher)(nil).PublishCertificate), ctx, username, certificate)
+}
+
+// MockCertificateGetter is a mock of CertificateGetter interface.
+type MockCertificateGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificateGetterMockRecorder
+}
+
+// MockCertificateGetterMockRecorder is the mock recorder for MockCertificateGetter.
+type MockCertificateGetterMockRecorder struct {
+	mock *MockCertificateGetter
+}
+
+// NewMockCertificateGetter creates a new mock instance.
+func NewMockCertificateGetter(ctrl *gomock.Controller) *MockCertificateGetter {
+	mock := &MockCertificateGetter{ctrl: ctrl}
+	mock.recorder = &MockCertificateGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificateGetter) EXPECT() *MockCertificateGetterMockRecorder {
+	return m.recorder
+}
+
+// GetCertificate mocks base method.
+func (m *MockCertificateGetter) GetCertificate(ctx context.Context, username string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCertificate", ctx, username)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCertificate indicates an expected call of GetCertificate.
+func (mr *MockCertificateGetterMockRecorder) GetCertificate(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCertificate", reflect.TypeOf((*MockCertificateGetter)(nil).GetCertificate), ctx, username)
+}
+
+// MockSecretSharer is a mock of SecretSharer interface.
+type MockSecretSharer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretSharerMockRecorder
+}
+
+// MockSecretSharerMockRecorder is the mock recorder for MockSecretSharer.
+type MockSecretSharerMockRecorder struct {
+	mock *MockSecretSharer
+}
+
+// NewMockSecretSharer creates a new mock instance.
+func NewMockSecretSharer(ctrl *gomock.Controller) *MockSecretSharer {
+	mock := &MockSecretSharer{ctrl: ctrl}
+	mock.recorder = &MockSecretSharerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretSharer) EXPECT() *MockSecretSharerMockRecorder {
+	return m.recorder
+}
+
+// Share mocks base method.
+func (m *MockSecretSharer) Share(ctx context.Context, owner, secretType, secretName, recipient string, aesKeyEnc []byte, keyID, permission string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Share", ctx, owner, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Share indicates an expected call of Share.
+func (mr *MockSecretSharerMockRecorder) Share(ctx, owner, secretType, secretName, recipient, aesKeyEnc, keyID, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Share", reflect.TypeOf((*MockSecretSharer)(nil).Share), ctx, owner, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+}
+
+// MockSecretUnsharer is a mock of SecretUnsharer interface.
+type MockSecretUnsharer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretUnsharerMockRecorder
+}
+
+// MockSecretUnsharerMockRecorder is the mock recorder for MockSecretUnsharer.
+type MockSecretUnsharerMockRecorder struct {
+	mock *MockSecretUnsharer
+}
+
+// NewMockSecretUnsharer creates a new mock instance.
+func NewMockSecretUnsharer(ctrl *gomock.Controller) *MockSecretUnsharer {
+	mock := &MockSecretUnsharer{ctrl: ctrl}
+	mock.recorder = &MockSecretUnsharerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretUnsharer) EXPECT() *MockSecretUnsharerMockRecorder {
+	return m.recorder
+}
+
+// Unshare mocks base method.
+func (m *MockSecretUnsharer) Unshare(ctx context.Context, owner, secretType, secretName, recipient string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unshare", ctx, owner, secretType, secretName, recipient)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unshare indicates an expected call of Unshare.
+func (mr *MockSecretUnsharerMockRecorder) Unshare(ctx, owner, secretType, secretName, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unshare", reflect.TypeOf((*MockSecretUnsharer)(nil).Unshare), ctx, owner, secretType, secretName, recipient)
+}
+
+// MockSharedLister is a mock of SharedLister interface.
+type MockSharedLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharedListerMockRecorder
+}
+
+// MockSharedListerMockRecorder is the mock recorder for MockSharedLister.
+type MockSharedListerMockRecorder struct {
+	mock *MockSharedLister
+}
+
+// NewMockSharedLister creates a new mock instance.
+func NewMockSharedLister(ctrl *gomock.Controller) *MockSharedLister {
+	mock := &MockSharedLister{ctrl: ctrl}
+	mock.recorder = &MockSharedListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSharedLister) EXPECT() *MockSharedListerMockRecorder {
+	return m.recorder
+}
+
+// ListSharedWithMe mocks base method.
+func (m *MockSharedLister) ListSharedWithMe(ctx context.Context, username string) ([]*models.SharedSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSharedWithMe", ctx, username)
+	ret0, _ := ret[0].([]*models.SharedSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSharedWithMe indicates an expected call of ListSharedWithMe.
+func (mr *MockSharedListerMockRecorder) ListSharedWithMe(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSharedWithMe", reflect.TypeOf((*MockSharedLister)(nil).ListSharedWithMe), ctx, username)
+}
+
+// MockSharedSaver is a mock of SharedSaver interface.
+type MockSharedSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockSharedSaverMockRecorder
+}
+
+// MockSharedSaverMockRecorder is the mock recorder for MockSharedSaver.
+type MockSharedSaverMockRecorder struct {
+	mock *MockSharedSaver
+}
+
+// NewMockSharedSaver creates a new mock instance.
+func NewMockSharedSaver(ctrl *gomock.Controller) *MockSharedSaver {
+	mock := &MockSharedSaver{ctrl: ctrl}
+	mock.recorder = &MockSharedSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSharedSaver) EXPECT() *MockSharedSaverMockRecorder {
+	return m.recorder
+}
+
+// SaveShared mocks base method.
+func (m *MockSharedSaver) SaveShared(ctx context.Context, username, owner, secretType, secretName string, ciphertext []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveShared", ctx, username, owner, secretType, secretName, ciphertext)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveShared indicates an expected call of SaveShared.
+func (mr *MockSharedSaverMockRecorder) SaveShared(ctx, username, owner, secretType, secretName, ciphertext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveShared", reflect.TypeOf((*MockSharedSaver)(nil).SaveShared), ctx, username, owner, secretType, secretName, ciphertext)
+}
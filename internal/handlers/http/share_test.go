@@ -0,0 +1,258 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withURLParams attaches chi URL parameters to the request.
+func withURLParams(req *http.Request, params map[string]string) *http.Request {
+	routeCtx := chi.NewRouteContext()
+	for k, v := range params {
+		routeCtx.URLParams.Add(k, v)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx))
+}
+
+func TestCertificatePublishHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		authHeader     string
+		expectedStatus int
+		mockSetup      func(publisher *MockCertificatePublisher, parser *MockJWTParser)
+	}{
+		{
+			name:           "success",
+			body:           `{"certificate":"pem"}`,
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(publisher *MockCertificatePublisher, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				publisher.EXPECT().PublishCertificate(gomock.Any(), "alice", "pem").Return(nil)
+			},
+		},
+		{
+			name:           "invalid certificate",
+			body:           `{"certificate":"garbage"}`,
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(publisher *MockCertificatePublisher, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+				publisher.EXPECT().PublishCertificate(gomock.Any(), "alice", "garbage").Return(services.ErrInvalidCertificate)
+			},
+		},
+		{
+			name:           "invalid body",
+			body:           `{`,
+			authHeader:     "Bearer validtoken",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(publisher *MockCertificatePublisher, parser *MockJWTParser) {
+				parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			},
+		},
+		{
+			name:           "missing token",
+			body:           `{"certificate":"pem"}`,
+			expectedStatus: http.StatusUnauthorized,
+			mockSetup:      func(publisher *MockCertificatePublisher, parser *MockJWTParser) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			publisher := NewMockCertificatePublisher(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			tt.mockSetup(publisher, parser)
+
+			req := httptest.NewRequest(http.MethodPut, "/certificate", bytes.NewBufferString(tt.body))
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rr := httptest.NewRecorder()
+
+			NewCertificatePublishHandler(publisher, parser).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestCertificateGetHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	getter := NewMockCertificateGetter(ctrl)
+	parser := NewMockJWTParser(ctrl)
+	handler := NewCertificateGetHandler(getter, parser)
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil).Times(2)
+	getter.EXPECT().GetCertificate(gomock.Any(), "bob").Return("bob-pem", nil)
+	getter.EXPECT().GetCertificate(gomock.Any(), "carol").Return("", services.ErrNoCertificate)
+
+	req := withURLParams(httptest.NewRequest(http.MethodGet, "/users/bob/certificate", nil), map[string]string{"username": "bob"})
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp CertificateResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	assert.Equal(t, "bob-pem", resp.Certificate)
+
+	req = withURLParams(httptest.NewRequest(http.MethodGet, "/users/carol/certificate", nil), map[string]string{"username": "carol"})
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestShareHandler(t *testing.T) {
+	body := `{"secret_type":"text","secret_name":"wifi","recipient":"bob","aes_key_enc":"d3JhcHBlZA==","key_id":"bob-id","permission":"rw"}`
+
+	tests := []struct {
+		name           string
+		shareErr       error
+		expectedStatus int
+	}{
+		{"success", nil, http.StatusOK},
+		{"invalid permission", services.ErrInvalidPermission, http.StatusBadRequest},
+		{"recipient without certificate", services.ErrNoCertificate, http.StatusNotFound},
+		{"outdated certificate", services.ErrRecipientKeyOutdated, http.StatusConflict},
+		{"internal", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sharer := NewMockSecretSharer(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			parser.EXPECT().Parse("validtoken").Return("alice", nil)
+			sharer.EXPECT().
+				Share(gomock.Any(), "alice", "text", "wifi", "bob", []byte("wrapped"), "bob-id", models.SharePermissionReadWrite).
+				Return(tt.shareErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/shares", bytes.NewBufferString(body))
+			req.Header.Set("Authorization", "Bearer validtoken")
+			rr := httptest.NewRecorder()
+
+			NewShareHandler(sharer, parser).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestUnshareHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	unsharer := NewMockSecretUnsharer(ctrl)
+	parser := NewMockJWTParser(ctrl)
+	handler := NewUnshareHandler(unsharer, parser)
+	params := map[string]string{"secret_type": "text", "secret_name": "wifi", "recipient": "bob"}
+
+	parser.EXPECT().Parse("validtoken").Return("alice", nil).Times(2)
+	gomock.InOrder(
+		unsharer.EXPECT().Unshare(gomock.Any(), "alice", "text", "wifi", "bob").Return(nil),
+		unsharer.EXPECT().Unshare(gomock.Any(), "alice", "text", "wifi", "bob").Return(services.ErrShareNotFound),
+	)
+
+	for _, want := range []int{http.StatusOK, http.StatusNotFound} {
+		req := withURLParams(httptest.NewRequest(http.MethodDelete, "/shares/text/wifi/bob", nil), params)
+		req.Header.Set("Authorization", "Bearer validtoken")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, want, rr.Code)
+	}
+}
+
+func TestSharedListHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	lister := NewMockSharedLister(ctrl)
+	parser := NewMockJWTParser(ctrl)
+
+	parser.EXPECT().Parse("validtoken").Return("bob", nil)
+	lister.EXPECT().ListSharedWithMe(gomock.Any(), "bob").Return([]*models.SharedSecret{{
+		Secret: models.Secret{
+			SecretName:  "wifi",
+			SecretType:  "text",
+			SecretOwner: "alice",
+			Ciphertext:  []byte("ct"),
+			AESKeyEnc:   []byte("wrapped"),
+			KeyID:       "bob-id",
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		Permission: models.SharePermissionRead,
+	}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/shares", nil)
+	req.Header.Set("Authorization", "Bearer validtoken")
+	rr := httptest.NewRecorder()
+
+	NewSharedListHandler(lister, parser).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var resp []SharedSecretResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	require.Len(t, resp, 1)
+	assert.Equal(t, "alice", resp[0].SecretOwner)
+	assert.Equal(t, models.SharePermissionRead, resp[0].Permission)
+	assert.Equal(t, []byte("wrapped"), resp[0].AESKeyEnc)
+}
+
+func TestSharedSaveHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		saveErr        error
+		expectedStatus int
+	}{
+		{"success", nil, http.StatusOK},
+		{"read-only share", services.ErrShareReadOnly, http.StatusForbidden},
+		{"share not found", services.ErrShareNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			saver := NewMockSharedSaver(ctrl)
+			parser := NewMockJWTParser(ctrl)
+			parser.EXPECT().Parse("validtoken").Return("bob", nil)
+			saver.EXPECT().SaveShared(gomock.Any(), "bob", "alice", "text", "wifi", []byte("ct")).Return(tt.saveErr)
+
+			req := httptest.NewRequest(http.MethodPut, "/shared/alice/text/wifi", bytes.NewBufferString(`{"ciphertext":"Y3Q="}`))
+			req = withURLParams(req, map[string]string{"secret_owner": "alice", "secret_type": "text", "secret_name": "wifi"})
+			req.Header.Set("Authorization", "Bearer validtoken")
+			rr := httptest.NewRecorder()
+
+			NewSharedSaveHandler(saver, parser).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
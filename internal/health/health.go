@@ -0,0 +1,412 @@
+package health
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// Issues reported by Check.
+const (
+	IssueWeak          = "weak"
+	IssueReused        = "reused"
+	IssueOld           = "old"
+	IssueExpiring      = "expiring"
+	IssueExpired       = "expired"
+	IssueInvalidExpiry = "invalid-expiry"
+)
+
+// Default thresholds of a Checker.
+const (
+	DefaultMinEntropy   = 50.0
+	DefaultMaxAge       = 365 * 24 * time.Hour
+	DefaultExpiryWindow = 60 * 24 * time.Hour
+)
+
+// Lengths of the patterns that make a password weak.
+const (
+	minLength      = 8
+	minRepeat      = 3
+	minSequence    = 4
+	minUsernameLen = 3
+)
+
+// commonData lists frequently used passwords, matched case-insensitively
+// and after undoing digit-for-letter substitutions and stripping trailing digits.
+//
+//go:embed common.txt
+var commonData string
+
+var common = func() map[string]bool {
+	m := make(map[string]bool)
+	for _, p := range strings.Fields(commonData) {
+		m[p] = true
+	}
+	return m
+}()
+
+// keyboardRows are the rows of a QWERTY keyboard, walked in either direction by weak passwords.
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// unleet undoes the usual substitutions of digits and symbols for letters.
+var unleet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i")
+
+// Entry is a decrypted secret to check. Only user and bankcard secrets are checked.
+// The age of a password is taken from UpdatedAt when the login has no password change time.
+type Entry struct {
+	Type      string
+	Name      string
+	UpdatedAt time.Time
+	User      *models.UserPayload
+	Bankcard  *models.BankcardPayload
+}
+
+// Finding is one issue found in a secret.
+type Finding struct {
+	SecretType string `json:"secret_type"`
+	SecretName string `json:"secret_name"`
+	Issue      string `json:"issue"`
+	Detail     string `json:"detail"`
+}
+
+// Report is the result of a check.
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Passwords int       `json:"passwords"` // Passwords is the number of user secrets checked.
+	Bankcards int       `json:"bankcards"` // Bankcards is the number of bankcard secrets checked.
+	Findings  []Finding `json:"findings"`
+}
+
+// Checker finds weak, reused and old passwords and expiring bank cards.
+type Checker struct {
+	minEntropy   float64
+	maxAge       time.Duration
+	expiryWindow time.Duration
+	now          func() time.Time
+}
+
+// Opt defines a functional option for Checker configuration.
+type Opt func(*Checker)
+
+// WithMinEntropy sets the estimated entropy in bits below which a password is weak.
+func WithMinEntropy(bits float64) Opt {
+	return func(c *Checker) {
+		c.minEntropy = bits
+	}
+}
+
+// WithMaxAge sets the age after which a password not changed since is old.
+func WithMaxAge(age time.Duration) Opt {
+	return func(c *Checker) {
+		c.maxAge = age
+	}
+}
+
+// WithExpiryWindow sets how long before its expiry a bank card is reported.
+func WithExpiryWindow(window time.Duration) Opt {
+	return func(c *Checker) {
+		c.expiryWindow = window
+	}
+}
+
+// WithNow sets the clock ages and expiry dates are measured against.
+func WithNow(now func() time.Time) Opt {
+	return func(c *Checker) {
+		c.now = now
+	}
+}
+
+// New constructs a Checker with given options.
+func New(opts ...Opt) *Checker {
+	c := &Checker{
+		minEntropy:   DefaultMinEntropy,
+		maxAge:       DefaultMaxAge,
+		expiryWindow: DefaultExpiryWindow,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Check analyses entries and returns the findings ordered by secret type and name.
+func (c *Checker) Check(entries []Entry) *Report {
+	now := c.now()
+	report := &Report{CheckedAt: now, Findings: []Finding{}}
+
+	reuse := make(map[string][]string)
+	for _, e := range entries {
+		if e.User != nil && e.User.Password != "" {
+			reuse[e.User.Password] = append(reuse[e.User.Password], e.Type+"/"+e.Name)
+		}
+	}
+
+	for _, e := range entries {
+		switch {
+		case e.User != nil:
+			report.Passwords++
+			report.Findings = append(report.Findings, c.checkUser(e, reuse, now)...)
+		case e.Bankcard != nil:
+			report.Bankcards++
+			report.Findings = append(report.Findings, c.checkBankcard(e, now)...)
+		}
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.SecretType != b.SecretType {
+			return a.SecretType < b.SecretType
+		}
+		return a.SecretName < b.SecretName
+	})
+	return report
+}
+
+// checkUser reports a weak, reused or old password.
+func (c *Checker) checkUser(e Entry, reuse map[string][]string, now time.Time) []Finding {
+	var findings []Finding
+	add := func(issue, detail string) {
+		findings = append(findings, Finding{SecretType: e.Type, SecretName: e.Name, Issue: issue, Detail: detail})
+	}
+
+	password := e.User.Password
+	if password == "" {
+		add(IssueWeak, "empty password")
+		return findings
+	}
+
+	bits := Entropy(password)
+	reasons := Weaknesses(password, e.User.Username)
+	if bits < c.minEntropy {
+		reasons = append(reasons, "low entropy")
+	}
+	if len(reasons) > 0 {
+		add(IssueWeak, fmt.Sprintf("%s (estimated %.1f bits)", strings.Join(reasons, ", "), bits))
+	}
+
+	if others := without(reuse[password], e.Type+"/"+e.Name); len(others) > 0 {
+		add(IssueReused, "also used by "+strings.Join(others, ", "))
+	}
+
+	changed := e.UpdatedAt
+	if e.User.PasswordChangedAt != nil {
+		changed = *e.User.PasswordChangedAt
+	}
+	if !changed.IsZero() && now.Sub(changed) > c.maxAge {
+		add(IssueOld, fmt.Sprintf("not changed for %d days", int(now.Sub(changed).Hours()/24)))
+	}
+
+	return findings
+}
+
+// checkBankcard reports an expired or soon expiring bank card.
+func (c *Checker) checkBankcard(e Entry, now time.Time) []Finding {
+	finding := Finding{SecretType: e.Type, SecretName: e.Name}
+
+	end, err := expiryEnd(e.Bankcard.Exp)
+	switch {
+	case err != nil:
+		finding.Issue, finding.Detail = IssueInvalidExpiry, err.Error()
+	case !now.Before(end):
+		finding.Issue, finding.Detail = IssueExpired, "expired at the end of "+end.AddDate(0, 0, -1).Format("01/2006")
+	case end.Sub(now) <= c.expiryWindow:
+		finding.Issue, finding.Detail = IssueExpiring, fmt.Sprintf("expires in %d days", int(math.Ceil(end.Sub(now).Hours()/24)))
+	default:
+		return nil
+	}
+	return []Finding{finding}
+}
+
+// Text renders the report as a table.
+func (r *Report) Text() (string, error) {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Checked %d passwords and %d bank cards.\n", r.Passwords, r.Bankcards)
+	if len(r.Findings) == 0 {
+		builder.WriteString("No issues found.\n")
+		return builder.String(), nil
+	}
+
+	builder.WriteString("\n")
+	tw := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ISSUE\tSECRET\tDETAIL")
+	for _, f := range r.Findings {
+		fmt.Fprintf(tw, "%s\t%s/%s\t%s\n", f.Issue, f.SecretType, f.SecretName, f.Detail)
+	}
+	if err := tw.Flush(); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// Entropy estimates the entropy in bits of password as if its characters were drawn
+// uniformly from the classes it uses. It overestimates patterned passwords, see Weaknesses.
+func Entropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	n := 0
+	for _, r := range password {
+		n++
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(n) * math.Log2(float64(pool))
+}
+
+// Weaknesses returns the patterns that make password easy to guess for an account named username.
+func Weaknesses(password, username string) []string {
+	var found []string
+	lower := strings.ToLower(password)
+
+	if len([]rune(password)) < minLength {
+		found = append(found, "too short")
+	}
+	if isCommon(lower) {
+		found = append(found, "common password")
+	}
+	if name, _, _ := strings.Cut(strings.ToLower(username), "@"); len(name) >= minUsernameLen && strings.Contains(lower, name) {
+		found = append(found, "contains the username")
+	}
+	if hasRepeat(lower) {
+		found = append(found, "repeated characters")
+	}
+	if hasSequence(lower) {
+		found = append(found, "character sequence")
+	}
+	return found
+}
+
+// isCommon reports whether the lower-cased password is a common one or a variation of it.
+func isCommon(lower string) bool {
+	trim := func(s string) string {
+		return strings.TrimRightFunc(s, func(r rune) bool { return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) })
+	}
+	for _, candidate := range []string{lower, trim(lower), unleet.Replace(lower), trim(unleet.Replace(trim(lower)))} {
+		if common[candidate] {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRepeat reports whether s has minRepeat identical characters in a row.
+func hasRepeat(s string) bool {
+	runes := []rune(s)
+	run := 1
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == runes[i-1] {
+			run++
+			if run >= minRepeat {
+				return true
+			}
+		} else {
+			run = 1
+		}
+	}
+	return false
+}
+
+// hasSequence reports whether s has minSequence characters running up or down the alphabet,
+// the digits or a keyboard row.
+func hasSequence(s string) bool {
+	runes := []rune(s)
+	for i := 0; i+minSequence <= len(runes); i++ {
+		window := string(runes[i : i+minSequence])
+		for _, row := range keyboardRows {
+			if strings.Contains(row, window) || strings.Contains(reverse(row), window) {
+				return true
+			}
+		}
+
+		up, down := true, true
+		for j := 1; j < minSequence; j++ {
+			a, b := runes[i+j-1], runes[i+j]
+			if !unicode.IsLetter(a) && !unicode.IsDigit(a) {
+				up, down = false, false
+				break
+			}
+			up = up && b == a+1
+			down = down && b == a-1
+		}
+		if up || down {
+			return true
+		}
+	}
+	return false
+}
+
+// expiryEnd parses a card expiry date given as MM/YY or MM/YYYY and returns
+// the first moment the card is no longer valid.
+func expiryEnd(exp string) (time.Time, error) {
+	m, y, ok := strings.Cut(strings.TrimSpace(exp), "/")
+	if !ok {
+		m, y, ok = strings.Cut(strings.TrimSpace(exp), "-")
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("expiry date %q is not MM/YY", exp)
+	}
+
+	month, err := strconv.Atoi(m)
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, fmt.Errorf("expiry date %q has an invalid month", exp)
+	}
+	year, err := strconv.Atoi(y)
+	if err != nil || (len(y) != 2 && len(y) != 4) {
+		return time.Time{}, fmt.Errorf("expiry date %q has an invalid year", exp)
+	}
+	if len(y) == 2 {
+		year += 2000
+	}
+
+	return time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// without returns names except name.
+func without(names []string, name string) []string {
+	var out []string
+	for _, n := range names {
+		if n != name {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// reverse returns s with its characters in reverse order.
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
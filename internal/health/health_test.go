@@ -0,0 +1,147 @@
+package health
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, time.August, 10, 12, 0, 0, 0, time.UTC)
+
+func userEntry(name, username, password string, updatedAt time.Time) Entry {
+	return Entry{
+		Type:      models.SecretTypeUser,
+		Name:      name,
+		UpdatedAt: updatedAt,
+		User:      &models.UserPayload{Username: username, Password: password},
+	}
+}
+
+func cardEntry(name, exp string) Entry {
+	return Entry{
+		Type:     models.SecretTypeBankCard,
+		Name:     name,
+		Bankcard: &models.BankcardPayload{Number: "4111111111111111", Exp: exp},
+	}
+}
+
+func TestWeaknesses(t *testing.T) {
+	tests := []struct {
+		password string
+		username string
+		want     []string
+	}{
+		{"Xk#9vQ!m2Lp$", "alice", nil},
+		{"abcd", "", []string{"too short", "character sequence"}},
+		{"P@ssw0rd1", "", []string{"common password"}},
+		{"Dragon2024!", "", []string{"common password"}},
+		{"alice-Rules-77x", "alice@example.com", []string{"contains the username"}},
+		{"Zaaab#91mQ", "", []string{"repeated characters"}},
+		{"Tq#qwerty8Lm", "", []string{"character sequence"}},
+		{"Tq#9876Lm!x", "", []string{"character sequence"}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Weaknesses(tt.password, tt.username), tt.password)
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	assert.Zero(t, Entropy(""))
+	assert.InDelta(t, 8*4.7004, Entropy("abcdefgh"), 0.01)
+	assert.InDelta(t, 12*6.5699, Entropy("Xk#9vQ!m2Lp$"), 0.01)
+}
+
+func TestCheck(t *testing.T) {
+	checker := New(WithNow(func() time.Time { return testNow }))
+
+	report := checker.Check([]Entry{
+		userEntry("github", "alice", "Xk#9vQ!m2Lp$Rt7w", testNow.AddDate(0, -1, 0)),
+		userEntry("gitlab", "alice", "Xk#9vQ!m2Lp$Rt7w", testNow.AddDate(-2, 0, 0)),
+		userEntry("forum", "alice", "letmein", testNow),
+		cardEntry("visa", "08/25"),
+		cardEntry("mastercard", "09/2025"),
+		cardEntry("amex", "12/30"),
+		cardEntry("old", "01/24"),
+		cardEntry("broken", "soon"),
+		{Type: models.SecretTypeText, Name: "note"},
+	})
+
+	assert.Equal(t, 3, report.Passwords)
+	assert.Equal(t, 5, report.Bankcards)
+	assert.Equal(t, testNow, report.CheckedAt)
+
+	issues := make(map[string][]string)
+	for _, f := range report.Findings {
+		issues[f.SecretType+"/"+f.SecretName] = append(issues[f.SecretType+"/"+f.SecretName], f.Issue)
+	}
+	assert.Equal(t, map[string][]string{
+		"user/github":         {IssueReused},
+		"user/gitlab":         {IssueReused, IssueOld},
+		"user/forum":          {IssueWeak},
+		"bankcard/visa":       {IssueExpiring},
+		"bankcard/mastercard": {IssueExpiring},
+		"bankcard/old":        {IssueExpired},
+		"bankcard/broken":     {IssueInvalidExpiry},
+	}, issues)
+
+	assert.Equal(t, "bankcard", report.Findings[0].SecretType, "findings are sorted")
+}
+
+func TestCheck_Options(t *testing.T) {
+	entries := []Entry{
+		userEntry("github", "alice", "correct-horse", testNow.AddDate(0, -2, 0)),
+		cardEntry("visa", "12/25"),
+	}
+
+	report := New(WithNow(func() time.Time { return testNow })).Check(entries)
+	assert.Empty(t, report.Findings)
+
+	report = New(
+		WithNow(func() time.Time { return testNow }),
+		WithMinEntropy(80),
+		WithMaxAge(30*24*time.Hour),
+		WithExpiryWindow(180*24*time.Hour),
+	).Check(entries)
+	require.Len(t, report.Findings, 3)
+	assert.Equal(t, IssueExpiring, report.Findings[0].Issue)
+	assert.Equal(t, "expires in 144 days", report.Findings[0].Detail)
+	assert.Equal(t, IssueWeak, report.Findings[1].Issue)
+	assert.Contains(t, report.Findings[1].Detail, "low entropy")
+	assert.Equal(t, IssueOld, report.Findings[2].Issue)
+}
+
+func TestCheck_PasswordChangedAt(t *testing.T) {
+	checker := New(WithNow(func() time.Time { return testNow }))
+
+	changed := testNow.AddDate(-2, 0, 0)
+	resaved := userEntry("github", "alice", "Xk#9vQ!m2Lp$Rt7w", testNow)
+	resaved.User.PasswordChangedAt = &changed
+
+	recent := testNow.AddDate(0, -1, 0)
+	changedRecently := userEntry("gitlab", "alice", "Rt7w#Xk9vQ!m2Lp$", testNow.AddDate(-2, 0, 0))
+	changedRecently.User.PasswordChangedAt = &recent
+
+	report := checker.Check([]Entry{resaved, changedRecently})
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, "github", report.Findings[0].SecretName)
+	assert.Equal(t, IssueOld, report.Findings[0].Issue)
+	assert.Equal(t, "not changed for 731 days", report.Findings[0].Detail)
+}
+
+func TestReport_Text(t *testing.T) {
+	checker := New(WithNow(func() time.Time { return testNow }))
+
+	out, err := checker.Check(nil).Text()
+	require.NoError(t, err)
+	assert.Equal(t, "Checked 0 passwords and 0 bank cards.\nNo issues found.\n", out)
+
+	out, err = checker.Check([]Entry{userEntry("forum", "alice", "letmein", testNow)}).Text()
+	require.NoError(t, err)
+	assert.Contains(t, out, "Checked 1 passwords and 0 bank cards.")
+	assert.Contains(t, out, "ISSUE")
+	assert.Contains(t, out, "weak   user/forum  too short, common password, low entropy")
+}
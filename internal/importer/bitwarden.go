@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// Bitwarden item types.
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+	bitwardenCard       = 3
+	bitwardenIdentity   = 4
+)
+
+// bitwardenExport is the part of an unencrypted Bitwarden JSON export the importer reads.
+type bitwardenExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type     int    `json:"type"`
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	FolderID string `json:"folderId"`
+	Login    *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Brand          string `json:"brand"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Identity map[string]any `json:"identity"`
+	Fields   []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+}
+
+// ParseBitwardenJSON parses an unencrypted Bitwarden JSON export. Logins become user secrets,
+// cards bankcard secrets, and secure notes, identities and other items text secrets.
+// Items are named by their folder and name.
+func ParseBitwardenJSON(r io.Reader) ([]Item, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse Bitwarden JSON: %w", err)
+	}
+	if export.Encrypted {
+		return nil, errors.New("encrypted Bitwarden exports are not supported, export as unencrypted JSON")
+	}
+
+	folders := make(map[string]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	items := make([]Item, 0, len(export.Items))
+	for _, it := range export.Items {
+		var custom []string
+		for _, f := range it.Fields {
+			custom = append(custom, field(f.Name, f.Value))
+		}
+		name := itemName(folders[it.FolderID], it.Name)
+
+		switch {
+		case it.Type == bitwardenLogin && it.Login != nil:
+			parts := make([]string, 0, len(it.Login.URIs)+len(custom)+2)
+			for _, u := range it.Login.URIs {
+				parts = append(parts, field("URL", u.URI))
+			}
+			parts = append(parts, field("TOTP", it.Login.TOTP), it.Notes)
+			items = append(items, Item{
+				Type: models.SecretTypeUser,
+				Name: name,
+				Payload: models.UserPayload{
+					Username: it.Login.Username,
+					Password: it.Login.Password,
+					Meta:     meta(append(parts, custom...)...),
+				},
+			})
+
+		case it.Type == bitwardenCard && it.Card != nil:
+			items = append(items, Item{
+				Type: models.SecretTypeBankCard,
+				Name: name,
+				Payload: models.BankcardPayload{
+					Number: strings.ReplaceAll(it.Card.Number, " ", ""),
+					Owner:  it.Card.CardholderName,
+					Exp:    bitwardenExp(it.Card.ExpMonth, it.Card.ExpYear),
+					CVV:    it.Card.Code,
+					Meta:   meta(append([]string{field("Brand", it.Card.Brand), it.Notes}, custom...)...),
+				},
+			})
+
+		case it.Type == bitwardenIdentity && it.Identity != nil:
+			items = append(items, Item{
+				Type:    models.SecretTypeText,
+				Name:    name,
+				Payload: models.TextPayload{Data: identityText(it.Identity), Meta: meta(append([]string{it.Notes}, custom...)...)},
+			})
+
+		default:
+			items = append(items, Item{
+				Type:    models.SecretTypeText,
+				Name:    name,
+				Payload: models.TextPayload{Data: it.Notes, Meta: meta(custom...)},
+			})
+		}
+	}
+	return items, nil
+}
+
+// bitwardenExp formats a card expiry as MM/YY.
+func bitwardenExp(month, year string) string {
+	m, err := strconv.Atoi(month)
+	if err != nil || year == "" {
+		return ""
+	}
+	if len(year) == 4 {
+		year = year[2:]
+	}
+	return fmt.Sprintf("%02d/%s", m, year)
+}
+
+// identityText lists the non-empty fields of an identity, one per line.
+func identityText(identity map[string]any) string {
+	keys := make([]string, 0, len(identity))
+	for k, v := range identity {
+		if s, ok := v.(string); ok && s != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = field(k, identity[k].(string))
+	}
+	return strings.Join(lines, "\n")
+}
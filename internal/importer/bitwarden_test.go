@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bitwardenJSON = `{
+  "encrypted": false,
+  "folders": [{"id": "f1", "name": "Work"}],
+  "items": [
+    {
+      "type": 1, "name": "GitHub", "folderId": "f1", "notes": null,
+      "login": {"username": "alice", "password": "s3cret!", "totp": "otpauth://totp/x", "uris": [{"uri": "https://github.com"}]},
+      "fields": [{"name": "PIN", "value": "1234"}]
+    },
+    {
+      "type": 3, "name": "Visa", "folderId": null, "notes": "main card",
+      "card": {"cardholderName": "Alice", "brand": "Visa", "number": "4111 1111 1111 1111", "expMonth": "8", "expYear": "2027", "code": "123"}
+    },
+    {"type": 2, "name": "Wifi", "notes": "hunter2", "secureNote": {"type": 0}},
+    {"type": 4, "name": "Passport", "identity": {"firstName": "Alice", "lastName": "Smith", "passportNumber": "X123", "middleName": null}}
+  ]
+}`
+
+func TestParseBitwardenJSON(t *testing.T) {
+	items, err := ParseBitwardenJSON(strings.NewReader(bitwardenJSON))
+	require.NoError(t, err)
+
+	loginMeta := "URL: https://github.com\nTOTP: otpauth://totp/x\nPIN: 1234"
+	cardMeta := "Brand: Visa\nmain card"
+	assert.Equal(t, []Item{
+		{Type: models.SecretTypeUser, Name: "Work/GitHub", Payload: models.UserPayload{Username: "alice", Password: "s3cret!", Meta: &loginMeta}},
+		{Type: models.SecretTypeBankCard, Name: "Visa", Payload: models.BankcardPayload{Number: "4111111111111111", Owner: "Alice", Exp: "08/27", CVV: "123", Meta: &cardMeta}},
+		{Type: models.SecretTypeText, Name: "Wifi", Payload: models.TextPayload{Data: "hunter2"}},
+		{Type: models.SecretTypeText, Name: "Passport", Payload: models.TextPayload{Data: "firstName: Alice\nlastName: Smith\npassportNumber: X123"}},
+	}, items)
+}
+
+func TestParseBitwardenJSON_Errors(t *testing.T) {
+	_, err := ParseBitwardenJSON(strings.NewReader(`{"encrypted": true, "items": []}`))
+	assert.ErrorContains(t, err, "encrypted")
+
+	_, err = ParseBitwardenJSON(strings.NewReader(`[`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// onePasswordColumns maps the columns of 1Password CSV exports, by their lower-case
+// header in either the 1Password 7 or 8 layout, onto canonical names.
+var onePasswordColumns = map[string]string{
+	"title":          "title",
+	"name":           "title",
+	"url":            "url",
+	"website":        "url",
+	"urls":           "url",
+	"username":       "username",
+	"login_username": "username",
+	"password":       "password",
+	"login_password": "password",
+	"notes":          "notes",
+	"notesplain":     "notes",
+	"otpauth":        "otp",
+	"tags":           "tags",
+}
+
+// genericColumns maps the columns of the generic CSV format onto canonical names.
+var genericColumns = map[string]string{
+	"type":       "type",
+	"name":       "name",
+	"title":      "name",
+	"username":   "username",
+	"login":      "username",
+	"password":   "password",
+	"url":        "url",
+	"website":    "url",
+	"notes":      "notes",
+	"meta":       "notes",
+	"number":     "number",
+	"cardnumber": "number",
+	"owner":      "owner",
+	"cardholder": "owner",
+	"exp":        "exp",
+	"expiry":     "exp",
+	"cvv":        "cvv",
+	"code":       "cvv",
+	"data":       "data",
+}
+
+// Parse1PasswordCSV parses a 1Password CSV export. Rows with a username or password
+// become user secrets and the others text secrets holding their notes.
+func Parse1PasswordCSV(r io.Reader) ([]Item, error) {
+	return parseCSV(r, onePasswordColumns, func(row map[string]string) (Item, error) {
+		name := itemName("", row["title"])
+		if row["username"] == "" && row["password"] == "" {
+			return Item{
+				Type:    models.SecretTypeText,
+				Name:    name,
+				Payload: models.TextPayload{Data: row["notes"], Meta: meta(field("URL", row["url"]), field("Tags", row["tags"]))},
+			}, nil
+		}
+		return Item{
+			Type: models.SecretTypeUser,
+			Name: name,
+			Payload: models.UserPayload{
+				Username: row["username"],
+				Password: row["password"],
+				Meta:     meta(field("URL", row["url"]), field("TOTP", row["otp"]), field("Tags", row["tags"]), row["notes"]),
+			},
+		}, nil
+	})
+}
+
+// ParseCSV parses the generic CSV format: a header row naming the columns type, name,
+// username, password, url, notes (user), number, owner, exp, cvv (bankcard) and data
+// (text, base64 for binary), in any order. A missing type means a user secret.
+func ParseCSV(r io.Reader) ([]Item, error) {
+	return parseCSV(r, genericColumns, func(row map[string]string) (Item, error) {
+		name := strings.TrimSpace(row["name"])
+		if name == "" {
+			return Item{}, errors.New("name is empty")
+		}
+		notes := meta(field("URL", row["url"]), row["notes"])
+
+		switch secretType := strings.ToLower(strings.TrimSpace(row["type"])); secretType {
+		case "", models.SecretTypeUser:
+			return Item{Type: models.SecretTypeUser, Name: name, Payload: models.UserPayload{
+				Username: row["username"],
+				Password: row["password"],
+				Meta:     notes,
+			}}, nil
+		case models.SecretTypeBankCard:
+			return Item{Type: models.SecretTypeBankCard, Name: name, Payload: models.BankcardPayload{
+				Number: strings.ReplaceAll(row["number"], " ", ""),
+				Owner:  row["owner"],
+				Exp:    row["exp"],
+				CVV:    row["cvv"],
+				Meta:   notes,
+			}}, nil
+		case models.SecretTypeText:
+			return Item{Type: models.SecretTypeText, Name: name, Payload: models.TextPayload{Data: row["data"], Meta: notes}}, nil
+		case models.SecretTypeBinary:
+			data, err := base64.StdEncoding.DecodeString(row["data"])
+			if err != nil {
+				return Item{}, fmt.Errorf("binary data is not base64: %w", err)
+			}
+			return Item{Type: models.SecretTypeBinary, Name: name, Payload: models.BinaryPayload{Data: data, Meta: notes}}, nil
+		default:
+			return Item{}, fmt.Errorf("unknown secret type %q", secretType)
+		}
+	})
+}
+
+// parseCSV reads a CSV file with a header row, renaming the known columns with columns
+// and ignoring the others, and converts each row with convert.
+func parseCSV(r io.Reader, columns map[string]string, convert func(row map[string]string) (Item, error)) ([]Item, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	names := make([]string, len(header))
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		names[i] = columns[h]
+		if names[i] == "" {
+			names[i] = columns[strings.NewReplacer(" ", "", "-", "").Replace(h)]
+		}
+	}
+
+	var items []Item
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		row := make(map[string]string, len(record))
+		for i, value := range record {
+			if i < len(names) && names[i] != "" {
+				row[names[i]] = value
+			}
+		}
+
+		item, err := convert(row)
+		if err != nil {
+			return nil, fmt.Errorf("CSV line %d: %w", line, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
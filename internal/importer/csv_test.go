@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse1PasswordCSV(t *testing.T) {
+	export := "\ufeffTitle,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+		"GitHub,https://github.com,alice,s3cret!,,false,false,work,\"two\nlines\"\n" +
+		"Wifi,,,,,false,false,,hunter2\n"
+
+	items, err := Parse1PasswordCSV(strings.NewReader(export))
+	require.NoError(t, err)
+
+	loginMeta := "URL: https://github.com\nTags: work\ntwo\nlines"
+	assert.Equal(t, []Item{
+		{Type: models.SecretTypeUser, Name: "GitHub", Payload: models.UserPayload{Username: "alice", Password: "s3cret!", Meta: &loginMeta}},
+		{Type: models.SecretTypeText, Name: "Wifi", Payload: models.TextPayload{Data: "hunter2"}},
+	}, items)
+}
+
+func TestParseCSV(t *testing.T) {
+	export := "type,name,username,password,url,notes,number,owner,exp,cvv,data\n" +
+		",GitHub,alice,s3cret!,https://github.com,,,,,,\n" +
+		"bankcard,Visa,,,,,4111 1111 1111 1111,Alice,08/27,123,\n" +
+		"text,Note,,,,,,,,,hello\n" +
+		"binary,Blob,,,,,,,,,aGk=\n"
+
+	items, err := ParseCSV(strings.NewReader(export))
+	require.NoError(t, err)
+
+	url := "URL: https://github.com"
+	assert.Equal(t, []Item{
+		{Type: models.SecretTypeUser, Name: "GitHub", Payload: models.UserPayload{Username: "alice", Password: "s3cret!", Meta: &url}},
+		{Type: models.SecretTypeBankCard, Name: "Visa", Payload: models.BankcardPayload{Number: "4111111111111111", Owner: "Alice", Exp: "08/27", CVV: "123"}},
+		{Type: models.SecretTypeText, Name: "Note", Payload: models.TextPayload{Data: "hello"}},
+		{Type: models.SecretTypeBinary, Name: "Blob", Payload: models.BinaryPayload{Data: []byte("hi")}},
+	}, items)
+}
+
+func TestParseCSV_Errors(t *testing.T) {
+	tests := map[string]string{
+		"empty":        "",
+		"no name":      "name,password\n,x\n",
+		"unknown type": "type,name\nsshkey,key\n",
+		"bad base64":   "type,name,data\nbinary,blob,!!\n",
+	}
+	for name, export := range tests {
+		_, err := ParseCSV(strings.NewReader(export))
+		assert.Error(t, err, name)
+	}
+}
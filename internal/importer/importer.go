@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Formats of the bundled parsers.
+const (
+	FormatKeePassXML    = "keepass-xml"
+	FormatBitwardenJSON = "bitwarden-json"
+	Format1PasswordCSV  = "1password-csv"
+	FormatCSV           = "csv"
+)
+
+// Item is a secret read from an export, not yet encrypted. Payload is one of
+// models.UserPayload, models.BankcardPayload, models.TextPayload and models.BinaryPayload,
+// matching Type.
+type Item struct {
+	Type    string
+	Name    string
+	Payload any
+}
+
+// Key returns the type/name key that identifies the secret of the item.
+func (i Item) Key() string {
+	return i.Type + "/" + i.Name
+}
+
+// Parser reads the secrets of an export of another password manager.
+type Parser interface {
+	Parse(r io.Reader) ([]Item, error)
+}
+
+// ParserFunc adapts an ordinary function to the Parser interface.
+type ParserFunc func(r io.Reader) ([]Item, error)
+
+// Parse calls f(r).
+func (f ParserFunc) Parse(r io.Reader) ([]Item, error) {
+	return f(r)
+}
+
+var (
+	mu      sync.RWMutex
+	parsers = map[string]Parser{
+		FormatKeePassXML:    ParserFunc(ParseKeePassXML),
+		FormatBitwardenJSON: ParserFunc(ParseBitwardenJSON),
+		Format1PasswordCSV:  ParserFunc(Parse1PasswordCSV),
+		FormatCSV:           ParserFunc(ParseCSV),
+	}
+)
+
+// Register makes parser available under format, replacing any parser registered before.
+func Register(format string, parser Parser) {
+	mu.Lock()
+	defer mu.Unlock()
+	parsers[format] = parser
+}
+
+// Get returns the parser registered under format.
+func Get(format string) (Parser, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	parser, ok := parsers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format %q, supported: %s", format, strings.Join(formats(), ", "))
+	}
+	return parser, nil
+}
+
+// Formats returns the registered formats in alphabetical order.
+func Formats() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return formats()
+}
+
+func formats() []string {
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// untitled is the name of items an export gives no name.
+const untitled = "untitled"
+
+// itemName joins the folder path and the title of an entry into a secret name.
+func itemName(folder, title string) string {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = untitled
+	}
+	if folder = strings.Trim(strings.TrimSpace(folder), "/"); folder != "" {
+		return folder + "/" + title
+	}
+	return title
+}
+
+// meta joins the non-empty parts into the meta of a payload, nil if all are empty.
+func meta(parts ...string) *string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	m := strings.Join(kept, "\n")
+	return &m
+}
+
+// field formats a labelled value for meta, empty if value is empty.
+func field(label, value string) string {
+	if strings.TrimSpace(value) == "" {
+		return ""
+	}
+	return label + ": " + value
+}
@@ -0,0 +1,36 @@
+package importer
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	assert.Equal(t, []string{Format1PasswordCSV, FormatBitwardenJSON, FormatCSV, FormatKeePassXML}, Formats())
+
+	_, err := Get("lastpass-csv")
+	assert.ErrorContains(t, err, "supported: 1password-csv, bitwarden-json, csv, keepass-xml")
+
+	Register("test-format", ParserFunc(func(io.Reader) ([]Item, error) {
+		return []Item{{Type: "text", Name: "x"}}, nil
+	}))
+	defer func() {
+		mu.Lock()
+		delete(parsers, "test-format")
+		mu.Unlock()
+	}()
+
+	parser, err := Get("test-format")
+	require.NoError(t, err)
+	items, err := parser.Parse(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "text/x", items[0].Key())
+}
+
+func TestItemName(t *testing.T) {
+	assert.Equal(t, "GitHub", itemName("", " GitHub "))
+	assert.Equal(t, "Work/untitled", itemName("/Work/", ""))
+}
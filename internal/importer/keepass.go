@@ -0,0 +1,176 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// keePassFile is the part of a KeePass 2.x XML export the importer reads.
+type keePassFile struct {
+	Meta struct {
+		RecycleBinUUID string          `xml:"RecycleBinUUID"`
+		Binaries       []keePassBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keePassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keePassBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed string `xml:"Compressed,attr"`
+	Data       string `xml:",chardata"`
+}
+
+type keePassGroup struct {
+	UUID    string         `xml:"UUID"`
+	Name    string         `xml:"Name"`
+	Entries []keePassEntry `xml:"Entry"`
+	Groups  []keePassGroup `xml:"Group"`
+}
+
+type keePassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Ref  string `xml:"Ref,attr"`
+			Data string `xml:",chardata"`
+		} `xml:"Value"`
+	} `xml:"Binary"`
+}
+
+// keePassStandard are the string fields of an entry mapped onto payload fields.
+var keePassStandard = map[string]bool{"Title": true, "UserName": true, "Password": true, "URL": true, "Notes": true}
+
+// ParseKeePassXML parses a KeePass 2.x XML export. Entries with a username or password become
+// user secrets and the others text secrets holding their notes; attachments become binary secrets
+// named after the entry and the file. Entries are named by their group path below the root group,
+// the recycle bin and entry history are skipped.
+func ParseKeePassXML(r io.Reader) ([]Item, error) {
+	var file keePassFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse KeePass XML: %w", err)
+	}
+
+	binaries := make(map[string][]byte, len(file.Meta.Binaries))
+	for _, b := range file.Meta.Binaries {
+		data, err := keePassBinaryData(b.Data, b.Compressed == "True")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read KeePass attachment %s: %w", b.ID, err)
+		}
+		binaries[b.ID] = data
+	}
+
+	var items []Item
+	var walk func(g keePassGroup, path string) error
+	walk = func(g keePassGroup, path string) error {
+		if g.UUID != "" && g.UUID == file.Meta.RecycleBinUUID {
+			return nil
+		}
+		for _, e := range g.Entries {
+			entryItems, err := keePassItems(e, path, binaries)
+			if err != nil {
+				return err
+			}
+			items = append(items, entryItems...)
+		}
+		for _, sub := range g.Groups {
+			if err := walk(sub, strings.TrimPrefix(path+"/"+sub.Name, "/")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range file.Root.Groups {
+		if err := walk(root, ""); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// keePassItems converts an entry and its attachments.
+func keePassItems(e keePassEntry, path string, binaries map[string][]byte) ([]Item, error) {
+	fields := make(map[string]string, len(e.Strings))
+	var custom []string
+	for _, s := range e.Strings {
+		fields[s.Key] = s.Value
+		if !keePassStandard[s.Key] {
+			custom = append(custom, field(s.Key, s.Value))
+		}
+	}
+	sort.Strings(custom)
+
+	name := itemName(path, fields["Title"])
+	var items []Item
+
+	if fields["UserName"] != "" || fields["Password"] != "" {
+		items = append(items, Item{
+			Type: models.SecretTypeUser,
+			Name: name,
+			Payload: models.UserPayload{
+				Username: fields["UserName"],
+				Password: fields["Password"],
+				Meta:     meta(append([]string{field("URL", fields["URL"]), fields["Notes"]}, custom...)...),
+			},
+		})
+	} else if fields["Notes"] != "" || len(custom) > 0 {
+		items = append(items, Item{
+			Type: models.SecretTypeText,
+			Name: name,
+			Payload: models.TextPayload{
+				Data: fields["Notes"],
+				Meta: meta(append([]string{field("URL", fields["URL"])}, custom...)...),
+			},
+		})
+	}
+
+	for _, b := range e.Binaries {
+		data, ok := binaries[b.Value.Ref]
+		if b.Value.Ref == "" {
+			var err error
+			if data, err = keePassBinaryData(b.Value.Data, false); err != nil {
+				return nil, fmt.Errorf("failed to read KeePass attachment %s of %s: %w", b.Key, name, err)
+			}
+		} else if !ok {
+			return nil, fmt.Errorf("KeePass attachment %s of %s refers to missing binary %s", b.Key, name, b.Value.Ref)
+		}
+		items = append(items, Item{
+			Type:    models.SecretTypeBinary,
+			Name:    name + "/" + b.Key,
+			Payload: models.BinaryPayload{Data: data, Meta: meta("Attachment of " + name)},
+		})
+	}
+
+	return items, nil
+}
+
+// keePassBinaryData decodes a base64 attachment, gunzipping it if compressed.
+func keePassBinaryData(encoded string, compressed bool) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+	if !compressed {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
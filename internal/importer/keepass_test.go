@@ -0,0 +1,82 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keePassExport = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinUUID>cmVjeWNsZWJpbnV1aWQ9PQ==</RecycleBinUUID>
+		<Binaries>
+			<Binary ID="0" Compressed="True">H4sIAIJP1WoC/8tIzcnJV0gsKUlMzshNzSsBADcSvqMQAAAA</Binary>
+		</Binaries>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>cm9vdGdyb3VwdXVpZD09</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>GitHub</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value>s3cret!</Value></String>
+				<String><Key>URL</Key><Value>https://github.com</Value></String>
+				<String><Key>Notes</Key><Value>work account</Value></String>
+				<String><Key>Recovery</Key><Value>abcd-efgh</Value></String>
+				<Binary><Key>keys.txt</Key><Value Ref="0" /></Binary>
+				<History>
+					<Entry>
+						<String><Key>Title</Key><Value>GitHub</Value></String>
+						<String><Key>Password</Key><Value>old</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<UUID>ZmluYW5jZWdyb3VwdXVpZA==</UUID>
+				<Name>Finance</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Safe combination</Value></String>
+					<String><Key>Notes</Key><Value>12-34-56</Value></String>
+					<Binary><Key>scan.bin</Key><Value>aW5saW5lIGRhdGE=</Value></Binary>
+				</Entry>
+			</Group>
+			<Group>
+				<UUID>cmVjeWNsZWJpbnV1aWQ9PQ==</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Deleted</Value></String>
+					<String><Key>Password</Key><Value>gone</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>
+`
+
+func TestParseKeePassXML(t *testing.T) {
+	items, err := ParseKeePassXML(strings.NewReader(keePassExport))
+	require.NoError(t, err)
+
+	work, keysMeta := "URL: https://github.com\nwork account\nRecovery: abcd-efgh", "Attachment of GitHub"
+	attachment := "Attachment of Finance/Safe combination"
+	assert.Equal(t, []Item{
+		{Type: models.SecretTypeUser, Name: "GitHub", Payload: models.UserPayload{Username: "alice", Password: "s3cret!", Meta: &work}},
+		{Type: models.SecretTypeBinary, Name: "GitHub/keys.txt", Payload: models.BinaryPayload{Data: []byte("hello attachment"), Meta: &keysMeta}},
+		{Type: models.SecretTypeText, Name: "Finance/Safe combination", Payload: models.TextPayload{Data: "12-34-56"}},
+		{Type: models.SecretTypeBinary, Name: "Finance/Safe combination/scan.bin", Payload: models.BinaryPayload{Data: []byte("inline data"), Meta: &attachment}},
+	}, items)
+}
+
+func TestParseKeePassXML_Errors(t *testing.T) {
+	_, err := ParseKeePassXML(strings.NewReader("not xml"))
+	assert.Error(t, err)
+
+	broken := strings.Replace(keePassExport, `<Value Ref="0" />`, `<Value Ref="7" />`, 1)
+	_, err = ParseKeePassXML(strings.NewReader(broken))
+	assert.ErrorContains(t, err, "missing binary 7")
+}
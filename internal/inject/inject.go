@@ -0,0 +1,148 @@
+// Package inject hands secrets to other programs without writing them to disk: as environment
+// variables of a child process, or rendered into configuration templates.
+//
+// Secrets are referenced as type/name#field, like user/db#password; without the field, the
+// default field of the type is used (see client.DefaultField).
+package inject
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// Ref references one field of a secret.
+type Ref struct {
+	Type  string
+	Name  string
+	Field string // Field is empty for the default field of Type.
+}
+
+// String returns r as type/name#field.
+func (r Ref) String() string {
+	s := r.Type + "/" + r.Name
+	if r.Field != "" {
+		s += "#" + r.Field
+	}
+	return s
+}
+
+// secretTypes are the types a Ref may name.
+var secretTypes = []string{models.SecretTypeBankCard, models.SecretTypeText, models.SecretTypeUser, models.SecretTypeSSHKey}
+
+// ParseRef parses a reference of the form type/name#field. The name may contain slashes; the
+// field follows the last #.
+func ParseRef(s string) (Ref, error) {
+	secretType, rest, ok := strings.Cut(s, "/")
+	if !ok {
+		return Ref{}, fmt.Errorf("invalid secret reference %q, want type/name#field", s)
+	}
+	if !slices.Contains(secretTypes, secretType) {
+		return Ref{}, fmt.Errorf("invalid secret reference %q: unknown secret type %q", s, secretType)
+	}
+	name, field := rest, ""
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		name, field = rest[:i], rest[i+1:]
+		if field == "" {
+			return Ref{}, fmt.Errorf("invalid secret reference %q: empty field", s)
+		}
+	}
+	if name == "" {
+		return Ref{}, fmt.Errorf("invalid secret reference %q: empty name", s)
+	}
+	return Ref{Type: secretType, Name: name, Field: field}, nil
+}
+
+// envName matches the names of environment variables that may be set.
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseEnv parses a NAME=type/name#field assignment of an environment variable.
+func ParseEnv(s string) (string, Ref, error) {
+	name, ref, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", Ref{}, fmt.Errorf("invalid variable %q, want NAME=type/name#field", s)
+	}
+	if !envName.MatchString(name) {
+		return "", Ref{}, fmt.Errorf("invalid variable name %q", name)
+	}
+	r, err := ParseRef(ref)
+	if err != nil {
+		return "", Ref{}, err
+	}
+	return name, r, nil
+}
+
+// Resolver returns the value of a referenced secret field.
+type Resolver interface {
+	Resolve(ctx context.Context, ref Ref) (string, error)
+}
+
+// ResolverFunc adapts a function to a Resolver.
+type ResolverFunc func(ctx context.Context, ref Ref) (string, error)
+
+// Resolve implements Resolver.
+func (f ResolverFunc) Resolve(ctx context.Context, ref Ref) (string, error) {
+	return f(ctx, ref)
+}
+
+// Environ resolves NAME=type/name#field assignments into NAME=value entries of an environment.
+// Every assignment is parsed before the first secret is resolved.
+func Environ(ctx context.Context, resolver Resolver, assignments []string) ([]string, error) {
+	names := make([]string, 0, len(assignments))
+	refs := make([]Ref, 0, len(assignments))
+	for _, a := range assignments {
+		name, ref, err := ParseEnv(a)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		refs = append(refs, ref)
+	}
+
+	env := make([]string, 0, len(assignments))
+	for i, ref := range refs {
+		value, err := resolver.Resolve(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", names[i], err)
+		}
+		env = append(env, names[i]+"="+value)
+	}
+	return env, nil
+}
+
+// Render executes the template text to w. Besides the usual text/template actions it provides
+// the secret function: {{ secret "user/db" "password" }} is the password field of the user secret
+// db, and {{ secret "user/db#password" }} the same; without a field, the default one is used.
+func Render(ctx context.Context, resolver Resolver, name, text string, w io.Writer) error {
+	funcs := template.FuncMap{
+		"secret": func(s string, field ...string) (string, error) {
+			ref, err := ParseRef(s)
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case len(field) > 1:
+				return "", fmt.Errorf("secret %s: too many fields", s)
+			case len(field) == 1 && ref.Field != "":
+				return "", fmt.Errorf("secret %s: field given twice", s)
+			case len(field) == 1:
+				ref.Field = field[0]
+			}
+			return resolver.Resolve(ctx, ref)
+		},
+	}
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Execute(w, nil); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
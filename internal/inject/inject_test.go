@@ -0,0 +1,99 @@
+package inject
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver resolves references from a map keyed by their string form.
+type fakeResolver map[string]string
+
+func (f fakeResolver) Resolve(_ context.Context, ref Ref) (string, error) {
+	value, ok := f[ref.String()]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Ref
+		wantErr string
+	}{
+		{in: "user/db#password", want: Ref{Type: "user", Name: "db", Field: "password"}},
+		{in: "text/note", want: Ref{Type: "text", Name: "note"}},
+		{in: "user/GitHub/work#totp", want: Ref{Type: "user", Name: "GitHub/work", Field: "totp"}},
+		{in: "user/a#b#username", want: Ref{Type: "user", Name: "a#b", Field: "username"}},
+		{in: "db", wantErr: `invalid secret reference "db", want type/name#field`},
+		{in: "binary/file", wantErr: `invalid secret reference "binary/file": unknown secret type "binary"`},
+		{in: "user/#password", wantErr: `invalid secret reference "user/#password": empty name`},
+		{in: "user/db#", wantErr: `invalid secret reference "user/db#": empty field`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRef(tt.in)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.in, got.String())
+		})
+	}
+}
+
+func TestParseEnv(t *testing.T) {
+	name, ref, err := ParseEnv("DB_PASSWORD=user/db#password")
+	require.NoError(t, err)
+	assert.Equal(t, "DB_PASSWORD", name)
+	assert.Equal(t, Ref{Type: "user", Name: "db", Field: "password"}, ref)
+
+	_, _, err = ParseEnv("user/db")
+	assert.EqualError(t, err, `invalid variable "user/db", want NAME=type/name#field`)
+	_, _, err = ParseEnv("1X=user/db")
+	assert.EqualError(t, err, `invalid variable name "1X"`)
+}
+
+func TestEnviron(t *testing.T) {
+	resolver := fakeResolver{"user/db#password": "s3cret", "text/token": "abc=def"}
+
+	env, err := Environ(context.Background(), resolver, []string{"DB_PASSWORD=user/db#password", "TOKEN=text/token"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DB_PASSWORD=s3cret", "TOKEN=abc=def"}, env)
+
+	_, err = Environ(context.Background(), resolver, []string{"A=user/missing"})
+	assert.EqualError(t, err, "failed to resolve A: not found")
+}
+
+func TestRender(t *testing.T) {
+	resolver := fakeResolver{"user/db#password": "s3cret", "user/db#username": "app", "user/db": "default"}
+
+	var out strings.Builder
+	err := Render(context.Background(), resolver, "config", `dsn: postgres://{{ secret "user/db" "username" }}:{{ secret "user/db#password" }}@db
+fallback: {{ secret "user/db" }}
+`, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "dsn: postgres://app:s3cret@db\nfallback: default\n", out.String())
+
+	tests := []struct {
+		text    string
+		wantErr string
+	}{
+		{text: `{{ secret "user/db#password" "username" }}`, wantErr: "field given twice"},
+		{text: `{{ secret "user/db" "a" "b" }}`, wantErr: "too many fields"},
+		{text: `{{ secret "user/missing" }}`, wantErr: "not found"},
+		{text: `{{ secret "user/db"`, wantErr: "failed to parse template"},
+	}
+	for _, tt := range tests {
+		err := Render(context.Background(), resolver, "config", tt.text, &out)
+		assert.ErrorContains(t, err, tt.wantErr, tt.text)
+	}
+}
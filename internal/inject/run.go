@@ -0,0 +1,71 @@
+package inject
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+)
+
+// ExitError reports that the child process did not succeed. Code is its exit status, or 128 plus
+// the signal number when a signal killed it, as in shells.
+type ExitError struct {
+	Code int
+}
+
+// Error implements error.
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Command is a child process to run with secrets in its environment.
+type Command struct {
+	Name   string
+	Args   []string
+	Env    []string // Env is added to the environment of the current process, overriding it.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run runs c and waits for it. The signals a wrapper should pass on, like SIGTERM and SIGHUP,
+// are forwarded to the child rather than stopping the current process. SIGINT and SIGQUIT
+// from the terminal already reach the child and are only kept from stopping the current
+// process. An *ExitError is returned when the child fails.
+func Run(c Command) error {
+	cmd := exec.Command(c.Name, c.Args...)
+	cmd.Env = append(os.Environ(), c.Env...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = c.Stdin, c.Stdout, c.Stderr
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, slices.Concat(forwardedSignals, terminalSignals)...)
+	defer signal.Stop(signals)
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", c.Name, err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if slices.Contains(forwardedSignals, sig) {
+					_ = cmd.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitCode(exitErr.ProcessState)}
+	}
+	return err
+}
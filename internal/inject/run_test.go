@@ -0,0 +1,97 @@
+//go:build unix
+
+package inject
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Setenv("INJECT_TEST_KEPT", "kept")
+	t.Setenv("INJECT_TEST_SECRET", "overridden")
+
+	var out strings.Builder
+	err := Run(Command{
+		Name:   "sh",
+		Args:   []string{"-c", `echo "$INJECT_TEST_KEPT $INJECT_TEST_SECRET"`},
+		Env:    []string{"INJECT_TEST_SECRET=s3cret"},
+		Stdout: &out,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kept s3cret\n", out.String())
+	assert.Equal(t, "overridden", os.Getenv("INJECT_TEST_SECRET"))
+
+	err = Run(Command{Name: "sh", Args: []string{"-c", "exit 3"}})
+	var exitErr *ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.Code)
+
+	err = Run(Command{Name: "sh", Args: []string{"-c", "kill -KILL $$"}})
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 128+int(syscall.SIGKILL), exitErr.Code)
+
+	err = Run(Command{Name: "gophkeeper-missing-command"})
+	assert.ErrorContains(t, err, "failed to start gophkeeper-missing-command")
+}
+
+func TestRun_ForwardsSignals(t *testing.T) {
+	stdout, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(Command{
+			Name:   "sh",
+			Args:   []string{"-c", `trap 'exit 7' TERM; echo ready; while :; do sleep 0.05; done`},
+			Stdout: w,
+		})
+		w.Close()
+	}()
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "ready\n", line)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	var exitErr *ExitError
+	require.ErrorAs(t, <-done, &exitErr)
+	assert.Equal(t, 7, exitErr.Code)
+}
+
+func TestRun_KeepsTerminalSignals(t *testing.T) {
+	stdout, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(Command{
+			Name:   "sh",
+			Args:   []string{"-c", `trap 'echo interrupted' INT; trap 'exit 7' TERM; echo ready; while :; do sleep 0.05; done`},
+			Stdout: w,
+		})
+		w.Close()
+	}()
+
+	r := bufio.NewReader(stdout)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "ready\n", line)
+
+	// The terminal sends SIGINT to the child itself; the wrapper must survive it without passing it on.
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Empty(t, string(rest))
+
+	var exitErr *ExitError
+	require.ErrorAs(t, <-done, &exitErr)
+	assert.Equal(t, 7, exitErr.Code)
+}
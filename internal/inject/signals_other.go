@@ -0,0 +1,17 @@
+//go:build !unix
+
+package inject
+
+import "os"
+
+// forwardedSignals are passed on to the child process.
+var forwardedSignals []os.Signal
+
+// terminalSignals are sent by the console to every process attached to it, the child
+// included, so they only must not stop the current process.
+var terminalSignals = []os.Signal{os.Interrupt}
+
+// exitCode returns the exit status of a finished process.
+func exitCode(state *os.ProcessState) int {
+	return state.ExitCode()
+}
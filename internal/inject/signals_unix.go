@@ -0,0 +1,23 @@
+//go:build unix
+
+package inject
+
+import (
+	"os"
+	"syscall"
+)
+
+// forwardedSignals are passed on to the child process.
+var forwardedSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// terminalSignals are sent by the terminal to its whole foreground process group, which the
+// child is part of, so they only must not stop the current process.
+var terminalSignals = []os.Signal{syscall.SIGINT, syscall.SIGQUIT}
+
+// exitCode returns the exit status of a finished process, 128 plus the signal number when killed by one.
+func exitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
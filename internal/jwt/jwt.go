@@ -1,16 +1,29 @@
 package jwt
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
+func init() {
+	// Issue times are compared with the moment a password change revokes earlier tokens,
+	// whole seconds would keep the tokens issued in the second before the change valid.
+	jwt.TimePrecision = time.Microsecond
+}
+
 // JWT holds config for signing and verifying tokens.
 type JWT struct {
 	secret   string
 	lifetime time.Duration
+	checker  RevocationChecker
+}
+
+// RevocationChecker reports the moment before which all tokens of a user are revoked.
+type RevocationChecker interface {
+	TokensValidAfter(ctx context.Context, username string) (time.Time, error)
 }
 
 // Opt defines a functional option for JWT configuration.
@@ -30,6 +43,13 @@ func WithLifetime(duration time.Duration) Opt {
 	}
 }
 
+// WithRevocationChecker makes Parse reject tokens issued before the time reported by checker.
+func WithRevocationChecker(checker RevocationChecker) Opt {
+	return func(j *JWT) {
+		j.checker = checker
+	}
+}
+
 // New constructs a JWT instance with given options.
 func New(opts ...Opt) *JWT {
 	j := &JWT{}
@@ -73,9 +93,37 @@ func (j *JWT) Parse(tokenStr string) (string, error) {
 		return "", err
 	}
 
-	if claims, ok := parsedToken.Claims.(*claims); ok && parsedToken.Valid {
-		return claims.Username, nil
+	claims, ok := parsedToken.Claims.(*claims)
+	if !ok || !parsedToken.Valid {
+		return "", errors.New("invalid token")
+	}
+
+	if j.checker != nil {
+		validAfter, err := j.checker.TokensValidAfter(context.Background(), claims.Username)
+		if err != nil {
+			return "", errors.New("invalid token")
+		}
+		// Issue times travel as float seconds and may come back a microsecond early.
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Add(time.Microsecond).Before(validAfter) {
+			return "", errors.New("token has been revoked")
+		}
+	}
+
+	return claims.Username, nil
+}
+
+// Username returns the username a token was issued to without verifying its signature.
+// Clients use it to learn whom their secrets belong to; servers must use Parse.
+func Username(tokenStr string) (string, error) {
+	parsedToken, _, err := jwt.NewParser().ParseUnverified(tokenStr, &claims{})
+	if err != nil {
+		return "", err
+	}
+
+	claims, ok := parsedToken.Claims.(*claims)
+	if !ok || claims.Username == "" {
+		return "", errors.New("invalid token")
 	}
 
-	return "", errors.New("invalid token")
+	return claims.Username, nil
 }
@@ -1,8 +1,10 @@
 package jwt
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
 	"testing"
 	"time"
 
@@ -83,3 +85,85 @@ func TestJWT_Parse_InvalidSigningMethod(t *testing.T) {
 	assert.Contains(t, err.Error(), "unexpected signing method")
 	assert.Equal(t, "", username)
 }
+
+type revocationCheckerFunc func(ctx context.Context, username string) (time.Time, error)
+
+func (f revocationCheckerFunc) TokensValidAfter(ctx context.Context, username string) (time.Time, error) {
+	return f(ctx, username)
+}
+
+func TestJWT_Parse_RevocationChecker(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		validAfter time.Time
+		checkErr   error
+		wantErr    bool
+	}{
+		{name: "never revoked", validAfter: time.Unix(0, 0)},
+		{name: "revoked before issue", validAfter: now.Add(-time.Hour)},
+		{name: "revoked after issue", validAfter: now.Add(time.Hour), wantErr: true},
+		{name: "user lookup fails", checkErr: errors.New("not found"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := revocationCheckerFunc(func(_ context.Context, username string) (time.Time, error) {
+				assert.Equal(t, "testuser", username)
+				return tt.validAfter, tt.checkErr
+			})
+			j := New(WithSecret("mysecret"), WithLifetime(time.Minute), WithRevocationChecker(checker))
+
+			token, err := j.Generate("testuser")
+			require.NoError(t, err)
+
+			username, err := j.Parse(token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, username)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "testuser", username)
+		})
+	}
+}
+
+func TestJWT_Parse_RevokedInTheSameSecond(t *testing.T) {
+	var validAfter time.Time
+	checker := revocationCheckerFunc(func(context.Context, string) (time.Time, error) {
+		return validAfter, nil
+	})
+	j := New(WithSecret("mysecret"), WithLifetime(time.Minute), WithRevocationChecker(checker))
+
+	before, err := j.Generate("testuser")
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	validAfter = time.Now().Truncate(time.Microsecond)
+	after, err := j.Generate("testuser")
+	require.NoError(t, err)
+
+	_, err = j.Parse(before)
+	assert.Error(t, err)
+	username, err := j.Parse(after)
+	require.NoError(t, err)
+	assert.Equal(t, "testuser", username)
+}
+
+func TestUsername(t *testing.T) {
+	token, err := New(WithSecret("server-secret"), WithLifetime(time.Minute)).Generate("alice")
+	require.NoError(t, err)
+
+	username, err := Username(token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", username)
+
+	_, err = Username("not.a.token")
+	assert.Error(t, err)
+
+	noUser, err := New(WithSecret("server-secret"), WithLifetime(time.Minute)).Generate("")
+	require.NoError(t, err)
+	_, err = Username(noUser)
+	assert.Error(t, err)
+}
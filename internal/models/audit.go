@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Audit actions recorded by the server.
+const (
+	AuditActionRegister       = "auth.register"
+	AuditActionLogin          = "auth.login"
+	AuditActionLoginFailed    = "auth.login_failed"
+	AuditActionOTPFailed      = "auth.otp_failed"
+	AuditActionRecoveryCode   = "auth.recovery_code_used"
+	AuditActionOTPEnroll      = "auth.otp_enroll"
+	AuditActionOTPConfirm     = "auth.otp_confirm"
+	AuditActionPasswordChange = "auth.password_change"
+	AuditActionSecretGet      = "secret.get"
+	AuditActionSecretList     = "secret.list"
+	AuditActionSecretSave     = "secret.save"
+	AuditActionSecretShare    = "secret.share"
+	AuditActionSecretUnshare  = "secret.unshare"
+	AuditActionSharedList     = "secret.shared_list"
+	AuditActionSharedSave     = "secret.shared_save"
+	AuditActionCertPublish    = "auth.certificate_publish"
+	AuditActionAuditList      = "audit.list"
+	AuditActionOrgCreate      = "org.create"
+	AuditActionOrgDelete      = "org.delete"
+	AuditActionOrgMemberSet   = "org.member_set"
+	AuditActionOrgMemberDrop  = "org.member_remove"
+	AuditActionVaultCreate    = "org.vault_create"
+	AuditActionVaultDelete    = "org.vault_delete"
+)
+
+// AuditEvent is a single entry of the security audit log.
+type AuditEvent struct {
+	ID        int64     `json:"id" db:"id"`                 // ID is the event identifier.
+	Username  string    `json:"username" db:"username"`     // Username is the account the event belongs to.
+	Action    string    `json:"action" db:"action"`         // Action is one of the AuditAction constants.
+	SecretKey string    `json:"secret_key" db:"secret_key"` // SecretKey is "type/name" of the accessed secret, empty for non-secret events.
+	Transport string    `json:"transport" db:"transport"`   // Transport is "http" or "grpc".
+	ClientIP  string    `json:"client_ip" db:"client_ip"`   // ClientIP is the address the request came from.
+	CreatedAt time.Time `json:"created_at" db:"created_at"` // CreatedAt is when the event happened.
+}
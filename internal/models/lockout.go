@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// LockoutEvent records that a login subject was temporarily blocked after repeated failures.
+type LockoutEvent struct {
+	ID          int64     `json:"id" db:"id"`                     // ID is the event identifier.
+	Subject     string    `json:"subject" db:"subject"`           // Subject is the blocked username or client IP.
+	SubjectType string    `json:"subject_type" db:"subject_type"` // SubjectType is either "username" or "ip".
+	ClientIP    string    `json:"client_ip" db:"client_ip"`       // ClientIP is the address the last failed attempt came from.
+	Failures    int       `json:"failures" db:"failures"`         // Failures is the number of failed attempts that triggered the lockout.
+	LockedUntil time.Time `json:"locked_until" db:"locked_until"` // LockedUntil is when the subject may try again.
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`     // CreatedAt is when the lockout started.
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Membership roles, from the most to the least privileged.
+const (
+	RoleOwner    = "owner"     // RoleOwner manages the organization, its members and vaults.
+	RoleAdmin    = "admin"     // RoleAdmin manages members below owner and the vaults.
+	RoleMember   = "member"    // RoleMember reads and writes the secrets of every vault.
+	RoleReadOnly = "read-only" // RoleReadOnly only reads the secrets of every vault.
+)
+
+// Organization groups users sharing vaults.
+type Organization struct {
+	Name      string    `json:"name" db:"name"`             // Name is the unique organization name.
+	CreatedAt time.Time `json:"created_at" db:"created_at"` // CreatedAt is when the organization was created.
+}
+
+// Membership grants a user a role in an organization.
+type Membership struct {
+	Organization string    `json:"organization" db:"organization"` // Organization is the organization name.
+	Username     string    `json:"username" db:"username"`         // Username is the member.
+	Role         string    `json:"role" db:"role"`                 // Role is one of the Role constants.
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`     // CreatedAt is when the user joined.
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`     // UpdatedAt is the last role change.
+}
+
+// Vault is a named collection of secrets owned by an organization.
+// It is addressed as "organization/name".
+type Vault struct {
+	Organization string    `json:"organization" db:"organization"` // Organization is the owning organization.
+	Name         string    `json:"name" db:"name"`                 // Name is unique within the organization.
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`     // CreatedAt is when the vault was created.
+}
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // Secret types
 const (
@@ -8,12 +13,63 @@ const (
 	SecretTypeUser     = "user"
 	SecretTypeText     = "text"
 	SecretTypeBinary   = "binary"
+	SecretTypeSSHKey   = "sshkey"
 )
 
 // SecretEncrypted represents the secret storage structure in the database.
 type SecretEncrypted struct {
-	Ciphertext []byte `json:"ciphertext" db:"ciphertext"`
-	AESKeyEnc  []byte `json:"aes_key_enc" db:"aes_key_enc"`
+	Ciphertext []byte     `json:"ciphertext" db:"ciphertext"`
+	AESKeyEnc  []byte     `json:"aes_key_enc" db:"aes_key_enc"`
+	KeyID      string     `json:"key_id" db:"key_id"`         // KeyID identifies the public key AESKeyEnc is wrapped with.
+	Recipients Recipients `json:"recipients" db:"recipients"` // Recipients holds the data key wrapped for every recipient when there is more than one.
+}
+
+// SecretBinding identifies the secret a ciphertext belongs to. It is authenticated
+// together with the ciphertext, so a ciphertext copied to another secret fails to decrypt.
+type SecretBinding struct {
+	Owner string // Owner is the username of the secret owner, not a token.
+	Type  string
+	Name  string
+}
+
+// Recipient holds the data key of a secret wrapped with one recipient's public key.
+type Recipient struct {
+	KeyID     string `json:"key_id"`      // KeyID identifies the recipient's public key (certificate fingerprint).
+	AESKeyEnc []byte `json:"aes_key_enc"` // AESKeyEnc is the data key wrapped with that public key.
+}
+
+// Recipients lists the recipients of a secret. It is stored as JSON, an empty list as an empty string.
+type Recipients []Recipient
+
+// Value implements driver.Valuer.
+func (r Recipients) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (r *Recipients) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return fmt.Errorf("unsupported recipients type %T", src)
+	}
+	if len(b) == 0 {
+		*r = nil
+		return nil
+	}
+	return json.Unmarshal(b, r)
 }
 
 // SecretGetRequest holds SecretEncrypted secret data.
@@ -24,13 +80,16 @@ type SecretGetRequest struct {
 
 // Secret represents the secret storage structure in the database.
 type Secret struct {
-	SecretName  string    `json:"secret_name" db:"secret_name"`
-	SecretType  string    `json:"secret_type" db:"secret_type"`
-	SecretOwner string    `json:"secret_owner" db:"secret_owner"`
-	Ciphertext  []byte    `json:"ciphertext" db:"ciphertext"`
-	AESKeyEnc   []byte    `json:"aes_key_enc" db:"aes_key_enc"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	SecretName  string     `json:"secret_name" db:"secret_name"`
+	SecretType  string     `json:"secret_type" db:"secret_type"`
+	SecretOwner string     `json:"secret_owner" db:"secret_owner"`
+	Ciphertext  []byte     `json:"ciphertext" db:"ciphertext"`
+	AESKeyEnc   []byte     `json:"aes_key_enc" db:"aes_key_enc"`
+	KeyID       string     `json:"key_id" db:"key_id"`
+	Recipients  Recipients `json:"recipients" db:"recipients"`
+	Vault       string     `json:"vault,omitempty" db:"vault"` // Vault is "organization/vault" for secrets of an organization vault, empty for personal ones.
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // BankcardPayload represents a bank card secret payload.
@@ -58,5 +117,19 @@ type BinaryPayload struct {
 type UserPayload struct {
 	Username string  `json:"username"`
 	Password string  `json:"password"`
+	TOTP     string  `json:"totp,omitempty"` // TOTP is the base32 seed of the account's one-time codes.
+	URL      string  `json:"url,omitempty"`  // URL is the site or service of the login; credential helpers look logins up by it.
 	Meta     *string `json:"meta,omitempty"`
+	// PasswordChangedAt is when Password was last set. Unlike the update time of the secret it
+	// survives re-encryption, sync and restores; logins saved without it have none.
+	PasswordChangedAt *time.Time `json:"password_changed_at,omitempty"`
+}
+
+// SSHKeyPayload represents an SSH key secret payload.
+type SSHKeyPayload struct {
+	PublicKey  string  `json:"public_key"`           // PublicKey is in the authorized_keys format.
+	PrivateKey string  `json:"private_key"`          // PrivateKey is the PEM or OpenSSH encoded private key.
+	Comment    string  `json:"comment,omitempty"`    // Comment names the key to SSH agents, like user@host.
+	Passphrase string  `json:"passphrase,omitempty"` // Passphrase decrypts PrivateKey when it is encrypted.
+	Meta       *string `json:"meta,omitempty"`
 }
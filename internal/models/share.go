@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Share permissions.
+const (
+	SharePermissionRead      = "ro" // SharePermissionRead lets the recipient read the secret.
+	SharePermissionReadWrite = "rw" // SharePermissionReadWrite also lets the recipient replace its content.
+)
+
+// SecretShare grants a user access to a secret of another user.
+type SecretShare struct {
+	SecretOwner string    `json:"secret_owner" db:"secret_owner"` // SecretOwner is the user the secret belongs to.
+	SecretType  string    `json:"secret_type" db:"secret_type"`   // SecretType is the type of the shared secret.
+	SecretName  string    `json:"secret_name" db:"secret_name"`   // SecretName is the name of the shared secret.
+	Recipient   string    `json:"recipient" db:"recipient"`       // Recipient is the user the secret is shared with.
+	AESKeyEnc   []byte    `json:"aes_key_enc" db:"aes_key_enc"`   // AESKeyEnc is the data key wrapped with the recipient's certificate.
+	KeyID       string    `json:"key_id" db:"key_id"`             // KeyID identifies the recipient's certificate.
+	Permission  string    `json:"permission" db:"permission"`     // Permission is one of the SharePermission constants.
+	Stale       bool      `json:"stale" db:"stale"`               // Stale reports that the secret got a new data key since it was shared.
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`     // CreatedAt is when the secret was first shared.
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`     // UpdatedAt is the last change of the share.
+}
+
+// SharedSecret is a secret of another user as seen by a recipient:
+// AESKeyEnc and KeyID hold the data key wrapped for the recipient.
+type SharedSecret struct {
+	Secret
+	Permission string `json:"permission" db:"permission"` // Permission is one of the SharePermission constants.
+}
@@ -1,11 +1,67 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
 
 // User represents a user account in the system.
 type User struct {
-	Username     string    `json:"username" db:"username"`           // Username is the unique identifier for the user.
-	PasswordHash string    `json:"password_hash" db:"password_hash"` // PasswordHash is the hashed password.
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`       // CreatedAt is when the user was created.
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`       // UpdatedAt is the last update time.
+	Username         string     `json:"username" db:"username"`           // Username is the unique identifier for the user.
+	PasswordHash     string     `json:"password_hash" db:"password_hash"` // PasswordHash is the hashed password.
+	OTPSecretEnc     []byte     `json:"-" db:"otp_secret_enc"`            // OTPSecretEnc is the TOTP seed encrypted with the server key.
+	OTPEnabled       bool       `json:"otp_enabled" db:"otp_enabled"`     // OTPEnabled reports whether login requires a one-time code.
+	OTPRecoveryCodes string     `json:"-" db:"otp_recovery_codes"`        // OTPRecoveryCodes holds newline-separated hashes of unused recovery codes.
+	TokensValidAfter time.Time  `json:"-" db:"tokens_valid_after"`        // TokensValidAfter revokes every token issued before it.
+	Certificate      string     `json:"certificate" db:"certificate"`     // Certificate is the PEM certificate other users encrypt shared secrets to.
+	KDFParams        *KDFParams `json:"-" db:"kdf_params"`                // KDFParams are set for users in master-password mode.
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`       // CreatedAt is when the user was created.
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`       // UpdatedAt is the last update time.
+}
+
+// ErrOTPRequired is returned by a login when the account has two-factor authentication
+// enabled and no one-time code was supplied.
+var ErrOTPRequired = errors.New("one-time code required")
+
+// OTPEnrollment holds the data shown to a user once when two-factor authentication is enrolled.
+type OTPEnrollment struct {
+	URI           string   `json:"uri"`            // URI is the otpauth:// key URI for authenticator apps.
+	RecoveryCodes []string `json:"recovery_codes"` // RecoveryCodes are single-use codes accepted instead of a TOTP code.
+}
+
+// KDFAlgorithmArgon2id names the key derivation function of master passwords.
+const KDFAlgorithmArgon2id = "argon2id"
+
+// KDFParams holds the parameters a master password is stretched with.
+// They are stored on the server, which never sees the master password or the keys derived from it.
+type KDFParams struct {
+	Algorithm string `json:"algorithm" example:"argon2id"`                                               // Algorithm is the key derivation function, always argon2id.
+	Salt      []byte `json:"salt" swaggertype:"string" format:"byte" example:"c2FsdHNhbHRzYWx0c2FsdA=="` // Salt is random and unique per user.
+	Time      uint32 `json:"time" example:"3"`                                                           // Time is the number of passes over the memory.
+	Memory    uint32 `json:"memory" example:"65536"`                                                     // Memory is the memory cost in KiB.
+	Threads   uint8  `json:"threads" example:"4"`                                                        // Threads is the degree of parallelism.
+}
+
+// Value implements driver.Valuer.
+func (p KDFParams) Value() (driver.Value, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner.
+func (p *KDFParams) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	case []byte:
+		return json.Unmarshal(v, p)
+	default:
+		return fmt.Errorf("unsupported kdf params type %T", src)
+	}
 }
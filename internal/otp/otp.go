@@ -0,0 +1,209 @@
+package otp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// SecretSize is the length of a generated TOTP seed in bytes (RFC 4226 recommends 160 bits).
+	SecretSize = 20
+	// Digits is the number of digits in a generated code.
+	Digits = 6
+	// Period is the TOTP time step.
+	Period = 30 * time.Second
+	// Skew is the number of time steps accepted before and after the current one.
+	Skew = 1
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random TOTP seed.
+func GenerateSecret() ([]byte, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("seed gen failed: %w", err)
+	}
+	return secret, nil
+}
+
+// URI builds an otpauth:// key URI understood by authenticator apps.
+func URI(issuer, account string, secret []byte) string {
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+
+	q := url.Values{}
+	q.Set("secret", EncodeSecret(secret))
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// EncodeSecret returns secret in base32, the form ParseSecret and authenticator apps accept.
+func EncodeSecret(secret []byte) string {
+	return b32.EncodeToString(secret)
+}
+
+// ParseSecret decodes a TOTP seed given as base32, the form authenticator apps show, or as an
+// otpauth:// URI. Spaces and case are ignored. URIs with other than the SHA-1, Digits and
+// Period parameters Code uses are rejected.
+func ParseSecret(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "otpauth://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid otpauth URI: %w", err)
+		}
+		q := u.Query()
+		for param, want := range map[string]string{
+			"algorithm": "SHA1",
+			"digits":    fmt.Sprint(Digits),
+			"period":    fmt.Sprint(int(Period.Seconds())),
+		} {
+			if v := q.Get(param); v != "" && !strings.EqualFold(v, want) {
+				return nil, fmt.Errorf("unsupported otpauth %s %q, only %s is supported", param, v, want)
+			}
+		}
+		s = q.Get("secret")
+	}
+
+	s = strings.ToUpper(strings.TrimRight(strings.ReplaceAll(s, " ", ""), "="))
+	if s == "" {
+		return nil, fmt.Errorf("empty TOTP secret")
+	}
+	secret, err := b32.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32 TOTP secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Code computes the TOTP code (RFC 6238, HMAC-SHA1) for the given moment.
+func Code(secret []byte, t time.Time) string {
+	return hotp(secret, uint64(t.Unix()/int64(Period.Seconds())))
+}
+
+// Validate reports whether code matches the seed at time t, allowing Skew steps of clock drift.
+func Validate(secret []byte, code string, t time.Time) bool {
+	_, ok := Match(secret, code, t)
+	return ok
+}
+
+// Match is Validate that also returns the time step code belongs to, so that callers can
+// refuse a code of a step at or before the last one they accepted.
+func Match(secret []byte, code string, t time.Time) (int64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+	counter := t.Unix() / int64(Period.Seconds())
+	for i := -Skew; i <= Skew; i++ {
+		if hmac.Equal([]byte(hotp(secret, uint64(counter+int64(i)))), []byte(code)) {
+			return counter + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// hotp computes an HOTP value (RFC 4226) for the given counter.
+func hotp(secret []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
+
+// GenerateRecoveryCodes returns n random single-use recovery codes in the form xxxxx-xxxxx.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("recovery code gen failed: %w", err)
+		}
+		s := hex.EncodeToString(raw)
+		codes = append(codes, s[:5]+"-"+s[5:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode returns the hex SHA-256 digest under which a recovery code is stored.
+func HashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
+
+// Sealer encrypts TOTP seeds at rest with AES-GCM under a key derived from a server secret.
+type Sealer struct {
+	key [32]byte
+}
+
+// NewSealer creates a Sealer whose AES-256 key is the SHA-256 digest of secret.
+func NewSealer(secret string) *Sealer {
+	return &Sealer{key: sha256.Sum256([]byte(secret))}
+}
+
+// Seal encrypts plaintext and returns nonce||ciphertext.
+func (s *Sealer) Seal(plaintext []byte) ([]byte, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce gen failed: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Open decrypts data produced by Seal.
+func (s *Sealer) Open(sealed []byte) ([]byte, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("sealed seed too short")
+	}
+	plaintext, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("seed decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+func (s *Sealer) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key[:])
+	if err != nil {
+		return nil, fmt.Errorf("AES cipher failed: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("GCM failed: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,128 @@
+package otp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6238 appendix B test vector seed for HMAC-SHA1.
+var rfcSecret = []byte("12345678901234567890")
+
+func TestCode_RFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Code(rfcSecret, time.Unix(tt.unix, 0)))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code := Code(rfcSecret, now)
+
+	assert.True(t, Validate(rfcSecret, code, now))
+	assert.True(t, Validate(rfcSecret, code, now.Add(Period)), "one step of drift is accepted")
+	assert.False(t, Validate(rfcSecret, code, now.Add(3*Period)), "codes expire")
+	assert.False(t, Validate(rfcSecret, "12345", now), "wrong length")
+	assert.False(t, Validate([]byte("another-secret-value"), code, now))
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / int64(Period.Seconds())
+
+	got, ok := Match(rfcSecret, Code(rfcSecret, now), now.Add(Period))
+	assert.True(t, ok)
+	assert.Equal(t, step, got, "the step of the code, not of the time it is checked at")
+
+	_, ok = Match(rfcSecret, Code(rfcSecret, now), now.Add(3*Period))
+	assert.False(t, ok)
+}
+
+func TestGenerateSecretAndURI(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, SecretSize)
+
+	uri := URI("GophKeeper", "alice", secret)
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/GophKeeper:alice", parsed.Path)
+	assert.Equal(t, b32.EncodeToString(secret), parsed.Query().Get("secret"))
+	assert.Equal(t, "GophKeeper", parsed.Query().Get("issuer"))
+}
+
+func TestParseSecret(t *testing.T) {
+	encoded := b32.EncodeToString(rfcSecret) // GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ
+
+	for _, in := range []string{
+		encoded,
+		strings.ToLower(encoded),
+		"GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ",
+		encoded + "====",
+		URI("GitHub", "alice", rfcSecret),
+		"otpauth://totp/GitHub:alice?secret=" + encoded,
+	} {
+		secret, err := ParseSecret(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, rfcSecret, secret, in)
+	}
+
+	for _, in := range []string{
+		"",
+		"not base32!",
+		"otpauth://totp/GitHub:alice?secret=" + encoded + "&digits=8",
+		"otpauth://totp/GitHub:alice?secret=" + encoded + "&algorithm=SHA256",
+	} {
+		_, err := ParseSecret(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	for _, c := range codes {
+		assert.Len(t, c, 11)
+		assert.Equal(t, "-", c[5:6])
+	}
+
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(" "+strings.ToUpper(codes[0])+" "))
+	assert.NotEqual(t, HashRecoveryCode(codes[0]), HashRecoveryCode(codes[1]))
+}
+
+func TestSealer(t *testing.T) {
+	s := NewSealer("server-secret")
+
+	sealed, err := s.Seal([]byte("seed"))
+	require.NoError(t, err)
+
+	opened, err := s.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("seed"), opened)
+
+	_, err = NewSealer("other-secret").Open(sealed)
+	assert.Error(t, err)
+
+	_, err = s.Open([]byte("short"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+const (
+	// SubjectUsername marks limits and lockout events tracked per username.
+	SubjectUsername = "username"
+	// SubjectIP marks limits and lockout events tracked per client IP.
+	SubjectIP = "ip"
+)
+
+// Recorder persists lockout events.
+type Recorder interface {
+	Save(ctx context.Context, event *models.LockoutEvent) error
+}
+
+// entry is the failure state of one subject.
+type entry struct {
+	failures    int
+	lockouts    int
+	lockedUntil time.Time
+	lastSeen    time.Time
+}
+
+// Limiter tracks failed authentication attempts per username and per client IP
+// and locks subjects out with exponentially growing durations.
+//
+// After maxFailures consecutive failures a subject is locked for baseLockout,
+// each further lockout doubles the duration up to maxLockout.
+// A client IP gets ipFactor times more attempts than a username, since many users may share it.
+type Limiter struct {
+	mu       sync.Mutex
+	entries  map[string]*entry
+	lastGC   time.Time
+	recorder Recorder
+
+	maxFailures int
+	ipFactor    int
+	baseLockout time.Duration
+	maxLockout  time.Duration
+	now         func() time.Time
+}
+
+// Opt defines a functional option for Limiter configuration.
+type Opt func(*Limiter)
+
+// WithMaxFailures sets the number of failures that triggers a lockout. Zero disables limiting.
+func WithMaxFailures(n int) Opt {
+	return func(l *Limiter) {
+		l.maxFailures = n
+	}
+}
+
+// WithIPFactor sets how many times more failures a client IP may make than a single username.
+func WithIPFactor(n int) Opt {
+	return func(l *Limiter) {
+		if n > 0 {
+			l.ipFactor = n
+		}
+	}
+}
+
+// WithLockout sets the first lockout duration and the upper bound for exponential growth.
+func WithLockout(base, max time.Duration) Opt {
+	return func(l *Limiter) {
+		l.baseLockout = base
+		l.maxLockout = max
+	}
+}
+
+// WithRecorder sets where lockout events are persisted.
+func WithRecorder(r Recorder) Opt {
+	return func(l *Limiter) {
+		l.recorder = r
+	}
+}
+
+// WithClock replaces time.Now, used in tests.
+func WithClock(now func() time.Time) Opt {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// New creates a Limiter. Defaults: 5 failures, IP factor 4, lockout from 1 minute up to 1 hour.
+func New(opts ...Opt) *Limiter {
+	l := &Limiter{
+		entries:     make(map[string]*entry),
+		maxFailures: 5,
+		ipFactor:    4,
+		baseLockout: time.Minute,
+		maxLockout:  time.Hour,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.maxLockout < l.baseLockout {
+		l.maxLockout = l.baseLockout
+	}
+	return l
+}
+
+// Check reports whether an attempt from ip for username may proceed.
+// When it may not, the returned duration tells how long the caller has to wait.
+func (l *Limiter) Check(ip, username string) (time.Duration, bool) {
+	if l.maxFailures <= 0 {
+		return 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, key := range keys(ip, username) {
+		if e, ok := l.entries[key]; ok && e.lockedUntil.After(now) {
+			if d := e.lockedUntil.Sub(now); d > wait {
+				wait = d
+			}
+		}
+	}
+	return wait, wait == 0
+}
+
+// Fail registers a failed attempt from ip for username.
+// Lockouts triggered by it are passed to the recorder.
+func (l *Limiter) Fail(ctx context.Context, ip, username string) {
+	if l.maxFailures <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	now := l.now()
+	l.gc(now)
+
+	var events []*models.LockoutEvent
+	if ip != "" {
+		if ev := l.fail(now, SubjectIP, ip, l.maxFailures*l.ipFactor); ev != nil {
+			events = append(events, ev)
+		}
+	}
+	if username != "" {
+		if ev := l.fail(now, SubjectUsername, username, l.maxFailures); ev != nil {
+			events = append(events, ev)
+		}
+	}
+	l.mu.Unlock()
+
+	if l.recorder == nil {
+		return
+	}
+	for _, ev := range events {
+		ev.ClientIP = ip
+		if err := l.recorder.Save(ctx, ev); err != nil {
+			log.Printf("failed to record lockout of %s %q: %v", ev.SubjectType, ev.Subject, err)
+		}
+	}
+}
+
+// Succeed registers a successful attempt and clears the failure state of username.
+// The client IP state is kept so that one valid account cannot be used to reset a spraying attack.
+func (l *Limiter) Succeed(ip, username string) {
+	if l.maxFailures <= 0 || username == "" {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, SubjectUsername+":"+username)
+}
+
+// fail increments the failure counter of a subject and locks it once limit is reached.
+// It must be called with l.mu held.
+func (l *Limiter) fail(now time.Time, subjectType, subject string, limit int) *models.LockoutEvent {
+	key := subjectType + ":" + subject
+	e, ok := l.entries[key]
+	if !ok {
+		e = &entry{}
+		l.entries[key] = e
+	}
+	e.lastSeen = now
+	e.failures++
+	if e.failures < limit {
+		return nil
+	}
+
+	lockout := l.baseLockout
+	for i := 0; i < e.lockouts && lockout < l.maxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > l.maxLockout {
+		lockout = l.maxLockout
+	}
+	e.lockouts++
+	e.failures = 0
+	e.lockedUntil = now.Add(lockout)
+
+	return &models.LockoutEvent{
+		Subject:     subject,
+		SubjectType: subjectType,
+		Failures:    limit,
+		LockedUntil: e.lockedUntil,
+		CreatedAt:   now,
+	}
+}
+
+// gc drops subjects that have been quiet for longer than the maximum lockout.
+// It must be called with l.mu held.
+func (l *Limiter) gc(now time.Time) {
+	if now.Sub(l.lastGC) < l.maxLockout {
+		return
+	}
+	l.lastGC = now
+	for key, e := range l.entries {
+		if now.Sub(e.lastSeen) > l.maxLockout && !e.lockedUntil.After(now) {
+			delete(l.entries, key)
+		}
+	}
+}
+
+func keys(ip, username string) []string {
+	var ks []string
+	if ip != "" {
+		ks = append(ks, SubjectIP+":"+ip)
+	}
+	if username != "" {
+		ks = append(ks, SubjectUsername+":"+username)
+	}
+	return ks
+}
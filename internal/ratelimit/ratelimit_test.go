@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRecorder struct {
+	events []*models.LockoutEvent
+	err    error
+}
+
+func (f *fakeRecorder) Save(_ context.Context, event *models.LockoutEvent) error {
+	f.events = append(f.events, event)
+	return f.err
+}
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestLimiter_UsernameLockoutIsExponential(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 8, 1, 12, 0, 0, 0, time.UTC)}
+	rec := &fakeRecorder{}
+	l := New(
+		WithMaxFailures(3),
+		WithLockout(time.Minute, 3*time.Minute),
+		WithRecorder(rec),
+		WithClock(clock.now),
+	)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		l.Fail(ctx, "10.0.0.1", "alice")
+		_, ok := l.Check("10.0.0.1", "alice")
+		require.True(t, ok)
+	}
+
+	// Third failure locks the username for the base duration.
+	l.Fail(ctx, "10.0.0.1", "alice")
+	wait, ok := l.Check("10.0.0.2", "alice")
+	require.False(t, ok)
+	assert.Equal(t, time.Minute, wait)
+
+	// Other users from the same IP are not affected yet.
+	_, ok = l.Check("10.0.0.1", "bob")
+	assert.True(t, ok)
+
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, SubjectUsername, rec.events[0].SubjectType)
+	assert.Equal(t, "alice", rec.events[0].Subject)
+	assert.Equal(t, "10.0.0.1", rec.events[0].ClientIP)
+	assert.Equal(t, 3, rec.events[0].Failures)
+
+	// Second lockout doubles, third is capped.
+	clock.t = clock.t.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		l.Fail(ctx, "10.0.0.1", "alice")
+	}
+	wait, _ = l.Check("", "alice")
+	assert.Equal(t, 2*time.Minute, wait)
+
+	clock.t = clock.t.Add(2 * time.Minute)
+	for i := 0; i < 3; i++ {
+		l.Fail(ctx, "10.0.0.1", "alice")
+	}
+	wait, _ = l.Check("", "alice")
+	assert.Equal(t, 3*time.Minute, wait)
+
+	// Lock expires.
+	clock.t = clock.t.Add(3 * time.Minute)
+	_, ok = l.Check("", "alice")
+	assert.True(t, ok)
+}
+
+func TestLimiter_IPLockout(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	rec := &fakeRecorder{}
+	l := New(
+		WithMaxFailures(2),
+		WithIPFactor(2),
+		WithLockout(time.Minute, time.Hour),
+		WithRecorder(rec),
+		WithClock(clock.now),
+	)
+	ctx := context.Background()
+
+	// Spray different usernames from one address.
+	for _, u := range []string{"a", "b", "c", "d"} {
+		l.Fail(ctx, "10.0.0.1", u)
+	}
+
+	_, ok := l.Check("10.0.0.1", "e")
+	assert.False(t, ok)
+	_, ok = l.Check("10.0.0.2", "e")
+	assert.True(t, ok)
+
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, SubjectIP, rec.events[0].SubjectType)
+	assert.Equal(t, "10.0.0.1", rec.events[0].Subject)
+}
+
+func TestLimiter_SucceedResetsUsername(t *testing.T) {
+	l := New(WithMaxFailures(2), WithIPFactor(10))
+	ctx := context.Background()
+
+	l.Fail(ctx, "10.0.0.1", "alice")
+	l.Succeed("10.0.0.1", "alice")
+	l.Fail(ctx, "10.0.0.1", "alice")
+
+	_, ok := l.Check("10.0.0.1", "alice")
+	assert.True(t, ok)
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	l := New(WithMaxFailures(0))
+	ctx := context.Background()
+
+	for i := 0; i < 100; i++ {
+		l.Fail(ctx, "10.0.0.1", "alice")
+	}
+	_, ok := l.Check("10.0.0.1", "alice")
+	assert.True(t, ok)
+}
+
+func TestLimiter_RecorderErrorIsIgnored(t *testing.T) {
+	rec := &fakeRecorder{err: errors.New("db error")}
+	l := New(WithMaxFailures(1), WithRecorder(rec))
+
+	l.Fail(context.Background(), "", "alice")
+
+	_, ok := l.Check("", "alice")
+	assert.False(t, ok)
+	assert.Len(t, rec.events, 1)
+}
+
+func TestLimiter_GC(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	l := New(WithMaxFailures(5), WithLockout(time.Minute, time.Minute), WithClock(clock.now))
+	ctx := context.Background()
+
+	l.Fail(ctx, "10.0.0.1", "alice")
+	assert.Len(t, l.entries, 2)
+
+	clock.t = clock.t.Add(2 * time.Minute)
+	l.Fail(ctx, "10.0.0.2", "bob")
+	assert.Len(t, l.entries, 2)
+	assert.NotContains(t, l.entries, "username:alice")
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// AuditEventsPerUser is the number of newest audit events kept for each user.
+const AuditEventsPerUser = 10000
+
+// AuditEventWriteRepository handles write operations for audit events.
+type AuditEventWriteRepository struct {
+	db   *sqlx.DB
+	keep int
+}
+
+// NewAuditEventWriteRepository creates a new AuditEventWriteRepository.
+func NewAuditEventWriteRepository(db *sqlx.DB) *AuditEventWriteRepository {
+	return &AuditEventWriteRepository{db: db, keep: AuditEventsPerUser}
+}
+
+// Save inserts an audit event and drops the events of its user beyond the newest AuditEventsPerUser,
+// so that a flood of failed logins cannot grow the log without bound.
+func (r *AuditEventWriteRepository) Save(ctx context.Context, event *models.AuditEvent) error {
+	query := `
+		INSERT INTO audit_events (username, action, secret_key, transport, client_ip, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP);
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		event.Username,
+		event.Action,
+		event.SecretKey,
+		event.Transport,
+		event.ClientIP,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save audit event: %w", err)
+	}
+
+	prune := `
+		DELETE FROM audit_events
+		WHERE username = $1 AND id NOT IN (
+			SELECT id FROM audit_events WHERE username = $1 ORDER BY id DESC LIMIT $2
+		);
+	`
+	if _, err := r.db.ExecContext(ctx, prune, event.Username, r.keep); err != nil {
+		return fmt.Errorf("failed to prune audit events: %w", err)
+	}
+	return nil
+}
+
+// AuditEventReadRepository handles read operations for audit events.
+type AuditEventReadRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditEventReadRepository creates a new AuditEventReadRepository.
+func NewAuditEventReadRepository(db *sqlx.DB) *AuditEventReadRepository {
+	return &AuditEventReadRepository{db: db}
+}
+
+// List returns at most limit audit events of a user, newest first.
+func (r *AuditEventReadRepository) List(ctx context.Context, username string, limit int) ([]*models.AuditEvent, error) {
+	query := `
+		SELECT id, username, action, secret_key, transport, client_ip, created_at
+		FROM audit_events
+		WHERE username = $1
+		ORDER BY id DESC
+		LIMIT $2;
+	`
+	var events []*models.AuditEvent
+	err := r.db.SelectContext(ctx, &events, query, username, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func setupAuditTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+
+	schema := `
+	CREATE TABLE audit_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL,
+		action TEXT NOT NULL,
+		secret_key TEXT NOT NULL DEFAULT '',
+		transport TEXT NOT NULL DEFAULT '',
+		client_ip TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestAuditEventRepository_SaveAndList(t *testing.T) {
+	db := setupAuditTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewAuditEventWriteRepository(db)
+	readRepo := NewAuditEventReadRepository(db)
+	ctx := context.Background()
+
+	events := []*models.AuditEvent{
+		{Username: "alice", Action: models.AuditActionLogin, Transport: "http", ClientIP: "10.0.0.1"},
+		{Username: "alice", Action: models.AuditActionSecretGet, SecretKey: "user/mail", Transport: "grpc", ClientIP: "10.0.0.2"},
+		{Username: "bob", Action: models.AuditActionLogin, Transport: "http", ClientIP: "10.0.0.3"},
+		{Username: "alice", Action: models.AuditActionSecretList, Transport: "http", ClientIP: "10.0.0.1"},
+	}
+	for _, ev := range events {
+		require.NoError(t, writeRepo.Save(ctx, ev))
+	}
+
+	got, err := readRepo.List(ctx, "alice", 10)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, models.AuditActionSecretList, got[0].Action)
+	assert.Equal(t, models.AuditActionSecretGet, got[1].Action)
+	assert.Equal(t, "user/mail", got[1].SecretKey)
+	assert.Equal(t, "grpc", got[1].Transport)
+	assert.Equal(t, "10.0.0.2", got[1].ClientIP)
+	assert.False(t, got[1].CreatedAt.IsZero())
+
+	got, err = readRepo.List(ctx, "alice", 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, models.AuditActionSecretList, got[0].Action)
+}
+
+func TestAuditEventRepository_Retention(t *testing.T) {
+	db := setupAuditTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewAuditEventWriteRepository(db)
+	writeRepo.keep = 2
+	readRepo := NewAuditEventReadRepository(db)
+	ctx := context.Background()
+
+	for _, action := range []string{models.AuditActionLogin, models.AuditActionLoginFailed, models.AuditActionSecretList} {
+		require.NoError(t, writeRepo.Save(ctx, &models.AuditEvent{Username: "alice", Action: action}))
+	}
+	require.NoError(t, writeRepo.Save(ctx, &models.AuditEvent{Username: "bob", Action: models.AuditActionLogin}))
+
+	got, err := readRepo.List(ctx, "alice", 10)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, models.AuditActionSecretList, got[0].Action)
+	assert.Equal(t, models.AuditActionLoginFailed, got[1].Action)
+
+	got, err = readRepo.List(ctx, "bob", 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestAuditEventRepository_Errors(t *testing.T) {
+	db := setupAuditTestDB(t)
+	db.Close()
+
+	ctx := context.Background()
+
+	err := NewAuditEventWriteRepository(db).Save(ctx, &models.AuditEvent{Username: "alice"})
+	assert.Error(t, err)
+
+	_, err = NewAuditEventReadRepository(db).List(ctx, "alice", 10)
+	assert.Error(t, err)
+}
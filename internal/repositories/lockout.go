@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// LockoutEventWriteRepository handles write operations for login lockout events.
+type LockoutEventWriteRepository struct {
+	db *sqlx.DB
+}
+
+// NewLockoutEventWriteRepository creates a new LockoutEventWriteRepository.
+func NewLockoutEventWriteRepository(db *sqlx.DB) *LockoutEventWriteRepository {
+	return &LockoutEventWriteRepository{db: db}
+}
+
+// Save inserts a lockout event.
+func (r *LockoutEventWriteRepository) Save(ctx context.Context, event *models.LockoutEvent) error {
+	query := `
+		INSERT INTO lockout_events (subject, subject_type, client_ip, failures, locked_until, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP);
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		event.Subject,
+		event.SubjectType,
+		event.ClientIP,
+		event.Failures,
+		event.LockedUntil,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save lockout event: %w", err)
+	}
+	return nil
+}
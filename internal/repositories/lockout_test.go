@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func setupLockoutTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+
+	schema := `
+	CREATE TABLE lockout_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		subject TEXT NOT NULL,
+		subject_type TEXT NOT NULL,
+		client_ip TEXT NOT NULL,
+		failures INTEGER NOT NULL,
+		locked_until DATETIME NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestLockoutEventRepository_Save(t *testing.T) {
+	db := setupLockoutTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewLockoutEventWriteRepository(db)
+	ctx := context.Background()
+
+	lockedUntil := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+
+	require.NoError(t, writeRepo.Save(ctx, &models.LockoutEvent{
+		Subject:     "alice",
+		SubjectType: "username",
+		ClientIP:    "10.0.0.1",
+		Failures:    5,
+		LockedUntil: lockedUntil,
+	}))
+	require.NoError(t, writeRepo.Save(ctx, &models.LockoutEvent{
+		Subject:     "10.0.0.1",
+		SubjectType: "ip",
+		ClientIP:    "10.0.0.1",
+		Failures:    20,
+		LockedUntil: lockedUntil,
+	}))
+
+	var events []*models.LockoutEvent
+	require.NoError(t, db.SelectContext(ctx, &events, `
+		SELECT id, subject, subject_type, client_ip, failures, locked_until, created_at
+		FROM lockout_events ORDER BY id;
+	`))
+	require.Len(t, events, 2)
+	assert.Equal(t, "alice", events[0].Subject)
+	assert.Equal(t, "username", events[0].SubjectType)
+	assert.Equal(t, 5, events[0].Failures)
+	assert.True(t, lockedUntil.Equal(events[0].LockedUntil))
+	assert.Equal(t, "ip", events[1].SubjectType)
+	assert.Equal(t, 20, events[1].Failures)
+}
+
+func TestLockoutEventRepository_Errors(t *testing.T) {
+	db := setupLockoutTestDB(t)
+	db.Close()
+
+	err := NewLockoutEventWriteRepository(db).Save(context.Background(), &models.LockoutEvent{Subject: "alice"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// OrganizationWriteRepository handles write operations related to organizations,
+// their memberships and vaults.
+type OrganizationWriteRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrganizationWriteRepository(db *sqlx.DB) *OrganizationWriteRepository {
+	return &OrganizationWriteRepository{db: db}
+}
+
+// Create inserts a new organization and makes owner its first member with the owner role.
+func (r *OrganizationWriteRepository) Create(ctx context.Context, name string, owner string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO organizations (name, created_at) VALUES ($1, CURRENT_TIMESTAMP);`, name); err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, saveMembershipQuery, name, owner, models.RoleOwner); err != nil {
+		return fmt.Errorf("failed to add organization owner: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+	return nil
+}
+
+// Delete removes an organization together with its memberships, vaults and their secrets.
+// It fails with an error wrapping sql.ErrNoRows when the organization does not exist.
+func (r *OrganizationWriteRepository) Delete(ctx context.Context, name string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM vault_secrets WHERE organization = $1;`,
+		`DELETE FROM vaults WHERE organization = $1;`,
+		`DELETE FROM memberships WHERE organization = $1;`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, name); err != nil {
+			return fmt.Errorf("failed to delete organization: %w", err)
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM organizations WHERE name = $1;`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	if err := expectAffected(res, "failed to delete organization"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+	return nil
+}
+
+// SaveMembership adds a user to an organization or changes their role.
+func (r *OrganizationWriteRepository) SaveMembership(
+	ctx context.Context,
+	organization string,
+	username string,
+	role string,
+) error {
+	if _, err := r.db.ExecContext(ctx, saveMembershipQuery, organization, username, role); err != nil {
+		return fmt.Errorf("failed to save membership: %w", err)
+	}
+	return nil
+}
+
+// DeleteMembership removes a user from an organization.
+// It fails with an error wrapping sql.ErrNoRows when the user is not a member.
+func (r *OrganizationWriteRepository) DeleteMembership(ctx context.Context, organization string, username string) error {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM memberships WHERE organization = $1 AND username = $2;`,
+		organization, username,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete membership: %w", err)
+	}
+	return expectAffected(res, "failed to delete membership")
+}
+
+// CreateVault inserts a new vault into an organization.
+func (r *OrganizationWriteRepository) CreateVault(ctx context.Context, organization string, name string) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO vaults (organization, name, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP);`,
+		organization, name,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create vault: %w", err)
+	}
+	return nil
+}
+
+// DeleteVault removes a vault together with its secrets.
+// It fails with an error wrapping sql.ErrNoRows when the vault does not exist.
+func (r *OrganizationWriteRepository) DeleteVault(ctx context.Context, organization string, name string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete vault: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM vault_secrets WHERE organization = $1 AND vault = $2;`,
+		organization, name,
+	); err != nil {
+		return fmt.Errorf("failed to delete vault secrets: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM vaults WHERE organization = $1 AND name = $2;`, organization, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete vault: %w", err)
+	}
+	if err := expectAffected(res, "failed to delete vault"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete vault: %w", err)
+	}
+	return nil
+}
+
+// saveMembershipQuery upserts the role of a user in an organization.
+const saveMembershipQuery = `
+	INSERT INTO memberships (organization, username, role, created_at, updated_at)
+	VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	ON CONFLICT(organization, username) DO UPDATE SET
+		role = EXCLUDED.role,
+		updated_at = CURRENT_TIMESTAMP;
+`
+
+// expectAffected returns an error wrapping sql.ErrNoRows when res changed no rows.
+func expectAffected(res sql.Result, msg string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", msg, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// OrganizationReadRepository handles read operations related to organizations.
+type OrganizationReadRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrganizationReadRepository(db *sqlx.DB) *OrganizationReadRepository {
+	return &OrganizationReadRepository{db: db}
+}
+
+// Get fetches an organization by name.
+func (r *OrganizationReadRepository) Get(ctx context.Context, name string) (*models.Organization, error) {
+	query := `
+		SELECT name, created_at
+		FROM organizations
+		WHERE name = $1
+	`
+
+	var org models.Organization
+	if err := r.db.GetContext(ctx, &org, query, name); err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return &org, nil
+}
+
+// GetMembership fetches the membership of a user in an organization.
+func (r *OrganizationReadRepository) GetMembership(
+	ctx context.Context,
+	organization string,
+	username string,
+) (*models.Membership, error) {
+	query := `
+		SELECT organization, username, role, created_at, updated_at
+		FROM memberships
+		WHERE organization = $1 AND username = $2
+	`
+
+	var m models.Membership
+	if err := r.db.GetContext(ctx, &m, query, organization, username); err != nil {
+		return nil, fmt.Errorf("failed to get membership: %w", err)
+	}
+	return &m, nil
+}
+
+// ListMembers fetches all memberships of an organization.
+func (r *OrganizationReadRepository) ListMembers(ctx context.Context, organization string) ([]*models.Membership, error) {
+	query := `
+		SELECT organization, username, role, created_at, updated_at
+		FROM memberships
+		WHERE organization = $1
+		ORDER BY username
+	`
+
+	var members []*models.Membership
+	if err := r.db.SelectContext(ctx, &members, query, organization); err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	return members, nil
+}
+
+// ListByUser fetches the memberships of a user in all organizations.
+func (r *OrganizationReadRepository) ListByUser(ctx context.Context, username string) ([]*models.Membership, error) {
+	query := `
+		SELECT organization, username, role, created_at, updated_at
+		FROM memberships
+		WHERE username = $1
+		ORDER BY organization
+	`
+
+	var memberships []*models.Membership
+	if err := r.db.SelectContext(ctx, &memberships, query, username); err != nil {
+		return nil, fmt.Errorf("failed to list memberships: %w", err)
+	}
+	return memberships, nil
+}
+
+// GetVault fetches a vault of an organization.
+func (r *OrganizationReadRepository) GetVault(ctx context.Context, organization string, name string) (*models.Vault, error) {
+	query := `
+		SELECT organization, name, created_at
+		FROM vaults
+		WHERE organization = $1 AND name = $2
+	`
+
+	var v models.Vault
+	if err := r.db.GetContext(ctx, &v, query, organization, name); err != nil {
+		return nil, fmt.Errorf("failed to get vault: %w", err)
+	}
+	return &v, nil
+}
+
+// ListVaults fetches all vaults of an organization.
+func (r *OrganizationReadRepository) ListVaults(ctx context.Context, organization string) ([]*models.Vault, error) {
+	query := `
+		SELECT organization, name, created_at
+		FROM vaults
+		WHERE organization = $1
+		ORDER BY name
+	`
+
+	var vaults []*models.Vault
+	if err := r.db.SelectContext(ctx, &vaults, query, organization); err != nil {
+		return nil, fmt.Errorf("failed to list vaults: %w", err)
+	}
+	return vaults, nil
+}
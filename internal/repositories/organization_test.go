@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+func setupOrganizationTestDB(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+
+	schema := `
+	CREATE TABLE organizations (
+		name TEXT PRIMARY KEY,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE memberships (
+		organization TEXT NOT NULL,
+		username TEXT NOT NULL,
+		role TEXT NOT NULL CHECK (role IN ('owner', 'admin', 'member', 'read-only')),
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (organization, username)
+	);
+	CREATE TABLE vaults (
+		organization TEXT NOT NULL,
+		name TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (organization, name)
+	);
+	CREATE TABLE vault_secrets (
+		organization TEXT NOT NULL,
+		vault TEXT NOT NULL,
+		secret_name TEXT NOT NULL,
+		secret_type TEXT NOT NULL,
+		ciphertext BLOB NOT NULL,
+		aes_key_enc BLOB NOT NULL,
+		key_id TEXT NOT NULL DEFAULT '',
+		recipients TEXT NOT NULL DEFAULT '',
+		updated_by TEXT NOT NULL,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (organization, vault, secret_type, secret_name)
+	);
+	`
+	_, err = db.Exec(schema)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestOrganizationRepository_Memberships(t *testing.T) {
+	db := setupOrganizationTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewOrganizationWriteRepository(db)
+	readRepo := NewOrganizationReadRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, writeRepo.Create(ctx, "acme", "alice"))
+	require.Error(t, writeRepo.Create(ctx, "acme", "bob"), "organization names are unique")
+
+	org, err := readRepo.Get(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", org.Name)
+
+	_, err = readRepo.Get(ctx, "globex")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	m, err := readRepo.GetMembership(ctx, "acme", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleOwner, m.Role)
+
+	require.NoError(t, writeRepo.SaveMembership(ctx, "acme", "bob", models.RoleReadOnly))
+	require.NoError(t, writeRepo.SaveMembership(ctx, "acme", "bob", models.RoleMember))
+	require.Error(t, writeRepo.SaveMembership(ctx, "acme", "carol", "superuser"))
+
+	members, err := readRepo.ListMembers(ctx, "acme")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "bob", members[1].Username)
+	assert.Equal(t, models.RoleMember, members[1].Role)
+
+	memberships, err := readRepo.ListByUser(ctx, "bob")
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, "acme", memberships[0].Organization)
+
+	require.NoError(t, writeRepo.DeleteMembership(ctx, "acme", "bob"))
+	require.ErrorIs(t, writeRepo.DeleteMembership(ctx, "acme", "bob"), sql.ErrNoRows)
+
+	_, err = readRepo.GetMembership(ctx, "acme", "bob")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestOrganizationRepository_VaultsAndDelete(t *testing.T) {
+	db := setupOrganizationTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewOrganizationWriteRepository(db)
+	readRepo := NewOrganizationReadRepository(db)
+	secretWriter := NewVaultSecretWriteRepository(db)
+	secretReader := NewVaultSecretReadRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, writeRepo.Create(ctx, "acme", "alice"))
+	require.NoError(t, writeRepo.CreateVault(ctx, "acme", "ops"))
+	require.NoError(t, writeRepo.CreateVault(ctx, "acme", "dev"))
+	require.Error(t, writeRepo.CreateVault(ctx, "acme", "ops"))
+
+	vaults, err := readRepo.ListVaults(ctx, "acme")
+	require.NoError(t, err)
+	require.Len(t, vaults, 2)
+	assert.Equal(t, "dev", vaults[0].Name)
+
+	_, err = readRepo.GetVault(ctx, "acme", "ops")
+	require.NoError(t, err)
+
+	require.NoError(t, secretWriter.Save(ctx, "acme", "ops", "db", models.SecretTypeUser, []byte("c"), []byte("k"), "id", nil, "alice"))
+	require.NoError(t, secretWriter.Save(ctx, "acme", "dev", "db", models.SecretTypeUser, []byte("c"), []byte("k"), "id", nil, "alice"))
+
+	require.NoError(t, writeRepo.DeleteVault(ctx, "acme", "ops"))
+	require.ErrorIs(t, writeRepo.DeleteVault(ctx, "acme", "ops"), sql.ErrNoRows)
+
+	_, err = readRepo.GetVault(ctx, "acme", "ops")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = secretReader.Get(ctx, "acme", "ops", models.SecretTypeUser, "db")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	require.NoError(t, writeRepo.Delete(ctx, "acme"))
+	require.ErrorIs(t, writeRepo.Delete(ctx, "acme"), sql.ErrNoRows)
+
+	secrets, err := secretReader.List(ctx, "acme", "dev")
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+
+	members, err := readRepo.ListMembers(ctx, "acme")
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
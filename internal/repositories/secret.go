@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
@@ -18,6 +20,7 @@ func NewSecretWriteRepository(db *sqlx.DB) *SecretWriteRepository {
 }
 
 // Save inserts or updates a secret, taking explicit arguments.
+// Shares of the secret are marked stale when its data key changes.
 func (r *SecretWriteRepository) Save(
 	ctx context.Context,
 	secretOwner string,
@@ -25,28 +28,173 @@ func (r *SecretWriteRepository) Save(
 	secretType string,
 	ciphertext []byte,
 	aesKeyEnc []byte,
+	keyID string,
+	recipients models.Recipients,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save secret: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = saveSecret(ctx, tx, &models.Secret{
+		SecretName:  secretName,
+		SecretType:  secretType,
+		SecretOwner: secretOwner,
+		Ciphertext:  ciphertext,
+		AESKeyEnc:   aesKeyEnc,
+		KeyID:       keyID,
+		Recipients:  recipients,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save secret: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save secret: %w", err)
+	}
+	return nil
+}
+
+// SaveBatch inserts or updates several secrets of one owner in a single transaction,
+// so either all of them are stored or none. Like Save it marks shares stale.
+// Secrets with creation or update times, such as restored ones, keep them.
+func (r *SecretWriteRepository) SaveBatch(
+	ctx context.Context,
+	secretOwner string,
+	secrets []*models.Secret,
+) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range secrets {
+		secret := *s
+		secret.SecretOwner = secretOwner
+		if err := saveSecret(ctx, tx, &secret); err != nil {
+			return fmt.Errorf("failed to save secret %s/%s: %w", s.SecretType, s.SecretName, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	return nil
+}
+
+// UpdateCiphertext replaces the ciphertext of an existing secret, keeping its wrapped keys.
+// It fails with an error wrapping sql.ErrNoRows when the secret does not exist.
+func (r *SecretWriteRepository) UpdateCiphertext(
+	ctx context.Context,
+	secretOwner string,
+	secretType string,
+	secretName string,
+	ciphertext []byte,
 ) error {
 	query := `
-		INSERT INTO secrets (secret_name, secret_type, secret_owner, ciphertext, aes_key_enc, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		ON CONFLICT(secret_name, secret_type, secret_owner) DO UPDATE SET
-			ciphertext = EXCLUDED.ciphertext,
-			aes_key_enc = EXCLUDED.aes_key_enc,
-			updated_at = CURRENT_TIMESTAMP;
+		UPDATE secrets SET
+			ciphertext = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE secret_owner = $1 AND secret_type = $2 AND secret_name = $3;
 	`
-	_, err := r.db.ExecContext(ctx, query,
-		secretName,
-		secretType,
-		secretOwner,
-		ciphertext,
-		aesKeyEnc,
-	)
+	res, err := r.db.ExecContext(ctx, query, secretOwner, secretType, secretName, ciphertext)
 	if err != nil {
-		return fmt.Errorf("failed to save secret: %w", err)
+		return fmt.Errorf("failed to update secret: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update secret: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to update secret: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// Delete removes a secret. It fails with an error wrapping sql.ErrNoRows when the secret does not exist.
+func (r *SecretWriteRepository) Delete(
+	ctx context.Context,
+	secretOwner string,
+	secretType string,
+	secretName string,
+) error {
+	query := `
+		DELETE FROM secrets
+		WHERE secret_owner = $1 AND secret_type = $2 AND secret_name = $3;
+	`
+	res, err := r.db.ExecContext(ctx, query, secretOwner, secretType, secretName)
+	if err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to delete secret: %w", sql.ErrNoRows)
 	}
 	return nil
 }
 
+// saveSecret upserts a single secret within tx.
+//
+// The server cannot wrap a new data key for the recipients of a share, so the shares of a
+// secret whose wrapped data key changes are marked stale until the owner shares it again.
+func saveSecret(ctx context.Context, tx *sqlx.Tx, s *models.Secret) error {
+	_, err := tx.ExecContext(ctx, staleSharesQuery, s.SecretOwner, s.SecretType, s.SecretName, s.AESKeyEnc)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, saveSecretQuery,
+		s.SecretName,
+		s.SecretType,
+		s.SecretOwner,
+		s.Ciphertext,
+		s.AESKeyEnc,
+		s.KeyID,
+		s.Recipients,
+		timestampArg(s.CreatedAt),
+		timestampArg(s.UpdatedAt),
+	)
+	return err
+}
+
+// timestampArg returns t in the format of CURRENT_TIMESTAMP, or nil for the zero time.
+func timestampArg(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.DateTime)
+}
+
+// staleSharesQuery marks the shares of a secret stale when its stored data key differs from $4.
+const staleSharesQuery = `
+	UPDATE secret_shares SET
+		stale = 1,
+		updated_at = CURRENT_TIMESTAMP
+	WHERE secret_owner = $1 AND secret_type = $2 AND secret_name = $3 AND NOT stale
+		AND EXISTS (
+			SELECT 1 FROM secrets
+			WHERE secret_owner = $1 AND secret_type = $2 AND secret_name = $3 AND aes_key_enc <> $4
+		);
+`
+
+// saveSecretQuery upserts a single secret. $8 and $9 are its creation and update times,
+// NULL for the current time; an updated secret keeps its creation time when $8 is NULL.
+const saveSecretQuery = `
+	INSERT INTO secrets (secret_name, secret_type, secret_owner, ciphertext, aes_key_enc, key_id, recipients, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, CURRENT_TIMESTAMP), COALESCE($9, CURRENT_TIMESTAMP))
+	ON CONFLICT(secret_name, secret_type, secret_owner) DO UPDATE SET
+		ciphertext = EXCLUDED.ciphertext,
+		aes_key_enc = EXCLUDED.aes_key_enc,
+		key_id = EXCLUDED.key_id,
+		recipients = EXCLUDED.recipients,
+		created_at = COALESCE($8, secrets.created_at),
+		updated_at = EXCLUDED.updated_at;
+`
+
 // SecretReadRepository handles read operations related to secrets.
 type SecretReadRepository struct {
 	db *sqlx.DB
@@ -64,7 +212,7 @@ func (r *SecretReadRepository) Get(
 	secretName string,
 ) (*models.Secret, error) {
 	query := `
-		SELECT secret_name, secret_type, secret_owner, ciphertext, aes_key_enc, created_at, updated_at
+		SELECT secret_name, secret_type, secret_owner, ciphertext, aes_key_enc, key_id, recipients, created_at, updated_at
 		FROM secrets
 		WHERE secret_name = $1 AND secret_type = $2 AND secret_owner = $3
 	`
@@ -87,7 +235,7 @@ func (r *SecretReadRepository) List(
 	secretOwner string,
 ) ([]*models.Secret, error) {
 	query := `
-		SELECT secret_name, secret_type, secret_owner, ciphertext, aes_key_enc, created_at, updated_at
+		SELECT secret_name, secret_type, secret_owner, ciphertext, aes_key_enc, key_id, recipients, created_at, updated_at
 		FROM secrets
 		WHERE secret_owner = $1
 	`
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -24,10 +25,26 @@ func setupTestDB(t *testing.T) *sqlx.DB {
 		secret_owner TEXT NOT NULL,
 		ciphertext BLOB NOT NULL,
 		aes_key_enc BLOB NOT NULL,
+		key_id TEXT NOT NULL DEFAULT '',
+		recipients TEXT NOT NULL DEFAULT '',
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		PRIMARY KEY (secret_name, secret_type, secret_owner)
 	);
+
+	CREATE TABLE secret_shares (
+		secret_owner TEXT NOT NULL,
+		secret_type TEXT NOT NULL,
+		secret_name TEXT NOT NULL,
+		recipient TEXT NOT NULL,
+		aes_key_enc BLOB NOT NULL,
+		key_id TEXT NOT NULL DEFAULT '',
+		permission TEXT NOT NULL,
+		stale BOOLEAN NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (secret_owner, secret_type, secret_name, recipient)
+	);
 	`
 	_, err = db.Exec(schema)
 	require.NoError(t, err)
@@ -51,7 +68,7 @@ func TestSecretWriteRepository_SaveAndGet(t *testing.T) {
 	aesKeyEnc := []byte("SecretEncrypted-key")
 
 	// Save new secret
-	err := writeRepo.Save(ctx, owner, secretName, secretType, ciphertext, aesKeyEnc)
+	err := writeRepo.Save(ctx, owner, secretName, secretType, ciphertext, aesKeyEnc, "key-1", nil)
 	require.NoError(t, err)
 
 	// Get secret and verify
@@ -62,6 +79,7 @@ func TestSecretWriteRepository_SaveAndGet(t *testing.T) {
 	assert.Equal(t, owner, got.SecretOwner)
 	assert.Equal(t, ciphertext, got.Ciphertext)
 	assert.Equal(t, aesKeyEnc, got.AESKeyEnc)
+	assert.Equal(t, "key-1", got.KeyID)
 
 	// Wait to ensure updated_at changes (SQLite timestamps have seconds precision)
 	timeBeforeUpdate := time.Now()
@@ -69,12 +87,13 @@ func TestSecretWriteRepository_SaveAndGet(t *testing.T) {
 
 	// Update secret
 	updatedCiphertext := []byte("updated-SecretEncrypted-data")
-	err = writeRepo.Save(ctx, owner, secretName, secretType, updatedCiphertext, aesKeyEnc)
+	err = writeRepo.Save(ctx, owner, secretName, secretType, updatedCiphertext, aesKeyEnc, "key-2", nil)
 	require.NoError(t, err)
 
 	gotUpdated, err := readRepo.Get(ctx, owner, secretType, secretName)
 	require.NoError(t, err)
 	assert.Equal(t, updatedCiphertext, gotUpdated.Ciphertext)
+	assert.Equal(t, "key-2", gotUpdated.KeyID)
 	// updated_at should be after timeBeforeUpdate
 	assert.True(t, gotUpdated.UpdatedAt.After(timeBeforeUpdate) || gotUpdated.UpdatedAt.Equal(timeBeforeUpdate))
 }
@@ -100,7 +119,7 @@ func TestSecretReadRepository_List(t *testing.T) {
 	}
 
 	for _, s := range secrets {
-		err := writeRepo.Save(ctx, owner, s.SecretName, s.SecretType, s.Ciphertext, s.AESKeyEnc)
+		err := writeRepo.Save(ctx, owner, s.SecretName, s.SecretType, s.Ciphertext, s.AESKeyEnc, "", nil)
 		require.NoError(t, err)
 	}
 
@@ -121,3 +140,132 @@ func TestSecretReadRepository_List(t *testing.T) {
 		assert.True(t, found, "secret not found: %s", expected.SecretName)
 	}
 }
+
+func TestSecretWriteRepository_SaveBatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewSecretWriteRepository(db)
+	readRepo := NewSecretReadRepository(db)
+
+	ctx := context.Background()
+	owner := "user1"
+
+	require.NoError(t, writeRepo.Save(ctx, owner, "note", models.SecretTypeText, []byte("old"), []byte("old-key"), "old-id", nil))
+
+	err := writeRepo.SaveBatch(ctx, owner, []*models.Secret{
+		{SecretName: "note", SecretType: models.SecretTypeText, Ciphertext: []byte("new"), AESKeyEnc: []byte("new-key"), KeyID: "new-id"},
+		{SecretName: "card", SecretType: models.SecretTypeBankCard, Ciphertext: []byte("card"), AESKeyEnc: []byte("new-key"), KeyID: "new-id"},
+	})
+	require.NoError(t, err)
+
+	secrets, err := readRepo.List(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	for _, s := range secrets {
+		assert.Equal(t, "new-id", s.KeyID)
+		assert.Equal(t, []byte("new-key"), s.AESKeyEnc)
+	}
+
+	// A failing secret rolls back the whole batch.
+	err = writeRepo.SaveBatch(ctx, owner, []*models.Secret{
+		{SecretName: "note", SecretType: models.SecretTypeText, Ciphertext: []byte("newer"), AESKeyEnc: []byte("k"), KeyID: "newer-id"},
+		{SecretName: "broken", SecretType: models.SecretTypeText, Ciphertext: nil, AESKeyEnc: []byte("k"), KeyID: "newer-id"},
+	})
+	require.Error(t, err)
+
+	got, err := readRepo.Get(ctx, owner, models.SecretTypeText, "note")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), got.Ciphertext)
+	assert.Equal(t, "new-id", got.KeyID)
+}
+
+func TestSecretWriteRepository_SaveBatchTimestamps(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewSecretWriteRepository(db)
+	readRepo := NewSecretReadRepository(db)
+
+	ctx := context.Background()
+	owner := "user1"
+	created := time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)
+	updated := time.Date(2024, time.June, 2, 18, 45, 0, 0, time.UTC)
+
+	require.NoError(t, writeRepo.Save(ctx, owner, "note", models.SecretTypeText, []byte("old"), []byte("key"), "id", nil))
+	before, err := readRepo.Get(ctx, owner, models.SecretTypeText, "note")
+	require.NoError(t, err)
+
+	// Restored secrets keep their times, whether they are new or replace a stored one.
+	err = writeRepo.SaveBatch(ctx, owner, []*models.Secret{
+		{SecretName: "note", SecretType: models.SecretTypeText, Ciphertext: []byte("new"), AESKeyEnc: []byte("key"), KeyID: "id", CreatedAt: created, UpdatedAt: updated},
+		{SecretName: "card", SecretType: models.SecretTypeBankCard, Ciphertext: []byte("card"), AESKeyEnc: []byte("key"), KeyID: "id", CreatedAt: created, UpdatedAt: updated},
+	})
+	require.NoError(t, err)
+	secrets, err := readRepo.List(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	for _, s := range secrets {
+		assert.True(t, created.Equal(s.CreatedAt), s.CreatedAt)
+		assert.True(t, updated.Equal(s.UpdatedAt), s.UpdatedAt)
+	}
+
+	// Without times an update keeps the creation time and is stamped now.
+	err = writeRepo.SaveBatch(ctx, owner, []*models.Secret{
+		{SecretName: "note", SecretType: models.SecretTypeText, Ciphertext: []byte("newer"), AESKeyEnc: []byte("key"), KeyID: "id"},
+	})
+	require.NoError(t, err)
+	got, err := readRepo.Get(ctx, owner, models.SecretTypeText, "note")
+	require.NoError(t, err)
+	assert.True(t, created.Equal(got.CreatedAt), got.CreatedAt)
+	assert.False(t, got.UpdatedAt.Before(before.UpdatedAt), got.UpdatedAt)
+}
+
+func TestSecretWriteRepository_SaveRecipients(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewSecretWriteRepository(db)
+	readRepo := NewSecretReadRepository(db)
+
+	ctx := context.Background()
+	owner := "user1"
+
+	recipients := models.Recipients{
+		{KeyID: "laptop", AESKeyEnc: []byte("laptop-key")},
+		{KeyID: "phone", AESKeyEnc: []byte("phone-key")},
+	}
+	require.NoError(t, writeRepo.Save(ctx, owner, "shared", models.SecretTypeText, []byte("data"), []byte("laptop-key"), "laptop", recipients))
+	require.NoError(t, writeRepo.Save(ctx, owner, "single", models.SecretTypeText, []byte("data"), []byte("key"), "laptop", nil))
+
+	got, err := readRepo.Get(ctx, owner, models.SecretTypeText, "shared")
+	require.NoError(t, err)
+	assert.Equal(t, recipients, got.Recipients)
+
+	got, err = readRepo.Get(ctx, owner, models.SecretTypeText, "single")
+	require.NoError(t, err)
+	assert.Nil(t, got.Recipients)
+}
+
+func TestSecretWriteRepository_Delete(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewSecretWriteRepository(db)
+	readRepo := NewSecretReadRepository(db)
+
+	ctx := context.Background()
+	owner := "user1"
+
+	require.NoError(t, writeRepo.Save(ctx, owner, "github", models.SecretTypeUser, []byte("data"), []byte("key"), "", nil))
+	require.NoError(t, writeRepo.Save(ctx, owner, "github", models.SecretTypeText, []byte("data"), []byte("key"), "", nil))
+
+	require.NoError(t, writeRepo.Delete(ctx, owner, models.SecretTypeUser, "github"))
+	_, err := readRepo.Get(ctx, owner, models.SecretTypeUser, "github")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = readRepo.Get(ctx, owner, models.SecretTypeText, "github")
+	assert.NoError(t, err)
+
+	err = writeRepo.Delete(ctx, owner, models.SecretTypeUser, "github")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
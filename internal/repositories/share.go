@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// ShareWriteRepository handles write operations related to secret shares.
+type ShareWriteRepository struct {
+	db *sqlx.DB
+}
+
+func NewShareWriteRepository(db *sqlx.DB) *ShareWriteRepository {
+	return &ShareWriteRepository{db: db}
+}
+
+// Save inserts or updates the share of a secret with recipient.
+// Saving a stale share with the current data key makes it valid again.
+func (r *ShareWriteRepository) Save(
+	ctx context.Context,
+	secretOwner string,
+	secretType string,
+	secretName string,
+	recipient string,
+	aesKeyEnc []byte,
+	keyID string,
+	permission string,
+) error {
+	query := `
+		INSERT INTO secret_shares (secret_owner, secret_type, secret_name, recipient, aes_key_enc, key_id, permission, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(secret_owner, secret_type, secret_name, recipient) DO UPDATE SET
+			aes_key_enc = EXCLUDED.aes_key_enc,
+			key_id = EXCLUDED.key_id,
+			permission = EXCLUDED.permission,
+			stale = 0,
+			updated_at = CURRENT_TIMESTAMP;
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		secretOwner,
+		secretType,
+		secretName,
+		recipient,
+		aesKeyEnc,
+		keyID,
+		permission,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save share: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the share of a secret with recipient.
+// It fails with an error wrapping sql.ErrNoRows when there is no such share.
+func (r *ShareWriteRepository) Delete(
+	ctx context.Context,
+	secretOwner string,
+	secretType string,
+	secretName string,
+	recipient string,
+) error {
+	query := `
+		DELETE FROM secret_shares
+		WHERE secret_owner = $1 AND secret_type = $2 AND secret_name = $3 AND recipient = $4;
+	`
+	res, err := r.db.ExecContext(ctx, query, secretOwner, secretType, secretName, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to delete share: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete share: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("failed to delete share: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// ShareReadRepository handles read operations related to secret shares.
+type ShareReadRepository struct {
+	db *sqlx.DB
+}
+
+func NewShareReadRepository(db *sqlx.DB) *ShareReadRepository {
+	return &ShareReadRepository{db: db}
+}
+
+// Get fetches the share of a secret with recipient.
+func (r *ShareReadRepository) Get(
+	ctx context.Context,
+	secretOwner string,
+	secretType string,
+	secretName string,
+	recipient string,
+) (*models.SecretShare, error) {
+	query := `
+		SELECT secret_owner, secret_type, secret_name, recipient, aes_key_enc, key_id, permission, stale, created_at, updated_at
+		FROM secret_shares
+		WHERE secret_owner = $1 AND secret_type = $2 AND secret_name = $3 AND recipient = $4
+	`
+
+	var share models.SecretShare
+	err := r.db.GetContext(ctx, &share, query, secretOwner, secretType, secretName, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get share: %w", err)
+	}
+	return &share, nil
+}
+
+// ListByRecipient fetches all secrets shared with recipient,
+// each carrying the data key wrapped for the recipient. Stale shares are left out,
+// their wrapped key no longer opens the secret.
+func (r *ShareReadRepository) ListByRecipient(
+	ctx context.Context,
+	recipient string,
+) ([]*models.SharedSecret, error) {
+	query := `
+		SELECT s.secret_name, s.secret_type, s.secret_owner, s.ciphertext,
+			sh.aes_key_enc, sh.key_id, sh.permission, s.created_at, s.updated_at
+		FROM secret_shares sh
+		JOIN secrets s
+			ON s.secret_owner = sh.secret_owner AND s.secret_type = sh.secret_type AND s.secret_name = sh.secret_name
+		WHERE sh.recipient = $1 AND NOT sh.stale
+		ORDER BY s.secret_owner, s.secret_type, s.secret_name
+	`
+
+	var secrets []*models.SharedSecret
+	err := r.db.SelectContext(ctx, &secrets, query, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared secrets: %w", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+func TestShareRepository_SaveGetDelete(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewShareWriteRepository(db)
+	readRepo := NewShareReadRepository(db)
+
+	ctx := context.Background()
+
+	require.NoError(t, writeRepo.Save(ctx, "alice", models.SecretTypeText, "wifi", "bob", []byte("bob-key"), "bob-id", models.SharePermissionRead))
+
+	got, err := readRepo.Get(ctx, "alice", models.SecretTypeText, "wifi", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bob-key"), got.AESKeyEnc)
+	assert.Equal(t, "bob-id", got.KeyID)
+	assert.Equal(t, models.SharePermissionRead, got.Permission)
+
+	// Sharing again updates the permission and the wrapped key.
+	require.NoError(t, writeRepo.Save(ctx, "alice", models.SecretTypeText, "wifi", "bob", []byte("bob-key-2"), "bob-id-2", models.SharePermissionReadWrite))
+
+	got, err = readRepo.Get(ctx, "alice", models.SecretTypeText, "wifi", "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bob-key-2"), got.AESKeyEnc)
+	assert.Equal(t, models.SharePermissionReadWrite, got.Permission)
+
+	require.NoError(t, writeRepo.Delete(ctx, "alice", models.SecretTypeText, "wifi", "bob"))
+
+	_, err = readRepo.Get(ctx, "alice", models.SecretTypeText, "wifi", "bob")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	err = writeRepo.Delete(ctx, "alice", models.SecretTypeText, "wifi", "bob")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestShareReadRepository_ListByRecipient(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	secretRepo := NewSecretWriteRepository(db)
+	writeRepo := NewShareWriteRepository(db)
+	readRepo := NewShareReadRepository(db)
+
+	ctx := context.Background()
+
+	require.NoError(t, secretRepo.Save(ctx, "alice", "wifi", models.SecretTypeText, []byte("wifi-ct"), []byte("alice-key"), "alice-id", nil))
+	require.NoError(t, secretRepo.Save(ctx, "alice", "mail", models.SecretTypeUser, []byte("mail-ct"), []byte("alice-key"), "alice-id", nil))
+	require.NoError(t, writeRepo.Save(ctx, "alice", models.SecretTypeText, "wifi", "bob", []byte("bob-key"), "bob-id", models.SharePermissionRead))
+	require.NoError(t, writeRepo.Save(ctx, "alice", models.SecretTypeUser, "mail", "carol", []byte("carol-key"), "carol-id", models.SharePermissionRead))
+
+	secrets, err := readRepo.ListByRecipient(ctx, "bob")
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "alice", secrets[0].SecretOwner)
+	assert.Equal(t, "wifi", secrets[0].SecretName)
+	assert.Equal(t, []byte("wifi-ct"), secrets[0].Ciphertext)
+	assert.Equal(t, []byte("bob-key"), secrets[0].AESKeyEnc)
+	assert.Equal(t, "bob-id", secrets[0].KeyID)
+	assert.Equal(t, models.SharePermissionRead, secrets[0].Permission)
+
+	// A read-write recipient replaces the content, every wrapped key stays valid.
+	require.NoError(t, secretRepo.UpdateCiphertext(ctx, "alice", models.SecretTypeText, "wifi", []byte("wifi-ct-2")))
+	secrets, err = readRepo.ListByRecipient(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("wifi-ct-2"), secrets[0].Ciphertext)
+
+	err = secretRepo.UpdateCiphertext(ctx, "alice", models.SecretTypeText, "missing", []byte("x"))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	secrets, err = readRepo.ListByRecipient(ctx, "dave")
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+}
+
+func TestShareRepository_Stale(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	secretRepo := NewSecretWriteRepository(db)
+	writeRepo := NewShareWriteRepository(db)
+	readRepo := NewShareReadRepository(db)
+
+	ctx := context.Background()
+
+	require.NoError(t, secretRepo.Save(ctx, "alice", "wifi", models.SecretTypeText, []byte("ct"), []byte("alice-key"), "alice-id", nil))
+	require.NoError(t, writeRepo.Save(ctx, "alice", models.SecretTypeText, "wifi", "bob", []byte("bob-key"), "bob-id", models.SharePermissionReadWrite))
+
+	// Saving the secret with the same data key keeps the share.
+	require.NoError(t, secretRepo.Save(ctx, "alice", "wifi", models.SecretTypeText, []byte("ct-2"), []byte("alice-key"), "alice-id", nil))
+	share, err := readRepo.Get(ctx, "alice", models.SecretTypeText, "wifi", "bob")
+	require.NoError(t, err)
+	assert.False(t, share.Stale)
+
+	// A new data key leaves the wrapped key of bob useless.
+	require.NoError(t, secretRepo.SaveBatch(ctx, "alice", []*models.Secret{
+		{SecretName: "wifi", SecretType: models.SecretTypeText, Ciphertext: []byte("ct-3"), AESKeyEnc: []byte("alice-key-2"), KeyID: "alice-id"},
+	}))
+	share, err = readRepo.Get(ctx, "alice", models.SecretTypeText, "wifi", "bob")
+	require.NoError(t, err)
+	assert.True(t, share.Stale)
+
+	secrets, err := readRepo.ListByRecipient(ctx, "bob")
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+
+	// Sharing again with the new data key makes the share valid.
+	require.NoError(t, writeRepo.Save(ctx, "alice", models.SecretTypeText, "wifi", "bob", []byte("bob-key-2"), "bob-id", models.SharePermissionReadWrite))
+	secrets, err = readRepo.ListByRecipient(ctx, "bob")
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, []byte("bob-key-2"), secrets[0].AESKeyEnc)
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
@@ -18,21 +19,161 @@ func NewUserWriteRepository(db *sqlx.DB) *UserWriteRepository {
 }
 
 // Save inserts or updates a user record.
-func (r *UserWriteRepository) Save(ctx context.Context, username, passwordHash string) error {
+// kdfParams is nil unless the user registers in master-password mode. A new user only accepts
+// tokens issued from now on, so that tokens of a deleted account of the same name stay revoked.
+func (r *UserWriteRepository) Save(ctx context.Context, username, passwordHash string, kdfParams *models.KDFParams) error {
 	query := `
-		INSERT INTO users (username, password_hash, created_at, updated_at)
-		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO users (username, password_hash, kdf_params, tokens_valid_after, created_at, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		ON CONFLICT(username) DO UPDATE SET
 			password_hash = EXCLUDED.password_hash,
+			kdf_params = EXCLUDED.kdf_params,
 			updated_at = CURRENT_TIMESTAMP;
 	`
-	_, err := r.db.ExecContext(ctx, query, username, passwordHash)
+	_, err := r.db.ExecContext(ctx, query, username, passwordHash, kdfParams)
 	if err != nil {
 		return fmt.Errorf("failed to save user: %w", err)
 	}
 	return nil
 }
 
+// SaveOTP updates the two-factor authentication state of an existing user.
+func (r *UserWriteRepository) SaveOTP(
+	ctx context.Context,
+	username string,
+	secretEnc []byte,
+	recoveryCodes string,
+	enabled bool,
+) error {
+	query := `
+		UPDATE users SET
+			otp_secret_enc = $2,
+			otp_recovery_codes = $3,
+			otp_enabled = $4,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE username = $1;
+	`
+	_, err := r.db.ExecContext(ctx, query, username, secretEnc, recoveryCodes, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to save user otp: %w", err)
+	}
+	return nil
+}
+
+// UseOTPStep records step as the last TOTP time step accepted for the user. It reports false,
+// recording nothing, when a step at or after it was accepted before, so a code cannot be replayed
+// while it is still inside the clock drift window.
+func (r *UserWriteRepository) UseOTPStep(ctx context.Context, username string, step int64) (bool, error) {
+	query := `
+		UPDATE users SET
+			otp_last_step = $2
+		WHERE username = $1 AND otp_last_step < $2;
+	`
+	res, err := r.db.ExecContext(ctx, query, username, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to use otp step: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use otp step: %w", err)
+	}
+	return n == 1, nil
+}
+
+// UseRecoveryCode replaces the recovery code hashes of the user with newCodes when they are
+// still oldCodes. It reports false, changing nothing, when they were changed in the meantime,
+// so a recovery code cannot be used by two concurrent logins.
+func (r *UserWriteRepository) UseRecoveryCode(ctx context.Context, username, oldCodes, newCodes string) (bool, error) {
+	query := `
+		UPDATE users SET
+			otp_recovery_codes = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE username = $1 AND otp_recovery_codes = $2;
+	`
+	res, err := r.db.ExecContext(ctx, query, username, oldCodes, newCodes)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return n == 1, nil
+}
+
+// UpdatePassword replaces the password hash of an existing user and revokes
+// every token issued before tokensValidAfter.
+func (r *UserWriteRepository) UpdatePassword(
+	ctx context.Context,
+	username string,
+	passwordHash string,
+	tokensValidAfter time.Time,
+) error {
+	query := `
+		UPDATE users SET
+			password_hash = $2,
+			tokens_valid_after = $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE username = $1;
+	`
+	_, err := r.db.ExecContext(ctx, query, username, passwordHash, tokensValidAfter.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to update user password: %w", err)
+	}
+	return nil
+}
+
+// SetCertificate stores the public certificate other users encrypt shared secrets to.
+func (r *UserWriteRepository) SetCertificate(ctx context.Context, username, certificate string) error {
+	query := `
+		UPDATE users SET
+			certificate = $2,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE username = $1;
+	`
+	_, err := r.db.ExecContext(ctx, query, username, certificate)
+	if err != nil {
+		return fmt.Errorf("failed to set user certificate: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a user together with their audit log.
+// Secrets and shares are removed by the ON DELETE CASCADE foreign keys, which SQLite enforces
+// only when foreign_keys is enabled on the connection, so the deletion runs on a
+// dedicated connection with the pragma switched on, and off again before the connection
+// goes back to the pool.
+func (r *UserWriteRepository) Delete(ctx context.Context, username string) error {
+	conn, err := r.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = ON;`); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `PRAGMA foreign_keys = OFF;`)
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM audit_events WHERE username = $1;`, username); err != nil {
+		return fmt.Errorf("failed to delete user audit events: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE username = $1;`, username); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	return nil
+}
+
 // UserReadRepository handles read operations for users.
 type UserReadRepository struct {
 	db *sqlx.DB
@@ -45,7 +186,7 @@ func NewUserReadRepository(db *sqlx.DB) *UserReadRepository {
 // Get fetches a user by username.
 func (r *UserReadRepository) Get(ctx context.Context, username string) (*models.User, error) {
 	query := `
-		SELECT username, password_hash, created_at, updated_at
+		SELECT username, password_hash, otp_secret_enc, otp_enabled, otp_recovery_codes, tokens_valid_after, certificate, kdf_params, created_at, updated_at
 		FROM users
 		WHERE username = $1;
 	`
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	_ "modernc.org/sqlite"
@@ -19,6 +20,13 @@ func setupUserTestDB(t *testing.T) *sqlx.DB {
 	CREATE TABLE users (
 		username TEXT PRIMARY KEY,
 		password_hash TEXT NOT NULL,
+		otp_secret_enc BLOB,
+		otp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+		otp_recovery_codes TEXT NOT NULL DEFAULT '',
+		otp_last_step INTEGER NOT NULL DEFAULT 0,
+		tokens_valid_after DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00',
+		certificate TEXT NOT NULL DEFAULT '',
+		kdf_params TEXT,
 		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 		updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 	);
@@ -41,7 +49,7 @@ func TestUserWriteRepository_SaveAndGet(t *testing.T) {
 	passwordHash := "hash123"
 
 	// Save new user
-	err := writeRepo.Save(ctx, username, passwordHash)
+	err := writeRepo.Save(ctx, username, passwordHash, nil)
 	require.NoError(t, err)
 
 	// Get user
@@ -49,6 +57,7 @@ func TestUserWriteRepository_SaveAndGet(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, username, got.Username)
 	assert.Equal(t, passwordHash, got.PasswordHash)
+	assert.WithinDuration(t, time.Now(), got.TokensValidAfter, 2*time.Second, "tokens of an earlier account of the name stay revoked")
 
 	// Capture time before update
 	timeBeforeUpdate := time.Now()
@@ -56,7 +65,7 @@ func TestUserWriteRepository_SaveAndGet(t *testing.T) {
 
 	// Update password hash
 	newPasswordHash := "updated-hash456"
-	err = writeRepo.Save(ctx, username, newPasswordHash)
+	err = writeRepo.Save(ctx, username, newPasswordHash, nil)
 	require.NoError(t, err)
 
 	// Get updated user
@@ -65,3 +74,207 @@ func TestUserWriteRepository_SaveAndGet(t *testing.T) {
 	assert.Equal(t, newPasswordHash, updated.PasswordHash)
 	assert.True(t, updated.UpdatedAt.After(timeBeforeUpdate) || updated.UpdatedAt.Equal(timeBeforeUpdate))
 }
+
+func TestUserWriteRepository_SaveKDFParams(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewUserWriteRepository(db)
+	readRepo := NewUserReadRepository(db)
+	ctx := context.Background()
+
+	params := &models.KDFParams{Algorithm: models.KDFAlgorithmArgon2id, Salt: []byte("0123456789abcdef"), Time: 3, Memory: 65536, Threads: 4}
+	require.NoError(t, writeRepo.Save(ctx, "alice", "hash", params))
+	require.NoError(t, writeRepo.Save(ctx, "bob", "hash", nil))
+
+	alice, err := readRepo.Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, params, alice.KDFParams)
+
+	bob, err := readRepo.Get(ctx, "bob")
+	require.NoError(t, err)
+	assert.Nil(t, bob.KDFParams)
+}
+
+func TestUserWriteRepository_SaveOTP(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewUserWriteRepository(db)
+	readRepo := NewUserReadRepository(db)
+
+	ctx := context.Background()
+	username := "otpuser"
+
+	require.NoError(t, writeRepo.Save(ctx, username, "hash", nil))
+
+	// New users have two-factor authentication disabled
+	got, err := readRepo.Get(ctx, username)
+	require.NoError(t, err)
+	assert.False(t, got.OTPEnabled)
+	assert.Empty(t, got.OTPSecretEnc)
+	assert.Empty(t, got.OTPRecoveryCodes)
+
+	// Enroll and enable
+	err = writeRepo.SaveOTP(ctx, username, []byte("sealed-seed"), "h1\nh2", true)
+	require.NoError(t, err)
+
+	got, err = readRepo.Get(ctx, username)
+	require.NoError(t, err)
+	assert.True(t, got.OTPEnabled)
+	assert.Equal(t, []byte("sealed-seed"), got.OTPSecretEnc)
+	assert.Equal(t, "h1\nh2", got.OTPRecoveryCodes)
+	assert.Equal(t, "hash", got.PasswordHash)
+}
+
+func TestUserWriteRepository_UseOTPStep(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewUserWriteRepository(db)
+	ctx := context.Background()
+	require.NoError(t, writeRepo.Save(ctx, "otpuser", "hash", nil))
+
+	fresh, err := writeRepo.UseOTPStep(ctx, "otpuser", 100)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	for _, step := range []int64{100, 99} {
+		fresh, err = writeRepo.UseOTPStep(ctx, "otpuser", step)
+		require.NoError(t, err)
+		assert.False(t, fresh, "step %d was already used", step)
+	}
+
+	fresh, err = writeRepo.UseOTPStep(ctx, "otpuser", 101)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+}
+
+func TestUserWriteRepository_UseRecoveryCode(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewUserWriteRepository(db)
+	readRepo := NewUserReadRepository(db)
+	ctx := context.Background()
+	require.NoError(t, writeRepo.Save(ctx, "otpuser", "hash", nil))
+	require.NoError(t, writeRepo.SaveOTP(ctx, "otpuser", []byte("seed"), "a\nb", true))
+
+	used, err := writeRepo.UseRecoveryCode(ctx, "otpuser", "a\nb", "b")
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	// A concurrent login read the codes before they changed.
+	used, err = writeRepo.UseRecoveryCode(ctx, "otpuser", "a\nb", "a")
+	require.NoError(t, err)
+	assert.False(t, used)
+
+	got, err := readRepo.Get(ctx, "otpuser")
+	require.NoError(t, err)
+	assert.Equal(t, "b", got.OTPRecoveryCodes)
+}
+
+func TestUserWriteRepository_UpdatePassword(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewUserWriteRepository(db)
+	readRepo := NewUserReadRepository(db)
+
+	ctx := context.Background()
+	username := "pwuser"
+
+	require.NoError(t, writeRepo.Save(ctx, username, "old-hash", nil))
+
+	got, err := readRepo.Get(ctx, username)
+	require.NoError(t, err)
+	assert.True(t, got.TokensValidAfter.Equal(got.CreatedAt))
+
+	// Tokens carry sub-second issue times, so the revocation time keeps them.
+	validAfter := time.Date(2025, 8, 4, 9, 0, 0, 123456000, time.UTC)
+	require.NoError(t, writeRepo.UpdatePassword(ctx, username, "new-hash", validAfter))
+
+	got, err = readRepo.Get(ctx, username)
+	require.NoError(t, err)
+	assert.Equal(t, "new-hash", got.PasswordHash)
+	assert.True(t, validAfter.Equal(got.TokensValidAfter))
+}
+
+func TestUserWriteRepository_SetCertificate(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewUserWriteRepository(db)
+	readRepo := NewUserReadRepository(db)
+
+	ctx := context.Background()
+
+	require.NoError(t, writeRepo.Save(ctx, "alice", "hash", nil))
+
+	got, err := readRepo.Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, got.Certificate)
+
+	require.NoError(t, writeRepo.SetCertificate(ctx, "alice", "-----BEGIN CERTIFICATE-----"))
+
+	got, err = readRepo.Get(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "-----BEGIN CERTIFICATE-----", got.Certificate)
+}
+
+func TestUserWriteRepository_Delete(t *testing.T) {
+	db := setupUserTestDB(t)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err := db.Exec(`
+	CREATE TABLE secrets (
+		secret_name TEXT NOT NULL,
+		secret_type TEXT NOT NULL,
+		secret_owner TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+		PRIMARY KEY (secret_name, secret_type, secret_owner)
+	);
+	CREATE TABLE audit_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT NOT NULL,
+		action TEXT NOT NULL
+	);
+	`)
+	require.NoError(t, err)
+
+	writeRepo := NewUserWriteRepository(db)
+	readRepo := NewUserReadRepository(db)
+
+	ctx := context.Background()
+
+	require.NoError(t, writeRepo.Save(ctx, "alice", "hash", nil))
+	require.NoError(t, writeRepo.Save(ctx, "bob", "hash", nil))
+
+	_, err = db.Exec(`
+	INSERT INTO secrets (secret_name, secret_type, secret_owner) VALUES
+		('mail', 'user', 'alice'), ('card', 'bankcard', 'alice'), ('mail', 'user', 'bob');
+	INSERT INTO audit_events (username, action) VALUES ('alice', 'auth.login'), ('bob', 'auth.login');
+	`)
+	require.NoError(t, err)
+
+	require.NoError(t, writeRepo.Delete(ctx, "alice"))
+
+	_, err = readRepo.Get(ctx, "alice")
+	assert.Error(t, err)
+
+	var secrets, events int
+	require.NoError(t, db.Get(&secrets, `SELECT COUNT(*) FROM secrets WHERE secret_owner = 'alice'`))
+	require.NoError(t, db.Get(&events, `SELECT COUNT(*) FROM audit_events WHERE username = 'alice'`))
+	assert.Zero(t, secrets)
+	assert.Zero(t, events)
+
+	require.NoError(t, db.Get(&secrets, `SELECT COUNT(*) FROM secrets WHERE secret_owner = 'bob'`))
+	require.NoError(t, db.Get(&events, `SELECT COUNT(*) FROM audit_events WHERE username = 'bob'`))
+	assert.Equal(t, 1, secrets)
+	assert.Equal(t, 1, events)
+
+	// The only pooled connection is back to the default.
+	var foreignKeys int
+	require.NoError(t, db.Get(&foreignKeys, `PRAGMA foreign_keys`))
+	assert.Zero(t, foreignKeys)
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// VaultSecretWriteRepository handles write operations related to secrets of organization vaults.
+type VaultSecretWriteRepository struct {
+	db *sqlx.DB
+}
+
+func NewVaultSecretWriteRepository(db *sqlx.DB) *VaultSecretWriteRepository {
+	return &VaultSecretWriteRepository{db: db}
+}
+
+// Save inserts or updates a secret of a vault, recording updatedBy as its last writer.
+func (r *VaultSecretWriteRepository) Save(
+	ctx context.Context,
+	organization string,
+	vault string,
+	secretName string,
+	secretType string,
+	ciphertext []byte,
+	aesKeyEnc []byte,
+	keyID string,
+	recipients models.Recipients,
+	updatedBy string,
+) error {
+	query := `
+		INSERT INTO vault_secrets (organization, vault, secret_name, secret_type, ciphertext, aes_key_enc, key_id, recipients, updated_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(organization, vault, secret_type, secret_name) DO UPDATE SET
+			ciphertext = EXCLUDED.ciphertext,
+			aes_key_enc = EXCLUDED.aes_key_enc,
+			key_id = EXCLUDED.key_id,
+			recipients = EXCLUDED.recipients,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = CURRENT_TIMESTAMP;
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		organization,
+		vault,
+		secretName,
+		secretType,
+		ciphertext,
+		aesKeyEnc,
+		keyID,
+		recipients,
+		updatedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save vault secret: %w", err)
+	}
+	return nil
+}
+
+// VaultSecretReadRepository handles read operations related to secrets of organization vaults.
+type VaultSecretReadRepository struct {
+	db *sqlx.DB
+}
+
+func NewVaultSecretReadRepository(db *sqlx.DB) *VaultSecretReadRepository {
+	return &VaultSecretReadRepository{db: db}
+}
+
+// selectVaultSecrets reads vault secrets as models.Secret: the organization
+// is reported as the owner and the vault is addressed as "organization/vault".
+const selectVaultSecrets = `
+	SELECT secret_name, secret_type, organization AS secret_owner, ciphertext, aes_key_enc, key_id, recipients,
+		organization || '/' || vault AS vault, created_at, updated_at
+	FROM vault_secrets
+`
+
+// Get fetches a secret of a vault by type and name.
+func (r *VaultSecretReadRepository) Get(
+	ctx context.Context,
+	organization string,
+	vault string,
+	secretType string,
+	secretName string,
+) (*models.Secret, error) {
+	query := selectVaultSecrets + `
+		WHERE organization = $1 AND vault = $2 AND secret_type = $3 AND secret_name = $4
+	`
+
+	var secret models.Secret
+	if err := r.db.GetContext(ctx, &secret, query, organization, vault, secretType, secretName); err != nil {
+		return nil, fmt.Errorf("failed to get vault secret: %w", err)
+	}
+	return &secret, nil
+}
+
+// List fetches all secrets of a vault.
+func (r *VaultSecretReadRepository) List(
+	ctx context.Context,
+	organization string,
+	vault string,
+) ([]*models.Secret, error) {
+	query := selectVaultSecrets + `
+		WHERE organization = $1 AND vault = $2
+		ORDER BY secret_type, secret_name
+	`
+
+	var secrets []*models.Secret
+	if err := r.db.SelectContext(ctx, &secrets, query, organization, vault); err != nil {
+		return nil, fmt.Errorf("failed to list vault secrets: %w", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+func TestVaultSecretRepository_SaveGetList(t *testing.T) {
+	db := setupOrganizationTestDB(t)
+	defer db.Close()
+
+	writeRepo := NewVaultSecretWriteRepository(db)
+	readRepo := NewVaultSecretReadRepository(db)
+	ctx := context.Background()
+
+	recipients := models.Recipients{{KeyID: "a", AESKeyEnc: []byte("ka")}, {KeyID: "b", AESKeyEnc: []byte("kb")}}
+
+	require.NoError(t, writeRepo.Save(ctx, "acme", "ops", "db", models.SecretTypeUser, []byte("c1"), []byte("k1"), "a", recipients, "alice"))
+	require.NoError(t, writeRepo.Save(ctx, "acme", "ops", "note", models.SecretTypeText, []byte("c2"), []byte("k2"), "a", nil, "alice"))
+	require.NoError(t, writeRepo.Save(ctx, "acme", "dev", "db", models.SecretTypeUser, []byte("c3"), []byte("k3"), "a", nil, "alice"))
+
+	// A second member overwrites the same secret instead of creating their own copy.
+	require.NoError(t, writeRepo.Save(ctx, "acme", "ops", "db", models.SecretTypeUser, []byte("c1b"), []byte("k1"), "a", recipients, "bob"))
+
+	got, err := readRepo.Get(ctx, "acme", "ops", models.SecretTypeUser, "db")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("c1b"), got.Ciphertext)
+	assert.Equal(t, "acme", got.SecretOwner)
+	assert.Equal(t, "acme/ops", got.Vault)
+	assert.Equal(t, recipients, got.Recipients)
+
+	secrets, err := readRepo.List(ctx, "acme", "ops")
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, models.SecretTypeText, secrets[0].SecretType)
+	assert.Equal(t, models.SecretTypeUser, secrets[1].SecretType)
+}
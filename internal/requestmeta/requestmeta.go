@@ -0,0 +1,39 @@
+package requestmeta
+
+import "context"
+
+const (
+	// TransportHTTP marks requests served by the HTTP API.
+	TransportHTTP = "http"
+	// TransportGRPC marks requests served by the gRPC API.
+	TransportGRPC = "grpc"
+)
+
+type ctxKey int
+
+const (
+	transportKey ctxKey = iota
+	clientIPKey
+)
+
+// WithTransport returns a copy of ctx carrying the transport the request came in on.
+func WithTransport(ctx context.Context, transport string) context.Context {
+	return context.WithValue(ctx, transportKey, transport)
+}
+
+// Transport returns the transport stored in ctx, or an empty string.
+func Transport(ctx context.Context) string {
+	v, _ := ctx.Value(transportKey).(string)
+	return v
+}
+
+// WithClientIP returns a copy of ctx carrying the client IP address.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIP returns the client IP stored in ctx, or an empty string.
+func ClientIP(ctx context.Context) string {
+	v, _ := ctx.Value(clientIPKey).(string)
+	return v
+}
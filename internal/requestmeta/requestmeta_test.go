@@ -0,0 +1,20 @@
+package requestmeta
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMeta(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, Transport(ctx))
+	assert.Empty(t, ClientIP(ctx))
+
+	ctx = WithTransport(ctx, TransportGRPC)
+	ctx = WithClientIP(ctx, "192.0.2.1")
+
+	assert.Equal(t, TransportGRPC, Transport(ctx))
+	assert.Equal(t, "192.0.2.1", ClientIP(ctx))
+}
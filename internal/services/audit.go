@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/requestmeta"
+)
+
+// AuditSaver persists audit events.
+type AuditSaver interface {
+	Save(ctx context.Context, event *models.AuditEvent) error
+}
+
+// AuditGetter reads audit events.
+type AuditGetter interface {
+	List(ctx context.Context, username string, limit int) ([]*models.AuditEvent, error)
+}
+
+// Auditor records security relevant actions of a user.
+type Auditor interface {
+	Record(ctx context.Context, username, action, secretKey string)
+}
+
+const (
+	// DefaultAuditLimit is the number of audit events returned when no limit is given.
+	DefaultAuditLimit = 100
+	// MaxAuditLimit caps the number of audit events returned at once.
+	MaxAuditLimit = 1000
+)
+
+// AuditService records and lists audit events.
+type AuditService struct {
+	saver  AuditSaver
+	getter AuditGetter
+}
+
+// NewAuditService creates a new instance of AuditService.
+func NewAuditService(saver AuditSaver, getter AuditGetter) *AuditService {
+	return &AuditService{saver: saver, getter: getter}
+}
+
+// Record stores an audit event, taking the transport and client IP from ctx.
+// A failure to store the event is logged and does not interrupt the audited operation.
+func (s *AuditService) Record(ctx context.Context, username, action, secretKey string) {
+	event := &models.AuditEvent{
+		Username:  username,
+		Action:    action,
+		SecretKey: secretKey,
+		Transport: requestmeta.Transport(ctx),
+		ClientIP:  requestmeta.ClientIP(ctx),
+	}
+	if err := s.saver.Save(ctx, event); err != nil {
+		log.Printf("failed to record audit event %s for %q: %v", action, username, err)
+	}
+}
+
+// List returns the newest audit events of a user.
+// A non-positive limit selects DefaultAuditLimit, larger values are capped at MaxAuditLimit.
+// The lookup itself is recorded as well.
+func (s *AuditService) List(ctx context.Context, username string, limit int) ([]*models.AuditEvent, error) {
+	switch {
+	case limit <= 0:
+		limit = DefaultAuditLimit
+	case limit > MaxAuditLimit:
+		limit = MaxAuditLimit
+	}
+
+	events, err := s.getter.List(ctx, username, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Record(ctx, username, models.AuditActionAuditList, "")
+
+	return events, nil
+}
+
+// secretKey formats the audit key of a secret.
+func secretKey(secretType, secretName string) string {
+	return secretType + "/" + secretName
+}
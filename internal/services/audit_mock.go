@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/services/audit.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockAuditSaver is a mock of AuditSaver interface.
+type MockAuditSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditSaverMockRecorder
+}
+
+// MockAuditSaverMockRecorder is the mock recorder for MockAuditSaver.
+type MockAuditSaverMockRecorder struct {
+	mock *MockAuditSaver
+}
+
+// NewMockAuditSaver creates a new mock instance.
+func NewMockAuditSaver(ctrl *gomock.Controller) *MockAuditSaver {
+	mock := &MockAuditSaver{ctrl: ctrl}
+	mock.recorder = &MockAuditSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditSaver) EXPECT() *MockAuditSaverMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method.
+func (m *MockAuditSaver) Save(ctx context.Context, event *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockAuditSaverMockRecorder) Save(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockAuditSaver)(nil).Save), ctx, event)
+}
+
+// MockAuditGetter is a mock of AuditGetter interface.
+type MockAuditGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditGetterMockRecorder
+}
+
+// MockAuditGetterMockRecorder is the mock recorder for MockAuditGetter.
+type MockAuditGetterMockRecorder struct {
+	mock *MockAuditGetter
+}
+
+// NewMockAuditGetter creates a new mock instance.
+func NewMockAuditGetter(ctrl *gomock.Controller) *MockAuditGetter {
+	mock := &MockAuditGetter{ctrl: ctrl}
+	mock.recorder = &MockAuditGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditGetter) EXPECT() *MockAuditGetterMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAuditGetter) List(ctx context.Context, username string, limit int) ([]*models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, username, limit)
+	ret0, _ := ret[0].([]*models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditGetterMockRecorder) List(ctx, username, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditGetter)(nil).List), ctx, username, limit)
+}
+
+// MockAuditor is a mock of Auditor interface.
+type MockAuditor struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditorMockRecorder
+}
+
+// MockAuditorMockRecorder is the mock recorder for MockAuditor.
+type MockAuditorMockRecorder struct {
+	mock *MockAuditor
+}
+
+// NewMockAuditor creates a new mock instance.
+func NewMockAuditor(ctrl *gomock.Controller) *MockAuditor {
+	mock := &MockAuditor{ctrl: ctrl}
+	mock.recorder = &MockAuditorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditor) EXPECT() *MockAuditorMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditor) Record(ctx context.Context, username, action, secretKey string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", ctx, username, action, secretKey)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditorMockRecorder) Record(ctx, username, action, secretKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditor)(nil).Record), ctx, username, action, secretKey)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/requestmeta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditService_Record(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaver := NewMockAuditSaver(ctrl)
+	service := NewAuditService(mockSaver, nil)
+
+	ctx := requestmeta.WithTransport(context.Background(), requestmeta.TransportHTTP)
+	ctx = requestmeta.WithClientIP(ctx, "192.0.2.1")
+
+	mockSaver.EXPECT().Save(ctx, &models.AuditEvent{
+		Username:  "alice",
+		Action:    models.AuditActionSecretGet,
+		SecretKey: "user/mail",
+		Transport: requestmeta.TransportHTTP,
+		ClientIP:  "192.0.2.1",
+	}).Return(nil)
+
+	service.Record(ctx, "alice", models.AuditActionSecretGet, "user/mail")
+
+	// Storage errors do not propagate.
+	mockSaver.EXPECT().Save(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+	service.Record(context.Background(), "alice", models.AuditActionSecretList, "")
+}
+
+func TestAuditService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaver := NewMockAuditSaver(ctrl)
+	mockGetter := NewMockAuditGetter(ctrl)
+	service := NewAuditService(mockSaver, mockGetter)
+
+	ctx := context.Background()
+	events := []*models.AuditEvent{{ID: 1, Username: "alice", Action: models.AuditActionLogin}}
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantLimit int
+		getErr    error
+	}{
+		{name: "default limit", limit: 0, wantLimit: DefaultAuditLimit},
+		{name: "custom limit", limit: 10, wantLimit: 10},
+		{name: "capped limit", limit: MaxAuditLimit + 1, wantLimit: MaxAuditLimit},
+		{name: "getter error", limit: 10, wantLimit: 10, getErr: errors.New("db error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.getErr != nil {
+				mockGetter.EXPECT().List(ctx, "alice", tt.wantLimit).Return(nil, tt.getErr)
+			} else {
+				mockGetter.EXPECT().List(ctx, "alice", tt.wantLimit).Return(events, nil)
+				mockSaver.EXPECT().Save(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, ev *models.AuditEvent) error {
+						assert.Equal(t, models.AuditActionAuditList, ev.Action)
+						return nil
+					})
+			}
+
+			got, err := service.List(ctx, "alice", tt.limit)
+			if tt.getErr != nil {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, events, got)
+		})
+	}
+}
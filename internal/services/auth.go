@@ -2,16 +2,26 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/otp"
+	"github.com/sbilibin2017/gophkeeper/internal/requestmeta"
 )
 
 // Dependencies needed by the service
 type UserSaver interface {
-	Save(ctx context.Context, username, passwordHash string) error
+	Save(ctx context.Context, username, passwordHash string, kdfParams *models.KDFParams) error
 }
 
 type UserGetter interface {
@@ -22,25 +32,149 @@ type JWTGenerator interface {
 	Generate(username string) (string, error)
 }
 
+// UserOTPSaver persists the two-factor authentication state of a user.
+type UserOTPSaver interface {
+	SaveOTP(ctx context.Context, username string, secretEnc []byte, recoveryCodes string, enabled bool) error
+	// UseOTPStep records the time step of an accepted code, reporting false when it is not
+	// after the last one recorded.
+	UseOTPStep(ctx context.Context, username string, step int64) (bool, error)
+	// UseRecoveryCode replaces the recovery code hashes oldCodes with newCodes, reporting false
+	// when they are no longer oldCodes.
+	UseRecoveryCode(ctx context.Context, username, oldCodes, newCodes string) (bool, error)
+}
+
+// UserAccountWriter changes credentials of and removes existing users.
+type UserAccountWriter interface {
+	UpdatePassword(ctx context.Context, username, passwordHash string, tokensValidAfter time.Time) error
+	Delete(ctx context.Context, username string) error
+}
+
+// MembershipLister lists organization memberships, to keep account deletion from orphaning an organization.
+type MembershipLister interface {
+	ListByUser(ctx context.Context, username string) ([]*models.Membership, error)
+	ListMembers(ctx context.Context, organization string) ([]*models.Membership, error)
+}
+
+// CertificateSaver stores the public certificate of a user.
+type CertificateSaver interface {
+	SetCertificate(ctx context.Context, username, certificate string) error
+}
+
+// SeedSealer encrypts and decrypts TOTP seeds stored in the database.
+type SeedSealer interface {
+	Seal(plaintext []byte) ([]byte, error)
+	Open(sealed []byte) ([]byte, error)
+}
+
 var (
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrInvalidData       = errors.New("invalid username or password")
+	ErrUserAlreadyExists    = errors.New("user already exists")
+	ErrInvalidData          = errors.New("invalid username or password")
+	ErrInvalidOTP           = errors.New("invalid one-time code")
+	ErrOTPNotEnrolled       = errors.New("two-factor authentication is not enrolled")
+	ErrOTPAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrOTPNotConfigured     = errors.New("two-factor authentication is not configured on the server")
+	ErrInvalidPassword      = errors.New("invalid password")
+	ErrAccountNotConfigured = errors.New("account management is not configured on the server")
+	ErrUserNotFound         = errors.New("user not found")
+	ErrInvalidCertificate   = errors.New("invalid certificate")
+	ErrNoCertificate        = errors.New("user has not published a certificate")
+	ErrCertNotConfigured    = errors.New("certificate publishing is not configured on the server")
+	ErrInvalidKDFParams     = errors.New("invalid KDF parameters")
+	ErrNoKDFParams          = errors.New("master password is not set up for this user")
 )
 
+// recoveryCodesPerEnroll is the number of recovery codes issued on enrolment.
+const recoveryCodesPerEnroll = 10
+
 type AuthService struct {
 	users UserGetter
 	saver UserSaver
+
+	otpSaver  UserOTPSaver
+	otpSealer SeedSealer
+	otpIssuer string
+
+	accounts    UserAccountWriter
+	memberships MembershipLister
+
+	certificates CertificateSaver
+
+	kdfDecoyKey []byte
+
+	auditor Auditor
+}
+
+// AuthOpt defines a functional option for configuring an AuthService.
+type AuthOpt func(*AuthService)
+
+// WithOTP enables TOTP two-factor authentication, storing seeds sealed by sealer
+// and labelling authenticator entries with issuer.
+func WithOTP(saver UserOTPSaver, sealer SeedSealer, issuer string) AuthOpt {
+	return func(s *AuthService) {
+		s.otpSaver = saver
+		s.otpSealer = sealer
+		s.otpIssuer = issuer
+	}
+}
+
+// WithAccounts enables password changes and account deletion through writer.
+func WithAccounts(writer UserAccountWriter) AuthOpt {
+	return func(s *AuthService) {
+		s.accounts = writer
+	}
 }
 
-func NewAuthService(users UserGetter, saver UserSaver) *AuthService {
-	return &AuthService{
+// WithOrganizations makes account deletion fail while the user is the only owner of an organization.
+func WithOrganizations(memberships MembershipLister) AuthOpt {
+	return func(s *AuthService) {
+		s.memberships = memberships
+	}
+}
+
+// WithKDFDecoy makes GetKDFParams answer for unknown users and users without a master password
+// with parameters derived from key and the username, so the answer does not tell whether an
+// account exists or uses a master password.
+func WithKDFDecoy(key []byte) AuthOpt {
+	return func(s *AuthService) {
+		s.kdfDecoyKey = key
+	}
+}
+
+// WithCertificates enables publishing of user certificates through saver.
+func WithCertificates(saver CertificateSaver) AuthOpt {
+	return func(s *AuthService) {
+		s.certificates = saver
+	}
+}
+
+// WithAuthAuditor records registrations, logins and two-factor changes with auditor.
+func WithAuthAuditor(auditor Auditor) AuthOpt {
+	return func(s *AuthService) {
+		s.auditor = auditor
+	}
+}
+
+func NewAuthService(users UserGetter, saver UserSaver, opts ...AuthOpt) *AuthService {
+	s := &AuthService{
 		users: users,
 		saver: saver,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Register a new user and return JWT token
-func (s *AuthService) Register(ctx context.Context, username, password string) error {
+// Register a new user and return JWT token.
+// Users registering in master-password mode pass the parameters their master password
+// is stretched with and, as the password, the authentication key derived from it.
+func (s *AuthService) Register(ctx context.Context, username, password string, kdfParams *models.KDFParams) error {
+	if kdfParams != nil {
+		if err := cryptor.ValidateKDFParams(*kdfParams); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidKDFParams, err)
+		}
+	}
+
 	existingUser, err := s.users.Get(ctx, username)
 	if err != nil {
 		return err
@@ -54,23 +188,353 @@ func (s *AuthService) Register(ctx context.Context, username, password string) e
 		return err
 	}
 
-	if err := s.saver.Save(ctx, username, string(hashedPassword)); err != nil {
+	if err := s.saver.Save(ctx, username, string(hashedPassword), kdfParams); err != nil {
 		return err
 	}
 
+	s.audit(ctx, username, models.AuditActionRegister)
+
 	return nil
 }
 
-// Authenticate verifies credentials and returns JWT token
+// Authenticate verifies credentials and returns JWT token.
+// Failed logins of existing users are audited; those of unknown usernames are only logged,
+// so that nobody who registers the name later inherits them.
 func (s *AuthService) Authenticate(ctx context.Context, username, password string) error {
 	user, err := s.users.Get(ctx, username)
 	if err != nil || user == nil {
+		log.Printf("failed login for unknown user %q from %s", username, requestmeta.ClientIP(ctx))
 		return ErrInvalidData
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		s.audit(ctx, username, models.AuditActionLoginFailed)
 		return ErrInvalidData
 	}
 
 	return nil
 }
+
+// EnrollOTP generates a new TOTP seed and recovery codes for the user.
+// Two-factor authentication stays disabled until the first code is confirmed with ConfirmOTP.
+func (s *AuthService) EnrollOTP(ctx context.Context, username string) (*models.OTPEnrollment, error) {
+	if s.otpSaver == nil || s.otpSealer == nil {
+		return nil, ErrOTPNotConfigured
+	}
+
+	user, err := s.users.Get(ctx, username)
+	if err != nil || user == nil {
+		return nil, ErrInvalidData
+	}
+	if user.OTPEnabled {
+		return nil, ErrOTPAlreadyEnabled
+	}
+
+	secret, err := otp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	secretEnc, err := s.otpSealer.Seal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := otp.GenerateRecoveryCodes(recoveryCodesPerEnroll)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = otp.HashRecoveryCode(c)
+	}
+
+	if err := s.otpSaver.SaveOTP(ctx, username, secretEnc, strings.Join(hashes, "\n"), false); err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, username, models.AuditActionOTPEnroll)
+
+	return &models.OTPEnrollment{
+		URI:           otp.URI(s.otpIssuer, username, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// ConfirmOTP enables two-factor authentication once the user proves possession of the seed.
+func (s *AuthService) ConfirmOTP(ctx context.Context, username, code string) error {
+	if s.otpSaver == nil || s.otpSealer == nil {
+		return ErrOTPNotConfigured
+	}
+
+	user, err := s.users.Get(ctx, username)
+	if err != nil || user == nil {
+		return ErrInvalidData
+	}
+	if len(user.OTPSecretEnc) == 0 {
+		return ErrOTPNotEnrolled
+	}
+	if user.OTPEnabled {
+		return ErrOTPAlreadyEnabled
+	}
+
+	secret, err := s.otpSealer.Open(user.OTPSecretEnc)
+	if err != nil {
+		return err
+	}
+	step, ok := otp.Match(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidOTP
+	}
+	// The confirming code cannot be used again to log in.
+	if _, err := s.otpSaver.UseOTPStep(ctx, username, step); err != nil {
+		return err
+	}
+
+	if err := s.otpSaver.SaveOTP(ctx, username, user.OTPSecretEnc, user.OTPRecoveryCodes, true); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionOTPConfirm)
+
+	return nil
+}
+
+// VerifyOTP is the second login step. It succeeds immediately for users without
+// two-factor authentication, otherwise it accepts a current TOTP code not used before or an
+// unused recovery code, which is consumed. A successful call completes the login and is audited as such.
+func (s *AuthService) VerifyOTP(ctx context.Context, username, code string) error {
+	user, err := s.users.Get(ctx, username)
+	if err != nil || user == nil {
+		return ErrInvalidData
+	}
+	if !user.OTPEnabled {
+		s.audit(ctx, username, models.AuditActionLogin)
+		return nil
+	}
+	if code == "" {
+		return models.ErrOTPRequired
+	}
+	if s.otpSaver == nil || s.otpSealer == nil {
+		return ErrOTPNotConfigured
+	}
+
+	secret, err := s.otpSealer.Open(user.OTPSecretEnc)
+	if err != nil {
+		return err
+	}
+	if step, ok := otp.Match(secret, code, time.Now()); ok {
+		fresh, err := s.otpSaver.UseOTPStep(ctx, username, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			s.audit(ctx, username, models.AuditActionOTPFailed)
+			return ErrInvalidOTP
+		}
+		s.audit(ctx, username, models.AuditActionLogin)
+		return nil
+	}
+
+	hash := otp.HashRecoveryCode(code)
+	var remaining []string
+	found := false
+	for _, h := range strings.Split(user.OTPRecoveryCodes, "\n") {
+		if h == "" {
+			continue
+		}
+		if !found && h == hash {
+			found = true
+			continue
+		}
+		remaining = append(remaining, h)
+	}
+	if !found {
+		s.audit(ctx, username, models.AuditActionOTPFailed)
+		return ErrInvalidOTP
+	}
+
+	used, err := s.otpSaver.UseRecoveryCode(ctx, username, user.OTPRecoveryCodes, strings.Join(remaining, "\n"))
+	if err != nil {
+		return err
+	}
+	if !used {
+		s.audit(ctx, username, models.AuditActionOTPFailed)
+		return ErrInvalidOTP
+	}
+
+	s.audit(ctx, username, models.AuditActionRecoveryCode)
+	s.audit(ctx, username, models.AuditActionLogin)
+
+	return nil
+}
+
+// ChangePassword replaces the password of the user after verifying the current one.
+// All tokens issued before the change are revoked, so other sessions have to log in again.
+func (s *AuthService) ChangePassword(ctx context.Context, username, oldPassword, newPassword string) error {
+	if s.accounts == nil {
+		return ErrAccountNotConfigured
+	}
+
+	if err := s.checkPassword(ctx, username, oldPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	// Token issue times have microsecond precision, so tokens issued earlier in the same
+	// second are revoked while the token issued right after the change is accepted.
+	validAfter := time.Now().Truncate(time.Microsecond)
+	if err := s.accounts.UpdatePassword(ctx, username, string(hashedPassword), validAfter); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionPasswordChange)
+
+	return nil
+}
+
+// DeleteAccount removes the user together with all their secrets after verifying the password.
+// It fails with ErrLastOwner while the user is the only owner of an organization.
+func (s *AuthService) DeleteAccount(ctx context.Context, username, password string) error {
+	if s.accounts == nil {
+		return ErrAccountNotConfigured
+	}
+
+	if err := s.checkPassword(ctx, username, password); err != nil {
+		return err
+	}
+	if err := s.keepOrganizationOwners(ctx, username); err != nil {
+		return err
+	}
+
+	return s.accounts.Delete(ctx, username)
+}
+
+// keepOrganizationOwners fails with ErrLastOwner when username is the only owner of an organization,
+// which deleting the account would leave without anyone able to manage it.
+func (s *AuthService) keepOrganizationOwners(ctx context.Context, username string) error {
+	if s.memberships == nil {
+		return nil
+	}
+
+	memberships, err := s.memberships.ListByUser(ctx, username)
+	if err != nil {
+		return err
+	}
+	for _, m := range memberships {
+		if m.Role != models.RoleOwner {
+			continue
+		}
+		members, err := s.memberships.ListMembers(ctx, m.Organization)
+		if err != nil {
+			return err
+		}
+		owners := 0
+		for _, member := range members {
+			if member.Role == models.RoleOwner {
+				owners++
+			}
+		}
+		if owners < 2 {
+			return fmt.Errorf("%w: make another member owner of %s or delete it first", ErrLastOwner, m.Organization)
+		}
+	}
+	return nil
+}
+
+// PublishCertificate stores the PEM certificate other users encrypt secrets shared with username to.
+// Publishing again replaces the certificate; shares wrapped for the old one stay readable only with its key.
+func (s *AuthService) PublishCertificate(ctx context.Context, username, certificate string) error {
+	if s.certificates == nil {
+		return ErrCertNotConfigured
+	}
+
+	if _, err := cryptor.CertificateKeyID([]byte(certificate)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCertificate, err)
+	}
+
+	if err := s.certificates.SetCertificate(ctx, username, certificate); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionCertPublish)
+
+	return nil
+}
+
+// GetCertificate returns the published certificate of username.
+func (s *AuthService) GetCertificate(ctx context.Context, username string) (string, error) {
+	user, err := s.users.Get(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user == nil) {
+		return "", ErrUserNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if user.Certificate == "" {
+		return "", ErrNoCertificate
+	}
+	return user.Certificate, nil
+}
+
+// GetKDFParams returns the parameters the master password of username is stretched with.
+// Unknown users and users without a master password get the same decoy parameters every time
+// when WithKDFDecoy is set, and ErrNoKDFParams otherwise.
+func (s *AuthService) GetKDFParams(ctx context.Context, username string) (*models.KDFParams, error) {
+	user, err := s.users.Get(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (user == nil || user.KDFParams == nil)) {
+		if s.kdfDecoyKey != nil {
+			return s.decoyKDFParams(username), nil
+		}
+		return nil, ErrNoKDFParams
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user.KDFParams, nil
+}
+
+// decoyKDFParams returns the default KDF parameters with a salt derived from username,
+// which look like those of a real master password.
+func (s *AuthService) decoyKDFParams(username string) *models.KDFParams {
+	mac := hmac.New(sha256.New, s.kdfDecoyKey)
+	mac.Write([]byte("gophkeeper kdf decoy\x00" + username))
+	return &models.KDFParams{
+		Algorithm: models.KDFAlgorithmArgon2id,
+		Salt:      mac.Sum(nil)[:16],
+		Time:      cryptor.DefaultKDFTime,
+		Memory:    cryptor.DefaultKDFMemory,
+		Threads:   cryptor.DefaultKDFThreads,
+	}
+}
+
+// TokensValidAfter returns the moment before which all tokens of the user are revoked.
+func (s *AuthService) TokensValidAfter(ctx context.Context, username string) (time.Time, error) {
+	user, err := s.users.Get(ctx, username)
+	if err != nil || user == nil {
+		return time.Time{}, ErrInvalidData
+	}
+	return user.TokensValidAfter, nil
+}
+
+// checkPassword verifies the current password of an authenticated user.
+func (s *AuthService) checkPassword(ctx context.Context, username, password string) error {
+	user, err := s.users.Get(ctx, username)
+	if err != nil || user == nil {
+		return ErrInvalidData
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// audit records an account event when an auditor is configured.
+func (s *AuthService) audit(ctx context.Context, username, action string) {
+	if s.auditor != nil {
+		s.auditor.Record(ctx, username, action, "")
+	}
+}
@@ -7,6 +7,7 @@ package services
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/sbilibin2017/gophkeeper/internal/models"
@@ -36,17 +37,17 @@ func (m *MockUserSaver) EXPECT() *MockUserSaverMockRecorder {
 }
 
 // Save mocks base method.
-func (m *MockUserSaver) Save(ctx context.Context, username, passwordHash string) error {
+func (m *MockUserSaver) Save(ctx context.Context, username, passwordHash string, kdfParams *models.KDFParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, username, passwordHash)
+	ret := m.ctrl.Call(m, "Save", ctx, username, passwordHash, kdfParams)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockUserSaverMockRecorder) Save(ctx, username, passwordHash interface{}) *gomock.Call {
+func (mr *MockUserSaverMockRecorder) Save(ctx, username, passwordHash, kdfParams interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockUserSaver)(nil).Save), ctx, username, passwordHash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockUserSaver)(nil).Save), ctx, username, passwordHash, kdfParams)
 }
 
 // MockUserGetter is a mock of UserGetter interface.
@@ -124,3 +125,264 @@ func (mr *MockJWTGeneratorMockRecorder) Generate(username interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockJWTGenerator)(nil).Generate), username)
 }
+
+// MockUserOTPSaver is a mock of UserOTPSaver interface.
+type MockUserOTPSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserOTPSaverMockRecorder
+}
+
+// MockUserOTPSaverMockRecorder is the mock recorder for MockUserOTPSaver.
+type MockUserOTPSaverMockRecorder struct {
+	mock *MockUserOTPSaver
+}
+
+// NewMockUserOTPSaver creates a new mock instance.
+func NewMockUserOTPSaver(ctrl *gomock.Controller) *MockUserOTPSaver {
+	mock := &MockUserOTPSaver{ctrl: ctrl}
+	mock.recorder = &MockUserOTPSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserOTPSaver) EXPECT() *MockUserOTPSaverMockRecorder {
+	return m.recorder
+}
+
+// SaveOTP mocks base method.
+func (m *MockUserOTPSaver) SaveOTP(ctx context.Context, username string, secretEnc []byte, recoveryCodes string, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOTP", ctx, username, secretEnc, recoveryCodes, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOTP indicates an expected call of SaveOTP.
+func (mr *MockUserOTPSaverMockRecorder) SaveOTP(ctx, username, secretEnc, recoveryCodes, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOTP", reflect.TypeOf((*MockUserOTPSaver)(nil).SaveOTP), ctx, username, secretEnc, recoveryCodes, enabled)
+}
+
+// UseOTPStep mocks base method.
+func (m *MockUserOTPSaver) UseOTPStep(ctx context.Context, username string, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseOTPStep", ctx, username, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseOTPStep indicates an expected call of UseOTPStep.
+func (mr *MockUserOTPSaverMockRecorder) UseOTPStep(ctx, username, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseOTPStep", reflect.TypeOf((*MockUserOTPSaver)(nil).UseOTPStep), ctx, username, step)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockUserOTPSaver) UseRecoveryCode(ctx context.Context, username, oldCodes, newCodes string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, username, oldCodes, newCodes)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockUserOTPSaverMockRecorder) UseRecoveryCode(ctx, username, oldCodes, newCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockUserOTPSaver)(nil).UseRecoveryCode), ctx, username, oldCodes, newCodes)
+}
+
+// MockUserAccountWriter is a mock of UserAccountWriter interface.
+type MockUserAccountWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserAccountWriterMockRecorder
+}
+
+// MockUserAccountWriterMockRecorder is the mock recorder for MockUserAccountWriter.
+type MockUserAccountWriterMockRecorder struct {
+	mock *MockUserAccountWriter
+}
+
+// NewMockUserAccountWriter creates a new mock instance.
+func NewMockUserAccountWriter(ctrl *gomock.Controller) *MockUserAccountWriter {
+	mock := &MockUserAccountWriter{ctrl: ctrl}
+	mock.recorder = &MockUserAccountWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserAccountWriter) EXPECT() *MockUserAccountWriterMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockUserAccountWriter) Delete(ctx context.Context, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUserAccountWriterMockRecorder) Delete(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserAccountWriter)(nil).Delete), ctx, username)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserAccountWriter) UpdatePassword(ctx context.Context, username, passwordHash string, tokensValidAfter time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, username, passwordHash, tokensValidAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserAccountWriterMockRecorder) UpdatePassword(ctx, username, passwordHash, tokensValidAfter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserAccountWriter)(nil).UpdatePassword), ctx, username, passwordHash, tokensValidAfter)
+}
+
+// MockMembershipLister is a mock of MembershipLister interface.
+type MockMembershipLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockMembershipListerMockRecorder
+}
+
+// MockMembershipListerMockRecorder is the mock recorder for MockMembershipLister.
+type MockMembershipListerMockRecorder struct {
+	mock *MockMembershipLister
+}
+
+// NewMockMembershipLister creates a new mock instance.
+func NewMockMembershipLister(ctrl *gomock.Controller) *MockMembershipLister {
+	mock := &MockMembershipLister{ctrl: ctrl}
+	mock.recorder = &MockMembershipListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMembershipLister) EXPECT() *MockMembershipListerMockRecorder {
+	return m.recorder
+}
+
+// ListByUser mocks base method.
+func (m *MockMembershipLister) ListByUser(ctx context.Context, username string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, username)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockMembershipListerMockRecorder) ListByUser(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockMembershipLister)(nil).ListByUser), ctx, username)
+}
+
+// ListMembers mocks base method.
+func (m *MockMembershipLister) ListMembers(ctx context.Context, organization string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, organization)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockMembershipListerMockRecorder) ListMembers(ctx, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockMembershipLister)(nil).ListMembers), ctx, organization)
+}
+
+// MockCertificateSaver is a mock of CertificateSaver interface.
+type MockCertificateSaver struct {
+	ctrl     *gomock.Controller
+	recorder *MockCertificateSaverMockRecorder
+}
+
+// MockCertificateSaverMockRecorder is the mock recorder for MockCertificateSaver.
+type MockCertificateSaverMockRecorder struct {
+	mock *MockCertificateSaver
+}
+
+// NewMockCertificateSaver creates a new mock instance.
+func NewMockCertificateSaver(ctrl *gomock.Controller) *MockCertificateSaver {
+	mock := &MockCertificateSaver{ctrl: ctrl}
+	mock.recorder = &MockCertificateSaverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCertificateSaver) EXPECT() *MockCertificateSaverMockRecorder {
+	return m.recorder
+}
+
+// SetCertificate mocks base method.
+func (m *MockCertificateSaver) SetCertificate(ctx context.Context, username, certificate string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCertificate", ctx, username, certificate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetCertificate indicates an expected call of SetCertificate.
+func (mr *MockCertificateSaverMockRecorder) SetCertificate(ctx, username, certificate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCertificate", reflect.TypeOf((*MockCertificateSaver)(nil).SetCertificate), ctx, username, certificate)
+}
+
+// MockSeedSealer is a mock of SeedSealer interface.
+type MockSeedSealer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSeedSealerMockRecorder
+}
+
+// MockSeedSealerMockRecorder is the mock recorder for MockSeedSealer.
+type MockSeedSealerMockRecorder struct {
+	mock *MockSeedSealer
+}
+
+// NewMockSeedSealer creates a new mock instance.
+func NewMockSeedSealer(ctrl *gomock.Controller) *MockSeedSealer {
+	mock := &MockSeedSealer{ctrl: ctrl}
+	mock.recorder = &MockSeedSealerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSeedSealer) EXPECT() *MockSeedSealerMockRecorder {
+	return m.recorder
+}
+
+// Open mocks base method.
+func (m *MockSeedSealer) Open(sealed []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", sealed)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockSeedSealerMockRecorder) Open(sealed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockSeedSealer)(nil).Open), sealed)
+}
+
+// Seal mocks base method.
+func (m *MockSeedSealer) Seal(plaintext []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seal", plaintext)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Seal indicates an expected call of Seal.
+func (mr *MockSeedSealerMockRecorder) Seal(plaintext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seal", reflect.TypeOf((*MockSeedSealer)(nil).Seal), plaintext)
+}
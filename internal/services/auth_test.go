@@ -2,12 +2,19 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
 	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/otp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -71,10 +78,10 @@ func TestAuthService_Register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			mockUserGetter.EXPECT().Get(gomock.Any(), tt.username).Return(tt.getUserReturn, tt.getUserErr)
 			if tt.getUserReturn == nil && tt.getUserErr == nil {
-				mockUserSaver.EXPECT().Save(gomock.Any(), tt.username, gomock.Any()).Return(tt.saveErr)
+				mockUserSaver.EXPECT().Save(gomock.Any(), tt.username, gomock.Any(), nil).Return(tt.saveErr)
 			}
 
-			err := service.Register(context.Background(), tt.username, tt.password)
+			err := service.Register(context.Background(), tt.username, tt.password, nil)
 			if tt.expectErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, tt.expectErr.Error())
@@ -152,3 +159,411 @@ func TestAuthService_Authenticate(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthService_EnrollAndConfirmOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockOTPSaver := NewMockUserOTPSaver(ctrl)
+	sealer := otp.NewSealer("server-secret")
+
+	service := NewAuthService(mockUserGetter, nil, WithOTP(mockOTPSaver, sealer, "GophKeeper"))
+	ctx := context.Background()
+
+	// Enroll
+	var stored *models.User
+	mockUserGetter.EXPECT().Get(gomock.Any(), "alice").Return(&models.User{Username: "alice"}, nil)
+	mockOTPSaver.EXPECT().
+		SaveOTP(gomock.Any(), "alice", gomock.Any(), gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, username string, secretEnc []byte, codes string, enabled bool) error {
+			stored = &models.User{Username: username, OTPSecretEnc: secretEnc, OTPRecoveryCodes: codes, OTPEnabled: enabled}
+			return nil
+		})
+
+	enrollment, err := service.EnrollOTP(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/GophKeeper:alice?"))
+	assert.Len(t, enrollment.RecoveryCodes, recoveryCodesPerEnroll)
+	require.NotNil(t, stored)
+
+	seed, err := sealer.Open(stored.OTPSecretEnc)
+	require.NoError(t, err)
+
+	// Confirm with a wrong code
+	mockUserGetter.EXPECT().Get(gomock.Any(), "alice").Return(stored, nil)
+	err = service.ConfirmOTP(ctx, "alice", "000000x")
+	assert.ErrorIs(t, err, ErrInvalidOTP)
+
+	// Confirm with a valid code
+	mockUserGetter.EXPECT().Get(gomock.Any(), "alice").Return(stored, nil)
+	mockOTPSaver.EXPECT().UseOTPStep(gomock.Any(), "alice", gomock.Any()).Return(true, nil)
+	mockOTPSaver.EXPECT().SaveOTP(gomock.Any(), "alice", stored.OTPSecretEnc, stored.OTPRecoveryCodes, true).Return(nil)
+	err = service.ConfirmOTP(ctx, "alice", otp.Code(seed, time.Now()))
+	require.NoError(t, err)
+
+	// Enrolling again is rejected once enabled
+	mockUserGetter.EXPECT().Get(gomock.Any(), "alice").Return(&models.User{Username: "alice", OTPEnabled: true}, nil)
+	_, err = service.EnrollOTP(ctx, "alice")
+	assert.ErrorIs(t, err, ErrOTPAlreadyEnabled)
+
+	// Confirm without enrolment
+	mockUserGetter.EXPECT().Get(gomock.Any(), "bob").Return(&models.User{Username: "bob"}, nil)
+	err = service.ConfirmOTP(ctx, "bob", "123456")
+	assert.ErrorIs(t, err, ErrOTPNotEnrolled)
+}
+
+func TestAuthService_EnrollOTP_NotConfigured(t *testing.T) {
+	service := NewAuthService(nil, nil)
+
+	_, err := service.EnrollOTP(context.Background(), "alice")
+	assert.ErrorIs(t, err, ErrOTPNotConfigured)
+}
+
+func TestAuthService_VerifyOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockOTPSaver := NewMockUserOTPSaver(ctrl)
+	sealer := otp.NewSealer("server-secret")
+
+	service := NewAuthService(mockUserGetter, nil, WithOTP(mockOTPSaver, sealer, "GophKeeper"))
+
+	seed := []byte("12345678901234567890")
+	seedEnc, err := sealer.Seal(seed)
+	require.NoError(t, err)
+
+	recovery := "abcde-12345"
+	codes := otp.HashRecoveryCode("other-00000") + "\n" + otp.HashRecoveryCode(recovery)
+
+	enabledUser := &models.User{Username: "alice", OTPEnabled: true, OTPSecretEnc: seedEnc, OTPRecoveryCodes: codes}
+
+	now := time.Now()
+	step := now.Unix() / int64(otp.Period.Seconds())
+
+	tests := []struct {
+		name      string
+		user      *models.User
+		code      string
+		useCode   bool
+		raced     bool
+		useStep   bool
+		replayed  bool
+		expectErr error
+	}{
+		{name: "otp disabled", user: &models.User{Username: "alice"}, code: ""},
+		{name: "code required", user: enabledUser, code: "", expectErr: models.ErrOTPRequired},
+		{name: "valid totp", user: enabledUser, code: otp.Code(seed, now), useStep: true},
+		{name: "replayed totp", user: enabledUser, code: otp.Code(seed, now), useStep: true, replayed: true, expectErr: ErrInvalidOTP},
+		{name: "invalid code", user: enabledUser, code: "99999x", expectErr: ErrInvalidOTP},
+		{name: "recovery code consumed", user: enabledUser, code: recovery, useCode: true},
+		{name: "recovery code used concurrently", user: enabledUser, code: recovery, useCode: true, raced: true, expectErr: ErrInvalidOTP},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserGetter.EXPECT().Get(gomock.Any(), "alice").Return(tt.user, nil)
+			if tt.useStep {
+				mockOTPSaver.EXPECT().UseOTPStep(gomock.Any(), "alice", step).Return(!tt.replayed, nil)
+			}
+			if tt.useCode {
+				mockOTPSaver.EXPECT().
+					UseRecoveryCode(gomock.Any(), "alice", codes, otp.HashRecoveryCode("other-00000")).
+					Return(!tt.raced, nil)
+			}
+
+			err := service.VerifyOTP(context.Background(), "alice", tt.code)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthService_Audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockUserSaver := NewMockUserSaver(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewAuthService(mockUserGetter, mockUserSaver, WithAuthAuditor(mockAuditor))
+
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{Username: "alice", PasswordHash: string(hashedPassword)}
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(nil, nil)
+	mockUserSaver.EXPECT().Save(ctx, "alice", gomock.Any(), nil).Return(nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionRegister, "")
+	require.NoError(t, service.Register(ctx, "alice", "pass", nil))
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionLoginFailed, "")
+	require.ErrorIs(t, service.Authenticate(ctx, "alice", "wrong"), ErrInvalidData)
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+	require.NoError(t, service.Authenticate(ctx, "alice", "pass"))
+
+	// Unknown usernames get no audit log
+	mockUserGetter.EXPECT().Get(ctx, "mallory").Return(nil, nil)
+	require.ErrorIs(t, service.Authenticate(ctx, "mallory", "pass"), ErrInvalidData)
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionLogin, "")
+	require.NoError(t, service.VerifyOTP(ctx, "alice", ""))
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockAccounts := NewMockUserAccountWriter(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewAuthService(mockUserGetter, nil, WithAccounts(mockAccounts), WithAuthAuditor(mockAuditor))
+
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("old-pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{Username: "alice", PasswordHash: string(hashedPassword)}
+
+	t.Run("wrong old password", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+		err := service.ChangePassword(ctx, "alice", "wrong", "new-pass")
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "bob").Return(nil, errors.New("not found"))
+		err := service.ChangePassword(ctx, "bob", "old-pass", "new-pass")
+		assert.ErrorIs(t, err, ErrInvalidData)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		before := time.Now().Truncate(time.Microsecond)
+		mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+		mockAccounts.EXPECT().
+			UpdatePassword(ctx, "alice", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, hash string, validAfter time.Time) error {
+				assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-pass")))
+				assert.False(t, validAfter.Before(before))
+				assert.False(t, validAfter.After(time.Now()))
+				return nil
+			})
+		mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionPasswordChange, "")
+
+		require.NoError(t, service.ChangePassword(ctx, "alice", "old-pass", "new-pass"))
+	})
+
+	t.Run("update fails", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+		mockAccounts.EXPECT().UpdatePassword(ctx, "alice", gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+
+		assert.EqualError(t, service.ChangePassword(ctx, "alice", "old-pass", "new-pass"), "db error")
+	})
+}
+
+func TestAuthService_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockAccounts := NewMockUserAccountWriter(ctrl)
+	service := NewAuthService(mockUserGetter, nil, WithAccounts(mockAccounts))
+
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{Username: "alice", PasswordHash: string(hashedPassword)}
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+	assert.ErrorIs(t, service.DeleteAccount(ctx, "alice", "wrong"), ErrInvalidPassword)
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+	mockAccounts.EXPECT().Delete(ctx, "alice").Return(nil)
+	require.NoError(t, service.DeleteAccount(ctx, "alice", "pass"))
+}
+
+func TestAuthService_DeleteAccountOrganizationOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockAccounts := NewMockUserAccountWriter(ctrl)
+	mockMemberships := NewMockMembershipLister(ctrl)
+	service := NewAuthService(mockUserGetter, nil, WithAccounts(mockAccounts), WithOrganizations(mockMemberships))
+
+	ctx := context.Background()
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{Username: "alice", PasswordHash: string(hashedPassword)}
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+	mockMemberships.EXPECT().ListByUser(ctx, "alice").Return([]*models.Membership{
+		{Organization: "acme", Username: "alice", Role: models.RoleOwner},
+	}, nil)
+	mockMemberships.EXPECT().ListMembers(ctx, "acme").Return([]*models.Membership{
+		{Organization: "acme", Username: "alice", Role: models.RoleOwner},
+		{Organization: "acme", Username: "bob", Role: models.RoleAdmin},
+	}, nil)
+	err = service.DeleteAccount(ctx, "alice", "pass")
+	assert.ErrorIs(t, err, ErrLastOwner)
+	assert.ErrorContains(t, err, "acme")
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(user, nil)
+	mockMemberships.EXPECT().ListByUser(ctx, "alice").Return([]*models.Membership{
+		{Organization: "acme", Username: "alice", Role: models.RoleOwner},
+		{Organization: "other", Username: "alice", Role: models.RoleMember},
+	}, nil)
+	mockMemberships.EXPECT().ListMembers(ctx, "acme").Return([]*models.Membership{
+		{Organization: "acme", Username: "alice", Role: models.RoleOwner},
+		{Organization: "acme", Username: "bob", Role: models.RoleOwner},
+	}, nil)
+	mockAccounts.EXPECT().Delete(ctx, "alice").Return(nil)
+	require.NoError(t, service.DeleteAccount(ctx, "alice", "pass"))
+}
+
+func TestAuthService_AccountNotConfigured(t *testing.T) {
+	service := NewAuthService(nil, nil)
+
+	assert.ErrorIs(t, service.ChangePassword(context.Background(), "alice", "a", "b"), ErrAccountNotConfigured)
+	assert.ErrorIs(t, service.DeleteAccount(context.Background(), "alice", "a"), ErrAccountNotConfigured)
+}
+
+func TestAuthService_TokensValidAfter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	service := NewAuthService(mockUserGetter, nil)
+
+	ctx := context.Background()
+	validAfter := time.Date(2025, 8, 4, 9, 0, 0, 0, time.UTC)
+
+	mockUserGetter.EXPECT().Get(ctx, "alice").Return(&models.User{Username: "alice", TokensValidAfter: validAfter}, nil)
+	got, err := service.TokensValidAfter(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, validAfter, got)
+
+	mockUserGetter.EXPECT().Get(ctx, "bob").Return(nil, nil)
+	_, err = service.TokensValidAfter(ctx, "bob")
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestAuthService_Certificates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockCerts := NewMockCertificateSaver(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewAuthService(mockUserGetter, nil, WithCertificates(mockCerts), WithAuthAuditor(mockAuditor))
+
+	ctx := context.Background()
+	cert, _ := testCertificatePEM(t)
+
+	t.Run("publish", func(t *testing.T) {
+		mockCerts.EXPECT().SetCertificate(ctx, "alice", cert).Return(nil)
+		mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionCertPublish, "")
+		require.NoError(t, service.PublishCertificate(ctx, "alice", cert))
+	})
+
+	t.Run("publish invalid certificate", func(t *testing.T) {
+		assert.ErrorIs(t, service.PublishCertificate(ctx, "alice", "garbage"), ErrInvalidCertificate)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "alice").Return(&models.User{Username: "alice", Certificate: cert}, nil)
+		got, err := service.GetCertificate(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, cert, got)
+	})
+
+	t.Run("get not published", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+		_, err := service.GetCertificate(ctx, "bob")
+		assert.ErrorIs(t, err, ErrNoCertificate)
+	})
+
+	t.Run("get unknown user", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "carol").Return(nil, fmt.Errorf("failed to get user: %w", sql.ErrNoRows))
+		_, err := service.GetCertificate(ctx, "carol")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		err := NewAuthService(nil, nil).PublishCertificate(ctx, "alice", cert)
+		assert.ErrorIs(t, err, ErrCertNotConfigured)
+	})
+}
+
+func TestAuthService_KDFParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserGetter := NewMockUserGetter(ctrl)
+	mockUserSaver := NewMockUserSaver(ctrl)
+	service := NewAuthService(mockUserGetter, mockUserSaver)
+
+	ctx := context.Background()
+	params := &models.KDFParams{Algorithm: models.KDFAlgorithmArgon2id, Salt: []byte("0123456789abcdef"), Time: 3, Memory: 65536, Threads: 4}
+
+	t.Run("register in master-password mode", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "alice").Return(nil, nil)
+		mockUserSaver.EXPECT().Save(ctx, "alice", gomock.Any(), params).Return(nil)
+		require.NoError(t, service.Register(ctx, "alice", "auth-key", params))
+	})
+
+	t.Run("register with weak parameters", func(t *testing.T) {
+		weak := *params
+		weak.Memory = 1024
+		assert.ErrorIs(t, service.Register(ctx, "alice", "auth-key", &weak), ErrInvalidKDFParams)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "alice").Return(&models.User{Username: "alice", KDFParams: params}, nil)
+		got, err := service.GetKDFParams(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, params, got)
+	})
+
+	t.Run("user without master password", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "bob").Return(&models.User{Username: "bob"}, nil)
+		_, err := service.GetKDFParams(ctx, "bob")
+		assert.ErrorIs(t, err, ErrNoKDFParams)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockUserGetter.EXPECT().Get(ctx, "carol").Return(nil, fmt.Errorf("failed to get user: %w", sql.ErrNoRows))
+		_, err := service.GetKDFParams(ctx, "carol")
+		assert.ErrorIs(t, err, ErrNoKDFParams)
+	})
+
+	t.Run("decoy", func(t *testing.T) {
+		decoy := NewAuthService(mockUserGetter, mockUserSaver, WithKDFDecoy([]byte("key")))
+
+		mockUserGetter.EXPECT().Get(ctx, "bob").Return(&models.User{Username: "bob"}, nil).Times(2)
+		bob, err := decoy.GetKDFParams(ctx, "bob")
+		require.NoError(t, err)
+		require.NoError(t, cryptor.ValidateKDFParams(*bob))
+		again, err := decoy.GetKDFParams(ctx, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, bob, again, "decoys are stable")
+
+		mockUserGetter.EXPECT().Get(ctx, "carol").Return(nil, fmt.Errorf("failed to get user: %w", sql.ErrNoRows))
+		carol, err := decoy.GetKDFParams(ctx, "carol")
+		require.NoError(t, err)
+		assert.NotEqual(t, bob.Salt, carol.Salt)
+		assert.Equal(t, params.Memory, carol.Memory)
+
+		mockUserGetter.EXPECT().Get(ctx, "alice").Return(&models.User{Username: "alice", KDFParams: params}, nil)
+		alice, err := decoy.GetKDFParams(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, params, alice)
+	})
+}
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// OrganizationWriter defines the interface for storing organizations, memberships and vaults.
+type OrganizationWriter interface {
+	Create(ctx context.Context, name, owner string) error
+	Delete(ctx context.Context, name string) error
+	SaveMembership(ctx context.Context, organization, username, role string) error
+	DeleteMembership(ctx context.Context, organization, username string) error
+	CreateVault(ctx context.Context, organization, name string) error
+	DeleteVault(ctx context.Context, organization, name string) error
+}
+
+// OrganizationReader defines the interface for reading organizations, memberships and vaults.
+type OrganizationReader interface {
+	Get(ctx context.Context, name string) (*models.Organization, error)
+	GetMembership(ctx context.Context, organization, username string) (*models.Membership, error)
+	ListMembers(ctx context.Context, organization string) ([]*models.Membership, error)
+	ListByUser(ctx context.Context, username string) ([]*models.Membership, error)
+	GetVault(ctx context.Context, organization, name string) (*models.Vault, error)
+	ListVaults(ctx context.Context, organization string) ([]*models.Vault, error)
+}
+
+var (
+	ErrInvalidName         = errors.New("invalid name, use 3 to 64 letters, digits, '-', '_' or '.'")
+	ErrInvalidRole         = errors.New("invalid role, expected owner, admin, member or read-only")
+	ErrInvalidVault        = errors.New("vault must be addressed as organization/vault")
+	ErrOrganizationExists  = errors.New("organization already exists")
+	ErrVaultExists         = errors.New("vault already exists")
+	ErrVaultNotFound       = errors.New("vault not found")
+	ErrMemberNotFound      = errors.New("user is not a member of the organization")
+	ErrNotMember           = errors.New("not a member of the organization")
+	ErrInsufficientRole    = errors.New("your role in the organization does not allow this")
+	ErrLastOwner           = errors.New("organization must keep at least one owner")
+	ErrVaultsNotConfigured = errors.New("organization vaults are not configured on the server")
+)
+
+// nameRe matches valid organization and vault names.
+var nameRe = regexp.MustCompile(`^[A-Za-z0-9._-]{3,64}$`)
+
+// roleRank orders roles by privilege, unknown roles rank zero.
+var roleRank = map[string]int{
+	models.RoleReadOnly: 1,
+	models.RoleMember:   2,
+	models.RoleAdmin:    3,
+	models.RoleOwner:    4,
+}
+
+// ParseVault splits a vault address "organization/vault" into its parts.
+func ParseVault(vault string) (organization, name string, err error) {
+	organization, name, ok := strings.Cut(vault, "/")
+	if !ok || !nameRe.MatchString(organization) || !nameRe.MatchString(name) {
+		return "", "", ErrInvalidVault
+	}
+	return organization, name, nil
+}
+
+// OrganizationService manages organizations, their members and vaults,
+// and authorizes access to vault secrets by membership role.
+type OrganizationService struct {
+	writer  OrganizationWriter
+	reader  OrganizationReader
+	users   UserGetter
+	auditor Auditor
+}
+
+// OrganizationOpt defines a functional option for configuring an OrganizationService.
+type OrganizationOpt func(*OrganizationService)
+
+// WithOrganizationAuditor records every change of organizations, members and vaults with auditor.
+func WithOrganizationAuditor(auditor Auditor) OrganizationOpt {
+	return func(s *OrganizationService) {
+		s.auditor = auditor
+	}
+}
+
+// NewOrganizationService creates a new instance of OrganizationService.
+func NewOrganizationService(
+	writer OrganizationWriter,
+	reader OrganizationReader,
+	users UserGetter,
+	opts ...OrganizationOpt,
+) *OrganizationService {
+	s := &OrganizationService{
+		writer: writer,
+		reader: reader,
+		users:  users,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// CreateOrganization creates an organization with username as its owner.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, username, name string) error {
+	if !nameRe.MatchString(name) {
+		return ErrInvalidName
+	}
+
+	_, err := s.reader.Get(ctx, name)
+	if err == nil {
+		return ErrOrganizationExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := s.writer.Create(ctx, name, username); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionOrgCreate, name)
+
+	return nil
+}
+
+// DeleteOrganization deletes an organization with all its vaults and secrets.
+// Only owners may delete an organization.
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, username, name string) error {
+	if _, err := s.requireRole(ctx, username, name, models.RoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.writer.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionOrgDelete, name)
+
+	return nil
+}
+
+// ListOrganizations returns the memberships of username in all organizations.
+func (s *OrganizationService) ListOrganizations(ctx context.Context, username string) ([]*models.Membership, error) {
+	return s.reader.ListByUser(ctx, username)
+}
+
+// SetMember adds member to an organization or changes their role.
+// Admins manage members and read-only users; granting, changing or revoking
+// the owner and admin roles requires an owner.
+func (s *OrganizationService) SetMember(ctx context.Context, username, organization, member, role string) error {
+	if roleRank[role] == 0 {
+		return ErrInvalidRole
+	}
+
+	actor, err := s.requireRole(ctx, username, organization, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.users.Get(ctx, member)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user == nil) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := s.membership(ctx, organization, member)
+	if err != nil && !errors.Is(err, ErrMemberNotFound) {
+		return err
+	}
+	if !canManage(actor.Role, role) || (current != nil && !canManage(actor.Role, current.Role)) {
+		return ErrInsufficientRole
+	}
+	if current != nil && current.Role == models.RoleOwner && role != models.RoleOwner {
+		if err := s.keepOwner(ctx, organization); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writer.SaveMembership(ctx, organization, member, role); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionOrgMemberSet, organization+":"+member+"="+role)
+
+	return nil
+}
+
+// RemoveMember removes member from an organization.
+// Members may always leave an organization themselves, other removals follow the rules of SetMember.
+func (s *OrganizationService) RemoveMember(ctx context.Context, username, organization, member string) error {
+	current, err := s.membership(ctx, organization, member)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) && member == username {
+			return ErrNotMember
+		}
+		if errors.Is(err, ErrMemberNotFound) {
+			if _, err := s.requireRole(ctx, username, organization, models.RoleAdmin); err != nil {
+				return err
+			}
+			return ErrMemberNotFound
+		}
+		return err
+	}
+
+	if member != username {
+		actor, err := s.requireRole(ctx, username, organization, models.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if !canManage(actor.Role, current.Role) {
+			return ErrInsufficientRole
+		}
+	}
+	if current.Role == models.RoleOwner {
+		if err := s.keepOwner(ctx, organization); err != nil {
+			return err
+		}
+	}
+
+	if err := s.writer.DeleteMembership(ctx, organization, member); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionOrgMemberDrop, organization+":"+member)
+
+	return nil
+}
+
+// ListMembers returns the members of an organization username belongs to.
+func (s *OrganizationService) ListMembers(ctx context.Context, username, organization string) ([]*models.Membership, error) {
+	if _, err := s.requireRole(ctx, username, organization, models.RoleReadOnly); err != nil {
+		return nil, err
+	}
+	return s.reader.ListMembers(ctx, organization)
+}
+
+// CreateVault creates a vault in an organization. It requires the admin role.
+func (s *OrganizationService) CreateVault(ctx context.Context, username, organization, name string) error {
+	if !nameRe.MatchString(name) {
+		return ErrInvalidName
+	}
+	if _, err := s.requireRole(ctx, username, organization, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	_, err := s.reader.GetVault(ctx, organization, name)
+	if err == nil {
+		return ErrVaultExists
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := s.writer.CreateVault(ctx, organization, name); err != nil {
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionVaultCreate, organization+"/"+name)
+
+	return nil
+}
+
+// DeleteVault deletes a vault with all its secrets. It requires the admin role.
+func (s *OrganizationService) DeleteVault(ctx context.Context, username, organization, name string) error {
+	if _, err := s.requireRole(ctx, username, organization, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := s.writer.DeleteVault(ctx, organization, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrVaultNotFound
+		}
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionVaultDelete, organization+"/"+name)
+
+	return nil
+}
+
+// ListVaults returns the vaults of an organization username belongs to.
+func (s *OrganizationService) ListVaults(ctx context.Context, username, organization string) ([]*models.Vault, error) {
+	if _, err := s.requireRole(ctx, username, organization, models.RoleReadOnly); err != nil {
+		return nil, err
+	}
+	return s.reader.ListVaults(ctx, organization)
+}
+
+// AuthorizeVault checks that username may access the secrets of vault, addressed as
+// "organization/vault", and returns its parts. Every member may read, writing
+// requires at least the member role.
+func (s *OrganizationService) AuthorizeVault(
+	ctx context.Context,
+	username, vault string,
+	write bool,
+) (organization, name string, err error) {
+	organization, name, err = ParseVault(vault)
+	if err != nil {
+		return "", "", err
+	}
+
+	minRole := models.RoleReadOnly
+	if write {
+		minRole = models.RoleMember
+	}
+	if _, err := s.requireRole(ctx, username, organization, minRole); err != nil {
+		return "", "", err
+	}
+
+	if _, err := s.reader.GetVault(ctx, organization, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", ErrVaultNotFound
+		}
+		return "", "", err
+	}
+
+	return organization, name, nil
+}
+
+// requireRole returns the membership of username in organization
+// when it grants at least minRole.
+func (s *OrganizationService) requireRole(ctx context.Context, username, organization, minRole string) (*models.Membership, error) {
+	m, err := s.membership(ctx, organization, username)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return nil, ErrNotMember
+		}
+		return nil, err
+	}
+	if roleRank[m.Role] < roleRank[minRole] {
+		return nil, ErrInsufficientRole
+	}
+	return m, nil
+}
+
+// membership returns the membership of username in organization or ErrMemberNotFound.
+func (s *OrganizationService) membership(ctx context.Context, organization, username string) (*models.Membership, error) {
+	m, err := s.reader.GetMembership(ctx, organization, username)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && m == nil) {
+		return nil, ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// keepOwner fails with ErrLastOwner unless organization has more than one owner.
+func (s *OrganizationService) keepOwner(ctx context.Context, organization string) error {
+	members, err := s.reader.ListMembers(ctx, organization)
+	if err != nil {
+		return err
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	if owners < 2 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+// canManage reports whether a member with role actor may grant or revoke role target.
+// Owners manage every role, admins only the roles below admin.
+func canManage(actor, target string) bool {
+	if actor == models.RoleOwner {
+		return true
+	}
+	return actor == models.RoleAdmin && roleRank[target] < roleRank[models.RoleAdmin]
+}
+
+// audit records an organization event when an auditor is configured.
+func (s *OrganizationService) audit(ctx context.Context, username, action, key string) {
+	if s.auditor != nil {
+		s.auditor.Record(ctx, username, action, key)
+	}
+}
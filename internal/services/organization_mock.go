@@ -0,0 +1,233 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/services/organization.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockOrganizationWriter is a mock of OrganizationWriter interface.
+type MockOrganizationWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationWriterMockRecorder
+}
+
+// MockOrganizationWriterMockRecorder is the mock recorder for MockOrganizationWriter.
+type MockOrganizationWriterMockRecorder struct {
+	mock *MockOrganizationWriter
+}
+
+// NewMockOrganizationWriter creates a new mock instance.
+func NewMockOrganizationWriter(ctrl *gomock.Controller) *MockOrganizationWriter {
+	mock := &MockOrganizationWriter{ctrl: ctrl}
+	mock.recorder = &MockOrganizationWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationWriter) EXPECT() *MockOrganizationWriterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockOrganizationWriter) Create(ctx context.Context, name, owner string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, name, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOrganizationWriterMockRecorder) Create(ctx, name, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrganizationWriter)(nil).Create), ctx, name, owner)
+}
+
+// CreateVault mocks base method.
+func (m *MockOrganizationWriter) CreateVault(ctx context.Context, organization, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVault", ctx, organization, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVault indicates an expected call of CreateVault.
+func (mr *MockOrganizationWriterMockRecorder) CreateVault(ctx, organization, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVault", reflect.TypeOf((*MockOrganizationWriter)(nil).CreateVault), ctx, organization, name)
+}
+
+// Delete mocks base method.
+func (m *MockOrganizationWriter) Delete(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOrganizationWriterMockRecorder) Delete(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrganizationWriter)(nil).Delete), ctx, name)
+}
+
+// DeleteMembership mocks base method.
+func (m *MockOrganizationWriter) DeleteMembership(ctx context.Context, organization, username string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMembership", ctx, organization, username)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMembership indicates an expected call of DeleteMembership.
+func (mr *MockOrganizationWriterMockRecorder) DeleteMembership(ctx, organization, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMembership", reflect.TypeOf((*MockOrganizationWriter)(nil).DeleteMembership), ctx, organization, username)
+}
+
+// DeleteVault mocks base method.
+func (m *MockOrganizationWriter) DeleteVault(ctx context.Context, organization, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVault", ctx, organization, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVault indicates an expected call of DeleteVault.
+func (mr *MockOrganizationWriterMockRecorder) DeleteVault(ctx, organization, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVault", reflect.TypeOf((*MockOrganizationWriter)(nil).DeleteVault), ctx, organization, name)
+}
+
+// SaveMembership mocks base method.
+func (m *MockOrganizationWriter) SaveMembership(ctx context.Context, organization, username, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMembership", ctx, organization, username, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMembership indicates an expected call of SaveMembership.
+func (mr *MockOrganizationWriterMockRecorder) SaveMembership(ctx, organization, username, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMembership", reflect.TypeOf((*MockOrganizationWriter)(nil).SaveMembership), ctx, organization, username, role)
+}
+
+// MockOrganizationReader is a mock of OrganizationReader interface.
+type MockOrganizationReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationReaderMockRecorder
+}
+
+// MockOrganizationReaderMockRecorder is the mock recorder for MockOrganizationReader.
+type MockOrganizationReaderMockRecorder struct {
+	mock *MockOrganizationReader
+}
+
+// NewMockOrganizationReader creates a new mock instance.
+func NewMockOrganizationReader(ctrl *gomock.Controller) *MockOrganizationReader {
+	mock := &MockOrganizationReader{ctrl: ctrl}
+	mock.recorder = &MockOrganizationReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationReader) EXPECT() *MockOrganizationReaderMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockOrganizationReader) Get(ctx context.Context, name string) (*models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, name)
+	ret0, _ := ret[0].(*models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOrganizationReaderMockRecorder) Get(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrganizationReader)(nil).Get), ctx, name)
+}
+
+// GetMembership mocks base method.
+func (m *MockOrganizationReader) GetMembership(ctx context.Context, organization, username string) (*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembership", ctx, organization, username)
+	ret0, _ := ret[0].(*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembership indicates an expected call of GetMembership.
+func (mr *MockOrganizationReaderMockRecorder) GetMembership(ctx, organization, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembership", reflect.TypeOf((*MockOrganizationReader)(nil).GetMembership), ctx, organization, username)
+}
+
+// GetVault mocks base method.
+func (m *MockOrganizationReader) GetVault(ctx context.Context, organization, name string) (*models.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVault", ctx, organization, name)
+	ret0, _ := ret[0].(*models.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVault indicates an expected call of GetVault.
+func (mr *MockOrganizationReaderMockRecorder) GetVault(ctx, organization, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVault", reflect.TypeOf((*MockOrganizationReader)(nil).GetVault), ctx, organization, name)
+}
+
+// ListByUser mocks base method.
+func (m *MockOrganizationReader) ListByUser(ctx context.Context, username string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, username)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockOrganizationReaderMockRecorder) ListByUser(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockOrganizationReader)(nil).ListByUser), ctx, username)
+}
+
+// ListMembers mocks base method.
+func (m *MockOrganizationReader) ListMembers(ctx context.Context, organization string) ([]*models.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, organization)
+	ret0, _ := ret[0].([]*models.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers.
+func (mr *MockOrganizationReaderMockRecorder) ListMembers(ctx, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockOrganizationReader)(nil).ListMembers), ctx, organization)
+}
+
+// ListVaults mocks base method.
+func (m *MockOrganizationReader) ListVaults(ctx context.Context, organization string) ([]*models.Vault, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVaults", ctx, organization)
+	ret0, _ := ret[0].([]*models.Vault)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVaults indicates an expected call of ListVaults.
+func (mr *MockOrganizationReaderMockRecorder) ListVaults(ctx, organization interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVaults", reflect.TypeOf((*MockOrganizationReader)(nil).ListVaults), ctx, organization)
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+func TestParseVault(t *testing.T) {
+	tests := []struct {
+		vault   string
+		org     string
+		name    string
+		wantErr bool
+	}{
+		{"acme/ops", "acme", "ops", false},
+		{"acme.io/prod-db", "acme.io", "prod-db", false},
+		{"acme", "", "", true},
+		{"acme/", "", "", true},
+		{"acme/ops/db", "", "", true},
+		{"a/ops", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.vault, func(t *testing.T) {
+			org, name, err := ParseVault(tt.vault)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidVault)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.org, org)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
+
+func TestOrganizationService_CreateDelete(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockOrganizationWriter(ctrl)
+	mockReader := NewMockOrganizationReader(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewOrganizationService(mockWriter, mockReader, nil, WithOrganizationAuditor(mockAuditor))
+
+	ctx := context.Background()
+	notFound := fmt.Errorf("failed to get: %w", sql.ErrNoRows)
+
+	t.Run("create", func(t *testing.T) {
+		mockReader.EXPECT().Get(ctx, "acme").Return(nil, notFound)
+		mockWriter.EXPECT().Create(ctx, "acme", "alice").Return(nil)
+		mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionOrgCreate, "acme")
+
+		require.NoError(t, service.CreateOrganization(ctx, "alice", "acme"))
+	})
+
+	t.Run("create existing", func(t *testing.T) {
+		mockReader.EXPECT().Get(ctx, "acme").Return(&models.Organization{Name: "acme"}, nil)
+
+		assert.ErrorIs(t, service.CreateOrganization(ctx, "bob", "acme"), ErrOrganizationExists)
+	})
+
+	t.Run("create invalid name", func(t *testing.T) {
+		assert.ErrorIs(t, service.CreateOrganization(ctx, "alice", "acme/ops"), ErrInvalidName)
+	})
+
+	t.Run("delete by owner", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "alice").Return(&models.Membership{Role: models.RoleOwner}, nil)
+		mockWriter.EXPECT().Delete(ctx, "acme").Return(nil)
+		mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionOrgDelete, "acme")
+
+		require.NoError(t, service.DeleteOrganization(ctx, "alice", "acme"))
+	})
+
+	t.Run("delete by admin", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(&models.Membership{Role: models.RoleAdmin}, nil)
+
+		assert.ErrorIs(t, service.DeleteOrganization(ctx, "bob", "acme"), ErrInsufficientRole)
+	})
+
+	t.Run("delete by outsider", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "eve").Return(nil, notFound)
+
+		assert.ErrorIs(t, service.DeleteOrganization(ctx, "eve", "acme"), ErrNotMember)
+	})
+}
+
+func TestOrganizationService_Members(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockOrganizationWriter(ctrl)
+	mockReader := NewMockOrganizationReader(ctrl)
+	mockUsers := NewMockUserGetter(ctrl)
+	service := NewOrganizationService(mockWriter, mockReader, mockUsers)
+
+	ctx := context.Background()
+	notFound := fmt.Errorf("failed to get: %w", sql.ErrNoRows)
+	owner := &models.Membership{Organization: "acme", Username: "alice", Role: models.RoleOwner}
+	admin := &models.Membership{Organization: "acme", Username: "bob", Role: models.RoleAdmin}
+	member := &models.Membership{Organization: "acme", Username: "carol", Role: models.RoleMember}
+
+	t.Run("admin adds member", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+		mockUsers.EXPECT().Get(ctx, "dave").Return(&models.User{Username: "dave"}, nil)
+		mockReader.EXPECT().GetMembership(ctx, "acme", "dave").Return(nil, notFound)
+		mockWriter.EXPECT().SaveMembership(ctx, "acme", "dave", models.RoleReadOnly).Return(nil)
+
+		require.NoError(t, service.SetMember(ctx, "bob", "acme", "dave", models.RoleReadOnly))
+	})
+
+	t.Run("admin cannot grant admin", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+		mockUsers.EXPECT().Get(ctx, "carol").Return(&models.User{Username: "carol"}, nil)
+		mockReader.EXPECT().GetMembership(ctx, "acme", "carol").Return(member, nil)
+
+		assert.ErrorIs(t, service.SetMember(ctx, "bob", "acme", "carol", models.RoleAdmin), ErrInsufficientRole)
+	})
+
+	t.Run("member cannot manage members", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "carol").Return(member, nil)
+
+		assert.ErrorIs(t, service.SetMember(ctx, "carol", "acme", "dave", models.RoleMember), ErrInsufficientRole)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "alice").Return(owner, nil)
+		mockUsers.EXPECT().Get(ctx, "ghost").Return(nil, notFound)
+
+		assert.ErrorIs(t, service.SetMember(ctx, "alice", "acme", "ghost", models.RoleMember), ErrUserNotFound)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		assert.ErrorIs(t, service.SetMember(ctx, "alice", "acme", "dave", "root"), ErrInvalidRole)
+	})
+
+	t.Run("last owner cannot step down", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "alice").Return(owner, nil).Times(2)
+		mockUsers.EXPECT().Get(ctx, "alice").Return(&models.User{Username: "alice"}, nil)
+		mockReader.EXPECT().ListMembers(ctx, "acme").Return([]*models.Membership{owner, admin, member}, nil)
+
+		assert.ErrorIs(t, service.SetMember(ctx, "alice", "acme", "alice", models.RoleAdmin), ErrLastOwner)
+	})
+
+	t.Run("member leaves", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "carol").Return(member, nil)
+		mockWriter.EXPECT().DeleteMembership(ctx, "acme", "carol").Return(nil)
+
+		require.NoError(t, service.RemoveMember(ctx, "carol", "acme", "carol"))
+	})
+
+	t.Run("last owner cannot leave", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "alice").Return(owner, nil)
+		mockReader.EXPECT().ListMembers(ctx, "acme").Return([]*models.Membership{owner, admin}, nil)
+
+		assert.ErrorIs(t, service.RemoveMember(ctx, "alice", "acme", "alice"), ErrLastOwner)
+	})
+
+	t.Run("admin cannot remove owner", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "alice").Return(owner, nil)
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+
+		assert.ErrorIs(t, service.RemoveMember(ctx, "bob", "acme", "alice"), ErrInsufficientRole)
+	})
+
+	t.Run("remove non-member", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "dave").Return(nil, notFound)
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+
+		assert.ErrorIs(t, service.RemoveMember(ctx, "bob", "acme", "dave"), ErrMemberNotFound)
+	})
+
+	t.Run("list members", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "carol").Return(member, nil)
+		mockReader.EXPECT().ListMembers(ctx, "acme").Return([]*models.Membership{owner, admin, member}, nil)
+
+		members, err := service.ListMembers(ctx, "carol", "acme")
+		require.NoError(t, err)
+		assert.Len(t, members, 3)
+	})
+}
+
+func TestOrganizationService_Vaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockOrganizationWriter(ctrl)
+	mockReader := NewMockOrganizationReader(ctrl)
+	service := NewOrganizationService(mockWriter, mockReader, nil)
+
+	ctx := context.Background()
+	notFound := fmt.Errorf("failed to get: %w", sql.ErrNoRows)
+	admin := &models.Membership{Role: models.RoleAdmin}
+	readOnly := &models.Membership{Role: models.RoleReadOnly}
+
+	t.Run("create", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+		mockReader.EXPECT().GetVault(ctx, "acme", "ops").Return(nil, notFound)
+		mockWriter.EXPECT().CreateVault(ctx, "acme", "ops").Return(nil)
+
+		require.NoError(t, service.CreateVault(ctx, "bob", "acme", "ops"))
+	})
+
+	t.Run("create existing", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+		mockReader.EXPECT().GetVault(ctx, "acme", "ops").Return(&models.Vault{}, nil)
+
+		assert.ErrorIs(t, service.CreateVault(ctx, "bob", "acme", "ops"), ErrVaultExists)
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+		mockWriter.EXPECT().DeleteVault(ctx, "acme", "gone").Return(notFound)
+
+		assert.ErrorIs(t, service.DeleteVault(ctx, "bob", "acme", "gone"), ErrVaultNotFound)
+	})
+
+	t.Run("read-only member reads", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "dave").Return(readOnly, nil)
+		mockReader.EXPECT().GetVault(ctx, "acme", "ops").Return(&models.Vault{}, nil)
+
+		org, name, err := service.AuthorizeVault(ctx, "dave", "acme/ops", false)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", org)
+		assert.Equal(t, "ops", name)
+	})
+
+	t.Run("read-only member cannot write", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "dave").Return(readOnly, nil)
+
+		_, _, err := service.AuthorizeVault(ctx, "dave", "acme/ops", true)
+		assert.ErrorIs(t, err, ErrInsufficientRole)
+	})
+
+	t.Run("outsider", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "eve").Return(nil, notFound)
+
+		_, _, err := service.AuthorizeVault(ctx, "eve", "acme/ops", false)
+		assert.ErrorIs(t, err, ErrNotMember)
+	})
+
+	t.Run("missing vault", func(t *testing.T) {
+		mockReader.EXPECT().GetMembership(ctx, "acme", "bob").Return(admin, nil)
+		mockReader.EXPECT().GetVault(ctx, "acme", "gone").Return(nil, notFound)
+
+		_, _, err := service.AuthorizeVault(ctx, "bob", "acme/gone", true)
+		assert.ErrorIs(t, err, ErrVaultNotFound)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, _, err := service.AuthorizeVault(ctx, "bob", "ops", false)
+		assert.ErrorIs(t, err, ErrInvalidVault)
+	})
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/sbilibin2017/gophkeeper/internal/models"
 )
@@ -12,26 +14,115 @@ type SecretWriter interface {
 		ctx context.Context,
 		username, secretName, secretType string,
 		ciphertext, aesKeyEnc []byte,
+		keyID string,
+		recipients models.Recipients,
+	) error
+	SaveBatch(ctx context.Context, username string, secrets []*models.Secret) error
+}
+
+// VaultAuthorizer checks the access of a user to the secrets of an organization vault.
+type VaultAuthorizer interface {
+	AuthorizeVault(ctx context.Context, username, vault string, write bool) (organization, name string, err error)
+}
+
+// VaultSecretWriter defines the interface for storing secrets of organization vaults.
+type VaultSecretWriter interface {
+	Save(
+		ctx context.Context,
+		organization, vault, secretName, secretType string,
+		ciphertext, aesKeyEnc []byte,
+		keyID string,
+		recipients models.Recipients,
+		updatedBy string,
 	) error
 }
 
 // SecretWriteService provides methods for writing secrets.
 type SecretWriteService struct {
-	writer SecretWriter
+	writer       SecretWriter
+	auditor      Auditor
+	vaults       VaultAuthorizer
+	vaultSecrets VaultSecretWriter
+}
+
+// SecretWriteOpt defines a functional option for configuring a SecretWriteService.
+type SecretWriteOpt func(*SecretWriteService)
+
+// WithSecretWriteAuditor records every saved secret with auditor.
+func WithSecretWriteAuditor(auditor Auditor) SecretWriteOpt {
+	return func(s *SecretWriteService) {
+		s.auditor = auditor
+	}
+}
+
+// WithSecretWriteVaults enables saving secrets to organization vaults,
+// authorizing every write by the membership of the user.
+func WithSecretWriteVaults(authorizer VaultAuthorizer, writer VaultSecretWriter) SecretWriteOpt {
+	return func(s *SecretWriteService) {
+		s.vaults = authorizer
+		s.vaultSecrets = writer
+	}
 }
 
 // NewSecretWriteService creates a new instance of SecretWriteService.
-func NewSecretWriteService(writer SecretWriter) *SecretWriteService {
-	return &SecretWriteService{writer: writer}
+func NewSecretWriteService(writer SecretWriter, opts ...SecretWriteOpt) *SecretWriteService {
+	s := &SecretWriteService{writer: writer}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Save stores a new secret.
+// Save stores a new secret. An empty vault stores a personal secret of username,
+// otherwise the secret is stored in the organization vault addressed as "organization/vault",
+// which requires at least the member role.
 func (s *SecretWriteService) Save(
 	ctx context.Context,
-	username, secretName, secretType string,
+	username, vault, secretName, secretType string,
 	ciphertext, aesKeyEnc []byte,
+	keyID string,
+	recipients models.Recipients,
 ) error {
-	return s.writer.Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc)
+	key := secretKey(secretType, secretName)
+	if vault == "" {
+		if err := s.writer.Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients); err != nil {
+			return err
+		}
+	} else {
+		if s.vaults == nil {
+			return ErrVaultsNotConfigured
+		}
+		organization, name, err := s.vaults.AuthorizeVault(ctx, username, vault, true)
+		if err != nil {
+			return err
+		}
+		err = s.vaultSecrets.Save(ctx, organization, name, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients, username)
+		if err != nil {
+			return err
+		}
+		key = vault + ":" + key
+	}
+	if s.auditor != nil {
+		s.auditor.Record(ctx, username, models.AuditActionSecretSave, key)
+	}
+	return nil
+}
+
+// SaveBatch stores several secrets atomically, either all of them or none.
+func (s *SecretWriteService) SaveBatch(
+	ctx context.Context,
+	username string,
+	secrets []*models.Secret,
+) error {
+	if err := s.writer.SaveBatch(ctx, username, secrets); err != nil {
+		return err
+	}
+	if s.auditor != nil {
+		for _, secret := range secrets {
+			s.auditor.Record(ctx, username, models.AuditActionSecretSave, secretKey(secret.SecretType, secret.SecretName))
+		}
+	}
+	return nil
 }
 
 // SecretReader defines the interface that the read service depends on.
@@ -40,34 +131,115 @@ type SecretReader interface {
 	List(ctx context.Context, username string) ([]*models.Secret, error)
 }
 
+// VaultSecretReader defines the interface for reading secrets of organization vaults.
+type VaultSecretReader interface {
+	Get(ctx context.Context, organization, vault, secretType, secretName string) (*models.Secret, error)
+	List(ctx context.Context, organization, vault string) ([]*models.Secret, error)
+}
+
 // SecretReadService provides methods for reading secrets using a JWT token.
 type SecretReadService struct {
-	reader SecretReader
+	reader       SecretReader
+	auditor      Auditor
+	vaults       VaultAuthorizer
+	vaultSecrets VaultSecretReader
+}
+
+// SecretReadOpt defines a functional option for configuring a SecretReadService.
+type SecretReadOpt func(*SecretReadService)
+
+// WithSecretReadAuditor records every secret read with auditor.
+func WithSecretReadAuditor(auditor Auditor) SecretReadOpt {
+	return func(s *SecretReadService) {
+		s.auditor = auditor
+	}
+}
+
+// WithSecretReadVaults enables reading secrets of organization vaults,
+// authorizing every read by the membership of the user.
+func WithSecretReadVaults(authorizer VaultAuthorizer, reader VaultSecretReader) SecretReadOpt {
+	return func(s *SecretReadService) {
+		s.vaults = authorizer
+		s.vaultSecrets = reader
+	}
 }
 
 // NewSecretReadService creates a new instance of SecretReadService.
-func NewSecretReadService(reader SecretReader) *SecretReadService {
-	return &SecretReadService{
+func NewSecretReadService(reader SecretReader, opts ...SecretReadOpt) *SecretReadService {
+	s := &SecretReadService{
 		reader: reader,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// Get parses token and returns a secret by type and name.
+// Get returns a secret by type and name. An empty vault reads a personal secret of username,
+// otherwise a secret of the organization vault username is a member of.
 func (s *SecretReadService) Get(
 	ctx context.Context,
-	username, secretType, secretName string,
+	username, vault, secretType, secretName string,
 ) (*models.Secret, error) {
-	secret, err := s.reader.Get(ctx, username, secretType, secretName)
+	key := secretKey(secretType, secretName)
+	var (
+		secret *models.Secret
+		err    error
+	)
+	if vault == "" {
+		secret, err = s.reader.Get(ctx, username, secretType, secretName)
+	} else {
+		if s.vaults == nil {
+			return nil, ErrVaultsNotConfigured
+		}
+		var organization, name string
+		organization, name, err = s.vaults.AuthorizeVault(ctx, username, vault, false)
+		if err != nil {
+			return nil, err
+		}
+		secret, err = s.vaultSecrets.Get(ctx, organization, name, secretType, secretName)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSecretNotFound
+		}
+		key = vault + ":" + key
+	}
 	if err != nil {
 		return nil, err
 	}
+	if s.auditor != nil {
+		s.auditor.Record(ctx, username, models.AuditActionSecretGet, key)
+	}
 	return secret, nil
 }
 
-// List parses token and returns all secrets for the user.
+// List returns all personal secrets of username for an empty vault,
+// otherwise all secrets of the organization vault username is a member of.
 func (s *SecretReadService) List(
 	ctx context.Context,
-	username string,
+	username, vault string,
 ) ([]*models.Secret, error) {
-	return s.reader.List(ctx, username)
+	var (
+		secrets []*models.Secret
+		err     error
+	)
+	if vault == "" {
+		secrets, err = s.reader.List(ctx, username)
+	} else {
+		if s.vaults == nil {
+			return nil, ErrVaultsNotConfigured
+		}
+		var organization, name string
+		organization, name, err = s.vaults.AuthorizeVault(ctx, username, vault, false)
+		if err != nil {
+			return nil, err
+		}
+		secrets, err = s.vaultSecrets.List(ctx, organization, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.auditor != nil {
+		s.auditor.Record(ctx, username, models.AuditActionSecretList, vault)
+	}
+	return secrets, nil
 }
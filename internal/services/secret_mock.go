@@ -36,17 +36,107 @@ func (m *MockSecretWriter) EXPECT() *MockSecretWriterMockRecorder {
 }
 
 // Save mocks base method.
-func (m *MockSecretWriter) Save(ctx context.Context, username, secretName, secretType string, ciphertext, aesKeyEnc []byte) error {
+func (m *MockSecretWriter) Save(ctx context.Context, username, secretName, secretType string, ciphertext, aesKeyEnc []byte, keyID string, recipients models.Recipients) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", ctx, username, secretName, secretType, ciphertext, aesKeyEnc)
+	ret := m.ctrl.Call(m, "Save", ctx, username, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockSecretWriterMockRecorder) Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc interface{}) *gomock.Call {
+func (mr *MockSecretWriterMockRecorder) Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSecretWriter)(nil).Save), ctx, username, secretName, secretType, ciphertext, aesKeyEnc)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSecretWriter)(nil).Save), ctx, username, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
+}
+
+// SaveBatch mocks base method.
+func (m *MockSecretWriter) SaveBatch(ctx context.Context, username string, secrets []*models.Secret) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveBatch", ctx, username, secrets)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveBatch indicates an expected call of SaveBatch.
+func (mr *MockSecretWriterMockRecorder) SaveBatch(ctx, username, secrets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatch", reflect.TypeOf((*MockSecretWriter)(nil).SaveBatch), ctx, username, secrets)
+}
+
+// MockVaultAuthorizer is a mock of VaultAuthorizer interface.
+type MockVaultAuthorizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultAuthorizerMockRecorder
+}
+
+// MockVaultAuthorizerMockRecorder is the mock recorder for MockVaultAuthorizer.
+type MockVaultAuthorizerMockRecorder struct {
+	mock *MockVaultAuthorizer
+}
+
+// NewMockVaultAuthorizer creates a new mock instance.
+func NewMockVaultAuthorizer(ctrl *gomock.Controller) *MockVaultAuthorizer {
+	mock := &MockVaultAuthorizer{ctrl: ctrl}
+	mock.recorder = &MockVaultAuthorizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultAuthorizer) EXPECT() *MockVaultAuthorizerMockRecorder {
+	return m.recorder
+}
+
+// AuthorizeVault mocks base method.
+func (m *MockVaultAuthorizer) AuthorizeVault(ctx context.Context, username, vault string, write bool) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizeVault", ctx, username, vault, write)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuthorizeVault indicates an expected call of AuthorizeVault.
+func (mr *MockVaultAuthorizerMockRecorder) AuthorizeVault(ctx, username, vault, write interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizeVault", reflect.TypeOf((*MockVaultAuthorizer)(nil).AuthorizeVault), ctx, username, vault, write)
+}
+
+// MockVaultSecretWriter is a mock of VaultSecretWriter interface.
+type MockVaultSecretWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultSecretWriterMockRecorder
+}
+
+// MockVaultSecretWriterMockRecorder is the mock recorder for MockVaultSecretWriter.
+type MockVaultSecretWriterMockRecorder struct {
+	mock *MockVaultSecretWriter
+}
+
+// NewMockVaultSecretWriter creates a new mock instance.
+func NewMockVaultSecretWriter(ctrl *gomock.Controller) *MockVaultSecretWriter {
+	mock := &MockVaultSecretWriter{ctrl: ctrl}
+	mock.recorder = &MockVaultSecretWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultSecretWriter) EXPECT() *MockVaultSecretWriterMockRecorder {
+	return m.recorder
+}
+
+// Save mocks base method.
+func (m *MockVaultSecretWriter) Save(ctx context.Context, organization, vault, secretName, secretType string, ciphertext, aesKeyEnc []byte, keyID string, recipients models.Recipients, updatedBy string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, organization, vault, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients, updatedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockVaultSecretWriterMockRecorder) Save(ctx, organization, vault, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients, updatedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockVaultSecretWriter)(nil).Save), ctx, organization, vault, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients, updatedBy)
 }
 
 // MockSecretReader is a mock of SecretReader interface.
@@ -101,3 +191,56 @@ func (mr *MockSecretReaderMockRecorder) List(ctx, username interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSecretReader)(nil).List), ctx, username)
 }
+
+// MockVaultSecretReader is a mock of VaultSecretReader interface.
+type MockVaultSecretReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockVaultSecretReaderMockRecorder
+}
+
+// MockVaultSecretReaderMockRecorder is the mock recorder for MockVaultSecretReader.
+type MockVaultSecretReaderMockRecorder struct {
+	mock *MockVaultSecretReader
+}
+
+// NewMockVaultSecretReader creates a new mock instance.
+func NewMockVaultSecretReader(ctrl *gomock.Controller) *MockVaultSecretReader {
+	mock := &MockVaultSecretReader{ctrl: ctrl}
+	mock.recorder = &MockVaultSecretReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVaultSecretReader) EXPECT() *MockVaultSecretReaderMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockVaultSecretReader) Get(ctx context.Context, organization, vault, secretType, secretName string) (*models.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, organization, vault, secretType, secretName)
+	ret0, _ := ret[0].(*models.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockVaultSecretReaderMockRecorder) Get(ctx, organization, vault, secretType, secretName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockVaultSecretReader)(nil).Get), ctx, organization, vault, secretType, secretName)
+}
+
+// List mocks base method.
+func (m *MockVaultSecretReader) List(ctx context.Context, organization, vault string) ([]*models.Secret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, organization, vault)
+	ret0, _ := ret[0].([]*models.Secret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockVaultSecretReaderMockRecorder) List(ctx, organization, vault interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockVaultSecretReader)(nil).List), ctx, organization, vault)
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -24,6 +26,8 @@ func TestSecretWriteService_Save(t *testing.T) {
 	secretType := "password"
 	ciphertext := []byte("cipherdata")
 	aesKeyEnc := []byte("keydata")
+	keyID := "key-1"
+	recipients := models.Recipients{{KeyID: keyID, AESKeyEnc: aesKeyEnc}}
 
 	tests := []struct {
 		name      string
@@ -37,10 +41,10 @@ func TestSecretWriteService_Save(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockWriter.EXPECT().
-				Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc).
+				Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients).
 				Return(tt.saveErr)
 
-			err := service.Save(ctx, username, secretName, secretType, ciphertext, aesKeyEnc)
+			err := service.Save(ctx, username, "", secretName, secretType, ciphertext, aesKeyEnc, keyID, recipients)
 			if tt.expectErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, tt.expectErr.Error())
@@ -51,6 +55,29 @@ func TestSecretWriteService_Save(t *testing.T) {
 	}
 }
 
+func TestSecretWriteService_SaveBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockSecretWriter(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewSecretWriteService(mockWriter, WithSecretWriteAuditor(mockAuditor))
+
+	ctx := context.Background()
+	secrets := []*models.Secret{
+		{SecretName: "mail", SecretType: models.SecretTypeUser, KeyID: "key-2"},
+		{SecretName: "note", SecretType: models.SecretTypeText, KeyID: "key-2"},
+	}
+
+	mockWriter.EXPECT().SaveBatch(ctx, "alice", secrets).Return(nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionSecretSave, "user/mail")
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionSecretSave, "text/note")
+	assert.NoError(t, service.SaveBatch(ctx, "alice", secrets))
+
+	mockWriter.EXPECT().SaveBatch(ctx, "alice", secrets).Return(errors.New("tx error"))
+	assert.EqualError(t, service.SaveBatch(ctx, "alice", secrets), "tx error")
+}
+
 func TestSecretReadService_Get(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -91,7 +118,7 @@ func TestSecretReadService_Get(t *testing.T) {
 				Get(ctx, username, secretType, secretName).
 				Return(tt.getSecret, tt.getErr)
 
-			secret, err := service.Get(ctx, username, secretType, secretName)
+			secret, err := service.Get(ctx, username, "", secretType, secretName)
 			if tt.expectErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, tt.expectErr.Error())
@@ -153,7 +180,7 @@ func TestSecretReadService_List(t *testing.T) {
 				List(ctx, username).
 				Return(tt.listSecrets, tt.listErr)
 
-			result, err := service.List(ctx, username)
+			result, err := service.List(ctx, username, "")
 			if tt.expectErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, tt.expectErr.Error())
@@ -165,3 +192,106 @@ func TestSecretReadService_List(t *testing.T) {
 		})
 	}
 }
+
+func TestSecretServices_Audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockSecretWriter(ctrl)
+	mockReader := NewMockSecretReader(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+
+	writeService := NewSecretWriteService(mockWriter, WithSecretWriteAuditor(mockAuditor))
+	readService := NewSecretReadService(mockReader, WithSecretReadAuditor(mockAuditor))
+
+	ctx := context.Background()
+
+	// Successful operations are recorded.
+	mockWriter.EXPECT().Save(ctx, "alice", "mail", "user", []byte("ct"), []byte("key"), "key-1", nil).Return(nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionSecretSave, "user/mail")
+	assert.NoError(t, writeService.Save(ctx, "alice", "", "mail", "user", []byte("ct"), []byte("key"), "key-1", nil))
+
+	mockReader.EXPECT().Get(ctx, "alice", "user", "mail").Return(&models.Secret{}, nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionSecretGet, "user/mail")
+	_, err := readService.Get(ctx, "alice", "", "user", "mail")
+	assert.NoError(t, err)
+
+	mockReader.EXPECT().List(ctx, "alice").Return(nil, nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionSecretList, "")
+	_, err = readService.List(ctx, "alice", "")
+	assert.NoError(t, err)
+
+	// Failed operations are not.
+	mockWriter.EXPECT().Save(ctx, "alice", "mail", "user", nil, nil, "", nil).Return(errors.New("save error"))
+	assert.Error(t, writeService.Save(ctx, "alice", "", "mail", "user", nil, nil, "", nil))
+
+	mockReader.EXPECT().Get(ctx, "alice", "user", "mail").Return(nil, errors.New("not found"))
+	_, err = readService.Get(ctx, "alice", "", "user", "mail")
+	assert.Error(t, err)
+
+	mockReader.EXPECT().List(ctx, "alice").Return(nil, errors.New("db error"))
+	_, err = readService.List(ctx, "alice", "")
+	assert.Error(t, err)
+}
+
+func TestSecretServices_Vault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuthorizer := NewMockVaultAuthorizer(ctrl)
+	mockVaultWriter := NewMockVaultSecretWriter(ctrl)
+	mockVaultReader := NewMockVaultSecretReader(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+
+	writeService := NewSecretWriteService(NewMockSecretWriter(ctrl),
+		WithSecretWriteAuditor(mockAuditor),
+		WithSecretWriteVaults(mockAuthorizer, mockVaultWriter),
+	)
+	readService := NewSecretReadService(NewMockSecretReader(ctrl),
+		WithSecretReadAuditor(mockAuditor),
+		WithSecretReadVaults(mockAuthorizer, mockVaultReader),
+	)
+
+	ctx := context.Background()
+	secret := &models.Secret{SecretName: "db", SecretType: "text", SecretOwner: "acme", Vault: "acme/ops"}
+
+	// Members write to the vault, every write records its author.
+	mockAuthorizer.EXPECT().AuthorizeVault(ctx, "alice", "acme/ops", true).Return("acme", "ops", nil)
+	mockVaultWriter.EXPECT().Save(ctx, "acme", "ops", "db", "text", []byte("ct"), []byte("key"), "key-1", nil, "alice").Return(nil)
+	mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionSecretSave, "acme/ops:text/db")
+	assert.NoError(t, writeService.Save(ctx, "alice", "acme/ops", "db", "text", []byte("ct"), []byte("key"), "key-1", nil))
+
+	// Read-only members cannot.
+	mockAuthorizer.EXPECT().AuthorizeVault(ctx, "bob", "acme/ops", true).Return("", "", ErrInsufficientRole)
+	assert.ErrorIs(t, writeService.Save(ctx, "bob", "acme/ops", "db", "text", nil, nil, "", nil), ErrInsufficientRole)
+
+	// But they read.
+	mockAuthorizer.EXPECT().AuthorizeVault(ctx, "bob", "acme/ops", false).Return("acme", "ops", nil)
+	mockVaultReader.EXPECT().Get(ctx, "acme", "ops", "text", "db").Return(secret, nil)
+	mockAuditor.EXPECT().Record(ctx, "bob", models.AuditActionSecretGet, "acme/ops:text/db")
+	got, err := readService.Get(ctx, "bob", "acme/ops", "text", "db")
+	assert.NoError(t, err)
+	assert.Equal(t, secret, got)
+
+	mockAuthorizer.EXPECT().AuthorizeVault(ctx, "bob", "acme/ops", false).Return("acme", "ops", nil)
+	mockVaultReader.EXPECT().Get(ctx, "acme", "ops", "text", "missing").Return(nil, fmt.Errorf("wrapped: %w", sql.ErrNoRows))
+	_, err = readService.Get(ctx, "bob", "acme/ops", "text", "missing")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	mockAuthorizer.EXPECT().AuthorizeVault(ctx, "bob", "acme/ops", false).Return("acme", "ops", nil)
+	mockVaultReader.EXPECT().List(ctx, "acme", "ops").Return([]*models.Secret{secret}, nil)
+	mockAuditor.EXPECT().Record(ctx, "bob", models.AuditActionSecretList, "acme/ops")
+	list, err := readService.List(ctx, "bob", "acme/ops")
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	// Outsiders do not.
+	mockAuthorizer.EXPECT().AuthorizeVault(ctx, "eve", "acme/ops", false).Return("", "", ErrNotMember)
+	_, err = readService.List(ctx, "eve", "acme/ops")
+	assert.ErrorIs(t, err, ErrNotMember)
+
+	// Without vault support only personal secrets are available.
+	_, err = NewSecretReadService(NewMockSecretReader(ctrl)).Get(ctx, "bob", "acme/ops", "text", "db")
+	assert.ErrorIs(t, err, ErrVaultsNotConfigured)
+	assert.ErrorIs(t, NewSecretWriteService(NewMockSecretWriter(ctrl)).Save(ctx, "bob", "acme/ops", "db", "text", nil, nil, "", nil), ErrVaultsNotConfigured)
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// ShareWriter defines the interface for storing secret shares.
+type ShareWriter interface {
+	Save(
+		ctx context.Context,
+		secretOwner, secretType, secretName, recipient string,
+		aesKeyEnc []byte,
+		keyID, permission string,
+	) error
+	Delete(ctx context.Context, secretOwner, secretType, secretName, recipient string) error
+}
+
+// ShareReader defines the interface for reading secret shares.
+type ShareReader interface {
+	Get(ctx context.Context, secretOwner, secretType, secretName, recipient string) (*models.SecretShare, error)
+	ListByRecipient(ctx context.Context, recipient string) ([]*models.SharedSecret, error)
+}
+
+// SecretCiphertextUpdater replaces the content of an existing secret.
+type SecretCiphertextUpdater interface {
+	UpdateCiphertext(ctx context.Context, secretOwner, secretType, secretName string, ciphertext []byte) error
+}
+
+var (
+	ErrSecretNotFound       = errors.New("secret not found")
+	ErrShareNotFound        = errors.New("share not found")
+	ErrInvalidPermission    = errors.New("invalid share permission, expected ro or rw")
+	ErrShareWithSelf        = errors.New("cannot share a secret with its owner")
+	ErrShareReadOnly        = errors.New("secret is shared read-only")
+	ErrRecipientKeyOutdated = errors.New("data key is not wrapped for the recipient's current certificate")
+	ErrShareStale           = errors.New("secret has a new data key, the owner has to share it again")
+)
+
+// ShareService shares secrets of one user with other users.
+//
+// The server never sees a data key: the owner unwraps it locally and wraps it
+// for the certificate the recipient published, the service only checks that
+// the wrapped key belongs to that certificate.
+type ShareService struct {
+	writer  ShareWriter
+	reader  ShareReader
+	secrets SecretReader
+	updater SecretCiphertextUpdater
+	users   UserGetter
+	auditor Auditor
+}
+
+// ShareOpt defines a functional option for configuring a ShareService.
+type ShareOpt func(*ShareService)
+
+// WithShareAuditor records every share change and shared secret access with auditor.
+func WithShareAuditor(auditor Auditor) ShareOpt {
+	return func(s *ShareService) {
+		s.auditor = auditor
+	}
+}
+
+// NewShareService creates a new instance of ShareService.
+func NewShareService(
+	writer ShareWriter,
+	reader ShareReader,
+	secrets SecretReader,
+	updater SecretCiphertextUpdater,
+	users UserGetter,
+	opts ...ShareOpt,
+) *ShareService {
+	s := &ShareService{
+		writer:  writer,
+		reader:  reader,
+		secrets: secrets,
+		updater: updater,
+		users:   users,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Share grants recipient access to a secret of owner with the given permission.
+// aesKeyEnc is the data key of the secret wrapped for the certificate identified by keyID,
+// which has to be the certificate recipient currently publishes.
+// Sharing an already shared secret replaces the wrapped key and the permission.
+func (s *ShareService) Share(
+	ctx context.Context,
+	owner, secretType, secretName, recipient string,
+	aesKeyEnc []byte,
+	keyID, permission string,
+) error {
+	if permission != models.SharePermissionRead && permission != models.SharePermissionReadWrite {
+		return ErrInvalidPermission
+	}
+	if recipient == owner {
+		return ErrShareWithSelf
+	}
+
+	if _, err := s.secrets.Get(ctx, owner, secretType, secretName); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSecretNotFound
+		}
+		return err
+	}
+
+	user, err := s.users.Get(ctx, recipient)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && user == nil) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if user.Certificate == "" {
+		return ErrNoCertificate
+	}
+	certKeyID, err := cryptor.CertificateKeyID([]byte(user.Certificate))
+	if err != nil {
+		return err
+	}
+	if certKeyID != keyID {
+		return ErrRecipientKeyOutdated
+	}
+
+	if err := s.writer.Save(ctx, owner, secretType, secretName, recipient, aesKeyEnc, keyID, permission); err != nil {
+		return err
+	}
+
+	s.audit(ctx, owner, models.AuditActionSecretShare, secretKey(secretType, secretName)+" -> "+recipient)
+
+	return nil
+}
+
+// Unshare revokes the access of recipient to a secret of owner.
+func (s *ShareService) Unshare(ctx context.Context, owner, secretType, secretName, recipient string) error {
+	if err := s.writer.Delete(ctx, owner, secretType, secretName, recipient); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrShareNotFound
+		}
+		return err
+	}
+
+	s.audit(ctx, owner, models.AuditActionSecretUnshare, secretKey(secretType, secretName)+" -> "+recipient)
+
+	return nil
+}
+
+// ListSharedWithMe returns all secrets other users share with username.
+func (s *ShareService) ListSharedWithMe(ctx context.Context, username string) ([]*models.SharedSecret, error) {
+	secrets, err := s.reader.ListByRecipient(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, username, models.AuditActionSharedList, "")
+
+	return secrets, nil
+}
+
+// SaveShared replaces the content of a secret of owner shared with username.
+// The share must be read-write and not stale; the ciphertext has to be encrypted
+// with the existing data key, so that every wrapped key of the secret stays valid.
+func (s *ShareService) SaveShared(
+	ctx context.Context,
+	username, owner, secretType, secretName string,
+	ciphertext []byte,
+) error {
+	share, err := s.reader.Get(ctx, owner, secretType, secretName, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrShareNotFound
+		}
+		return err
+	}
+	if share.Permission != models.SharePermissionReadWrite {
+		return ErrShareReadOnly
+	}
+	if share.Stale {
+		return ErrShareStale
+	}
+
+	if err := s.updater.UpdateCiphertext(ctx, owner, secretType, secretName, ciphertext); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSecretNotFound
+		}
+		return err
+	}
+
+	s.audit(ctx, username, models.AuditActionSharedSave, owner+":"+secretKey(secretType, secretName))
+
+	return nil
+}
+
+// audit records a share event when an auditor is configured.
+func (s *ShareService) audit(ctx context.Context, username, action, key string) {
+	if s.auditor != nil {
+		s.auditor.Record(ctx, username, action, key)
+	}
+}
@@ -0,0 +1,154 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: /home/sergey/Github/gophkeeper/internal/services/share.go
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// MockShareWriter is a mock of ShareWriter interface.
+type MockShareWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareWriterMockRecorder
+}
+
+// MockShareWriterMockRecorder is the mock recorder for MockShareWriter.
+type MockShareWriterMockRecorder struct {
+	mock *MockShareWriter
+}
+
+// NewMockShareWriter creates a new mock instance.
+func NewMockShareWriter(ctrl *gomock.Controller) *MockShareWriter {
+	mock := &MockShareWriter{ctrl: ctrl}
+	mock.recorder = &MockShareWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareWriter) EXPECT() *MockShareWriterMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockShareWriter) Delete(ctx context.Context, secretOwner, secretType, secretName, recipient string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, secretOwner, secretType, secretName, recipient)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockShareWriterMockRecorder) Delete(ctx, secretOwner, secretType, secretName, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockShareWriter)(nil).Delete), ctx, secretOwner, secretType, secretName, recipient)
+}
+
+// Save mocks base method.
+func (m *MockShareWriter) Save(ctx context.Context, secretOwner, secretType, secretName, recipient string, aesKeyEnc []byte, keyID, permission string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, secretOwner, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockShareWriterMockRecorder) Save(ctx, secretOwner, secretType, secretName, recipient, aesKeyEnc, keyID, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockShareWriter)(nil).Save), ctx, secretOwner, secretType, secretName, recipient, aesKeyEnc, keyID, permission)
+}
+
+// MockShareReader is a mock of ShareReader interface.
+type MockShareReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareReaderMockRecorder
+}
+
+// MockShareReaderMockRecorder is the mock recorder for MockShareReader.
+type MockShareReaderMockRecorder struct {
+	mock *MockShareReader
+}
+
+// NewMockShareReader creates a new mock instance.
+func NewMockShareReader(ctrl *gomock.Controller) *MockShareReader {
+	mock := &MockShareReader{ctrl: ctrl}
+	mock.recorder = &MockShareReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareReader) EXPECT() *MockShareReaderMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockShareReader) Get(ctx context.Context, secretOwner, secretType, secretName, recipient string) (*models.SecretShare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, secretOwner, secretType, secretName, recipient)
+	ret0, _ := ret[0].(*models.SecretShare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockShareReaderMockRecorder) Get(ctx, secretOwner, secretType, secretName, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockShareReader)(nil).Get), ctx, secretOwner, secretType, secretName, recipient)
+}
+
+// ListByRecipient mocks base method.
+func (m *MockShareReader) ListByRecipient(ctx context.Context, recipient string) ([]*models.SharedSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByRecipient", ctx, recipient)
+	ret0, _ := ret[0].([]*models.SharedSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByRecipient indicates an expected call of ListByRecipient.
+func (mr *MockShareReaderMockRecorder) ListByRecipient(ctx, recipient interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByRecipient", reflect.TypeOf((*MockShareReader)(nil).ListByRecipient), ctx, recipient)
+}
+
+// MockSecretCiphertextUpdater is a mock of SecretCiphertextUpdater interface.
+type MockSecretCiphertextUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecretCiphertextUpdaterMockRecorder
+}
+
+// MockSecretCiphertextUpdaterMockRecorder is the mock recorder for MockSecretCiphertextUpdater.
+type MockSecretCiphertextUpdaterMockRecorder struct {
+	mock *MockSecretCiphertextUpdater
+}
+
+// NewMockSecretCiphertextUpdater creates a new mock instance.
+func NewMockSecretCiphertextUpdater(ctrl *gomock.Controller) *MockSecretCiphertextUpdater {
+	mock := &MockSecretCiphertextUpdater{ctrl: ctrl}
+	mock.recorder = &MockSecretCiphertextUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecretCiphertextUpdater) EXPECT() *MockSecretCiphertextUpdaterMockRecorder {
+	return m.recorder
+}
+
+// UpdateCiphertext mocks base method.
+func (m *MockSecretCiphertextUpdater) UpdateCiphertext(ctx context.Context, secretOwner, secretType, secretName string, ciphertext []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCiphertext", ctx, secretOwner, secretType, secretName, ciphertext)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCiphertext indicates an expected call of UpdateCiphertext.
+func (mr *MockSecretCiphertextUpdaterMockRecorder) UpdateCiphertext(ctx, secretOwner, secretType, secretName, ciphertext interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCiphertext", reflect.TypeOf((*MockSecretCiphertextUpdater)(nil).UpdateCiphertext), ctx, secretOwner, secretType, secretName, ciphertext)
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// testCertificatePEM returns a self-signed certificate and its key identifier.
+func testCertificatePEM(t *testing.T) (string, string) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyID, err := cryptor.CertificateKeyID(cert)
+	require.NoError(t, err)
+
+	return string(cert), keyID
+}
+
+func TestShareService_Share(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockShareWriter(ctrl)
+	mockSecrets := NewMockSecretReader(ctrl)
+	mockUsers := NewMockUserGetter(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewShareService(mockWriter, nil, mockSecrets, nil, mockUsers, WithShareAuditor(mockAuditor))
+
+	ctx := context.Background()
+	cert, keyID := testCertificatePEM(t)
+	notFound := fmt.Errorf("failed to get: %w", sql.ErrNoRows)
+
+	t.Run("success", func(t *testing.T) {
+		mockSecrets.EXPECT().Get(ctx, "alice", "text", "wifi").Return(&models.Secret{}, nil)
+		mockUsers.EXPECT().Get(ctx, "bob").Return(&models.User{Username: "bob", Certificate: cert}, nil)
+		mockWriter.EXPECT().Save(ctx, "alice", "text", "wifi", "bob", []byte("wrapped"), keyID, models.SharePermissionRead).Return(nil)
+		mockAuditor.EXPECT().Record(ctx, "alice", models.AuditActionSecretShare, "text/wifi -> bob")
+
+		require.NoError(t, service.Share(ctx, "alice", "text", "wifi", "bob", []byte("wrapped"), keyID, models.SharePermissionRead))
+	})
+
+	t.Run("invalid permission", func(t *testing.T) {
+		err := service.Share(ctx, "alice", "text", "wifi", "bob", []byte("wrapped"), keyID, "admin")
+		assert.ErrorIs(t, err, ErrInvalidPermission)
+	})
+
+	t.Run("share with self", func(t *testing.T) {
+		err := service.Share(ctx, "alice", "text", "wifi", "alice", []byte("wrapped"), keyID, models.SharePermissionRead)
+		assert.ErrorIs(t, err, ErrShareWithSelf)
+	})
+
+	t.Run("secret not found", func(t *testing.T) {
+		mockSecrets.EXPECT().Get(ctx, "alice", "text", "missing").Return(nil, notFound)
+		err := service.Share(ctx, "alice", "text", "missing", "bob", []byte("wrapped"), keyID, models.SharePermissionRead)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("recipient not found", func(t *testing.T) {
+		mockSecrets.EXPECT().Get(ctx, "alice", "text", "wifi").Return(&models.Secret{}, nil)
+		mockUsers.EXPECT().Get(ctx, "nobody").Return(nil, notFound)
+		err := service.Share(ctx, "alice", "text", "wifi", "nobody", []byte("wrapped"), keyID, models.SharePermissionRead)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("recipient without certificate", func(t *testing.T) {
+		mockSecrets.EXPECT().Get(ctx, "alice", "text", "wifi").Return(&models.Secret{}, nil)
+		mockUsers.EXPECT().Get(ctx, "carol").Return(&models.User{Username: "carol"}, nil)
+		err := service.Share(ctx, "alice", "text", "wifi", "carol", []byte("wrapped"), keyID, models.SharePermissionRead)
+		assert.ErrorIs(t, err, ErrNoCertificate)
+	})
+
+	t.Run("wrapped for another certificate", func(t *testing.T) {
+		mockSecrets.EXPECT().Get(ctx, "alice", "text", "wifi").Return(&models.Secret{}, nil)
+		mockUsers.EXPECT().Get(ctx, "bob").Return(&models.User{Username: "bob", Certificate: cert}, nil)
+		err := service.Share(ctx, "alice", "text", "wifi", "bob", []byte("wrapped"), "stale", models.SharePermissionRead)
+		assert.ErrorIs(t, err, ErrRecipientKeyOutdated)
+	})
+}
+
+func TestShareService_Unshare(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWriter := NewMockShareWriter(ctrl)
+	service := NewShareService(mockWriter, nil, nil, nil, nil)
+
+	ctx := context.Background()
+
+	mockWriter.EXPECT().Delete(ctx, "alice", "text", "wifi", "bob").Return(nil)
+	require.NoError(t, service.Unshare(ctx, "alice", "text", "wifi", "bob"))
+
+	mockWriter.EXPECT().Delete(ctx, "alice", "text", "wifi", "bob").Return(fmt.Errorf("failed to delete share: %w", sql.ErrNoRows))
+	assert.ErrorIs(t, service.Unshare(ctx, "alice", "text", "wifi", "bob"), ErrShareNotFound)
+}
+
+func TestShareService_ListSharedWithMe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReader := NewMockShareReader(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewShareService(nil, mockReader, nil, nil, nil, WithShareAuditor(mockAuditor))
+
+	ctx := context.Background()
+	shared := []*models.SharedSecret{{Secret: models.Secret{SecretOwner: "alice", SecretName: "wifi"}, Permission: models.SharePermissionRead}}
+
+	mockReader.EXPECT().ListByRecipient(ctx, "bob").Return(shared, nil)
+	mockAuditor.EXPECT().Record(ctx, "bob", models.AuditActionSharedList, "")
+	got, err := service.ListSharedWithMe(ctx, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, shared, got)
+
+	mockReader.EXPECT().ListByRecipient(ctx, "bob").Return(nil, errors.New("db error"))
+	_, err = service.ListSharedWithMe(ctx, "bob")
+	assert.Error(t, err)
+}
+
+func TestShareService_SaveShared(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockReader := NewMockShareReader(ctrl)
+	mockUpdater := NewMockSecretCiphertextUpdater(ctrl)
+	mockAuditor := NewMockAuditor(ctrl)
+	service := NewShareService(nil, mockReader, nil, mockUpdater, nil, WithShareAuditor(mockAuditor))
+
+	ctx := context.Background()
+
+	t.Run("read-write", func(t *testing.T) {
+		mockReader.EXPECT().Get(ctx, "alice", "text", "wifi", "bob").Return(&models.SecretShare{Permission: models.SharePermissionReadWrite}, nil)
+		mockUpdater.EXPECT().UpdateCiphertext(ctx, "alice", "text", "wifi", []byte("ct")).Return(nil)
+		mockAuditor.EXPECT().Record(ctx, "bob", models.AuditActionSharedSave, "alice:text/wifi")
+
+		require.NoError(t, service.SaveShared(ctx, "bob", "alice", "text", "wifi", []byte("ct")))
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		mockReader.EXPECT().Get(ctx, "alice", "text", "wifi", "bob").Return(&models.SecretShare{Permission: models.SharePermissionRead}, nil)
+		assert.ErrorIs(t, service.SaveShared(ctx, "bob", "alice", "text", "wifi", []byte("ct")), ErrShareReadOnly)
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		mockReader.EXPECT().Get(ctx, "alice", "text", "wifi", "bob").Return(&models.SecretShare{Permission: models.SharePermissionReadWrite, Stale: true}, nil)
+		assert.ErrorIs(t, service.SaveShared(ctx, "bob", "alice", "text", "wifi", []byte("ct")), ErrShareStale)
+	})
+
+	t.Run("not shared", func(t *testing.T) {
+		mockReader.EXPECT().Get(ctx, "alice", "text", "wifi", "carol").Return(nil, fmt.Errorf("failed to get share: %w", sql.ErrNoRows))
+		assert.ErrorIs(t, service.SaveShared(ctx, "carol", "alice", "text", "wifi", []byte("ct")), ErrShareNotFound)
+	})
+}
@@ -0,0 +1,151 @@
+package shell
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// errUnterminatedQuote is returned by splitArgs for a quote that is not closed.
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+// splitArgs splits a command line into arguments separated by spaces. Single and double quotes
+// group words, and a backslash outside single quotes escapes the next character.
+func splitArgs(line string) ([]string, error) {
+	args, open, _ := scanArgs(line)
+	if open {
+		return nil, errUnterminatedQuote
+	}
+	return args, nil
+}
+
+// scanArgs splits line like splitArgs. It also reports whether a quote is left open
+// and whether the line ends within an argument rather than after a separator.
+func scanArgs(line string) (args []string, open bool, inArg bool) {
+	var (
+		b       strings.Builder
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				b.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, b.String())
+				b.Reset()
+				inArg = false
+			}
+		default:
+			b.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, b.String())
+	}
+	return args, quote != 0 || escaped, inArg
+}
+
+// quoteArg quotes s when it would not be read back as one argument.
+func quoteArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t'\"\\") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Complete completes the last argument of line. It returns the completed line and, when
+// more than one candidate is left, the candidates.
+func (s *Shell) Complete(line string) (string, []string) {
+	args, open, inArg := scanArgs(line)
+	if open {
+		return line, nil
+	}
+
+	prefix := ""
+	if inArg {
+		prefix = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+
+	var candidates []string
+	for _, c := range s.candidates(args) {
+		if strings.HasPrefix(c, prefix) {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return line, nil
+	}
+
+	// Replace the argument being completed, quoting the completion as needed.
+	head := make([]string, 0, len(args)+1)
+	for _, a := range args {
+		head = append(head, quoteArg(a))
+	}
+	if len(candidates) == 1 {
+		return strings.Join(append(head, quoteArg(candidates[0])), " ") + " ", nil
+	}
+
+	common := commonPrefix(candidates)
+	if len(common) > len(prefix) && !strings.ContainsAny(common, " \t'\"\\") {
+		return strings.Join(append(head, common), " "), candidates
+	}
+	return line, candidates
+}
+
+// candidates returns the completions of the argument following args.
+func (s *Shell) candidates(args []string) []string {
+	if len(args) == 0 {
+		names := make([]string, 0, len(commands))
+		for _, cmd := range commands {
+			names = append(names, cmd.name)
+		}
+		return names
+	}
+
+	cmd := findCommand(args[0])
+	n := len(args) - 1
+	if cmd == nil || n >= len(cmd.args) {
+		return nil
+	}
+	switch cmd.args[n] {
+	case argCommand:
+		return s.candidates(nil)
+	case argType:
+		return secretTypes
+	case argName:
+		return s.names[args[n]]
+	}
+	return nil
+}
+
+// commonPrefix returns the longest prefix shared by all of ss.
+func commonPrefix(ss []string) string {
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	// Do not end in the middle of a multi-byte character.
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix
+}
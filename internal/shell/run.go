@@ -0,0 +1,272 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/terminal"
+)
+
+// prompt is shown while the shell is unlocked.
+const prompt = "gophkeeper> "
+
+// Run reads commands from in and writes their output to out until the user exits or in ends.
+// On a terminal the line is edited in raw mode, with history on ↑/↓ and completion on Tab;
+// otherwise lines are read as they come, without prompts. After the idle timeout without
+// input the keys are dropped and the unlock prompt is shown.
+func (s *Shell) Run(ctx context.Context, in io.Reader, out io.Writer) error {
+	ed := &editor{out: out, nl: "\n"}
+	if f, ok := in.(*os.File); ok && terminal.IsTerminal(int(f.Fd())) {
+		restore, err := terminal.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return err
+		}
+		defer restore()
+		ed.interactive = true
+		ed.nl = "\r\n"
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		ed.print(err.Error() + "\n")
+	}
+
+	keys := make(chan terminal.Key)
+	readErr := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	// The reader is left blocked in Read when Run returns before in ends; in is stdin,
+	// which is not read by anything else afterwards.
+	go readKeys(in, keys, readErr, stop)
+
+	var idle <-chan time.Time
+	var timer *time.Timer
+	if s.idleTimeout > 0 {
+		timer = time.NewTimer(s.idleTimeout)
+		defer timer.Stop()
+		idle = timer.C
+	}
+
+	s.startLine(ed)
+	for !s.done {
+		select {
+		case <-ctx.Done():
+			ed.print("\n")
+			return nil
+
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+
+		case <-idle:
+			if s.Locked() {
+				continue
+			}
+			s.Lock()
+			ed.print(fmt.Sprintf("\nLocked after %s of inactivity.\n", s.idleTimeout))
+			if s.unlock == nil {
+				ed.print("Start the shell again to continue.\n")
+				return nil
+			}
+			s.startLine(ed)
+
+		case k := <-keys:
+			if timer != nil {
+				timer.Reset(s.idleTimeout)
+			}
+			s.handleKey(ctx, ed, k)
+		}
+	}
+	return nil
+}
+
+// readKeys sends the key presses read from in to keys until in fails or stop is closed.
+func readKeys(in io.Reader, keys chan<- terminal.Key, errc chan<- error, stop <-chan struct{}) {
+	buf := make([]byte, 256)
+	for {
+		n, err := in.Read(buf)
+		for _, k := range terminal.DecodeKeys(buf[:n]) {
+			select {
+			case keys <- k:
+			case <-stop:
+				return
+			}
+		}
+		if err != nil {
+			errc <- err
+			return
+		}
+	}
+}
+
+// startLine starts editing a new line: a command, or the unlock secret while locked.
+func (s *Shell) startLine(ed *editor) {
+	if s.Locked() {
+		ed.start(s.unlockPrompt, s.unlockHidden)
+		return
+	}
+	ed.start(prompt, false)
+}
+
+// handleKey applies a key press to the line being edited and runs the line on Enter.
+func (s *Shell) handleKey(ctx context.Context, ed *editor, k terminal.Key) {
+	switch k.Code {
+	case terminal.KeyRune:
+		ed.insert(k.Rune)
+	case terminal.KeyBackspace:
+		ed.backspace()
+	case terminal.KeyTab:
+		if !ed.interactive {
+			ed.insert(' ')
+			return
+		}
+		if s.Locked() {
+			return
+		}
+		line, candidates := s.Complete(string(ed.line))
+		if len(candidates) > 0 {
+			ed.print("\n" + strings.Join(candidates, "  ") + "\n")
+		}
+		ed.set(line)
+	case terminal.KeyUp:
+		if !s.Locked() {
+			ed.historyMove(-1)
+		}
+	case terminal.KeyDown:
+		if !s.Locked() {
+			ed.historyMove(1)
+		}
+	case terminal.KeyCtrlC:
+		ed.print("^C\n")
+		s.startLine(ed)
+	case terminal.KeyCtrlD:
+		if len(ed.line) == 0 {
+			ed.print("\n")
+			s.done = true
+		}
+	case terminal.KeyEnter:
+		line := string(ed.line)
+		ed.print("\n")
+		s.submit(ctx, ed, line)
+		if !s.done {
+			s.startLine(ed)
+		}
+	}
+}
+
+// submit runs a command line, or unlocks the shell with it while locked.
+func (s *Shell) submit(ctx context.Context, ed *editor, line string) {
+	if s.Locked() {
+		if line == "" {
+			return
+		}
+		if err := s.Unlock(ctx, line); err != nil {
+			ed.print("Unlock failed: " + err.Error() + "\n")
+			return
+		}
+		ed.print("Unlocked\n")
+		return
+	}
+
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	ed.remember(line)
+	out, err := s.Execute(ctx, line)
+	if err != nil {
+		ed.print(err.Error() + "\n")
+		return
+	}
+	ed.print(out)
+}
+
+// editor edits one line at a time, echoing it on an interactive terminal.
+type editor struct {
+	out         io.Writer
+	interactive bool
+	// nl ends lines; a terminal in raw mode needs a carriage return.
+	nl string
+
+	prompt  string
+	hidden  bool
+	line    []rune
+	history []string
+	// histPos is the history entry shown, len(history) for the new line.
+	histPos int
+}
+
+// print writes s, ending its lines with nl.
+func (e *editor) print(s string) {
+	fmt.Fprint(e.out, strings.ReplaceAll(s, "\n", e.nl))
+}
+
+// start shows prompt for a new empty line, whose input is not echoed when hidden.
+func (e *editor) start(prompt string, hidden bool) {
+	e.prompt = prompt
+	e.hidden = hidden
+	e.line = nil
+	e.histPos = len(e.history)
+	e.redraw()
+}
+
+// set replaces the line.
+func (e *editor) set(line string) {
+	e.line = []rune(line)
+	e.redraw()
+}
+
+// redraw draws the prompt and the line over the current terminal line.
+func (e *editor) redraw() {
+	if !e.interactive {
+		return
+	}
+	shown := string(e.line)
+	if e.hidden {
+		shown = ""
+	}
+	fmt.Fprint(e.out, "\r\x1b[K"+e.prompt+shown)
+}
+
+func (e *editor) insert(r rune) {
+	e.line = append(e.line, r)
+	if e.interactive && !e.hidden {
+		fmt.Fprint(e.out, string(r))
+	}
+}
+
+func (e *editor) backspace() {
+	if len(e.line) == 0 {
+		return
+	}
+	e.line = e.line[:len(e.line)-1]
+	if e.interactive && !e.hidden {
+		fmt.Fprint(e.out, "\b \b")
+	}
+}
+
+// remember adds line to the history unless it repeats the last entry.
+func (e *editor) remember(line string) {
+	if n := len(e.history); n == 0 || e.history[n-1] != line {
+		e.history = append(e.history, line)
+	}
+}
+
+// historyMove shows the history entry delta entries away from the one shown.
+func (e *editor) historyMove(delta int) {
+	pos := min(max(e.histPos+delta, 0), len(e.history))
+	if pos == e.histPos {
+		return
+	}
+	e.histPos = pos
+	if pos == len(e.history) {
+		e.set("")
+		return
+	}
+	e.set(e.history[pos])
+}
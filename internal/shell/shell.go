@@ -0,0 +1,410 @@
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/client"
+	"github.com/sbilibin2017/gophkeeper/internal/generator"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// DefaultIdleTimeout is how long the shell stays unlocked without input.
+const DefaultIdleTimeout = 5 * time.Minute
+
+// Errors of the shell.
+var (
+	ErrLocked         = errors.New("shell is locked")
+	ErrWrongSecret    = errors.New("the keys do not match the keys the shell was started with")
+	ErrUnknownCommand = errors.New("unknown command, type help for the list of commands")
+)
+
+// Keys encrypt and decrypt secrets while the shell is unlocked.
+type Keys interface {
+	client.Encryptor
+	client.Decryptor
+}
+
+// keyIdentifier is implemented by keys that know the identifier of their key pair, like *cryptor.Cryptor.
+type keyIdentifier interface {
+	KeyID() (string, error)
+}
+
+// UnlockFunc derives the keys from the secret typed at the unlock prompt.
+type UnlockFunc func(ctx context.Context, secret string) (Keys, error)
+
+// SyncFunc uploads the secrets of the local store to the server.
+type SyncFunc func(ctx context.Context) error
+
+// Shell is a session of commands run against the local store with keys unlocked once.
+// The keys are dropped by Lock and restored by Unlock.
+type Shell struct {
+	token        string
+	lister       client.ClientLister
+	saver        client.ClientSaver
+	keys         Keys
+	keyID        string
+	unlock       UnlockFunc
+	unlockPrompt string
+	unlockHidden bool
+	sync         SyncFunc
+	idleTimeout  time.Duration
+
+	// names caches the stored secret names by type for completion.
+	names map[string][]string
+	done  bool
+}
+
+// Opt configures a Shell.
+type Opt func(*Shell)
+
+// WithStore sets the local store secrets are listed from and saved to.
+func WithStore(lister client.ClientLister, saver client.ClientSaver) Opt {
+	return func(s *Shell) {
+		s.lister = lister
+		s.saver = saver
+	}
+}
+
+// WithKeys sets the keys the shell starts unlocked with.
+func WithKeys(keys Keys) Opt {
+	return func(s *Shell) {
+		s.keys = keys
+	}
+}
+
+// WithUnlock sets how the shell is unlocked after it locked: the prompt, whether the
+// input is hidden and the function deriving the keys from it.
+func WithUnlock(prompt string, hidden bool, unlock UnlockFunc) Opt {
+	return func(s *Shell) {
+		s.unlockPrompt = prompt
+		s.unlockHidden = hidden
+		s.unlock = unlock
+	}
+}
+
+// WithSync enables the sync command.
+func WithSync(sync SyncFunc) Opt {
+	return func(s *Shell) {
+		s.sync = sync
+	}
+}
+
+// WithIdleTimeout sets how long the shell stays unlocked without input; 0 never locks.
+func WithIdleTimeout(d time.Duration) Opt {
+	return func(s *Shell) {
+		s.idleTimeout = d
+	}
+}
+
+// New returns the shell of the owner of token.
+func New(token string, opts ...Opt) *Shell {
+	s := &Shell{token: token, idleTimeout: DefaultIdleTimeout}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if k, ok := s.keys.(keyIdentifier); ok {
+		s.keyID, _ = k.KeyID()
+	}
+	return s
+}
+
+// Done reports whether the user exited.
+func (s *Shell) Done() bool {
+	return s.done
+}
+
+// Locked reports whether the keys were dropped.
+func (s *Shell) Locked() bool {
+	return s.keys == nil
+}
+
+// Lock drops the keys.
+func (s *Shell) Lock() {
+	s.keys = nil
+}
+
+// Unlock derives the keys from secret. The keys must belong to the key pair the shell was started with.
+func (s *Shell) Unlock(ctx context.Context, secret string) error {
+	if s.unlock == nil {
+		return errors.New("unlocking is not configured")
+	}
+	keys, err := s.unlock(ctx, secret)
+	if err != nil {
+		return err
+	}
+	if s.keyID != "" {
+		k, ok := keys.(keyIdentifier)
+		if !ok {
+			return ErrWrongSecret
+		}
+		if id, err := k.KeyID(); err != nil || id != s.keyID {
+			return ErrWrongSecret
+		}
+	}
+	s.keys = keys
+	return nil
+}
+
+// Refresh reloads the secret names used for completion.
+func (s *Shell) Refresh(ctx context.Context) error {
+	secrets, err := s.lister.List(ctx, s.token)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %w", err)
+	}
+	s.names = make(map[string][]string)
+	for _, secret := range secrets {
+		s.names[secret.SecretType] = append(s.names[secret.SecretType], secret.SecretName)
+	}
+	for _, names := range s.names {
+		slices.Sort(names)
+	}
+	return nil
+}
+
+// command is a shell command.
+type command struct {
+	name    string
+	usage   string
+	summary string
+	// args lists what each argument completes to.
+	args      []argKind
+	minArgs   int
+	maxArgs   int
+	needsKeys bool
+	run       func(ctx context.Context, s *Shell, args []string) (string, error)
+}
+
+// argKind is what an argument of a command completes to.
+type argKind int
+
+const (
+	argNone argKind = iota
+	argCommand
+	argType
+	// argName completes the names of secrets of the type given by the previous argument.
+	argName
+)
+
+// secretTypes are the secret types in completion order.
+var secretTypes = []string{models.SecretTypeBankCard, models.SecretTypeBinary, models.SecretTypeSSHKey, models.SecretTypeText, models.SecretTypeUser}
+
+// commands are the shell commands in help order. It is filled in init, as help refers to it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "help", usage: "help [command]", summary: "Show the commands or the usage of one", args: []argKind{argCommand}, maxArgs: 1, run: runHelp},
+		{name: "list", usage: "list [type]", summary: "List the stored secrets", args: []argKind{argType}, maxArgs: 1, run: runList},
+		{name: "show", usage: "show <type> <name>", summary: "Decrypt and show a secret", args: []argKind{argType, argName}, minArgs: 2, maxArgs: 2, needsKeys: true, run: runShow},
+		{name: "add-bankcard", usage: "add-bankcard <name> <number> <owner> <exp> <cvv> [meta]", summary: "Add a bank card", minArgs: 5, maxArgs: 6, needsKeys: true, run: runAdd(models.SecretTypeBankCard)},
+		{name: "add-text", usage: "add-text <name> <data> [meta]", summary: "Add a text secret", minArgs: 2, maxArgs: 3, needsKeys: true, run: runAdd(models.SecretTypeText)},
+		{name: "add-binary", usage: "add-binary <name> <base64 data> [meta]", summary: "Add a binary secret", minArgs: 2, maxArgs: 3, needsKeys: true, run: runAdd(models.SecretTypeBinary)},
+		{name: "add-user", usage: "add-user <name> <username> <password> [meta]", summary: "Add a login", minArgs: 3, maxArgs: 4, needsKeys: true, run: runAdd(models.SecretTypeUser)},
+		{name: "generate", usage: "generate [length]", summary: "Generate a password", maxArgs: 1, run: runGenerate},
+		{name: "sync", usage: "sync", summary: "Sync the local store with the server", run: runSync},
+		{name: "lock", usage: "lock", summary: "Drop the keys until unlocked again", run: runLock},
+		{name: "exit", usage: "exit", summary: "Leave the shell", run: runExit},
+	}
+}
+
+// findCommand returns the command called name; quit is an alias of exit.
+func findCommand(name string) *command {
+	if name == "quit" {
+		name = "exit"
+	}
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// Execute runs a command line and returns its output.
+func (s *Shell) Execute(ctx context.Context, line string) (string, error) {
+	args, err := splitArgs(line)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", nil
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return "", fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+	}
+	args = args[1:]
+	if len(args) < cmd.minArgs || len(args) > cmd.maxArgs {
+		return "", fmt.Errorf("usage: %s", cmd.usage)
+	}
+	if cmd.needsKeys && s.Locked() {
+		return "", ErrLocked
+	}
+	return cmd.run(ctx, s, args)
+}
+
+func runHelp(_ context.Context, _ *Shell, args []string) (string, error) {
+	if len(args) == 1 {
+		cmd := findCommand(args[0])
+		if cmd == nil {
+			return "", fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+		}
+		return fmt.Sprintf("%s\n  %s\n", cmd.usage, cmd.summary), nil
+	}
+
+	var b strings.Builder
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	b.WriteString("Arguments with spaces are quoted. Tab completes commands, types and names.\n")
+	return b.String(), nil
+}
+
+func runList(ctx context.Context, s *Shell, args []string) (string, error) {
+	if err := s.Refresh(ctx); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, secretType := range secretTypes {
+		if len(args) == 1 && args[0] != secretType {
+			continue
+		}
+		for _, name := range s.names[secretType] {
+			fmt.Fprintf(&b, "%-9s %s\n", secretType, name)
+		}
+	}
+	if b.Len() == 0 {
+		return "No secrets\n", nil
+	}
+	return b.String(), nil
+}
+
+// encryptor returns the keys, encrypting secrets already in the store with their data key
+// when the store can look them up and the keys can reuse it, like *cryptor.Cryptor.
+func (s *Shell) encryptor(ctx context.Context) client.Encryptor {
+	getter, ok := s.lister.(client.ServerGetter)
+	if !ok {
+		return s.keys
+	}
+	reencryptor, ok := s.keys.(client.Reencryptor)
+	if !ok {
+		return s.keys
+	}
+	return client.StoredKeyEncryptor(ctx, getter, reencryptor, s.token)
+}
+
+// storedUser returns the stored user secret name, nil when there is none.
+func (s *Shell) storedUser(ctx context.Context, name string) (*models.UserPayload, error) {
+	secrets, err := client.ClientGetSecrets(ctx, s.lister, s.keys, s.token)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		if payload, ok := secret.Payload.(models.UserPayload); ok && secret.SecretName == name {
+			return &payload, nil
+		}
+	}
+	return nil, nil
+}
+
+func runShow(ctx context.Context, s *Shell, args []string) (string, error) {
+	secrets, err := client.ClientGetSecrets(ctx, s.lister, s.keys, s.token)
+	if err != nil {
+		return "", err
+	}
+	for _, secret := range secrets {
+		if secret.SecretType == args[0] && secret.SecretName == args[1] {
+			out, err := json.MarshalIndent(secret.Payload, "", "  ")
+			if err != nil {
+				return "", err
+			}
+			return string(out) + "\n", nil
+		}
+	}
+	return "", fmt.Errorf("secret %s/%s not found", args[0], args[1])
+}
+
+// runAdd returns the command adding a secret of secretType with its add function.
+func runAdd(secretType string) func(ctx context.Context, s *Shell, args []string) (string, error) {
+	return func(ctx context.Context, s *Shell, args []string) (string, error) {
+		// The meta argument is optional.
+		args = append(args, "")
+		encryptor := s.encryptor(ctx)
+		var err error
+		switch secretType {
+		case models.SecretTypeBankCard:
+			err = client.ClientAddBankcard(ctx, s.saver, encryptor, s.token, args[0], args[1], args[2], args[3], args[4], args[5])
+		case models.SecretTypeText:
+			err = client.ClientAddText(ctx, s.saver, encryptor, s.token, args[0], args[1], args[2])
+		case models.SecretTypeBinary:
+			err = client.ClientAddBinary(ctx, s.saver, encryptor, s.token, args[0], args[1], args[2])
+		case models.SecretTypeUser:
+			var prev *models.UserPayload
+			if prev, err = s.storedUser(ctx, args[0]); err == nil {
+				err = client.ClientAddUser(ctx, s.saver, encryptor, s.token, args[0], args[1], args[2], "", "", args[3], prev, time.Now())
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := s.Refresh(ctx); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Saved %s/%s\n", secretType, args[0]), nil
+	}
+}
+
+func runGenerate(_ context.Context, _ *Shell, args []string) (string, error) {
+	length := generator.DefaultLength
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return "", fmt.Errorf("invalid length: %s", args[0])
+		}
+		length = n
+	}
+	g, err := generator.New(generator.WithLength(length))
+	if err != nil {
+		return "", err
+	}
+	password, entropy, err := client.ClientGeneratePassword(g)
+	if err != nil {
+		return "", err
+	}
+	return password + "\n" + entropy + "\n", nil
+}
+
+func runSync(ctx context.Context, s *Shell, _ []string) (string, error) {
+	if s.sync == nil {
+		return "", errors.New("sync is not configured, start the shell with --server-url")
+	}
+	if err := s.sync(ctx); err != nil {
+		return "", fmt.Errorf("sync failed: %w", err)
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return "", err
+	}
+	return "Synced\n", nil
+}
+
+func runLock(_ context.Context, s *Shell, _ []string) (string, error) {
+	if s.unlock == nil {
+		return "", errors.New("the shell cannot be unlocked again, use exit instead")
+	}
+	s.Lock()
+	return "Locked\n", nil
+}
+
+func runExit(_ context.Context, s *Shell, _ []string) (string, error) {
+	s.done = true
+	return "", nil
+}
@@ -0,0 +1,233 @@
+package shell
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/jwt"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a local store kept in memory.
+type memoryStore struct {
+	secrets []*models.Secret
+}
+
+func (s *memoryStore) List(_ context.Context, _ string) ([]*models.Secret, error) {
+	return s.secrets, nil
+}
+
+func (s *memoryStore) Save(
+	_ context.Context,
+	_ string,
+	secretName string,
+	secretType string,
+	ciphertext []byte,
+	aesKeyEnc []byte,
+	keyID string,
+	recipients models.Recipients,
+) error {
+	secret := &models.Secret{
+		SecretName: secretName,
+		SecretType: secretType,
+		Ciphertext: ciphertext,
+		AESKeyEnc:  aesKeyEnc,
+		KeyID:      keyID,
+		Recipients: recipients,
+	}
+	for i, existing := range s.secrets {
+		if existing.SecretName == secretName && existing.SecretType == secretType {
+			s.secrets[i] = secret
+			return nil
+		}
+	}
+	s.secrets = append(s.secrets, secret)
+	return nil
+}
+
+func vaultKeys(t *testing.T) (*cryptor.Cryptor, []byte) {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	c, err := cryptor.New(cryptor.WithVaultKey(key))
+	require.NoError(t, err)
+	return c, key
+}
+
+// newTestShell returns a shell unlocked again by the secret "correct"; other secrets derive other keys.
+func newTestShell(t *testing.T, opts ...Opt) (*Shell, *memoryStore) {
+	t.Helper()
+	keys, key := vaultKeys(t)
+	other, _ := vaultKeys(t)
+	token, err := jwt.New(jwt.WithSecret("secret"), jwt.WithLifetime(time.Hour)).Generate("alice")
+	require.NoError(t, err)
+
+	unlock := func(_ context.Context, secret string) (Keys, error) {
+		if secret == "correct" {
+			return cryptor.New(cryptor.WithVaultKey(key))
+		}
+		return other, nil
+	}
+
+	store := &memoryStore{}
+	s := New(token, append([]Opt{WithStore(store, store), WithKeys(keys), WithUnlock("Master password: ", true, unlock)}, opts...)...)
+	return s, store
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "", want: nil},
+		{line: "  list  text ", want: []string{"list", "text"}},
+		{line: `add-text "my note" 'it''s'`, want: []string{"add-text", "my note", "its"}},
+		{line: `add-text note a\ b "say \"hi\""`, want: []string{"add-text", "note", "a b", `say "hi"`}},
+		{line: `show text ''`, want: []string{"show", "text", ""}},
+	}
+	for _, tt := range tests {
+		got, err := splitArgs(tt.line)
+		require.NoError(t, err, tt.line)
+		assert.Equal(t, tt.want, got, tt.line)
+	}
+
+	_, err := splitArgs(`add-text "open`)
+	assert.ErrorIs(t, err, errUnterminatedQuote)
+}
+
+func TestShell_Execute(t *testing.T) {
+	ctx := context.Background()
+	s, store := newTestShell(t)
+
+	out, err := s.Execute(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, "No secrets\n", out)
+
+	out, err = s.Execute(ctx, `add-user "git hub" alice hunter2`)
+	require.NoError(t, err)
+	assert.Equal(t, "Saved user/git hub\n", out)
+	_, err = s.Execute(ctx, "add-text note hello")
+	require.NoError(t, err)
+	require.Len(t, store.secrets, 2)
+
+	out, err = s.Execute(ctx, "list")
+	require.NoError(t, err)
+	assert.Equal(t, "text      note\nuser      git hub\n", out)
+
+	out, err = s.Execute(ctx, "show user 'git hub'")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"password": "hunter2"`)
+
+	_, err = s.Execute(ctx, "show user gitlab")
+	assert.EqualError(t, err, "secret user/gitlab not found")
+	_, err = s.Execute(ctx, "add-text note")
+	assert.EqualError(t, err, "usage: add-text <name> <data> [meta]")
+	_, err = s.Execute(ctx, "delete note")
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+
+	out, err = s.Execute(ctx, "generate 24")
+	require.NoError(t, err)
+	assert.Len(t, strings.SplitN(out, "\n", 2)[0], 24)
+
+	_, err = s.Execute(ctx, "sync")
+	assert.Error(t, err, "sync is not configured")
+
+	_, err = s.Execute(ctx, "quit")
+	require.NoError(t, err)
+	assert.True(t, s.Done())
+}
+
+func TestShell_LockUnlock(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestShell(t)
+	_, err := s.Execute(ctx, "add-text note hello")
+	require.NoError(t, err)
+
+	_, err = s.Execute(ctx, "lock")
+	require.NoError(t, err)
+	assert.True(t, s.Locked())
+	_, err = s.Execute(ctx, "show text note")
+	assert.ErrorIs(t, err, ErrLocked)
+	out, err := s.Execute(ctx, "list")
+	require.NoError(t, err, "names are listed without the keys")
+	assert.Contains(t, out, "note")
+
+	assert.ErrorIs(t, s.Unlock(ctx, "wrong"), ErrWrongSecret)
+	assert.True(t, s.Locked())
+
+	require.NoError(t, s.Unlock(ctx, "correct"))
+	out, err = s.Execute(ctx, "show text note")
+	require.NoError(t, err)
+	assert.Contains(t, out, "hello")
+}
+
+func TestShell_Complete(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestShell(t)
+	for _, line := range []string{"add-user github a b", "add-user gitlab a b", "add-user 'my bank' a b", "add-text notes x"} {
+		_, err := s.Execute(ctx, line)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		line       string
+		want       string
+		candidates []string
+	}{
+		{line: "sh", want: "show "},
+		{line: "add-", want: "add-", candidates: []string{"add-bankcard", "add-text", "add-binary", "add-user"}},
+		{line: "show u", want: "show user "},
+		{line: "show user g", want: "show user git", candidates: []string{"github", "gitlab"}},
+		{line: "show user gith", want: "show user github "},
+		{line: "show user m", want: "show user 'my bank' "},
+		{line: "show text ", want: "show text notes "},
+		{line: "help li", want: "help list "},
+		{line: "show user github ", want: "show user github "},
+		{line: "unknown x", want: "unknown x"},
+	}
+	for _, tt := range tests {
+		got, candidates := s.Complete(tt.line)
+		assert.Equal(t, tt.want, got, tt.line)
+		assert.Equal(t, tt.candidates, candidates, tt.line)
+	}
+}
+
+func TestShell_RunIdleLock(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newTestShell(t, WithIdleTimeout(50*time.Millisecond))
+
+	in, w := io.Pipe()
+	var out strings.Builder
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx, in, &out) }()
+
+	_, err := io.WriteString(w, "add-text note hello\n")
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	// While locked, lines are unlock attempts.
+	_, err = io.WriteString(w, "wrong\ncorrect\nshow text note\nexit\n")
+	require.NoError(t, err)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shell did not exit")
+	}
+
+	text := out.String()
+	assert.Contains(t, text, "Saved text/note\n")
+	assert.Contains(t, text, "Locked after 50ms of inactivity.\n")
+	assert.Contains(t, text, "Unlock failed: "+ErrWrongSecret.Error())
+	assert.Contains(t, text, "Unlocked\n")
+	assert.Contains(t, text, `"data": "hello"`)
+}
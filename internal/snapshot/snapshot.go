@@ -0,0 +1,241 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+	_ "modernc.org/sqlite"
+)
+
+// Snapshots taken into a directory are named gophkeeper-<UTC time>.db,
+// so that their names sort by age.
+const (
+	filePrefix = "gophkeeper-"
+	fileSuffix = ".db"
+	timeLayout = "20060102T150405.000Z"
+)
+
+var (
+	// ErrCorrupt is returned for a snapshot that fails the SQLite integrity check.
+	ErrCorrupt = errors.New("snapshot is corrupt")
+	// ErrNotMigrated is returned for a database with no applied migrations.
+	ErrNotMigrated = errors.New("snapshot has no migration version")
+	// ErrNewerVersion is returned for a snapshot migrated past the newest known migration.
+	ErrNewerVersion = errors.New("snapshot was written by a newer server")
+	// ErrNoDatabaseFile is returned by PathFromDSN for a DSN of an in-memory database.
+	ErrNoDatabaseFile = errors.New("database has no file")
+)
+
+// Info describes a verified snapshot.
+type Info struct {
+	Path string
+	// Version is the migration version of the snapshot.
+	Version int64
+	// Latest is the version of the newest migration in the migrations directory.
+	// The server migrates snapshots of an older version up when it starts.
+	Latest int64
+	Size   int64
+}
+
+// Take writes a consistent copy of the database db to path with VACUUM INTO.
+// The copy is taken in a read transaction, so the server keeps serving while it is written.
+// path must not exist.
+func Take(ctx context.Context, db *sqlx.DB, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("snapshot %s already exists", path)
+	}
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return fmt.Errorf("failed to take snapshot: %w", err)
+	}
+	return nil
+}
+
+// TakeInDir takes a snapshot of db into dir, named after now, and returns its path.
+func TakeInDir(ctx context.Context, db *sqlx.DB, dir string, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	path := filepath.Join(dir, filePrefix+now.UTC().Format(timeLayout)+fileSuffix)
+	if err := Take(ctx, db, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Verify opens the snapshot at path read-only, runs the SQLite integrity check and compares
+// its migration version with the newest migration in migrationsDir.
+func Verify(ctx context.Context, path string, migrationsDir string) (*Info, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+
+	db, err := sqlx.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.GetContext(ctx, &result, "PRAGMA integrity_check"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if result != "ok" {
+		return nil, fmt.Errorf("%w: %s", ErrCorrupt, result)
+	}
+
+	version, err := migrationVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	latest, err := migrations.Last()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	if version > latest.Version {
+		return nil, fmt.Errorf("%w: version %d, newest known %d", ErrNewerVersion, version, latest.Version)
+	}
+
+	return &Info{Path: path, Version: version, Latest: latest.Version, Size: stat.Size()}, nil
+}
+
+// migrationVersion returns the migration version recorded by goose in db the way goose reads it:
+// the newest applied version that was not rolled back later.
+func migrationVersion(ctx context.Context, db *sqlx.DB) (int64, error) {
+	rows, err := db.QueryContext(ctx, "SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC")
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNotMigrated, err)
+	}
+	defer rows.Close()
+
+	rolledBack := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, fmt.Errorf("failed to read migration version: %w", err)
+		}
+		if rolledBack[version] {
+			continue
+		}
+		if applied {
+			if version == 0 {
+				break
+			}
+			return version, nil
+		}
+		rolledBack[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return 0, ErrNotMigrated
+}
+
+// PathFromDSN returns the database file of the SQLite DSN dsn: a path or a file: URI,
+// either followed by ?parameters.
+func PathFromDSN(dsn string) (string, error) {
+	path, query, _ := strings.Cut(dsn, "?")
+	if rest, ok := strings.CutPrefix(path, "file:"); ok {
+		if authority, ok := strings.CutPrefix(rest, "//"); ok {
+			// file://localhost/path and file:///path name an absolute path after the host.
+			_, abs, _ := strings.Cut(authority, "/")
+			rest = "/" + abs
+		}
+		unescaped, err := url.PathUnescape(rest)
+		if err != nil {
+			return "", fmt.Errorf("invalid database DSN %q: %w", dsn, err)
+		}
+		path = unescaped
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid database DSN %q: %w", dsn, err)
+	}
+	if path == "" || path == ":memory:" || params.Get("mode") == "memory" {
+		return "", fmt.Errorf("%w: %q", ErrNoDatabaseFile, dsn)
+	}
+	return path, nil
+}
+
+// Restore replaces the database file at dbPath with a copy of the snapshot at path.
+// The copy is written next to dbPath, the write-ahead log of the replaced database is
+// removed, so that SQLite cannot replay it into the copy, and the copy is renamed over
+// dbPath. The server must not be running.
+func Restore(ctx context.Context, path string, dbPath string) error {
+	db, err := sqlx.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer db.Close()
+
+	tmp := dbPath + ".restore"
+	os.Remove(tmp)
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", tmp); err != nil {
+		return fmt.Errorf("failed to copy snapshot: %w", err)
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return fmt.Errorf("failed to remove %s: %w", dbPath+suffix, err)
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+	return nil
+}
+
+// Prune removes all but the keep newest snapshots taken into dir and returns the removed paths.
+// Other files in dir are left alone. A keep of zero or less keeps every snapshot.
+func Prune(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.Type().IsRegular() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) <= keep {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	var removed []string
+	for _, name := range names[:len(names)-keep] {
+		path := filepath.Join(dir, name)
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove snapshot: %w", err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,155 @@
+package snapshot
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDB creates a database migrated with two migrations and returns it with the migrations directory.
+func setupDB(t *testing.T) (*sqlx.DB, string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	migrationsDir := filepath.Join(dir, "migrations")
+	require.NoError(t, os.Mkdir(migrationsDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(migrationsDir, "00001_create_items.sql"), []byte(
+		"-- +goose Up\nCREATE TABLE items (name TEXT PRIMARY KEY);\n-- +goose Down\nDROP TABLE items;\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(migrationsDir, "00002_add_size.sql"), []byte(
+		"-- +goose Up\nALTER TABLE items ADD COLUMN size INTEGER;\n-- +goose Down\nSELECT 1;\n"), 0o600))
+
+	dbPath := filepath.Join(dir, "server.db")
+	db, err := sqlx.Connect("sqlite", dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	require.NoError(t, goose.SetDialect("sqlite"))
+	require.NoError(t, goose.Up(db.DB, migrationsDir))
+	_, err = db.Exec("INSERT INTO items (name, size) VALUES ('a', 1), ('b', 2)")
+	require.NoError(t, err)
+
+	return db, dbPath, migrationsDir
+}
+
+func TestTakeVerifyRestore(t *testing.T) {
+	ctx := context.Background()
+	db, dbPath, migrationsDir := setupDB(t)
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	require.NoError(t, Take(ctx, db, path))
+	assert.Error(t, Take(ctx, db, path), "existing snapshots are not overwritten")
+
+	info, err := Verify(ctx, path, migrationsDir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), info.Version)
+	assert.Equal(t, int64(2), info.Latest)
+	assert.Positive(t, info.Size)
+
+	_, err = db.Exec("DELETE FROM items")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+	require.NoError(t, os.WriteFile(dbPath+"-wal", []byte("stale log"), 0o600))
+
+	require.NoError(t, Restore(ctx, path, dbPath))
+	assert.NoFileExists(t, dbPath+"-wal")
+
+	restored, err := sqlx.Connect("sqlite", dbPath)
+	require.NoError(t, err)
+	defer restored.Close()
+	var count int
+	require.NoError(t, restored.Get(&count, "SELECT COUNT(*) FROM items"))
+	assert.Equal(t, 2, count)
+	assert.NoFileExists(t, dbPath+".restore")
+}
+
+func TestPathFromDSN(t *testing.T) {
+	tests := []struct {
+		dsn     string
+		want    string
+		wantErr bool
+	}{
+		{dsn: "server.db", want: "server.db"},
+		{dsn: "data/server.db?_pragma=busy_timeout(5000)", want: "data/server.db"},
+		{dsn: "file:server.db?_pragma=journal_mode(WAL)", want: "server.db"},
+		{dsn: "file:data/my%20vault.db", want: "data/my vault.db"},
+		{dsn: "file:///var/lib/gophkeeper/server.db?mode=rwc", want: "/var/lib/gophkeeper/server.db"},
+		{dsn: "file://localhost/var/lib/server.db", want: "/var/lib/server.db"},
+		{dsn: ":memory:", wantErr: true},
+		{dsn: "file:vault?mode=memory&cache=shared", wantErr: true},
+		{dsn: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := PathFromDSN(tt.dsn)
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrNoDatabaseFile, tt.dsn)
+			continue
+		}
+		require.NoError(t, err, tt.dsn)
+		assert.Equal(t, tt.want, got, tt.dsn)
+	}
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	ctx := context.Background()
+	db, _, migrationsDir := setupDB(t)
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	require.NoError(t, os.WriteFile(garbage, []byte("definitely not a SQLite database, just some text"), 0o600))
+	_, err := Verify(ctx, garbage, migrationsDir)
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	empty := filepath.Join(dir, "empty.db")
+	emptyDB, err := sqlx.Connect("sqlite", empty)
+	require.NoError(t, err)
+	_, err = emptyDB.Exec("CREATE TABLE t (x INTEGER)")
+	require.NoError(t, err)
+	require.NoError(t, emptyDB.Close())
+	_, err = Verify(ctx, empty, migrationsDir)
+	assert.ErrorIs(t, err, ErrNotMigrated)
+
+	_, err = db.Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (3, 1)")
+	require.NoError(t, err)
+	newer := filepath.Join(dir, "newer.db")
+	require.NoError(t, Take(ctx, db, newer))
+	_, err = Verify(ctx, newer, migrationsDir)
+	assert.ErrorIs(t, err, ErrNewerVersion)
+
+	_, err = Verify(ctx, filepath.Join(dir, "missing.db"), migrationsDir)
+	assert.Error(t, err)
+}
+
+func TestTakeInDirPrune(t *testing.T) {
+	ctx := context.Background()
+	db, _, _ := setupDB(t)
+	dir := filepath.Join(t.TempDir(), "backups")
+
+	start := time.Date(2025, time.August, 10, 12, 0, 0, 0, time.UTC)
+	var paths []string
+	for i := range 4 {
+		path, err := TakeInDir(ctx, db, dir, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+	assert.Equal(t, filepath.Join(dir, "gophkeeper-20250810T120000.000Z.db"), paths[0])
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0o600))
+
+	removed, err := Prune(dir, 0)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+
+	removed, err = Prune(dir, 2)
+	require.NoError(t, err)
+	assert.Equal(t, paths[:2], removed)
+	assert.NoFileExists(t, paths[0])
+	assert.FileExists(t, paths[3])
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+}
@@ -0,0 +1,288 @@
+// Package sshagent serves the SSH keys of the vault to ssh and git over the SSH agent protocol.
+// The private keys never leave the process: each signature is confirmed by the user first,
+// and keys cannot be added or removed through the agent.
+package sshagent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	sshagent "golang.org/x/crypto/ssh/agent"
+
+	"github.com/sbilibin2017/gophkeeper/internal/agent"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+)
+
+// EnvSocket is the environment variable ssh reads the agent socket from.
+const EnvSocket = "SSH_AUTH_SOCK"
+
+var (
+	// ErrReadOnly is returned to requests changing the keys, which live in the vault.
+	ErrReadOnly = errors.New("the keys of the vault cannot be changed through the agent")
+	// ErrDenied is returned when the user refuses a signature.
+	ErrDenied = errors.New("use of the key was denied")
+	// ErrUnknownKey is returned for a signature with a key the agent does not hold.
+	ErrUnknownKey = errors.New("unknown key")
+)
+
+// Key is an SSH key of the vault ready to sign.
+type Key struct {
+	Name    string // Name is the name of the sshkey secret.
+	Comment string
+	signer  ssh.Signer
+}
+
+// ParseKey parses the private key of the sshkey secret name, decrypting it with the stored passphrase.
+func ParseKey(name string, payload models.SSHKeyPayload) (*Key, error) {
+	var (
+		signer ssh.Signer
+		err    error
+	)
+	if payload.Passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(payload.PrivateKey), []byte(payload.Passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(payload.PrivateKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", name, err)
+	}
+	comment := payload.Comment
+	if comment == "" {
+		comment = name
+	}
+	return &Key{Name: name, Comment: comment, signer: signer}, nil
+}
+
+// PublicKey returns the public key of k.
+func (k *Key) PublicKey() ssh.PublicKey {
+	return k.signer.PublicKey()
+}
+
+// Confirmer asks the user whether a key may be used.
+type Confirmer interface {
+	Confirm(prompt string) (bool, error)
+}
+
+// Terminal confirms on a terminal: the prompt is written to out and a line starting with y is
+// read from in as consent.
+type Terminal struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewTerminal returns a confirmer reading answers from in and writing prompts to out.
+func NewTerminal(in io.Reader, out io.Writer) *Terminal {
+	return &Terminal{in: bufio.NewReader(in), out: out}
+}
+
+// Confirm implements Confirmer.
+func (t *Terminal) Confirm(prompt string) (bool, error) {
+	fmt.Fprintf(t.out, "%s [y/N] ", prompt)
+	line, err := t.in.ReadString('\n')
+	if err != nil && line == "" {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// Askpass confirms with an askpass program, like ssh-agent does for keys added with ssh-add -c:
+// the program is run with the prompt as its argument and SSH_ASKPASS_PROMPT=confirm, and
+// exiting with zero is consent.
+type Askpass struct {
+	program string
+}
+
+// NewAskpass returns a confirmer running program, usually $SSH_ASKPASS.
+func NewAskpass(program string) *Askpass {
+	return &Askpass{program: program}
+}
+
+// Confirm implements Confirmer.
+func (a *Askpass) Confirm(prompt string) (bool, error) {
+	cmd := exec.Command(a.program, prompt)
+	cmd.Env = append(os.Environ(), "SSH_ASKPASS_PROMPT=confirm")
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to run %s: %w", a.program, err)
+	}
+	return true, nil
+}
+
+// Keyring is a read-only SSH agent of the keys of the vault. It implements agent.ExtendedAgent.
+type Keyring struct {
+	keys    []*Key
+	confirm Confirmer
+	uid     int
+	// mu serializes confirmations, so prompts of concurrent signatures do not interleave.
+	mu sync.Mutex
+}
+
+// Opt configures a Keyring.
+type Opt func(*Keyring)
+
+// WithUID sets the user whose connections are served, the user running the agent by default.
+func WithUID(uid int) Opt {
+	return func(k *Keyring) {
+		k.uid = uid
+	}
+}
+
+// NewKeyring returns an agent of keys asking confirm before each signature.
+func NewKeyring(keys []*Key, confirm Confirmer, opts ...Opt) *Keyring {
+	k := &Keyring{keys: keys, confirm: confirm, uid: os.Getuid()}
+	for _, opt := range opts {
+		opt(k)
+	}
+	return k
+}
+
+// List implements agent.Agent.
+func (k *Keyring) List() ([]*sshagent.Key, error) {
+	list := make([]*sshagent.Key, 0, len(k.keys))
+	for _, key := range k.keys {
+		pub := key.PublicKey()
+		list = append(list, &sshagent.Key{Format: pub.Type(), Blob: pub.Marshal(), Comment: key.Comment})
+	}
+	return list, nil
+}
+
+// Sign implements agent.Agent.
+func (k *Keyring) Sign(pub ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return k.SignWithFlags(pub, data, 0)
+}
+
+// SignWithFlags implements agent.ExtendedAgent. It signs only after the user confirms the use of the key.
+func (k *Keyring) SignWithFlags(pub ssh.PublicKey, data []byte, flags sshagent.SignatureFlags) (*ssh.Signature, error) {
+	key := k.find(pub)
+	if key == nil {
+		return nil, ErrUnknownKey
+	}
+
+	k.mu.Lock()
+	ok, err := k.confirm.Confirm(fmt.Sprintf("Allow use of SSH key %s (%s %s)?", key.Name, key.Comment, ssh.FingerprintSHA256(pub)))
+	k.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("confirmation failed: %w", err)
+	}
+	if !ok {
+		return nil, ErrDenied
+	}
+
+	if algSigner, isAlg := key.signer.(ssh.AlgorithmSigner); isAlg {
+		switch {
+		case flags&sshagent.SignatureFlagRsaSha256 != 0:
+			return algSigner.SignWithAlgorithm(nil, data, ssh.KeyAlgoRSASHA256)
+		case flags&sshagent.SignatureFlagRsaSha512 != 0:
+			return algSigner.SignWithAlgorithm(nil, data, ssh.KeyAlgoRSASHA512)
+		}
+	}
+	return key.signer.Sign(nil, data)
+}
+
+// find returns the key with the public key pub, nil when there is none.
+func (k *Keyring) find(pub ssh.PublicKey) *Key {
+	blob := pub.Marshal()
+	for _, key := range k.keys {
+		if string(key.PublicKey().Marshal()) == string(blob) {
+			return key
+		}
+	}
+	return nil
+}
+
+// Add implements agent.Agent; keys are added to the vault instead.
+func (k *Keyring) Add(sshagent.AddedKey) error {
+	return ErrReadOnly
+}
+
+// Remove implements agent.Agent.
+func (k *Keyring) Remove(ssh.PublicKey) error {
+	return ErrReadOnly
+}
+
+// RemoveAll implements agent.Agent.
+func (k *Keyring) RemoveAll() error {
+	return ErrReadOnly
+}
+
+// Lock implements agent.Agent; stopping the agent drops the keys instead.
+func (k *Keyring) Lock([]byte) error {
+	return ErrReadOnly
+}
+
+// Unlock implements agent.Agent.
+func (k *Keyring) Unlock([]byte) error {
+	return ErrReadOnly
+}
+
+// Signers implements agent.Agent. The signers are not handed out, as they would sign unconfirmed.
+func (k *Keyring) Signers() ([]ssh.Signer, error) {
+	return nil, ErrReadOnly
+}
+
+// Extension implements agent.ExtendedAgent.
+func (k *Keyring) Extension(string, []byte) ([]byte, error) {
+	return nil, sshagent.ErrExtensionUnsupported
+}
+
+// Serve answers the connections of the agent user on l until ctx is done, then closes l.
+func (k *Keyring) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			k.handle(ctx, conn)
+		}()
+	}
+}
+
+// handle serves one connection after checking that it comes from the agent user.
+func (k *Keyring) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	if uid, err := agent.PeerUID(conn); err != nil || uid != k.uid {
+		return
+	}
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		conn.Close()
+	}()
+	_ = sshagent.ServeAgent(k, conn)
+}
+
+// DefaultSocketPath returns the socket of the SSH agent of the current user, next to the socket
+// of the gophkeeper agent.
+func DefaultSocketPath() string {
+	return filepath.Join(filepath.Dir(agent.DefaultSocketPath()), "ssh-agent.sock")
+}
@@ -0,0 +1,186 @@
+package sshagent
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	sshagent "golang.org/x/crypto/ssh/agent"
+
+	"github.com/sbilibin2017/gophkeeper/internal/agent"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConfirmer answers every confirmation with answer and records the prompts.
+type fakeConfirmer struct {
+	answer  bool
+	prompts []string
+}
+
+func (f *fakeConfirmer) Confirm(prompt string) (bool, error) {
+	f.prompts = append(f.prompts, prompt)
+	return f.answer, nil
+}
+
+// ed25519Payload returns the payload of a new ed25519 key, encrypted with passphrase when it is set.
+func ed25519Payload(t *testing.T, passphrase string) models.SSHKeyPayload {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	}
+	require.NoError(t, err)
+	return models.SSHKeyPayload{PrivateKey: string(pem.EncodeToMemory(block)), Comment: "alice@laptop", Passphrase: passphrase}
+}
+
+// startKeyring serves k on a socket in a temporary directory and returns an agent client of it.
+func startKeyring(t *testing.T, k *Keyring) sshagent.ExtendedAgent {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "agent", "ssh-agent.sock")
+	l, err := agent.Listen(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = k.Serve(ctx, l)
+		close(done)
+	}()
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-done
+	})
+	return sshagent.NewClient(conn)
+}
+
+func TestParseKey(t *testing.T) {
+	key, err := ParseKey("laptop", ed25519Payload(t, "s3cret"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice@laptop", key.Comment)
+	assert.Equal(t, ssh.KeyAlgoED25519, key.PublicKey().Type())
+
+	payload := ed25519Payload(t, "")
+	payload.Comment = ""
+	key, err = ParseKey("laptop", payload)
+	require.NoError(t, err)
+	assert.Equal(t, "laptop", key.Comment)
+
+	payload = ed25519Payload(t, "s3cret")
+	payload.Passphrase = ""
+	_, err = ParseKey("laptop", payload)
+	assert.ErrorContains(t, err, "failed to parse SSH key laptop")
+}
+
+func TestKeyring(t *testing.T) {
+	key, err := ParseKey("laptop", ed25519Payload(t, ""))
+	require.NoError(t, err)
+	confirm := &fakeConfirmer{answer: true}
+	client := startKeyring(t, NewKeyring([]*Key{key}, confirm))
+
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "alice@laptop", keys[0].Comment)
+	assert.Equal(t, key.PublicKey().Marshal(), keys[0].Blob)
+
+	data := []byte("session")
+	sig, err := client.Sign(key.PublicKey(), data)
+	require.NoError(t, err)
+	require.NoError(t, key.PublicKey().Verify(data, sig))
+	require.Len(t, confirm.prompts, 1)
+	assert.Contains(t, confirm.prompts[0], "Allow use of SSH key laptop (alice@laptop SHA256:")
+
+	confirm.answer = false
+	_, err = client.Sign(key.PublicKey(), data)
+	assert.Error(t, err)
+
+	other, err := ParseKey("other", ed25519Payload(t, ""))
+	require.NoError(t, err)
+	_, err = client.Sign(other.PublicKey(), data)
+	assert.Error(t, err)
+	assert.Len(t, confirm.prompts, 2)
+
+	assert.Error(t, client.Add(sshagent.AddedKey{PrivateKey: ed25519.NewKeyFromSeed(make([]byte, 32))}))
+	assert.Error(t, client.RemoveAll())
+	_, err = client.Signers()
+	assert.NoError(t, err) // the client builds its signers from List
+}
+
+func TestKeyring_RSASignatureFlags(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	require.NoError(t, err)
+	key, err := ParseKey("rsa", models.SSHKeyPayload{PrivateKey: string(pem.EncodeToMemory(block))})
+	require.NoError(t, err)
+	client := startKeyring(t, NewKeyring([]*Key{key}, &fakeConfirmer{answer: true}))
+
+	sig, err := client.SignWithFlags(key.PublicKey(), []byte("data"), sshagent.SignatureFlagRsaSha512)
+	require.NoError(t, err)
+	assert.Equal(t, ssh.KeyAlgoRSASHA512, sig.Format)
+	require.NoError(t, key.PublicKey().Verify([]byte("data"), sig))
+}
+
+func TestKeyring_PeerRejected(t *testing.T) {
+	key, err := ParseKey("laptop", ed25519Payload(t, ""))
+	require.NoError(t, err)
+	client := startKeyring(t, NewKeyring([]*Key{key}, &fakeConfirmer{answer: true}, WithUID(os.Getuid()+1)))
+
+	_, err = client.List()
+	assert.Error(t, err)
+}
+
+func TestTerminal(t *testing.T) {
+	var out bytes.Buffer
+	term := NewTerminal(strings.NewReader("y\nno\nYES\n"), &out)
+
+	for _, want := range []bool{true, false, true} {
+		ok, err := term.Confirm("Allow?")
+		require.NoError(t, err)
+		assert.Equal(t, want, ok)
+	}
+	assert.Equal(t, "Allow? [y/N] Allow? [y/N] Allow? [y/N] ", out.String())
+
+	_, err := term.Confirm("Allow?")
+	assert.Error(t, err)
+}
+
+func TestAskpass(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "askpass")
+	prompt := filepath.Join(dir, "prompt")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"$SSH_ASKPASS_PROMPT $1\" > "+prompt+"\ntest \"$1\" = yes\n"), 0o700))
+
+	ok, err := NewAskpass(script).Confirm("yes")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, err := os.ReadFile(prompt)
+	require.NoError(t, err)
+	assert.Equal(t, "confirm yes\n", string(got))
+
+	ok, err = NewAskpass(script).Confirm("no")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = NewAskpass(filepath.Join(dir, "missing")).Confirm("yes")
+	assert.Error(t, err)
+}
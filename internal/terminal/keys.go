@@ -0,0 +1,114 @@
+package terminal
+
+import "unicode/utf8"
+
+// KeyCode identifies a key press; printable characters are KeyRune.
+type KeyCode int
+
+// Special keys.
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyBackTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyCtrlC
+	KeyCtrlD
+	KeyCtrlR
+)
+
+// Key is a key press.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// Rune returns the key press of the printable character r.
+func Rune(r rune) Key {
+	return Key{Code: KeyRune, Rune: r}
+}
+
+// escapeSequences maps the escape sequences of special keys, without the leading ESC.
+var escapeSequences = map[string]KeyCode{
+	"[A": KeyUp,
+	"[B": KeyDown,
+	"[C": KeyRight,
+	"[D": KeyLeft,
+	"OA": KeyUp,
+	"OB": KeyDown,
+	"OC": KeyRight,
+	"OD": KeyLeft,
+	"[Z": KeyBackTab,
+}
+
+// DecodeKeys decodes the bytes read from a terminal in raw mode into key presses.
+// Unknown control characters and escape sequences are dropped.
+func DecodeKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				return append(keys, Key{Code: KeyEsc})
+			}
+			if len(b) >= 3 {
+				if code, ok := escapeSequences[string(b[1:3])]; ok {
+					keys = append(keys, Key{Code: code})
+					b = b[3:]
+					continue
+				}
+			}
+			// Skip an unknown CSI sequence up to its final byte, or a lone ESC.
+			if b[1] == '[' {
+				i := 2
+				for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+					i++
+				}
+				b = b[min(i+1, len(b)):]
+				continue
+			}
+			keys = append(keys, Key{Code: KeyEsc})
+			b = b[1:]
+
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+			b = b[1:]
+
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+			b = b[1:]
+
+		case c == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+			b = b[1:]
+
+		case c == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+			b = b[1:]
+
+		case c == 0x04:
+			keys = append(keys, Key{Code: KeyCtrlD})
+			b = b[1:]
+
+		case c == 0x12:
+			keys = append(keys, Key{Code: KeyCtrlR})
+			b = b[1:]
+
+		case c < 0x20:
+			b = b[1:]
+
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				keys = append(keys, Rune(r))
+			}
+			b = b[size:]
+		}
+	}
+	return keys
+}
@@ -0,0 +1,26 @@
+package terminal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Key
+	}{
+		{"ab", []Key{Rune('a'), Rune('b')}},
+		{"ж€", []Key{Rune('ж'), Rune('€')}},
+		{"\r\x7f\t", []Key{{Code: KeyEnter}, {Code: KeyBackspace}, {Code: KeyTab}}},
+		{"\x1b[A\x1b[B\x1bOC\x1b[D\x1b[Z", []Key{{Code: KeyUp}, {Code: KeyDown}, {Code: KeyRight}, {Code: KeyLeft}, {Code: KeyBackTab}}},
+		{"\x1b", []Key{{Code: KeyEsc}}},
+		{"\x1b[3~x", []Key{Rune('x')}},
+		{"\x03\x04\x12\x01", []Key{{Code: KeyCtrlC}, {Code: KeyCtrlD}, {Code: KeyCtrlR}}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DecodeKeys([]byte(tt.in)), "%q", tt.in)
+	}
+}
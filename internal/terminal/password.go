@@ -0,0 +1,59 @@
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned by ReadPassword when Ctrl-C is pressed.
+var ErrInterrupted = errors.New("interrupted")
+
+// ReadPassword writes prompt to w and reads a line from the terminal in without echoing it.
+func ReadPassword(in *os.File, w io.Writer, prompt string) (string, error) {
+	restore, err := MakeRaw(int(in.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	fmt.Fprint(w, prompt)
+	defer fmt.Fprint(w, "\r\n")
+	return readPassword(in)
+}
+
+// readPassword reads a line typed in raw mode from r. Backspace deletes the last character,
+// Ctrl-C interrupts and Ctrl-D on an empty line ends the input.
+func readPassword(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n == 0 {
+			if err == nil {
+				continue
+			}
+			if errors.Is(err, io.EOF) && len(line) > 0 {
+				return string(line), nil
+			}
+			return "", err
+		}
+		switch b[0] {
+		case '\r', '\n':
+			return string(line), nil
+		case 3:
+			return "", ErrInterrupted
+		case 4:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+		case 8, 127:
+			_, size := utf8.DecodeLastRune(line)
+			line = line[:len(line)-size]
+		default:
+			line = append(line, b[0])
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package terminal
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPassword(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "enter", input: "s3cret!\rignored", want: "s3cret!"},
+		{name: "newline", input: "s3cret!\n", want: "s3cret!"},
+		{name: "backspace", input: "s3cx\x7fr\x08ret!\r", want: "s3cret!"},
+		{name: "backspace over multibyte", input: "паролв\x7fь\r", want: "пароль"},
+		{name: "backspace on empty line", input: "\x7fs3cret!\r", want: "s3cret!"},
+		{name: "end of input", input: "s3cret!", want: "s3cret!"},
+		{name: "ctrl-c", input: "s3c\x03ret!\r", wantErr: ErrInterrupted},
+		{name: "ctrl-d", input: "\x04", wantErr: io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readPassword(strings.NewReader(tt.input))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
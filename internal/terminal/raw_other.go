@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package terminal
+
+// MakeRaw fails on platforms without termios.
+func MakeRaw(int) (func(), error) {
+	return nil, ErrUnsupported
+}
+
+// Size fails on platforms without termios.
+func Size(int) (int, int, error) {
+	return 0, 0, ErrUnsupported
+}
+
+// IsTerminal reports false on platforms without termios.
+func IsTerminal(int) bool {
+	return false
+}
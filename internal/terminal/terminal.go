@@ -0,0 +1,6 @@
+package terminal
+
+import "errors"
+
+// ErrUnsupported is returned on platforms without termios.
+var ErrUnsupported = errors.New("terminal raw mode is not supported on this platform")
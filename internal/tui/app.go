@@ -0,0 +1,405 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sbilibin2017/gophkeeper/internal/client"
+	"github.com/sbilibin2017/gophkeeper/internal/jwt"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/terminal"
+)
+
+// SyncFunc uploads the secrets of the local store to the server.
+type SyncFunc func(ctx context.Context) error
+
+// screen is a view of the UI.
+type screen int
+
+const (
+	screenBrowser screen = iota
+	screenDetail
+	screenPickType
+	screenForm
+)
+
+// pickKeys maps the keys of the type picker to secret types.
+var pickKeys = map[rune]string{
+	'b': models.SecretTypeBankCard,
+	't': models.SecretTypeText,
+	'i': models.SecretTypeBinary,
+	'u': models.SecretTypeUser,
+}
+
+// App is the state of the terminal UI: a browser of the secrets of the local store, a detail
+// view revealing sensitive fields on demand, add and edit forms and a sync status panel.
+// It is driven by key presses and rendered to lines, so it runs without a terminal in tests.
+type App struct {
+	token      string
+	lister     client.ClientLister
+	saver      client.ClientSaver
+	encryptor  client.Encryptor
+	decryptor  client.Decryptor
+	sync       SyncFunc
+	syncTarget string
+	now        func() time.Time
+
+	secrets  []client.DecryptedSecret
+	cursor   int
+	screen   screen
+	form     *form
+	revealed bool
+	status   string
+	lastSync time.Time
+	syncErr  error
+	done     bool
+}
+
+// Opt configures an App.
+type Opt func(*App)
+
+// WithStore sets the local store secrets are listed from and saved to.
+func WithStore(lister client.ClientLister, saver client.ClientSaver) Opt {
+	return func(a *App) {
+		a.lister = lister
+		a.saver = saver
+	}
+}
+
+// WithCryptor sets the keys secrets are encrypted and decrypted with.
+func WithCryptor(encryptor client.Encryptor, decryptor client.Decryptor) Opt {
+	return func(a *App) {
+		a.encryptor = encryptor
+		a.decryptor = decryptor
+	}
+}
+
+// WithSync enables syncing with the server at target.
+func WithSync(target string, sync SyncFunc) Opt {
+	return func(a *App) {
+		a.syncTarget = target
+		a.sync = sync
+	}
+}
+
+// WithNow sets the clock of the sync panel.
+func WithNow(now func() time.Time) Opt {
+	return func(a *App) {
+		a.now = now
+	}
+}
+
+// New returns the UI of the owner of token.
+func New(token string, opts ...Opt) *App {
+	a := &App{token: token, now: time.Now}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Done reports whether the user quit.
+func (a *App) Done() bool {
+	return a.done
+}
+
+// Load reads and decrypts the secrets of the local store.
+func (a *App) Load(ctx context.Context) error {
+	secrets, err := client.ClientGetSecrets(ctx, a.lister, a.decryptor, a.token)
+	if err != nil {
+		return err
+	}
+	a.secrets = secrets
+	a.cursor = min(a.cursor, max(len(secrets)-1, 0))
+	return nil
+}
+
+// HandleKey applies a key press.
+func (a *App) HandleKey(ctx context.Context, k terminal.Key) {
+	if k.Code == terminal.KeyCtrlC {
+		a.done = true
+		return
+	}
+
+	switch a.screen {
+	case screenBrowser:
+		a.handleBrowser(ctx, k)
+	case screenDetail:
+		a.handleDetail(ctx, k)
+	case screenPickType:
+		a.handlePickType(k)
+	case screenForm:
+		a.handleForm(ctx, k)
+	}
+}
+
+func (a *App) handleBrowser(ctx context.Context, k terminal.Key) {
+	switch {
+	case k.Code == terminal.KeyUp || k == terminal.Rune('k'):
+		a.cursor = max(a.cursor-1, 0)
+	case k.Code == terminal.KeyDown || k == terminal.Rune('j'):
+		a.cursor = min(a.cursor+1, max(len(a.secrets)-1, 0))
+	case k.Code == terminal.KeyEnter:
+		if a.selected() != nil {
+			a.screen = screenDetail
+			a.revealed = false
+		}
+	case k == terminal.Rune('a'):
+		a.screen = screenPickType
+	case k == terminal.Rune('e'):
+		a.startEdit()
+	case k == terminal.Rune('s'):
+		a.runSync(ctx)
+	case k == terminal.Rune('q'):
+		a.done = true
+	}
+}
+
+func (a *App) handleDetail(ctx context.Context, k terminal.Key) {
+	switch {
+	case k.Code == terminal.KeyEsc || k.Code == terminal.KeyBackspace || k == terminal.Rune('q'):
+		a.screen = screenBrowser
+		a.revealed = false
+	case k == terminal.Rune('r'):
+		a.revealed = !a.revealed
+	case k == terminal.Rune('e'):
+		a.startEdit()
+	case k == terminal.Rune('s'):
+		a.runSync(ctx)
+	}
+}
+
+func (a *App) handlePickType(k terminal.Key) {
+	if k.Code == terminal.KeyEsc {
+		a.screen = screenBrowser
+		return
+	}
+	if secretType, ok := pickKeys[k.Rune]; ok && k.Code == terminal.KeyRune {
+		a.form = newForm(secretType)
+		a.screen = screenForm
+	}
+}
+
+func (a *App) handleForm(ctx context.Context, k terminal.Key) {
+	if k.Code == terminal.KeyEsc {
+		a.form = nil
+		a.screen = screenBrowser
+		a.status = ""
+		return
+	}
+	if !a.form.handleKey(k) {
+		return
+	}
+
+	if err := a.form.validate(); err != nil {
+		a.status = err.Error()
+		return
+	}
+	name := a.form.values[0]
+	if !a.form.edit && a.find(a.form.secretType, name) >= 0 {
+		a.status = fmt.Sprintf("%s/%s already exists, edit it instead", a.form.secretType, name)
+		return
+	}
+	if err := a.form.save(ctx, a); err != nil {
+		a.status = err.Error()
+		return
+	}
+
+	a.status = fmt.Sprintf("Saved %s/%s", a.form.secretType, name)
+	if err := a.Load(ctx); err != nil {
+		a.status = err.Error()
+	}
+	if i := a.find(a.form.secretType, name); i >= 0 {
+		a.cursor = i
+	}
+	a.form = nil
+	a.screen = screenBrowser
+}
+
+// startEdit opens the edit form of the selected secret.
+func (a *App) startEdit() {
+	secret := a.selected()
+	if secret == nil {
+		return
+	}
+	if _, ok := fieldsByType[secret.SecretType]; !ok {
+		a.status = fmt.Sprintf("%s secrets cannot be edited", secret.SecretType)
+		return
+	}
+	a.form = editForm(*secret)
+	a.screen = screenForm
+}
+
+// runSync syncs with the server and reloads the secrets.
+func (a *App) runSync(ctx context.Context) {
+	if a.sync == nil {
+		a.status = "Sync is not configured, start with --server-url"
+		return
+	}
+	a.syncErr = a.sync(ctx)
+	a.lastSync = a.now()
+	if a.syncErr != nil {
+		a.status = "Sync failed: " + a.syncErr.Error()
+		return
+	}
+	a.status = "Synced"
+	if err := a.Load(ctx); err != nil {
+		a.status = err.Error()
+	}
+}
+
+// selected returns the secret under the cursor, or nil when there are none.
+func (a *App) selected() *client.DecryptedSecret {
+	if a.cursor < len(a.secrets) {
+		return &a.secrets[a.cursor]
+	}
+	return nil
+}
+
+// find returns the index of a secret, or -1.
+func (a *App) find(secretType, name string) int {
+	for i, s := range a.secrets {
+		if s.SecretType == secretType && s.SecretName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// pending returns how many secrets were changed after the last sync.
+func (a *App) pending() int {
+	n := 0
+	for _, s := range a.secrets {
+		if a.lastSync.IsZero() || s.UpdatedAt.After(a.lastSync) {
+			n++
+		}
+	}
+	return n
+}
+
+// Render returns the screen as lines of at most width characters, at most height lines.
+func (a *App) Render(width, height int) []string {
+	owner, _ := jwt.Username(a.token)
+	header := fmt.Sprintf("GophKeeper · %s · %d secrets", owner, len(a.secrets))
+
+	keys := map[screen]string{
+		screenBrowser:  "↑/↓ move  enter open  a add  e edit  s sync  q quit",
+		screenDetail:   "r reveal/hide  e edit  s sync  esc back",
+		screenPickType: "esc cancel",
+		screenForm:     "tab/↓ next  shift-tab/↑ previous  enter next/save  ctrl-r reveal  esc cancel",
+	}[a.screen]
+
+	footer := []string{"", strings.Repeat("─", max(width, 1))}
+	footer = append(footer, a.renderSync()...)
+	footer = append(footer, keys, a.status)
+
+	// The body is cut so that the header and the footer always fit.
+	room := max(height-len(footer)-2, 0)
+
+	var body []string
+	switch a.screen {
+	case screenBrowser:
+		body = a.renderBrowser(room)
+	case screenDetail:
+		body = a.renderDetail()
+	case screenPickType:
+		body = []string{"Add a secret:", "", "  b  bankcard", "  t  text", "  i  binary", "  u  user"}
+	case screenForm:
+		body = a.form.render()
+	}
+	if len(body) > room {
+		body = body[:room]
+	}
+
+	lines := append([]string{header, ""}, body...)
+	for len(lines)+len(footer) < height {
+		lines = append(lines, "")
+	}
+	lines = append(lines, footer...)
+
+	for i, line := range lines {
+		lines[i] = truncate(line, width)
+	}
+	return lines
+}
+
+func (a *App) renderBrowser(rows int) []string {
+	if len(a.secrets) == 0 {
+		return []string{"No secrets yet. Press a to add one."}
+	}
+
+	lines := []string{fmt.Sprintf("  %-9s %-32s %s", "TYPE", "NAME", "UPDATED")}
+	rows = max(rows-1, 1)
+	offset := max(a.cursor-rows+1, 0)
+	for i := offset; i < len(a.secrets) && i < offset+rows; i++ {
+		s := a.secrets[i]
+		cursor := "  "
+		if i == a.cursor {
+			cursor = "> "
+		}
+		updated := ""
+		if !s.UpdatedAt.IsZero() {
+			updated = s.UpdatedAt.Local().Format("2006-01-02 15:04")
+		}
+		lines = append(lines, fmt.Sprintf("%s%-9s %-32s %s", cursor, s.SecretType, s.SecretName, updated))
+	}
+	return lines
+}
+
+func (a *App) renderDetail() []string {
+	secret := a.selected()
+	if secret == nil {
+		return nil
+	}
+
+	lines := []string{fmt.Sprintf("%s/%s", secret.SecretType, secret.SecretName), ""}
+	values := payloadValues(*secret)
+	for i, fl := range fieldsByType[secret.SecretType] {
+		if i == 0 || i >= len(values) {
+			continue
+		}
+		value := values[i]
+		if fl.sensitive && !a.revealed {
+			value = mask
+		}
+		for j, part := range strings.Split(value, "\n") {
+			label := fl.label + ":"
+			if j > 0 {
+				label = ""
+			}
+			lines = append(lines, fmt.Sprintf("  %-20s %s", label, part))
+		}
+	}
+	if !secret.UpdatedAt.IsZero() {
+		lines = append(lines, fmt.Sprintf("  %-20s %s", "Updated:", secret.UpdatedAt.Local().Format(time.RFC3339)))
+	}
+	return lines
+}
+
+func (a *App) renderSync() []string {
+	if a.sync == nil {
+		return []string{"Sync: not configured, start with --server-url"}
+	}
+
+	last := "never"
+	if !a.lastSync.IsZero() {
+		last = a.lastSync.Local().Format("15:04:05")
+		if a.syncErr != nil {
+			last += " (failed)"
+		} else {
+			last += " (ok)"
+		}
+	}
+	return []string{fmt.Sprintf("Sync: %s · last sync %s · %d changed since", a.syncTarget, last, a.pending())}
+}
+
+// truncate cuts s to width characters.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:max(width, 0)])
+}
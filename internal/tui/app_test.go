@@ -0,0 +1,202 @@
+package tui
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/cryptor"
+	"github.com/sbilibin2017/gophkeeper/internal/jwt"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, time.August, 10, 12, 0, 0, 0, time.UTC)
+
+// memoryStore is a local store kept in memory.
+type memoryStore struct {
+	secrets []*models.Secret
+}
+
+func (s *memoryStore) List(_ context.Context, _ string) ([]*models.Secret, error) {
+	return s.secrets, nil
+}
+
+func (s *memoryStore) Save(
+	_ context.Context,
+	_ string,
+	secretName string,
+	secretType string,
+	ciphertext []byte,
+	aesKeyEnc []byte,
+	keyID string,
+	recipients models.Recipients,
+) error {
+	secret := &models.Secret{
+		SecretName: secretName,
+		SecretType: secretType,
+		Ciphertext: ciphertext,
+		AESKeyEnc:  aesKeyEnc,
+		KeyID:      keyID,
+		Recipients: recipients,
+		UpdatedAt:  testNow.Add(time.Minute),
+	}
+	for i, existing := range s.secrets {
+		if existing.SecretName == secretName && existing.SecretType == secretType {
+			s.secrets[i] = secret
+			return nil
+		}
+	}
+	s.secrets = append(s.secrets, secret)
+	return nil
+}
+
+func newTestApp(t *testing.T, opts ...Opt) (*App, *memoryStore) {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	c, err := cryptor.New(cryptor.WithVaultKey(key))
+	require.NoError(t, err)
+	token, err := jwt.New(jwt.WithSecret("secret"), jwt.WithLifetime(time.Hour)).Generate("alice")
+	require.NoError(t, err)
+
+	store := &memoryStore{}
+	app := New(token, append([]Opt{WithStore(store, store), WithCryptor(c, c), WithNow(func() time.Time { return testNow })}, opts...)...)
+	return app, store
+}
+
+func typeKeys(ctx context.Context, app *App, keys ...any) {
+	for _, k := range keys {
+		switch k := k.(type) {
+		case string:
+			for _, r := range k {
+				app.HandleKey(ctx, terminal.Rune(r))
+			}
+		case terminal.KeyCode:
+			app.HandleKey(ctx, terminal.Key{Code: k})
+		}
+	}
+}
+
+func screenText(app *App) string {
+	return strings.Join(app.Render(100, 30), "\n")
+}
+
+func TestApp_AddBrowseReveal(t *testing.T) {
+	ctx := context.Background()
+	app, store := newTestApp(t)
+	require.NoError(t, app.Load(ctx))
+	assert.Contains(t, screenText(app), "No secrets yet")
+
+	// Add a bank card; the form validates the number and CVV before saving.
+	typeKeys(ctx, app, "a", "b", "visa", terminal.KeyEnter, "4111111111111112", terminal.KeyEnter, "Alice", terminal.KeyEnter, "12/30", terminal.KeyEnter, "123", terminal.KeyEnter, terminal.KeyEnter)
+	assert.Contains(t, screenText(app), "invalid card number")
+	assert.Empty(t, store.secrets)
+
+	typeKeys(ctx, app, terminal.KeyUp, terminal.KeyUp, terminal.KeyUp, terminal.KeyUp, terminal.KeyBackspace, "1", terminal.KeyDown, terminal.KeyDown, terminal.KeyDown, terminal.KeyDown, terminal.KeyEnter)
+	require.Len(t, store.secrets, 1)
+	text := screenText(app)
+	assert.Contains(t, text, "Saved bankcard/visa")
+	assert.Contains(t, text, "> bankcard  visa")
+
+	// Add a user secret.
+	typeKeys(ctx, app, "a", "u", "github", terminal.KeyTab, "alice", terminal.KeyTab, "hunter2", terminal.KeyTab, terminal.KeyTab, terminal.KeyTab, terminal.KeyEnter)
+	require.Len(t, store.secrets, 2)
+	assert.Contains(t, screenText(app), "> user      github")
+
+	// Sensitive fields stay masked until revealed.
+	typeKeys(ctx, app, terminal.KeyEnter)
+	text = screenText(app)
+	assert.Contains(t, text, "user/github")
+	assert.Contains(t, text, "alice")
+	assert.Contains(t, text, mask)
+	assert.NotContains(t, text, "hunter2")
+
+	typeKeys(ctx, app, "r")
+	assert.Contains(t, screenText(app), "hunter2")
+
+	typeKeys(ctx, app, terminal.KeyEsc, terminal.KeyUp, terminal.KeyEnter)
+	text = screenText(app)
+	assert.Contains(t, text, "bankcard/visa")
+	assert.NotContains(t, text, "4111111111111111", "leaving the detail view hides the fields again")
+
+	typeKeys(ctx, app, terminal.KeyEsc, "q")
+	assert.True(t, app.Done())
+}
+
+func TestApp_EditAndDuplicates(t *testing.T) {
+	ctx := context.Background()
+	app, store := newTestApp(t)
+	require.NoError(t, app.Load(ctx))
+
+	typeKeys(ctx, app, "a", "t", "note", terminal.KeyEnter, "first", terminal.KeyEnter, terminal.KeyEnter)
+	require.Len(t, store.secrets, 1)
+
+	typeKeys(ctx, app, "a", "t", "note", terminal.KeyEnter, "second", terminal.KeyEnter, terminal.KeyEnter)
+	assert.Contains(t, screenText(app), "text/note already exists")
+	typeKeys(ctx, app, terminal.KeyEsc)
+
+	// The edit form is prefilled and keeps the name fixed.
+	typeKeys(ctx, app, "e")
+	text := screenText(app)
+	assert.Contains(t, text, "Edit text")
+	typeKeys(ctx, app, terminal.KeyCtrlR)
+	assert.Contains(t, screenText(app), "first_")
+
+	typeKeys(ctx, app, terminal.KeyUp, terminal.KeyBackspace, terminal.KeyBackspace, terminal.KeyBackspace, terminal.KeyBackspace, terminal.KeyBackspace, "updated", terminal.KeyEnter, terminal.KeyEnter)
+	require.Len(t, store.secrets, 1)
+	require.Len(t, app.secrets, 1)
+	assert.Equal(t, "note", app.secrets[0].SecretName)
+	assert.Equal(t, models.TextPayload{Data: "updated"}, app.secrets[0].Payload)
+
+	typeKeys(ctx, app, "a", "i", "file", terminal.KeyEnter, "not base64!", terminal.KeyEnter, terminal.KeyEnter)
+	assert.Contains(t, screenText(app), "data is not valid base64")
+	typeKeys(ctx, app, terminal.KeyEsc)
+	assert.Len(t, store.secrets, 1)
+}
+
+func TestApp_Sync(t *testing.T) {
+	ctx := context.Background()
+
+	app, _ := newTestApp(t)
+	typeKeys(ctx, app, "s")
+	assert.Contains(t, screenText(app), "Sync: not configured")
+
+	var syncErr error
+	calls := 0
+	app, _ = newTestApp(t, WithSync("http://localhost:8080", func(context.Context) error {
+		calls++
+		return syncErr
+	}))
+	require.NoError(t, app.Load(ctx))
+	typeKeys(ctx, app, "a", "t", "note", terminal.KeyEnter, "data", terminal.KeyEnter, terminal.KeyEnter)
+	assert.Contains(t, screenText(app), "Sync: http://localhost:8080 · last sync never · 1 changed since")
+
+	syncErr = errors.New("connection refused")
+	typeKeys(ctx, app, "s")
+	text := screenText(app)
+	assert.Contains(t, text, "(failed)")
+	assert.Contains(t, text, "Sync failed: connection refused")
+
+	syncErr = nil
+	typeKeys(ctx, app, "s")
+	text = screenText(app)
+	assert.Contains(t, text, "(ok) · 1 changed since", "the secret was saved after the test clock")
+	assert.Equal(t, 2, calls)
+}
+
+func TestApp_Render(t *testing.T) {
+	app, _ := newTestApp(t)
+	lines := app.Render(20, 10)
+	assert.Len(t, lines, 10)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len([]rune(line)), 20)
+	}
+	assert.True(t, strings.HasPrefix(lines[0], "GophKeeper · alice"))
+}
@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sbilibin2017/gophkeeper/internal/client"
+	"github.com/sbilibin2017/gophkeeper/internal/models"
+	"github.com/sbilibin2017/gophkeeper/internal/terminal"
+	"github.com/sbilibin2017/gophkeeper/internal/validators"
+)
+
+// mask replaces sensitive values until they are revealed. It has a fixed length,
+// so that it does not give away the length of the value.
+const mask = "••••••••"
+
+// field describes one field of a secret in forms and the detail view.
+type field struct {
+	label     string
+	sensitive bool
+	optional  bool
+}
+
+// fieldName is the first field of every form.
+var fieldName = field{label: "Name"}
+
+// fieldsByType lists the fields of each secret type, in the order of its add command.
+var fieldsByType = map[string][]field{
+	models.SecretTypeBankCard: {
+		fieldName,
+		{label: "Number", sensitive: true},
+		{label: "Owner"},
+		{label: "Expiry (MM/YY)"},
+		{label: "CVV", sensitive: true},
+		{label: "Meta", optional: true},
+	},
+	models.SecretTypeText: {
+		fieldName,
+		{label: "Data", sensitive: true},
+		{label: "Meta", optional: true},
+	},
+	models.SecretTypeBinary: {
+		fieldName,
+		{label: "Data (base64)", sensitive: true},
+		{label: "Meta", optional: true},
+	},
+	models.SecretTypeUser: {
+		fieldName,
+		{label: "Username"},
+		{label: "Password", sensitive: true},
+		{label: "TOTP seed", sensitive: true, optional: true},
+		{label: "URL", optional: true},
+		{label: "Meta", optional: true},
+	},
+}
+
+// payloadValues returns the field values of a decrypted secret, in the order of fieldsByType.
+func payloadValues(secret client.DecryptedSecret) []string {
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	switch p := secret.Payload.(type) {
+	case models.BankcardPayload:
+		return []string{secret.SecretName, p.Number, p.Owner, p.Exp, p.CVV, deref(p.Meta)}
+	case models.TextPayload:
+		return []string{secret.SecretName, p.Data, deref(p.Meta)}
+	case models.BinaryPayload:
+		return []string{secret.SecretName, base64.StdEncoding.EncodeToString(p.Data), deref(p.Meta)}
+	case models.UserPayload:
+		return []string{secret.SecretName, p.Username, p.Password, p.TOTP, p.URL, deref(p.Meta)}
+	}
+	return []string{secret.SecretName}
+}
+
+// form is an add or edit form of one secret type.
+type form struct {
+	secretType string
+	fields     []field
+	values     []string
+	focus      int
+	// edit is set when the form edits a stored secret, whose name is then fixed.
+	edit     bool
+	revealed bool
+	// prev is the stored login an edit form of a user secret replaces.
+	prev *models.UserPayload
+}
+
+// newForm returns an empty add form of secretType.
+func newForm(secretType string) *form {
+	fields := fieldsByType[secretType]
+	return &form{secretType: secretType, fields: fields, values: make([]string, len(fields))}
+}
+
+// editForm returns a form prefilled with the values of secret, focused on its first editable field.
+func editForm(secret client.DecryptedSecret) *form {
+	f := newForm(secret.SecretType)
+	copy(f.values, payloadValues(secret))
+	f.edit = true
+	f.focus = 1
+	if prev, ok := secret.Payload.(models.UserPayload); ok {
+		f.prev = &prev
+	}
+	return f
+}
+
+// handleKey edits the focused field. It reports whether the form is to be submitted.
+func (f *form) handleKey(k terminal.Key) bool {
+	switch k.Code {
+	case terminal.KeyTab, terminal.KeyDown:
+		f.move(1)
+	case terminal.KeyBackTab, terminal.KeyUp:
+		f.move(-1)
+	case terminal.KeyEnter:
+		if f.focus == len(f.fields)-1 {
+			return true
+		}
+		f.move(1)
+	case terminal.KeyBackspace:
+		if v := []rune(f.values[f.focus]); len(v) > 0 {
+			f.values[f.focus] = string(v[:len(v)-1])
+		}
+	case terminal.KeyCtrlR:
+		f.revealed = !f.revealed
+	case terminal.KeyRune:
+		f.values[f.focus] += string(k.Rune)
+	}
+	return false
+}
+
+// move moves the focus by delta fields, skipping the fixed name of an edit form.
+func (f *form) move(delta int) {
+	first := 0
+	if f.edit {
+		first = 1
+	}
+	f.focus = min(max(f.focus+delta, first), len(f.fields)-1)
+}
+
+// validate checks the values with the validators of the add commands.
+func (f *form) validate() error {
+	for i, fl := range f.fields {
+		if !fl.optional && strings.TrimSpace(f.values[i]) == "" {
+			return fmt.Errorf("%s is required", fl.label)
+		}
+	}
+	if f.secretType == models.SecretTypeBankCard {
+		if err := validators.ValidateLuhn(f.values[1]); err != nil {
+			return fmt.Errorf("invalid card number: %w", err)
+		}
+		if err := validators.ValidateCVV(f.values[4]); err != nil {
+			return fmt.Errorf("invalid CVV: %w", err)
+		}
+	}
+	if f.secretType == models.SecretTypeBinary {
+		if _, err := base64.StdEncoding.DecodeString(f.values[1]); err != nil {
+			return errors.New("data is not valid base64")
+		}
+	}
+	return nil
+}
+
+// save encrypts and saves the secret of the form with the add function of its type.
+func (f *form) save(ctx context.Context, a *App) error {
+	v := f.values
+	switch f.secretType {
+	case models.SecretTypeBankCard:
+		return client.ClientAddBankcard(ctx, a.saver, a.encryptor, a.token, v[0], v[1], v[2], v[3], v[4], v[5])
+	case models.SecretTypeText:
+		return client.ClientAddText(ctx, a.saver, a.encryptor, a.token, v[0], v[1], v[2])
+	case models.SecretTypeBinary:
+		return client.ClientAddBinary(ctx, a.saver, a.encryptor, a.token, v[0], v[1], v[2])
+	case models.SecretTypeUser:
+		return client.ClientAddUser(ctx, a.saver, a.encryptor, a.token, v[0], v[1], v[2], v[3], v[4], v[5], f.prev, time.Now())
+	}
+	return fmt.Errorf("unsupported secret type: %s", f.secretType)
+}
+
+// render returns the lines of the form.
+func (f *form) render() []string {
+	verb := "Add"
+	if f.edit {
+		verb = "Edit"
+	}
+	lines := []string{verb + " " + f.secretType, ""}
+	for i, fl := range f.fields {
+		value := f.values[i]
+		if fl.sensitive && !f.revealed && value != "" {
+			value = mask
+		}
+		cursor := "  "
+		if i == f.focus {
+			cursor = "> "
+			value += "_"
+		}
+		label := fl.label
+		if fl.optional {
+			label += " (optional)"
+		}
+		lines = append(lines, fmt.Sprintf("%s%-20s %s", cursor, label+":", value))
+	}
+	return lines
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sbilibin2017/gophkeeper/internal/terminal"
+)
+
+// Escape sequences switching to the alternate screen with a hidden cursor and back.
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// Run runs app on the terminal in, drawing to out, until the user quits.
+// The terminal is restored when Run returns.
+func Run(ctx context.Context, app *App, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	restore, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	if err := app.Load(ctx); err != nil {
+		app.status = err.Error()
+	}
+
+	buf := make([]byte, 256)
+	for !app.Done() {
+		width, height, err := terminal.Size(fd)
+		if err != nil || width == 0 || height == 0 {
+			width, height = 80, 24
+		}
+		fmt.Fprint(out, clearScreen+strings.Join(app.Render(width, height), "\r\n"))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		for _, k := range terminal.DecodeKeys(buf[:n]) {
+			app.HandleKey(ctx, k)
+			if app.Done() {
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN otp_secret_enc BLOB;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN otp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN otp_recovery_codes TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN otp_recovery_codes;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN otp_enabled;
+-- +goose StatementEnd
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN otp_secret_enc;
+-- +goose StatementEnd
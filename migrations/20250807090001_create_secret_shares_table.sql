@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS secret_shares (
+    secret_owner TEXT NOT NULL,
+    secret_type TEXT NOT NULL,
+    secret_name TEXT NOT NULL,
+    recipient TEXT NOT NULL REFERENCES users(username) ON DELETE CASCADE,
+    aes_key_enc BLOB NOT NULL,
+    key_id TEXT NOT NULL DEFAULT '',
+    permission TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (secret_owner, secret_type, secret_name, recipient),
+    FOREIGN KEY (secret_name, secret_type, secret_owner)
+        REFERENCES secrets(secret_name, secret_type, secret_owner) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_secret_shares_recipient ON secret_shares (recipient);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS secret_shares;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS vault_secrets (
+    organization TEXT NOT NULL,
+    vault TEXT NOT NULL,
+    secret_name TEXT NOT NULL,
+    secret_type TEXT NOT NULL,
+    ciphertext BLOB NOT NULL,
+    aes_key_enc BLOB NOT NULL,
+    key_id TEXT NOT NULL DEFAULT '',
+    recipients TEXT NOT NULL DEFAULT '',
+    updated_by TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization, vault, secret_type, secret_name),
+    FOREIGN KEY (organization, vault) REFERENCES vaults(organization, name) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS vault_secrets;
+-- +goose StatementEnd
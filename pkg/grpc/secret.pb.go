@@ -25,9 +25,11 @@ const (
 
 // SecretGetRequest defines the request to fetch a secret by its name and type.
 type SecretGetRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SecretName    string                 `protobuf:"bytes,1,opt,name=secret_name,json=secretName,proto3" json:"secret_name,omitempty"`
-	SecretType    string                 `protobuf:"bytes,2,opt,name=secret_type,json=secretType,proto3" json:"secret_type,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SecretName string                 `protobuf:"bytes,1,opt,name=secret_name,json=secretName,proto3" json:"secret_name,omitempty"`
+	SecretType string                 `protobuf:"bytes,2,opt,name=secret_type,json=secretType,proto3" json:"secret_type,omitempty"`
+	// Organization vault as organization/vault, empty for a personal secret.
+	Vault         string `protobuf:"bytes,3,opt,name=vault,proto3" json:"vault,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -76,19 +78,81 @@ func (x *SecretGetRequest) GetSecretType() string {
 	return ""
 }
 
+func (x *SecretGetRequest) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
+// SecretListRequest defines the request to list personal secrets or the secrets of a vault.
+type SecretListRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Organization vault as organization/vault, empty for personal secrets.
+	Vault         string `protobuf:"bytes,1,opt,name=vault,proto3" json:"vault,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecretListRequest) Reset() {
+	*x = SecretListRequest{}
+	mi := &file_secret_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecretListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecretListRequest) ProtoMessage() {}
+
+func (x *SecretListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_secret_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecretListRequest.ProtoReflect.Descriptor instead.
+func (*SecretListRequest) Descriptor() ([]byte, []int) {
+	return file_secret_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SecretListRequest) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
 type SecretSaveRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SecretName    string                 `protobuf:"bytes,1,opt,name=secret_name,json=secretName,proto3" json:"secret_name,omitempty"`
-	SecretType    string                 `protobuf:"bytes,2,opt,name=secret_type,json=secretType,proto3" json:"secret_type,omitempty"`
-	Ciphertext    []byte                 `protobuf:"bytes,4,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
-	AesKeyEnc     []byte                 `protobuf:"bytes,5,opt,name=aes_key_enc,json=aesKeyEnc,proto3" json:"aes_key_enc,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SecretName string                 `protobuf:"bytes,1,opt,name=secret_name,json=secretName,proto3" json:"secret_name,omitempty"`
+	SecretType string                 `protobuf:"bytes,2,opt,name=secret_type,json=secretType,proto3" json:"secret_type,omitempty"`
+	Ciphertext []byte                 `protobuf:"bytes,4,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	AesKeyEnc  []byte                 `protobuf:"bytes,5,opt,name=aes_key_enc,json=aesKeyEnc,proto3" json:"aes_key_enc,omitempty"`
+	// Identifier of the public key aes_key_enc is wrapped with.
+	KeyId string `protobuf:"bytes,6,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// Data key wrapped for every recipient when there is more than one.
+	Recipients []*Recipient `protobuf:"bytes,7,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	// Organization vault as organization/vault, empty for a personal secret.
+	Vault string `protobuf:"bytes,8,opt,name=vault,proto3" json:"vault,omitempty"`
+	// Creation and update times kept by batch saves of restored secrets, unset for now.
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SecretSaveRequest) Reset() {
 	*x = SecretSaveRequest{}
-	mi := &file_secret_proto_msgTypes[1]
+	mi := &file_secret_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -100,7 +164,7 @@ func (x *SecretSaveRequest) String() string {
 func (*SecretSaveRequest) ProtoMessage() {}
 
 func (x *SecretSaveRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_secret_proto_msgTypes[1]
+	mi := &file_secret_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -113,7 +177,7 @@ func (x *SecretSaveRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SecretSaveRequest.ProtoReflect.Descriptor instead.
 func (*SecretSaveRequest) Descriptor() ([]byte, []int) {
-	return file_secret_proto_rawDescGZIP(), []int{1}
+	return file_secret_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *SecretSaveRequest) GetSecretName() string {
@@ -144,23 +208,162 @@ func (x *SecretSaveRequest) GetAesKeyEnc() []byte {
 	return nil
 }
 
+func (x *SecretSaveRequest) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *SecretSaveRequest) GetRecipients() []*Recipient {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+func (x *SecretSaveRequest) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
+func (x *SecretSaveRequest) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SecretSaveRequest) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+// Recipient holds the data key wrapped with one recipient's public key.
+type Recipient struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KeyId         string                 `protobuf:"bytes,1,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	AesKeyEnc     []byte                 `protobuf:"bytes,2,opt,name=aes_key_enc,json=aesKeyEnc,proto3" json:"aes_key_enc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recipient) Reset() {
+	*x = Recipient{}
+	mi := &file_secret_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recipient) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recipient) ProtoMessage() {}
+
+func (x *Recipient) ProtoReflect() protoreflect.Message {
+	mi := &file_secret_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recipient.ProtoReflect.Descriptor instead.
+func (*Recipient) Descriptor() ([]byte, []int) {
+	return file_secret_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Recipient) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *Recipient) GetAesKeyEnc() []byte {
+	if x != nil {
+		return x.AesKeyEnc
+	}
+	return nil
+}
+
+// SecretBatchSaveRequest carries secrets that are saved in a single transaction.
+type SecretBatchSaveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Secrets       []*SecretSaveRequest   `protobuf:"bytes,1,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SecretBatchSaveRequest) Reset() {
+	*x = SecretBatchSaveRequest{}
+	mi := &file_secret_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecretBatchSaveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecretBatchSaveRequest) ProtoMessage() {}
+
+func (x *SecretBatchSaveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_secret_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecretBatchSaveRequest.ProtoReflect.Descriptor instead.
+func (*SecretBatchSaveRequest) Descriptor() ([]byte, []int) {
+	return file_secret_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SecretBatchSaveRequest) GetSecrets() []*SecretSaveRequest {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
 // Secret represents an SecretEncrypted secret stored in the database.
 type Secret struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SecretName    string                 `protobuf:"bytes,1,opt,name=secret_name,json=secretName,proto3" json:"secret_name,omitempty"`
-	SecretType    string                 `protobuf:"bytes,2,opt,name=secret_type,json=secretType,proto3" json:"secret_type,omitempty"`
-	SecretOwner   string                 `protobuf:"bytes,3,opt,name=secret_owner,json=secretOwner,proto3" json:"secret_owner,omitempty"`
-	Ciphertext    []byte                 `protobuf:"bytes,4,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
-	AesKeyEnc     []byte                 `protobuf:"bytes,5,opt,name=aes_key_enc,json=aesKeyEnc,proto3" json:"aes_key_enc,omitempty"`
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SecretName  string                 `protobuf:"bytes,1,opt,name=secret_name,json=secretName,proto3" json:"secret_name,omitempty"`
+	SecretType  string                 `protobuf:"bytes,2,opt,name=secret_type,json=secretType,proto3" json:"secret_type,omitempty"`
+	SecretOwner string                 `protobuf:"bytes,3,opt,name=secret_owner,json=secretOwner,proto3" json:"secret_owner,omitempty"`
+	Ciphertext  []byte                 `protobuf:"bytes,4,opt,name=ciphertext,proto3" json:"ciphertext,omitempty"`
+	AesKeyEnc   []byte                 `protobuf:"bytes,5,opt,name=aes_key_enc,json=aesKeyEnc,proto3" json:"aes_key_enc,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Identifier of the public key aes_key_enc is wrapped with.
+	KeyId string `protobuf:"bytes,8,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`
+	// Data key wrapped for every recipient when there is more than one.
+	Recipients []*Recipient `protobuf:"bytes,9,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	// Organization vault as organization/vault, empty for a personal secret.
+	Vault         string `protobuf:"bytes,10,opt,name=vault,proto3" json:"vault,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Secret) Reset() {
 	*x = Secret{}
-	mi := &file_secret_proto_msgTypes[2]
+	mi := &file_secret_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -172,7 +375,7 @@ func (x *Secret) String() string {
 func (*Secret) ProtoMessage() {}
 
 func (x *Secret) ProtoReflect() protoreflect.Message {
-	mi := &file_secret_proto_msgTypes[2]
+	mi := &file_secret_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -185,7 +388,7 @@ func (x *Secret) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Secret.ProtoReflect.Descriptor instead.
 func (*Secret) Descriptor() ([]byte, []int) {
-	return file_secret_proto_rawDescGZIP(), []int{2}
+	return file_secret_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Secret) GetSecretName() string {
@@ -237,16 +440,40 @@ func (x *Secret) GetUpdatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *Secret) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *Secret) GetRecipients() []*Recipient {
+	if x != nil {
+		return x.Recipients
+	}
+	return nil
+}
+
+func (x *Secret) GetVault() string {
+	if x != nil {
+		return x.Vault
+	}
+	return ""
+}
+
 var File_secret_proto protoreflect.FileDescriptor
 
 const file_secret_proto_rawDesc = "" +
 	"\n" +
-	"\fsecret.proto\x12\x06secret\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"T\n" +
+	"\fsecret.proto\x12\x06secret\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"j\n" +
 	"\x10SecretGetRequest\x12\x1f\n" +
 	"\vsecret_name\x18\x01 \x01(\tR\n" +
 	"secretName\x12\x1f\n" +
 	"\vsecret_type\x18\x02 \x01(\tR\n" +
-	"secretType\"\x95\x01\n" +
+	"secretType\x12\x14\n" +
+	"\x05vault\x18\x03 \x01(\tR\x05vault\")\n" +
+	"\x11SecretListRequest\x12\x14\n" +
+	"\x05vault\x18\x01 \x01(\tR\x05vault\"\xeb\x02\n" +
 	"\x11SecretSaveRequest\x12\x1f\n" +
 	"\vsecret_name\x18\x01 \x01(\tR\n" +
 	"secretName\x12\x1f\n" +
@@ -255,7 +482,22 @@ const file_secret_proto_rawDesc = "" +
 	"\n" +
 	"ciphertext\x18\x04 \x01(\fR\n" +
 	"ciphertext\x12\x1e\n" +
-	"\vaes_key_enc\x18\x05 \x01(\fR\taesKeyEnc\"\xa3\x02\n" +
+	"\vaes_key_enc\x18\x05 \x01(\fR\taesKeyEnc\x12\x15\n" +
+	"\x06key_id\x18\x06 \x01(\tR\x05keyId\x121\n" +
+	"\n" +
+	"recipients\x18\a \x03(\v2\x11.secret.RecipientR\n" +
+	"recipients\x12\x14\n" +
+	"\x05vault\x18\b \x01(\tR\x05vault\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"B\n" +
+	"\tRecipient\x12\x15\n" +
+	"\x06key_id\x18\x01 \x01(\tR\x05keyId\x12\x1e\n" +
+	"\vaes_key_enc\x18\x02 \x01(\fR\taesKeyEnc\"M\n" +
+	"\x16SecretBatchSaveRequest\x123\n" +
+	"\asecrets\x18\x01 \x03(\v2\x19.secret.SecretSaveRequestR\asecrets\"\x83\x03\n" +
 	"\x06Secret\x12\x1f\n" +
 	"\vsecret_name\x18\x01 \x01(\tR\n" +
 	"secretName\x12\x1f\n" +
@@ -269,12 +511,19 @@ const file_secret_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
 	"\n" +
-	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2O\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x15\n" +
+	"\x06key_id\x18\b \x01(\tR\x05keyId\x121\n" +
+	"\n" +
+	"recipients\x18\t \x03(\v2\x11.secret.RecipientR\n" +
+	"recipients\x12\x14\n" +
+	"\x05vault\x18\n" +
+	" \x01(\tR\x05vault2\x94\x01\n" +
 	"\x12SecretWriteService\x129\n" +
-	"\x04Save\x12\x19.secret.SecretSaveRequest\x1a\x16.google.protobuf.Empty2v\n" +
+	"\x04Save\x12\x19.secret.SecretSaveRequest\x1a\x16.google.protobuf.Empty\x12C\n" +
+	"\tSaveBatch\x12\x1e.secret.SecretBatchSaveRequest\x1a\x16.google.protobuf.Empty2y\n" +
 	"\x11SecretReadService\x12/\n" +
-	"\x03Get\x12\x18.secret.SecretGetRequest\x1a\x0e.secret.Secret\x120\n" +
-	"\x04List\x12\x16.google.protobuf.Empty\x1a\x0e.secret.Secret0\x01B-Z+github.com/sbilibin2017/gophkeeper/pkg/grpcb\x06proto3"
+	"\x03Get\x12\x18.secret.SecretGetRequest\x1a\x0e.secret.Secret\x123\n" +
+	"\x04List\x12\x19.secret.SecretListRequest\x1a\x0e.secret.Secret0\x01B-Z+github.com/sbilibin2017/gophkeeper/pkg/grpcb\x06proto3"
 
 var (
 	file_secret_proto_rawDescOnce sync.Once
@@ -288,28 +537,38 @@ func file_secret_proto_rawDescGZIP() []byte {
 	return file_secret_proto_rawDescData
 }
 
-var file_secret_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_secret_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_secret_proto_goTypes = []any{
-	(*SecretGetRequest)(nil),      // 0: secret.SecretGetRequest
-	(*SecretSaveRequest)(nil),     // 1: secret.SecretSaveRequest
-	(*Secret)(nil),                // 2: secret.Secret
-	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),         // 4: google.protobuf.Empty
+	(*SecretGetRequest)(nil),       // 0: secret.SecretGetRequest
+	(*SecretListRequest)(nil),      // 1: secret.SecretListRequest
+	(*SecretSaveRequest)(nil),      // 2: secret.SecretSaveRequest
+	(*Recipient)(nil),              // 3: secret.Recipient
+	(*SecretBatchSaveRequest)(nil), // 4: secret.SecretBatchSaveRequest
+	(*Secret)(nil),                 // 5: secret.Secret
+	(*timestamppb.Timestamp)(nil),  // 6: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),          // 7: google.protobuf.Empty
 }
 var file_secret_proto_depIdxs = []int32{
-	3, // 0: secret.Secret.created_at:type_name -> google.protobuf.Timestamp
-	3, // 1: secret.Secret.updated_at:type_name -> google.protobuf.Timestamp
-	1, // 2: secret.SecretWriteService.Save:input_type -> secret.SecretSaveRequest
-	0, // 3: secret.SecretReadService.Get:input_type -> secret.SecretGetRequest
-	4, // 4: secret.SecretReadService.List:input_type -> google.protobuf.Empty
-	4, // 5: secret.SecretWriteService.Save:output_type -> google.protobuf.Empty
-	2, // 6: secret.SecretReadService.Get:output_type -> secret.Secret
-	2, // 7: secret.SecretReadService.List:output_type -> secret.Secret
-	5, // [5:8] is the sub-list for method output_type
-	2, // [2:5] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	3,  // 0: secret.SecretSaveRequest.recipients:type_name -> secret.Recipient
+	6,  // 1: secret.SecretSaveRequest.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 2: secret.SecretSaveRequest.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 3: secret.SecretBatchSaveRequest.secrets:type_name -> secret.SecretSaveRequest
+	6,  // 4: secret.Secret.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 5: secret.Secret.updated_at:type_name -> google.protobuf.Timestamp
+	3,  // 6: secret.Secret.recipients:type_name -> secret.Recipient
+	2,  // 7: secret.SecretWriteService.Save:input_type -> secret.SecretSaveRequest
+	4,  // 8: secret.SecretWriteService.SaveBatch:input_type -> secret.SecretBatchSaveRequest
+	0,  // 9: secret.SecretReadService.Get:input_type -> secret.SecretGetRequest
+	1,  // 10: secret.SecretReadService.List:input_type -> secret.SecretListRequest
+	7,  // 11: secret.SecretWriteService.Save:output_type -> google.protobuf.Empty
+	7,  // 12: secret.SecretWriteService.SaveBatch:output_type -> google.protobuf.Empty
+	5,  // 13: secret.SecretReadService.Get:output_type -> secret.Secret
+	5,  // 14: secret.SecretReadService.List:output_type -> secret.Secret
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_secret_proto_init() }
@@ -323,7 +582,7 @@ func file_secret_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_secret_proto_rawDesc), len(file_secret_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
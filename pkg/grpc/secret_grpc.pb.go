@@ -20,7 +20,8 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SecretWriteService_Save_FullMethodName = "/secret.SecretWriteService/Save"
+	SecretWriteService_Save_FullMethodName      = "/secret.SecretWriteService/Save"
+	SecretWriteService_SaveBatch_FullMethodName = "/secret.SecretWriteService/SaveBatch"
 )
 
 // SecretWriteServiceClient is the client API for SecretWriteService service.
@@ -31,6 +32,8 @@ const (
 type SecretWriteServiceClient interface {
 	// Saves an SecretEncrypted secret.
 	Save(ctx context.Context, in *SecretSaveRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Saves several secrets atomically, either all of them or none.
+	SaveBatch(ctx context.Context, in *SecretBatchSaveRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type secretWriteServiceClient struct {
@@ -51,6 +54,16 @@ func (c *secretWriteServiceClient) Save(ctx context.Context, in *SecretSaveReque
 	return out, nil
 }
 
+func (c *secretWriteServiceClient) SaveBatch(ctx context.Context, in *SecretBatchSaveRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SecretWriteService_SaveBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SecretWriteServiceServer is the server API for SecretWriteService service.
 // All implementations must embed UnimplementedSecretWriteServiceServer
 // for forward compatibility.
@@ -59,6 +72,8 @@ func (c *secretWriteServiceClient) Save(ctx context.Context, in *SecretSaveReque
 type SecretWriteServiceServer interface {
 	// Saves an SecretEncrypted secret.
 	Save(context.Context, *SecretSaveRequest) (*emptypb.Empty, error)
+	// Saves several secrets atomically, either all of them or none.
+	SaveBatch(context.Context, *SecretBatchSaveRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedSecretWriteServiceServer()
 }
 
@@ -72,6 +87,9 @@ type UnimplementedSecretWriteServiceServer struct{}
 func (UnimplementedSecretWriteServiceServer) Save(context.Context, *SecretSaveRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Save not implemented")
 }
+func (UnimplementedSecretWriteServiceServer) SaveBatch(context.Context, *SecretBatchSaveRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveBatch not implemented")
+}
 func (UnimplementedSecretWriteServiceServer) mustEmbedUnimplementedSecretWriteServiceServer() {}
 func (UnimplementedSecretWriteServiceServer) testEmbeddedByValue()                            {}
 
@@ -111,6 +129,24 @@ func _SecretWriteService_Save_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SecretWriteService_SaveBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecretBatchSaveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretWriteServiceServer).SaveBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SecretWriteService_SaveBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretWriteServiceServer).SaveBatch(ctx, req.(*SecretBatchSaveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SecretWriteService_ServiceDesc is the grpc.ServiceDesc for SecretWriteService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -122,6 +158,10 @@ var SecretWriteService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Save",
 			Handler:    _SecretWriteService_Save_Handler,
 		},
+		{
+			MethodName: "SaveBatch",
+			Handler:    _SecretWriteService_SaveBatch_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "secret.proto",
@@ -140,8 +180,8 @@ const (
 type SecretReadServiceClient interface {
 	// Retrieves a specific secret by name and type.
 	Get(ctx context.Context, in *SecretGetRequest, opts ...grpc.CallOption) (*Secret, error)
-	// Lists all secrets for the authenticated user.
-	List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Secret], error)
+	// Lists all secrets for the authenticated user or of an organization vault.
+	List(ctx context.Context, in *SecretListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Secret], error)
 }
 
 type secretReadServiceClient struct {
@@ -162,13 +202,13 @@ func (c *secretReadServiceClient) Get(ctx context.Context, in *SecretGetRequest,
 	return out, nil
 }
 
-func (c *secretReadServiceClient) List(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Secret], error) {
+func (c *secretReadServiceClient) List(ctx context.Context, in *SecretListRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Secret], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	stream, err := c.cc.NewStream(ctx, &SecretReadService_ServiceDesc.Streams[0], SecretReadService_List_FullMethodName, cOpts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &grpc.GenericClientStream[emptypb.Empty, Secret]{ClientStream: stream}
+	x := &grpc.GenericClientStream[SecretListRequest, Secret]{ClientStream: stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -189,8 +229,8 @@ type SecretReadService_ListClient = grpc.ServerStreamingClient[Secret]
 type SecretReadServiceServer interface {
 	// Retrieves a specific secret by name and type.
 	Get(context.Context, *SecretGetRequest) (*Secret, error)
-	// Lists all secrets for the authenticated user.
-	List(*emptypb.Empty, grpc.ServerStreamingServer[Secret]) error
+	// Lists all secrets for the authenticated user or of an organization vault.
+	List(*SecretListRequest, grpc.ServerStreamingServer[Secret]) error
 	mustEmbedUnimplementedSecretReadServiceServer()
 }
 
@@ -204,7 +244,7 @@ type UnimplementedSecretReadServiceServer struct{}
 func (UnimplementedSecretReadServiceServer) Get(context.Context, *SecretGetRequest) (*Secret, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
-func (UnimplementedSecretReadServiceServer) List(*emptypb.Empty, grpc.ServerStreamingServer[Secret]) error {
+func (UnimplementedSecretReadServiceServer) List(*SecretListRequest, grpc.ServerStreamingServer[Secret]) error {
 	return status.Errorf(codes.Unimplemented, "method List not implemented")
 }
 func (UnimplementedSecretReadServiceServer) mustEmbedUnimplementedSecretReadServiceServer() {}
@@ -247,11 +287,11 @@ func _SecretReadService_Get_Handler(srv interface{}, ctx context.Context, dec fu
 }
 
 func _SecretReadService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(emptypb.Empty)
+	m := new(SecretListRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(SecretReadServiceServer).List(m, &grpc.GenericServerStream[emptypb.Empty, Secret]{ServerStream: stream})
+	return srv.(SecretReadServiceServer).List(m, &grpc.GenericServerStream[SecretListRequest, Secret]{ServerStream: stream})
 }
 
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.